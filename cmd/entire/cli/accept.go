@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/huh"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/spf13/cobra"
+)
+
+func newAcceptCmd() *cobra.Command {
+	var rebase bool
+
+	cmd := &cobra.Command{
+		Use:   "accept [session-id]",
+		Short: "Apply a session's work branch to the current branch",
+		Long: `Accept applies the changes of a session-branch strategy session to the
+branch checked out in the current worktree, then removes the session's
+entire/work/<session> branch and its worktree.
+
+By default the session's commits are squashed into one commit carrying an
+Entire-Checkpoint trailer for every checkpoint of the session. With --rebase
+they are applied one by one, keeping one commit per agent turn.
+
+The session is first rebased onto the current branch in its worktree. If that
+conflicts, resolve the conflict there and run 'entire accept' again.
+
+The session ID may be abbreviated, and can be left out when there is only
+one session branch.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			mode := strategy.AcceptSquash
+			if rebase {
+				mode = strategy.AcceptRebase
+			}
+			return runAccept(cmd.Context(), cmd.OutOrStdout(), sessionArg(args), mode)
+		},
+	}
+
+	cmd.Flags().BoolVar(&rebase, "rebase", false, "Apply the session's commits one by one instead of squashing them")
+
+	return cmd
+}
+
+func runAccept(ctx context.Context, w io.Writer, query string, mode strategy.AcceptMode) error {
+	sb, err := findSessionBranch(ctx, query)
+	if err != nil {
+		return err
+	}
+	result, err := strategy.AcceptSession(ctx, sb, mode)
+	if errors.Is(err, strategy.ErrNothingToAccept) {
+		fmt.Fprintf(w, "Session %s has no changes that aren't already on the current branch.\n", sb.Name())
+		fmt.Fprintln(w, "Run 'entire discard' to remove its branch.")
+		return nil
+	}
+	if err != nil {
+		return err //nolint:wrapcheck // Already descriptive
+	}
+
+	fmt.Fprintf(w, "Accepted session %s onto %s:\n", sb.Name(), result.Branch)
+	for _, commit := range result.Commits {
+		fmt.Fprintf(w, "  %s\n", commit[:7])
+	}
+	fmt.Fprintf(w, "Linked %d checkpoint(s). Removed %s.\n", len(result.CheckpointIDs), sb.Branch)
+	return nil
+}
+
+func newDiscardCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "discard [session-id]",
+		Short: "Throw away a session's work branch",
+		Long: `Discard removes a session-branch strategy session: its entire/work/<session>
+branch, its worktree and its session state.
+
+Files in the current worktree that still have the session's latest content are
+restored to their HEAD version, so changes the agent made in your checkout are
+undone as well. Files you changed since are left alone.
+
+The session's checkpoints stay on entire/checkpoints/v1 until removed with
+'entire clean'.
+
+The session ID may be abbreviated, and can be left out when there is only
+one session branch. Without --force, prompts for confirmation.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runDiscard(cmd.Context(), cmd.OutOrStdout(), sessionArg(args), force)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+func runDiscard(ctx context.Context, w io.Writer, query string, force bool) error {
+	sb, err := findSessionBranch(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		var confirmed bool
+		form := NewAccessibleForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title(fmt.Sprintf("Discard session %s and its branch %s?", sb.Name(), sb.Branch)).
+					Value(&confirmed),
+			),
+		)
+		if err := form.Run(); err != nil {
+			if errors.Is(err, huh.ErrUserAborted) {
+				return nil
+			}
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	result, err := strategy.DiscardSession(ctx, sb)
+	if err != nil {
+		return err //nolint:wrapcheck // Already descriptive
+	}
+	fmt.Fprintf(w, "Discarded session %s and removed %s.\n", sb.Name(), sb.Branch)
+	if len(result.Restored) > 0 {
+		fmt.Fprintf(w, "Restored %d file(s) to their HEAD version:\n", len(result.Restored))
+		for _, file := range result.Restored {
+			fmt.Fprintf(w, "  %s\n", file)
+		}
+	}
+	if len(result.Kept) > 0 {
+		fmt.Fprintf(w, "Kept %d file(s) with changes made after the session:\n", len(result.Kept))
+		for _, file := range result.Kept {
+			fmt.Fprintf(w, "  %s\n", file)
+		}
+	}
+	return nil
+}
+
+func sessionArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// findSessionBranch finds the session branch matching query, explaining how
+// session branches are created when there are none.
+func findSessionBranch(ctx context.Context, query string) (strategy.SessionBranch, error) {
+	sb, err := strategy.FindSessionBranch(ctx, query)
+	if errors.Is(err, strategy.ErrNoSessionBranch) {
+		return sb, fmt.Errorf("%w; session branches are created by the %s strategy", err, strategy.StrategyNameSessionBranch)
+	}
+	return sb, err //nolint:wrapcheck // Already descriptive
+}
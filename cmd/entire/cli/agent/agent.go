@@ -140,6 +140,17 @@ type TranscriptAnalyzer interface {
 	ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error)
 }
 
+// TokenUsageCalculator is implemented by agents whose transcripts record token
+// usage. Hook-based agents compute it in their hook handlers; `entire watch`
+// uses this to attach token usage to the checkpoints of watched agents.
+type TokenUsageCalculator interface {
+	Agent
+
+	// CalculateTokenUsage returns the token usage recorded in the transcript
+	// at path from startOffset on, in the units of GetTranscriptPosition.
+	CalculateTokenUsage(path string, startOffset int) (*TokenUsage, error)
+}
+
 // TranscriptChunker is implemented by agents that support transcript chunking.
 // This allows agents to split large transcripts into chunks for storage (GitHub has
 // a 100MB blob limit) and reassemble them when reading.
@@ -0,0 +1,290 @@
+// Package aider implements the Agent interface for Aider.
+// Aider has no hook system, so sessions are captured by watching the
+// chat history it writes to the repository root (see `entire watch`).
+package aider
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameAider, NewAiderAgent)
+}
+
+// AiderAgent implements the Agent interface for Aider.
+//
+//nolint:revive // AiderAgent is clearer than Agent in this context
+type AiderAgent struct {
+	// watch tracks turn progress per session while `entire watch` runs.
+	watch *watchTracker
+}
+
+// NewAiderAgent creates a new Aider agent instance.
+func NewAiderAgent() agent.Agent {
+	return &AiderAgent{watch: newWatchTracker()}
+}
+
+// Name returns the agent registry key.
+func (a *AiderAgent) Name() agent.AgentName {
+	return agent.AgentNameAider
+}
+
+// Type returns the agent type identifier.
+func (a *AiderAgent) Type() agent.AgentType {
+	return agent.AgentTypeAider
+}
+
+// Description returns a human-readable description.
+func (a *AiderAgent) Description() string {
+	return "Aider - AI pair programming in your terminal"
+}
+
+// DetectPresence checks if Aider has been used or configured in the repository.
+func (a *AiderAgent) DetectPresence() (bool, error) {
+	repoRoot := repoRootOrCwd()
+	for _, name := range []string{ChatHistoryFileName, InputHistoryFileName, ConfigFileName} {
+		if _, err := os.Stat(filepath.Join(repoRoot, name)); err == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetHookConfigPath returns an empty string as Aider has no hook config.
+func (a *AiderAgent) GetHookConfigPath() string {
+	return ""
+}
+
+// SupportsHooks returns false as Aider is captured by file watching.
+func (a *AiderAgent) SupportsHooks() bool {
+	return false
+}
+
+// ParseHookInput always fails as Aider never invokes hooks.
+func (a *AiderAgent) ParseHookInput(_ agent.HookType, _ io.Reader) (*agent.HookInput, error) {
+	return nil, errors.New("aider does not support hooks; run `entire watch` to capture Aider sessions")
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (a *AiderAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// ProtectedDirs returns the Aider history files, which live in the repository
+// root rather than in a directory, so rewind never deletes them.
+func (a *AiderAgent) ProtectedDirs() []string {
+	return []string{ChatHistoryFileName, InputHistoryFileName}
+}
+
+// GetSessionDir returns the directory Aider writes its history to: the repository itself.
+func (a *AiderAgent) GetSessionDir(repoPath string) (string, error) {
+	if repoPath != "" {
+		return repoPath, nil
+	}
+	return repoRootOrCwd(), nil
+}
+
+// ResolveSessionFile returns the chat history path.
+// All Aider sessions in a repository share one history file.
+func (a *AiderAgent) ResolveSessionFile(sessionDir, _ string) string {
+	return filepath.Join(sessionDir, ChatHistoryFileName)
+}
+
+// ReadSession reads a session from Aider's chat history.
+// NativeData, Entries and ModifiedFiles cover only the requested session (the
+// most recent one if no session ID is given), not the rest of the shared file.
+func (a *AiderAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (chat history path) is required")
+	}
+
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+
+	sess := &agent.AgentSession{
+		SessionID:  input.SessionID,
+		AgentName:  a.Name(),
+		RepoPath:   filepath.Dir(input.SessionRef),
+		SessionRef: input.SessionRef,
+		StartTime:  time.Now(),
+		NativeData: data,
+	}
+
+	if chat := FindSession(ParseChatHistory(data), input.SessionID); chat != nil {
+		sess.SessionID = chat.ID
+		if !chat.StartedAt.IsZero() {
+			sess.StartTime = chat.StartedAt
+		}
+		sess.NativeData, _ = SessionTranscript(data, chat.ID)
+		sess.ModifiedFiles = ExtractModifiedFiles(chat.Exchanges)
+		sess.Entries = SessionEntries(chat)
+	}
+
+	return sess, nil
+}
+
+// WriteSession writes a session back to Aider's chat history file.
+// NativeData holds the session's markdown; it replaces the session's part of
+// an existing history file, or is appended to it, so other sessions are kept.
+func (a *AiderAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Aider
+	if session.AgentName != "" && session.AgentName != a.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, a.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (chat history path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	data := session.NativeData
+	if existing, err := os.ReadFile(session.SessionRef); err == nil {
+		data = MergeSessionTranscript(existing, session.NativeData, session.SessionID)
+	}
+	if err := os.WriteFile(session.SessionRef, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write chat history: %w", err)
+	}
+
+	return nil
+}
+
+// FormatResumeCommand returns the command to resume an Aider session.
+// Aider has no session IDs; it restores the chat from the history file instead.
+func (a *AiderAgent) FormatResumeCommand(_ string) string {
+	return "aider --restore-chat-history"
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition returns the current line count of the chat history.
+// Returns 0 if the file doesn't exist or is empty.
+func (a *AiderAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	file, err := os.Open(path) //nolint:gosec // Path comes from Aider chat history location
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to open chat history: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	lineCount := 0
+
+	for {
+		_, err := reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return 0, fmt.Errorf("failed to read chat history: %w", err)
+		}
+		lineCount++
+	}
+
+	return lineCount, nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files edited since a given line number.
+// Returns:
+//   - files: list of file paths edited by Aider
+//   - currentPosition: total number of lines in the file
+//   - error: any error encountered during reading
+func (a *AiderAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+
+	data, readErr := os.ReadFile(path) //nolint:gosec // Path comes from Aider chat history location
+	if readErr != nil {
+		if os.IsNotExist(readErr) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to read chat history: %w", readErr)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	if startOffset < len(lines) {
+		for _, session := range ParseChatHistory([]byte(strings.Join(lines[max(startOffset, 0):], ""))) {
+			files = mergeFiles(files, ExtractModifiedFiles(session.Exchanges))
+		}
+	}
+
+	return files, len(lines), nil
+}
+
+// TokenUsageCalculator interface implementation
+
+// CalculateTokenUsage sums the token reports in the chat history from line startOffset on.
+func (a *AiderAgent) CalculateTokenUsage(path string, startOffset int) (*agent.TokenUsage, error) {
+	return CalculateTokenUsageFromFile(path, startOffset)
+}
+
+// TranscriptChunker interface implementation
+
+// ChunkTranscript splits the markdown chat history at line boundaries.
+func (a *AiderAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk chat history: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates chunks with newlines.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (a *AiderAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
+
+// TranscriptNormalizer interface implementation
+
+// NormalizeTranscript converts every chat session in the history into session entries.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (a *AiderAgent) NormalizeTranscript(content []byte) ([]agent.SessionEntry, error) {
+	var entries []agent.SessionEntry
+	sessions := ParseChatHistory(content)
+	for i := range sessions {
+		entries = append(entries, SessionEntries(&sessions[i])...)
+	}
+	return entries, nil
+}
+
+// repoRootOrCwd returns the repository root, falling back to the current
+// directory outside a git repository (e.g., during tests).
+func repoRootOrCwd() string {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return "."
+	}
+	return repoRoot
+}
@@ -0,0 +1,182 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestNewAiderAgent(t *testing.T) {
+	t.Parallel()
+	ag := NewAiderAgent()
+	if ag == nil {
+		t.Fatal("NewAiderAgent() returned nil")
+	}
+	if ag.Name() != agent.AgentNameAider {
+		t.Errorf("Name() = %q, want %q", ag.Name(), agent.AgentNameAider)
+	}
+	if ag.Type() != agent.AgentTypeAider {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeAider)
+	}
+	if ag.SupportsHooks() {
+		t.Error("SupportsHooks() = true, want false")
+	}
+}
+
+func TestDetectPresence(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &AiderAgent{}
+	present, err := ag.DetectPresence()
+	if err != nil {
+		t.Fatalf("DetectPresence() error = %v", err)
+	}
+	if present {
+		t.Error("DetectPresence() = true without Aider files, want false")
+	}
+
+	if err := os.WriteFile(ChatHistoryFileName, []byte(testHistory), 0o600); err != nil {
+		t.Fatalf("failed to write chat history: %v", err)
+	}
+	present, err = ag.DetectPresence()
+	if err != nil {
+		t.Fatalf("DetectPresence() error = %v", err)
+	}
+	if !present {
+		t.Error("DetectPresence() = false with chat history, want true")
+	}
+}
+
+func TestParseHookInput_Unsupported(t *testing.T) {
+	t.Parallel()
+	ag := &AiderAgent{}
+	if _, err := ag.ParseHookInput(agent.HookStop, strings.NewReader("{}")); err == nil {
+		t.Error("ParseHookInput() should error for Aider")
+	}
+}
+
+func TestResolveSessionFile(t *testing.T) {
+	t.Parallel()
+	ag := &AiderAgent{}
+	if got := ag.ResolveSessionFile("/repo", "aider-x"); got != filepath.Join("/repo", ChatHistoryFileName) {
+		t.Errorf("ResolveSessionFile() = %q", got)
+	}
+}
+
+func TestReadWriteSession(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	historyPath := filepath.Join(tempDir, ChatHistoryFileName)
+	data := testHistory + "\n# aider chat started at 2025-01-03 09:30:00\n\n#### Next task\n\nOn it.\n"
+	if err := os.WriteFile(historyPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write chat history: %v", err)
+	}
+
+	ag := &AiderAgent{}
+	sess, err := ag.ReadSession(&agent.HookInput{
+		SessionID:  "aider-2025-01-02T10-00-00",
+		SessionRef: historyPath,
+	})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if sess.GetLastUserPrompt() != "Thanks" {
+		t.Errorf("GetLastUserPrompt() = %q, want %q", sess.GetLastUserPrompt(), "Thanks")
+	}
+	if sess.GetLastAssistantResponse() != "You're welcome." {
+		t.Errorf("GetLastAssistantResponse() = %q", sess.GetLastAssistantResponse())
+	}
+	if len(sess.ModifiedFiles) != 2 {
+		t.Errorf("ModifiedFiles = %v, want 2 files", sess.ModifiedFiles)
+	}
+
+	latest, err := ag.ReadSession(&agent.HookInput{SessionRef: historyPath})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if latest.SessionID != "aider-2025-01-03T09-30-00" {
+		t.Errorf("SessionID = %q, want most recent session", latest.SessionID)
+	}
+
+	// NativeData holds only the session's part of the shared history.
+	latestSection := "# aider chat started at 2025-01-03 09:30:00\n\n#### Next task\n\nOn it.\n"
+	if string(latest.NativeData) != latestSection {
+		t.Errorf("NativeData = %q, want only the latest session %q", latest.NativeData, latestSection)
+	}
+
+	restorePath := filepath.Join(tempDir, "restored.md")
+	latest.SessionRef = restorePath
+	if err := ag.WriteSession(latest); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+	restored, err := os.ReadFile(restorePath)
+	if err != nil {
+		t.Fatalf("failed to read restored history: %v", err)
+	}
+	if string(restored) != latestSection {
+		t.Errorf("restored history = %q, want %q", restored, latestSection)
+	}
+
+	// Writing into an existing history replaces only the session's part.
+	edited := strings.Replace(data, "Thanks", "Thanks again", 1)
+	if err := os.WriteFile(historyPath, []byte(edited), 0o600); err != nil {
+		t.Fatalf("failed to write chat history: %v", err)
+	}
+	sess.SessionRef = historyPath
+	if err := ag.WriteSession(sess); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+	restored, err = os.ReadFile(historyPath)
+	if err != nil {
+		t.Fatalf("failed to read restored history: %v", err)
+	}
+	if string(restored) != data {
+		t.Errorf("restored history = %q, want %q", restored, data)
+	}
+}
+
+func TestWriteSession_WrongAgent(t *testing.T) {
+	t.Parallel()
+	ag := &AiderAgent{}
+	err := ag.WriteSession(&agent.AgentSession{
+		AgentName:  agent.AgentNameClaudeCode,
+		SessionRef: filepath.Join(t.TempDir(), ChatHistoryFileName),
+		NativeData: []byte("data"),
+	})
+	if err == nil {
+		t.Error("WriteSession() should reject sessions from other agents")
+	}
+}
+
+func TestExtractModifiedFilesFromOffset(t *testing.T) {
+	t.Parallel()
+	historyPath := filepath.Join(t.TempDir(), ChatHistoryFileName)
+	data := "#### one\n\nok\n\n> Applied edit to a.go\n\n#### two\n\nok\n\n> Applied edit to b.go\n"
+	if err := os.WriteFile(historyPath, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write chat history: %v", err)
+	}
+
+	ag := &AiderAgent{}
+	files, pos, err := ag.ExtractModifiedFilesFromOffset(historyPath, 5)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if pos != 11 {
+		t.Errorf("position = %d, want 11", pos)
+	}
+	if len(files) != 1 || files[0] != "b.go" {
+		t.Errorf("files = %v, want [b.go]", files)
+	}
+
+	position, err := ag.GetTranscriptPosition(historyPath)
+	if err != nil {
+		t.Fatalf("GetTranscriptPosition() error = %v", err)
+	}
+	if position != 11 {
+		t.Errorf("GetTranscriptPosition() = %d, want 11", position)
+	}
+}
@@ -0,0 +1,42 @@
+package aider
+
+import (
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestNormalizeTranscript(t *testing.T) {
+	t.Parallel()
+
+	history := testHistory + `
+# aider chat started at 2025-01-02 11:00:00
+
+#### Second session
+
+OK.
+`
+
+	entries, err := (&AiderAgent{}).NormalizeTranscript([]byte(history))
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+
+	var prompts []string
+	var edits int
+	for _, e := range entries {
+		switch e.Type {
+		case agent.EntryUser:
+			prompts = append(prompts, e.Content)
+		case agent.EntryTool:
+			edits++
+		case agent.EntryAssistant, agent.EntrySystem:
+		}
+	}
+	if len(prompts) != 3 || prompts[2] != "Second session" {
+		t.Errorf("prompts = %q, want both sessions' prompts", prompts)
+	}
+	if edits != 2 {
+		t.Errorf("got %d edit entries, want 2", edits)
+	}
+}
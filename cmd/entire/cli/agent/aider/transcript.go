@@ -0,0 +1,393 @@
+package aider
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// nonIDCharRegex matches characters not allowed in derived session IDs.
+var nonIDCharRegex = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// ParseChatHistory splits Aider's markdown chat history into sessions.
+// History written before any session header is returned as a session with
+// the default ID, so partial files (e.g. a transcript slice) still parse.
+func ParseChatHistory(data []byte) []ChatSession {
+	var sessions []ChatSession
+	var current *ChatSession
+	var exchange *Exchange
+	var response []string
+	lastWasPrompt := false
+
+	flushExchange := func() {
+		if exchange == nil {
+			return
+		}
+		exchange.Response = strings.TrimSpace(strings.Join(response, "\n"))
+		exchange.ModifiedFiles = mergeFiles(ParseSearchReplaceFiles(exchange.Response), exchange.ModifiedFiles)
+		current.Exchanges = append(current.Exchanges, *exchange)
+		exchange = nil
+		response = nil
+	}
+	flushSession := func() {
+		if current == nil {
+			return
+		}
+		flushExchange()
+		sessions = append(sessions, *current)
+		current = nil
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if strings.HasPrefix(line, sessionHeaderPrefix) {
+			flushSession()
+			current = newChatSession(strings.TrimSpace(strings.TrimPrefix(line, sessionHeaderPrefix)), i)
+			lastWasPrompt = false
+			continue
+		}
+		if current == nil {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			current = &ChatSession{ID: defaultSessionID, StartLine: i}
+		}
+
+		switch {
+		case strings.HasPrefix(line, userPromptPrefix) || line == strings.TrimSpace(userPromptPrefix):
+			text := strings.TrimPrefix(strings.TrimPrefix(line, strings.TrimSpace(userPromptPrefix)), " ")
+			if lastWasPrompt && exchange != nil {
+				// Multi-line prompts are written as consecutive #### lines
+				exchange.Prompt += "\n" + text
+				continue
+			}
+			flushExchange()
+			exchange = &Exchange{Prompt: text}
+			lastWasPrompt = true
+
+		case strings.HasPrefix(line, toolOutputPrefix):
+			lastWasPrompt = false
+			if exchange == nil {
+				continue
+			}
+			text := strings.TrimSpace(strings.TrimPrefix(line, toolOutputPrefix))
+			if text == "" {
+				continue
+			}
+			exchange.ToolOutput = append(exchange.ToolOutput, text)
+			if file, ok := strings.CutPrefix(text, appliedEditPrefix); ok {
+				exchange.ModifiedFiles = mergeFiles(exchange.ModifiedFiles, []string{strings.TrimSpace(file)})
+			}
+
+		default:
+			if lastWasPrompt && strings.TrimSpace(line) == "" {
+				continue
+			}
+			lastWasPrompt = false
+			if exchange != nil {
+				response = append(response, line)
+			}
+		}
+	}
+	flushSession()
+
+	return sessions
+}
+
+// newChatSession creates a session from the timestamp in its header.
+func newChatSession(timestamp string, startLine int) *ChatSession {
+	session := &ChatSession{StartLine: startLine}
+	if t, err := time.ParseInLocation(sessionTimeLayout, timestamp, time.Local); err == nil {
+		session.StartedAt = t
+		session.ID = "aider-" + t.Format("2006-01-02T15-04-05")
+	} else {
+		session.ID = "aider-" + strings.Trim(nonIDCharRegex.ReplaceAllString(timestamp, "-"), "-")
+	}
+	return session
+}
+
+// LastSession returns the most recent session in the history, or nil if there is none.
+func LastSession(sessions []ChatSession) *ChatSession {
+	if len(sessions) == 0 {
+		return nil
+	}
+	return &sessions[len(sessions)-1]
+}
+
+// FindSession returns the session with the given ID, or the most recent session if id is empty.
+func FindSession(sessions []ChatSession, id string) *ChatSession {
+	if id == "" {
+		return LastSession(sessions)
+	}
+	for i := range sessions {
+		if sessions[i].ID == id {
+			return &sessions[i]
+		}
+	}
+	return nil
+}
+
+// sessionLineRange returns the lines of the chat history that belong to the
+// session with the given ID: from its header up to the next session's, or to
+// the end of the file if end is -1.
+func sessionLineRange(data []byte, sessionID string) (start, end int, ok bool) {
+	sessions := ParseChatHistory(data)
+	for i := range sessions {
+		if sessions[i].ID != sessionID {
+			continue
+		}
+		end = -1
+		if i+1 < len(sessions) {
+			end = sessions[i+1].StartLine
+		}
+		return sessions[i].StartLine, end, true
+	}
+	return 0, 0, false
+}
+
+// SessionTranscript returns the part of the chat history that belongs to the
+// session with the given ID, and the line it starts at. Every Aider run in a
+// repository appends to the same history file, so checkpoints store only
+// their session's part. Returns data and 0 if the session isn't found.
+func SessionTranscript(data []byte, sessionID string) ([]byte, int) {
+	start, end, ok := sessionLineRange(data, sessionID)
+	if !ok {
+		return data, 0
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if end < 0 || end > len(lines) {
+		end = len(lines)
+	}
+	return []byte(strings.Join(lines[start:end], "")), start
+}
+
+// MergeSessionTranscript returns the chat history existing with the session's
+// part replaced by its part of transcript, or appended if existing doesn't
+// have the session, so restoring one session keeps the others.
+func MergeSessionTranscript(existing, transcript []byte, sessionID string) []byte {
+	section, _ := SessionTranscript(transcript, sessionID)
+	start, end, ok := sessionLineRange(existing, sessionID)
+	if !ok {
+		if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+			existing = append(existing, '\n')
+		}
+		return append(existing, section...)
+	}
+	lines := strings.SplitAfter(string(existing), "\n")
+	if end < 0 || end > len(lines) {
+		end = len(lines)
+	}
+	rest := strings.Join(lines[end:], "")
+	if rest != "" && len(section) > 0 && !strings.HasSuffix(string(section), "\n") {
+		section = append(section, '\n')
+	}
+	return []byte(strings.Join(lines[:start], "") + string(section) + rest)
+}
+
+// ParseSearchReplaceFiles returns the files targeted by SEARCH/REPLACE blocks.
+// Aider writes the file path on its own line just before the block's code fence.
+func ParseSearchReplaceFiles(text string) []string {
+	lines := strings.Split(text, "\n")
+	var files []string
+	for i, line := range lines {
+		if strings.TrimSpace(line) != searchMarker {
+			continue
+		}
+		if file := searchReplaceFileName(lines[:i]); file != "" {
+			files = mergeFiles(files, []string{file})
+		}
+	}
+	return files
+}
+
+// searchReplaceFileName finds the file name above a SEARCH marker,
+// skipping the opening code fence.
+func searchReplaceFileName(before []string) string {
+	skippedFence := false
+	for j := len(before) - 1; j >= 0; j-- {
+		candidate := strings.TrimSpace(before[j])
+		if candidate == "" {
+			continue
+		}
+		if strings.HasPrefix(candidate, codeFence) && !skippedFence {
+			skippedFence = true
+			continue
+		}
+		candidate = strings.Trim(candidate, "`*: ")
+		if candidate == "" || strings.Contains(candidate, " ") {
+			return ""
+		}
+		return candidate
+	}
+	return ""
+}
+
+// mergeFiles appends files from b to a, skipping duplicates and empty names.
+func mergeFiles(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	result := make([]string, 0, len(a)+len(b))
+	for _, file := range append(append([]string{}, a...), b...) {
+		if file == "" || seen[file] {
+			continue
+		}
+		seen[file] = true
+		result = append(result, file)
+	}
+	return result
+}
+
+// ExtractModifiedFiles returns the files edited across the given exchanges.
+func ExtractModifiedFiles(exchanges []Exchange) []string {
+	var files []string
+	for _, exchange := range exchanges {
+		files = mergeFiles(files, exchange.ModifiedFiles)
+	}
+	return files
+}
+
+// ExtractAllUserPrompts extracts the prompts of the most recent session in the history.
+// Callers capturing a session pass only its part of the history (see SessionTranscript).
+func ExtractAllUserPrompts(data []byte) ([]string, error) {
+	session := LastSession(ParseChatHistory(data))
+	if session == nil {
+		return nil, nil
+	}
+	prompts := make([]string, 0, len(session.Exchanges))
+	for _, exchange := range session.Exchanges {
+		if prompt := strings.TrimSpace(exchange.Prompt); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts, nil
+}
+
+// ExtractLastAssistantMessage extracts Aider's last response in the most recent session.
+func ExtractLastAssistantMessage(data []byte) (string, error) {
+	session := LastSession(ParseChatHistory(data))
+	if session == nil {
+		return "", nil
+	}
+	for i := len(session.Exchanges) - 1; i >= 0; i-- {
+		if session.Exchanges[i].Response != "" {
+			return session.Exchanges[i].Response, nil
+		}
+	}
+	return "", nil
+}
+
+// SessionEntries converts a chat session into normalized session entries.
+func SessionEntries(session *ChatSession) []agent.SessionEntry {
+	var entries []agent.SessionEntry
+	for i, exchange := range session.Exchanges {
+		entries = append(entries, agent.SessionEntry{
+			UUID:      fmt.Sprintf("%s-%d-user", session.ID, i),
+			Type:      agent.EntryUser,
+			Timestamp: session.StartedAt,
+			Content:   exchange.Prompt,
+		})
+		if exchange.Response != "" {
+			entries = append(entries, agent.SessionEntry{
+				UUID:      fmt.Sprintf("%s-%d-assistant", session.ID, i),
+				Type:      agent.EntryAssistant,
+				Timestamp: session.StartedAt,
+				Content:   exchange.Response,
+			})
+		}
+		for j, file := range exchange.ModifiedFiles {
+			entries = append(entries, agent.SessionEntry{
+				UUID:          fmt.Sprintf("%s-%d-edit-%d", session.ID, i, j),
+				Type:          agent.EntryTool,
+				Timestamp:     session.StartedAt,
+				ToolName:      "edit",
+				FilesAffected: []string{file},
+			})
+		}
+	}
+	return entries
+}
+
+// CalculateTokenUsage calculates token usage from Aider's "Tokens:" report lines.
+// Aider prints one report per model call, e.g.
+// "> Tokens: 2.1k sent, 1.5k cache write, 512 cache hit, 150 received. Cost: ...".
+// Only processes lines from startLine onwards (0-indexed).
+func CalculateTokenUsage(data []byte, startLine int) *agent.TokenUsage {
+	lines := strings.Split(string(data), "\n")
+	if startLine > 0 {
+		if startLine >= len(lines) {
+			return &agent.TokenUsage{}
+		}
+		lines = lines[startLine:]
+	}
+
+	usage := &agent.TokenUsage{}
+	for _, line := range lines {
+		text := strings.TrimSpace(strings.TrimPrefix(line, toolOutputPrefix))
+		report, ok := strings.CutPrefix(text, tokensPrefix)
+		if !ok {
+			continue
+		}
+		if idx := strings.Index(report, ". Cost"); idx >= 0 {
+			report = report[:idx]
+		}
+		usage.APICallCount++
+		for _, part := range strings.Split(strings.TrimSuffix(report, "."), ",") {
+			count, label, found := strings.Cut(strings.TrimSpace(part), " ")
+			if !found {
+				continue
+			}
+			n := parseTokenCount(count)
+			switch label {
+			case "sent":
+				usage.InputTokens += n
+			case "received":
+				usage.OutputTokens += n
+			case "cache write":
+				usage.CacheCreationTokens += n
+			case "cache hit":
+				usage.CacheReadTokens += n
+			}
+		}
+	}
+
+	return usage
+}
+
+// parseTokenCount parses Aider's abbreviated counts ("512", "2.1k", "1.2M").
+// Returns 0 if the count can't be parsed.
+func parseTokenCount(s string) int {
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(s, "k"):
+		multiplier = 1_000
+		s = strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1_000_000
+		s = strings.TrimSuffix(s, "M")
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return int(value * multiplier)
+}
+
+// CalculateTokenUsageFromFile calculates token usage from an Aider chat history file.
+// If startLine > 0, only considers lines from startLine onwards.
+func CalculateTokenUsageFromFile(path string, startLine int) (*agent.TokenUsage, error) {
+	if path == "" {
+		return &agent.TokenUsage{}, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Reading from controlled chat history path
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+
+	return CalculateTokenUsage(data, startLine), nil
+}
@@ -0,0 +1,253 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+const testHistory = `
+# aider chat started at 2025-01-02 10:00:00
+
+> Aider v0.80.0
+> Added main.go to the chat.
+
+#### Add a greeting
+#### and print it
+
+I'll add the greeting.
+
+main.go
+` + "```go" + `
+<<<<<<< SEARCH
+func main() {}
+=======
+func main() { fmt.Println("hi") }
+>>>>>>> REPLACE
+` + "```" + `
+
+> Tokens: 2.1k sent, 1.5k cache write, 512 cache hit, 150 received. Cost: $0.01 message, $0.01 session.
+> Applied edit to main.go
+> Applied edit to README.md
+
+#### Thanks
+
+You're welcome.
+
+> Tokens: 3k sent, 20 received. Cost: $0.01 message, $0.02 session.
+`
+
+func TestParseChatHistory(t *testing.T) {
+	t.Parallel()
+
+	sessions := ParseChatHistory([]byte(testHistory))
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+
+	session := sessions[0]
+	if session.ID != "aider-2025-01-02T10-00-00" {
+		t.Errorf("ID = %q, want %q", session.ID, "aider-2025-01-02T10-00-00")
+	}
+	if session.StartLine != 1 {
+		t.Errorf("StartLine = %d, want 1", session.StartLine)
+	}
+	if len(session.Exchanges) != 2 {
+		t.Fatalf("got %d exchanges, want 2", len(session.Exchanges))
+	}
+
+	first := session.Exchanges[0]
+	if first.Prompt != "Add a greeting\nand print it" {
+		t.Errorf("Prompt = %q", first.Prompt)
+	}
+	if len(first.ModifiedFiles) != 2 || first.ModifiedFiles[0] != "main.go" || first.ModifiedFiles[1] != "README.md" {
+		t.Errorf("ModifiedFiles = %v, want [main.go README.md]", first.ModifiedFiles)
+	}
+	if !first.Complete() {
+		t.Error("first exchange should be complete")
+	}
+	if session.Exchanges[1].Response != "You're welcome." {
+		t.Errorf("Response = %q, want %q", session.Exchanges[1].Response, "You're welcome.")
+	}
+}
+
+func TestParseChatHistory_MultipleSessions(t *testing.T) {
+	t.Parallel()
+
+	data := testHistory + "\n# aider chat started at 2025-01-03 09:30:00\n\n#### Next task\n"
+	sessions := ParseChatHistory([]byte(data))
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+
+	last := LastSession(sessions)
+	if last.ID != "aider-2025-01-03T09-30-00" {
+		t.Errorf("last ID = %q", last.ID)
+	}
+	if len(last.Exchanges) != 1 || last.Exchanges[0].Complete() {
+		t.Errorf("last session exchanges = %+v, want one unanswered prompt", last.Exchanges)
+	}
+	if FindSession(sessions, "aider-2025-01-02T10-00-00") != &sessions[0] {
+		t.Error("FindSession() did not return the first session")
+	}
+	if FindSession(sessions, "missing") != nil {
+		t.Error("FindSession() should return nil for unknown IDs")
+	}
+}
+
+func TestParseChatHistory_Fragment(t *testing.T) {
+	t.Parallel()
+
+	sessions := ParseChatHistory([]byte("#### Fix it\n\nDone.\n"))
+	if len(sessions) != 1 || sessions[0].ID != defaultSessionID {
+		t.Fatalf("sessions = %+v, want one default session", sessions)
+	}
+}
+
+func TestSessionTranscript(t *testing.T) {
+	t.Parallel()
+
+	second := "# aider chat started at 2025-01-03 09:30:00\n\n#### Next task\n"
+	data := []byte(testHistory + "\n" + second)
+
+	first, start := SessionTranscript(data, "aider-2025-01-02T10-00-00")
+	// testHistory opens with a blank line, so the session starts at line 1.
+	if string(first) != testHistory[1:]+"\n" || start != 1 {
+		t.Errorf("first session = %q at %d, want the first part at 1", first, start)
+	}
+	prompts, err := ExtractAllUserPrompts(first)
+	if err != nil || len(prompts) != 2 {
+		t.Errorf("ExtractAllUserPrompts() = %v, %v; want the first session's prompts", prompts, err)
+	}
+
+	last, start := SessionTranscript(data, "aider-2025-01-03T09-30-00")
+	if string(last) != second || start != strings.Count(testHistory, "\n")+1 {
+		t.Errorf("last session = %q at %d, want %q", last, start, second)
+	}
+
+	if all, start := SessionTranscript(data, "missing"); string(all) != string(data) || start != 0 {
+		t.Errorf("unknown session = %q at %d, want the whole history", all, start)
+	}
+}
+
+func TestMergeSessionTranscript(t *testing.T) {
+	t.Parallel()
+
+	second := "# aider chat started at 2025-01-03 09:30:00\n\n#### Next task\n"
+	data := testHistory + "\n" + second
+	edited := strings.Replace(data, "Thanks", "Thanks again", 1)
+
+	// The session's part is replaced; the other session is kept.
+	merged := MergeSessionTranscript([]byte(edited), []byte(data), "aider-2025-01-02T10-00-00")
+	if string(merged) != data {
+		t.Errorf("merged = %q, want %q", merged, data)
+	}
+
+	// A session missing from the history is appended.
+	merged = MergeSessionTranscript([]byte(strings.TrimSuffix(testHistory, "\n")), []byte(data), "aider-2025-01-03T09-30-00")
+	if string(merged) != testHistory+second {
+		t.Errorf("merged = %q, want %q", merged, testHistory+second)
+	}
+}
+
+func TestParseSearchReplaceFiles(t *testing.T) {
+	t.Parallel()
+
+	text := "Changes:\n\nsrc/app.py\n```python\n<<<<<<< SEARCH\na\n=======\nb\n>>>>>>> REPLACE\n```\n\n`src/app.py`\n```python\n<<<<<<< SEARCH\nc\n=======\nd\n>>>>>>> REPLACE\n```\n\nsrc/util.py\n```\n<<<<<<< SEARCH\n=======\nnew\n>>>>>>> REPLACE\n```\n"
+	files := ParseSearchReplaceFiles(text)
+	if len(files) != 2 || files[0] != "src/app.py" || files[1] != "src/util.py" {
+		t.Errorf("ParseSearchReplaceFiles() = %v, want [src/app.py src/util.py]", files)
+	}
+}
+
+func TestExtractAllUserPrompts(t *testing.T) {
+	t.Parallel()
+
+	prompts, err := ExtractAllUserPrompts([]byte(testHistory))
+	if err != nil {
+		t.Fatalf("ExtractAllUserPrompts() error = %v", err)
+	}
+	if len(prompts) != 2 || prompts[0] != "Add a greeting\nand print it" || prompts[1] != "Thanks" {
+		t.Errorf("prompts = %q", prompts)
+	}
+}
+
+func TestExtractLastAssistantMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := ExtractLastAssistantMessage([]byte(testHistory))
+	if err != nil {
+		t.Fatalf("ExtractLastAssistantMessage() error = %v", err)
+	}
+	if msg != "You're welcome." {
+		t.Errorf("ExtractLastAssistantMessage() = %q, want %q", msg, "You're welcome.")
+	}
+}
+
+func TestCalculateTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	usage := CalculateTokenUsage([]byte(testHistory), 0)
+	if usage.APICallCount != 2 {
+		t.Errorf("APICallCount = %d, want 2", usage.APICallCount)
+	}
+	if usage.InputTokens != 5100 {
+		t.Errorf("InputTokens = %d, want 5100", usage.InputTokens)
+	}
+	if usage.OutputTokens != 170 {
+		t.Errorf("OutputTokens = %d, want 170", usage.OutputTokens)
+	}
+	if usage.CacheCreationTokens != 1500 {
+		t.Errorf("CacheCreationTokens = %d, want 1500", usage.CacheCreationTokens)
+	}
+	if usage.CacheReadTokens != 512 {
+		t.Errorf("CacheReadTokens = %d, want 512", usage.CacheReadTokens)
+	}
+}
+
+func TestCalculateTokenUsage_FromOffset(t *testing.T) {
+	t.Parallel()
+
+	// Line 24 is the "#### Thanks" prompt
+	usage := CalculateTokenUsage([]byte(testHistory), 24)
+	if usage.APICallCount != 1 || usage.InputTokens != 3000 {
+		t.Errorf("usage = %+v, want one call with 3000 input tokens", usage)
+	}
+}
+
+func TestAiderAgent_CalculateTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), ".aider.chat.history.md")
+	if err := os.WriteFile(path, []byte(testHistory), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var calculator agent.TokenUsageCalculator = &AiderAgent{}
+	usage, err := calculator.CalculateTokenUsage(path, 24)
+	if err != nil {
+		t.Fatalf("CalculateTokenUsage() error = %v", err)
+	}
+	if usage.APICallCount != 1 || usage.InputTokens != 3000 {
+		t.Errorf("usage = %+v, want one call with 3000 input tokens", usage)
+	}
+}
+
+func TestParseTokenCount(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"512":  512,
+		"2.1k": 2100,
+		"12k":  12000,
+		"1.2M": 1200000,
+		"n/a":  0,
+	}
+	for input, want := range tests {
+		if got := parseTokenCount(input); got != want {
+			t.Errorf("parseTokenCount(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
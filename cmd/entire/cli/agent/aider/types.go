@@ -0,0 +1,60 @@
+package aider
+
+import "time"
+
+// File names Aider writes in the repository root.
+const (
+	// ChatHistoryFileName is the markdown log of every chat, appended across sessions.
+	ChatHistoryFileName = ".aider.chat.history.md"
+
+	// InputHistoryFileName records each prompt the user submits.
+	InputHistoryFileName = ".aider.input.history"
+
+	// ConfigFileName is Aider's optional per-project config file.
+	ConfigFileName = ".aider.conf.yml"
+)
+
+// Chat history markers.
+const (
+	sessionHeaderPrefix = "# aider chat started at "
+	userPromptPrefix    = "#### "
+	toolOutputPrefix    = ">"
+	appliedEditPrefix   = "Applied edit to "
+	tokensPrefix        = "Tokens: "
+	searchMarker        = "<<<<<<< SEARCH"
+	codeFence           = "```"
+)
+
+// sessionTimeLayout is the timestamp format of the session header.
+const sessionTimeLayout = "2006-01-02 15:04:05"
+
+// defaultSessionID identifies history that precedes any session header.
+const defaultSessionID = "aider-session"
+
+// ChatSession is one `aider` run in the chat history file.
+type ChatSession struct {
+	ID        string
+	StartedAt time.Time
+
+	// StartLine is the 0-indexed line of the session header in the history file.
+	StartLine int
+
+	Exchanges []Exchange
+}
+
+// Exchange is one user prompt and everything Aider wrote in response.
+type Exchange struct {
+	Prompt   string
+	Response string
+
+	// ToolOutput holds Aider's own status lines (the "> " blockquotes).
+	ToolOutput []string
+
+	// ModifiedFiles lists files edited during the exchange.
+	ModifiedFiles []string
+}
+
+// Complete reports whether Aider has responded to the prompt.
+func (e Exchange) Complete() bool {
+	return e.Response != "" || len(e.ModifiedFiles) > 0
+}
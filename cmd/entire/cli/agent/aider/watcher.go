@@ -0,0 +1,190 @@
+package aider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Ensure AiderAgent implements FileWatcher, TranscriptAnalyzer and TranscriptChunker
+var (
+	_ agent.FileWatcher          = (*AiderAgent)(nil)
+	_ agent.TokenUsageCalculator = (*AiderAgent)(nil)
+	_ agent.TranscriptAnalyzer   = (*AiderAgent)(nil)
+	_ agent.TranscriptChunker    = (*AiderAgent)(nil)
+	_ agent.TranscriptNormalizer = (*AiderAgent)(nil)
+)
+
+// watchTracker remembers, per session, how many exchanges have already been
+// reported as finished turns, so repeated writes to the history don't replay them.
+type watchTracker struct {
+	mu            sync.Mutex
+	sessions      map[string]*sessionProgress
+	lastSessionID string
+}
+
+// sessionProgress is the watch state of one chat session.
+type sessionProgress struct {
+	ended  int  // number of exchanges reported as finished
+	inTurn bool // a turn start has been reported but not its end
+}
+
+func newWatchTracker() *watchTracker {
+	return &watchTracker{sessions: make(map[string]*sessionProgress)}
+}
+
+// GetWatchPaths returns the chat and input history files in the repository root.
+// It also records how far the existing history goes, so exchanges from before
+// the watcher started are not reported as new turns.
+func (a *AiderAgent) GetWatchPaths() ([]string, error) {
+	repoRoot := repoRootOrCwd()
+	chatPath := filepath.Join(repoRoot, ChatHistoryFileName)
+
+	data, err := os.ReadFile(chatPath) //nolint:gosec // Path is repo root + fixed file name
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+	a.tracker().seed(ParseChatHistory(data))
+
+	return []string{chatPath, filepath.Join(repoRoot, InputHistoryFileName)}, nil
+}
+
+// OnFileChange turns a change to Aider's history files into a session event:
+//   - a new prompt (input history, or an unanswered #### prompt) starts a turn
+//   - a prompt that has been answered ends the turn
+//   - a new session header ends the previous session
+//
+// Returns nil if the change doesn't mark a turn boundary.
+func (a *AiderAgent) OnFileChange(path string) (*agent.SessionChange, error) {
+	chatPath := filepath.Join(filepath.Dir(path), ChatHistoryFileName)
+	data, err := os.ReadFile(chatPath) //nolint:gosec // Path is a sibling of a watched Aider file
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil //nolint:nilnil // No history yet means no session activity
+		}
+		return nil, fmt.Errorf("failed to read chat history: %w", err)
+	}
+
+	session := LastSession(ParseChatHistory(data))
+	if session == nil {
+		return nil, nil //nolint:nilnil // Empty history means no session activity
+	}
+
+	var eventType agent.HookType
+	sessionID := session.ID
+	switch filepath.Base(path) {
+	case InputHistoryFileName:
+		eventType = a.tracker().promptSubmitted(session)
+	case ChatHistoryFileName:
+		eventType, sessionID = a.tracker().historyChanged(session)
+	default:
+		return nil, nil //nolint:nilnil // Not an Aider history file
+	}
+	if eventType == "" {
+		return nil, nil //nolint:nilnil // Change is not a turn boundary
+	}
+
+	return &agent.SessionChange{
+		SessionID:  sessionID,
+		SessionRef: chatPath,
+		EventType:  eventType,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// tracker returns the agent's watch tracker, creating it for zero-value agents.
+func (a *AiderAgent) tracker() *watchTracker {
+	if a.watch == nil {
+		a.watch = newWatchTracker()
+	}
+	return a.watch
+}
+
+// seed marks every finished exchange of the latest session as already reported.
+func (t *watchTracker) seed(sessions []ChatSession) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	session := LastSession(sessions)
+	if session == nil {
+		return
+	}
+	progress := t.progress(session.ID)
+	progress.ended = completedExchanges(session)
+	t.lastSessionID = session.ID
+}
+
+// promptSubmitted handles a new entry in the input history.
+func (t *watchTracker) promptSubmitted(session *ChatSession) agent.HookType {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastSessionID = session.ID
+	progress := t.progress(session.ID)
+	// Already in a turn, or the prompt was answered before this change was processed
+	if progress.inTurn || (len(session.Exchanges) > 0 && len(session.Exchanges) <= progress.ended) {
+		return ""
+	}
+	progress.inTurn = true
+	return agent.HookUserPromptSubmit
+}
+
+// historyChanged handles a write to the chat history and returns the event
+// and the session it applies to.
+func (t *watchTracker) historyChanged(session *ChatSession) (agent.HookType, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previousID := t.lastSessionID
+	t.lastSessionID = session.ID
+	_, known := t.sessions[session.ID]
+	progress := t.progress(session.ID)
+
+	if !known && len(session.Exchanges) == 0 {
+		if previousID != "" && previousID != session.ID {
+			delete(t.sessions, previousID)
+			return agent.HookSessionEnd, previousID
+		}
+		return agent.HookSessionStart, session.ID
+	}
+
+	n := len(session.Exchanges)
+	if n == 0 || n <= progress.ended {
+		return "", session.ID
+	}
+	if session.Exchanges[n-1].Complete() {
+		progress.ended = n
+		progress.inTurn = false
+		return agent.HookStop, session.ID
+	}
+	if !progress.inTurn {
+		progress.inTurn = true
+		return agent.HookUserPromptSubmit, session.ID
+	}
+	return "", session.ID
+}
+
+// progress returns the progress record for a session, creating it if needed.
+// Callers must hold t.mu.
+func (t *watchTracker) progress(sessionID string) *sessionProgress {
+	progress, ok := t.sessions[sessionID]
+	if !ok {
+		progress = &sessionProgress{}
+		t.sessions[sessionID] = progress
+	}
+	return progress
+}
+
+// completedExchanges counts the exchanges up to and including the last answered one.
+func completedExchanges(session *ChatSession) int {
+	for i := len(session.Exchanges) - 1; i >= 0; i-- {
+		if session.Exchanges[i].Complete() {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package aider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestOnFileChange_TurnLifecycle(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	chatPath := filepath.Join(tempDir, ChatHistoryFileName)
+	inputPath := filepath.Join(tempDir, InputHistoryFileName)
+	header := "\n# aider chat started at 2025-01-02 10:00:00\n\n"
+
+	writeHistory(t, chatPath, "")
+	ag := &AiderAgent{}
+	if _, err := ag.GetWatchPaths(); err != nil {
+		t.Fatalf("GetWatchPaths() error = %v", err)
+	}
+
+	// Aider starts and writes the session header
+	writeHistory(t, chatPath, header)
+	expectChange(t, ag, chatPath, agent.HookSessionStart, "aider-2025-01-02T10-00-00")
+
+	// The user submits a prompt
+	writeHistory(t, chatPath, header+"#### Add a test\n\n")
+	expectChange(t, ag, inputPath, agent.HookUserPromptSubmit, "aider-2025-01-02T10-00-00")
+	expectChange(t, ag, chatPath, "", "")
+
+	// Aider answers and applies its edit
+	writeHistory(t, chatPath, header+"#### Add a test\n\nAdded.\n\n> Applied edit to a_test.go\n")
+	expectChange(t, ag, chatPath, agent.HookStop, "aider-2025-01-02T10-00-00")
+
+	// Further writes without a new prompt don't replay the turn
+	expectChange(t, ag, chatPath, "", "")
+
+	// A prompt answered before the watcher saw it only ends the turn
+	writeHistory(t, chatPath, header+"#### Add a test\n\nAdded.\n\n#### Thanks\n\nSure.\n")
+	expectChange(t, ag, chatPath, agent.HookStop, "aider-2025-01-02T10-00-00")
+	expectChange(t, ag, inputPath, "", "")
+}
+
+func TestOnFileChange_NewSessionEndsPrevious(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	chatPath := filepath.Join(tempDir, ChatHistoryFileName)
+	first := "\n# aider chat started at 2025-01-02 10:00:00\n\n#### Hi\n\nHello.\n"
+	writeHistory(t, chatPath, first)
+
+	ag := &AiderAgent{}
+	if _, err := ag.GetWatchPaths(); err != nil {
+		t.Fatalf("GetWatchPaths() error = %v", err)
+	}
+
+	// Existing exchanges are not reported again
+	expectChange(t, ag, chatPath, "", "")
+
+	writeHistory(t, chatPath, first+"\n# aider chat started at 2025-01-02 11:00:00\n\n")
+	expectChange(t, ag, chatPath, agent.HookSessionEnd, "aider-2025-01-02T10-00-00")
+}
+
+func TestOnFileChange_IgnoresOtherFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	writeHistory(t, filepath.Join(tempDir, ChatHistoryFileName), "#### Hi\n")
+
+	ag := &AiderAgent{}
+	change, err := ag.OnFileChange(filepath.Join(tempDir, "main.go"))
+	if err != nil {
+		t.Fatalf("OnFileChange() error = %v", err)
+	}
+	if change != nil {
+		t.Errorf("OnFileChange() = %+v, want nil", change)
+	}
+}
+
+func writeHistory(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// expectChange calls OnFileChange and checks the reported event; an empty
+// eventType means no change should be reported.
+func expectChange(t *testing.T, ag *AiderAgent, path string, eventType agent.HookType, sessionID string) {
+	t.Helper()
+	change, err := ag.OnFileChange(path)
+	if err != nil {
+		t.Fatalf("OnFileChange(%s) error = %v", filepath.Base(path), err)
+	}
+	if eventType == "" {
+		if change != nil {
+			t.Fatalf("OnFileChange(%s) = %+v, want nil", filepath.Base(path), change)
+		}
+		return
+	}
+	if change == nil {
+		t.Fatalf("OnFileChange(%s) = nil, want %s", filepath.Base(path), eventType)
+	}
+	if change.EventType != eventType || change.SessionID != sessionID {
+		t.Errorf("OnFileChange(%s) = %s/%s, want %s/%s", filepath.Base(path), change.EventType, change.SessionID, eventType, sessionID)
+	}
+}
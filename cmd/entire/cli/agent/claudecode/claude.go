@@ -163,6 +163,9 @@ func (c *ClaudeCodeAgent) GetSessionDir(repoPath string) (string, error) {
 	if override := os.Getenv("ENTIRE_TEST_CLAUDE_PROJECT_DIR"); override != "" {
 		return override, nil
 	}
+	if override := agent.SessionDirOverride(); override != "" {
+		return override, nil
+	}
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
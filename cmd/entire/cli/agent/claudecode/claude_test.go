@@ -66,3 +66,18 @@ func TestParseHookInput_SessionStart_NoPrompt(t *testing.T) {
 		t.Errorf("UserPrompt = %q, want empty", result.UserPrompt)
 	}
 }
+
+func TestGetSessionDir_Override(t *testing.T) {
+	t.Setenv("ENTIRE_TEST_CLAUDE_PROJECT_DIR", "")
+	ag := &ClaudeCodeAgent{}
+	restore := agent.OverrideSessionDir("/tmp/simulated")
+	dir, err := ag.GetSessionDir("/repo")
+	restore()
+	if err != nil || dir != "/tmp/simulated" {
+		t.Errorf("GetSessionDir() = %q, %v; want the overridden directory", dir, err)
+	}
+
+	if dir, err := ag.GetSessionDir("/repo"); err != nil || dir == "/tmp/simulated" {
+		t.Errorf("GetSessionDir() after restore = %q, %v; want Claude's project directory", dir, err)
+	}
+}
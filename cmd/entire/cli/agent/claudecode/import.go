@@ -0,0 +1,106 @@
+package claudecode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+
+	"github.com/google/uuid"
+)
+
+// Ensure ClaudeCodeAgent implements SessionImporter
+var _ agent.SessionImporter = (*ClaudeCodeAgent)(nil)
+
+// importLine is a Claude Code transcript line with the fields Claude needs to
+// rebuild the conversation on resume: lines are chained through parentUuid.
+type importLine struct {
+	ParentUUID  *string         `json:"parentUuid"`
+	IsSidechain bool            `json:"isSidechain"`
+	UserType    string          `json:"userType"`
+	Cwd         string          `json:"cwd"`
+	SessionID   string          `json:"sessionId"`
+	Type        string          `json:"type"`
+	Message     json.RawMessage `json:"message"`
+	UUID        string          `json:"uuid"`
+	Timestamp   string          `json:"timestamp"`
+}
+
+// ImportSession builds a Claude Code session from another agent's normalized entries.
+func (c *ClaudeCodeAgent) ImportSession(repoPath, sessionID string, entries []agent.SessionEntry) (*agent.AgentSession, error) {
+	data, err := BuildTranscript(sessionID, repoPath, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionDir, err := c.GetSessionDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &agent.AgentSession{
+		SessionID:  sessionID,
+		AgentName:  c.Name(),
+		RepoPath:   repoPath,
+		SessionRef: c.ResolveSessionFile(sessionDir, sessionID),
+		StartTime:  time.Now(),
+		NativeData: data,
+	}, nil
+}
+
+// BuildTranscript renders normalized entries as Claude Code JSONL.
+// The conversation is reduced to user and assistant messages (see agent.HandoffMessages).
+func BuildTranscript(sessionID, cwd string, entries []agent.SessionEntry) ([]byte, error) {
+	messages := agent.HandoffMessages(entries)
+	if len(messages) == 0 {
+		return nil, errors.New("session has no messages to import")
+	}
+
+	var buf bytes.Buffer
+	var parent *string
+	now := time.Now().UTC()
+
+	for _, msg := range messages {
+		var body any
+		lineType := "user"
+		if msg.Type == agent.EntryAssistant {
+			lineType = "assistant"
+			body = map[string]any{
+				"role":    "assistant",
+				"content": []map[string]string{{"type": "text", "text": msg.Content}},
+			}
+		} else {
+			body = map[string]any{"role": "user", "content": msg.Content}
+		}
+		message, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message: %w", err)
+		}
+
+		timestamp := msg.Timestamp
+		if timestamp.IsZero() {
+			timestamp = now
+		}
+		lineUUID := uuid.NewString()
+		line, err := json.Marshal(importLine{
+			ParentUUID: parent,
+			UserType:   "external",
+			Cwd:        cwd,
+			SessionID:  sessionID,
+			Type:       lineType,
+			Message:    message,
+			UUID:       lineUUID,
+			Timestamp:  timestamp.UTC().Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal line: %w", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+		parent = &lineUUID
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,79 @@
+package claudecode
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestBuildTranscript_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	entries := []agent.SessionEntry{
+		{Type: agent.EntryUser, Content: "Add a README"},
+		{Type: agent.EntryAssistant, Content: "Adding it."},
+		{Type: agent.EntryTool, ToolName: "write_file", FilesAffected: []string{"README.md"}},
+		{Type: agent.EntryUser, Content: "Thanks"},
+	}
+
+	data, err := BuildTranscript("sess-1", "/repo", entries)
+	if err != nil {
+		t.Fatalf("BuildTranscript() error = %v", err)
+	}
+
+	lines, err := ParseTranscript(data)
+	if err != nil {
+		t.Fatalf("ParseTranscript() error = %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if got := ExtractLastUserPrompt(lines); got != "Thanks" {
+		t.Errorf("ExtractLastUserPrompt() = %q, want Thanks", got)
+	}
+
+	// Each line must chain to the previous one so Claude can rebuild the conversation
+	var prev *string
+	for i, raw := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var line importLine
+		if err := json.Unmarshal(raw, &line); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if (prev == nil) != (line.ParentUUID == nil) || (prev != nil && *prev != *line.ParentUUID) {
+			t.Errorf("line %d parentUuid = %v, want %v", i, line.ParentUUID, prev)
+		}
+		if line.SessionID != "sess-1" || line.Cwd != "/repo" {
+			t.Errorf("line %d = %+v", i, line)
+		}
+		uuid := line.UUID
+		prev = &uuid
+	}
+
+	normalized, err := (&ClaudeCodeAgent{}).NormalizeTranscript(data)
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+	if len(normalized) != 3 || normalized[1].Content != "Adding it.\n\n[Tool: write_file README.md]" {
+		t.Errorf("normalized = %+v", normalized)
+	}
+}
+
+func TestImportSession(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ENTIRE_TEST_CLAUDE_PROJECT_DIR", dir)
+
+	sess, err := (&ClaudeCodeAgent{}).ImportSession("/repo", "sess-1", []agent.SessionEntry{{Type: agent.EntryUser, Content: "Hi"}})
+	if err != nil {
+		t.Fatalf("ImportSession() error = %v", err)
+	}
+	if sess.SessionRef != filepath.Join(dir, "sess-1.jsonl") || sess.AgentName != agent.AgentNameClaudeCode {
+		t.Errorf("unexpected session: %+v", sess)
+	}
+
+	if _, err := (&ClaudeCodeAgent{}).ImportSession("/repo", "sess-2", nil); err == nil {
+		t.Error("ImportSession() should fail without messages")
+	}
+}
@@ -0,0 +1,160 @@
+package claudecode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/transcript"
+)
+
+// Ensure ClaudeCodeAgent implements TranscriptNormalizer
+var _ agent.TranscriptNormalizer = (*ClaudeCodeAgent)(nil)
+
+// normalizeLine is a transcript line with the fields normalization needs
+// beyond transcript.Line.
+type normalizeLine struct {
+	Type      string          `json:"type"`
+	UUID      string          `json:"uuid"`
+	Timestamp time.Time       `json:"timestamp"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// normalizeBlock is a message content block, including the tool_use and
+// tool_result fields that transcript.ContentBlock omits.
+type normalizeBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
+}
+
+// NormalizeTranscript converts Claude Code JSONL into session entries.
+// Each assistant text block and tool_use block becomes its own entry; tool_result
+// blocks in user messages are attached to the tool entry with the matching ID.
+func (c *ClaudeCodeAgent) NormalizeTranscript(content []byte) ([]agent.SessionEntry, error) {
+	var entries []agent.SessionEntry
+	toolEntries := make(map[string]int) // tool_use ID -> index in entries
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, scannerBufferSize), scannerBufferSize)
+
+	for scanner.Scan() {
+		var line normalizeLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue // Skip malformed lines
+		}
+
+		switch line.Type {
+		case transcript.TypeUser:
+			var msg struct {
+				Content json.RawMessage `json:"content"`
+			}
+			if err := json.Unmarshal(line.Message, &msg); err == nil {
+				var blocks []normalizeBlock
+				if json.Unmarshal(msg.Content, &blocks) == nil {
+					for _, block := range blocks {
+						if block.Type != "tool_result" {
+							continue
+						}
+						if i, ok := toolEntries[block.ToolUseID]; ok {
+							entries[i].ToolOutput = decodeToolValue(block.Content)
+						}
+					}
+				}
+			}
+			if text := transcript.ExtractUserContent(line.Message); text != "" {
+				entries = append(entries, agent.SessionEntry{
+					UUID:      line.UUID,
+					Type:      agent.EntryUser,
+					Timestamp: line.Timestamp,
+					Content:   text,
+				})
+			}
+
+		case transcript.TypeAssistant:
+			var msg struct {
+				Content []normalizeBlock `json:"content"`
+			}
+			if err := json.Unmarshal(line.Message, &msg); err != nil {
+				continue
+			}
+			for _, block := range msg.Content {
+				switch block.Type {
+				case transcript.ContentTypeText:
+					if block.Text == "" {
+						continue
+					}
+					entries = append(entries, agent.SessionEntry{
+						UUID:      line.UUID,
+						Type:      agent.EntryAssistant,
+						Timestamp: line.Timestamp,
+						Content:   block.Text,
+					})
+				case transcript.ContentTypeToolUse:
+					entry := agent.SessionEntry{
+						UUID:      line.UUID,
+						Type:      agent.EntryTool,
+						Timestamp: line.Timestamp,
+						ToolName:  block.Name,
+						ToolInput: decodeToolValue(block.Input),
+					}
+					if slices.Contains(FileModificationTools, block.Name) {
+						var input toolInput
+						if json.Unmarshal(block.Input, &input) == nil {
+							file := input.FilePath
+							if file == "" {
+								file = input.NotebookPath
+							}
+							if file != "" {
+								entry.FilesAffected = []string{file}
+							}
+						}
+					}
+					if block.ID != "" {
+						toolEntries[block.ID] = len(entries)
+					}
+					entries = append(entries, entry)
+				}
+			}
+
+		case "system":
+			var msg struct {
+				Content string `json:"content"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &msg) == nil && msg.Content != "" {
+				entries = append(entries, agent.SessionEntry{
+					UUID:      line.UUID,
+					Type:      agent.EntrySystem,
+					Timestamp: line.Timestamp,
+					Content:   msg.Content,
+				})
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan transcript: %w", err)
+	}
+	return entries, nil
+}
+
+// decodeToolValue decodes raw tool input or output JSON into a generic value.
+// Returns nil for empty input, and the raw string if it isn't valid JSON.
+func decodeToolValue(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	return v
+}
@@ -0,0 +1,58 @@
+package claudecode
+
+import (
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestNormalizeTranscript(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{"type":"user","uuid":"u1","timestamp":"2026-01-01T00:00:00Z","message":{"content":"Edit main.go"}}
+{"type":"assistant","uuid":"a1","message":{"content":[{"type":"text","text":"Editing."},{"type":"tool_use","id":"toolu_1","name":"Edit","input":{"file_path":"main.go","old_string":"a","new_string":"b"}}]}}
+{"type":"user","uuid":"u2","message":{"content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"ok"}]}}
+{"type":"assistant","uuid":"a2","message":{"content":[{"type":"tool_use","id":"toolu_2","name":"Bash","input":{"command":"go test"}}]}}
+not json
+{"type":"assistant","uuid":"a3","message":{"content":[{"type":"text","text":"Done."}]}}
+`)
+
+	entries, err := (&ClaudeCodeAgent{}).NormalizeTranscript(data)
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+
+	want := []struct {
+		typ     agent.EntryType
+		content string
+		tool    string
+	}{
+		{agent.EntryUser, "Edit main.go", ""},
+		{agent.EntryAssistant, "Editing.", ""},
+		{agent.EntryTool, "", "Edit"},
+		{agent.EntryTool, "", "Bash"},
+		{agent.EntryAssistant, "Done.", ""},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		if entries[i].Type != w.typ || entries[i].Content != w.content || entries[i].ToolName != w.tool {
+			t.Errorf("entries[%d] = %+v, want %+v", i, entries[i], w)
+		}
+	}
+
+	if entries[0].UUID != "u1" || entries[0].Timestamp.IsZero() {
+		t.Errorf("user entry should keep uuid and timestamp: %+v", entries[0])
+	}
+	edit := entries[2]
+	if len(edit.FilesAffected) != 1 || edit.FilesAffected[0] != "main.go" {
+		t.Errorf("Edit FilesAffected = %v, want [main.go]", edit.FilesAffected)
+	}
+	if edit.ToolOutput != "ok" {
+		t.Errorf("Edit ToolOutput = %v, want tool_result content", edit.ToolOutput)
+	}
+	if len(entries[3].FilesAffected) != 0 || entries[3].ToolOutput != nil {
+		t.Errorf("Bash entry = %+v, want no files and no output", entries[3])
+	}
+}
@@ -0,0 +1,424 @@
+// Package codex implements the Agent interface for OpenAI Codex CLI.
+package codex
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameCodex, NewCodexAgent)
+}
+
+// maxRolloutScan caps how many recent rollout files are inspected when a
+// notification doesn't carry a thread ID.
+const maxRolloutScan = 50
+
+// CodexAgent implements the Agent interface for OpenAI Codex CLI.
+//
+//nolint:revive // CodexAgent is clearer than Agent in this context
+type CodexAgent struct{}
+
+// NewCodexAgent creates a new Codex CLI agent instance.
+func NewCodexAgent() agent.Agent {
+	return &CodexAgent{}
+}
+
+// Name returns the agent registry key.
+func (c *CodexAgent) Name() agent.AgentName {
+	return agent.AgentNameCodex
+}
+
+// Type returns the agent type identifier.
+func (c *CodexAgent) Type() agent.AgentType {
+	return agent.AgentTypeCodex
+}
+
+// Description returns a human-readable description.
+func (c *CodexAgent) Description() string {
+	return "Codex CLI - OpenAI's CLI coding agent"
+}
+
+// DetectPresence checks if Codex CLI is configured in the repository.
+func (c *CodexAgent) DetectPresence() (bool, error) {
+	// Get repo root to check for .codex directory
+	// This is needed because the CLI may be run from a subdirectory
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		// Not in a git repo, fall back to CWD-relative check
+		repoRoot = "."
+	}
+
+	// Check for .codex directory
+	codexDir := filepath.Join(repoRoot, ".codex")
+	if _, err := os.Stat(codexDir); err == nil {
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetHookConfigPath returns the path to Codex's config file.
+func (c *CodexAgent) GetHookConfigPath() string {
+	return ".codex/" + ConfigFileName
+}
+
+// SupportsHooks returns true as Codex supports a turn-complete notify hook.
+func (c *CodexAgent) SupportsHooks() bool {
+	return true
+}
+
+// ParseHookInput parses a Codex notify payload.
+// Codex only emits agent-turn-complete notifications, and each one carries the
+// prompt(s) of the turn, so the same payload backs the session start, user prompt
+// and stop lifecycle events.
+func (c *CodexAgent) ParseHookInput(hookType agent.HookType, reader io.Reader) (*agent.HookInput, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("empty input")
+	}
+
+	var raw notifyPayload
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse notify payload: %w", err)
+	}
+
+	input := &agent.HookInput{
+		HookType:  hookType,
+		SessionID: raw.ThreadID,
+		Timestamp: time.Now(),
+		RawData: map[string]interface{}{
+			"type": raw.Type,
+		},
+	}
+	if raw.TurnID != "" {
+		input.RawData["turn_id"] = raw.TurnID
+	}
+	if raw.Cwd != "" {
+		input.RawData["cwd"] = raw.Cwd
+	}
+	if raw.LastAssistantMessage != "" {
+		input.RawData["last_assistant_message"] = raw.LastAssistantMessage
+	}
+	if len(raw.InputMessages) > 0 {
+		input.UserPrompt = raw.InputMessages[len(raw.InputMessages)-1]
+	}
+
+	sessionDir, err := c.GetSessionDir(raw.Cwd)
+	if err != nil {
+		return input, nil //nolint:nilerr // Rollout path is best-effort; the handler validates it
+	}
+
+	if input.SessionID != "" {
+		input.SessionRef = c.ResolveSessionFile(sessionDir, input.SessionID)
+		return input, nil
+	}
+
+	// Older Codex versions don't send a thread ID; fall back to the most recent
+	// rollout recorded for this working directory.
+	cwd := raw.Cwd
+	if cwd == "" {
+		if root, rootErr := paths.RepoRoot(); rootErr == nil {
+			cwd = root
+		}
+	}
+	if path, sessionID := findLatestRollout(sessionDir, cwd); path != "" {
+		input.SessionID = sessionID
+		input.SessionRef = path
+	}
+
+	return input, nil
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (c *CodexAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// ProtectedDirs returns directories that Codex uses for config/state.
+func (c *CodexAgent) ProtectedDirs() []string { return []string{".codex"} }
+
+// GetSessionDir returns the directory where Codex stores rollout files.
+// Codex keeps rollouts for all projects under $CODEX_HOME/sessions (default ~/.codex/sessions),
+// so repoPath is not part of the path.
+func (c *CodexAgent) GetSessionDir(_ string) (string, error) {
+	// Check for test environment override
+	if override := os.Getenv("ENTIRE_TEST_CODEX_SESSION_DIR"); override != "" {
+		return override, nil
+	}
+
+	if codexHome := os.Getenv("CODEX_HOME"); codexHome != "" {
+		return filepath.Join(codexHome, "sessions"), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".codex", "sessions"), nil
+}
+
+// ResolveSessionFile returns the path to a Codex rollout file.
+// Rollouts are stored as <sessionDir>/YYYY/MM/DD/rollout-<timestamp>-<id>.jsonl,
+// so the session ID alone is not enough to build the path - existing files are
+// searched first, and a dated path for today is returned otherwise.
+func (c *CodexAgent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	for _, pattern := range []string{
+		filepath.Join(sessionDir, "*", "*", "*", "rollout-*-"+agentSessionID+".jsonl"),
+		filepath.Join(sessionDir, "rollout-*-"+agentSessionID+".jsonl"),
+	} {
+		matches, err := filepath.Glob(pattern)
+		if err == nil && len(matches) > 0 {
+			// Return the most recent match (last alphabetically, since date is in the path)
+			return matches[len(matches)-1]
+		}
+	}
+
+	// Fallback: construct a path in today's directory using Codex's naming convention
+	now := time.Now()
+	return filepath.Join(sessionDir, now.Format("2006"), now.Format("01"), now.Format("02"),
+		"rollout-"+now.Format("2006-01-02T15-04-05")+"-"+agentSessionID+".jsonl")
+}
+
+// findLatestRollout returns the path and session ID of the most recently
+// modified rollout whose session_meta cwd matches cwd.
+func findLatestRollout(sessionDir, cwd string) (string, string) {
+	matches, err := filepath.Glob(filepath.Join(sessionDir, "*", "*", "*", "rollout-*.jsonl"))
+	if err != nil || len(matches) == 0 {
+		return "", ""
+	}
+
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+	candidates := make([]candidate, 0, len(matches))
+	for _, match := range matches {
+		info, statErr := os.Stat(match)
+		if statErr != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{path: match, modTime: info.ModTime()})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].modTime.After(candidates[j].modTime)
+	})
+
+	for i, cand := range candidates {
+		if i >= maxRolloutScan {
+			break
+		}
+		meta, metaErr := readSessionMeta(cand.path)
+		if metaErr != nil {
+			continue
+		}
+		if cwd == "" || meta.Cwd == cwd {
+			return cand.path, meta.ID
+		}
+	}
+	return "", ""
+}
+
+// readSessionMeta reads the session_meta line at the top of a rollout file.
+func readSessionMeta(path string) (*sessionMeta, error) {
+	file, err := os.Open(path) //nolint:gosec // Path comes from Codex session directory
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rollout: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, scannerBufferSize), scannerBufferSize)
+	for scanner.Scan() {
+		var line RolloutLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil || line.Type != LineTypeSessionMeta {
+			continue
+		}
+		var meta sessionMeta
+		if err := json.Unmarshal(line.Payload, &meta); err != nil {
+			return nil, fmt.Errorf("failed to parse session_meta: %w", err)
+		}
+		return &meta, nil
+	}
+	return nil, errors.New("no session_meta in rollout")
+}
+
+// ReadSession reads a session from Codex's storage (JSONL rollout file).
+// The session data is stored in NativeData as raw JSONL bytes.
+func (c *CodexAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (rollout path) is required")
+	}
+
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rollout: %w", err)
+	}
+
+	lines, err := ParseRollout(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rollout: %w", err)
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     c.Name(),
+		SessionRef:    input.SessionRef,
+		StartTime:     time.Now(),
+		NativeData:    data,
+		ModifiedFiles: ExtractModifiedFiles(lines),
+	}, nil
+}
+
+// WriteSession writes a session to Codex's storage (JSONL rollout file).
+// Uses the NativeData field which contains raw JSONL bytes.
+func (c *CodexAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Codex
+	if session.AgentName != "" && session.AgentName != c.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, c.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (rollout path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(session.SessionRef), 0o750); err != nil {
+		return fmt.Errorf("failed to create rollout directory: %w", err)
+	}
+
+	if err := os.WriteFile(session.SessionRef, session.NativeData, 0o600); err != nil {
+		return fmt.Errorf("failed to write rollout: %w", err)
+	}
+
+	return nil
+}
+
+// FormatResumeCommand returns the command to resume a Codex session.
+func (c *CodexAgent) FormatResumeCommand(sessionID string) string {
+	return "codex resume " + sessionID
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition returns the current line count of a Codex rollout.
+// Codex uses JSONL format, so position is the number of lines.
+// Returns 0 if the file doesn't exist or is empty.
+func (c *CodexAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	file, err := os.Open(path) //nolint:gosec // Path comes from Codex rollout location
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to open rollout file: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	lineCount := 0
+
+	for {
+		_, err := reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return 0, fmt.Errorf("failed to read rollout: %w", err)
+		}
+		lineCount++
+	}
+
+	return lineCount, nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files modified since a given line number.
+// For Codex (JSONL format), offset is the starting line number.
+// Returns:
+//   - files: list of file paths touched by apply_patch calls
+//   - currentPosition: total number of lines in the file
+//   - error: any error encountered during reading
+func (c *CodexAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+
+	file, openErr := os.Open(path) //nolint:gosec // Path comes from Codex rollout location
+	if openErr != nil {
+		if os.IsNotExist(openErr) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to open rollout file: %w", openErr)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var lines []RolloutLine
+	lineNum := 0
+
+	for {
+		lineData, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, 0, fmt.Errorf("failed to read rollout: %w", readErr)
+		}
+
+		if len(lineData) > 0 {
+			lineNum++
+			if lineNum > startOffset {
+				var line RolloutLine
+				if parseErr := json.Unmarshal(lineData, &line); parseErr == nil {
+					lines = append(lines, line)
+				}
+				// Skip malformed lines silently
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	return ExtractModifiedFiles(lines), lineNum, nil
+}
+
+// TranscriptChunker interface implementation
+
+// ChunkTranscript splits a JSONL rollout at line boundaries.
+func (c *CodexAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk JSONL rollout: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates JSONL chunks with newlines.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (c *CodexAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
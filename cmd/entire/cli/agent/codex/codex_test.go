@@ -0,0 +1,189 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestNewCodexAgent(t *testing.T) {
+	t.Parallel()
+	ag := NewCodexAgent()
+	if ag == nil {
+		t.Fatal("NewCodexAgent() returned nil")
+	}
+	if ag.Name() != agent.AgentNameCodex {
+		t.Errorf("Name() = %q, want %q", ag.Name(), agent.AgentNameCodex)
+	}
+	if ag.Type() != agent.AgentTypeCodex {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeCodex)
+	}
+}
+
+func TestDetectPresence(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CodexAgent{}
+	present, err := ag.DetectPresence()
+	if err != nil {
+		t.Fatalf("DetectPresence() error = %v", err)
+	}
+	if present {
+		t.Error("DetectPresence() = true without .codex, want false")
+	}
+
+	if err := os.Mkdir(".codex", 0o755); err != nil {
+		t.Fatalf("failed to create .codex: %v", err)
+	}
+	present, err = ag.DetectPresence()
+	if err != nil {
+		t.Fatalf("DetectPresence() error = %v", err)
+	}
+	if !present {
+		t.Error("DetectPresence() = false with .codex, want true")
+	}
+}
+
+func TestParseHookInput_TurnComplete(t *testing.T) {
+	sessionDir := t.TempDir()
+	t.Setenv("ENTIRE_TEST_CODEX_SESSION_DIR", sessionDir)
+
+	rolloutPath := writeRollout(t, sessionDir, "2025/01/02", "thread-1", "/repo")
+
+	ag := &CodexAgent{}
+	input := `{"type":"agent-turn-complete","thread-id":"thread-1","turn-id":"turn-1","cwd":"/repo","input-messages":["first","Fix the login bug"],"last-assistant-message":"Done."}`
+
+	result, err := ag.ParseHookInput(agent.HookStop, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+
+	if result.SessionID != "thread-1" {
+		t.Errorf("SessionID = %q, want %q", result.SessionID, "thread-1")
+	}
+	if result.SessionRef != rolloutPath {
+		t.Errorf("SessionRef = %q, want %q", result.SessionRef, rolloutPath)
+	}
+	if result.UserPrompt != "Fix the login bug" {
+		t.Errorf("UserPrompt = %q, want %q", result.UserPrompt, "Fix the login bug")
+	}
+	if result.RawData["type"] != NotifyTypeAgentTurnComplete {
+		t.Errorf("RawData[type] = %v, want %q", result.RawData["type"], NotifyTypeAgentTurnComplete)
+	}
+}
+
+func TestParseHookInput_NoThreadIDFallsBackToLatestRollout(t *testing.T) {
+	sessionDir := t.TempDir()
+	t.Setenv("ENTIRE_TEST_CODEX_SESSION_DIR", sessionDir)
+
+	writeRollout(t, sessionDir, "2025/01/01", "other-repo", "/other")
+	want := writeRollout(t, sessionDir, "2025/01/02", "this-repo", "/repo")
+	older := writeRollout(t, sessionDir, "2025/01/03", "stale", "/repo")
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(older, past, past); err != nil {
+		t.Fatalf("failed to set mod time: %v", err)
+	}
+
+	ag := &CodexAgent{}
+	input := `{"type":"agent-turn-complete","cwd":"/repo","input-messages":["hi"]}`
+
+	result, err := ag.ParseHookInput(agent.HookStop, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+	if result.SessionID != "this-repo" {
+		t.Errorf("SessionID = %q, want %q", result.SessionID, "this-repo")
+	}
+	if result.SessionRef != want {
+		t.Errorf("SessionRef = %q, want %q", result.SessionRef, want)
+	}
+}
+
+func TestParseHookInput_Empty(t *testing.T) {
+	t.Parallel()
+	ag := &CodexAgent{}
+	if _, err := ag.ParseHookInput(agent.HookStop, strings.NewReader("")); err == nil {
+		t.Error("ParseHookInput() should error on empty input")
+	}
+}
+
+func TestResolveSessionFile(t *testing.T) {
+	t.Parallel()
+	sessionDir := t.TempDir()
+	ag := &CodexAgent{}
+
+	existing := writeRollout(t, sessionDir, "2025/03/04", "abc", "/repo")
+	if got := ag.ResolveSessionFile(sessionDir, "abc"); got != existing {
+		t.Errorf("ResolveSessionFile() = %q, want %q", got, existing)
+	}
+
+	got := ag.ResolveSessionFile(sessionDir, "missing")
+	if !strings.HasPrefix(got, sessionDir) || !strings.HasSuffix(got, "-missing.jsonl") {
+		t.Errorf("ResolveSessionFile() fallback = %q, want dated path under %q", got, sessionDir)
+	}
+}
+
+func TestGetSessionDir_CodexHome(t *testing.T) {
+	t.Setenv("ENTIRE_TEST_CODEX_SESSION_DIR", "")
+	t.Setenv("CODEX_HOME", "/custom/codex")
+
+	ag := &CodexAgent{}
+	dir, err := ag.GetSessionDir("/repo")
+	if err != nil {
+		t.Fatalf("GetSessionDir() error = %v", err)
+	}
+	if want := filepath.Join("/custom/codex", "sessions"); dir != want {
+		t.Errorf("GetSessionDir() = %q, want %q", dir, want)
+	}
+}
+
+func TestFormatResumeCommand(t *testing.T) {
+	t.Parallel()
+	ag := &CodexAgent{}
+	if cmd := ag.FormatResumeCommand("abc"); cmd != "codex resume abc" {
+		t.Errorf("FormatResumeCommand() = %q, want %q", cmd, "codex resume abc")
+	}
+}
+
+func TestExtractModifiedFilesFromOffset(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "rollout.jsonl")
+	content := `{"type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch\n*** Update File: a.go\n*** End Patch"}}
+{"type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch\n*** Update File: b.go\n*** End Patch"}}
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write rollout: %v", err)
+	}
+
+	ag := &CodexAgent{}
+	files, pos, err := ag.ExtractModifiedFilesFromOffset(path, 1)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if pos != 2 {
+		t.Errorf("position = %d, want 2", pos)
+	}
+	if len(files) != 1 || files[0] != "b.go" {
+		t.Errorf("files = %v, want [b.go]", files)
+	}
+}
+
+// writeRollout writes a minimal rollout with a session_meta line under sessionDir/datePath.
+func writeRollout(t *testing.T, sessionDir, datePath, id, cwd string) string {
+	t.Helper()
+	dir := filepath.Join(sessionDir, filepath.FromSlash(datePath))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create rollout dir: %v", err)
+	}
+	path := filepath.Join(dir, "rollout-2025-01-01T00-00-00-"+id+".jsonl")
+	content := `{"timestamp":"2025-01-01T00:00:00Z","type":"session_meta","payload":{"id":"` + id + `","cwd":"` + cwd + `"}}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write rollout: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,201 @@
+package codex
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Ensure CodexAgent implements HookSupport, HookHandler, TranscriptAnalyzer and TranscriptChunker
+var (
+	_ agent.HookSupport        = (*CodexAgent)(nil)
+	_ agent.HookHandler        = (*CodexAgent)(nil)
+	_ agent.TranscriptAnalyzer = (*CodexAgent)(nil)
+	_ agent.TranscriptChunker  = (*CodexAgent)(nil)
+)
+
+// Codex hook names - these become subcommands under `entire hooks codex`
+const (
+	HookNameTurnComplete = "turn-complete"
+)
+
+// ConfigFileName is the Codex config file that holds the notify command.
+const ConfigFileName = "config.toml"
+
+// notifyKey is the config key for the program Codex runs after each turn.
+const notifyKey = "notify"
+
+// entireHookPrefixes are command prefixes that identify Entire hooks.
+var entireHookPrefixes = []string{
+	"entire ",
+	"go run ./cmd/entire/main.go ",
+}
+
+// GetHookNames returns the hook verbs Codex supports.
+// These become subcommands: entire hooks codex <verb>
+func (c *CodexAgent) GetHookNames() []string {
+	return []string{
+		HookNameTurnComplete,
+	}
+}
+
+// configFilePath returns the absolute path to the project's .codex/config.toml.
+// Falls back to CWD when not in a git repo (e.g., during tests).
+func configFilePath() (string, error) {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		repoRoot, err = os.Getwd() //nolint:forbidigo // Intentional fallback when RepoRoot() fails (tests run outside git repos)
+		if err != nil {
+			return "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+	return filepath.Join(repoRoot, ".codex", ConfigFileName), nil
+}
+
+// readConfig reads .codex/config.toml into a generic map so keys we don't
+// manage survive a round trip. Returns an empty map if the file doesn't exist.
+func readConfig(path string) (map[string]interface{}, error) {
+	config := make(map[string]interface{})
+	data, err := os.ReadFile(path) //nolint:gosec // path is constructed from repo root + fixed path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return config, nil
+		}
+		return nil, fmt.Errorf("failed to read config.toml: %w", err)
+	}
+	if err := toml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config.toml: %w", err)
+	}
+	return config, nil
+}
+
+// writeConfig writes the config map back to .codex/config.toml.
+func writeConfig(path string, config map[string]interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create .codex directory: %w", err)
+	}
+	output, err := toml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config.toml: %w", err)
+	}
+	if err := os.WriteFile(path, output, 0o600); err != nil {
+		return fmt.Errorf("failed to write config.toml: %w", err)
+	}
+	return nil
+}
+
+// notifyCommand returns the notify command configured in config, or nil if unset.
+func notifyCommand(config map[string]interface{}) []string {
+	raw, ok := config[notifyKey].([]interface{})
+	if !ok {
+		return nil
+	}
+	command := make([]string, 0, len(raw))
+	for _, arg := range raw {
+		if s, ok := arg.(string); ok {
+			command = append(command, s)
+		}
+	}
+	return command
+}
+
+// isEntireHook checks if a notify command is an Entire hook
+func isEntireHook(command []string) bool {
+	joined := strings.Join(command, " ")
+	for _, prefix := range entireHookPrefixes {
+		if strings.HasPrefix(joined, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// InstallHooks installs the Entire notify command in .codex/config.toml.
+// Codex runs a single notify program after every agent turn and passes the
+// turn details as its last argument.
+// Returns the number of hooks installed.
+func (c *CodexAgent) InstallHooks(localDev bool, force bool) (int, error) {
+	configPath, err := configFilePath()
+	if err != nil {
+		return 0, err
+	}
+
+	config, err := readConfig(configPath)
+	if err != nil {
+		return 0, err
+	}
+
+	var command []string
+	if localDev {
+		command = []string{"go", "run", "./cmd/entire/main.go", "hooks", "codex", HookNameTurnComplete}
+	} else {
+		command = []string{"entire", "hooks", "codex", HookNameTurnComplete}
+	}
+
+	existing := notifyCommand(config)
+	if len(existing) > 0 && !isEntireHook(existing) {
+		// Codex supports only one notify program, so we can't chain onto the user's
+		return 0, fmt.Errorf("%s already sets notify to %q; Codex supports a single notify command, remove it to enable Entire",
+			c.GetHookConfigPath(), strings.Join(existing, " "))
+	}
+
+	// Check for idempotency - same command already installed
+	if !force && strings.Join(existing, " ") == strings.Join(command, " ") {
+		return 0, nil
+	}
+
+	config[notifyKey] = command
+	if err := writeConfig(configPath, config); err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// UninstallHooks removes the Entire notify command from .codex/config.toml.
+func (c *CodexAgent) UninstallHooks() error {
+	configPath, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	if _, statErr := os.Stat(configPath); statErr != nil {
+		return nil //nolint:nilerr // No config file means nothing to uninstall
+	}
+
+	config, err := readConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if !isEntireHook(notifyCommand(config)) {
+		return nil
+	}
+
+	delete(config, notifyKey)
+	return writeConfig(configPath, config)
+}
+
+// AreHooksInstalled checks if the Entire notify command is installed.
+func (c *CodexAgent) AreHooksInstalled() bool {
+	configPath, err := configFilePath()
+	if err != nil {
+		return false
+	}
+	config, err := readConfig(configPath)
+	if err != nil {
+		return false
+	}
+	return isEntireHook(notifyCommand(config))
+}
+
+// GetSupportedHooks returns the hook types Codex supports.
+// Codex only notifies Entire when a turn completes, which maps to Stop.
+func (c *CodexAgent) GetSupportedHooks() []agent.HookType {
+	return []agent.HookType{
+		agent.HookStop,
+	}
+}
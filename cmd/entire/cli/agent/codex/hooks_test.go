@@ -0,0 +1,193 @@
+package codex
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestInstallHooks_FreshInstall(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CodexAgent{}
+	count, err := ag.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("InstallHooks() count = %d, want 1", count)
+	}
+
+	config := readTestConfig(t, tempDir)
+	if got := strings.Join(notifyCommand(config), " "); got != "entire hooks codex turn-complete" {
+		t.Errorf("notify = %q, want %q", got, "entire hooks codex turn-complete")
+	}
+	if !ag.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+}
+
+func TestInstallHooks_LocalDev(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CodexAgent{}
+	if _, err := ag.InstallHooks(true, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+
+	config := readTestConfig(t, tempDir)
+	if got := strings.Join(notifyCommand(config), " "); got != "go run ./cmd/entire/main.go hooks codex turn-complete" {
+		t.Errorf("notify = %q", got)
+	}
+}
+
+func TestInstallHooks_Idempotent(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CodexAgent{}
+	if _, err := ag.InstallHooks(false, false); err != nil {
+		t.Fatalf("first InstallHooks() error = %v", err)
+	}
+	count, err := ag.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("second InstallHooks() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("second InstallHooks() count = %d, want 0", count)
+	}
+
+	count, err = ag.InstallHooks(false, true)
+	if err != nil {
+		t.Fatalf("forced InstallHooks() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("forced InstallHooks() count = %d, want 1", count)
+	}
+}
+
+func TestInstallHooks_PreservesOtherSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	writeTestConfig(t, tempDir, "model = \"o4-mini\"\n\n[mcp_servers.docs]\ncommand = \"docs-server\"\n")
+
+	ag := &CodexAgent{}
+	if _, err := ag.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+
+	config := readTestConfig(t, tempDir)
+	if config["model"] != "o4-mini" {
+		t.Errorf("model = %v, want o4-mini", config["model"])
+	}
+	servers, ok := config["mcp_servers"].(map[string]interface{})
+	if !ok || servers["docs"] == nil {
+		t.Errorf("mcp_servers.docs was not preserved: %v", config["mcp_servers"])
+	}
+}
+
+func TestInstallHooks_ExistingNotifyConflict(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	writeTestConfig(t, tempDir, "notify = [\"notify-send\", \"Codex\"]\n")
+
+	ag := &CodexAgent{}
+	if _, err := ag.InstallHooks(false, false); err == nil {
+		t.Fatal("InstallHooks() should error when a non-Entire notify is configured")
+	}
+
+	config := readTestConfig(t, tempDir)
+	if got := strings.Join(notifyCommand(config), " "); got != "notify-send Codex" {
+		t.Errorf("notify = %q, want user's command untouched", got)
+	}
+}
+
+func TestUninstallHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	writeTestConfig(t, tempDir, "model = \"o4-mini\"\n")
+
+	ag := &CodexAgent{}
+	if _, err := ag.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if err := ag.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+
+	config := readTestConfig(t, tempDir)
+	if _, ok := config[notifyKey]; ok {
+		t.Error("notify should be removed after uninstall")
+	}
+	if config["model"] != "o4-mini" {
+		t.Errorf("model = %v, want o4-mini", config["model"])
+	}
+	if ag.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true after uninstall")
+	}
+}
+
+func TestUninstallHooks_LeavesUserNotify(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	writeTestConfig(t, tempDir, "notify = [\"notify-send\", \"Codex\"]\n")
+
+	ag := &CodexAgent{}
+	if err := ag.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+
+	config := readTestConfig(t, tempDir)
+	if got := strings.Join(notifyCommand(config), " "); got != "notify-send Codex" {
+		t.Errorf("notify = %q, want user's command untouched", got)
+	}
+}
+
+func TestUninstallHooks_NoConfigFile(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CodexAgent{}
+	if err := ag.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+}
+
+func writeTestConfig(t *testing.T, tempDir, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".codex"), 0o755); err != nil {
+		t.Fatalf("failed to create .codex: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".codex", ConfigFileName), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config.toml: %v", err)
+	}
+}
+
+func readTestConfig(t *testing.T, tempDir string) map[string]interface{} {
+	t.Helper()
+	config, err := readConfig(filepath.Join(tempDir, ".codex", ConfigFileName))
+	if err != nil {
+		t.Fatalf("failed to read config.toml: %v", err)
+	}
+	return config
+}
+
+func TestGetSupportedHooks(t *testing.T) {
+	ag := &CodexAgent{}
+
+	// Only the turn-complete notify command is installed, which maps to Stop
+	want := []agent.HookType{agent.HookStop}
+	if got := ag.GetSupportedHooks(); !slices.Equal(got, want) {
+		t.Errorf("GetSupportedHooks() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package codex
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Ensure CodexAgent implements TranscriptNormalizer
+var _ agent.TranscriptNormalizer = (*CodexAgent)(nil)
+
+// Response item payload types for tool results
+const (
+	itemTypeFunctionCallOutput   = "function_call_output"
+	itemTypeCustomToolCallOutput = "custom_tool_call_output"
+)
+
+// toolCallLink holds the fields that pair a tool call with its output item.
+type toolCallLink struct {
+	CallID string          `json:"call_id"`
+	Output json.RawMessage `json:"output,omitempty"`
+}
+
+// NormalizeTranscript converts a Codex rollout into session entries.
+// User prompts come from user_message events, since user message items also
+// carry injected environment context. Tool outputs are attached to the tool
+// entry with the matching call ID.
+func (c *CodexAgent) NormalizeTranscript(content []byte) ([]agent.SessionEntry, error) {
+	lines, err := ParseRollout(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []agent.SessionEntry
+	toolEntries := make(map[string]int) // call ID -> index in entries
+
+	for _, line := range lines {
+		timestamp, _ := time.Parse(time.RFC3339Nano, line.Timestamp) //nolint:errcheck // Zero time is fine for a missing timestamp
+
+		if prompt := UserPromptText(line); prompt != "" {
+			entries = append(entries, agent.SessionEntry{Type: agent.EntryUser, Timestamp: timestamp, Content: prompt})
+			continue
+		}
+		if text := AssistantText(line); text != "" {
+			entries = append(entries, agent.SessionEntry{Type: agent.EntryAssistant, Timestamp: timestamp, Content: text})
+			continue
+		}
+
+		item := parseResponseItem(line)
+		if item == nil {
+			continue
+		}
+		var link toolCallLink
+		if err := json.Unmarshal(line.Payload, &link); err != nil {
+			continue
+		}
+
+		switch item.Type {
+		case ItemTypeFunctionCall, ItemTypeCustomToolCall:
+			name, input, _ := ToolCall(line)
+			entry := agent.SessionEntry{
+				Type:          agent.EntryTool,
+				Timestamp:     timestamp,
+				ToolName:      name,
+				ToolInput:     decodeToolValue(input),
+				FilesAffected: ParsePatchFiles(applyPatchInput(item)),
+			}
+			if link.CallID != "" {
+				toolEntries[link.CallID] = len(entries)
+			}
+			entries = append(entries, entry)
+
+		case itemTypeFunctionCallOutput, itemTypeCustomToolCallOutput:
+			if i, ok := toolEntries[link.CallID]; ok {
+				var output interface{}
+				if json.Unmarshal(link.Output, &output) == nil {
+					entries[i].ToolOutput = output
+				}
+			}
+		}
+	}
+	return entries, nil
+}
+
+// decodeToolValue decodes a tool call's input, which is JSON-encoded arguments
+// for function calls and raw text for custom tool calls.
+func decodeToolValue(input string) interface{} {
+	if input == "" {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		return input
+	}
+	return v
+}
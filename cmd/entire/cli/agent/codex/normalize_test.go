@@ -0,0 +1,57 @@
+package codex
+
+import (
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestNormalizeTranscript(t *testing.T) {
+	t.Parallel()
+
+	rollout := testRollout +
+		`{"timestamp":"2025-01-01T00:00:11Z","type":"response_item","payload":{"type":"function_call","name":"shell","call_id":"call_1","arguments":"{\"command\":[\"ls\"]}"}}
+{"timestamp":"2025-01-01T00:00:12Z","type":"response_item","payload":{"type":"function_call_output","call_id":"call_1","output":"{\"output\":\"README.md\\n\"}"}}
+`
+
+	entries, err := (&CodexAgent{}).NormalizeTranscript([]byte(rollout))
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+
+	want := []struct {
+		typ     agent.EntryType
+		content string
+		tool    string
+		files   int
+	}{
+		{agent.EntryUser, "Add a README", "", 0},
+		{agent.EntryTool, "", "apply_patch", 1},
+		{agent.EntryAssistant, "Created README.", "", 0},
+		{agent.EntryUser, "Also fix main.go", "", 0},
+		{agent.EntryTool, "", "apply_patch", 2},
+		{agent.EntryTool, "", "shell", 1},
+		{agent.EntryTool, "", "shell", 0},
+		{agent.EntryAssistant, "Done.", "", 0},
+		{agent.EntryTool, "", "shell", 0},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Type != w.typ || e.Content != w.content || e.ToolName != w.tool || len(e.FilesAffected) != w.files {
+			t.Errorf("entries[%d] = %+v, want %+v", i, e, w)
+		}
+	}
+
+	if entries[0].Timestamp.IsZero() {
+		t.Error("entries should carry the rollout line timestamp")
+	}
+	if entries[1].ToolInput == nil {
+		t.Error("custom tool call should keep its raw input")
+	}
+	if output, ok := entries[8].ToolOutput.(string); !ok || output == "" {
+		t.Errorf("shell ToolOutput = %v, want the function_call_output", entries[8].ToolOutput)
+	}
+}
@@ -0,0 +1,301 @@
+package codex
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Transcript parsing - Codex writes each session to a JSONL "rollout" file:
+// ~/.codex/sessions/YYYY/MM/DD/rollout-<timestamp>-<session-id>.jsonl
+
+// Scanner buffer size for large rollout files (10MB)
+const scannerBufferSize = 10 * 1024 * 1024
+
+// Role constants for message response items
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// apply_patch envelope headers that name the files a patch touches
+const (
+	patchAddFile    = "*** Add File: "
+	patchUpdateFile = "*** Update File: "
+	patchDeleteFile = "*** Delete File: "
+	patchMoveTo     = "*** Move to: "
+)
+
+// ParseRollout parses raw JSONL content into rollout lines.
+// Malformed lines are skipped.
+func ParseRollout(data []byte) ([]RolloutLine, error) {
+	var lines []RolloutLine
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, scannerBufferSize), scannerBufferSize)
+
+	for scanner.Scan() {
+		var line RolloutLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue // Skip malformed lines
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan rollout: %w", err)
+	}
+	return lines, nil
+}
+
+// parseResponseItem decodes a response_item payload, returning nil for other line types.
+func parseResponseItem(line RolloutLine) *responseItem {
+	if line.Type != LineTypeResponseItem {
+		return nil
+	}
+	var item responseItem
+	if err := json.Unmarshal(line.Payload, &item); err != nil {
+		return nil
+	}
+	return &item
+}
+
+// parseEventMsg decodes an event_msg payload, returning nil for other line types.
+func parseEventMsg(line RolloutLine) *eventMsg {
+	if line.Type != LineTypeEventMsg {
+		return nil
+	}
+	var event eventMsg
+	if err := json.Unmarshal(line.Payload, &event); err != nil {
+		return nil
+	}
+	return &event
+}
+
+// SessionIDFromLines returns the session ID recorded in the rollout's session_meta line.
+// Returns empty string if there is no session_meta line.
+func SessionIDFromLines(lines []RolloutLine) string {
+	for _, line := range lines {
+		if line.Type != LineTypeSessionMeta {
+			continue
+		}
+		var meta sessionMeta
+		if err := json.Unmarshal(line.Payload, &meta); err == nil {
+			return meta.ID
+		}
+	}
+	return ""
+}
+
+// ExtractModifiedFiles extracts files touched by apply_patch calls from rollout lines.
+// apply_patch shows up as a custom tool call, a function call, or a shell command
+// depending on the Codex version and model, so all three forms are handled.
+func ExtractModifiedFiles(lines []RolloutLine) []string {
+	fileSet := make(map[string]bool)
+	var files []string
+
+	for _, line := range lines {
+		item := parseResponseItem(line)
+		if item == nil {
+			continue
+		}
+
+		for _, file := range ParsePatchFiles(applyPatchInput(item)) {
+			if !fileSet[file] {
+				fileSet[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	return files
+}
+
+// applyPatchInput returns the patch text of an apply_patch call, or empty string
+// if the response item is not an apply_patch call.
+func applyPatchInput(item *responseItem) string {
+	switch item.Type {
+	case ItemTypeCustomToolCall:
+		if item.Name == ToolApplyPatch {
+			return item.Input
+		}
+	case ItemTypeFunctionCall:
+		switch item.Name {
+		case ToolApplyPatch:
+			var args applyPatchArguments
+			if err := json.Unmarshal([]byte(item.Arguments), &args); err == nil {
+				return args.Input
+			}
+		case ToolShell:
+			var args shellArguments
+			if err := json.Unmarshal([]byte(item.Arguments), &args); err != nil {
+				return ""
+			}
+			// e.g. ["apply_patch", "*** Begin Patch\n..."]
+			if len(args.Command) >= 2 && args.Command[0] == ToolApplyPatch {
+				return args.Command[1]
+			}
+		}
+	}
+	return ""
+}
+
+// ParsePatchFiles returns the file paths named in an apply_patch envelope,
+// including both source and destination of moves.
+func ParsePatchFiles(patch string) []string {
+	var files []string
+	for _, line := range strings.Split(patch, "\n") {
+		line = strings.TrimRight(line, "\r")
+		for _, header := range []string{patchAddFile, patchUpdateFile, patchDeleteFile, patchMoveTo} {
+			if strings.HasPrefix(line, header) {
+				if file := strings.TrimSpace(strings.TrimPrefix(line, header)); file != "" {
+					files = append(files, file)
+				}
+				break
+			}
+		}
+	}
+	return files
+}
+
+// ExtractAllUserPrompts extracts all user prompts from rollout data
+func ExtractAllUserPrompts(data []byte) ([]string, error) {
+	lines, err := ParseRollout(data)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractAllUserPromptsFromLines(lines), nil
+}
+
+// ExtractAllUserPromptsFromLines extracts all user prompts from parsed rollout lines.
+// Prompts come from user_message events rather than user response items, because
+// Codex also injects AGENTS.md and environment context as user messages.
+func ExtractAllUserPromptsFromLines(lines []RolloutLine) []string {
+	var prompts []string
+	for _, line := range lines {
+		event := parseEventMsg(line)
+		if event == nil || event.Type != EventTypeUserMessage {
+			continue
+		}
+		if prompt := strings.TrimSpace(event.Message); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts
+}
+
+// ExtractLastAssistantMessage extracts the last assistant response from rollout data
+func ExtractLastAssistantMessage(data []byte) (string, error) {
+	lines, err := ParseRollout(data)
+	if err != nil {
+		return "", err
+	}
+	return ExtractLastAssistantMessageFromLines(lines), nil
+}
+
+// ExtractLastAssistantMessageFromLines extracts the last assistant response from parsed rollout lines
+func ExtractLastAssistantMessageFromLines(lines []RolloutLine) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if text := AssistantText(lines[i]); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// AssistantText returns the text of an assistant message response item,
+// or empty string if the line is not one.
+func AssistantText(line RolloutLine) string {
+	item := parseResponseItem(line)
+	if item == nil || item.Type != ItemTypeMessage || item.Role != RoleAssistant {
+		return ""
+	}
+	var texts []string
+	for _, block := range item.Content {
+		if block.Text != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// UserPromptText returns the prompt of a user_message event, or empty string
+// if the line is not one.
+func UserPromptText(line RolloutLine) string {
+	event := parseEventMsg(line)
+	if event == nil || event.Type != EventTypeUserMessage {
+		return ""
+	}
+	return strings.TrimSpace(event.Message)
+}
+
+// ToolCall returns the name and raw input of a tool call response item.
+// ok is false if the line is not a tool call.
+func ToolCall(line RolloutLine) (name, input string, ok bool) {
+	item := parseResponseItem(line)
+	if item == nil {
+		return "", "", false
+	}
+	switch item.Type {
+	case ItemTypeFunctionCall:
+		return item.Name, item.Arguments, true
+	case ItemTypeCustomToolCall:
+		return item.Name, item.Input, true
+	}
+	return "", "", false
+}
+
+// CalculateTokenUsage calculates token usage from Codex rollout data.
+// Each token_count event that carries usage counts as one API call.
+// Only processes lines from startLine onwards (0-indexed).
+func CalculateTokenUsage(data []byte, startLine int) *agent.TokenUsage {
+	lines, err := ParseRollout(data)
+	if err != nil {
+		return &agent.TokenUsage{}
+	}
+	if startLine > 0 {
+		if startLine >= len(lines) {
+			return &agent.TokenUsage{}
+		}
+		lines = lines[startLine:]
+	}
+
+	usage := &agent.TokenUsage{}
+	for _, line := range lines {
+		event := parseEventMsg(line)
+		if event == nil || event.Type != EventTypeTokenCount || event.Info == nil || event.Info.LastTokenUsage == nil {
+			continue
+		}
+		last := event.Info.LastTokenUsage
+
+		usage.APICallCount++
+		// Codex reports cached tokens as a subset of input tokens
+		usage.InputTokens += last.InputTokens - last.CachedInputTokens
+		usage.CacheReadTokens += last.CachedInputTokens
+		usage.OutputTokens += last.OutputTokens
+	}
+
+	return usage
+}
+
+// CalculateTokenUsageFromFile calculates token usage from a Codex rollout file.
+// If startLine > 0, only considers lines from startLine onwards.
+func CalculateTokenUsageFromFile(path string, startLine int) (*agent.TokenUsage, error) {
+	if path == "" {
+		return &agent.TokenUsage{}, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Reading from controlled rollout path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &agent.TokenUsage{}, nil
+		}
+		return nil, fmt.Errorf("failed to read rollout: %w", err)
+	}
+
+	return CalculateTokenUsage(data, startLine), nil
+}
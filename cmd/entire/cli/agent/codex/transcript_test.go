@@ -0,0 +1,111 @@
+package codex
+
+import (
+	"testing"
+)
+
+const testRollout = `{"timestamp":"2025-01-01T00:00:00Z","type":"session_meta","payload":{"id":"thread-1","cwd":"/repo"}}
+{"timestamp":"2025-01-01T00:00:01Z","type":"event_msg","payload":{"type":"user_message","message":"Add a README"}}
+{"timestamp":"2025-01-01T00:00:02Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch\n*** Add File: README.md\n+# Project\n*** End Patch"}}
+{"timestamp":"2025-01-01T00:00:03Z","type":"event_msg","payload":{"type":"token_count","info":{"last_token_usage":{"input_tokens":100,"cached_input_tokens":40,"output_tokens":20}}}}
+{"timestamp":"2025-01-01T00:00:04Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Created README."}]}}
+{"timestamp":"2025-01-01T00:00:05Z","type":"event_msg","payload":{"type":"user_message","message":"Also fix main.go"}}
+{"timestamp":"2025-01-01T00:00:06Z","type":"response_item","payload":{"type":"function_call","name":"apply_patch","arguments":"{\"input\":\"*** Begin Patch\\n*** Update File: main.go\\n*** Move to: cmd/main.go\\n*** End Patch\"}"}}
+{"timestamp":"2025-01-01T00:00:07Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"apply_patch\",\"*** Begin Patch\\n*** Delete File: old.go\\n*** End Patch\"]}"}}
+{"timestamp":"2025-01-01T00:00:08Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"go\",\"test\",\"./...\"]}"}}
+{"timestamp":"2025-01-01T00:00:09Z","type":"event_msg","payload":{"type":"token_count","info":{"last_token_usage":{"input_tokens":200,"cached_input_tokens":0,"output_tokens":30}}}}
+{"timestamp":"2025-01-01T00:00:10Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Done."}]}}
+`
+
+func TestExtractAllUserPrompts(t *testing.T) {
+	t.Parallel()
+
+	prompts, err := ExtractAllUserPrompts([]byte(testRollout))
+	if err != nil {
+		t.Fatalf("ExtractAllUserPrompts() error = %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("got %d prompts, want 2: %v", len(prompts), prompts)
+	}
+	if prompts[0] != "Add a README" || prompts[1] != "Also fix main.go" {
+		t.Errorf("prompts = %v", prompts)
+	}
+}
+
+func TestExtractLastAssistantMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := ExtractLastAssistantMessage([]byte(testRollout))
+	if err != nil {
+		t.Fatalf("ExtractLastAssistantMessage() error = %v", err)
+	}
+	if msg != "Done." {
+		t.Errorf("ExtractLastAssistantMessage() = %q, want %q", msg, "Done.")
+	}
+}
+
+func TestExtractModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	lines, err := ParseRollout([]byte(testRollout))
+	if err != nil {
+		t.Fatalf("ParseRollout() error = %v", err)
+	}
+	files := ExtractModifiedFiles(lines)
+	want := []string{"README.md", "main.go", "cmd/main.go", "old.go"}
+	if len(files) != len(want) {
+		t.Fatalf("ExtractModifiedFiles() = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("files[%d] = %q, want %q", i, files[i], want[i])
+		}
+	}
+}
+
+func TestSessionIDFromLines(t *testing.T) {
+	t.Parallel()
+
+	lines, err := ParseRollout([]byte(testRollout))
+	if err != nil {
+		t.Fatalf("ParseRollout() error = %v", err)
+	}
+	if id := SessionIDFromLines(lines); id != "thread-1" {
+		t.Errorf("SessionIDFromLines() = %q, want %q", id, "thread-1")
+	}
+}
+
+func TestCalculateTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	usage := CalculateTokenUsage([]byte(testRollout), 0)
+	if usage.APICallCount != 2 {
+		t.Errorf("APICallCount = %d, want 2", usage.APICallCount)
+	}
+	if usage.InputTokens != 260 {
+		t.Errorf("InputTokens = %d, want 260", usage.InputTokens)
+	}
+	if usage.CacheReadTokens != 40 {
+		t.Errorf("CacheReadTokens = %d, want 40", usage.CacheReadTokens)
+	}
+	if usage.OutputTokens != 50 {
+		t.Errorf("OutputTokens = %d, want 50", usage.OutputTokens)
+	}
+}
+
+func TestCalculateTokenUsage_FromOffset(t *testing.T) {
+	t.Parallel()
+
+	usage := CalculateTokenUsage([]byte(testRollout), 5)
+	if usage.APICallCount != 1 {
+		t.Errorf("APICallCount = %d, want 1", usage.APICallCount)
+	}
+	if usage.InputTokens != 200 {
+		t.Errorf("InputTokens = %d, want 200", usage.InputTokens)
+	}
+
+	usage = CalculateTokenUsage([]byte(testRollout), 99)
+	if usage.APICallCount != 0 {
+		t.Errorf("APICallCount past end = %d, want 0", usage.APICallCount)
+	}
+}
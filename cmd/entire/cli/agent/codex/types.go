@@ -0,0 +1,111 @@
+package codex
+
+import "encoding/json"
+
+// notifyPayload is the JSON document Codex passes to its notify program.
+// Codex appends it as the final command-line argument rather than writing it to stdin.
+// Keys are kebab-case in Codex's notify protocol.
+type notifyPayload struct {
+	Type                 string   `json:"type"` // e.g., "agent-turn-complete"
+	ThreadID             string   `json:"thread-id,omitempty"`
+	TurnID               string   `json:"turn-id,omitempty"`
+	Cwd                  string   `json:"cwd,omitempty"`
+	InputMessages        []string `json:"input-messages,omitempty"`
+	LastAssistantMessage string   `json:"last-assistant-message,omitempty"`
+}
+
+// NotifyTypeAgentTurnComplete is the only notification type Codex currently emits.
+const NotifyTypeAgentTurnComplete = "agent-turn-complete"
+
+// Rollout line types - each line of a rollout file is tagged with one of these
+const (
+	LineTypeSessionMeta  = "session_meta"
+	LineTypeResponseItem = "response_item"
+	LineTypeEventMsg     = "event_msg"
+	LineTypeTurnContext  = "turn_context"
+)
+
+// Response item payload types
+const (
+	ItemTypeMessage        = "message"
+	ItemTypeFunctionCall   = "function_call"
+	ItemTypeCustomToolCall = "custom_tool_call"
+)
+
+// Event message payload types
+const (
+	EventTypeUserMessage  = "user_message"
+	EventTypeAgentMessage = "agent_message"
+	EventTypeTokenCount   = "token_count"
+)
+
+// Tool names used by Codex for file modifications.
+// apply_patch is either its own tool or invoked through the shell tool.
+const (
+	ToolApplyPatch = "apply_patch"
+	ToolShell      = "shell"
+)
+
+// RolloutLine is a single line of a Codex rollout file.
+type RolloutLine struct {
+	Timestamp string          `json:"timestamp"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// sessionMeta is the payload of the first line of a rollout file.
+type sessionMeta struct {
+	ID  string `json:"id"`
+	Cwd string `json:"cwd"`
+}
+
+// responseItem is the payload of a response_item line.
+// Only the fields needed for prompt, summary and file extraction are decoded.
+type responseItem struct {
+	Type      string         `json:"type"`
+	Role      string         `json:"role,omitempty"`
+	Content   []contentBlock `json:"content,omitempty"`
+	Name      string         `json:"name,omitempty"`
+	Arguments string         `json:"arguments,omitempty"` // function_call: JSON-encoded arguments
+	Input     string         `json:"input,omitempty"`     // custom_tool_call: raw tool input
+}
+
+// contentBlock is a block within a message response item.
+type contentBlock struct {
+	Type string `json:"type"` // input_text, output_text
+	Text string `json:"text,omitempty"`
+}
+
+// eventMsg is the payload of an event_msg line.
+type eventMsg struct {
+	Type    string          `json:"type"`
+	Message string          `json:"message,omitempty"`
+	Info    *tokenCountInfo `json:"info,omitempty"`
+}
+
+// tokenCountInfo carries usage for token_count events.
+// Info is null on events that only report rate limits.
+type tokenCountInfo struct {
+	LastTokenUsage *tokenUsage `json:"last_token_usage,omitempty"`
+}
+
+// tokenUsage mirrors Codex's per-request usage counters.
+// InputTokens includes CachedInputTokens, and OutputTokens includes reasoning output.
+type tokenUsage struct {
+	InputTokens           int `json:"input_tokens"`
+	CachedInputTokens     int `json:"cached_input_tokens"`
+	OutputTokens          int `json:"output_tokens"`
+	ReasoningOutputTokens int `json:"reasoning_output_tokens"`
+	TotalTokens           int `json:"total_tokens"`
+}
+
+// shellArguments is the decoded arguments of a shell function call.
+type shellArguments struct {
+	Command []string `json:"command"`
+	Workdir string   `json:"workdir,omitempty"`
+}
+
+// applyPatchArguments is the decoded arguments of an apply_patch function call.
+type applyPatchArguments struct {
+	Input string `json:"input"`
+}
@@ -0,0 +1,384 @@
+// Package cursor implements the Agent interface for Cursor.
+package cursor
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.Register(agent.AgentNameCursor, NewCursorAgent)
+}
+
+// CursorAgent implements the Agent interface for Cursor.
+//
+//nolint:revive // CursorAgent is clearer than Agent in this context
+type CursorAgent struct{}
+
+// NewCursorAgent creates a new Cursor agent instance.
+func NewCursorAgent() agent.Agent {
+	return &CursorAgent{}
+}
+
+// Name returns the agent registry key.
+func (c *CursorAgent) Name() agent.AgentName {
+	return agent.AgentNameCursor
+}
+
+// Type returns the agent type identifier.
+func (c *CursorAgent) Type() agent.AgentType {
+	return agent.AgentTypeCursor
+}
+
+// Description returns a human-readable description.
+func (c *CursorAgent) Description() string {
+	return "Cursor - AI code editor"
+}
+
+// DetectPresence checks if Cursor is configured in the repository.
+func (c *CursorAgent) DetectPresence() (bool, error) {
+	// Get repo root to check for .cursor directory
+	// This is needed because the CLI may be run from a subdirectory
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		// Not in a git repo, fall back to CWD-relative check
+		repoRoot = "."
+	}
+
+	// Check for .cursor directory
+	cursorDir := filepath.Join(repoRoot, ".cursor")
+	if _, err := os.Stat(cursorDir); err == nil {
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetHookConfigPath returns the path to Cursor's hook config file.
+func (c *CursorAgent) GetHookConfigPath() string {
+	return ".cursor/" + HooksFileName
+}
+
+// SupportsHooks returns true as Cursor supports lifecycle hooks.
+func (c *CursorAgent) SupportsHooks() bool {
+	return true
+}
+
+// ParseHookInput parses Cursor hook input from stdin.
+// Cursor does not always send a transcript path, so when it is missing the
+// transcript location is resolved from the workspace root and conversation ID.
+func (c *CursorAgent) ParseHookInput(hookType agent.HookType, reader io.Reader) (*agent.HookInput, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("empty input")
+	}
+
+	input := &agent.HookInput{
+		HookType:  hookType,
+		Timestamp: time.Now(),
+		RawData:   make(map[string]interface{}),
+	}
+
+	var base hookInputRaw
+
+	// Parse based on hook type
+	switch hookType {
+	case agent.HookUserPromptSubmit:
+		var raw beforeSubmitPromptRaw
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse before-submit-prompt input: %w", err)
+		}
+		base = raw.hookInputRaw
+		input.UserPrompt = raw.Prompt
+
+	case agent.HookStop:
+		var raw stopRaw
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse stop input: %w", err)
+		}
+		base = raw.hookInputRaw
+		if raw.Status != "" {
+			input.RawData["status"] = raw.Status
+		}
+
+	case agent.HookSessionEnd:
+		var raw sessionEndRaw
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse session-end input: %w", err)
+		}
+		base = raw.hookInputRaw
+		if raw.Reason != "" {
+			input.RawData["reason"] = raw.Reason
+		}
+
+	case agent.HookPostToolUse:
+		var raw afterFileEditRaw
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse after-file-edit input: %w", err)
+		}
+		base = raw.hookInputRaw
+		input.ToolName = ToolEditFile
+		input.RawData["file_path"] = raw.FilePath
+
+	case agent.HookSessionStart, agent.HookPreToolUse:
+		if err := json.Unmarshal(data, &base); err != nil {
+			return nil, fmt.Errorf("failed to parse hook input: %w", err)
+		}
+	}
+
+	input.SessionID = base.ConversationID
+	if input.SessionID == "" {
+		input.SessionID = base.SessionID
+	}
+	input.SessionRef = base.TranscriptPath
+	if input.SessionRef == "" && input.SessionID != "" {
+		input.SessionRef = c.resolveTranscriptPath(base.WorkspaceRoots, input.SessionID)
+	}
+
+	input.RawData["hook_event_name"] = base.HookEventName
+	if base.GenerationID != "" {
+		input.RawData["generation_id"] = base.GenerationID
+	}
+	if len(base.WorkspaceRoots) > 0 {
+		input.RawData["cwd"] = base.WorkspaceRoots[0]
+	}
+
+	return input, nil
+}
+
+// resolveTranscriptPath derives the transcript path for a conversation from
+// Cursor's per-project session directory. Returns empty string if it cannot be resolved.
+func (c *CursorAgent) resolveTranscriptPath(workspaceRoots []string, conversationID string) string {
+	repoPath := ""
+	if len(workspaceRoots) > 0 {
+		repoPath = workspaceRoots[0]
+	} else if root, err := paths.RepoRoot(); err == nil {
+		repoPath = root
+	}
+	if repoPath == "" {
+		return ""
+	}
+
+	sessionDir, err := c.GetSessionDir(repoPath)
+	if err != nil {
+		return ""
+	}
+	return c.ResolveSessionFile(sessionDir, conversationID)
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (c *CursorAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// ProtectedDirs returns directories that Cursor uses for config/state.
+func (c *CursorAgent) ProtectedDirs() []string { return []string{".cursor"} }
+
+// GetSessionDir returns the directory where Cursor stores agent transcripts.
+// Cursor stores transcripts in ~/.cursor/projects/<sanitized-repo-path>/agent-transcripts/
+func (c *CursorAgent) GetSessionDir(repoPath string) (string, error) {
+	// Check for test environment override
+	if override := os.Getenv("ENTIRE_TEST_CURSOR_PROJECT_DIR"); override != "" {
+		return override, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	projectDir := SanitizePathForCursor(repoPath)
+	return filepath.Join(homeDir, ".cursor", "projects", projectDir, "agent-transcripts"), nil
+}
+
+// ResolveSessionFile returns the path to a Cursor transcript file.
+// Cursor names transcripts directly as <conversation-id>.jsonl.
+func (c *CursorAgent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	return filepath.Join(sessionDir, agentSessionID+".jsonl")
+}
+
+// ReadSession reads a session from Cursor's storage (JSONL transcript file).
+// The session data is stored in NativeData as raw JSONL bytes.
+func (c *CursorAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (transcript path) is required")
+	}
+
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	lines, err := ParseTranscript(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+
+	return &agent.AgentSession{
+		SessionID:     input.SessionID,
+		AgentName:     c.Name(),
+		SessionRef:    input.SessionRef,
+		StartTime:     time.Now(),
+		NativeData:    data,
+		ModifiedFiles: ExtractModifiedFiles(lines),
+	}, nil
+}
+
+// WriteSession writes a session to Cursor's storage (JSONL transcript file).
+// Uses the NativeData field which contains raw JSONL bytes.
+func (c *CursorAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+
+	// Verify this session belongs to Cursor
+	if session.AgentName != "" && session.AgentName != c.Name() {
+		return fmt.Errorf("session belongs to agent %q, not %q", session.AgentName, c.Name())
+	}
+
+	if session.SessionRef == "" {
+		return errors.New("session reference (transcript path) is required")
+	}
+
+	if len(session.NativeData) == 0 {
+		return errors.New("session has no native data to write")
+	}
+
+	if err := os.WriteFile(session.SessionRef, session.NativeData, 0o600); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+
+	return nil
+}
+
+// FormatResumeCommand returns the command to resume a Cursor session.
+// Cursor chats are resumed with the cursor-agent CLI.
+func (c *CursorAgent) FormatResumeCommand(sessionID string) string {
+	return "cursor-agent --resume " + sessionID
+}
+
+var nonAlphanumericRegex = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// SanitizePathForCursor converts a path to Cursor's project directory format.
+// Cursor drops the leading separator and replaces any non-alphanumeric character with a dash.
+func SanitizePathForCursor(path string) string {
+	return nonAlphanumericRegex.ReplaceAllString(strings.TrimLeft(path, `/\`), "-")
+}
+
+// TranscriptAnalyzer interface implementation
+
+// GetTranscriptPosition returns the current line count of a Cursor transcript.
+// Cursor uses JSONL format, so position is the number of lines.
+// Returns 0 if the file doesn't exist or is empty.
+func (c *CursorAgent) GetTranscriptPosition(path string) (int, error) {
+	if path == "" {
+		return 0, nil
+	}
+
+	file, err := os.Open(path) //nolint:gosec // Path comes from Cursor transcript location
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to open transcript file: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	lineCount := 0
+
+	for {
+		_, err := reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return 0, fmt.Errorf("failed to read transcript: %w", err)
+		}
+		lineCount++
+	}
+
+	return lineCount, nil
+}
+
+// ExtractModifiedFilesFromOffset extracts files modified since a given line number.
+// For Cursor (JSONL format), offset is the starting line number.
+// Returns:
+//   - files: list of file paths modified by Cursor (from edit/write tools)
+//   - currentPosition: total number of lines in the file
+//   - error: any error encountered during reading
+func (c *CursorAgent) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	if path == "" {
+		return nil, 0, nil
+	}
+
+	file, openErr := os.Open(path) //nolint:gosec // Path comes from Cursor transcript location
+	if openErr != nil {
+		if os.IsNotExist(openErr) {
+			return nil, 0, nil
+		}
+		return nil, 0, fmt.Errorf("failed to open transcript file: %w", openErr)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var lines []TranscriptLine
+	lineNum := 0
+
+	for {
+		lineData, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, 0, fmt.Errorf("failed to read transcript: %w", readErr)
+		}
+
+		if len(lineData) > 0 {
+			lineNum++
+			if lineNum > startOffset {
+				var line TranscriptLine
+				if parseErr := json.Unmarshal(lineData, &line); parseErr == nil {
+					lines = append(lines, line)
+				}
+				// Skip malformed lines silently
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	return ExtractModifiedFiles(lines), lineNum, nil
+}
+
+// TranscriptChunker interface implementation
+
+// ChunkTranscript splits a JSONL transcript at line boundaries.
+func (c *CursorAgent) ChunkTranscript(content []byte, maxSize int) ([][]byte, error) {
+	chunks, err := agent.ChunkJSONL(content, maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to chunk JSONL transcript: %w", err)
+	}
+	return chunks, nil
+}
+
+// ReassembleTranscript concatenates JSONL chunks with newlines.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (c *CursorAgent) ReassembleTranscript(chunks [][]byte) ([]byte, error) {
+	return agent.ReassembleJSONL(chunks), nil
+}
@@ -0,0 +1,291 @@
+package cursor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestNewCursorAgent(t *testing.T) {
+	t.Parallel()
+	ag := NewCursorAgent()
+	if ag == nil {
+		t.Fatal("NewCursorAgent() returned nil")
+	}
+	if ag.Name() != agent.AgentNameCursor {
+		t.Errorf("Name() = %q, want %q", ag.Name(), agent.AgentNameCursor)
+	}
+	if ag.Type() != agent.AgentTypeCursor {
+		t.Errorf("Type() = %q, want %q", ag.Type(), agent.AgentTypeCursor)
+	}
+}
+
+func TestDetectPresence(t *testing.T) {
+	t.Run("no .cursor directory", func(t *testing.T) {
+		tempDir := t.TempDir()
+		t.Chdir(tempDir)
+
+		ag := &CursorAgent{}
+		present, err := ag.DetectPresence()
+		if err != nil {
+			t.Fatalf("DetectPresence() error = %v", err)
+		}
+		if present {
+			t.Error("DetectPresence() = true, want false")
+		}
+	})
+
+	t.Run("with .cursor directory", func(t *testing.T) {
+		tempDir := t.TempDir()
+		t.Chdir(tempDir)
+
+		if err := os.Mkdir(".cursor", 0o755); err != nil {
+			t.Fatalf("failed to create .cursor: %v", err)
+		}
+
+		ag := &CursorAgent{}
+		present, err := ag.DetectPresence()
+		if err != nil {
+			t.Fatalf("DetectPresence() error = %v", err)
+		}
+		if !present {
+			t.Error("DetectPresence() = false, want true")
+		}
+	})
+}
+
+func TestGetHookConfigPath(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	if path := ag.GetHookConfigPath(); path != ".cursor/hooks.json" {
+		t.Errorf("GetHookConfigPath() = %q, want %q", path, ".cursor/hooks.json")
+	}
+}
+
+func TestParseHookInput_BeforeSubmitPrompt(t *testing.T) {
+	t.Parallel()
+
+	ag := &CursorAgent{}
+	input := `{"conversation_id":"conv-123","generation_id":"gen-1","hook_event_name":"beforeSubmitPrompt","workspace_roots":["/repo"],"transcript_path":"/tmp/conv-123.jsonl","prompt":"Fix the login bug"}`
+
+	result, err := ag.ParseHookInput(agent.HookUserPromptSubmit, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+
+	if result.SessionID != "conv-123" {
+		t.Errorf("SessionID = %q, want %q", result.SessionID, "conv-123")
+	}
+	if result.SessionRef != "/tmp/conv-123.jsonl" {
+		t.Errorf("SessionRef = %q, want %q", result.SessionRef, "/tmp/conv-123.jsonl")
+	}
+	if result.UserPrompt != "Fix the login bug" {
+		t.Errorf("UserPrompt = %q, want %q", result.UserPrompt, "Fix the login bug")
+	}
+	if result.RawData["cwd"] != "/repo" {
+		t.Errorf("RawData[cwd] = %v, want /repo", result.RawData["cwd"])
+	}
+}
+
+func TestParseHookInput_SessionIDFallback(t *testing.T) {
+	t.Parallel()
+
+	ag := &CursorAgent{}
+	input := `{"session_id":"sess-456","transcript_path":"/tmp/sess-456.jsonl"}`
+
+	result, err := ag.ParseHookInput(agent.HookSessionStart, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+	if result.SessionID != "sess-456" {
+		t.Errorf("SessionID = %q, want %q", result.SessionID, "sess-456")
+	}
+}
+
+func TestParseHookInput_ResolvesTranscriptPath(t *testing.T) {
+	sessionDir := t.TempDir()
+	t.Setenv("ENTIRE_TEST_CURSOR_PROJECT_DIR", sessionDir)
+
+	ag := &CursorAgent{}
+	input := `{"conversation_id":"conv-789","hook_event_name":"stop","workspace_roots":["/repo"],"status":"completed"}`
+
+	result, err := ag.ParseHookInput(agent.HookStop, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+
+	want := filepath.Join(sessionDir, "conv-789.jsonl")
+	if result.SessionRef != want {
+		t.Errorf("SessionRef = %q, want %q", result.SessionRef, want)
+	}
+	if result.RawData["status"] != "completed" {
+		t.Errorf("RawData[status] = %v, want completed", result.RawData["status"])
+	}
+}
+
+func TestParseHookInput_AfterFileEdit(t *testing.T) {
+	t.Parallel()
+
+	ag := &CursorAgent{}
+	input := `{"conversation_id":"conv-1","transcript_path":"/tmp/t.jsonl","file_path":"/repo/main.go","edits":[{"old_string":"a","new_string":"b"}]}`
+
+	result, err := ag.ParseHookInput(agent.HookPostToolUse, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+	if result.ToolName != ToolEditFile {
+		t.Errorf("ToolName = %q, want %q", result.ToolName, ToolEditFile)
+	}
+	if result.RawData["file_path"] != "/repo/main.go" {
+		t.Errorf("RawData[file_path] = %v, want /repo/main.go", result.RawData["file_path"])
+	}
+}
+
+func TestParseHookInput_Empty(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	if _, err := ag.ParseHookInput(agent.HookStop, strings.NewReader("")); err == nil {
+		t.Error("ParseHookInput() should error on empty input")
+	}
+}
+
+func TestParseHookInput_InvalidJSON(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	if _, err := ag.ParseHookInput(agent.HookStop, strings.NewReader("not json")); err == nil {
+		t.Error("ParseHookInput() should error on invalid JSON")
+	}
+}
+
+func TestResolveSessionFile(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	result := ag.ResolveSessionFile("/home/user/.cursor/projects/foo/agent-transcripts", "abc-123")
+	expected := "/home/user/.cursor/projects/foo/agent-transcripts/abc-123.jsonl"
+	if result != expected {
+		t.Errorf("ResolveSessionFile() = %q, want %q", result, expected)
+	}
+}
+
+func TestGetSessionDir_DefaultPath(t *testing.T) {
+	t.Setenv("ENTIRE_TEST_CURSOR_PROJECT_DIR", "")
+
+	ag := &CursorAgent{}
+	dir, err := ag.GetSessionDir("/Users/me/src/project")
+	if err != nil {
+		t.Fatalf("GetSessionDir() error = %v", err)
+	}
+	want := filepath.Join(".cursor", "projects", "Users-me-src-project", "agent-transcripts")
+	if !strings.HasSuffix(dir, want) {
+		t.Errorf("GetSessionDir() = %q, want suffix %q", dir, want)
+	}
+}
+
+func TestSanitizePathForCursor(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/Users/me/project", "Users-me-project"},
+		{"/home/user/my.repo", "home-user-my-repo"},
+		{"relative/path", "relative-path"},
+	}
+	for _, tt := range tests {
+		if got := SanitizePathForCursor(tt.input); got != tt.want {
+			t.Errorf("SanitizePathForCursor(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatResumeCommand(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	if cmd := ag.FormatResumeCommand("abc"); cmd != "cursor-agent --resume abc" {
+		t.Errorf("FormatResumeCommand() = %q, want %q", cmd, "cursor-agent --resume abc")
+	}
+}
+
+func TestReadWriteSession(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	transcriptPath := filepath.Join(tempDir, "conv.jsonl")
+
+	content := `{"role":"user","message":{"content":[{"type":"text","text":"hi"}]}}
+{"role":"assistant","message":{"content":[{"type":"tool_use","name":"edit_file","input":{"target_file":"main.go"}}]}}
+`
+	if err := os.WriteFile(transcriptPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+
+	ag := &CursorAgent{}
+	session, err := ag.ReadSession(&agent.HookInput{SessionID: "conv", SessionRef: transcriptPath})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if len(session.ModifiedFiles) != 1 || session.ModifiedFiles[0] != "main.go" {
+		t.Errorf("ModifiedFiles = %v, want [main.go]", session.ModifiedFiles)
+	}
+
+	session.SessionRef = filepath.Join(tempDir, "copy.jsonl")
+	if err := ag.WriteSession(session); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+	written, err := os.ReadFile(session.SessionRef)
+	if err != nil {
+		t.Fatalf("failed to read written transcript: %v", err)
+	}
+	if string(written) != content {
+		t.Errorf("written transcript = %q, want %q", written, content)
+	}
+}
+
+func TestWriteSession_WrongAgent(t *testing.T) {
+	t.Parallel()
+	ag := &CursorAgent{}
+	err := ag.WriteSession(&agent.AgentSession{
+		AgentName:  agent.AgentNameClaudeCode,
+		SessionRef: "/tmp/x.jsonl",
+		NativeData: []byte("{}"),
+	})
+	if err == nil {
+		t.Error("WriteSession() should error for a session from another agent")
+	}
+}
+
+func TestExtractModifiedFilesFromOffset(t *testing.T) {
+	t.Parallel()
+	tempDir := t.TempDir()
+	transcriptPath := filepath.Join(tempDir, "conv.jsonl")
+
+	content := `{"role":"assistant","message":{"content":[{"type":"tool_use","name":"write","input":{"path":"a.go"}}]}}
+{"role":"user","message":{"content":[{"type":"text","text":"more"}]}}
+{"role":"assistant","message":{"content":[{"type":"tool_use","name":"search_replace","input":{"file_path":"b.go"}}]}}
+`
+	if err := os.WriteFile(transcriptPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+
+	ag := &CursorAgent{}
+	files, pos, err := ag.ExtractModifiedFilesFromOffset(transcriptPath, 1)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if pos != 3 {
+		t.Errorf("position = %d, want 3", pos)
+	}
+	if len(files) != 1 || files[0] != "b.go" {
+		t.Errorf("files = %v, want [b.go]", files)
+	}
+
+	count, err := ag.GetTranscriptPosition(transcriptPath)
+	if err != nil {
+		t.Fatalf("GetTranscriptPosition() error = %v", err)
+	}
+	if count != 3 {
+		t.Errorf("GetTranscriptPosition() = %d, want 3", count)
+	}
+}
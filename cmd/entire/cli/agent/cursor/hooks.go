@@ -0,0 +1,327 @@
+package cursor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// Ensure CursorAgent implements HookSupport, HookHandler, TranscriptAnalyzer and TranscriptChunker
+var (
+	_ agent.HookSupport        = (*CursorAgent)(nil)
+	_ agent.HookHandler        = (*CursorAgent)(nil)
+	_ agent.TranscriptAnalyzer = (*CursorAgent)(nil)
+	_ agent.TranscriptChunker  = (*CursorAgent)(nil)
+)
+
+// Cursor hook names - these become subcommands under `entire hooks cursor`
+const (
+	HookNameSessionStart       = "session-start"
+	HookNameSessionEnd         = "session-end"
+	HookNameBeforeSubmitPrompt = "before-submit-prompt"
+	HookNameStop               = "stop"
+	HookNameAfterFileEdit      = "after-file-edit"
+)
+
+// HooksFileName is the hook config file used by Cursor.
+const HooksFileName = "hooks.json"
+
+// hooksFileVersion is the schema version Cursor expects in hooks.json.
+const hooksFileVersion = 1
+
+// entireHookPrefixes are command prefixes that identify Entire hooks.
+// Cursor runs project hooks from the project root, so the local dev command is root-relative.
+var entireHookPrefixes = []string{
+	"entire ",
+	"go run ./cmd/entire/main.go ",
+}
+
+// GetHookNames returns the hook verbs Cursor supports.
+// These become subcommands: entire hooks cursor <verb>
+func (c *CursorAgent) GetHookNames() []string {
+	return []string{
+		HookNameSessionStart,
+		HookNameSessionEnd,
+		HookNameBeforeSubmitPrompt,
+		HookNameStop,
+		HookNameAfterFileEdit,
+	}
+}
+
+// hooksFilePath returns the absolute path to .cursor/hooks.json.
+// Falls back to CWD when not in a git repo (e.g., during tests).
+func hooksFilePath() (string, error) {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		repoRoot, err = os.Getwd() //nolint:forbidigo // Intentional fallback when RepoRoot() fails (tests run outside git repos)
+		if err != nil {
+			return "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+	return filepath.Join(repoRoot, ".cursor", HooksFileName), nil
+}
+
+// InstallHooks installs Cursor hooks in .cursor/hooks.json.
+// If force is true, removes existing Entire hooks before installing.
+// Returns the number of hooks installed.
+func (c *CursorAgent) InstallHooks(localDev bool, force bool) (int, error) {
+	hooksPath, err := hooksFilePath()
+	if err != nil {
+		return 0, err
+	}
+
+	// rawFile preserves top-level keys we don't manage
+	var rawFile map[string]json.RawMessage
+	// rawHooks preserves hook types we don't manage (e.g., "beforeShellExecution")
+	var rawHooks map[string]json.RawMessage
+
+	existingData, readErr := os.ReadFile(hooksPath) //nolint:gosec // path is constructed from repo root + fixed path
+	if readErr == nil {
+		if err := json.Unmarshal(existingData, &rawFile); err != nil {
+			return 0, fmt.Errorf("failed to parse existing hooks.json: %w", err)
+		}
+		if hooksRaw, ok := rawFile["hooks"]; ok {
+			if err := json.Unmarshal(hooksRaw, &rawHooks); err != nil {
+				return 0, fmt.Errorf("failed to parse hooks in hooks.json: %w", err)
+			}
+		}
+	} else {
+		rawFile = make(map[string]json.RawMessage)
+	}
+
+	if rawHooks == nil {
+		rawHooks = make(map[string]json.RawMessage)
+	}
+
+	// Define hook commands based on localDev mode
+	var cmdPrefix string
+	if localDev {
+		cmdPrefix = "go run ./cmd/entire/main.go hooks cursor "
+	} else {
+		cmdPrefix = "entire hooks cursor "
+	}
+
+	var sessionStart, sessionEnd, beforeSubmitPrompt, stop, afterFileEdit []CursorHookEntry
+	parseCursorHookType(rawHooks, "sessionStart", &sessionStart)
+	parseCursorHookType(rawHooks, "sessionEnd", &sessionEnd)
+	parseCursorHookType(rawHooks, "beforeSubmitPrompt", &beforeSubmitPrompt)
+	parseCursorHookType(rawHooks, "stop", &stop)
+	parseCursorHookType(rawHooks, "afterFileEdit", &afterFileEdit)
+
+	// Check for idempotency BEFORE removing hooks
+	// If the exact same hook command already exists, return 0 (no changes needed)
+	if !force {
+		if getFirstEntireHookCommand(stop) == cmdPrefix+HookNameStop {
+			return 0, nil // Already installed with same mode
+		}
+	}
+
+	// Remove existing Entire hooks first (for clean installs and mode switching)
+	sessionStart = removeEntireHooks(sessionStart)
+	sessionEnd = removeEntireHooks(sessionEnd)
+	beforeSubmitPrompt = removeEntireHooks(beforeSubmitPrompt)
+	stop = removeEntireHooks(stop)
+	afterFileEdit = removeEntireHooks(afterFileEdit)
+
+	sessionStart = append(sessionStart, CursorHookEntry{Command: cmdPrefix + HookNameSessionStart})
+	sessionEnd = append(sessionEnd, CursorHookEntry{Command: cmdPrefix + HookNameSessionEnd})
+	beforeSubmitPrompt = append(beforeSubmitPrompt, CursorHookEntry{Command: cmdPrefix + HookNameBeforeSubmitPrompt})
+	stop = append(stop, CursorHookEntry{Command: cmdPrefix + HookNameStop})
+	afterFileEdit = append(afterFileEdit, CursorHookEntry{Command: cmdPrefix + HookNameAfterFileEdit})
+	count := 5
+
+	marshalCursorHookType(rawHooks, "sessionStart", sessionStart)
+	marshalCursorHookType(rawHooks, "sessionEnd", sessionEnd)
+	marshalCursorHookType(rawHooks, "beforeSubmitPrompt", beforeSubmitPrompt)
+	marshalCursorHookType(rawHooks, "stop", stop)
+	marshalCursorHookType(rawHooks, "afterFileEdit", afterFileEdit)
+
+	// Cursor requires the schema version to be present
+	if _, ok := rawFile["version"]; !ok {
+		versionJSON, err := json.Marshal(hooksFileVersion)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal version: %w", err)
+		}
+		rawFile["version"] = versionJSON
+	}
+
+	hooksJSON, err := json.Marshal(rawHooks)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal hooks: %w", err)
+	}
+	rawFile["hooks"] = hooksJSON
+
+	if err := os.MkdirAll(filepath.Dir(hooksPath), 0o750); err != nil {
+		return 0, fmt.Errorf("failed to create .cursor directory: %w", err)
+	}
+
+	output, err := jsonutil.MarshalIndentWithNewline(rawFile, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal hooks.json: %w", err)
+	}
+
+	if err := os.WriteFile(hooksPath, output, 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write hooks.json: %w", err)
+	}
+
+	return count, nil
+}
+
+// UninstallHooks removes Entire hooks from Cursor's hooks.json.
+func (c *CursorAgent) UninstallHooks() error {
+	hooksPath, err := hooksFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(hooksPath) //nolint:gosec // path is constructed from repo root + fixed path
+	if err != nil {
+		return nil //nolint:nilerr // No hooks file means nothing to uninstall
+	}
+
+	var rawFile map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rawFile); err != nil {
+		return fmt.Errorf("failed to parse hooks.json: %w", err)
+	}
+
+	var rawHooks map[string]json.RawMessage
+	if hooksRaw, ok := rawFile["hooks"]; ok {
+		if err := json.Unmarshal(hooksRaw, &rawHooks); err != nil {
+			return fmt.Errorf("failed to parse hooks: %w", err)
+		}
+	}
+	if rawHooks == nil {
+		rawHooks = make(map[string]json.RawMessage)
+	}
+
+	for _, hookType := range []string{"sessionStart", "sessionEnd", "beforeSubmitPrompt", "stop", "afterFileEdit"} {
+		var entries []CursorHookEntry
+		parseCursorHookType(rawHooks, hookType, &entries)
+		marshalCursorHookType(rawHooks, hookType, removeEntireHooks(entries))
+	}
+
+	if len(rawHooks) > 0 {
+		hooksJSON, err := json.Marshal(rawHooks)
+		if err != nil {
+			return fmt.Errorf("failed to marshal hooks: %w", err)
+		}
+		rawFile["hooks"] = hooksJSON
+	} else {
+		delete(rawFile, "hooks")
+	}
+
+	output, err := jsonutil.MarshalIndentWithNewline(rawFile, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal hooks.json: %w", err)
+	}
+
+	if err := os.WriteFile(hooksPath, output, 0o600); err != nil {
+		return fmt.Errorf("failed to write hooks.json: %w", err)
+	}
+	return nil
+}
+
+// AreHooksInstalled checks if Entire hooks are installed.
+func (c *CursorAgent) AreHooksInstalled() bool {
+	hooksPath, err := hooksFilePath()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(hooksPath) //nolint:gosec // path is constructed from repo root + fixed path
+	if err != nil {
+		return false
+	}
+
+	var hooksFile CursorHooksFile
+	if err := json.Unmarshal(data, &hooksFile); err != nil {
+		return false
+	}
+
+	return hasEntireHook(hooksFile.Hooks.SessionStart) ||
+		hasEntireHook(hooksFile.Hooks.SessionEnd) ||
+		hasEntireHook(hooksFile.Hooks.BeforeSubmitPrompt) ||
+		hasEntireHook(hooksFile.Hooks.Stop) ||
+		hasEntireHook(hooksFile.Hooks.AfterFileEdit)
+}
+
+// GetSupportedHooks returns the hook types Cursor supports.
+func (c *CursorAgent) GetSupportedHooks() []agent.HookType {
+	return []agent.HookType{
+		agent.HookSessionStart,
+		agent.HookSessionEnd,
+		agent.HookUserPromptSubmit, // Maps to Cursor's beforeSubmitPrompt
+		agent.HookStop,
+		agent.HookPostToolUse, // Maps to Cursor's afterFileEdit
+	}
+}
+
+// Helper functions for hook management
+
+// parseCursorHookType parses a specific hook type from rawHooks into the target slice.
+// Silently ignores parse errors (leaves target unchanged).
+func parseCursorHookType(rawHooks map[string]json.RawMessage, hookType string, target *[]CursorHookEntry) {
+	if data, ok := rawHooks[hookType]; ok {
+		//nolint:errcheck,gosec // Intentionally ignoring parse errors - leave target as nil/empty
+		json.Unmarshal(data, target)
+	}
+}
+
+// marshalCursorHookType marshals a hook type back to rawHooks.
+// If the slice is empty, removes the key from rawHooks.
+func marshalCursorHookType(rawHooks map[string]json.RawMessage, hookType string, entries []CursorHookEntry) {
+	if len(entries) == 0 {
+		delete(rawHooks, hookType)
+		return
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return // Silently ignore marshal errors (shouldn't happen)
+	}
+	rawHooks[hookType] = data
+}
+
+// isEntireHook checks if a command is an Entire hook
+func isEntireHook(command string) bool {
+	for _, prefix := range entireHookPrefixes {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEntireHook checks if any entry is an Entire hook
+func hasEntireHook(entries []CursorHookEntry) bool {
+	for _, entry := range entries {
+		if isEntireHook(entry.Command) {
+			return true
+		}
+	}
+	return false
+}
+
+// getFirstEntireHookCommand returns the command of the first Entire hook found, or empty string
+func getFirstEntireHookCommand(entries []CursorHookEntry) string {
+	for _, entry := range entries {
+		if isEntireHook(entry.Command) {
+			return entry.Command
+		}
+	}
+	return ""
+}
+
+// removeEntireHooks removes all Entire hooks from a list of entries
+func removeEntireHooks(entries []CursorHookEntry) []CursorHookEntry {
+	result := make([]CursorHookEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !isEntireHook(entry.Command) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
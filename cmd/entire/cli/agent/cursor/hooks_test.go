@@ -0,0 +1,207 @@
+package cursor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallHooks_FreshInstall(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CursorAgent{}
+	count, err := ag.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if count != 5 {
+		t.Errorf("InstallHooks() count = %d, want 5", count)
+	}
+
+	hooksFile := readHooksFile(t, tempDir)
+	if hooksFile.Version != 1 {
+		t.Errorf("Version = %d, want 1", hooksFile.Version)
+	}
+	verifyCommand(t, hooksFile.Hooks.SessionStart, "entire hooks cursor session-start")
+	verifyCommand(t, hooksFile.Hooks.SessionEnd, "entire hooks cursor session-end")
+	verifyCommand(t, hooksFile.Hooks.BeforeSubmitPrompt, "entire hooks cursor before-submit-prompt")
+	verifyCommand(t, hooksFile.Hooks.Stop, "entire hooks cursor stop")
+	verifyCommand(t, hooksFile.Hooks.AfterFileEdit, "entire hooks cursor after-file-edit")
+
+	if !ag.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+}
+
+func TestInstallHooks_LocalDev(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CursorAgent{}
+	if _, err := ag.InstallHooks(true, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+
+	hooksFile := readHooksFile(t, tempDir)
+	verifyCommand(t, hooksFile.Hooks.Stop, "go run ./cmd/entire/main.go hooks cursor stop")
+}
+
+func TestInstallHooks_Idempotent(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CursorAgent{}
+	if _, err := ag.InstallHooks(false, false); err != nil {
+		t.Fatalf("first InstallHooks() error = %v", err)
+	}
+	count, err := ag.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("second InstallHooks() error = %v", err)
+	}
+	if count != 0 {
+		t.Errorf("second InstallHooks() count = %d, want 0", count)
+	}
+
+	hooksFile := readHooksFile(t, tempDir)
+	if len(hooksFile.Hooks.Stop) != 1 {
+		t.Errorf("Stop hooks = %d, want 1", len(hooksFile.Hooks.Stop))
+	}
+
+	count, err = ag.InstallHooks(false, true)
+	if err != nil {
+		t.Fatalf("forced InstallHooks() error = %v", err)
+	}
+	if count != 5 {
+		t.Errorf("forced InstallHooks() count = %d, want 5", count)
+	}
+}
+
+func TestInstallHooks_PreservesUserHooksAndUnknownTypes(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	writeHooksFile(t, tempDir, `{
+  "version": 1,
+  "hooks": {
+    "stop": [{"command": "./scripts/notify.sh"}],
+    "beforeShellExecution": [{"command": "./scripts/audit.sh"}]
+  }
+}`)
+
+	ag := &CursorAgent{}
+	if _, err := ag.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+
+	hooksFile := readHooksFile(t, tempDir)
+	if len(hooksFile.Hooks.Stop) != 2 {
+		t.Fatalf("Stop hooks = %d, want 2", len(hooksFile.Hooks.Stop))
+	}
+	if hooksFile.Hooks.Stop[0].Command != "./scripts/notify.sh" {
+		t.Errorf("user stop hook = %q, want ./scripts/notify.sh", hooksFile.Hooks.Stop[0].Command)
+	}
+
+	raw := readRawHooks(t, tempDir)
+	if _, ok := raw["beforeShellExecution"]; !ok {
+		t.Error("beforeShellExecution hook should be preserved")
+	}
+}
+
+func TestUninstallHooks(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	writeHooksFile(t, tempDir, `{
+  "version": 1,
+  "hooks": {
+    "stop": [{"command": "./scripts/notify.sh"}],
+    "beforeShellExecution": [{"command": "./scripts/audit.sh"}]
+  }
+}`)
+
+	ag := &CursorAgent{}
+	if _, err := ag.InstallHooks(false, false); err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if err := ag.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if ag.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true after uninstall")
+	}
+
+	hooksFile := readHooksFile(t, tempDir)
+	if len(hooksFile.Hooks.Stop) != 1 || hooksFile.Hooks.Stop[0].Command != "./scripts/notify.sh" {
+		t.Errorf("Stop hooks = %v, want only user hook", hooksFile.Hooks.Stop)
+	}
+	if len(hooksFile.Hooks.SessionStart) != 0 {
+		t.Errorf("SessionStart hooks = %d, want 0", len(hooksFile.Hooks.SessionStart))
+	}
+	raw := readRawHooks(t, tempDir)
+	if _, ok := raw["beforeShellExecution"]; !ok {
+		t.Error("beforeShellExecution hook should be preserved")
+	}
+}
+
+func TestUninstallHooks_NoHooksFile(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Chdir(tempDir)
+
+	ag := &CursorAgent{}
+	if err := ag.UninstallHooks(); err != nil {
+		t.Errorf("UninstallHooks() error = %v, want nil", err)
+	}
+}
+
+func writeHooksFile(t *testing.T, dir, content string) {
+	t.Helper()
+	cursorDir := filepath.Join(dir, ".cursor")
+	if err := os.MkdirAll(cursorDir, 0o755); err != nil {
+		t.Fatalf("failed to create .cursor: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cursorDir, HooksFileName), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write hooks.json: %v", err)
+	}
+}
+
+func readHooksFile(t *testing.T, dir string) CursorHooksFile {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ".cursor", HooksFileName))
+	if err != nil {
+		t.Fatalf("failed to read hooks.json: %v", err)
+	}
+	var hooksFile CursorHooksFile
+	if err := json.Unmarshal(data, &hooksFile); err != nil {
+		t.Fatalf("failed to parse hooks.json: %v", err)
+	}
+	return hooksFile
+}
+
+func readRawHooks(t *testing.T, dir string) map[string]json.RawMessage {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, ".cursor", HooksFileName))
+	if err != nil {
+		t.Fatalf("failed to read hooks.json: %v", err)
+	}
+	var rawFile map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rawFile); err != nil {
+		t.Fatalf("failed to parse hooks.json: %v", err)
+	}
+	var rawHooks map[string]json.RawMessage
+	if err := json.Unmarshal(rawFile["hooks"], &rawHooks); err != nil {
+		t.Fatalf("failed to parse hooks: %v", err)
+	}
+	return rawHooks
+}
+
+func verifyCommand(t *testing.T, entries []CursorHookEntry, want string) {
+	t.Helper()
+	for _, entry := range entries {
+		if entry.Command == want {
+			return
+		}
+	}
+	t.Errorf("hook command %q not found in %v", want, entries)
+}
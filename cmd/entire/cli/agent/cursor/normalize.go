@@ -0,0 +1,60 @@
+package cursor
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Ensure CursorAgent implements TranscriptNormalizer
+var _ agent.TranscriptNormalizer = (*CursorAgent)(nil)
+
+// NormalizeTranscript converts a Cursor JSONL transcript into session entries.
+// Cursor transcripts carry no IDs, timestamps or tool results, so entries
+// only have content, tool names and tool inputs.
+func (c *CursorAgent) NormalizeTranscript(content []byte) ([]agent.SessionEntry, error) {
+	lines, err := ParseTranscript(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []agent.SessionEntry
+	for _, line := range lines {
+		msg := parseMessage(line)
+		if msg == nil {
+			continue
+		}
+
+		switch line.Role {
+		case RoleUser:
+			if prompt := CleanUserQuery(messageText(msg)); prompt != "" {
+				entries = append(entries, agent.SessionEntry{Type: agent.EntryUser, Content: prompt})
+			}
+
+		case RoleAssistant:
+			for _, block := range msg.Content {
+				switch block.Type {
+				case contentTypeText:
+					if block.Text != "" {
+						entries = append(entries, agent.SessionEntry{Type: agent.EntryAssistant, Content: block.Text})
+					}
+				case contentTypeToolUse:
+					entry := agent.SessionEntry{Type: agent.EntryTool, ToolName: block.Name}
+					var input interface{}
+					if json.Unmarshal(block.Input, &input) == nil {
+						entry.ToolInput = input
+					}
+					if slices.Contains(FileModificationTools, block.Name) {
+						var fileInput toolInput
+						if json.Unmarshal(block.Input, &fileInput) == nil && fileInput.file() != "" {
+							entry.FilesAffected = []string{fileInput.file()}
+						}
+					}
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,44 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestNormalizeTranscript(t *testing.T) {
+	t.Parallel()
+
+	entries, err := (&CursorAgent{}).NormalizeTranscript([]byte(testTranscript))
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+
+	want := []struct {
+		typ     agent.EntryType
+		content string
+		tool    string
+		file    string
+	}{
+		{agent.EntryUser, "Add a README", "", ""},
+		{agent.EntryAssistant, "Creating it now.", "", ""},
+		{agent.EntryTool, "", "write", "README.md"},
+		{agent.EntryUser, "Also fix main.go", "", ""},
+		{agent.EntryTool, "", "edit_file", "main.go"},
+		{agent.EntryTool, "", "read_file", ""},
+		{agent.EntryAssistant, "Done.", "", ""},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		var file string
+		if len(e.FilesAffected) > 0 {
+			file = e.FilesAffected[0]
+		}
+		if e.Type != w.typ || e.Content != w.content || e.ToolName != w.tool || file != w.file {
+			t.Errorf("entries[%d] = %+v, want %+v", i, e, w)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package cursor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/textutil"
+)
+
+// Transcript parsing types - Cursor stores agent transcripts as JSONL
+// Based on transcript_path format: ~/.cursor/projects/<sanitized-path>/agent-transcripts/<conversation-id>.jsonl
+
+// Role constants for Cursor transcript lines
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Content block type constants for Cursor transcript messages
+const (
+	contentTypeText    = "text"
+	contentTypeToolUse = "tool_use"
+)
+
+// Scanner buffer size for large transcript files (10MB)
+const scannerBufferSize = 10 * 1024 * 1024
+
+// TranscriptLine represents a single line in a Cursor JSONL transcript
+type TranscriptLine struct {
+	Role    string          `json:"role"`
+	Message json.RawMessage `json:"message"`
+}
+
+// transcriptMessage is the message body of a transcript line.
+type transcriptMessage struct {
+	Content []ContentBlock `json:"content"`
+	Usage   *messageUsage  `json:"usage,omitempty"`
+}
+
+// ContentBlock represents a block within a Cursor transcript message
+type ContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// toolInput holds the file path fields used by Cursor's file tools.
+// Cursor's edit tools use target_file; newer tool versions use path or file_path.
+type toolInput struct {
+	TargetFile string `json:"target_file,omitempty"`
+	FilePath   string `json:"file_path,omitempty"`
+	Path       string `json:"path,omitempty"`
+}
+
+// file returns the first non-empty path field.
+func (t toolInput) file() string {
+	switch {
+	case t.TargetFile != "":
+		return t.TargetFile
+	case t.FilePath != "":
+		return t.FilePath
+	default:
+		return t.Path
+	}
+}
+
+// ParseTranscript parses raw JSONL content into transcript lines
+func ParseTranscript(data []byte) ([]TranscriptLine, error) {
+	var lines []TranscriptLine
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, scannerBufferSize), scannerBufferSize)
+
+	for scanner.Scan() {
+		var line TranscriptLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue // Skip malformed lines
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan transcript: %w", err)
+	}
+	return lines, nil
+}
+
+// parseMessage decodes the message body of a transcript line.
+// Returns nil if the message is missing or malformed.
+func parseMessage(line TranscriptLine) *transcriptMessage {
+	if len(line.Message) == 0 {
+		return nil
+	}
+	var msg transcriptMessage
+	if err := json.Unmarshal(line.Message, &msg); err != nil {
+		return nil
+	}
+	return &msg
+}
+
+// ContentBlocks returns the content blocks of the line's message.
+// Returns nil if the message is missing or malformed.
+func (l TranscriptLine) ContentBlocks() []ContentBlock {
+	msg := parseMessage(l)
+	if msg == nil {
+		return nil
+	}
+	return msg.Content
+}
+
+// messageText joins the text blocks of a message.
+func messageText(msg *transcriptMessage) string {
+	var texts []string
+	for _, block := range msg.Content {
+		if block.Type == contentTypeText && block.Text != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// CleanUserQuery strips the <user_query> wrapper Cursor adds around typed prompts,
+// along with any IDE context tags.
+func CleanUserQuery(text string) string {
+	text = strings.TrimSpace(text)
+	if start := strings.Index(text, "<user_query>"); start >= 0 {
+		if end := strings.LastIndex(text, "</user_query>"); end > start {
+			text = text[start+len("<user_query>") : end]
+		}
+	}
+	return strings.TrimSpace(textutil.StripIDEContextTags(text))
+}
+
+// ExtractModifiedFiles extracts files modified by tool calls from transcript lines
+func ExtractModifiedFiles(lines []TranscriptLine) []string {
+	fileSet := make(map[string]bool)
+	var files []string
+
+	for _, line := range lines {
+		if line.Role != RoleAssistant {
+			continue
+		}
+
+		msg := parseMessage(line)
+		if msg == nil {
+			continue
+		}
+
+		for _, block := range msg.Content {
+			if block.Type != contentTypeToolUse {
+				continue
+			}
+
+			// Check if it's a file modification tool
+			isModifyTool := false
+			for _, name := range FileModificationTools {
+				if block.Name == name {
+					isModifyTool = true
+					break
+				}
+			}
+
+			if !isModifyTool {
+				continue
+			}
+
+			var input toolInput
+			if err := json.Unmarshal(block.Input, &input); err != nil {
+				continue
+			}
+
+			if file := input.file(); file != "" && !fileSet[file] {
+				fileSet[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	return files
+}
+
+// ExtractAllUserPrompts extracts all user prompts from transcript data
+func ExtractAllUserPrompts(data []byte) ([]string, error) {
+	lines, err := ParseTranscript(data)
+	if err != nil {
+		return nil, err
+	}
+	return ExtractAllUserPromptsFromLines(lines), nil
+}
+
+// ExtractAllUserPromptsFromLines extracts all user prompts from parsed transcript lines
+func ExtractAllUserPromptsFromLines(lines []TranscriptLine) []string {
+	var prompts []string
+	for _, line := range lines {
+		if line.Role != RoleUser {
+			continue
+		}
+		msg := parseMessage(line)
+		if msg == nil {
+			continue
+		}
+		if prompt := CleanUserQuery(messageText(msg)); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+	}
+	return prompts
+}
+
+// ExtractLastAssistantMessage extracts the last assistant response from transcript data
+func ExtractLastAssistantMessage(data []byte) (string, error) {
+	lines, err := ParseTranscript(data)
+	if err != nil {
+		return "", err
+	}
+	return ExtractLastAssistantMessageFromLines(lines), nil
+}
+
+// ExtractLastAssistantMessageFromLines extracts the last assistant response from parsed transcript lines
+func ExtractLastAssistantMessageFromLines(lines []TranscriptLine) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i].Role != RoleAssistant {
+			continue
+		}
+		msg := parseMessage(lines[i])
+		if msg == nil {
+			continue
+		}
+		if text := messageText(msg); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// CalculateTokenUsage calculates token usage from Cursor transcript data.
+// Each assistant line that carries a usage object counts as one API call.
+// Only processes lines from startLine onwards (0-indexed).
+func CalculateTokenUsage(data []byte, startLine int) *agent.TokenUsage {
+	lines, err := ParseTranscript(data)
+	if err != nil {
+		return &agent.TokenUsage{}
+	}
+	if startLine > 0 {
+		if startLine >= len(lines) {
+			return &agent.TokenUsage{}
+		}
+		lines = lines[startLine:]
+	}
+
+	usage := &agent.TokenUsage{}
+	for _, line := range lines {
+		if line.Role != RoleAssistant {
+			continue
+		}
+		msg := parseMessage(line)
+		if msg == nil || msg.Usage == nil {
+			continue
+		}
+
+		usage.APICallCount++
+		usage.InputTokens += msg.Usage.InputTokens
+		usage.CacheCreationTokens += msg.Usage.CacheCreationInputTokens
+		usage.CacheReadTokens += msg.Usage.CacheReadInputTokens
+		usage.OutputTokens += msg.Usage.OutputTokens
+	}
+
+	return usage
+}
+
+// CalculateTokenUsageFromFile calculates token usage from a Cursor transcript file.
+// If startLine > 0, only considers lines from startLine onwards.
+func CalculateTokenUsageFromFile(path string, startLine int) (*agent.TokenUsage, error) {
+	if path == "" {
+		return &agent.TokenUsage{}, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // Reading from controlled transcript path
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &agent.TokenUsage{}, nil
+		}
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+
+	return CalculateTokenUsage(data, startLine), nil
+}
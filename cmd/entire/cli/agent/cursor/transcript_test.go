@@ -0,0 +1,106 @@
+package cursor
+
+import (
+	"testing"
+)
+
+const testTranscript = `{"role":"user","message":{"content":[{"type":"text","text":"<user_query>\nAdd a README\n</user_query>"}]}}
+{"role":"assistant","message":{"content":[{"type":"text","text":"Creating it now."},{"type":"tool_use","name":"write","input":{"path":"README.md"}}],"usage":{"input_tokens":100,"output_tokens":20,"cache_read_input_tokens":5}}}
+{"role":"user","message":{"content":[{"type":"text","text":"Also fix main.go"}]}}
+{"role":"assistant","message":{"content":[{"type":"tool_use","name":"edit_file","input":{"target_file":"main.go"}},{"type":"tool_use","name":"read_file","input":{"target_file":"go.mod"}}],"usage":{"input_tokens":200,"output_tokens":30,"cache_creation_input_tokens":10}}}
+{"role":"assistant","message":{"content":[{"type":"text","text":"Done."}]}}
+`
+
+func TestExtractAllUserPrompts(t *testing.T) {
+	t.Parallel()
+
+	prompts, err := ExtractAllUserPrompts([]byte(testTranscript))
+	if err != nil {
+		t.Fatalf("ExtractAllUserPrompts() error = %v", err)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("got %d prompts, want 2: %v", len(prompts), prompts)
+	}
+	if prompts[0] != "Add a README" {
+		t.Errorf("prompts[0] = %q, want %q", prompts[0], "Add a README")
+	}
+	if prompts[1] != "Also fix main.go" {
+		t.Errorf("prompts[1] = %q, want %q", prompts[1], "Also fix main.go")
+	}
+}
+
+func TestExtractLastAssistantMessage(t *testing.T) {
+	t.Parallel()
+
+	msg, err := ExtractLastAssistantMessage([]byte(testTranscript))
+	if err != nil {
+		t.Fatalf("ExtractLastAssistantMessage() error = %v", err)
+	}
+	if msg != "Done." {
+		t.Errorf("ExtractLastAssistantMessage() = %q, want %q", msg, "Done.")
+	}
+}
+
+func TestExtractModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	lines, err := ParseTranscript([]byte(testTranscript))
+	if err != nil {
+		t.Fatalf("ParseTranscript() error = %v", err)
+	}
+	files := ExtractModifiedFiles(lines)
+	if len(files) != 2 || files[0] != "README.md" || files[1] != "main.go" {
+		t.Errorf("ExtractModifiedFiles() = %v, want [README.md main.go]", files)
+	}
+}
+
+func TestParseTranscript_SkipsMalformedLines(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("not json\n" + `{"role":"user","message":{"content":[]}}` + "\n")
+	lines, err := ParseTranscript(data)
+	if err != nil {
+		t.Fatalf("ParseTranscript() error = %v", err)
+	}
+	if len(lines) != 1 {
+		t.Errorf("got %d lines, want 1", len(lines))
+	}
+}
+
+func TestCalculateTokenUsage(t *testing.T) {
+	t.Parallel()
+
+	usage := CalculateTokenUsage([]byte(testTranscript), 0)
+	if usage.APICallCount != 2 {
+		t.Errorf("APICallCount = %d, want 2", usage.APICallCount)
+	}
+	if usage.InputTokens != 300 {
+		t.Errorf("InputTokens = %d, want 300", usage.InputTokens)
+	}
+	if usage.OutputTokens != 50 {
+		t.Errorf("OutputTokens = %d, want 50", usage.OutputTokens)
+	}
+	if usage.CacheReadTokens != 5 {
+		t.Errorf("CacheReadTokens = %d, want 5", usage.CacheReadTokens)
+	}
+	if usage.CacheCreationTokens != 10 {
+		t.Errorf("CacheCreationTokens = %d, want 10", usage.CacheCreationTokens)
+	}
+}
+
+func TestCalculateTokenUsage_FromOffset(t *testing.T) {
+	t.Parallel()
+
+	usage := CalculateTokenUsage([]byte(testTranscript), 2)
+	if usage.APICallCount != 1 {
+		t.Errorf("APICallCount = %d, want 1", usage.APICallCount)
+	}
+	if usage.InputTokens != 200 {
+		t.Errorf("InputTokens = %d, want 200", usage.InputTokens)
+	}
+
+	usage = CalculateTokenUsage([]byte(testTranscript), 99)
+	if usage.APICallCount != 0 {
+		t.Errorf("APICallCount past end = %d, want 0", usage.APICallCount)
+	}
+}
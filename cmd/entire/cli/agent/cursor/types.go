@@ -0,0 +1,102 @@
+package cursor
+
+import "encoding/json"
+
+// CursorHooksFile represents the .cursor/hooks.json structure
+//
+//nolint:revive // CursorHooksFile is clearer than HooksFile in this context
+type CursorHooksFile struct {
+	Version int         `json:"version"`
+	Hooks   CursorHooks `json:"hooks"`
+}
+
+// CursorHooks contains the hook configurations
+//
+//nolint:revive // CursorHooks is clearer than Hooks in this context
+type CursorHooks struct {
+	SessionStart       []CursorHookEntry `json:"sessionStart,omitempty"`
+	SessionEnd         []CursorHookEntry `json:"sessionEnd,omitempty"`
+	BeforeSubmitPrompt []CursorHookEntry `json:"beforeSubmitPrompt,omitempty"`
+	Stop               []CursorHookEntry `json:"stop,omitempty"`
+	AfterFileEdit      []CursorHookEntry `json:"afterFileEdit,omitempty"`
+}
+
+// CursorHookEntry represents a single hook command.
+// Unlike Claude Code and Gemini CLI, Cursor has no matcher layer:
+// each hook event maps directly to a list of commands.
+//
+//nolint:revive // CursorHookEntry is clearer than HookEntry in this context
+type CursorHookEntry struct {
+	Command string `json:"command"`
+}
+
+// hookInputRaw contains the fields Cursor sends with every hook event.
+// Cursor identifies a chat by conversation_id; newer versions also send
+// session_id on session lifecycle events.
+type hookInputRaw struct {
+	ConversationID string   `json:"conversation_id"`
+	SessionID      string   `json:"session_id,omitempty"`
+	GenerationID   string   `json:"generation_id"`
+	HookEventName  string   `json:"hook_event_name"`
+	WorkspaceRoots []string `json:"workspace_roots"`
+	TranscriptPath string   `json:"transcript_path,omitempty"`
+	Model          string   `json:"model,omitempty"`
+}
+
+// beforeSubmitPromptRaw is the JSON structure from beforeSubmitPrompt hooks.
+type beforeSubmitPromptRaw struct {
+	hookInputRaw
+
+	Prompt string `json:"prompt"`
+}
+
+// stopRaw is the JSON structure from stop hooks.
+type stopRaw struct {
+	hookInputRaw
+
+	Status    string `json:"status,omitempty"` // completed, aborted, error
+	LoopCount int    `json:"loop_count,omitempty"`
+}
+
+// sessionEndRaw is the JSON structure from sessionEnd hooks.
+type sessionEndRaw struct {
+	hookInputRaw
+
+	Reason string `json:"reason,omitempty"`
+}
+
+// afterFileEditRaw is the JSON structure from afterFileEdit hooks.
+type afterFileEditRaw struct {
+	hookInputRaw
+
+	FilePath string            `json:"file_path"`
+	Edits    []json.RawMessage `json:"edits,omitempty"`
+}
+
+// Tool names used in Cursor transcripts that create, modify or delete files
+const (
+	ToolEditFile      = "edit_file"
+	ToolSearchReplace = "search_replace"
+	ToolWrite         = "write"
+	ToolMultiEdit     = "multi_edit"
+	ToolDeleteFile    = "delete_file"
+)
+
+// FileModificationTools lists tools that create or modify files in Cursor
+var FileModificationTools = []string{
+	ToolEditFile,
+	ToolSearchReplace,
+	ToolWrite,
+	ToolMultiEdit,
+	ToolDeleteFile,
+}
+
+// messageUsage represents token usage attached to a Cursor assistant message.
+// Cursor only records usage when the model provider reports it, so every
+// field is optional.
+type messageUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+}
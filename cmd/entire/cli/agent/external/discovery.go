@@ -0,0 +1,152 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Plugin is a discovered agent plugin executable and the info it reported.
+type Plugin struct {
+	Path string
+	Info Info
+}
+
+// Discover finds agent plugins on PATH and at the given extra paths.
+// Plugins on PATH are named entire-agent-<name>, and the first one found for a
+// name wins, as with other PATH lookups. Extra paths may have any file name;
+// they are listed before PATH plugins so configured plugins take precedence.
+// Plugins that fail to start or report invalid info are returned as errors
+// rather than aborting discovery.
+func Discover(extraPaths []string) ([]Plugin, []error) {
+	var plugins []Plugin
+	var errs []error
+	seen := make(map[string]bool)
+
+	add := func(path, wantName string) {
+		info, err := LoadInfo(path)
+		if err == nil && wantName != "" && info.Name != wantName {
+			err = fmt.Errorf("reports name %q, want %q", info.Name, wantName)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("agent plugin %s: %w", path, err))
+			return
+		}
+		if seen[info.Name] {
+			return
+		}
+		seen[info.Name] = true
+		plugins = append(plugins, Plugin{Path: path, Info: info})
+	}
+
+	for _, path := range extraPaths {
+		add(path, "")
+	}
+	for _, candidate := range findOnPath(os.Getenv("PATH")) {
+		add(candidate.path, candidate.name)
+	}
+
+	return plugins, errs
+}
+
+// LoadInfo asks the plugin at path for its info and validates it.
+func LoadInfo(path string) (Info, error) {
+	var info Info
+	if err := invoke(path, MethodInfo, nil, &info); err != nil {
+		return Info{}, err
+	}
+	if info.ProtocolVersion != ProtocolVersion {
+		return Info{}, fmt.Errorf("unsupported protocol version %d (want %d)", info.ProtocolVersion, ProtocolVersion)
+	}
+	if info.Name == "" {
+		return Info{}, errors.New("info has no name")
+	}
+	if info.Type == "" {
+		return Info{}, errors.New("info has no type")
+	}
+	return info, nil
+}
+
+// Register adds discovered plugins to the agent registry.
+// Built-in agents take precedence: a plugin whose name or type is already
+// registered is skipped and reported as an error.
+func Register(plugins []Plugin) []error {
+	var errs []error
+	for _, p := range plugins {
+		name := agent.AgentName(p.Info.Name)
+		if existing, err := agent.Get(name); err == nil {
+			errs = append(errs, fmt.Errorf("agent plugin %s: name %q is already used by %s", p.Path, name, existing.Type()))
+			continue
+		}
+		if existing, err := agent.GetByAgentType(agent.AgentType(p.Info.Type)); err == nil {
+			errs = append(errs, fmt.Errorf("agent plugin %s: type %q is already used by %s", p.Path, p.Info.Type, existing.Name()))
+			continue
+		}
+		agent.Register(name, func() agent.Agent {
+			return New(p.Path, p.Info)
+		})
+	}
+	return errs
+}
+
+// pathCandidate is a plugin executable found on PATH.
+type pathCandidate struct {
+	name string
+	path string
+}
+
+// findOnPath returns the entire-agent-* executables in the directories of pathList,
+// in PATH order.
+func findOnPath(pathList string) []pathCandidate {
+	var candidates []pathCandidate
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			candidates = append(candidates, pathCandidate{name: name, path: path})
+		}
+	}
+	return candidates
+}
+
+// pluginName returns the agent name encoded in a plugin file name.
+func pluginName(fileName string) (string, bool) {
+	name, ok := strings.CutPrefix(fileName, ExecutablePrefix)
+	if !ok {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, ".exe")
+	}
+	return name, name != ""
+}
+
+// isExecutable reports whether path is a regular file the user can execute.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
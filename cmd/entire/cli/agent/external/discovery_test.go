@@ -0,0 +1,131 @@
+package external
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// writeScriptPlugin writes a shell script plugin that prints the given response.
+func writeScriptPlugin(t *testing.T, dir, name, response string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("script plugins require a POSIX shell")
+	}
+	path := filepath.Join(dir, name)
+	script := "#!/bin/sh\ncat >/dev/null\necho '" + response + "'\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil { //nolint:gosec // Test plugin must be executable
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	return path
+}
+
+func TestDiscover_FindsPluginOnPath(t *testing.T) {
+	t.Setenv("PATH", referencePluginDir+string(os.PathListSeparator)+t.TempDir())
+
+	plugins, errs := Discover(nil)
+	if len(errs) != 0 {
+		t.Fatalf("Discover() errors = %v", errs)
+	}
+	if len(plugins) != 1 {
+		t.Fatalf("Discover() found %d plugins, want 1", len(plugins))
+	}
+	if plugins[0].Info.Name != "reference" || plugins[0].Path != referencePluginPath() {
+		t.Errorf("unexpected plugin: %+v", plugins[0])
+	}
+}
+
+func TestDiscover_ConfiguredPathTakesPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	configured := writeScriptPlugin(t, dir, "my-reference",
+		`{"protocol_version":1,"result":{"protocol_version":1,"name":"reference","type":"Configured"}}`)
+	t.Setenv("PATH", referencePluginDir)
+
+	plugins, errs := Discover([]string{configured})
+	if len(errs) != 0 {
+		t.Fatalf("Discover() errors = %v", errs)
+	}
+	if len(plugins) != 1 || plugins[0].Path != configured {
+		t.Fatalf("Discover() = %+v, want only the configured plugin", plugins)
+	}
+}
+
+func TestDiscover_ReportsBrokenPlugins(t *testing.T) {
+	dir := t.TempDir()
+	writeScriptPlugin(t, dir, ExecutablePrefix+"old",
+		`{"protocol_version":1,"result":{"protocol_version":99,"name":"old","type":"Old"}}`)
+	writeScriptPlugin(t, dir, ExecutablePrefix+"misnamed",
+		`{"protocol_version":1,"result":{"protocol_version":1,"name":"other","type":"Other"}}`)
+	// Not executable, so not a plugin
+	if err := os.WriteFile(filepath.Join(dir, ExecutablePrefix+"data"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	t.Setenv("PATH", dir)
+
+	plugins, errs := Discover(nil)
+	if len(plugins) != 0 {
+		t.Errorf("Discover() = %+v, want no plugins", plugins)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Discover() errors = %v, want 2", errs)
+	}
+	joined := errs[0].Error() + "\n" + errs[1].Error()
+	if !strings.Contains(joined, "unsupported protocol version 99") {
+		t.Errorf("expected protocol version error, got: %s", joined)
+	}
+	if !strings.Contains(joined, `reports name "other", want "misnamed"`) {
+		t.Errorf("expected name mismatch error, got: %s", joined)
+	}
+}
+
+func TestInvoke_ResponseVersionMismatch(t *testing.T) {
+	t.Parallel()
+	path := writeScriptPlugin(t, t.TempDir(), "plugin", `{"protocol_version":2,"result":true}`)
+
+	err := invoke(path, MethodDetectPresence, nil, new(bool))
+	if err == nil || !strings.Contains(err.Error(), "unsupported protocol version 2") {
+		t.Errorf("invoke() error = %v, want protocol version error", err)
+	}
+}
+
+func TestInvoke_PluginError(t *testing.T) {
+	t.Parallel()
+	path := writeScriptPlugin(t, t.TempDir(), "plugin", `{"protocol_version":1,"error":"session not found"}`)
+
+	err := invoke(path, MethodReadSession, HookInput{}, &Session{})
+	if err == nil || !strings.Contains(err.Error(), "session not found") {
+		t.Errorf("invoke() error = %v, want plugin error", err)
+	}
+}
+
+func TestRegister_BuiltinTakesPrecedence(t *testing.T) {
+	agent.Register("builtin-test", func() agent.Agent {
+		return New("/nonexistent", Info{Name: "builtin-test", Type: "Builtin Test"})
+	})
+
+	errs := Register([]Plugin{
+		{Path: "/plugin-a", Info: Info{Name: "builtin-test", Type: "Plugin A"}},
+		{Path: "/plugin-b", Info: Info{Name: "plugin-b", Type: "Builtin Test"}},
+	})
+	if len(errs) != 2 {
+		t.Fatalf("Register() errors = %v, want 2", errs)
+	}
+	if _, err := agent.Get("plugin-b"); err == nil {
+		t.Error("plugin with a duplicate type should not be registered")
+	}
+
+	if errs := Register([]Plugin{{Path: referencePluginPath(), Info: Info{Name: "registered-reference", Type: "Registered Reference"}}}); len(errs) != 0 {
+		t.Fatalf("Register() errors = %v", errs)
+	}
+	ag, err := agent.Get("registered-reference")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if ag.Type() != "Registered Reference" {
+		t.Errorf("Type() = %q", ag.Type())
+	}
+}
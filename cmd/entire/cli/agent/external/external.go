@@ -0,0 +1,368 @@
+// Package external implements the Agent interface for agent plugins: executables
+// named entire-agent-<name> that are discovered on PATH or listed in settings.
+// Every Agent, HookSupport and TranscriptAnalyzer method is proxied to the plugin
+// as a JSON request on stdin, answered by a JSON response on stdout (see protocol.go).
+package external
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// callTimeout bounds a single plugin invocation so a hung plugin can't block hooks.
+const callTimeout = 30 * time.Second
+
+// Agent proxies the Agent interface to a plugin executable.
+// Use New to get an agent that also implements the optional interfaces the
+// plugin declares; Agent itself only implements agent.Agent.
+type Agent struct {
+	path string
+	info Info
+}
+
+// New returns the agent for a plugin described by info. The returned value
+// implements agent.HookSupport and agent.HookHandler if the plugin declares
+// hook support, and agent.TranscriptAnalyzer if it declares transcript analysis,
+// so callers' type assertions reflect what the plugin can actually do.
+func New(path string, info Info) agent.Agent {
+	base := &Agent{path: path, info: info}
+	hooks := hookMethods{a: base}
+	analyzer := analyzerMethods{a: base}
+
+	switch {
+	case info.Capabilities.Hooks && info.Capabilities.TranscriptAnalyzer:
+		return &hookAnalyzerAgent{Agent: base, hookMethods: hooks, analyzerMethods: analyzer}
+	case info.Capabilities.Hooks:
+		return &hookAgent{Agent: base, hookMethods: hooks}
+	case info.Capabilities.TranscriptAnalyzer:
+		return &analyzerAgent{Agent: base, analyzerMethods: analyzer}
+	default:
+		return base
+	}
+}
+
+// Path returns the plugin executable.
+func (a *Agent) Path() string {
+	return a.path
+}
+
+// Name returns the agent registry key.
+func (a *Agent) Name() agent.AgentName {
+	return agent.AgentName(a.info.Name)
+}
+
+// Type returns the agent type identifier.
+func (a *Agent) Type() agent.AgentType {
+	return agent.AgentType(a.info.Type)
+}
+
+// Description returns a human-readable description.
+func (a *Agent) Description() string {
+	return a.info.Description
+}
+
+// DetectPresence asks the plugin whether its agent is configured in the repository.
+func (a *Agent) DetectPresence() (bool, error) {
+	var present bool
+	if err := a.call(MethodDetectPresence, nil, &present); err != nil {
+		return false, err
+	}
+	return present, nil
+}
+
+// GetHookConfigPath returns the hook config path the plugin reported.
+func (a *Agent) GetHookConfigPath() string {
+	return a.info.HookConfigPath
+}
+
+// SupportsHooks returns whether the plugin declared hook support.
+func (a *Agent) SupportsHooks() bool {
+	return a.info.Capabilities.Hooks
+}
+
+// ParseHookInput sends the raw hook payload to the plugin for parsing.
+func (a *Agent) ParseHookInput(hookType agent.HookType, reader io.Reader) (*agent.HookInput, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hook input: %w", err)
+	}
+
+	var input HookInput
+	params := ParseHookInputParams{HookType: hookType, Payload: string(data)}
+	if err := a.call(MethodParseHookInput, params, &input); err != nil {
+		return nil, err
+	}
+	if input.HookType == "" {
+		input.HookType = hookType
+	}
+	return input.toAgent(), nil
+}
+
+// GetSessionID asks the plugin for the session ID of a hook input.
+// Falls back to the input's SessionID if the plugin can't answer.
+func (a *Agent) GetSessionID(input *agent.HookInput) string {
+	var sessionID string
+	if err := a.call(MethodGetSessionID, newHookInput(input), &sessionID); err != nil {
+		return input.SessionID
+	}
+	return sessionID
+}
+
+// ProtectedDirs returns the protected paths the plugin reported.
+func (a *Agent) ProtectedDirs() []string {
+	return a.info.ProtectedDirs
+}
+
+// GetSessionDir asks the plugin where it stores sessions for the repository.
+func (a *Agent) GetSessionDir(repoPath string) (string, error) {
+	var dir string
+	if err := a.call(MethodGetSessionDir, GetSessionDirParams{RepoPath: repoPath}, &dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ResolveSessionFile asks the plugin for a session's transcript path.
+// Returns an empty string if the plugin can't answer.
+func (a *Agent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	var path string
+	params := ResolveSessionFileParams{SessionDir: sessionDir, AgentSessionID: agentSessionID}
+	if err := a.call(MethodResolveSessionFile, params, &path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// ReadSession asks the plugin to read a session from its storage.
+func (a *Agent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	var session Session
+	if err := a.call(MethodReadSession, newHookInput(input), &session); err != nil {
+		return nil, err
+	}
+	sess := session.toAgent()
+	if sess.AgentName == "" {
+		sess.AgentName = a.Name()
+	}
+	return sess, nil
+}
+
+// WriteSession asks the plugin to write a session back to its storage.
+func (a *Agent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+	return a.call(MethodWriteSession, newSession(session), nil)
+}
+
+// FormatResumeCommand asks the plugin for the command that resumes a session.
+// Returns an empty string if the plugin can't answer.
+func (a *Agent) FormatResumeCommand(sessionID string) string {
+	var command string
+	if err := a.call(MethodFormatResumeCommand, FormatResumeCommandParams{SessionID: sessionID}, &command); err != nil {
+		return ""
+	}
+	return command
+}
+
+// IsPlugin reports whether ag is an agent plugin rather than a built-in agent.
+func IsPlugin(ag agent.Agent) bool {
+	_, ok := ag.(interface{ plugin() *Agent })
+	return ok
+}
+
+// HookTypeFor returns the lifecycle event a plugin hook verb signals.
+// Returns false if ag is not a plugin agent or doesn't declare the verb.
+func HookTypeFor(ag agent.Agent, hookName string) (agent.HookType, bool) {
+	p, ok := ag.(interface{ plugin() *Agent })
+	if !ok {
+		return "", false
+	}
+	for _, hook := range p.plugin().info.Hooks {
+		if hook.Name == hookName {
+			return hook.Type, true
+		}
+	}
+	return "", false
+}
+
+func (a *Agent) plugin() *Agent {
+	return a
+}
+
+// call runs the plugin with one request and decodes the response result into result.
+// result may be nil for methods that return nothing.
+func (a *Agent) call(method string, params, result any) error {
+	if err := invoke(a.path, method, params, result); err != nil {
+		return fmt.Errorf("agent plugin %s: %w", a.info.Name, err)
+	}
+	return nil
+}
+
+// invoke runs the plugin executable at path with one request.
+func invoke(path, method string, params, result any) error {
+	req := Request{ProtocolVersion: ProtocolVersion, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("%s: failed to encode params: %w", method, err)
+		}
+		req.Params = data
+	}
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("%s: failed to encode request: %w", method, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path) //nolint:gosec // Path is a discovered or configured agent plugin
+	cmd.Stdin = bytes.NewReader(reqData)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", method, err, msg)
+		}
+		return fmt.Errorf("%s: %w", method, err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return fmt.Errorf("%s: invalid response: %w", method, err)
+	}
+	if resp.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("%s: unsupported protocol version %d (want %d)", method, resp.ProtocolVersion, ProtocolVersion)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("%s: %s", method, resp.Error)
+	}
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("%s: invalid result: %w", method, err)
+	}
+	return nil
+}
+
+// hookMethods implements HookSupport and HookHandler for plugins that declare hooks.
+// It holds the agent as a named field, not embedded, so combining it with
+// analyzerMethods doesn't make the Agent methods ambiguous.
+type hookMethods struct {
+	a *Agent
+}
+
+// InstallHooks asks the plugin to install its hooks and returns how many it installed.
+func (h hookMethods) InstallHooks(localDev bool, force bool) (int, error) {
+	var count int
+	if err := h.a.call(MethodInstallHooks, InstallHooksParams{LocalDev: localDev, Force: force}, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// UninstallHooks asks the plugin to remove its hooks.
+func (h hookMethods) UninstallHooks() error {
+	return h.a.call(MethodUninstallHooks, nil, nil)
+}
+
+// AreHooksInstalled asks the plugin whether its hooks are installed.
+func (h hookMethods) AreHooksInstalled() bool {
+	var installed bool
+	if err := h.a.call(MethodAreHooksInstalled, nil, &installed); err != nil {
+		return false
+	}
+	return installed
+}
+
+// GetSupportedHooks returns the lifecycle events the plugin's hooks signal.
+func (h hookMethods) GetSupportedHooks() []agent.HookType {
+	var types []agent.HookType
+	for _, hook := range h.a.info.Hooks {
+		if !containsHookType(types, hook.Type) {
+			types = append(types, hook.Type)
+		}
+	}
+	return types
+}
+
+// GetHookNames returns the hook verbs the plugin declared.
+func (h hookMethods) GetHookNames() []string {
+	names := make([]string, 0, len(h.a.info.Hooks))
+	for _, hook := range h.a.info.Hooks {
+		names = append(names, hook.Name)
+	}
+	return names
+}
+
+func containsHookType(types []agent.HookType, t agent.HookType) bool {
+	for _, existing := range types {
+		if existing == t {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzerMethods implements TranscriptAnalyzer for plugins that declare it.
+type analyzerMethods struct {
+	a *Agent
+}
+
+// GetTranscriptPosition asks the plugin for the current transcript position.
+func (m analyzerMethods) GetTranscriptPosition(path string) (int, error) {
+	var position int
+	if err := m.a.call(MethodGetTranscriptPosition, TranscriptParams{Path: path}, &position); err != nil {
+		return 0, err
+	}
+	return position, nil
+}
+
+// ExtractModifiedFilesFromOffset asks the plugin for files modified since an offset.
+func (m analyzerMethods) ExtractModifiedFilesFromOffset(path string, startOffset int) (files []string, currentPosition int, err error) {
+	var result ModifiedFilesResult
+	if err := m.a.call(MethodExtractModifiedFiles, TranscriptParams{Path: path, StartOffset: startOffset}, &result); err != nil {
+		return nil, 0, err
+	}
+	return result.Files, result.CurrentPosition, nil
+}
+
+// Plugin agents, one type per combination of declared capabilities.
+type (
+	hookAgent struct {
+		*Agent
+		hookMethods
+	}
+
+	analyzerAgent struct {
+		*Agent
+		analyzerMethods
+	}
+
+	hookAnalyzerAgent struct {
+		*Agent
+		hookMethods
+		analyzerMethods
+	}
+)
+
+var (
+	_ agent.HookSupport        = (*hookAgent)(nil)
+	_ agent.HookHandler        = (*hookAgent)(nil)
+	_ agent.TranscriptAnalyzer = (*analyzerAgent)(nil)
+	_ agent.HookSupport        = (*hookAnalyzerAgent)(nil)
+	_ agent.HookHandler        = (*hookAnalyzerAgent)(nil)
+	_ agent.TranscriptAnalyzer = (*hookAnalyzerAgent)(nil)
+)
@@ -0,0 +1,248 @@
+package external
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// referencePluginDir holds the built reference plugin, named as it would be on PATH.
+var referencePluginDir string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "entire-agent-plugin-*")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create temp dir: %v\n", err)
+		os.Exit(1)
+	}
+	referencePluginDir = dir
+
+	name := ExecutablePrefix + "reference"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", filepath.Join(dir, name), "./testdata/reference-plugin")
+	if out, err := build.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build reference plugin: %v\n%s", err, out)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func referencePluginPath() string {
+	name := ExecutablePrefix + "reference"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(referencePluginDir, name)
+}
+
+func loadReferenceAgent(t *testing.T) agent.Agent {
+	t.Helper()
+	info, err := LoadInfo(referencePluginPath())
+	if err != nil {
+		t.Fatalf("LoadInfo() error = %v", err)
+	}
+	return New(referencePluginPath(), info)
+}
+
+func TestNew_CapabilitiesSelectInterfaces(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		capabilities Capabilities
+		wantHooks    bool
+		wantAnalyzer bool
+	}{
+		{name: "none", capabilities: Capabilities{}},
+		{name: "hooks", capabilities: Capabilities{Hooks: true}, wantHooks: true},
+		{name: "analyzer", capabilities: Capabilities{TranscriptAnalyzer: true}, wantAnalyzer: true},
+		{name: "both", capabilities: Capabilities{Hooks: true, TranscriptAnalyzer: true}, wantHooks: true, wantAnalyzer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ag := New("/nonexistent", Info{Name: "x", Type: "X", Capabilities: tt.capabilities})
+
+			_, isHookSupport := ag.(agent.HookSupport)
+			_, isHookHandler := ag.(agent.HookHandler)
+			_, isAnalyzer := ag.(agent.TranscriptAnalyzer)
+
+			if isHookSupport != tt.wantHooks || isHookHandler != tt.wantHooks {
+				t.Errorf("HookSupport = %v, HookHandler = %v, want %v", isHookSupport, isHookHandler, tt.wantHooks)
+			}
+			if isAnalyzer != tt.wantAnalyzer {
+				t.Errorf("TranscriptAnalyzer = %v, want %v", isAnalyzer, tt.wantAnalyzer)
+			}
+			if ag.SupportsHooks() != tt.wantHooks {
+				t.Errorf("SupportsHooks() = %v, want %v", ag.SupportsHooks(), tt.wantHooks)
+			}
+		})
+	}
+}
+
+func TestReferencePlugin_Info(t *testing.T) {
+	t.Parallel()
+	ag := loadReferenceAgent(t)
+
+	if ag.Name() != "reference" {
+		t.Errorf("Name() = %q, want %q", ag.Name(), "reference")
+	}
+	if ag.Type() != "Reference Agent" {
+		t.Errorf("Type() = %q, want %q", ag.Type(), "Reference Agent")
+	}
+	if dirs := ag.ProtectedDirs(); len(dirs) != 1 || dirs[0] != ".reference-agent" {
+		t.Errorf("ProtectedDirs() = %v", dirs)
+	}
+
+	handler, ok := ag.(agent.HookHandler)
+	if !ok {
+		t.Fatal("reference agent should implement HookHandler")
+	}
+	if names := handler.GetHookNames(); len(names) != 4 || names[1] != "prompt" {
+		t.Errorf("GetHookNames() = %v", names)
+	}
+	if hookType, ok := HookTypeFor(ag, "done"); !ok || hookType != agent.HookStop {
+		t.Errorf("HookTypeFor(done) = %q, %v, want %q", hookType, ok, agent.HookStop)
+	}
+	if _, ok := HookTypeFor(ag, "unknown"); ok {
+		t.Error("HookTypeFor(unknown) should not be found")
+	}
+}
+
+func TestReferencePlugin_ParseHookInput(t *testing.T) {
+	t.Parallel()
+	ag := loadReferenceAgent(t)
+
+	payload := `{"session_id":"sess-1","transcript_path":"/tmp/sess-1.jsonl","prompt":"Add tests"}`
+	input, err := ag.ParseHookInput(agent.HookUserPromptSubmit, strings.NewReader(payload))
+	if err != nil {
+		t.Fatalf("ParseHookInput() error = %v", err)
+	}
+	if input.HookType != agent.HookUserPromptSubmit {
+		t.Errorf("HookType = %q", input.HookType)
+	}
+	if input.SessionID != "sess-1" || input.SessionRef != "/tmp/sess-1.jsonl" || input.UserPrompt != "Add tests" {
+		t.Errorf("unexpected input: %+v", input)
+	}
+	if got := ag.GetSessionID(input); got != "sess-1" {
+		t.Errorf("GetSessionID() = %q", got)
+	}
+
+	if _, err := ag.ParseHookInput(agent.HookStop, strings.NewReader("not json")); err == nil {
+		t.Error("ParseHookInput() should return the plugin's error for an invalid payload")
+	}
+}
+
+func TestReferencePlugin_Sessions(t *testing.T) {
+	t.Parallel()
+	ag := loadReferenceAgent(t)
+	repo := t.TempDir()
+
+	sessionDir, err := ag.GetSessionDir(repo)
+	if err != nil {
+		t.Fatalf("GetSessionDir() error = %v", err)
+	}
+	sessionFile := ag.ResolveSessionFile(sessionDir, "sess-1")
+	if sessionFile != filepath.Join(repo, ".reference-agent", "sessions", "sess-1.jsonl") {
+		t.Errorf("ResolveSessionFile() = %q", sessionFile)
+	}
+
+	transcript := `{"role":"user","content":"Add a file"}
+{"role":"assistant","content":"Added.","files":["a.go"]}
+{"role":"user","content":"And another"}
+{"role":"assistant","content":"Done.","files":["b.go","a.go"]}
+`
+	if err := ag.WriteSession(&agent.AgentSession{
+		SessionID:  "sess-1",
+		AgentName:  ag.Name(),
+		SessionRef: sessionFile,
+		NativeData: []byte(transcript),
+	}); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+
+	sess, err := ag.ReadSession(&agent.HookInput{SessionID: "sess-1", SessionRef: sessionFile})
+	if err != nil {
+		t.Fatalf("ReadSession() error = %v", err)
+	}
+	if string(sess.NativeData) != transcript {
+		t.Error("NativeData does not round-trip")
+	}
+	if sess.GetLastUserPrompt() != "And another" || sess.GetLastAssistantResponse() != "Done." {
+		t.Errorf("unexpected entries: %+v", sess.Entries)
+	}
+	if len(sess.ModifiedFiles) != 2 {
+		t.Errorf("ModifiedFiles = %v, want [a.go b.go]", sess.ModifiedFiles)
+	}
+
+	analyzer, ok := ag.(agent.TranscriptAnalyzer)
+	if !ok {
+		t.Fatal("reference agent should implement TranscriptAnalyzer")
+	}
+	pos, err := analyzer.GetTranscriptPosition(sessionFile)
+	if err != nil || pos != 4 {
+		t.Errorf("GetTranscriptPosition() = %d, %v, want 4", pos, err)
+	}
+	files, pos, err := analyzer.ExtractModifiedFilesFromOffset(sessionFile, 2)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFilesFromOffset() error = %v", err)
+	}
+	if pos != 4 || len(files) != 2 || files[0] != "b.go" {
+		t.Errorf("ExtractModifiedFilesFromOffset() = %v, %d", files, pos)
+	}
+
+	if got := ag.FormatResumeCommand("sess-1"); got != "reference-agent --resume sess-1" {
+		t.Errorf("FormatResumeCommand() = %q", got)
+	}
+}
+
+func TestReferencePlugin_Hooks(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ag := loadReferenceAgent(t)
+
+	hooks, ok := ag.(agent.HookSupport)
+	if !ok {
+		t.Fatal("reference agent should implement HookSupport")
+	}
+
+	if present, err := ag.DetectPresence(); err != nil || present {
+		t.Errorf("DetectPresence() = %v, %v before install, want false", present, err)
+	}
+	if hooks.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true before install")
+	}
+
+	count, err := hooks.InstallHooks(false, false)
+	if err != nil {
+		t.Fatalf("InstallHooks() error = %v", err)
+	}
+	if count != 4 {
+		t.Errorf("InstallHooks() = %d, want 4", count)
+	}
+	if !hooks.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = false after install")
+	}
+	if present, err := ag.DetectPresence(); err != nil || !present {
+		t.Errorf("DetectPresence() = %v, %v after install, want true", present, err)
+	}
+
+	if err := hooks.UninstallHooks(); err != nil {
+		t.Fatalf("UninstallHooks() error = %v", err)
+	}
+	if hooks.AreHooksInstalled() {
+		t.Error("AreHooksInstalled() = true after uninstall")
+	}
+}
@@ -0,0 +1,290 @@
+package external
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// ProtocolVersion is the plugin protocol version this CLI speaks.
+// Plugins report the version they implement in their info response, and every
+// request and response carries it so either side can reject a mismatch.
+const ProtocolVersion = 1
+
+// ExecutablePrefix is the file name prefix of agent plugins on PATH.
+// The rest of the file name is the agent's registry name (entire-agent-<name>).
+const ExecutablePrefix = "entire-agent-"
+
+// Protocol methods. Each corresponds to one Agent, HookSupport or
+// TranscriptAnalyzer method; the request params and result of each are
+// documented on the types below.
+const (
+	// MethodInfo returns Info. It is called once when the plugin is discovered.
+	MethodInfo = "info"
+
+	// MethodDetectPresence has no params and returns a bool.
+	MethodDetectPresence = "detect_presence"
+
+	// MethodParseHookInput takes ParseHookInputParams and returns a HookInput.
+	MethodParseHookInput = "parse_hook_input"
+
+	// MethodGetSessionID takes a HookInput and returns a string.
+	MethodGetSessionID = "get_session_id"
+
+	// MethodGetSessionDir takes GetSessionDirParams and returns a string.
+	MethodGetSessionDir = "get_session_dir"
+
+	// MethodResolveSessionFile takes ResolveSessionFileParams and returns a string.
+	MethodResolveSessionFile = "resolve_session_file"
+
+	// MethodReadSession takes a HookInput and returns a Session.
+	MethodReadSession = "read_session"
+
+	// MethodWriteSession takes a Session and returns nothing.
+	MethodWriteSession = "write_session"
+
+	// MethodFormatResumeCommand takes FormatResumeCommandParams and returns a string.
+	MethodFormatResumeCommand = "format_resume_command"
+
+	// MethodInstallHooks takes InstallHooksParams and returns the number of hooks installed.
+	MethodInstallHooks = "install_hooks"
+
+	// MethodUninstallHooks has no params and returns nothing.
+	MethodUninstallHooks = "uninstall_hooks"
+
+	// MethodAreHooksInstalled has no params and returns a bool.
+	MethodAreHooksInstalled = "are_hooks_installed"
+
+	// MethodGetTranscriptPosition takes TranscriptParams and returns an int.
+	MethodGetTranscriptPosition = "get_transcript_position"
+
+	// MethodExtractModifiedFiles takes TranscriptParams and returns ModifiedFilesResult.
+	MethodExtractModifiedFiles = "extract_modified_files_from_offset"
+)
+
+// Request is written to the plugin's stdin. The plugin handles exactly one
+// request per invocation.
+type Request struct {
+	ProtocolVersion int             `json:"protocol_version"`
+	Method          string          `json:"method"`
+	Params          json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is read from the plugin's stdout. A non-empty Error fails the call.
+type Response struct {
+	ProtocolVersion int             `json:"protocol_version"`
+	Result          json.RawMessage `json:"result,omitempty"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// Info describes a plugin agent. Fields that never change for an agent are
+// reported once here instead of being requested on every call.
+type Info struct {
+	ProtocolVersion int    `json:"protocol_version"`
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	Description     string `json:"description"`
+	HookConfigPath  string `json:"hook_config_path,omitempty"`
+
+	// ProtectedDirs are repo-relative paths rewind must never touch.
+	ProtectedDirs []string `json:"protected_dirs,omitempty"`
+
+	Capabilities Capabilities `json:"capabilities"`
+
+	// Hooks lists the hook verbs the plugin's installed hooks invoke as
+	// `entire hooks <name> <verb>`, and the lifecycle event each one signals.
+	Hooks []HookSpec `json:"hooks,omitempty"`
+}
+
+// Capabilities lists the optional interfaces a plugin implements.
+type Capabilities struct {
+	Hooks              bool `json:"hooks"`
+	TranscriptAnalyzer bool `json:"transcript_analyzer"`
+}
+
+// HookSpec maps a hook verb to the lifecycle event it signals.
+type HookSpec struct {
+	Name string         `json:"name"`
+	Type agent.HookType `json:"type"`
+}
+
+// ParseHookInputParams carries the raw hook payload the agent sent to Entire.
+type ParseHookInputParams struct {
+	HookType agent.HookType `json:"hook_type"`
+	Payload  string         `json:"payload"`
+}
+
+// GetSessionDirParams are the params of MethodGetSessionDir.
+type GetSessionDirParams struct {
+	RepoPath string `json:"repo_path"`
+}
+
+// ResolveSessionFileParams are the params of MethodResolveSessionFile.
+type ResolveSessionFileParams struct {
+	SessionDir     string `json:"session_dir"`
+	AgentSessionID string `json:"agent_session_id"`
+}
+
+// FormatResumeCommandParams are the params of MethodFormatResumeCommand.
+type FormatResumeCommandParams struct {
+	SessionID string `json:"session_id"`
+}
+
+// InstallHooksParams are the params of MethodInstallHooks.
+type InstallHooksParams struct {
+	LocalDev bool `json:"local_dev"`
+	Force    bool `json:"force"`
+}
+
+// TranscriptParams are the params of the transcript analysis methods.
+type TranscriptParams struct {
+	Path        string `json:"path"`
+	StartOffset int    `json:"start_offset,omitempty"`
+}
+
+// ModifiedFilesResult is the result of MethodExtractModifiedFiles.
+type ModifiedFilesResult struct {
+	Files           []string `json:"files"`
+	CurrentPosition int      `json:"current_position"`
+}
+
+// HookInput is the wire form of agent.HookInput.
+type HookInput struct {
+	HookType     agent.HookType  `json:"hook_type"`
+	SessionID    string          `json:"session_id"`
+	SessionRef   string          `json:"session_ref,omitempty"`
+	Timestamp    time.Time       `json:"timestamp"`
+	UserPrompt   string          `json:"user_prompt,omitempty"`
+	ToolName     string          `json:"tool_name,omitempty"`
+	ToolUseID    string          `json:"tool_use_id,omitempty"`
+	ToolInput    json.RawMessage `json:"tool_input,omitempty"`
+	ToolResponse json.RawMessage `json:"tool_response,omitempty"`
+	RawData      map[string]any  `json:"raw_data,omitempty"`
+}
+
+// Session is the wire form of agent.AgentSession. NativeData is base64 encoded.
+type Session struct {
+	SessionID     string         `json:"session_id"`
+	AgentName     string         `json:"agent_name"`
+	RepoPath      string         `json:"repo_path,omitempty"`
+	SessionRef    string         `json:"session_ref,omitempty"`
+	StartTime     time.Time      `json:"start_time"`
+	NativeData    []byte         `json:"native_data,omitempty"`
+	ModifiedFiles []string       `json:"modified_files,omitempty"`
+	NewFiles      []string       `json:"new_files,omitempty"`
+	DeletedFiles  []string       `json:"deleted_files,omitempty"`
+	Entries       []SessionEntry `json:"entries,omitempty"`
+}
+
+// SessionEntry is the wire form of agent.SessionEntry.
+type SessionEntry struct {
+	UUID          string          `json:"uuid,omitempty"`
+	Type          agent.EntryType `json:"type"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Content       string          `json:"content,omitempty"`
+	ToolName      string          `json:"tool_name,omitempty"`
+	ToolInput     any             `json:"tool_input,omitempty"`
+	ToolOutput    any             `json:"tool_output,omitempty"`
+	FilesAffected []string        `json:"files_affected,omitempty"`
+}
+
+// newHookInput converts an agent.HookInput to its wire form.
+func newHookInput(input *agent.HookInput) HookInput {
+	return HookInput{
+		HookType:     input.HookType,
+		SessionID:    input.SessionID,
+		SessionRef:   input.SessionRef,
+		Timestamp:    input.Timestamp,
+		UserPrompt:   input.UserPrompt,
+		ToolName:     input.ToolName,
+		ToolUseID:    input.ToolUseID,
+		ToolInput:    rawJSON(input.ToolInput),
+		ToolResponse: rawJSON(input.ToolResponse),
+		RawData:      input.RawData,
+	}
+}
+
+// toAgent converts the wire form back to an agent.HookInput.
+func (h *HookInput) toAgent() *agent.HookInput {
+	return &agent.HookInput{
+		HookType:     h.HookType,
+		SessionID:    h.SessionID,
+		SessionRef:   h.SessionRef,
+		Timestamp:    h.Timestamp,
+		UserPrompt:   h.UserPrompt,
+		ToolName:     h.ToolName,
+		ToolUseID:    h.ToolUseID,
+		ToolInput:    h.ToolInput,
+		ToolResponse: h.ToolResponse,
+		RawData:      h.RawData,
+	}
+}
+
+// newSession converts an agent.AgentSession to its wire form.
+func newSession(session *agent.AgentSession) Session {
+	entries := make([]SessionEntry, 0, len(session.Entries))
+	for _, e := range session.Entries {
+		entries = append(entries, SessionEntry{
+			UUID:          e.UUID,
+			Type:          e.Type,
+			Timestamp:     e.Timestamp,
+			Content:       e.Content,
+			ToolName:      e.ToolName,
+			ToolInput:     e.ToolInput,
+			ToolOutput:    e.ToolOutput,
+			FilesAffected: e.FilesAffected,
+		})
+	}
+	return Session{
+		SessionID:     session.SessionID,
+		AgentName:     string(session.AgentName),
+		RepoPath:      session.RepoPath,
+		SessionRef:    session.SessionRef,
+		StartTime:     session.StartTime,
+		NativeData:    session.NativeData,
+		ModifiedFiles: session.ModifiedFiles,
+		NewFiles:      session.NewFiles,
+		DeletedFiles:  session.DeletedFiles,
+		Entries:       entries,
+	}
+}
+
+// toAgent converts the wire form back to an agent.AgentSession.
+func (s *Session) toAgent() *agent.AgentSession {
+	var entries []agent.SessionEntry
+	for _, e := range s.Entries {
+		entries = append(entries, agent.SessionEntry{
+			UUID:          e.UUID,
+			Type:          e.Type,
+			Timestamp:     e.Timestamp,
+			Content:       e.Content,
+			ToolName:      e.ToolName,
+			ToolInput:     e.ToolInput,
+			ToolOutput:    e.ToolOutput,
+			FilesAffected: e.FilesAffected,
+		})
+	}
+	return &agent.AgentSession{
+		SessionID:     s.SessionID,
+		AgentName:     agent.AgentName(s.AgentName),
+		RepoPath:      s.RepoPath,
+		SessionRef:    s.SessionRef,
+		StartTime:     s.StartTime,
+		NativeData:    s.NativeData,
+		ModifiedFiles: s.ModifiedFiles,
+		NewFiles:      s.NewFiles,
+		DeletedFiles:  s.DeletedFiles,
+		Entries:       entries,
+	}
+}
+
+// rawJSON returns data as a json.RawMessage if it is valid JSON, or nil.
+// Tool payloads are raw JSON by contract, but a malformed one must not break
+// encoding of the whole request.
+func rawJSON(data []byte) json.RawMessage {
+	if len(data) == 0 || !json.Valid(data) {
+		return nil
+	}
+	return json.RawMessage(data)
+}
@@ -0,0 +1,303 @@
+// Command entire-agent-reference is a reference agent plugin implementing every
+// method of the external agent protocol. Its "agent" keeps each session as a
+// JSONL transcript under .reference-agent/sessions in the repository, one
+// {"role", "content", "files"} object per line.
+//
+// Tests build it and put it on PATH; it also serves as an example for plugin authors.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/external"
+)
+
+const (
+	agentDir  = ".reference-agent"
+	hooksFile = ".reference-agent/hooks.json"
+)
+
+// transcriptLine is one line of a reference agent transcript.
+type transcriptLine struct {
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Files   []string `json:"files,omitempty"`
+}
+
+// hookPayload is what the reference agent sends to its hooks.
+type hookPayload struct {
+	SessionID      string `json:"session_id"`
+	TranscriptPath string `json:"transcript_path"`
+	Prompt         string `json:"prompt"`
+}
+
+func main() {
+	var req external.Request
+	resp := external.Response{ProtocolVersion: external.ProtocolVersion}
+
+	result, err := func() (any, error) {
+		if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+		if req.ProtocolVersion != external.ProtocolVersion {
+			return nil, fmt.Errorf("unsupported protocol version %d", req.ProtocolVersion)
+		}
+		return handle(req)
+	}()
+	if err != nil {
+		resp.Error = err.Error()
+	} else if result != nil {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			resp.Error = marshalErr.Error()
+		}
+		resp.Result = data
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		os.Exit(1)
+	}
+}
+
+func handle(req external.Request) (any, error) {
+	switch req.Method {
+	case external.MethodInfo:
+		return external.Info{
+			ProtocolVersion: external.ProtocolVersion,
+			Name:            "reference",
+			Type:            "Reference Agent",
+			Description:     "Reference agent plugin",
+			HookConfigPath:  hooksFile,
+			ProtectedDirs:   []string{agentDir},
+			Capabilities:    external.Capabilities{Hooks: true, TranscriptAnalyzer: true},
+			Hooks: []external.HookSpec{
+				{Name: "session-start", Type: agent.HookSessionStart},
+				{Name: "prompt", Type: agent.HookUserPromptSubmit},
+				{Name: "done", Type: agent.HookStop},
+				{Name: "session-end", Type: agent.HookSessionEnd},
+			},
+		}, nil
+
+	case external.MethodDetectPresence:
+		_, err := os.Stat(agentDir)
+		return err == nil, nil
+
+	case external.MethodParseHookInput:
+		var params external.ParseHookInputParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		var payload hookPayload
+		if err := json.Unmarshal([]byte(params.Payload), &payload); err != nil {
+			return nil, fmt.Errorf("invalid hook payload: %w", err)
+		}
+		return external.HookInput{
+			HookType:   params.HookType,
+			SessionID:  payload.SessionID,
+			SessionRef: payload.TranscriptPath,
+			UserPrompt: payload.Prompt,
+		}, nil
+
+	case external.MethodGetSessionID:
+		var input external.HookInput
+		if err := decode(req, &input); err != nil {
+			return nil, err
+		}
+		return input.SessionID, nil
+
+	case external.MethodGetSessionDir:
+		var params external.GetSessionDirParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		return filepath.Join(params.RepoPath, agentDir, "sessions"), nil
+
+	case external.MethodResolveSessionFile:
+		var params external.ResolveSessionFileParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		return filepath.Join(params.SessionDir, params.AgentSessionID+".jsonl"), nil
+
+	case external.MethodReadSession:
+		var input external.HookInput
+		if err := decode(req, &input); err != nil {
+			return nil, err
+		}
+		return readSession(input)
+
+	case external.MethodWriteSession:
+		var session external.Session
+		if err := decode(req, &session); err != nil {
+			return nil, err
+		}
+		if session.SessionRef == "" {
+			return nil, errors.New("session reference is required")
+		}
+		if err := os.MkdirAll(filepath.Dir(session.SessionRef), 0o750); err != nil {
+			return nil, err //nolint:wrapcheck // Reported to the CLI as the response error
+		}
+		return nil, os.WriteFile(session.SessionRef, session.NativeData, 0o600) //nolint:wrapcheck // Reported to the CLI as the response error
+
+	case external.MethodFormatResumeCommand:
+		var params external.FormatResumeCommandParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		return "reference-agent --resume " + params.SessionID, nil
+
+	case external.MethodInstallHooks:
+		var params external.InstallHooksParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(hooksFile); err == nil && !params.Force {
+			return 0, nil
+		}
+		command := "entire"
+		if params.LocalDev {
+			command = "go run ${REFERENCE_AGENT_PROJECT_DIR}/cmd/entire/main.go"
+		}
+		hooks := map[string]string{}
+		for _, verb := range []string{"session-start", "prompt", "done", "session-end"} {
+			hooks[verb] = command + " hooks reference " + verb
+		}
+		data, err := json.MarshalIndent(hooks, "", "  ")
+		if err != nil {
+			return nil, err //nolint:wrapcheck // Reported to the CLI as the response error
+		}
+		if err := os.MkdirAll(agentDir, 0o750); err != nil {
+			return nil, err //nolint:wrapcheck // Reported to the CLI as the response error
+		}
+		if err := os.WriteFile(hooksFile, data, 0o600); err != nil {
+			return nil, err //nolint:wrapcheck // Reported to the CLI as the response error
+		}
+		return len(hooks), nil
+
+	case external.MethodUninstallHooks:
+		if err := os.Remove(hooksFile); err != nil && !os.IsNotExist(err) {
+			return nil, err //nolint:wrapcheck // Reported to the CLI as the response error
+		}
+		return nil, nil
+
+	case external.MethodAreHooksInstalled:
+		_, err := os.Stat(hooksFile)
+		return err == nil, nil
+
+	case external.MethodGetTranscriptPosition:
+		var params external.TranscriptParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		lines, err := readTranscript(params.Path)
+		if err != nil {
+			return nil, err
+		}
+		return len(lines), nil
+
+	case external.MethodExtractModifiedFiles:
+		var params external.TranscriptParams
+		if err := decode(req, &params); err != nil {
+			return nil, err
+		}
+		lines, err := readTranscript(params.Path)
+		if err != nil {
+			return nil, err
+		}
+		result := external.ModifiedFilesResult{CurrentPosition: len(lines)}
+		if params.StartOffset < len(lines) {
+			result.Files = modifiedFiles(lines[params.StartOffset:])
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("unknown method %q", req.Method)
+}
+
+func decode(req external.Request, v any) error {
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return fmt.Errorf("invalid params for %s: %w", req.Method, err)
+	}
+	return nil
+}
+
+func readSession(input external.HookInput) (external.Session, error) {
+	if input.SessionRef == "" {
+		return external.Session{}, errors.New("session reference is required")
+	}
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return external.Session{}, err //nolint:wrapcheck // Reported to the CLI as the response error
+	}
+	lines, err := readTranscript(input.SessionRef)
+	if err != nil {
+		return external.Session{}, err
+	}
+
+	session := external.Session{
+		SessionID:     input.SessionID,
+		AgentName:     "reference",
+		SessionRef:    input.SessionRef,
+		NativeData:    data,
+		ModifiedFiles: modifiedFiles(lines),
+	}
+	for _, line := range lines {
+		entryType := agent.EntryAssistant
+		if line.Role == "user" {
+			entryType = agent.EntryUser
+		}
+		session.Entries = append(session.Entries, external.SessionEntry{
+			Type:          entryType,
+			Content:       line.Content,
+			FilesAffected: line.Files,
+		})
+	}
+	return session, nil
+}
+
+func readTranscript(path string) ([]transcriptLine, error) {
+	file, err := os.Open(path) //nolint:gosec // Path comes from the CLI request
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err //nolint:wrapcheck // Reported to the CLI as the response error
+	}
+	defer file.Close()
+
+	var lines []transcriptLine
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var line transcriptLine
+		if err := json.Unmarshal([]byte(text), &line); err != nil {
+			return nil, fmt.Errorf("invalid transcript line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err() //nolint:wrapcheck // Reported to the CLI as the response error
+}
+
+func modifiedFiles(lines []transcriptLine) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, line := range lines {
+		for _, f := range line.Files {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
+	}
+	return files
+}
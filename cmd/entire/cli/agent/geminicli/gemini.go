@@ -189,6 +189,9 @@ func (g *GeminiCLIAgent) GetSessionDir(repoPath string) (string, error) {
 	if override := os.Getenv("ENTIRE_TEST_GEMINI_PROJECT_DIR"); override != "" {
 		return override, nil
 	}
+	if override := agent.SessionDirOverride(); override != "" {
+		return override, nil
+	}
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -0,0 +1,105 @@
+package geminicli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+
+	"github.com/google/uuid"
+)
+
+// Ensure GeminiCLIAgent implements SessionImporter
+var _ agent.SessionImporter = (*GeminiCLIAgent)(nil)
+
+// importTranscript is the session file layout Gemini CLI writes and reads on resume.
+type importTranscript struct {
+	SessionID   string          `json:"sessionId"`
+	ProjectHash string          `json:"projectHash"`
+	StartTime   string          `json:"startTime"`
+	LastUpdated string          `json:"lastUpdated"`
+	Messages    []importMessage `json:"messages"`
+}
+
+type importMessage struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"`
+	Content   string `json:"content"`
+}
+
+// ImportSession builds a Gemini CLI session from another agent's normalized entries.
+// The file is named like Gemini's own session files so `gemini --resume` finds it.
+func (g *GeminiCLIAgent) ImportSession(repoPath, sessionID string, entries []agent.SessionEntry) (*agent.AgentSession, error) {
+	data, err := BuildTranscript(sessionID, repoPath, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionDir, err := g.GetSessionDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	shortID := sessionID
+	if len(shortID) > 8 {
+		shortID = shortID[:8]
+	}
+	fileName := "session-" + now.Format("2006-01-02T15-04") + "-" + shortID + ".json"
+
+	return &agent.AgentSession{
+		SessionID:  sessionID,
+		AgentName:  g.Name(),
+		RepoPath:   repoPath,
+		SessionRef: filepath.Join(sessionDir, fileName),
+		StartTime:  now,
+		NativeData: data,
+	}, nil
+}
+
+// BuildTranscript renders normalized entries as a Gemini CLI session file.
+// The conversation is reduced to user and gemini messages (see agent.HandoffMessages).
+func BuildTranscript(sessionID, projectRoot string, entries []agent.SessionEntry) ([]byte, error) {
+	messages := agent.HandoffMessages(entries)
+	if len(messages) == 0 {
+		return nil, errors.New("session has no messages to import")
+	}
+
+	now := time.Now().UTC()
+	hash := sha256.Sum256([]byte(projectRoot))
+	transcript := importTranscript{
+		SessionID:   sessionID,
+		ProjectHash: hex.EncodeToString(hash[:]),
+		LastUpdated: now.Format(time.RFC3339Nano),
+	}
+
+	for _, msg := range messages {
+		timestamp := msg.Timestamp
+		if timestamp.IsZero() {
+			timestamp = now
+		}
+		msgType := MessageTypeUser
+		if msg.Type == agent.EntryAssistant {
+			msgType = MessageTypeGemini
+		}
+		transcript.Messages = append(transcript.Messages, importMessage{
+			ID:        uuid.NewString(),
+			Timestamp: timestamp.UTC().Format(time.RFC3339Nano),
+			Type:      msgType,
+			Content:   msg.Content,
+		})
+	}
+	transcript.StartTime = transcript.Messages[0].Timestamp
+
+	data, err := json.MarshalIndent(transcript, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+	return data, nil
+}
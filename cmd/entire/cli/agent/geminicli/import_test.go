@@ -0,0 +1,64 @@
+package geminicli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestBuildTranscript(t *testing.T) {
+	t.Parallel()
+
+	entries := []agent.SessionEntry{
+		{Type: agent.EntryUser, Content: "Add a README"},
+		{Type: agent.EntryTool, ToolName: "Write", FilesAffected: []string{"README.md"}},
+		{Type: agent.EntryAssistant, Content: "Added."},
+		{Type: agent.EntryUser, Content: "Thanks"},
+	}
+
+	data, err := BuildTranscript("0123456789abcdef", "/repo", entries)
+	if err != nil {
+		t.Fatalf("BuildTranscript() error = %v", err)
+	}
+
+	transcript, err := ParseTranscript(data)
+	if err != nil {
+		t.Fatalf("ParseTranscript() error = %v", err)
+	}
+	if len(transcript.Messages) != 3 {
+		t.Fatalf("got %d messages, want 3", len(transcript.Messages))
+	}
+	if transcript.Messages[1].Type != MessageTypeGemini || transcript.Messages[1].Content != "[Tool: Write README.md]\n\nAdded." {
+		t.Errorf("Messages[1] = %+v", transcript.Messages[1])
+	}
+	if got := ExtractLastUserPromptFromTranscript(transcript); got != "Thanks" {
+		t.Errorf("last prompt = %q, want Thanks", got)
+	}
+	if !strings.Contains(string(data), `"sessionId": "0123456789abcdef"`) {
+		t.Error("transcript should record the session ID")
+	}
+}
+
+func TestImportSession(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ENTIRE_TEST_GEMINI_PROJECT_DIR", dir)
+
+	ag := &GeminiCLIAgent{}
+	sess, err := ag.ImportSession("/repo", "0123456789abcdef", []agent.SessionEntry{{Type: agent.EntryUser, Content: "Hi"}})
+	if err != nil {
+		t.Fatalf("ImportSession() error = %v", err)
+	}
+	if err := ag.WriteSession(sess); err != nil {
+		t.Fatalf("WriteSession() error = %v", err)
+	}
+
+	// Gemini's own session file naming, so ResolveSessionFile finds it
+	if got := ag.ResolveSessionFile(dir, "0123456789abcdef"); got != sess.SessionRef {
+		t.Errorf("ResolveSessionFile() = %q, want %q", got, sess.SessionRef)
+	}
+	if filepath.Dir(sess.SessionRef) != dir {
+		t.Errorf("SessionRef = %q, want it in %q", sess.SessionRef, dir)
+	}
+}
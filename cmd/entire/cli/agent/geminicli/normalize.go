@@ -0,0 +1,92 @@
+package geminicli
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Ensure GeminiCLIAgent implements TranscriptNormalizer
+var _ agent.TranscriptNormalizer = (*GeminiCLIAgent)(nil)
+
+// normalizeTranscript is a Gemini session with the fields normalization needs
+// beyond GeminiTranscript.
+type normalizeTranscript struct {
+	Messages []struct {
+		ID        string    `json:"id,omitempty"`
+		Type      string    `json:"type"`
+		Timestamp time.Time `json:"timestamp"`
+		Content   string    `json:"content,omitempty"`
+		ToolCalls []struct {
+			GeminiToolCall
+
+			Result interface{} `json:"result,omitempty"`
+		} `json:"toolCalls,omitempty"`
+	} `json:"messages"`
+}
+
+// NormalizeTranscript converts a Gemini session file into session entries.
+// A gemini message produces an assistant entry for its text followed by one
+// tool entry per tool call.
+func (g *GeminiCLIAgent) NormalizeTranscript(content []byte) ([]agent.SessionEntry, error) {
+	var transcript normalizeTranscript
+	if err := json.Unmarshal(content, &transcript); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript: %w", err)
+	}
+
+	var entries []agent.SessionEntry
+	for _, msg := range transcript.Messages {
+		switch msg.Type {
+		case MessageTypeUser:
+			if msg.Content != "" {
+				entries = append(entries, agent.SessionEntry{
+					UUID:      msg.ID,
+					Type:      agent.EntryUser,
+					Timestamp: msg.Timestamp,
+					Content:   msg.Content,
+				})
+			}
+
+		case MessageTypeGemini:
+			if msg.Content != "" {
+				entries = append(entries, agent.SessionEntry{
+					UUID:      msg.ID,
+					Type:      agent.EntryAssistant,
+					Timestamp: msg.Timestamp,
+					Content:   msg.Content,
+				})
+			}
+			for _, toolCall := range msg.ToolCalls {
+				entry := agent.SessionEntry{
+					UUID:       msg.ID,
+					Type:       agent.EntryTool,
+					Timestamp:  msg.Timestamp,
+					ToolName:   toolCall.Name,
+					ToolInput:  toolCall.Args,
+					ToolOutput: toolCall.Result,
+				}
+				if slices.Contains(FileModificationTools, toolCall.Name) {
+					if file := toolCallFile(toolCall.Args); file != "" {
+						entry.FilesAffected = []string{file}
+					}
+				}
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// toolCallFile returns the file a tool call targets, checking the argument
+// names Gemini's file tools use.
+func toolCallFile(args map[string]interface{}) string {
+	for _, key := range []string{"file_path", "path", "filename"} {
+		if file, ok := args[key].(string); ok && file != "" {
+			return file
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package geminicli
+
+import (
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestNormalizeTranscript(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+  "messages": [
+    {"id": "m1", "type": "user", "timestamp": "2026-01-01T00:00:00Z", "content": "Write a README"},
+    {"id": "m2", "type": "gemini", "content": "Writing it.", "toolCalls": [
+      {"id": "c1", "name": "write_file", "args": {"file_path": "README.md"}, "result": [{"output": "ok"}]},
+      {"id": "c2", "name": "read_file", "args": {"file_path": "go.mod"}}
+    ]}
+  ]
+}`)
+
+	entries, err := (&GeminiCLIAgent{}).NormalizeTranscript(data)
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4: %+v", len(entries), entries)
+	}
+
+	if entries[0].Type != agent.EntryUser || entries[0].Content != "Write a README" || entries[0].Timestamp.IsZero() {
+		t.Errorf("entries[0] = %+v, want user prompt with timestamp", entries[0])
+	}
+	if entries[1].Type != agent.EntryAssistant || entries[1].Content != "Writing it." {
+		t.Errorf("entries[1] = %+v, want assistant text", entries[1])
+	}
+	write := entries[2]
+	if write.ToolName != "write_file" || len(write.FilesAffected) != 1 || write.FilesAffected[0] != "README.md" || write.ToolOutput == nil {
+		t.Errorf("entries[2] = %+v, want write_file of README.md with output", write)
+	}
+	if entries[3].ToolName != "read_file" || len(entries[3].FilesAffected) != 0 {
+		t.Errorf("entries[3] = %+v, want read_file without files", entries[3])
+	}
+
+	if _, err := (&GeminiCLIAgent{}).NormalizeTranscript([]byte("not json")); err == nil {
+		t.Error("NormalizeTranscript() should fail on invalid JSON")
+	}
+}
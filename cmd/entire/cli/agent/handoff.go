@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SessionImporter is implemented by agents that can continue a session that
+// another agent started. The session arrives as normalized entries (see
+// TranscriptNormalizer) and is written in the importing agent's native format.
+type SessionImporter interface {
+	Agent
+
+	// ImportSession builds a native session with the given ID from normalized
+	// entries. The returned session has SessionRef and NativeData set and is
+	// ready for WriteSession.
+	ImportSession(repoPath, sessionID string, entries []SessionEntry) (*AgentSession, error)
+}
+
+// HandoffMessages reduces normalized entries to the alternating user/assistant
+// messages another agent can load as conversation history.
+// Tool calls are carried over as assistant text, since the importing agent has
+// different tools and can't replay them. System entries are dropped, and
+// consecutive messages from the same side are joined.
+func HandoffMessages(entries []SessionEntry) []SessionEntry {
+	var messages []SessionEntry
+	for _, entry := range entries {
+		var msg SessionEntry
+		switch entry.Type {
+		case EntryUser:
+			msg = SessionEntry{Type: EntryUser, Timestamp: entry.Timestamp, Content: entry.Content}
+		case EntryAssistant:
+			msg = SessionEntry{Type: EntryAssistant, Timestamp: entry.Timestamp, Content: entry.Content}
+		case EntryTool:
+			msg = SessionEntry{Type: EntryAssistant, Timestamp: entry.Timestamp, Content: describeToolCall(entry)}
+		case EntrySystem:
+			continue
+		}
+		if strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+
+		if n := len(messages); n > 0 && messages[n-1].Type == msg.Type {
+			messages[n-1].Content += "\n\n" + msg.Content
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// describeToolCall renders a tool entry as text, e.g. "[Tool: Edit main.go]".
+func describeToolCall(entry SessionEntry) string {
+	var b strings.Builder
+	b.WriteString("[Tool: ")
+	b.WriteString(entry.ToolName)
+	if len(entry.FilesAffected) > 0 {
+		b.WriteString(" " + strings.Join(entry.FilesAffected, ", "))
+	} else if entry.ToolInput != nil {
+		if input, err := json.Marshal(entry.ToolInput); err == nil {
+			b.WriteString(" " + truncateForHandoff(string(input)))
+		}
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// maxHandoffToolInput bounds how much of a tool's input is carried over as text.
+const maxHandoffToolInput = 200
+
+func truncateForHandoff(s string) string {
+	if len(s) <= maxHandoffToolInput {
+		return s
+	}
+	end := maxHandoffToolInput
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + "..."
+}
+
+// ImportEntries returns the normalized entries of a stored session, using the
+// stored normalized transcript if there is one and converting the native
+// transcript with the source agent otherwise.
+func ImportEntries(normalized, transcript []byte, sourceType AgentType) ([]SessionEntry, error) {
+	if len(normalized) == 0 {
+		var err error
+		normalized, err = NormalizeTranscript(transcript, sourceType)
+		if err != nil {
+			return nil, err
+		}
+	}
+	entries, err := ParseNormalizedTranscript(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read normalized transcript: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandoffMessages(t *testing.T) {
+	t.Parallel()
+
+	entries := []SessionEntry{
+		{Type: EntrySystem, Content: "Compacted"},
+		{Type: EntryUser, Content: "Fix main.go"},
+		{Type: EntryAssistant, Content: "Looking."},
+		{Type: EntryTool, ToolName: "Edit", FilesAffected: []string{"main.go"}},
+		{Type: EntryTool, ToolName: "Bash", ToolInput: map[string]interface{}{"command": strings.Repeat("x", 300)}},
+		{Type: EntryUser, Content: "  "},
+		{Type: EntryAssistant, Content: "Done."},
+		{Type: EntryUser, Content: "Thanks"},
+	}
+
+	messages := HandoffMessages(entries)
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want 3: %+v", len(messages), messages)
+	}
+	if messages[0].Type != EntryUser || messages[0].Content != "Fix main.go" {
+		t.Errorf("messages[0] = %+v", messages[0])
+	}
+
+	assistant := messages[1].Content
+	if messages[1].Type != EntryAssistant || !strings.HasPrefix(assistant, "Looking.\n\n[Tool: Edit main.go]\n\n[Tool: Bash {") {
+		t.Errorf("messages[1] = %q", assistant)
+	}
+	if !strings.HasSuffix(assistant, "...]\n\nDone.") {
+		t.Errorf("long tool input should be truncated: %q", assistant)
+	}
+	if messages[2].Content != "Thanks" {
+		t.Errorf("messages[2] = %+v", messages[2])
+	}
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNormalizeUnsupported is returned when no registered agent can normalize a transcript.
+var ErrNormalizeUnsupported = errors.New("transcript normalization not supported for agent")
+
+// normalizedScannerBufferSize bounds a single normalized line (10MB), matching
+// the agents' own transcript scanners.
+const normalizedScannerBufferSize = 10 * 1024 * 1024
+
+// TranscriptNormalizer is implemented by agents that can convert their native
+// transcript into agent-neutral session entries. Checkpoints store the result
+// as normalized.jsonl next to the native transcript, so tools can read any
+// agent's session in one format.
+type TranscriptNormalizer interface {
+	Agent
+
+	// NormalizeTranscript converts native transcript content into session entries,
+	// in transcript order. Tool results are attached to the tool entry that produced them.
+	NormalizeTranscript(content []byte) ([]SessionEntry, error)
+}
+
+// NormalizeTranscript converts a native transcript into normalized JSONL using
+// the agent of the given type. An empty agentType means the default agent, since
+// checkpoints without an agent type predate multi-agent support.
+// Returns ErrNormalizeUnsupported if the agent is unknown or has no converter.
+func NormalizeTranscript(content []byte, agentType AgentType) ([]byte, error) {
+	var ag Agent
+	if agentType == "" {
+		ag = Default()
+	} else if found, err := GetByAgentType(agentType); err == nil {
+		ag = found
+	}
+
+	normalizer, ok := ag.(TranscriptNormalizer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNormalizeUnsupported, agentType)
+	}
+
+	entries, err := normalizer.NormalizeTranscript(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize transcript: %w", err)
+	}
+	return MarshalNormalizedTranscript(entries)
+}
+
+// MarshalNormalizedTranscript encodes session entries as normalized JSONL, one entry per line.
+func MarshalNormalizedTranscript(entries []SessionEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal normalized entry: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseNormalizedTranscript decodes normalized JSONL into session entries.
+// Empty lines are skipped; malformed lines are an error.
+func ParseNormalizedTranscript(data []byte) ([]SessionEntry, error) {
+	var entries []SessionEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, normalizedScannerBufferSize), normalizedScannerBufferSize)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry SessionEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("invalid normalized entry on line %d: %w", lineNum, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan normalized transcript: %w", err)
+	}
+	return entries, nil
+}
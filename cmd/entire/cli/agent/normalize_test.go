@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNormalizedTranscript_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []SessionEntry{
+		{UUID: "u1", Type: EntryUser, Timestamp: ts, Content: "Add a test"},
+		{Type: EntryTool, ToolName: "Write", ToolInput: map[string]interface{}{"file_path": "a_test.go"}, FilesAffected: []string{"a_test.go"}},
+		{Type: EntryAssistant, Content: "Done."},
+	}
+
+	data, err := MarshalNormalizedTranscript(entries)
+	if err != nil {
+		t.Fatalf("MarshalNormalizedTranscript() error = %v", err)
+	}
+
+	got, err := ParseNormalizedTranscript(data)
+	if err != nil {
+		t.Fatalf("ParseNormalizedTranscript() error = %v", err)
+	}
+	if len(got) != len(entries) {
+		t.Fatalf("got %d entries, want %d", len(got), len(entries))
+	}
+	if !got[0].Timestamp.Equal(ts) || got[0].Content != "Add a test" {
+		t.Errorf("got[0] = %+v", got[0])
+	}
+	if got[1].ToolName != "Write" || len(got[1].FilesAffected) != 1 {
+		t.Errorf("got[1] = %+v", got[1])
+	}
+	if got[2].Type != EntryAssistant || !got[2].Timestamp.IsZero() {
+		t.Errorf("got[2] = %+v", got[2])
+	}
+}
+
+func TestParseNormalizedTranscript_InvalidLine(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseNormalizedTranscript([]byte("{\"type\":\"user\"}\nnot json\n")); err == nil {
+		t.Error("ParseNormalizedTranscript() should fail on a malformed line")
+	}
+}
+
+func TestNormalizeTranscript_UnknownAgent(t *testing.T) {
+	t.Parallel()
+
+	_, err := NormalizeTranscript([]byte("{}\n"), "No Such Agent")
+	if !errors.Is(err, ErrNormalizeUnsupported) {
+		t.Errorf("NormalizeTranscript() error = %v, want ErrNormalizeUnsupported", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 var (
 	registryMu sync.RWMutex
 	registry   = make(map[AgentName]Factory)
+	hidden     = make(map[AgentName]bool)
 )
 
 // Factory creates a new agent instance
@@ -22,6 +23,17 @@ func Register(name AgentName, factory Factory) {
 	registry[name] = factory
 }
 
+// RegisterHidden adds an agent factory to the registry without listing it:
+// Get finds the agent by name, but List, and so detection and the agent
+// lists shown to users, skip it. For agents that are only used on request,
+// like the simulator.
+func RegisterHidden(name AgentName, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+	hidden[name] = true
+}
+
 // Get retrieves an agent by name.
 //
 
@@ -36,34 +48,48 @@ func Get(name AgentName) (Agent, error) {
 	return factory(), nil
 }
 
-// List returns all registered agent names in sorted order.
+// List returns all registered agent names in sorted order, except agents
+// registered with RegisterHidden.
 func List() []AgentName {
 	registryMu.RLock()
 	defer registryMu.RUnlock()
 
 	names := make([]AgentName, 0, len(registry))
 	for name := range registry {
-		names = append(names, name)
+		if !hidden[name] {
+			names = append(names, name)
+		}
 	}
 	slices.Sort(names)
 	return names
 }
 
 // Detect attempts to auto-detect which agent is being used.
-// Checks each registered agent's DetectPresence method.
-//
-
+// Returns the first agent, in name order, whose DetectPresence reports it
+// configured in the repository.
 func Detect() (Agent, error) {
-	registryMu.RLock()
-	defer registryMu.RUnlock()
+	detected := DetectAll()
+	if len(detected) == 0 {
+		return nil, fmt.Errorf("no agent detected (available: %v)", List())
+	}
+	return detected[0], nil
+}
 
-	for _, factory := range registry {
-		ag := factory()
+// DetectAll returns every registered agent whose DetectPresence reports it
+// configured in the repository, sorted by name. Agents whose detection fails
+// are skipped.
+func DetectAll() []Agent {
+	var detected []Agent
+	for _, name := range List() {
+		ag, err := Get(name)
+		if err != nil {
+			continue
+		}
 		if present, err := ag.DetectPresence(); err == nil && present {
-			return ag, nil
+			detected = append(detected, ag)
 		}
 	}
-	return nil, fmt.Errorf("no agent detected (available: %v)", List())
+	return detected
 }
 
 // AgentName is the registry key type for agents (e.g., "claude-code", "gemini").
@@ -78,14 +104,22 @@ type AgentType string
 
 // Agent name constants (registry keys)
 const (
+	AgentNameAider      AgentName = "aider"
 	AgentNameClaudeCode AgentName = "claude-code"
+	AgentNameCodex      AgentName = "codex"
+	AgentNameCursor     AgentName = "cursor"
 	AgentNameGemini     AgentName = "gemini"
+	AgentNameSimulator  AgentName = "simulator"
 )
 
 // Agent type constants (type identifiers stored in metadata/trailers)
 const (
+	AgentTypeAider      AgentType = "Aider"
 	AgentTypeClaudeCode AgentType = "Claude Code"
+	AgentTypeCodex      AgentType = "Codex"
+	AgentTypeCursor     AgentType = "Cursor"
 	AgentTypeGemini     AgentType = "Gemini CLI"
+	AgentTypeSimulator  AgentType = "Simulator"
 	AgentTypeUnknown    AgentType = "Agent" // Fallback for backwards compatibility
 )
 
@@ -64,6 +64,27 @@ func TestRegistryOperations(t *testing.T) {
 			t.Errorf("expected sorted list [agent-a, agent-b], got %v", names)
 		}
 	})
+
+	t.Run("List skips hidden agents", func(t *testing.T) {
+		registryMu.Lock()
+		registry = make(map[AgentName]Factory)
+		registryMu.Unlock()
+		defer func() {
+			registryMu.Lock()
+			delete(hidden, AgentName("agent-hidden"))
+			registryMu.Unlock()
+		}()
+
+		Register(AgentName("agent-a"), func() Agent { return &mockAgent{} })
+		RegisterHidden(AgentName("agent-hidden"), func() Agent { return &mockAgent{} })
+
+		if names := List(); len(names) != 1 || names[0] != AgentName("agent-a") {
+			t.Errorf("List() = %v, want [agent-a]", names)
+		}
+		if _, err := Get(AgentName("agent-hidden")); err != nil {
+			t.Errorf("Get() of a hidden agent error = %v", err)
+		}
+	})
 }
 
 func TestDetect(t *testing.T) {
@@ -116,6 +137,47 @@ func TestDetect(t *testing.T) {
 			t.Errorf("expected Name() %q, got %q", "detectable", agent.Name())
 		}
 	})
+
+	t.Run("DetectAll returns every detected agent in name order", func(t *testing.T) {
+		registryMu.Lock()
+		registry = make(map[AgentName]Factory)
+		registryMu.Unlock()
+
+		for _, name := range []AgentName{"zeta", "alpha", "mid"} {
+			Register(name, func() Agent { return &namedDetectableAgent{name: name} })
+		}
+		Register(AgentName("undetected"), func() Agent { return &mockAgent{} })
+
+		detected := DetectAll()
+		if len(detected) != 3 {
+			t.Fatalf("DetectAll() returned %d agents, want 3", len(detected))
+		}
+		for i, want := range []AgentName{"alpha", "mid", "zeta"} {
+			if detected[i].Name() != want {
+				t.Errorf("DetectAll()[%d] = %q, want %q", i, detected[i].Name(), want)
+			}
+		}
+
+		first, err := Detect()
+		if err != nil || first.Name() != "alpha" {
+			t.Errorf("Detect() = %v, %v, want alpha", first, err)
+		}
+	})
+}
+
+// namedDetectableAgent is a detectable mock with a configurable name
+type namedDetectableAgent struct {
+	mockAgent
+
+	name AgentName
+}
+
+func (d *namedDetectableAgent) Name() AgentName {
+	return d.name
+}
+
+func (d *namedDetectableAgent) DetectPresence() (bool, error) {
+	return true, nil
 }
 
 // detectableAgent is a mock that returns true for DetectPresence
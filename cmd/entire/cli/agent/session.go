@@ -36,18 +36,19 @@ type AgentSession struct {
 	Entries []SessionEntry
 }
 
-// SessionEntry represents a single entry in the session
+// SessionEntry represents a single entry in the session.
+// It is also the line schema of normalized transcripts (see NormalizeTranscript).
 type SessionEntry struct {
-	UUID      string
-	Type      EntryType
-	Timestamp time.Time
-	Content   string
+	UUID      string    `json:"uuid,omitempty"`
+	Type      EntryType `json:"type"`
+	Timestamp time.Time `json:"timestamp,omitzero"`
+	Content   string    `json:"content,omitempty"`
 
 	// Tool-specific fields
-	ToolName      string
-	ToolInput     interface{}
-	ToolOutput    interface{}
-	FilesAffected []string
+	ToolName      string      `json:"tool_name,omitempty"`
+	ToolInput     interface{} `json:"tool_input,omitempty"`
+	ToolOutput    interface{} `json:"tool_output,omitempty"`
+	FilesAffected []string    `json:"files_affected,omitempty"`
 }
 
 // EntryType categorizes session entries
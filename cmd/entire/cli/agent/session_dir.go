@@ -0,0 +1,32 @@
+package agent
+
+import "sync"
+
+var (
+	sessionDirMu       sync.RWMutex
+	sessionDirOverride string
+)
+
+// OverrideSessionDir makes agents look up session transcripts in dir instead
+// of their own storage, until the returned function restores the previous
+// directory. `entire debug simulate` uses it so that lookups by session ID
+// find the simulator's transcripts, as they would find a real agent's.
+func OverrideSessionDir(dir string) (restore func()) {
+	sessionDirMu.Lock()
+	defer sessionDirMu.Unlock()
+	previous := sessionDirOverride
+	sessionDirOverride = dir
+	return func() {
+		sessionDirMu.Lock()
+		defer sessionDirMu.Unlock()
+		sessionDirOverride = previous
+	}
+}
+
+// SessionDirOverride returns the directory set by OverrideSessionDir, or ""
+// if agents should use their own storage.
+func SessionDirOverride() string {
+	sessionDirMu.RLock()
+	defer sessionDirMu.RUnlock()
+	return sessionDirOverride
+}
@@ -0,0 +1,247 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+
+	"github.com/google/uuid"
+)
+
+// claudeLine is a Claude Code transcript line.
+type claudeLine struct {
+	ParentUUID  *string        `json:"parentUuid"`
+	IsSidechain bool           `json:"isSidechain"`
+	UserType    string         `json:"userType"`
+	Cwd         string         `json:"cwd"`
+	SessionID   string         `json:"sessionId"`
+	AgentID     string         `json:"agentId,omitempty"`
+	Type        string         `json:"type"`
+	Message     map[string]any `json:"message,omitempty"`
+	Data        map[string]any `json:"data,omitempty"`
+	UUID        string         `json:"uuid"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+// claudeTranscript appends lines to a Claude Code JSONL transcript, chaining
+// each line to the previous one through parentUuid.
+type claudeTranscript struct {
+	path      string
+	cwd       string
+	sessionID string
+	agentID   string // set for subagent transcripts
+	parent    *string
+}
+
+func (t *claudeTranscript) append(lineType string, message, data map[string]any) error {
+	lineUUID := uuid.NewString()
+	line, err := json.Marshal(claudeLine{
+		ParentUUID:  t.parent,
+		IsSidechain: t.agentID != "",
+		UserType:    "external",
+		Cwd:         t.cwd,
+		SessionID:   t.sessionID,
+		AgentID:     t.agentID,
+		Type:        lineType,
+		Message:     message,
+		Data:        data,
+		UUID:        lineUUID,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript line: %w", err)
+	}
+
+	f, err := os.OpenFile(t.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	t.parent = &lineUUID
+	return nil
+}
+
+func (t *claudeTranscript) user(content any) error {
+	return t.append("user", map[string]any{"role": "user", "content": content}, nil)
+}
+
+func (t *claudeTranscript) assistant(blocks ...map[string]any) error {
+	return t.append("assistant", map[string]any{"role": "assistant", "content": blocks}, nil)
+}
+
+// toolCall writes a tool_use block and its tool_result.
+func (t *claudeTranscript) toolCall(id, name string, input map[string]any, output string) error {
+	if err := t.assistant(map[string]any{"type": "tool_use", "id": id, "name": name, "input": input}); err != nil {
+		return err
+	}
+	return t.user([]map[string]any{{"type": "tool_result", "tool_use_id": id, "content": output}})
+}
+
+// claudeSession plays a scenario as Claude Code.
+type claudeSession struct {
+	*player
+	transcript *claudeTranscript
+	subagents  map[string]*claudeTranscript // agent ID -> subagent transcript
+	toolCount  int
+}
+
+func (c *claudeSession) start() error {
+	// Start from an empty transcript when a scenario with a fixed session ID is replayed.
+	if err := os.Remove(c.transcriptPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to reset transcript: %w", err)
+	}
+	c.transcript = &claudeTranscript{path: c.transcriptPath, cwd: c.repoRoot, sessionID: c.sessionID}
+	c.subagents = make(map[string]*claudeTranscript)
+	return c.hook(claudecode.HookNameSessionStart, "SessionStart", map[string]any{"source": "startup"})
+}
+
+func (c *claudeSession) turn(step Step) error {
+	// The prompt reaches the transcript after UserPromptSubmit, which can still reject it.
+	if err := c.hook(claudecode.HookNameUserPromptSubmit, "UserPromptSubmit", map[string]any{"prompt": step.Prompt}); err != nil {
+		return err
+	}
+	if err := c.transcript.user(step.Prompt); err != nil {
+		return err
+	}
+
+	for _, task := range step.Tasks {
+		if err := c.task(task); err != nil {
+			return err
+		}
+	}
+	for _, tool := range step.Tools {
+		if err := c.transcript.toolCall(c.nextToolID(), tool.Name, tool.Input, tool.Output); err != nil {
+			return err
+		}
+	}
+	if err := c.writeFiles(c.transcript, step.Files); err != nil {
+		return err
+	}
+	if err := c.transcript.assistant(map[string]any{"type": "text", "text": step.Response}); err != nil {
+		return err
+	}
+
+	// Claude Code logs the stop hook launch before running it; the stop
+	// handler waits for this line to know the transcript has been flushed.
+	if err := c.transcript.append("progress", nil, map[string]any{
+		"type":      "hook_progress",
+		"hookEvent": "Stop",
+		"command":   "entire hooks claude-code stop",
+	}); err != nil {
+		return err
+	}
+	return c.hook(claudecode.HookNameStop, "Stop", map[string]any{"stop_hook_active": false})
+}
+
+// task runs a subagent: a Task tool call wrapped in pre-task and post-task
+// hooks, with the subagent's own transcript next to the main one.
+func (c *claudeSession) task(task Task) error {
+	toolID := c.nextToolID()
+	input := map[string]any{
+		"description":   task.Description,
+		"prompt":        task.Prompt,
+		"subagent_type": task.Type,
+	}
+	if err := c.transcript.assistant(map[string]any{"type": "tool_use", "id": toolID, "name": "Task", "input": input}); err != nil {
+		return err
+	}
+	if err := c.hook(claudecode.HookNamePreTask, "PreToolUse", map[string]any{
+		"tool_name":   "Task",
+		"tool_use_id": toolID,
+		"tool_input":  input,
+	}); err != nil {
+		return err
+	}
+
+	sub, ok := c.subagents[task.AgentID]
+	if !ok {
+		sub = &claudeTranscript{
+			path:      filepath.Join(filepath.Dir(c.transcriptPath), "agent-"+task.AgentID+".jsonl"),
+			cwd:       c.repoRoot,
+			sessionID: c.sessionID,
+			agentID:   task.AgentID,
+		}
+		if err := os.Remove(sub.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to reset subagent transcript: %w", err)
+		}
+		c.subagents[task.AgentID] = sub
+	}
+	if err := sub.user(task.Prompt); err != nil {
+		return err
+	}
+	if err := c.writeFiles(sub, task.Files); err != nil {
+		return err
+	}
+	if err := sub.assistant(map[string]any{"type": "text", "text": task.Response}); err != nil {
+		return err
+	}
+
+	// Claude Code appends the subagent's ID to the Task result, which is how
+	// token usage finds subagent transcripts.
+	result := []map[string]any{
+		{"type": "text", "text": task.Response},
+		{"type": "text", "text": "agentId: " + task.AgentID},
+	}
+	if err := c.transcript.user([]map[string]any{{"type": "tool_result", "tool_use_id": toolID, "content": result}}); err != nil {
+		return err
+	}
+	return c.hook(claudecode.HookNamePostTask, "PostToolUse", map[string]any{
+		"tool_name":     "Task",
+		"tool_use_id":   toolID,
+		"tool_input":    input,
+		"tool_response": map[string]any{"agentId": task.AgentID, "content": task.Response},
+	})
+}
+
+// writeFiles writes files to the working tree as Write tool calls in the transcript.
+func (c *claudeSession) writeFiles(t *claudeTranscript, files []FileWrite) error {
+	for _, f := range files {
+		if err := c.writeFile(f); err != nil {
+			return err
+		}
+		path := filepath.Join(c.repoRoot, f.Path)
+		input := map[string]any{"file_path": path, "content": f.Content}
+		if err := t.toolCall(c.nextToolID(), claudecode.ToolWrite, input, "File created successfully at: "+path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *claudeSession) end() error {
+	return c.hook(claudecode.HookNameSessionEnd, "SessionEnd", map[string]any{"reason": "exit"})
+}
+
+func (c *claudeSession) nextToolID() string {
+	c.toolCount++
+	return fmt.Sprintf("toolu_sim_%03d", c.toolCount)
+}
+
+// hook runs a Claude Code hook with the common payload fields plus extra.
+func (c *claudeSession) hook(hookName, event string, extra map[string]any) error {
+	payload := map[string]any{
+		"session_id":      c.sessionID,
+		"transcript_path": c.transcriptPath,
+		"cwd":             c.repoRoot,
+		"hook_event_name": event,
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload: %w", hookName, err)
+	}
+	if err := c.driver.RunHook(agent.AgentNameClaudeCode, hookName, data); err != nil {
+		return fmt.Errorf("%s hook failed: %w", hookName, err)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package simulator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
+
+	"github.com/google/uuid"
+)
+
+// geminiTranscript is a Gemini CLI session file.
+type geminiTranscript struct {
+	SessionID   string          `json:"sessionId"`
+	ProjectHash string          `json:"projectHash"`
+	StartTime   string          `json:"startTime"`
+	LastUpdated string          `json:"lastUpdated"`
+	Messages    []geminiMessage `json:"messages"`
+}
+
+type geminiMessage struct {
+	ID        string           `json:"id"`
+	Timestamp string           `json:"timestamp"`
+	Type      string           `json:"type"`
+	Content   string           `json:"content"`
+	ToolCalls []geminiToolCall `json:"toolCalls,omitempty"`
+}
+
+type geminiToolCall struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Args      map[string]any `json:"args"`
+	Result    string         `json:"result,omitempty"`
+	Status    string         `json:"status"`
+	Timestamp string         `json:"timestamp"`
+}
+
+// geminiSession plays a scenario as Gemini CLI. Gemini rewrites its whole
+// session file as the conversation grows, so the transcript is rewritten
+// before each hook.
+type geminiSession struct {
+	*player
+	transcript geminiTranscript
+	toolCount  int
+}
+
+func (g *geminiSession) start() error {
+	now := timestamp()
+	hash := sha256.Sum256([]byte(g.repoRoot))
+	g.transcript = geminiTranscript{
+		SessionID:   g.sessionID,
+		ProjectHash: hex.EncodeToString(hash[:]),
+		StartTime:   now,
+		LastUpdated: now,
+		Messages:    []geminiMessage{},
+	}
+	if err := g.save(); err != nil {
+		return err
+	}
+	return g.hook(geminicli.HookNameSessionStart, "SessionStart", map[string]any{"source": "startup"})
+}
+
+func (g *geminiSession) turn(step Step) error {
+	if err := g.hook(geminicli.HookNameBeforeAgent, "BeforeAgent", map[string]any{"prompt": step.Prompt}); err != nil {
+		return err
+	}
+	g.transcript.Messages = append(g.transcript.Messages, geminiMessage{
+		ID:        uuid.NewString(),
+		Timestamp: timestamp(),
+		Type:      geminicli.MessageTypeUser,
+		Content:   step.Prompt,
+	})
+
+	reply := geminiMessage{ID: uuid.NewString(), Timestamp: timestamp(), Type: geminicli.MessageTypeGemini}
+	for _, tool := range step.Tools {
+		if err := g.toolCall(&reply, tool.Name, tool.Input, tool.Output, nil); err != nil {
+			return err
+		}
+	}
+	for _, f := range step.Files {
+		args := map[string]any{"file_path": filepath.Join(g.repoRoot, f.Path), "content": f.Content}
+		output := "Successfully created and wrote to new file: " + filepath.Join(g.repoRoot, f.Path)
+		if err := g.toolCall(&reply, geminicli.ToolWriteFile, args, output, &f); err != nil {
+			return err
+		}
+	}
+
+	reply.Content = step.Response
+	g.transcript.Messages = append(g.transcript.Messages, reply)
+	if err := g.save(); err != nil {
+		return err
+	}
+	return g.hook(geminicli.HookNameAfterAgent, "AfterAgent", nil)
+}
+
+// toolCall runs a tool between before-tool and after-tool hooks and records it
+// on the reply. File writes happen between the two hooks, as they would in Gemini.
+func (g *geminiSession) toolCall(reply *geminiMessage, name string, args map[string]any, output string, write *FileWrite) error {
+	if err := g.hook(geminicli.HookNameBeforeTool, "BeforeTool", map[string]any{"tool_name": name, "tool_input": args}); err != nil {
+		return err
+	}
+	if write != nil {
+		if err := g.writeFile(*write); err != nil {
+			return err
+		}
+	}
+
+	g.toolCount++
+	reply.ToolCalls = append(reply.ToolCalls, geminiToolCall{
+		ID:        fmt.Sprintf("%s-%d", name, g.toolCount),
+		Name:      name,
+		Args:      args,
+		Result:    output,
+		Status:    "success",
+		Timestamp: timestamp(),
+	})
+	return g.hook(geminicli.HookNameAfterTool, "AfterTool", map[string]any{
+		"tool_name":     name,
+		"tool_input":    args,
+		"tool_response": map[string]any{"llmContent": output},
+	})
+}
+
+func (g *geminiSession) end() error {
+	return g.hook(geminicli.HookNameSessionEnd, "SessionEnd", map[string]any{"reason": "exit"})
+}
+
+func (g *geminiSession) save() error {
+	g.transcript.LastUpdated = timestamp()
+	data, err := json.MarshalIndent(g.transcript, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcript: %w", err)
+	}
+	if err := os.WriteFile(g.transcriptPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// hook runs a Gemini CLI hook with the common payload fields plus extra.
+func (g *geminiSession) hook(hookName, event string, extra map[string]any) error {
+	payload := map[string]any{
+		"session_id":      g.sessionID,
+		"transcript_path": g.transcriptPath,
+		"cwd":             g.repoRoot,
+		"hook_event_name": event,
+		"timestamp":       timestamp(),
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload: %w", hookName, err)
+	}
+	if err := g.driver.RunHook(agent.AgentNameGemini, hookName, data); err != nil {
+		return fmt.Errorf("%s hook failed: %w", hookName, err)
+	}
+	return nil
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
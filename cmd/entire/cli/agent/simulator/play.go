@@ -0,0 +1,117 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// Driver carries out what a scenario does outside the agent: running hooks
+// and making the user's commits. `entire debug simulate` runs hooks in process;
+// tests can run them through the entire binary instead.
+type Driver interface {
+	// RunHook runs an agent hook (e.g. claude-code/stop) with a raw JSON payload.
+	RunHook(agentName agent.AgentName, hookName string, payload []byte) error
+
+	// Commit commits the given repository-relative files as the user would.
+	Commit(message string, files []string) error
+}
+
+// Result summarizes a played scenario.
+type Result struct {
+	SessionID      string
+	TranscriptPath string
+	Turns          int
+	Commits        int
+}
+
+// session plays one format's hooks and transcript.
+type session interface {
+	start() error
+	turn(step Step) error
+	end() error
+}
+
+// Run plays a scenario in the repository at repoRoot. Files are written to the
+// working tree and transcripts to the simulator's session directory.
+func Run(s *Scenario, repoRoot string, driver Driver) (*Result, error) {
+	if driver == nil {
+		return nil, errors.New("driver is required")
+	}
+	sessionDir, err := (&SimulatorAgent{}).GetSessionDir(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(sessionDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	p := &player{
+		repoRoot:  repoRoot,
+		sessionID: s.SessionID,
+		driver:    driver,
+	}
+	var sess session
+	switch s.Format {
+	case agent.AgentNameGemini:
+		p.transcriptPath = filepath.Join(sessionDir, s.SessionID+".json")
+		sess = &geminiSession{player: p}
+	default:
+		p.transcriptPath = filepath.Join(sessionDir, s.SessionID+".jsonl")
+		sess = &claudeSession{player: p}
+	}
+
+	result := &Result{SessionID: s.SessionID, TranscriptPath: p.transcriptPath}
+	if err := sess.start(); err != nil {
+		return result, err
+	}
+	for i, step := range s.Steps {
+		if step.Commit != "" {
+			if err := driver.Commit(step.Commit, p.pending); err != nil {
+				return result, fmt.Errorf("step %d: commit failed: %w", i+1, err)
+			}
+			p.pending = nil
+			result.Commits++
+			continue
+		}
+		if err := sess.turn(step); err != nil {
+			return result, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		result.Turns++
+	}
+	if err := sess.end(); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// player holds the state shared by every format.
+type player struct {
+	repoRoot       string
+	sessionID      string
+	transcriptPath string
+	driver         Driver
+
+	// pending lists files written since the last commit step.
+	pending []string
+}
+
+// writeFile writes a scenario file into the working tree.
+func (p *player) writeFile(f FileWrite) error {
+	path := filepath.Join(p.repoRoot, f.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", f.Path, err)
+	}
+	//nolint:gosec // G306: files in the working tree are ordinary source files
+	if err := os.WriteFile(path, []byte(f.Content), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.Path, err)
+	}
+	if !slices.Contains(p.pending, f.Path) {
+		p.pending = append(p.pending, f.Path)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package simulator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
+)
+
+// recordingDriver records hooks and commits instead of running them.
+type recordingDriver struct {
+	hooks    []string
+	payloads []map[string]any
+	commits  [][]string
+}
+
+func (d *recordingDriver) RunHook(agentName agent.AgentName, hookName string, payload []byte) error {
+	d.hooks = append(d.hooks, string(agentName)+"/"+hookName)
+	var p map[string]any
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+	d.payloads = append(d.payloads, p)
+	return nil
+}
+
+func (d *recordingDriver) Commit(_ string, files []string) error {
+	d.commits = append(d.commits, files)
+	return nil
+}
+
+func TestRun_ClaudeCode(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseScenario([]byte(`
+format: claude-code
+session_id: sim-session
+steps:
+  - prompt: Add a greeting
+    tasks:
+      - agent_id: helper1
+        description: Write docs
+        files:
+          - path: docs/hello.md
+            content: "# Hello\n"
+    tools:
+      - name: Read
+        input: {file_path: README.md}
+        output: "# readme"
+    files:
+      - path: hello.go
+        content: "package main\n"
+    response: Added hello.go.
+  - commit: Add greeting
+`))
+	if err != nil {
+		t.Fatalf("ParseScenario() error = %v", err)
+	}
+
+	repo := t.TempDir()
+	driver := &recordingDriver{}
+	result, err := Run(s, repo, driver)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	wantHooks := []string{
+		"claude-code/session-start",
+		"claude-code/user-prompt-submit",
+		"claude-code/pre-task",
+		"claude-code/post-task",
+		"claude-code/stop",
+		"claude-code/session-end",
+	}
+	if !slices.Equal(driver.hooks, wantHooks) {
+		t.Errorf("hooks = %v, want %v", driver.hooks, wantHooks)
+	}
+	for _, p := range driver.payloads {
+		if p["session_id"] != "sim-session" || p["transcript_path"] != result.TranscriptPath {
+			t.Errorf("payload missing session fields: %v", p)
+		}
+	}
+	if resp, ok := driver.payloads[3]["tool_response"].(map[string]any); !ok || resp["agentId"] != "helper1" {
+		t.Errorf("post-task tool_response = %v, want agentId helper1", driver.payloads[3]["tool_response"])
+	}
+
+	if result.Turns != 1 || result.Commits != 1 {
+		t.Errorf("Turns, Commits = %d, %d, want 1, 1", result.Turns, result.Commits)
+	}
+	if len(driver.commits) != 1 || !slices.Equal(driver.commits[0], []string{"docs/hello.md", "hello.go"}) {
+		t.Errorf("commits = %v, want the two written files", driver.commits)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "hello.go")); err != nil {
+		t.Errorf("hello.go not written: %v", err)
+	}
+
+	data, err := os.ReadFile(result.TranscriptPath)
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	lines, err := claudecode.ParseTranscript(data)
+	if err != nil {
+		t.Fatalf("ParseTranscript() error = %v", err)
+	}
+	if got := claudecode.ExtractLastUserPrompt(lines); got != "Add a greeting" {
+		t.Errorf("ExtractLastUserPrompt() = %q, want %q", got, "Add a greeting")
+	}
+	if got := claudecode.ExtractModifiedFiles(lines); !slices.Equal(got, []string{filepath.Join(repo, "hello.go")}) {
+		t.Errorf("ExtractModifiedFiles() = %v", got)
+	}
+	if got := claudecode.ExtractSpawnedAgentIDs(lines); got["helper1"] == "" {
+		t.Errorf("ExtractSpawnedAgentIDs() = %v, want helper1", got)
+	}
+
+	subData, err := os.ReadFile(filepath.Join(filepath.Dir(result.TranscriptPath), "agent-helper1.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to read subagent transcript: %v", err)
+	}
+	subLines, err := claudecode.ParseTranscript(subData)
+	if err != nil {
+		t.Fatalf("ParseTranscript(subagent) error = %v", err)
+	}
+	if got := claudecode.ExtractModifiedFiles(subLines); !slices.Equal(got, []string{filepath.Join(repo, "docs/hello.md")}) {
+		t.Errorf("subagent ExtractModifiedFiles() = %v", got)
+	}
+}
+
+func TestRun_Gemini(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseScenario([]byte(`
+format: gemini
+steps:
+  - prompt: Add a greeting
+    files:
+      - path: hello.go
+        content: "package main\n"
+  - prompt: Add a farewell
+    files:
+      - path: bye.go
+        content: "package main\n"
+  - commit: Add greeting and farewell
+`))
+	if err != nil {
+		t.Fatalf("ParseScenario() error = %v", err)
+	}
+
+	repo := t.TempDir()
+	driver := &recordingDriver{}
+	result, err := Run(s, repo, driver)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	wantHooks := []string{
+		"gemini/session-start",
+		"gemini/before-agent", "gemini/before-tool", "gemini/after-tool", "gemini/after-agent",
+		"gemini/before-agent", "gemini/before-tool", "gemini/after-tool", "gemini/after-agent",
+		"gemini/session-end",
+	}
+	if !slices.Equal(driver.hooks, wantHooks) {
+		t.Errorf("hooks = %v, want %v", driver.hooks, wantHooks)
+	}
+	if len(driver.commits) != 1 || !slices.Equal(driver.commits[0], []string{"hello.go", "bye.go"}) {
+		t.Errorf("commits = %v", driver.commits)
+	}
+
+	data, err := os.ReadFile(result.TranscriptPath)
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	prompts, err := geminicli.ExtractAllUserPrompts(data)
+	if err != nil {
+		t.Fatalf("ExtractAllUserPrompts() error = %v", err)
+	}
+	if !slices.Equal(prompts, []string{"Add a greeting", "Add a farewell"}) {
+		t.Errorf("prompts = %v", prompts)
+	}
+	files, err := geminicli.ExtractModifiedFiles(data)
+	if err != nil {
+		t.Fatalf("ExtractModifiedFiles() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("ExtractModifiedFiles() = %v, want 2 files", files)
+	}
+}
@@ -0,0 +1,199 @@
+package simulator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a scripted agent session, loaded from YAML:
+//
+//	format: claude-code        # or gemini
+//	steps:
+//	  - prompt: Add a greeting
+//	    files:
+//	      - path: hello.go
+//	        content: |
+//	          package main
+//	    response: Added hello.go.
+//	  - commit: Add greeting
+type Scenario struct {
+	// Format is the agent whose hooks and transcript format are simulated
+	// (claude-code or gemini). Defaults to claude-code.
+	Format agent.AgentName `yaml:"format"`
+
+	// SessionID is the agent session ID. A random one is used if empty.
+	SessionID string `yaml:"session_id"`
+
+	Steps []Step `yaml:"steps"`
+}
+
+// Step is either a prompt turn or a user commit.
+type Step struct {
+	// Prompt starts an agent turn with this user prompt.
+	Prompt string `yaml:"prompt"`
+
+	// Tasks are subagent tasks run during the turn (claude-code only).
+	Tasks []Task `yaml:"tasks"`
+
+	// Tools are tool calls made during the turn that don't write files.
+	Tools []ToolCall `yaml:"tools"`
+
+	// Files are written by the agent during the turn.
+	Files []FileWrite `yaml:"files"`
+
+	// Response is the agent's final reply. Defaults to "Done."
+	Response string `yaml:"response"`
+
+	// Commit makes a user commit of the files written so far, with this message.
+	Commit string `yaml:"commit"`
+}
+
+// Task is a subagent task.
+type Task struct {
+	AgentID     string      `yaml:"agent_id"`
+	Type        string      `yaml:"type"`
+	Description string      `yaml:"description"`
+	Prompt      string      `yaml:"prompt"`
+	Files       []FileWrite `yaml:"files"`
+	Response    string      `yaml:"response"`
+}
+
+// ToolCall is a tool call and its output.
+type ToolCall struct {
+	Name   string         `yaml:"name"`
+	Input  map[string]any `yaml:"input"`
+	Output string         `yaml:"output"`
+}
+
+// FileWrite writes content to a repository-relative path.
+type FileWrite struct {
+	Path    string `yaml:"path"`
+	Content string `yaml:"content"`
+}
+
+const defaultResponse = "Done."
+
+// LoadScenario reads and validates a scenario file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// ParseScenario parses and validates a YAML scenario, filling in defaults.
+func ParseScenario(data []byte) (*Scenario, error) {
+	var s Scenario
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Scenario) validate() error {
+	switch s.Format {
+	case "":
+		s.Format = agent.AgentNameClaudeCode
+	case agent.AgentNameClaudeCode, agent.AgentNameGemini:
+	default:
+		return fmt.Errorf("unsupported format %q (use %s or %s)", s.Format, agent.AgentNameClaudeCode, agent.AgentNameGemini)
+	}
+	if s.SessionID == "" {
+		s.SessionID = uuid.NewString()
+	}
+	if strings.ContainsAny(s.SessionID, `/\`) {
+		return fmt.Errorf("invalid session_id %q: contains path separators", s.SessionID)
+	}
+	if len(s.Steps) == 0 {
+		return errors.New("scenario has no steps")
+	}
+
+	for i := range s.Steps {
+		step := &s.Steps[i]
+		if err := step.validate(s.Format); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (st *Step) validate(format agent.AgentName) error {
+	if st.Commit != "" {
+		if st.Prompt != "" || len(st.Tasks) > 0 || len(st.Tools) > 0 || len(st.Files) > 0 || st.Response != "" {
+			return errors.New("a commit step can't also be a prompt turn")
+		}
+		return nil
+	}
+	if st.Prompt == "" {
+		return errors.New("step needs a prompt or a commit")
+	}
+	if st.Response == "" {
+		st.Response = defaultResponse
+	}
+	if len(st.Tasks) > 0 && format != agent.AgentNameClaudeCode {
+		return fmt.Errorf("subagent tasks are only supported in %s format", agent.AgentNameClaudeCode)
+	}
+
+	for _, tool := range st.Tools {
+		if tool.Name == "" {
+			return errors.New("tool call needs a name")
+		}
+	}
+	if err := validateFiles(st.Files); err != nil {
+		return err
+	}
+	for i := range st.Tasks {
+		task := &st.Tasks[i]
+		if task.AgentID == "" {
+			return errors.New("task needs an agent_id")
+		}
+		// Claude Code agent IDs are alphanumeric, and transcripts are matched on that.
+		if strings.IndexFunc(task.AgentID, func(r rune) bool { return !isAlphanumeric(r) }) >= 0 {
+			return fmt.Errorf("invalid agent_id %q: must be alphanumeric", task.AgentID)
+		}
+		if task.Type == "" {
+			task.Type = "general-purpose"
+		}
+		if task.Prompt == "" {
+			task.Prompt = task.Description
+		}
+		if task.Response == "" {
+			task.Response = defaultResponse
+		}
+		if err := validateFiles(task.Files); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateFiles(files []FileWrite) error {
+	for _, f := range files {
+		if f.Path == "" {
+			return errors.New("file write needs a path")
+		}
+		if filepath.IsAbs(f.Path) || !filepath.IsLocal(f.Path) {
+			return fmt.Errorf("file path %q must be relative and inside the repository", f.Path)
+		}
+	}
+	return nil
+}
+
+func isAlphanumeric(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
@@ -0,0 +1,118 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+func TestParseScenario_Defaults(t *testing.T) {
+	t.Parallel()
+
+	s, err := ParseScenario([]byte(`
+steps:
+  - prompt: Add a greeting
+    tasks:
+      - agent_id: helper1
+        description: Write docs
+  - commit: Add greeting
+`))
+	if err != nil {
+		t.Fatalf("ParseScenario() error = %v", err)
+	}
+
+	if s.Format != agent.AgentNameClaudeCode {
+		t.Errorf("Format = %q, want %q", s.Format, agent.AgentNameClaudeCode)
+	}
+	if s.SessionID == "" {
+		t.Error("SessionID should default to a random ID")
+	}
+	if got := s.Steps[0].Response; got != defaultResponse {
+		t.Errorf("Response = %q, want %q", got, defaultResponse)
+	}
+	task := s.Steps[0].Tasks[0]
+	if task.Type != "general-purpose" {
+		t.Errorf("task Type = %q, want general-purpose", task.Type)
+	}
+	if task.Prompt != "Write docs" {
+		t.Errorf("task Prompt = %q, want the description", task.Prompt)
+	}
+	if s.Steps[1].Response != "" {
+		t.Errorf("commit step Response = %q, want empty", s.Steps[1].Response)
+	}
+}
+
+func TestParseScenario_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name:    "unknown field",
+			yaml:    "steps:\n  - prompt: hi\n    promt: typo\n",
+			wantErr: "promt",
+		},
+		{
+			name:    "unsupported format",
+			yaml:    "format: cursor\nsteps:\n  - prompt: hi\n",
+			wantErr: "unsupported format",
+		},
+		{
+			name:    "no steps",
+			yaml:    "format: gemini\n",
+			wantErr: "no steps",
+		},
+		{
+			name:    "empty step",
+			yaml:    "steps:\n  - response: hi\n",
+			wantErr: "step 1: step needs a prompt or a commit",
+		},
+		{
+			name:    "commit with prompt",
+			yaml:    "steps:\n  - prompt: hi\n    commit: msg\n",
+			wantErr: "can't also be a prompt turn",
+		},
+		{
+			name:    "tasks in gemini format",
+			yaml:    "format: gemini\nsteps:\n  - prompt: hi\n    tasks:\n      - agent_id: a1\n",
+			wantErr: "only supported in claude-code format",
+		},
+		{
+			name:    "file outside repository",
+			yaml:    "steps:\n  - prompt: hi\n    files:\n      - path: ../escape.txt\n",
+			wantErr: "inside the repository",
+		},
+		{
+			name:    "absolute file path",
+			yaml:    "steps:\n  - prompt: hi\n    files:\n      - path: /etc/passwd\n",
+			wantErr: "inside the repository",
+		},
+		{
+			name:    "non-alphanumeric agent ID",
+			yaml:    "steps:\n  - prompt: hi\n    tasks:\n      - agent_id: my-helper\n",
+			wantErr: "must be alphanumeric",
+		},
+		{
+			name:    "session ID with separator",
+			yaml:    "session_id: ../x\nsteps:\n  - prompt: hi\n",
+			wantErr: "invalid session_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseScenario([]byte(tt.yaml))
+			if err == nil {
+				t.Fatal("ParseScenario() should fail")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseScenario() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Package simulator implements a scripted agent for testing and demos.
+// It plays a YAML scenario (prompts, tool calls, file writes, subagent tasks
+// and commits) and emits the hook payloads and transcript a real Claude Code
+// or Gemini CLI session would, so settings and strategies can be exercised
+// without a live agent.
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+//nolint:gochecknoinits // Agent self-registration is the intended pattern
+func init() {
+	agent.RegisterHidden(agent.AgentNameSimulator, NewSimulatorAgent)
+}
+
+// SimulatorAgent implements the Agent interface for scripted sessions.
+// It has no hooks of its own: scenarios drive the hooks of the agent they
+// simulate, and sessions are recorded as that agent's.
+//
+//nolint:revive // SimulatorAgent is clearer than Agent in this context
+type SimulatorAgent struct{}
+
+// NewSimulatorAgent creates a new simulator agent instance.
+func NewSimulatorAgent() agent.Agent {
+	return &SimulatorAgent{}
+}
+
+// Name returns the agent registry key.
+func (s *SimulatorAgent) Name() agent.AgentName {
+	return agent.AgentNameSimulator
+}
+
+// Type returns the agent type identifier.
+func (s *SimulatorAgent) Type() agent.AgentType {
+	return agent.AgentTypeSimulator
+}
+
+// Description returns a human-readable description.
+func (s *SimulatorAgent) Description() string {
+	return "Simulator - scripted sessions for testing (entire debug simulate)"
+}
+
+// DetectPresence always returns false: the simulator is only used on request.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (s *SimulatorAgent) DetectPresence() (bool, error) {
+	return false, nil
+}
+
+// GetHookConfigPath returns an empty string as the simulator has no hook config.
+func (s *SimulatorAgent) GetHookConfigPath() string {
+	return ""
+}
+
+// SupportsHooks returns false; scenarios drive the simulated agent's hooks.
+func (s *SimulatorAgent) SupportsHooks() bool {
+	return false
+}
+
+// ParseHookInput always fails as the simulator's payloads go to the simulated agent's hooks.
+func (s *SimulatorAgent) ParseHookInput(_ agent.HookType, _ io.Reader) (*agent.HookInput, error) {
+	return nil, errors.New("the simulator has no hooks; its payloads are handled by the simulated agent")
+}
+
+// GetSessionID extracts the session ID from hook input.
+func (s *SimulatorAgent) GetSessionID(input *agent.HookInput) string {
+	return input.SessionID
+}
+
+// ProtectedDirs returns nil; simulated transcripts live under .entire/tmp.
+func (s *SimulatorAgent) ProtectedDirs() []string {
+	return nil
+}
+
+// GetSessionDir returns where simulated transcripts are written: .entire/tmp/simulator.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (s *SimulatorAgent) GetSessionDir(repoPath string) (string, error) {
+	if repoPath == "" {
+		if root, err := paths.RepoRoot(); err == nil {
+			repoPath = root
+		}
+	}
+	return filepath.Join(repoPath, paths.EntireTmpDir, "simulator"), nil
+}
+
+// ResolveSessionFile returns the transcript path for a session.
+// Claude-format transcripts are JSONL; Gemini-format ones are JSON.
+func (s *SimulatorAgent) ResolveSessionFile(sessionDir, agentSessionID string) string {
+	jsonl := filepath.Join(sessionDir, agentSessionID+".jsonl")
+	if json := filepath.Join(sessionDir, agentSessionID+".json"); !fileExists(jsonl) && fileExists(json) {
+		return json
+	}
+	return jsonl
+}
+
+// ReadSession reads a simulated transcript.
+func (s *SimulatorAgent) ReadSession(input *agent.HookInput) (*agent.AgentSession, error) {
+	if input.SessionRef == "" {
+		return nil, errors.New("session reference (transcript path) is required")
+	}
+	data, err := os.ReadFile(input.SessionRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return &agent.AgentSession{
+		SessionID:  input.SessionID,
+		AgentName:  s.Name(),
+		RepoPath:   filepath.Dir(input.SessionRef),
+		SessionRef: input.SessionRef,
+		StartTime:  time.Now(),
+		NativeData: data,
+	}, nil
+}
+
+// WriteSession writes a simulated transcript.
+func (s *SimulatorAgent) WriteSession(session *agent.AgentSession) error {
+	if session == nil {
+		return errors.New("session is nil")
+	}
+	if session.SessionRef == "" {
+		return errors.New("session reference (transcript path) is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(session.SessionRef), 0o750); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if err := os.WriteFile(session.SessionRef, session.NativeData, 0o600); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// FormatResumeCommand returns the command that replays a scenario; simulated
+// sessions can't be resumed interactively.
+func (s *SimulatorAgent) FormatResumeCommand(_ string) string {
+	return "entire debug simulate <scenario.yaml>"
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/ignore"
 
 	"github.com/go-git/go-git/v5/plumbing"
 )
@@ -58,19 +59,34 @@ const (
 )
 
 // Store provides low-level primitives for reading and writing checkpoints.
-// This is used by strategies to implement their storage approach.
+// This is used by strategies to implement their storage approach; strategies
+// should depend on this interface rather than on a particular backend.
+//
+// Temporary checkpoints always live on shadow branches in the repository.
+// Committed checkpoints live wherever the backend keeps them: on the
+// entire/checkpoints/v1 branch (GitStore) or in a plain directory (FilesystemStore).
+type Store interface {
+	TemporaryStore
+	CommittedStore
+}
+
+// TemporaryStore reads and writes temporary checkpoints on shadow branches.
 //
 // The interface matches the GitStore implementation signatures directly:
 // - WriteTemporary takes WriteTemporaryOptions and returns a result with commit hash and skip status
 // - ReadTemporary takes baseCommit (not sessionID) since shadow branches are keyed by commit
 // - List methods return implementation-specific info types for richer data
-type Store interface {
+type TemporaryStore interface {
 	// WriteTemporary writes a temporary checkpoint (full state) to a shadow branch.
 	// Shadow branches are named entire/<base-commit-short-hash>.
 	// Returns a result containing the commit hash and whether the checkpoint was skipped.
 	// Checkpoints are skipped (deduplicated) when the tree hash matches the previous checkpoint.
 	WriteTemporary(ctx context.Context, opts WriteTemporaryOptions) (WriteTemporaryResult, error)
 
+	// WriteTemporaryTask writes a task checkpoint (code changes plus task metadata)
+	// to a shadow branch and returns the new commit hash.
+	WriteTemporaryTask(ctx context.Context, opts WriteTemporaryTaskOptions) (plumbing.Hash, error)
+
 	// ReadTemporary reads the latest checkpoint from a shadow branch.
 	// baseCommit is the commit hash the session is based on.
 	// worktreeID is the internal git worktree identifier (empty for main worktree).
@@ -80,7 +96,31 @@ type Store interface {
 	// ListTemporary lists all shadow branches with their checkpoint info.
 	ListTemporary(ctx context.Context) ([]TemporaryInfo, error)
 
-	// WriteCommitted writes a committed checkpoint to the entire/checkpoints/v1 branch.
+	// ListTemporaryCheckpoints lists the checkpoint commits on the shadow branch
+	// for a base commit, optionally filtered by session ID.
+	ListTemporaryCheckpoints(ctx context.Context, baseCommit, worktreeID, sessionID string, limit int) ([]TemporaryCheckpointInfo, error)
+
+	// ListCheckpointsForBranch lists the checkpoint commits on a shadow branch by name.
+	ListCheckpointsForBranch(ctx context.Context, branchName, sessionID string, limit int) ([]TemporaryCheckpointInfo, error)
+
+	// ListAllTemporaryCheckpoints lists checkpoint commits across all shadow branches.
+	ListAllTemporaryCheckpoints(ctx context.Context, sessionID string, limit int) ([]TemporaryCheckpointInfo, error)
+
+	// GetTranscriptFromCommit reads the transcript stored under metadataDir in a
+	// shadow branch commit.
+	GetTranscriptFromCommit(commitHash plumbing.Hash, metadataDir string, agentType agent.AgentType) ([]byte, error)
+
+	// ShadowBranchExists reports whether the shadow branch for a base commit exists.
+	ShadowBranchExists(baseCommit, worktreeID string) bool
+
+	// DeleteShadowBranch deletes the shadow branch for a base commit.
+	DeleteShadowBranch(baseCommit, worktreeID string) error
+}
+
+// CommittedStore reads and writes committed checkpoints.
+// Checkpoints are stored at sharded paths: <id[:2]>/<id[2:]>/, whatever the backend.
+type CommittedStore interface {
+	// WriteCommitted writes a committed checkpoint.
 	// Checkpoints are stored at sharded paths: <id[:2]>/<id[2:]>/
 	WriteCommitted(ctx context.Context, opts WriteCommittedOptions) error
 
@@ -99,6 +139,23 @@ type Store interface {
 	// Useful when you have the session ID but don't know its index within the checkpoint.
 	ReadSessionContentByID(ctx context.Context, checkpointID id.CheckpointID, sessionID string) (*SessionContent, error)
 
+	// ReadLatestSessionContent reads the content of the checkpoint's most recent session.
+	// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
+	ReadLatestSessionContent(ctx context.Context, checkpointID id.CheckpointID) (*SessionContent, error)
+
+	// ReadCommittedFile reads a file by its path within the committed checkpoints
+	// tree (e.g. "a1/b2c3d4e5f6/tasks/<tool-use-id>/checkpoint.json").
+	ReadCommittedFile(ctx context.Context, path string) ([]byte, error)
+
+	// GetSessionLog reads the latest session's transcript and session ID.
+	// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
+	// Returns ErrNoTranscript if the checkpoint exists but has no transcript.
+	GetSessionLog(cpID id.CheckpointID) ([]byte, string, error)
+
+	// GetCheckpointAuthor returns who created a checkpoint. Backends without
+	// authorship records return an empty Author.
+	GetCheckpointAuthor(ctx context.Context, checkpointID id.CheckpointID) (Author, error)
+
 	// ListCommitted lists all committed checkpoints.
 	ListCommitted(ctx context.Context) ([]CommittedInfo, error)
 
@@ -107,6 +164,32 @@ type Store interface {
 	// session transcript (prompt to stop event).
 	// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
 	UpdateCommitted(ctx context.Context, opts UpdateCommittedOptions) error
+
+	// UpdateSummary sets the AI summary on the checkpoint's latest session.
+	// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
+	UpdateSummary(ctx context.Context, checkpointID id.CheckpointID, summary *Summary) error
+
+	// DeleteCommitted removes committed checkpoints. IDs that don't exist are ignored.
+	DeleteCommitted(ctx context.Context, checkpointIDs []id.CheckpointID) error
+
+	// CommittedSizes returns the stored size of each committed checkpoint.
+	CommittedSizes(ctx context.Context) (map[id.CheckpointID]CheckpointSize, error)
+
+	// PruneCommitted deletes checkpoints or drops their transcripts. Unlike
+	// DeleteCommitted, the branch-backed store rewrites its history so that
+	// pruned content is no longer reachable. IDs that don't exist are ignored.
+	PruneCommitted(ctx context.Context, opts PruneOptions) error
+
+	// ListPruned returns the checkpoints PruneCommitted deleted, sorted, so
+	// that trailers referencing them aren't mistaken for dangling ones.
+	ListPruned(ctx context.Context) ([]id.CheckpointID, error)
+
+	// VerifyCommitted checks the integrity of all committed checkpoints.
+	VerifyCommitted(ctx context.Context) (*VerifyResult, error)
+
+	// Location describes where committed checkpoints are kept, for messages
+	// and metadata references: the metadata branch name or a directory.
+	Location() string
 }
 
 // WriteTemporaryResult contains the result of writing a temporary checkpoint.
@@ -158,6 +241,10 @@ type WriteTemporaryOptions struct {
 	// IsFirstCheckpoint indicates if this is the first checkpoint of the session
 	// When true, all working directory files are captured (not just modified)
 	IsFirstCheckpoint bool
+
+	// Ignored holds the .entire/ignore exclusions; changes to excluded paths
+	// are left out of the checkpoint. Nil excludes nothing.
+	Ignored *ignore.Matcher
 }
 
 // ReadTemporaryResult contains the result of reading a temporary checkpoint.
@@ -271,6 +358,11 @@ type WriteCommittedOptions struct {
 	// CheckpointTranscriptStart is written to both CommittedMetadata.CheckpointTranscriptStart
 	// and the deprecated CommittedMetadata.TranscriptLinesAtStart for backward compatibility.
 
+	// TranscriptParent is the checkpoint this session was previously condensed into.
+	// When the store has delta transcripts enabled and the parent's transcript for
+	// the session is a prefix of Transcript, only the appended lines are stored.
+	TranscriptParent id.CheckpointID
+
 	// TokenUsage contains the token usage for this checkpoint
 	TokenUsage *agent.TokenUsage
 
@@ -290,6 +382,10 @@ type WriteCommittedOptions struct {
 	// Persisted in CommittedMetadata so restore can write the transcript back to
 	// the correct location without reconstructing agent-specific paths.
 	SessionTranscriptPath string
+
+	// CreatedAt overrides the session's creation time, e.g. when importing a
+	// checkpoint exported from another repository. Zero means now.
+	CreatedAt time.Time
 }
 
 // UpdateCommittedOptions contains options for updating an existing committed checkpoint.
@@ -345,6 +441,11 @@ type CommittedInfo struct {
 	// Multi-session support
 	SessionCount int      // Number of sessions (1 if single session)
 	SessionIDs   []string // All session IDs that contributed
+	// Branch is the branch the checkpoint was created on (empty if detached HEAD)
+	Branch string
+
+	// TokenUsage is the token usage recorded for the checkpoint
+	TokenUsage *agent.TokenUsage
 }
 
 // SessionContent contains the actual content for a session.
@@ -357,6 +458,10 @@ type SessionContent struct {
 	// Transcript is the session transcript content
 	Transcript []byte
 
+	// NormalizedTranscript is the agent-neutral transcript (normalized.jsonl),
+	// one agent.SessionEntry per line. Empty if the agent has no converter.
+	NormalizedTranscript []byte
+
 	// Prompts contains user prompts from this session
 	Prompts string
 
@@ -394,6 +499,17 @@ type CommittedMetadata struct {
 	// Deprecated: Use CheckpointTranscriptStart instead. Written for backward compatibility with older CLI versions.
 	TranscriptLinesAtStart int `json:"transcript_lines_at_start,omitempty"`
 
+	// TranscriptParent is set when the transcript is delta-encoded: the stored
+	// transcript holds only the lines following the first TranscriptParentLines
+	// lines of the same session's transcript in the parent checkpoint.
+	TranscriptParent      id.CheckpointID `json:"transcript_parent,omitempty"`
+	TranscriptParentLines int             `json:"transcript_parent_lines,omitempty"`
+
+	// NormalizedParentLines is set when the normalized transcript is
+	// delta-encoded against the same parent: the stored normalized transcript
+	// holds only the entries following its first NormalizedParentLines lines.
+	NormalizedParentLines int `json:"normalized_parent_lines,omitempty"`
+
 	// Token usage for this checkpoint
 	TokenUsage *agent.TokenUsage `json:"token_usage,omitempty"`
 
@@ -428,6 +544,7 @@ type SessionFilePaths struct {
 	Context     string `json:"context"`
 	ContentHash string `json:"content_hash"`
 	Prompt      string `json:"prompt"`
+	Normalized  string `json:"normalized,omitempty"`
 }
 
 // CheckpointSummary is the root-level metadata.json for a checkpoint.
@@ -442,6 +559,7 @@ type SessionFilePaths struct {
 //	├── 1/                    # First session
 //	│   ├── metadata.json     # Session-specific CommittedMetadata
 //	│   ├── full.jsonl
+//	│   ├── normalized.jsonl  # Agent-neutral transcript, if the agent supports it
 //	│   ├── prompt.txt
 //	│   ├── context.md
 //	│   └── content_hash.txt
@@ -556,6 +674,10 @@ type WriteTemporaryTaskOptions struct {
 	// DeletedFiles are files that have been deleted (relative paths)
 	DeletedFiles []string
 
+	// Ignored holds the .entire/ignore exclusions; changes to excluded paths
+	// are left out of the checkpoint. Nil excludes nothing.
+	Ignored *ignore.Matcher
+
 	// TranscriptPath is the path to the main session transcript
 	TranscriptPath string
 
@@ -14,8 +14,10 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode" // Registers the Claude Code normalizer
 	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/ignore"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
@@ -658,7 +660,7 @@ func TestUpdateSummary_NotFound(t *testing.T) {
 	store := NewGitStore(repo)
 
 	// Ensure sessions branch exists
-	err := store.ensureSessionsBranch()
+	err := store.ensureSessionsBranch(context.Background())
 	if err != nil {
 		t.Fatalf("ensureSessionsBranch() error = %v", err)
 	}
@@ -1556,7 +1558,7 @@ func TestReadCommitted_NonexistentCheckpoint(t *testing.T) {
 	store := NewGitStore(repo)
 
 	// Ensure sessions branch exists
-	err := store.ensureSessionsBranch()
+	err := store.ensureSessionsBranch(context.Background())
 	if err != nil {
 		t.Fatalf("ensureSessionsBranch() error = %v", err)
 	}
@@ -1579,7 +1581,7 @@ func TestReadSessionContent_NonexistentCheckpoint(t *testing.T) {
 	store := NewGitStore(repo)
 
 	// Ensure sessions branch exists
-	err := store.ensureSessionsBranch()
+	err := store.ensureSessionsBranch(context.Background())
 	if err != nil {
 		t.Fatalf("ensureSessionsBranch() error = %v", err)
 	}
@@ -1892,6 +1894,89 @@ func TestWriteTemporary_FirstCheckpoint_ExcludesGitIgnoredFiles(t *testing.T) {
 	}
 }
 
+// TestWriteTemporary_ExcludesEntireIgnoredFiles verifies that changes to paths
+// excluded by .entire/ignore are left out of checkpoint trees.
+func TestWriteTemporary_ExcludesEntireIgnoredFiles(t *testing.T) {
+	tempDir := t.TempDir()
+
+	repo, err := git.PlainInit(tempDir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	writeFile := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	writeFile("package-lock.json", "{}\n")
+	if _, err := worktree.Add("package-lock.json"); err != nil {
+		t.Fatalf("failed to add package-lock.json: %v", err)
+	}
+	initialCommit, err := worktree.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@test.com"},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	writeFile(".entire/ignore", "# Generated files\npackage-lock.json\ndist/\n")
+	writeFile("package-lock.json", "{\"lockfileVersion\": 3}\n")
+	writeFile("dist/app.js", "console.log(1)\n")
+	writeFile("main.go", "package main\n")
+	t.Chdir(tempDir)
+	ignored, err := ignore.Load(tempDir)
+	if err != nil {
+		t.Fatalf("ignore.Load() error = %v", err)
+	}
+
+	store := NewGitStore(repo)
+	result, err := store.WriteTemporary(context.Background(), WriteTemporaryOptions{
+		SessionID:     "test-session",
+		BaseCommit:    initialCommit.String(),
+		ModifiedFiles: []string{"package-lock.json"},
+		NewFiles:      []string{"dist/app.js", "main.go"},
+		CommitMessage: "Checkpoint",
+		AuthorName:    "Test",
+		AuthorEmail:   "test@test.com",
+		Ignored:       ignored,
+	})
+	if err != nil {
+		t.Fatalf("WriteTemporary() error = %v", err)
+	}
+
+	commit, err := repo.CommitObject(result.CommitHash)
+	if err != nil {
+		t.Fatalf("failed to get commit object: %v", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatalf("failed to get tree: %v", err)
+	}
+	if _, err := tree.File("main.go"); err != nil {
+		t.Errorf("main.go should be in checkpoint tree: %v", err)
+	}
+	if _, err := tree.File("dist/app.js"); err == nil {
+		t.Error("ignored dist/app.js should NOT be in checkpoint tree")
+	}
+	lock, err := tree.File("package-lock.json")
+	if err != nil {
+		t.Fatalf("package-lock.json should keep its base version: %v", err)
+	}
+	if content, err := lock.Contents(); err != nil || content != "{}\n" {
+		t.Errorf("package-lock.json = %q, %v; want the base version", content, err)
+	}
+}
+
 // TestWriteTemporary_FirstCheckpoint_UserAndAgentChanges verifies that
 // the first checkpoint captures both user's pre-existing changes and agent changes.
 func TestWriteTemporary_FirstCheckpoint_UserAndAgentChanges(t *testing.T) {
@@ -2971,3 +3056,94 @@ func TestWriteCommitted_CLIVersionField(t *testing.T) {
 		t.Errorf("CommittedMetadata.CLIVersion = %q, want %q", sessionMetadata.CLIVersion, buildinfo.Version)
 	}
 }
+
+func TestWriteCommitted_WritesNormalizedTranscript(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	checkpointID := id.MustCheckpointID("aabbccddee11")
+
+	transcript := []byte(`{"type":"user","uuid":"u1","message":{"content":"Fix the bug"}}
+{"type":"assistant","uuid":"a1","message":{"content":[{"type":"text","text":"Fixed. Key: ` + highEntropySecret + `"},{"type":"tool_use","id":"t1","name":"Edit","input":{"file_path":"main.go"}}]}}
+`)
+
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     checkpointID,
+		SessionID:        "normalized-session",
+		Strategy:         "manual-commit",
+		Agent:            agent.AgentTypeClaudeCode,
+		Transcript:       transcript,
+		CheckpointsCount: 1,
+		AuthorName:       "Test Author",
+		AuthorEmail:      "test@example.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	wantPath := "/" + checkpointID.Path() + "/0/" + paths.NormalizedFileNameCompressed
+	if summary.Sessions[0].Normalized != wantPath {
+		t.Errorf("Sessions[0].Normalized = %q, want %q", summary.Sessions[0].Normalized, wantPath)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if strings.Contains(string(content.NormalizedTranscript), highEntropySecret) {
+		t.Error("normalized transcript should not contain the secret after redaction")
+	}
+
+	entries, err := agent.ParseNormalizedTranscript(content.NormalizedTranscript)
+	if err != nil {
+		t.Fatalf("ParseNormalizedTranscript() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d normalized entries, want 3: %+v", len(entries), entries)
+	}
+	if entries[0].Type != agent.EntryUser || entries[0].Content != "Fix the bug" {
+		t.Errorf("entries[0] = %+v, want user prompt", entries[0])
+	}
+	if entries[2].Type != agent.EntryTool || len(entries[2].FilesAffected) != 1 || entries[2].FilesAffected[0] != "main.go" {
+		t.Errorf("entries[2] = %+v, want Edit of main.go", entries[2])
+	}
+}
+
+func TestWriteCommitted_NoNormalizedTranscriptForUnknownAgent(t *testing.T) {
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	checkpointID := id.MustCheckpointID("aabbccddee12")
+
+	err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     checkpointID,
+		SessionID:        "unknown-agent-session",
+		Strategy:         "manual-commit",
+		Agent:            "Unknown Agent",
+		Transcript:       []byte(`{"role":"user","content":"hello"}` + "\n"),
+		CheckpointsCount: 1,
+		AuthorName:       "Test Author",
+		AuthorEmail:      "test@example.com",
+	})
+	if err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if summary.Sessions[0].Normalized != "" {
+		t.Errorf("Sessions[0].Normalized = %q, want empty", summary.Sessions[0].Normalized)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if len(content.NormalizedTranscript) != 0 {
+		t.Errorf("NormalizedTranscript = %q, want empty", content.NormalizedTranscript)
+	}
+}
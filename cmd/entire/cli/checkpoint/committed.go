@@ -43,7 +43,6 @@ var errStopIteration = errors.New("stop iteration")
 //   - For incremental checkpoints: checkpoints/NNN-<tool-use-id>.json
 //   - For final checkpoints: checkpoint.json and agent-<agent-id>.jsonl
 func (s *GitStore) WriteCommitted(ctx context.Context, opts WriteCommittedOptions) error {
-	_ = ctx // Reserved for future use
 
 	// Validate identifiers to prevent path traversal and malformed data
 	if opts.CheckpointID.IsEmpty() {
@@ -60,7 +59,7 @@ func (s *GitStore) WriteCommitted(ctx context.Context, opts WriteCommittedOption
 	}
 
 	// Ensure sessions branch exists
-	if err := s.ensureSessionsBranch(); err != nil {
+	if err := s.ensureSessionsBranch(ctx); err != nil {
 		return fmt.Errorf("failed to ensure sessions branch: %w", err)
 	}
 
@@ -96,7 +95,7 @@ func (s *GitStore) WriteCommitted(ctx context.Context, opts WriteCommittedOption
 	}
 
 	commitMsg := s.buildCommitMessage(opts, taskMetadataPath)
-	newCommitHash, err := s.createCommit(newTreeHash, ref.Hash(), commitMsg, opts.AuthorName, opts.AuthorEmail)
+	newCommitHash, err := s.createMetadataCommit(ctx, newTreeHash, ref.Hash(), commitMsg, opts.AuthorName, opts.AuthorEmail)
 	if err != nil {
 		return err
 	}
@@ -295,39 +294,39 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 	}
 
 	// Write transcript
-	if err := s.writeTranscript(opts, sessionPath, entries); err != nil {
+	transcriptName, delta, err := s.writeTranscript(opts, sessionPath, entries)
+	if err != nil {
 		return filePaths, err
 	}
-	filePaths.Transcript = "/" + sessionPath + paths.TranscriptFileName
+	if transcriptName == "" {
+		transcriptName = paths.TranscriptFileName
+	}
+	filePaths.Transcript = "/" + sessionPath + transcriptName
 	filePaths.ContentHash = "/" + sessionPath + paths.ContentHashFileName
+	filePaths.Normalized = normalizedTranscriptPath(entries, sessionPath)
 
 	// Write prompts
 	if len(opts.Prompts) > 0 {
 		promptContent := redact.String(strings.Join(opts.Prompts, "\n\n---\n\n"))
-		blobHash, err := CreateBlobFromContent(s.repo, []byte(promptContent))
+		promptPath, err := s.writeContentFile(entries, sessionPath+paths.PromptFileName, []byte(promptContent))
 		if err != nil {
 			return filePaths, err
 		}
-		entries[sessionPath+paths.PromptFileName] = object.TreeEntry{
-			Name: sessionPath + paths.PromptFileName,
-			Mode: filemode.Regular,
-			Hash: blobHash,
-		}
-		filePaths.Prompt = "/" + sessionPath + paths.PromptFileName
+		filePaths.Prompt = "/" + promptPath
 	}
 
 	// Write context
 	if len(opts.Context) > 0 {
-		blobHash, err := CreateBlobFromContent(s.repo, redact.Bytes(opts.Context))
+		contextPath, err := s.writeContentFile(entries, sessionPath+paths.ContextFileName, redact.Bytes(opts.Context))
 		if err != nil {
 			return filePaths, err
 		}
-		entries[sessionPath+paths.ContextFileName] = object.TreeEntry{
-			Name: sessionPath + paths.ContextFileName,
-			Mode: filemode.Regular,
-			Hash: blobHash,
-		}
-		filePaths.Context = "/" + sessionPath + paths.ContextFileName
+		filePaths.Context = "/" + contextPath
+	}
+
+	createdAt := opts.CreatedAt.UTC()
+	if opts.CreatedAt.IsZero() {
+		createdAt = time.Now().UTC()
 	}
 
 	// Write session-level metadata.json (CommittedMetadata with all fields including initial_attribution)
@@ -335,7 +334,7 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 		CheckpointID:                opts.CheckpointID,
 		SessionID:                   opts.SessionID,
 		Strategy:                    opts.Strategy,
-		CreatedAt:                   time.Now().UTC(),
+		CreatedAt:                   createdAt,
 		Branch:                      opts.Branch,
 		CheckpointsCount:            opts.CheckpointsCount,
 		FilesTouched:                opts.FilesTouched,
@@ -346,6 +345,9 @@ func (s *GitStore) writeSessionToSubdirectory(opts WriteCommittedOptions, sessio
 		TranscriptIdentifierAtStart: opts.TranscriptIdentifierAtStart,
 		CheckpointTranscriptStart:   opts.CheckpointTranscriptStart,
 		TranscriptLinesAtStart:      opts.CheckpointTranscriptStart, // Deprecated: kept for backward compat
+		TranscriptParent:            delta.Parent,
+		TranscriptParentLines:       delta.ParentLines,
+		NormalizedParentLines:       delta.NormalizedParentLines,
 		TokenUsage:                  opts.TokenUsage,
 		InitialAttribution:          opts.InitialAttribution,
 		Summary:                     opts.Summary,
@@ -509,8 +511,9 @@ func aggregateTokenUsage(a, b *agent.TokenUsage) *agent.TokenUsage {
 }
 
 // writeTranscript writes the transcript file from in-memory content or file path.
-// If the transcript exceeds MaxChunkSize, it's split into multiple chunk files.
-func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry) error {
+// Returns the base name of the transcript file written, or "" if there was no
+// transcript to write, and the delta the transcript was stored as.
+func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string, entries map[string]object.TreeEntry) (string, transcriptDelta, error) {
 	transcript := opts.Transcript
 	if len(transcript) == 0 && opts.TranscriptPath != "" {
 		var readErr error
@@ -521,27 +524,92 @@ func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string,
 		}
 	}
 	if len(transcript) == 0 {
-		return nil
+		return "", transcriptDelta{}, nil
 	}
 
 	// Redact secrets before chunking so content hash reflects redacted content
 	transcript, err := redact.JSONLBytes(transcript)
 	if err != nil {
-		return fmt.Errorf("failed to redact transcript secrets: %w", err)
+		return "", transcriptDelta{}, fmt.Errorf("failed to redact transcript secrets: %w", err)
 	}
 
-	// Chunk the transcript if it's too large
-	chunks, err := agent.ChunkTranscript(transcript, opts.Agent)
+	stored, delta := s.deltaEncodeTranscript(opts.CheckpointID, opts.SessionID, opts.TranscriptParent, transcript)
+	name, err := s.replaceTranscript(transcript, stored, opts.Agent, basePath, entries)
 	if err != nil {
-		return fmt.Errorf("failed to chunk transcript: %w", err)
+		return "", delta, err
 	}
+	delta.NormalizedParentLines, err = s.writeNormalizedTranscript(transcript, opts.Agent, opts.SessionID, delta.Parent, basePath, entries)
+	return name, delta, err
+}
 
-	// Write chunk files
+// writeNormalizedTranscript writes the agent-neutral normalized.jsonl next to the
+// native transcript, replacing any previous normalized files. Normalization is
+// best effort: agents without a converter, or transcripts the converter can't
+// parse, just get no normalized.jsonl. The transcript must already be redacted.
+//
+// The normalized transcript is stored like the native one: delta-encoded
+// against parent, the transcript parent, when it extends the parent's
+// normalized transcript; zstd-compressed as normalized.jsonl.zst with a
+// non-zero compression level; and encrypted when encryption is enabled.
+// Returns the number of parent lines the stored entries follow (0 when the
+// normalized transcript is stored in full).
+func (s *GitStore) writeNormalizedTranscript(transcript []byte, agentType agent.AgentType, sessionID string, parent id.CheckpointID, basePath string, entries map[string]object.TreeEntry) (int, error) {
+	normalizedBase := basePath + paths.NormalizedFileName
+	for key := range entries {
+		if key == normalizedBase || strings.HasPrefix(key, normalizedBase+".") {
+			delete(entries, key)
+		}
+	}
+
+	normalized, err := agent.NormalizeTranscript(transcript, agentType)
+	if err != nil {
+		if !errors.Is(err, agent.ErrNormalizeUnsupported) {
+			logging.Warn(context.Background(), "failed to normalize transcript",
+				slog.String("agent", string(agentType)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return 0, nil
+	}
+	if len(normalized) == 0 {
+		return 0, nil
+	}
+	stored, parentLines := s.deltaEncodeNormalized(sessionID, parent, normalized)
+
+	// The normalized transcript carries the same content as the transcript,
+	// so it is compressed and encrypted the same way.
+	fileName := paths.NormalizedFileName
+	var chunks [][]byte
+	if s.compressionLevel > 0 || s.encryption.encrypts() {
+		data := stored
+		if s.compressionLevel > 0 {
+			compressed, err := compressTranscript(data, s.compressionLevel)
+			if err != nil {
+				return 0, err
+			}
+			data = compressed
+			fileName = paths.NormalizedFileNameCompressed
+		}
+		if s.encryption.encrypts() {
+			encrypted, err := s.encryption.encrypt(data)
+			if err != nil {
+				return 0, err
+			}
+			data = encrypted
+			fileName += EncryptedFileSuffix
+		}
+		chunks = splitBytes(data, agent.MaxChunkSize)
+	} else {
+		chunks, err = agent.ChunkJSONL(stored, agent.MaxChunkSize)
+		if err != nil {
+			return 0, fmt.Errorf("failed to chunk normalized transcript: %w", err)
+		}
+	}
 	for i, chunk := range chunks {
-		chunkPath := basePath + agent.ChunkFileName(paths.TranscriptFileName, i)
+		chunkPath := basePath + agent.ChunkFileName(fileName, i)
 		blobHash, err := CreateBlobFromContent(s.repo, chunk)
 		if err != nil {
-			return err
+			return 0, fmt.Errorf("failed to create normalized transcript blob: %w", err)
 		}
 		entries[chunkPath] = object.TreeEntry{
 			Name: chunkPath,
@@ -549,19 +617,27 @@ func (s *GitStore) writeTranscript(opts WriteCommittedOptions, basePath string,
 			Hash: blobHash,
 		}
 	}
+	return parentLines, nil
+}
 
-	// Content hash for deduplication (hash of full transcript)
-	contentHash := fmt.Sprintf("sha256:%x", sha256.Sum256(transcript))
-	hashBlob, err := CreateBlobFromContent(s.repo, []byte(contentHash))
-	if err != nil {
-		return err
-	}
-	entries[basePath+paths.ContentHashFileName] = object.TreeEntry{
-		Name: basePath + paths.ContentHashFileName,
-		Mode: filemode.Regular,
-		Hash: hashBlob,
+// normalizedBaseNames lists the base names a normalized transcript can be
+// stored under, ending with the plain normalized.jsonl.
+var normalizedBaseNames = []string{
+	paths.NormalizedFileNameCompressed + EncryptedFileSuffix,
+	paths.NormalizedFileNameCompressed,
+	paths.NormalizedFileName + EncryptedFileSuffix,
+	paths.NormalizedFileName,
+}
+
+// normalizedTranscriptPath returns the path of the normalized transcript
+// stored for the session at sessionPath, from the tree root, or "" if none.
+func normalizedTranscriptPath(entries map[string]object.TreeEntry, sessionPath string) string {
+	for _, name := range normalizedBaseNames {
+		if _, ok := entries[sessionPath+name]; ok {
+			return "/" + sessionPath + name
+		}
 	}
-	return nil
+	return ""
 }
 
 // mergeFilesTouched combines two file lists, removing duplicates.
@@ -689,8 +765,6 @@ func (s *GitStore) ReadCommitted(ctx context.Context, checkpointID id.Checkpoint
 // Returns the session's metadata, transcript, prompts, and context.
 // Returns an error if the checkpoint or session doesn't exist.
 func (s *GitStore) ReadSessionContent(ctx context.Context, checkpointID id.CheckpointID, sessionIndex int) (*SessionContent, error) {
-	_ = ctx // Reserved for future use
-
 	tree, err := s.getSessionsBranchTree()
 	if err != nil {
 		return nil, ErrCheckpointNotFound
@@ -712,33 +786,48 @@ func (s *GitStore) ReadSessionContent(ctx context.Context, checkpointID id.Check
 	result := &SessionContent{}
 
 	// Read session-specific metadata
-	var agentType agent.AgentType
 	if metadataFile, fileErr := sessionTree.File(paths.MetadataFileName); fileErr == nil {
 		if content, contentErr := metadataFile.Contents(); contentErr == nil {
-			if jsonErr := json.Unmarshal([]byte(content), &result.Metadata); jsonErr == nil {
-				agentType = result.Metadata.Agent
-			}
+			_ = json.Unmarshal([]byte(content), &result.Metadata) //nolint:errcheck // Best effort: unreadable metadata leaves fields zero
 		}
 	}
 
-	// Read transcript
-	if transcript, transcriptErr := readTranscriptFromTree(sessionTree, agentType); transcriptErr == nil && transcript != nil {
+	// Read transcript, reassembling it from parent checkpoints if delta-encoded
+	transcript, transcriptErr := s.readSessionTranscript(tree, sessionTree, &result.Metadata)
+	if errors.Is(transcriptErr, ErrEncryptedContent) {
+		return nil, transcriptErr
+	}
+	if transcriptErr == nil && transcript != nil {
 		result.Transcript = transcript
+	} else if transcriptErr != nil && !result.Metadata.TranscriptParent.IsEmpty() {
+		logging.Warn(ctx, "failed to reassemble delta-encoded transcript",
+			slog.String("checkpoint_id", checkpointID.String()),
+			slog.String("error", transcriptErr.Error()),
+		)
+	}
+
+	// Read normalized transcript
+	normalized, normalizedErr := s.readSessionNormalized(tree, sessionTree, &result.Metadata)
+	if errors.Is(normalizedErr, ErrEncryptedContent) {
+		return nil, normalizedErr
+	}
+	if normalizedErr == nil {
+		result.NormalizedTranscript = normalized
 	}
 
 	// Read prompts
-	if file, fileErr := sessionTree.File(paths.PromptFileName); fileErr == nil {
-		if content, contentErr := file.Contents(); contentErr == nil {
-			result.Prompts = content
-		}
+	prompts, _, promptsErr := s.readContentFile(sessionTree, paths.PromptFileName)
+	if errors.Is(promptsErr, ErrEncryptedContent) {
+		return nil, promptsErr
 	}
+	result.Prompts = string(prompts)
 
 	// Read context
-	if file, fileErr := sessionTree.File(paths.ContextFileName); fileErr == nil {
-		if content, contentErr := file.Contents(); contentErr == nil {
-			result.Context = content
-		}
+	sessionContext, _, contextErr := s.readContentFile(sessionTree, paths.ContextFileName)
+	if errors.Is(contextErr, ErrEncryptedContent) {
+		return nil, contextErr
 	}
+	result.Context = string(sessionContext)
 
 	return result, nil
 }
@@ -848,6 +937,8 @@ func (s *GitStore) ListCommitted(ctx context.Context) ([]CommittedInfo, error) {
 						info.CheckpointsCount = summary.CheckpointsCount
 						info.FilesTouched = summary.FilesTouched
 						info.SessionCount = len(summary.Sessions)
+						info.Branch = summary.Branch
+						info.TokenUsage = summary.TokenUsage
 
 						// Read session metadata from latest session to get Agent, SessionID, CreatedAt
 						if len(summary.Sessions) > 0 {
@@ -913,27 +1004,12 @@ func (s *GitStore) GetSessionLog(cpID id.CheckpointID) ([]byte, string, error) {
 	return content.Transcript, content.Metadata.SessionID, nil
 }
 
-// LookupSessionLog is a convenience function that opens the repository and retrieves
-// a session log by checkpoint ID. This is the primary entry point for callers that
-// don't already have a GitStore instance.
-// Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
-// Returns ErrNoTranscript if the checkpoint exists but has no transcript.
-func LookupSessionLog(cpID id.CheckpointID) ([]byte, string, error) {
-	repo, err := git.PlainOpenWithOptions(".", &git.PlainOpenOptions{DetectDotGit: true})
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to open git repository: %w", err)
-	}
-	store := NewGitStore(repo)
-	return store.GetSessionLog(cpID)
-}
-
 // UpdateSummary updates the summary field in the latest session's metadata.
 // Returns ErrCheckpointNotFound if the checkpoint doesn't exist.
 func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.CheckpointID, summary *Summary) error {
-	_ = ctx // Reserved for future use
 
 	// Ensure sessions branch exists
-	if err := s.ensureSessionsBranch(); err != nil {
+	if err := s.ensureSessionsBranch(ctx); err != nil {
 		return fmt.Errorf("failed to ensure sessions branch: %w", err)
 	}
 
@@ -996,7 +1072,7 @@ func (s *GitStore) UpdateSummary(ctx context.Context, checkpointID id.Checkpoint
 
 	authorName, authorEmail := GetGitAuthorFromRepo(s.repo)
 	commitMsg := fmt.Sprintf("Update summary for checkpoint %s (session: %s)", checkpointID, existingMetadata.SessionID)
-	newCommitHash, err := s.createCommit(newTreeHash, ref.Hash(), commitMsg, authorName, authorEmail)
+	newCommitHash, err := s.createMetadataCommit(ctx, newTreeHash, ref.Hash(), commitMsg, authorName, authorEmail)
 	if err != nil {
 		return err
 	}
@@ -1024,7 +1100,7 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 	}
 
 	// Ensure sessions branch exists
-	if err := s.ensureSessionsBranch(); err != nil {
+	if err := s.ensureSessionsBranch(ctx); err != nil {
 		return fmt.Errorf("failed to ensure sessions branch: %w", err)
 	}
 
@@ -1081,36 +1157,24 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 		if err != nil {
 			return fmt.Errorf("failed to redact transcript secrets: %w", err)
 		}
-		if err := s.replaceTranscript(transcript, opts.Agent, sessionPath, entries); err != nil {
-			return fmt.Errorf("failed to replace transcript: %w", err)
+		if err := s.finalizeTranscript(transcript, opts, sessionPath, sessionIndex, entries); err != nil {
+			return err
 		}
 	}
 
 	// Replace prompts (apply redaction as safety net)
 	if len(opts.Prompts) > 0 {
 		promptContent := redact.String(strings.Join(opts.Prompts, "\n\n---\n\n"))
-		blobHash, err := CreateBlobFromContent(s.repo, []byte(promptContent))
-		if err != nil {
+		if _, err := s.writeContentFile(entries, sessionPath+paths.PromptFileName, []byte(promptContent)); err != nil {
 			return fmt.Errorf("failed to create prompt blob: %w", err)
 		}
-		entries[sessionPath+paths.PromptFileName] = object.TreeEntry{
-			Name: sessionPath + paths.PromptFileName,
-			Mode: filemode.Regular,
-			Hash: blobHash,
-		}
 	}
 
 	// Replace context (apply redaction as safety net)
 	if len(opts.Context) > 0 {
-		contextBlob, err := CreateBlobFromContent(s.repo, redact.Bytes(opts.Context))
-		if err != nil {
+		if _, err := s.writeContentFile(entries, sessionPath+paths.ContextFileName, redact.Bytes(opts.Context)); err != nil {
 			return fmt.Errorf("failed to create context blob: %w", err)
 		}
-		entries[sessionPath+paths.ContextFileName] = object.TreeEntry{
-			Name: sessionPath + paths.ContextFileName,
-			Mode: filemode.Regular,
-			Hash: contextBlob,
-		}
 	}
 
 	// Build and commit
@@ -1121,7 +1185,7 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 
 	authorName, authorEmail := GetGitAuthorFromRepo(s.repo)
 	commitMsg := fmt.Sprintf("Finalize transcript for Checkpoint: %s", opts.CheckpointID)
-	newCommitHash, err := s.createCommit(newTreeHash, ref.Hash(), commitMsg, authorName, authorEmail)
+	newCommitHash, err := s.createMetadataCommit(ctx, newTreeHash, ref.Hash(), commitMsg, authorName, authorEmail)
 	if err != nil {
 		return err
 	}
@@ -1135,10 +1199,64 @@ func (s *GitStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOpti
 	return nil
 }
 
-// replaceTranscript writes the full transcript content, replacing any existing transcript.
+// finalizeTranscript replaces a session's transcript during UpdateCommitted.
+// A delta-encoded session is re-encoded against the same parent, and the
+// session metadata and root summary are updated if the stored form changed.
+func (s *GitStore) finalizeTranscript(transcript []byte, opts UpdateCommittedOptions, sessionPath string, sessionIndex int, entries map[string]object.TreeEntry) error {
+	metaPath := sessionPath + paths.MetadataFileName
+	var meta *CommittedMetadata
+	if entry, ok := entries[metaPath]; ok {
+		if m, err := s.readMetadataFromBlob(entry.Hash); err == nil {
+			meta = m
+		}
+	}
+
+	stored := transcript
+	var delta transcriptDelta
+	var sessionID string
+	if meta != nil {
+		sessionID = meta.SessionID
+		stored, delta = s.deltaEncodeTranscript(opts.CheckpointID, sessionID, meta.TranscriptParent, transcript)
+	}
+	name, err := s.replaceTranscript(transcript, stored, opts.Agent, sessionPath, entries)
+	if err != nil {
+		return fmt.Errorf("failed to replace transcript: %w", err)
+	}
+	delta.NormalizedParentLines, err = s.writeNormalizedTranscript(transcript, opts.Agent, sessionID, delta.Parent, sessionPath, entries)
+	if err != nil {
+		return fmt.Errorf("failed to replace normalized transcript: %w", err)
+	}
+
+	if meta != nil && (meta.TranscriptParent != delta.Parent || meta.TranscriptParentLines != delta.ParentLines ||
+		meta.NormalizedParentLines != delta.NormalizedParentLines) {
+		meta.TranscriptParent, meta.TranscriptParentLines = delta.Parent, delta.ParentLines
+		meta.NormalizedParentLines = delta.NormalizedParentLines
+		if err := s.writeJSONEntry(entries, metaPath, meta); err != nil {
+			return err
+		}
+	}
+
+	// The root summary records the transcript paths, which change when a
+	// checkpoint written at a different compression level is finalized.
+	basePath := opts.CheckpointID.Path() + "/"
+	return s.setSessionTranscriptPath(entries, basePath, sessionIndex, name)
+}
+
+// replaceTranscript writes the transcript content, replacing any existing transcript.
 // Also removes any chunk files from a previous write and updates the content hash.
-func (s *GitStore) replaceTranscript(transcript []byte, agentType agent.AgentType, sessionPath string, entries map[string]object.TreeEntry) error {
-	// Remove existing transcript files (base + any chunks)
+// stored is what goes into the transcript files: the full transcript, or the
+// delta returned by deltaEncodeTranscript. The content hash is always computed
+// from the full transcript. The normalized transcript is written separately,
+// by writeNormalizedTranscript.
+//
+// With a non-zero compression level the transcript is stored zstd-compressed as
+// full.jsonl.zst, split into .001, .002, ... chunks when the compressed blob
+// exceeds agent.MaxChunkSize. Otherwise it's chunked raw by agent.ChunkTranscript.
+// With encryption enabled the (compressed) transcript is then encrypted and
+// stored as full.jsonl.zst.age or full.jsonl.age, split the same way.
+// Returns the base name of the transcript file written.
+func (s *GitStore) replaceTranscript(transcript, stored []byte, agentType agent.AgentType, sessionPath string, entries map[string]object.TreeEntry) (string, error) {
+	// Remove existing transcript files (base + any chunks, compressed or not)
 	transcriptBase := sessionPath + paths.TranscriptFileName
 	for key := range entries {
 		if key == transcriptBase || strings.HasPrefix(key, transcriptBase+".") {
@@ -1146,18 +1264,41 @@ func (s *GitStore) replaceTranscript(transcript []byte, agentType agent.AgentTyp
 		}
 	}
 
-	// Chunk the transcript (matches writeTranscript behavior)
-	chunks, err := agent.ChunkTranscript(transcript, agentType)
-	if err != nil {
-		return fmt.Errorf("failed to chunk transcript: %w", err)
+	fileName := paths.TranscriptFileName
+	var chunks [][]byte
+	if s.compressionLevel > 0 || s.encryption.encrypts() {
+		data := stored
+		if s.compressionLevel > 0 {
+			compressed, err := compressTranscript(data, s.compressionLevel)
+			if err != nil {
+				return "", err
+			}
+			data = compressed
+			fileName = paths.TranscriptFileNameCompressed
+		}
+		if s.encryption.encrypts() {
+			encrypted, err := s.encryption.encrypt(data)
+			if err != nil {
+				return "", err
+			}
+			data = encrypted
+			fileName += EncryptedFileSuffix
+		}
+		chunks = splitBytes(data, agent.MaxChunkSize)
+	} else {
+		var err error
+		chunks, err = agent.ChunkTranscript(stored, agentType)
+		if err != nil {
+			return "", fmt.Errorf("failed to chunk transcript: %w", err)
+		}
 	}
 
 	// Write chunk files
 	for i, chunk := range chunks {
-		chunkPath := sessionPath + agent.ChunkFileName(paths.TranscriptFileName, i)
+		chunkPath := sessionPath + agent.ChunkFileName(fileName, i)
 		blobHash, err := CreateBlobFromContent(s.repo, chunk)
 		if err != nil {
-			return fmt.Errorf("failed to create transcript blob: %w", err)
+			return "", fmt.Errorf("failed to create transcript blob: %w", err)
 		}
 		entries[chunkPath] = object.TreeEntry{
 			Name: chunkPath,
@@ -1166,11 +1307,11 @@ func (s *GitStore) replaceTranscript(transcript []byte, agentType agent.AgentTyp
 		}
 	}
 
-	// Update content hash
+	// Content hash for deduplication (hash of the full, uncompressed transcript)
 	contentHash := fmt.Sprintf("sha256:%x", sha256.Sum256(transcript))
 	hashBlob, err := CreateBlobFromContent(s.repo, []byte(contentHash))
 	if err != nil {
-		return fmt.Errorf("failed to create content hash blob: %w", err)
+		return "", fmt.Errorf("failed to create content hash blob: %w", err)
 	}
 	hashPath := sessionPath + paths.ContentHashFileName
 	entries[hashPath] = object.TreeEntry{
@@ -1179,11 +1320,92 @@ func (s *GitStore) replaceTranscript(transcript []byte, agentType agent.AgentTyp
 		Hash: hashBlob,
 	}
 
+	return fileName, nil
+}
+
+// ReadCommittedFile reads a file by its path on the entire/checkpoints/v1 branch
+// (falling back to origin/entire/checkpoints/v1 if there is no local branch).
+func (s *GitStore) ReadCommittedFile(ctx context.Context, path string) ([]byte, error) {
+	_ = ctx // Reserved for future use
+
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, err
+	}
+	file, err := tree.File(path)
+	if err != nil {
+		// Encrypted content is stored under path.age.
+		encryptedFile, encErr := tree.File(path + EncryptedFileSuffix)
+		if encErr != nil {
+			return nil, fmt.Errorf("failed to find %s: %w", path, err)
+		}
+		content, err := encryptedFile.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", encryptedFile.Name, err)
+		}
+		return s.encryption.decrypt([]byte(content))
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return []byte(content), nil
+}
+
+// DeleteCommitted removes checkpoint directories from the entire/checkpoints/v1
+// branch in a single commit. IDs that don't exist are ignored.
+func (s *GitStore) DeleteCommitted(ctx context.Context, checkpointIDs []id.CheckpointID) error {
+
+	if len(checkpointIDs) == 0 {
+		return nil
+	}
+
+	ref, entries, err := s.getSessionsBranchEntries()
+	if err != nil {
+		return err
+	}
+
+	// Sessions delta-encoded against a deleted checkpoint are rewritten with
+	// their full transcripts first.
+	deleted := make(map[id.CheckpointID]bool, len(checkpointIDs))
+	for _, cpID := range checkpointIDs {
+		deleted[cpID] = true
+	}
+	root, err := s.getSessionsBranchTree()
+	if err != nil {
+		return err
+	}
+	if _, err := s.inlineTranscripts(root, entries, deleted, func(cpID id.CheckpointID) bool {
+		return !deleted[cpID]
+	}); err != nil {
+		return err
+	}
+
+	removed := removeCheckpointEntries(entries, deleted)
+	if removed == 0 {
+		return nil
+	}
+
+	newTreeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("Cleanup: removed %d checkpoints", removed)
+	newCommitHash, err := s.createMetadataCommit(ctx, newTreeHash, ref.Hash(), commitMsg, "Entire CLI", "cli@entire.io")
+	if err != nil {
+		return err
+	}
+
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, newCommitHash)); err != nil {
+		return fmt.Errorf("failed to set branch reference: %w", err)
+	}
 	return nil
 }
 
 // ensureSessionsBranch ensures the entire/checkpoints/v1 branch exists.
-func (s *GitStore) ensureSessionsBranch() error {
+func (s *GitStore) ensureSessionsBranch(ctx context.Context) error {
 	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
 	_, err := s.repo.Reference(refName, true)
 	if err == nil {
@@ -1197,7 +1419,7 @@ func (s *GitStore) ensureSessionsBranch() error {
 	}
 
 	authorName, authorEmail := GetGitAuthorFromRepo(s.repo)
-	commitHash, err := s.createCommit(emptyTreeHash, plumbing.ZeroHash, "Initialize sessions branch", authorName, authorEmail)
+	commitHash, err := s.createMetadataCommit(ctx, emptyTreeHash, plumbing.ZeroHash, "Initialize sessions branch", authorName, authorEmail)
 	if err != nil {
 		return err
 	}
@@ -1293,14 +1515,25 @@ func (s *GitStore) copyMetadataDir(metadataDir, basePath string, entries map[str
 			return fmt.Errorf("path traversal detected: %s", relPath)
 		}
 
-		// Create blob from file with secrets redaction
-		blobHash, mode, err := createRedactedBlobFromFile(s.repo, path, relPath)
+		// Read file with secrets redaction
+		content, mode, err := readRedactedFile(path, relPath)
 		if err != nil {
 			return fmt.Errorf("failed to create blob for %s: %w", path, err)
 		}
 
-		// Store at checkpoint path
+		// Store at checkpoint path, encrypted unless it is JSON metadata
 		fullPath := basePath + relPath
+		if s.encryption.encrypts() && shouldEncryptFile(relPath) {
+			content, err = s.encryption.encrypt(content)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt %s: %w", path, err)
+			}
+			fullPath += EncryptedFileSuffix
+		}
+		blobHash, err := CreateBlobFromContent(s.repo, content)
+		if err != nil {
+			return fmt.Errorf("failed to create blob for %s: %w", path, err)
+		}
 		entries[fullPath] = object.TreeEntry{
 			Name: fullPath,
 			Mode: mode,
@@ -1315,12 +1548,13 @@ func (s *GitStore) copyMetadataDir(metadataDir, basePath string, entries map[str
 	return nil
 }
 
-// createRedactedBlobFromFile reads a file, applies secrets redaction, and creates a git blob.
+// readRedactedFile reads a file and applies secrets redaction, returning the
+// content and the git file mode to store it with.
 // JSONL files get JSONL-aware redaction; all other files get plain string redaction.
-func createRedactedBlobFromFile(repo *git.Repository, filePath, treePath string) (plumbing.Hash, filemode.FileMode, error) {
+func readRedactedFile(filePath, treePath string) ([]byte, filemode.FileMode, error) {
 	info, err := os.Stat(filePath)
 	if err != nil {
-		return plumbing.ZeroHash, 0, fmt.Errorf("failed to stat file: %w", err)
+		return nil, 0, fmt.Errorf("failed to stat file: %w", err)
 	}
 
 	mode := filemode.Regular
@@ -1330,34 +1564,25 @@ func createRedactedBlobFromFile(repo *git.Repository, filePath, treePath string)
 
 	content, err := os.ReadFile(filePath) //nolint:gosec // filePath comes from walking the metadata directory
 	if err != nil {
-		return plumbing.ZeroHash, 0, fmt.Errorf("failed to read file: %w", err)
+		return nil, 0, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Skip redaction for binary files — they can't contain text secrets and
 	// running string replacement on them would corrupt the data.
 	isBin, binErr := binary.IsBinary(bytes.NewReader(content))
 	if binErr != nil || isBin {
-		hash, err := CreateBlobFromContent(repo, content)
-		if err != nil {
-			return plumbing.ZeroHash, 0, fmt.Errorf("failed to create blob: %w", err)
-		}
-		return hash, mode, nil
+		return content, mode, nil
 	}
 
 	if strings.HasSuffix(treePath, ".jsonl") {
 		content, err = redact.JSONLBytes(content)
 		if err != nil {
-			return plumbing.ZeroHash, 0, fmt.Errorf("failed to redact secrets: %w", err)
+			return nil, 0, fmt.Errorf("failed to redact secrets: %w", err)
 		}
 	} else {
 		content = redact.Bytes(content)
 	}
-
-	hash, err := CreateBlobFromContent(repo, content)
-	if err != nil {
-		return plumbing.ZeroHash, 0, fmt.Errorf("failed to create blob: %w", err)
-	}
-	return hash, mode, nil
+	return content, mode, nil
 }
 
 // GetGitAuthorFromRepo retrieves the git user.name and user.email,
@@ -1394,10 +1619,49 @@ func GetGitAuthorFromRepo(repo *git.Repository) (name, email string) {
 	return name, email
 }
 
+// readNormalizedTranscriptFromTree reads normalized.jsonl and any chunk files from a git tree.
+// Returns nil if the session has no normalized transcript.
+func readNormalizedTranscriptFromTree(tree *object.Tree) ([]byte, error) {
+	var chunkFiles []string
+	for _, entry := range tree.Entries {
+		if entry.Name == paths.NormalizedFileName || agent.ParseChunkIndex(entry.Name, paths.NormalizedFileName) > 0 {
+			chunkFiles = append(chunkFiles, entry.Name)
+		}
+	}
+	if len(chunkFiles) == 0 {
+		return nil, nil //nolint:nilnil // Sessions without a normalized transcript are not an error
+	}
+
+	var chunks [][]byte
+	for _, chunkFile := range agent.SortChunkFiles(chunkFiles, paths.NormalizedFileName) {
+		file, err := tree.File(chunkFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", chunkFile, err)
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s contents: %w", chunkFile, err)
+		}
+		chunks = append(chunks, []byte(content))
+	}
+	return agent.ReassembleJSONL(chunks), nil
+}
+
 // readTranscriptFromTree reads a transcript from a git tree, handling both chunked and non-chunked formats.
 // It checks for chunk files first (.001, .002, etc.), then falls back to the base file.
 // The agentType is used for reassembling chunks in the correct format.
 func readTranscriptFromTree(tree *object.Tree, agentType agent.AgentType) ([]byte, error) {
+	// Compressed transcripts (full.jsonl.zst plus any .001, .002 chunks) take
+	// precedence; checkpoints written before compression only have full.jsonl.
+	// The compressed blob is split at byte boundaries, so chunks are
+	// concatenated before decompressing.
+	if compressed, ok, err := readConcatenatedChunks(tree, paths.TranscriptFileNameCompressed); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		return decompressTranscript(compressed)
+	}
+
 	// Collect all transcript-related files
 	var chunkFiles []string
 	var hasBaseFile bool
@@ -1474,6 +1738,35 @@ func readTranscriptFromTree(tree *object.Tree, agentType agent.AgentType) ([]byt
 	return nil, nil
 }
 
+// readConcatenatedChunks reads a file that was split at byte boundaries by
+// splitBytes (baseName plus any .001, .002 chunks) and concatenates the pieces.
+// Reports false if the tree has none.
+func readConcatenatedChunks(tree *object.Tree, baseName string) ([]byte, bool, error) {
+	var chunkFiles []string
+	for _, entry := range tree.Entries {
+		if entry.Name == baseName || agent.ParseChunkIndex(entry.Name, baseName) > 0 {
+			chunkFiles = append(chunkFiles, entry.Name)
+		}
+	}
+	if len(chunkFiles) == 0 {
+		return nil, false, nil
+	}
+
+	var data []byte
+	for _, chunkFile := range agent.SortChunkFiles(chunkFiles, baseName) {
+		file, err := tree.File(chunkFile)
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to read chunk %s: %w", chunkFile, err)
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to read chunk %s: %w", chunkFile, err)
+		}
+		data = append(data, content...)
+	}
+	return data, true, nil
+}
+
 // Author contains author information for a checkpoint.
 type Author struct {
 	Name  string
@@ -0,0 +1,56 @@
+package checkpoint
+
+import (
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultCompressionLevel is the zstd level committed transcripts are written
+// with unless the store is configured otherwise.
+const DefaultCompressionLevel = 3
+
+// MaxCompressionLevel is the highest zstd level (the library maps it to its best setting).
+const MaxCompressionLevel = 22
+
+// compressTranscript compresses a transcript with zstd at the given level (1-22).
+func compressTranscript(data []byte, level int) ([]byte, error) {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	defer enc.Close()
+	return enc.EncodeAll(data, make([]byte, 0, len(data)/4)), nil
+}
+
+// decompressTranscript decompresses a zstd-compressed transcript.
+func decompressTranscript(data []byte) ([]byte, error) {
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+	defer dec.Close()
+	out, err := dec.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress transcript: %w", err)
+	}
+	return out, nil
+}
+
+// splitBytes splits data into pieces of at most size bytes. Compressed
+// transcripts are binary, so unlike agent.ChunkTranscript this doesn't look
+// for line or message boundaries; the pieces are simply concatenated on read.
+func splitBytes(data []byte, size int) [][]byte {
+	if len(data) <= size {
+		return [][]byte{data}
+	}
+	var chunks [][]byte
+	for len(data) > size {
+		chunks = append(chunks, data[:size])
+		data = data[size:]
+	}
+	if len(data) > 0 {
+		chunks = append(chunks, data)
+	}
+	return chunks
+}
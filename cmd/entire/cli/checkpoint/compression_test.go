@@ -0,0 +1,245 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// compressionTestTranscript is large and repetitive enough to compress well.
+var compressionTestTranscript = []byte(strings.Repeat(`{"type":"user","message":{"content":"refactor the parser"}}`+"\n", 200))
+
+// sessionTreeFileNames returns the file names in a checkpoint's session directory.
+func sessionTreeFileNames(t *testing.T, store *GitStore, checkpointID id.CheckpointID, sessionIndex int) []string {
+	t.Helper()
+	tree, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("getSessionsBranchTree() error = %v", err)
+	}
+	sessionTree, err := tree.Tree(fmt.Sprintf("%s/%d", checkpointID.Path(), sessionIndex))
+	if err != nil {
+		t.Fatalf("session tree not found: %v", err)
+	}
+	var names []string
+	for _, entry := range sessionTree.Entries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWriteCommitted_CompressesTranscript(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	checkpointID := id.MustCheckpointID("c0aa11223344")
+
+	if err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID: checkpointID,
+		SessionID:    "compressed-session",
+		Strategy:     "manual-commit",
+		Transcript:   compressionTestTranscript,
+		AuthorName:   "Test",
+		AuthorEmail:  "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	names := sessionTreeFileNames(t, store, checkpointID, 0)
+	if !containsName(names, paths.TranscriptFileNameCompressed) || containsName(names, paths.TranscriptFileName) {
+		t.Errorf("session files = %v, want %s and no %s", names, paths.TranscriptFileNameCompressed, paths.TranscriptFileName)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if want := "/" + checkpointID.Path() + "/0/" + paths.TranscriptFileNameCompressed; summary.Sessions[0].Transcript != want {
+		t.Errorf("Sessions[0].Transcript = %q, want %q", summary.Sessions[0].Transcript, want)
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if !bytes.Equal(content.Transcript, compressionTestTranscript) {
+		t.Errorf("Transcript was not decompressed: got %d bytes, want %d", len(content.Transcript), len(compressionTestTranscript))
+	}
+
+	// The content hash covers the uncompressed transcript.
+	hash, err := store.ReadCommittedFile(context.Background(), checkpointID.Path()+"/0/"+paths.ContentHashFileName)
+	if err != nil {
+		t.Fatalf("ReadCommittedFile(content hash) error = %v", err)
+	}
+	if want := fmt.Sprintf("sha256:%x", sha256.Sum256(compressionTestTranscript)); string(hash) != want {
+		t.Errorf("content hash = %q, want %q", hash, want)
+	}
+}
+
+func TestWriteCommitted_CompressionDisabled(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	store.SetCompressionLevel(0)
+	checkpointID := id.MustCheckpointID("c0aa11223355")
+
+	if err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID: checkpointID,
+		SessionID:    "raw-session",
+		Strategy:     "manual-commit",
+		Transcript:   compressionTestTranscript,
+		AuthorName:   "Test",
+		AuthorEmail:  "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	names := sessionTreeFileNames(t, store, checkpointID, 0)
+	if !containsName(names, paths.TranscriptFileName) || containsName(names, paths.TranscriptFileNameCompressed) {
+		t.Errorf("session files = %v, want only the raw %s", names, paths.TranscriptFileName)
+	}
+}
+
+// TestUpdateCommitted_CompressesUncompressedCheckpoint verifies that a
+// checkpoint written before compression is readable and that finalizing it
+// replaces the raw transcript and the path recorded in the root summary.
+func TestUpdateCommitted_CompressesUncompressedCheckpoint(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	legacy := NewGitStore(repo)
+	legacy.SetCompressionLevel(0)
+	checkpointID := id.MustCheckpointID("c0aa11223366")
+
+	if err := legacy.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID: checkpointID,
+		SessionID:    "session-1",
+		Strategy:     "manual-commit",
+		Transcript:   []byte(`{"type":"user","message":{"content":"provisional"}}` + "\n"),
+		AuthorName:   "Test",
+		AuthorEmail:  "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	store := NewGitStore(repo)
+	content, err := store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() of uncompressed checkpoint error = %v", err)
+	}
+	if !strings.Contains(string(content.Transcript), "provisional") {
+		t.Errorf("Transcript = %q, want the uncompressed transcript", content.Transcript)
+	}
+
+	if err := store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+		CheckpointID: checkpointID,
+		SessionID:    "session-1",
+		Transcript:   compressionTestTranscript,
+	}); err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+
+	names := sessionTreeFileNames(t, store, checkpointID, 0)
+	if !containsName(names, paths.TranscriptFileNameCompressed) || containsName(names, paths.TranscriptFileName) {
+		t.Errorf("session files = %v, want the raw transcript replaced by %s", names, paths.TranscriptFileNameCompressed)
+	}
+	summary, err := store.ReadCommitted(context.Background(), checkpointID)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if !strings.HasSuffix(summary.Sessions[0].Transcript, paths.TranscriptFileNameCompressed) {
+		t.Errorf("Sessions[0].Transcript = %q, want the compressed path", summary.Sessions[0].Transcript)
+	}
+	content, err = store.ReadSessionContent(context.Background(), checkpointID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if !bytes.Equal(content.Transcript, compressionTestTranscript) {
+		t.Errorf("Transcript = %d bytes, want the finalized transcript", len(content.Transcript))
+	}
+}
+
+// TestReadTranscriptFromTree_CompressedChunks verifies that a compressed
+// transcript split across chunk files is concatenated before decompressing.
+func TestReadTranscriptFromTree_CompressedChunks(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+
+	compressed, err := compressTranscript(compressionTestTranscript, DefaultCompressionLevel)
+	if err != nil {
+		t.Fatalf("compressTranscript() error = %v", err)
+	}
+	chunks := splitBytes(compressed, 16)
+	if len(chunks) < 3 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+
+	entries := make(map[string]object.TreeEntry)
+	for i, chunk := range chunks {
+		name := agent.ChunkFileName(paths.TranscriptFileNameCompressed, i)
+		hash, err := CreateBlobFromContent(repo, chunk)
+		if err != nil {
+			t.Fatalf("CreateBlobFromContent() error = %v", err)
+		}
+		entries[name] = object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: hash}
+	}
+	treeHash, err := BuildTreeFromEntries(repo, entries)
+	if err != nil {
+		t.Fatalf("BuildTreeFromEntries() error = %v", err)
+	}
+	tree, err := repo.TreeObject(treeHash)
+	if err != nil {
+		t.Fatalf("TreeObject() error = %v", err)
+	}
+
+	transcript, err := readTranscriptFromTree(tree, "")
+	if err != nil {
+		t.Fatalf("readTranscriptFromTree() error = %v", err)
+	}
+	if !bytes.Equal(transcript, compressionTestTranscript) {
+		t.Errorf("readTranscriptFromTree() = %d bytes, want %d", len(transcript), len(compressionTestTranscript))
+	}
+}
+
+func TestSplitBytes(t *testing.T) {
+	t.Parallel()
+	data := []byte("0123456789")
+	tests := []struct {
+		size int
+		want []string
+	}{
+		{size: 20, want: []string{"0123456789"}},
+		{size: 10, want: []string{"0123456789"}},
+		{size: 4, want: []string{"0123", "4567", "89"}},
+		{size: 5, want: []string{"01234", "56789"}},
+	}
+	for _, tt := range tests {
+		got := splitBytes(data, tt.size)
+		if len(got) != len(tt.want) {
+			t.Errorf("splitBytes(size=%d) = %q, want %q", tt.size, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if string(got[i]) != tt.want[i] {
+				t.Errorf("splitBytes(size=%d)[%d] = %q, want %q", tt.size, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
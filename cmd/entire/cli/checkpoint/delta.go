@@ -0,0 +1,376 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Delta-encoded transcripts
+//
+// Every checkpoint of a session normally stores the session's whole transcript.
+// With delta transcripts enabled, a session condensed into several checkpoints
+// stores only the lines appended since its previous checkpoint (the transcript
+// parent), and reads reassemble the full transcript by following the chain of
+// parents. The normalized transcript is delta-encoded against the same parent
+// when the parent's normalized transcript is a prefix of it. The content hash
+// always describes the full transcript.
+//
+// A delta records how many lines of the parent's transcript it extends rather
+// than assuming the parent's whole transcript, because finalization later
+// replaces the parent's transcript with a longer one.
+
+// maxTranscriptParentDepth bounds the parent chain followed when reassembling
+// a transcript, guarding against cycles in corrupt metadata.
+const maxTranscriptParentDepth = 1000
+
+// transcriptDelta describes how a session transcript is stored. The zero value
+// means the full transcript is stored.
+type transcriptDelta struct {
+	Parent      id.CheckpointID
+	ParentLines int
+
+	// NormalizedParentLines is the number of lines of the parent's
+	// normalized transcript the stored normalized transcript extends, or 0
+	// if it is stored in full.
+	NormalizedParentLines int
+}
+
+// deltaEncodeTranscript returns the part of transcript to store for a session
+// of checkpointID, and the delta it represents. It falls back to the full
+// transcript unless delta transcripts are enabled and parent's transcript for
+// the same session is a line-aligned prefix of transcript.
+func (s *GitStore) deltaEncodeTranscript(checkpointID id.CheckpointID, sessionID string, parent id.CheckpointID, transcript []byte) ([]byte, transcriptDelta) {
+	if !s.deltaTranscripts || parent.IsEmpty() || parent == checkpointID {
+		return transcript, transcriptDelta{}
+	}
+	root, err := s.getSessionsBranchTree()
+	if err != nil {
+		return transcript, transcriptDelta{}
+	}
+	base, err := s.readCheckpointSessionTranscript(root, parent, sessionID, 0)
+	if err != nil {
+		logging.Debug(context.Background(), "storing full transcript: parent transcript unavailable",
+			slog.String("checkpoint_id", checkpointID.String()),
+			slog.String("parent", parent.String()),
+			slog.String("error", err.Error()),
+		)
+		return transcript, transcriptDelta{}
+	}
+	if len(base) == 0 || base[len(base)-1] != '\n' || !bytes.HasPrefix(transcript, base) {
+		return transcript, transcriptDelta{}
+	}
+	return transcript[len(base):], transcriptDelta{Parent: parent, ParentLines: bytes.Count(base, []byte("\n"))}
+}
+
+// deltaEncodeNormalized returns the part of a session's normalized transcript
+// to store, and the number of lines of parent's normalized transcript it
+// extends. parent is the transcript parent chosen by deltaEncodeTranscript;
+// the normalized transcript is stored in full if it is empty or the parent's
+// normalized transcript is not a line-aligned prefix of normalized.
+func (s *GitStore) deltaEncodeNormalized(sessionID string, parent id.CheckpointID, normalized []byte) ([]byte, int) {
+	if parent.IsEmpty() {
+		return normalized, 0
+	}
+	root, err := s.getSessionsBranchTree()
+	if err != nil {
+		return normalized, 0
+	}
+	base, err := s.readCheckpointSessionNormalized(root, parent, sessionID, 0)
+	if err != nil || len(base) == 0 || len(base) == len(normalized) ||
+		base[len(base)-1] != '\n' || !bytes.HasPrefix(normalized, base) {
+		return normalized, 0
+	}
+	return normalized[len(base):], bytes.Count(base, []byte("\n"))
+}
+
+// readSessionTranscript reads a session's full transcript from sessionTree,
+// following meta's transcript parent through root if it is delta-encoded.
+func (s *GitStore) readSessionTranscript(root, sessionTree *object.Tree, meta *CommittedMetadata) ([]byte, error) {
+	return s.resolveSessionTranscript(root, sessionTree, meta, 0)
+}
+
+func (s *GitStore) resolveSessionTranscript(root, sessionTree *object.Tree, meta *CommittedMetadata, depth int) ([]byte, error) {
+	stored, err := s.readStoredTranscript(sessionTree, meta.Agent)
+	if err != nil || meta.TranscriptParent.IsEmpty() {
+		return stored, err
+	}
+	if depth >= maxTranscriptParentDepth {
+		return nil, fmt.Errorf("transcript parent chain exceeds %d checkpoints", maxTranscriptParentDepth)
+	}
+
+	parent, err := s.readCheckpointSessionTranscript(root, meta.TranscriptParent, meta.SessionID, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parent transcript from checkpoint %s: %w", meta.TranscriptParent, err)
+	}
+	base, ok := headLines(parent, meta.TranscriptParentLines)
+	if !ok {
+		return nil, fmt.Errorf("parent transcript in checkpoint %s has fewer than %d lines", meta.TranscriptParent, meta.TranscriptParentLines)
+	}
+
+	transcript := make([]byte, 0, len(base)+len(stored))
+	transcript = append(transcript, base...)
+	return append(transcript, stored...), nil
+}
+
+// readCheckpointSessionTranscript reads the full transcript of the session
+// with sessionID in checkpointID.
+func (s *GitStore) readCheckpointSessionTranscript(root *object.Tree, checkpointID id.CheckpointID, sessionID string, depth int) ([]byte, error) {
+	sessionTree, meta, err := findCheckpointSession(root, checkpointID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveSessionTranscript(root, sessionTree, meta, depth)
+}
+
+// readSessionNormalized reads a session's full normalized transcript from
+// sessionTree, following meta's transcript parent through root if it is
+// delta-encoded. Returns nil if the session has no normalized transcript.
+func (s *GitStore) readSessionNormalized(root, sessionTree *object.Tree, meta *CommittedMetadata) ([]byte, error) {
+	return s.resolveSessionNormalized(root, sessionTree, meta, 0)
+}
+
+func (s *GitStore) resolveSessionNormalized(root, sessionTree *object.Tree, meta *CommittedMetadata, depth int) ([]byte, error) {
+	stored, ok, err := s.readStoredNormalized(sessionTree)
+	if err != nil || !ok || meta.NormalizedParentLines == 0 || meta.TranscriptParent.IsEmpty() {
+		return stored, err
+	}
+	if depth >= maxTranscriptParentDepth {
+		return nil, fmt.Errorf("transcript parent chain exceeds %d checkpoints", maxTranscriptParentDepth)
+	}
+
+	parent, err := s.readCheckpointSessionNormalized(root, meta.TranscriptParent, meta.SessionID, depth+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parent normalized transcript from checkpoint %s: %w", meta.TranscriptParent, err)
+	}
+	base, ok := headLines(parent, meta.NormalizedParentLines)
+	if !ok {
+		return nil, fmt.Errorf("parent normalized transcript in checkpoint %s has fewer than %d lines", meta.TranscriptParent, meta.NormalizedParentLines)
+	}
+
+	normalized := make([]byte, 0, len(base)+len(stored))
+	normalized = append(normalized, base...)
+	return append(normalized, stored...), nil
+}
+
+// readCheckpointSessionNormalized reads the full normalized transcript of the
+// session with sessionID in checkpointID.
+func (s *GitStore) readCheckpointSessionNormalized(root *object.Tree, checkpointID id.CheckpointID, sessionID string, depth int) ([]byte, error) {
+	sessionTree, meta, err := findCheckpointSession(root, checkpointID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolveSessionNormalized(root, sessionTree, meta, depth)
+}
+
+// findCheckpointSession returns the directory and metadata of the session
+// with sessionID in checkpointID.
+func findCheckpointSession(root *object.Tree, checkpointID id.CheckpointID, sessionID string) (*object.Tree, *CommittedMetadata, error) {
+	checkpointTree, err := root.Tree(checkpointID.Path())
+	if err != nil {
+		return nil, nil, ErrCheckpointNotFound
+	}
+	for _, entry := range checkpointTree.Entries {
+		if entry.Mode != filemode.Dir {
+			continue
+		}
+		sessionTree, err := checkpointTree.Tree(entry.Name)
+		if err != nil {
+			continue
+		}
+		meta, err := readSessionMetadataFromTree(sessionTree)
+		if err != nil || meta.SessionID != sessionID {
+			continue
+		}
+		return sessionTree, meta, nil
+	}
+	return nil, nil, fmt.Errorf("session %q not found in checkpoint %s", sessionID, checkpointID)
+}
+
+// readSessionMetadataFromTree reads metadata.json from a session directory.
+func readSessionMetadataFromTree(sessionTree *object.Tree) (*CommittedMetadata, error) {
+	file, err := sessionTree.File(paths.MetadataFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find session metadata: %w", err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session metadata: %w", err)
+	}
+	var meta CommittedMetadata
+	if err := json.Unmarshal([]byte(content), &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse session metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+// headLines returns the first n lines of data, including their newlines.
+// Reports false if data has fewer than n complete lines.
+func headLines(data []byte, n int) ([]byte, bool) {
+	end := 0
+	for range n {
+		i := bytes.IndexByte(data[end:], '\n')
+		if i < 0 {
+			return nil, false
+		}
+		end += i + 1
+	}
+	return data[:end], true
+}
+
+// inlineTranscripts rewrites the sessions in entries that are delta-encoded
+// against one of parents as full transcripts, so that the parents can be
+// deleted. root is the tree entries was flattened from; only sessions of
+// checkpoints for which include returns true are rewritten. Returns the
+// number of sessions rewritten.
+func (s *GitStore) inlineTranscripts(root *object.Tree, entries map[string]object.TreeEntry, parents map[id.CheckpointID]bool, include func(id.CheckpointID) bool) (int, error) {
+	type dependent struct {
+		checkpointID id.CheckpointID
+		sessionDir   string
+		sessionIndex int
+		meta         *CommittedMetadata
+	}
+	var dependents []dependent
+	for treePath, entry := range entries {
+		// Session metadata lives at <id[:2]>/<id[2:]>/<index>/metadata.json.
+		parts := strings.Split(treePath, "/")
+		if len(parts) != 4 || parts[3] != paths.MetadataFileName {
+			continue
+		}
+		sessionIndex, err := strconv.Atoi(parts[2])
+		if err != nil {
+			continue
+		}
+		checkpointID, err := id.NewCheckpointID(parts[0] + parts[1])
+		if err != nil || !include(checkpointID) {
+			continue
+		}
+		meta, err := s.readMetadataFromBlob(entry.Hash)
+		if err != nil || !parents[meta.TranscriptParent] {
+			continue
+		}
+		dependents = append(dependents, dependent{checkpointID, strings.Join(parts[:3], "/"), sessionIndex, meta})
+	}
+
+	for _, d := range dependents {
+		sessionTree, err := root.Tree(d.sessionDir)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read session %s: %w", d.sessionDir, err)
+		}
+		transcript, err := s.readSessionTranscript(root, sessionTree, d.meta)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reassemble transcript for checkpoint %s: %w", d.checkpointID, err)
+		}
+
+		sessionPath := d.sessionDir + "/"
+		name, err := s.replaceTranscript(transcript, transcript, d.meta.Agent, sessionPath, entries)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := s.writeNormalizedTranscript(transcript, d.meta.Agent, d.meta.SessionID, "", sessionPath, entries); err != nil {
+			return 0, err
+		}
+		d.meta.TranscriptParent, d.meta.TranscriptParentLines, d.meta.NormalizedParentLines = "", 0, 0
+		if err := s.writeJSONEntry(entries, sessionPath+paths.MetadataFileName, d.meta); err != nil {
+			return 0, err
+		}
+		if err := s.setSessionTranscriptPath(entries, d.checkpointID.Path()+"/", d.sessionIndex, name); err != nil {
+			return 0, err
+		}
+	}
+	return len(dependents), nil
+}
+
+// commitInlinedTranscripts rewrites checkpointID's sessions that are
+// delta-encoded against one of parents as full transcripts, and commits the
+// result to the entire/checkpoints/v1 branch.
+func (s *GitStore) commitInlinedTranscripts(checkpointID id.CheckpointID, parents map[id.CheckpointID]bool) error {
+	ref, entries, err := s.getSessionsBranchEntries()
+	if err != nil {
+		return err
+	}
+	root, err := s.getSessionsBranchTree()
+	if err != nil {
+		return err
+	}
+	n, err := s.inlineTranscripts(root, entries, parents, func(cpID id.CheckpointID) bool {
+		return cpID == checkpointID
+	})
+	if err != nil || n == 0 {
+		return err
+	}
+
+	treeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return err
+	}
+	commitMsg := fmt.Sprintf("Inline transcripts for Checkpoint: %s", checkpointID)
+	commitHash, err := s.createCommit(treeHash, ref.Hash(), commitMsg, "Entire CLI", "cli@entire.io")
+	if err != nil {
+		return err
+	}
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, commitHash)); err != nil {
+		return fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	return nil
+}
+
+// setSessionTranscriptPath records the transcript file name of a session, and
+// the path of its normalized transcript, in the checkpoint's root summary,
+// rewriting it only if a path changed.
+func (s *GitStore) setSessionTranscriptPath(entries map[string]object.TreeEntry, basePath string, sessionIndex int, name string) error {
+	rootMetadataPath := basePath + paths.MetadataFileName
+	entry, ok := entries[rootMetadataPath]
+	if !ok {
+		return ErrCheckpointNotFound
+	}
+	summary, err := s.readSummaryFromBlob(entry.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint summary: %w", err)
+	}
+	if sessionIndex >= len(summary.Sessions) {
+		return errors.New("session index out of range")
+	}
+	sessionPath := basePath + strconv.Itoa(sessionIndex) + "/"
+	transcriptPath := "/" + sessionPath + name
+	normalizedPath := normalizedTranscriptPath(entries, sessionPath)
+	if summary.Sessions[sessionIndex].Transcript == transcriptPath && summary.Sessions[sessionIndex].Normalized == normalizedPath {
+		return nil
+	}
+	summary.Sessions[sessionIndex].Transcript = transcriptPath
+	summary.Sessions[sessionIndex].Normalized = normalizedPath
+	return s.writeJSONEntry(entries, rootMetadataPath, summary)
+}
+
+// writeJSONEntry writes v as indented JSON to treePath in entries.
+func (s *GitStore) writeJSONEntry(entries map[string]object.TreeEntry, treePath string, v any) error {
+	data, err := jsonutil.MarshalIndentWithNewline(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", treePath, err)
+	}
+	hash, err := CreateBlobFromContent(s.repo, data)
+	if err != nil {
+		return err
+	}
+	entries[treePath] = object.TreeEntry{
+		Name: treePath,
+		Mode: filemode.Regular,
+		Hash: hash,
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package checkpoint
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+// transcriptLines returns a JSONL transcript with lines 1 through n.
+func transcriptLines(n int) []byte {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		b.WriteString(`{"type":"user","message":{"content":"line `)
+		b.WriteString(strings.Repeat("x", i))
+		b.WriteString(`"}}` + "\n")
+	}
+	return []byte(b.String())
+}
+
+func writeDeltaCheckpoint(t *testing.T, store Store, cpID, parent id.CheckpointID, transcript []byte) {
+	t.Helper()
+	if err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     cpID,
+		SessionID:        "delta-session",
+		Strategy:         "manual-commit",
+		Agent:            agent.AgentTypeClaudeCode,
+		Transcript:       transcript,
+		TranscriptParent: parent,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted(%s) error = %v", cpID, err)
+	}
+}
+
+// storedTranscript returns the transcript bytes as stored in a session
+// directory, without reassembling delta-encoded transcripts.
+func storedTranscript(t *testing.T, store *GitStore, cpID id.CheckpointID) []byte {
+	t.Helper()
+	root, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("getSessionsBranchTree() error = %v", err)
+	}
+	sessionTree, err := root.Tree(cpID.Path() + "/0")
+	if err != nil {
+		t.Fatalf("session tree not found: %v", err)
+	}
+	transcript, err := readTranscriptFromTree(sessionTree, "")
+	if err != nil {
+		t.Fatalf("readTranscriptFromTree() error = %v", err)
+	}
+	return transcript
+}
+
+// storedNormalized returns the normalized transcript as stored in a session
+// directory, without reassembling delta-encoded normalized transcripts.
+func storedNormalized(t *testing.T, store *GitStore, cpID id.CheckpointID) []byte {
+	t.Helper()
+	root, err := store.getSessionsBranchTree()
+	if err != nil {
+		t.Fatalf("getSessionsBranchTree() error = %v", err)
+	}
+	sessionTree, err := root.Tree(cpID.Path() + "/0")
+	if err != nil {
+		t.Fatalf("session tree not found: %v", err)
+	}
+	normalized, ok, err := store.readStoredNormalized(sessionTree)
+	if err != nil || !ok {
+		t.Fatalf("readStoredNormalized() = %v, %v; want a stored normalized transcript", ok, err)
+	}
+	return normalized
+}
+
+// assertNormalized checks that cpID reads back the normalized form of transcript.
+func assertNormalized(t *testing.T, store Store, cpID id.CheckpointID, transcript []byte) {
+	t.Helper()
+	want, err := agent.NormalizeTranscript(transcript, agent.AgentTypeClaudeCode)
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent(%s) error = %v", cpID, err)
+	}
+	if string(content.NormalizedTranscript) != string(want) {
+		t.Errorf("normalized transcript of %s = %q, want %q", cpID, content.NormalizedTranscript, want)
+	}
+}
+
+func assertTranscript(t *testing.T, store Store, cpID id.CheckpointID, want []byte) *SessionContent {
+	t.Helper()
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent(%s) error = %v", cpID, err)
+	}
+	if string(content.Transcript) != string(want) {
+		t.Errorf("transcript of %s = %q, want %q", cpID, content.Transcript, want)
+	}
+	return content
+}
+
+func TestWriteCommitted_DeltaTranscripts(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	store.SetDeltaTranscripts(true)
+	cpA := id.MustCheckpointID("de1000000001")
+	cpB := id.MustCheckpointID("de1000000002")
+	cpC := id.MustCheckpointID("de1000000003")
+
+	writeDeltaCheckpoint(t, store, cpA, "", transcriptLines(2))
+	writeDeltaCheckpoint(t, store, cpB, cpA, transcriptLines(4))
+	writeDeltaCheckpoint(t, store, cpC, cpB, transcriptLines(6))
+
+	if got, want := string(storedTranscript(t, store, cpB)), strings.TrimPrefix(string(transcriptLines(4)), string(transcriptLines(2))); got != want {
+		t.Errorf("stored transcript of B = %q, want only the appended lines %q", got, want)
+	}
+
+	assertTranscript(t, store, cpA, transcriptLines(2))
+	contentB := assertTranscript(t, store, cpB, transcriptLines(4))
+	if contentB.Metadata.TranscriptParent != cpA || contentB.Metadata.TranscriptParentLines != 2 {
+		t.Errorf("B metadata parent = %s/%d, want %s/2", contentB.Metadata.TranscriptParent, contentB.Metadata.TranscriptParentLines, cpA)
+	}
+	assertTranscript(t, store, cpC, transcriptLines(6))
+
+	transcript, _, err := store.GetSessionLog(cpC)
+	if err != nil || string(transcript) != string(transcriptLines(6)) {
+		t.Errorf("GetSessionLog() = %q, %v; want the reassembled transcript", transcript, err)
+	}
+}
+
+func TestWriteCommitted_DeltaNormalizedTranscripts(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	store.SetDeltaTranscripts(true)
+	cpA := id.MustCheckpointID("de6000000001")
+	cpB := id.MustCheckpointID("de6000000002")
+
+	writeDeltaCheckpoint(t, store, cpA, "", transcriptLines(2))
+	writeDeltaCheckpoint(t, store, cpB, cpA, transcriptLines(4))
+
+	normalizedA, err := agent.NormalizeTranscript(transcriptLines(2), agent.AgentTypeClaudeCode)
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+	normalizedB, err := agent.NormalizeTranscript(transcriptLines(4), agent.AgentTypeClaudeCode)
+	if err != nil {
+		t.Fatalf("NormalizeTranscript() error = %v", err)
+	}
+	if got, want := string(storedNormalized(t, store, cpB)), strings.TrimPrefix(string(normalizedB), string(normalizedA)); got != want {
+		t.Errorf("stored normalized transcript of B = %q, want only the appended entries %q", got, want)
+	}
+
+	summary, err := store.ReadCommitted(context.Background(), cpB)
+	if err != nil {
+		t.Fatalf("ReadCommitted() error = %v", err)
+	}
+	if want := "/" + cpB.Path() + "/0/" + paths.NormalizedFileNameCompressed; summary.Sessions[0].Normalized != want {
+		t.Errorf("Sessions[0].Normalized = %q, want %q", summary.Sessions[0].Normalized, want)
+	}
+
+	assertNormalized(t, store, cpA, transcriptLines(2))
+	assertNormalized(t, store, cpB, transcriptLines(4))
+
+	// Finalizing the parent with a longer transcript leaves B's reads intact.
+	if err := store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+		CheckpointID: cpA,
+		SessionID:    "delta-session",
+		Agent:        agent.AgentTypeClaudeCode,
+		Transcript:   transcriptLines(6),
+	}); err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+	assertNormalized(t, store, cpB, transcriptLines(4))
+}
+
+// TestUpdateCommitted_DeltaParentGrows verifies that finalizing a parent with
+// a longer transcript doesn't change what its delta-encoded children read.
+func TestUpdateCommitted_DeltaParentGrows(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	store.SetDeltaTranscripts(true)
+	cpA := id.MustCheckpointID("de2000000001")
+	cpB := id.MustCheckpointID("de2000000002")
+
+	writeDeltaCheckpoint(t, store, cpA, "", transcriptLines(2))
+	writeDeltaCheckpoint(t, store, cpB, cpA, transcriptLines(4))
+
+	for _, cpID := range []id.CheckpointID{cpA, cpB} {
+		if err := store.UpdateCommitted(context.Background(), UpdateCommittedOptions{
+			CheckpointID: cpID,
+			SessionID:    "delta-session",
+			Transcript:   transcriptLines(6),
+		}); err != nil {
+			t.Fatalf("UpdateCommitted(%s) error = %v", cpID, err)
+		}
+	}
+
+	assertTranscript(t, store, cpA, transcriptLines(6))
+	contentB := assertTranscript(t, store, cpB, transcriptLines(6))
+	if contentB.Metadata.TranscriptParent != cpA || contentB.Metadata.TranscriptParentLines != 6 {
+		t.Errorf("B metadata parent = %s/%d, want %s/6", contentB.Metadata.TranscriptParent, contentB.Metadata.TranscriptParentLines, cpA)
+	}
+}
+
+func TestWriteCommitted_DeltaTranscriptsFallBackToFull(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		enabled    bool
+		transcript []byte
+	}{
+		{"disabled", false, transcriptLines(4)},
+		{"parent is not a prefix", true, []byte(`{"type":"user","message":{"content":"rewritten"}}` + "\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo, _ := setupBranchTestRepo(t)
+			store := NewGitStore(repo)
+			store.SetDeltaTranscripts(tt.enabled)
+			cpA := id.MustCheckpointID("de3000000001")
+			cpB := id.MustCheckpointID("de3000000002")
+
+			writeDeltaCheckpoint(t, store, cpA, "", transcriptLines(2))
+			writeDeltaCheckpoint(t, store, cpB, cpA, tt.transcript)
+
+			if got := storedTranscript(t, store, cpB); string(got) != string(tt.transcript) {
+				t.Errorf("stored transcript = %q, want the full transcript", got)
+			}
+			content := assertTranscript(t, store, cpB, tt.transcript)
+			if !content.Metadata.TranscriptParent.IsEmpty() {
+				t.Errorf("TranscriptParent = %s, want none", content.Metadata.TranscriptParent)
+			}
+		})
+	}
+}
+
+func TestDeleteCommitted_InlinesDeltaTranscripts(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	store.SetDeltaTranscripts(true)
+	cpA := id.MustCheckpointID("de4000000001")
+	cpB := id.MustCheckpointID("de4000000002")
+	cpC := id.MustCheckpointID("de4000000003")
+
+	writeDeltaCheckpoint(t, store, cpA, "", transcriptLines(2))
+	writeDeltaCheckpoint(t, store, cpB, cpA, transcriptLines(4))
+	writeDeltaCheckpoint(t, store, cpC, cpB, transcriptLines(6))
+
+	if err := store.DeleteCommitted(context.Background(), []id.CheckpointID{cpA}); err != nil {
+		t.Fatalf("DeleteCommitted() error = %v", err)
+	}
+
+	contentB := assertTranscript(t, store, cpB, transcriptLines(4))
+	if !contentB.Metadata.TranscriptParent.IsEmpty() || contentB.Metadata.NormalizedParentLines != 0 {
+		t.Errorf("B parent = %s/%d, want none after its parent was deleted", contentB.Metadata.TranscriptParent, contentB.Metadata.NormalizedParentLines)
+	}
+	assertNormalized(t, store, cpB, transcriptLines(4))
+	if got := storedTranscript(t, store, cpB); string(got) != string(transcriptLines(4)) {
+		t.Errorf("stored transcript of B = %q, want the full transcript", got)
+	}
+	// C is still delta-encoded against B.
+	contentC := assertTranscript(t, store, cpC, transcriptLines(6))
+	if contentC.Metadata.TranscriptParent != cpB {
+		t.Errorf("C TranscriptParent = %s, want %s", contentC.Metadata.TranscriptParent, cpB)
+	}
+	assertNormalized(t, store, cpC, transcriptLines(6))
+}
+
+func TestFilesystemStore_DeltaTranscripts(t *testing.T) {
+	t.Parallel()
+	store, _ := newTestFilesystemStore(t)
+	store.SetDeltaTranscripts(true)
+	cpA := id.MustCheckpointID("de5000000001")
+	cpB := id.MustCheckpointID("de5000000002")
+	cpC := id.MustCheckpointID("de5000000003")
+
+	writeDeltaCheckpoint(t, store, cpA, "", transcriptLines(2))
+	writeDeltaCheckpoint(t, store, cpB, cpA, transcriptLines(4))
+	writeDeltaCheckpoint(t, store, cpC, cpB, transcriptLines(6))
+
+	contentC := assertTranscript(t, store, cpC, transcriptLines(6))
+	if contentC.Metadata.TranscriptParent != cpB {
+		t.Errorf("C TranscriptParent = %s, want %s", contentC.Metadata.TranscriptParent, cpB)
+	}
+
+	if err := store.DeleteCommitted(context.Background(), []id.CheckpointID{cpA, cpB}); err != nil {
+		t.Fatalf("DeleteCommitted() error = %v", err)
+	}
+	contentC = assertTranscript(t, store, cpC, transcriptLines(6))
+	if !contentC.Metadata.TranscriptParent.IsEmpty() {
+		t.Errorf("C TranscriptParent = %s, want none after its parents were deleted", contentC.Metadata.TranscriptParent)
+	}
+}
+
+func TestHeadLines(t *testing.T) {
+	t.Parallel()
+	data := []byte("a\nb\nc")
+	tests := []struct {
+		n    int
+		want string
+		ok   bool
+	}{
+		{0, "", true},
+		{1, "a\n", true},
+		{2, "a\nb\n", true},
+		{3, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := headLines(data, tt.n)
+		if ok != tt.ok || string(got) != tt.want {
+			t.Errorf("headLines(%d) = %q, %v; want %q, %v", tt.n, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package checkpoint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"filippo.io/age"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// EncryptedFileSuffix is appended to the names of encrypted checkpoint files,
+// e.g. prompt.txt.age or full.jsonl.zst.age.
+const EncryptedFileSuffix = ".age"
+
+// ErrEncryptedContent is returned when checkpoint content is encrypted and
+// can't be decrypted, e.g. because no identity file is configured.
+var ErrEncryptedContent = errors.New("checkpoint content is encrypted")
+
+// Encryption configures age encryption of committed checkpoint content:
+// transcripts, prompts, context and any copied session files other than JSON
+// metadata. Checkpoint and session metadata.json stay readable so that listing
+// and attribution work without a key.
+type Encryption struct {
+	// Recipients are the public keys new content is encrypted to.
+	// No recipients means new content is written in plain text.
+	Recipients []age.Recipient
+
+	// Identities loads the private keys used to decrypt. It is only called
+	// when encrypted content is read, so writers don't need an identity.
+	Identities func() ([]age.Identity, error)
+}
+
+// encrypts reports whether new content is encrypted.
+func (e *Encryption) encrypts() bool {
+	return e != nil && len(e.Recipients) > 0
+}
+
+// encrypt encrypts data to all recipients.
+func (e *Encryption) encrypt(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, e.Recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt checkpoint content: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to encrypt checkpoint content: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt checkpoint content: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decrypt decrypts data with the configured identities. Errors wrap
+// ErrEncryptedContent.
+func (e *Encryption) decrypt(data []byte) ([]byte, error) {
+	if e == nil || e.Identities == nil {
+		return nil, fmt.Errorf("%w and no identity is configured to decrypt it", ErrEncryptedContent)
+	}
+	identities, err := e.Identities()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncryptedContent, err)
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncryptedContent, err)
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrEncryptedContent, err)
+	}
+	return plain, nil
+}
+
+// shouldEncryptFile reports whether a file copied into a checkpoint is
+// encrypted. JSON files hold metadata and stay readable.
+func shouldEncryptFile(treePath string) bool {
+	return !strings.HasSuffix(treePath, ".json")
+}
+
+// SetEncryption configures encryption of committed checkpoint content.
+// nil writes plain text and can't read encrypted checkpoints.
+func (s *GitStore) SetEncryption(enc *Encryption) {
+	s.encryption = enc
+}
+
+// writeContentFile writes a content file such as prompt.txt to treePath, or
+// encrypted to treePath.age when encryption is enabled, removing the other
+// variant. Returns the path written.
+func (s *GitStore) writeContentFile(entries map[string]object.TreeEntry, treePath string, data []byte) (string, error) {
+	delete(entries, treePath)
+	delete(entries, treePath+EncryptedFileSuffix)
+	if s.encryption.encrypts() {
+		encrypted, err := s.encryption.encrypt(data)
+		if err != nil {
+			return "", err
+		}
+		data = encrypted
+		treePath += EncryptedFileSuffix
+	}
+	blobHash, err := CreateBlobFromContent(s.repo, data)
+	if err != nil {
+		return "", err
+	}
+	entries[treePath] = object.TreeEntry{
+		Name: treePath,
+		Mode: filemode.Regular,
+		Hash: blobHash,
+	}
+	return treePath, nil
+}
+
+// readContentFile reads a content file such as prompt.txt from tree,
+// decrypting name.age if that is what's stored. Reports false if neither exists.
+func (s *GitStore) readContentFile(tree *object.Tree, name string) ([]byte, bool, error) {
+	if file, err := tree.File(name + EncryptedFileSuffix); err == nil {
+		content, err := file.Contents()
+		if err != nil {
+			return nil, true, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		plain, err := s.encryption.decrypt([]byte(content))
+		return plain, true, err
+	}
+	file, err := tree.File(name)
+	if err != nil {
+		return nil, false, nil
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return []byte(content), true, nil
+}
+
+// readStoredTranscript reads the transcript stored in a session directory,
+// decrypting and decompressing it as needed. A delta-encoded transcript is
+// returned as stored; see readSessionTranscript.
+func (s *GitStore) readStoredTranscript(sessionTree *object.Tree, agentType agent.AgentType) ([]byte, error) {
+	for _, name := range []string{paths.TranscriptFileNameCompressed, paths.TranscriptFileName} {
+		encrypted, ok, err := readConcatenatedChunks(sessionTree, name+EncryptedFileSuffix)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		transcript, err := s.encryption.decrypt(encrypted)
+		if err != nil {
+			return nil, err
+		}
+		if name == paths.TranscriptFileNameCompressed {
+			return decompressTranscript(transcript)
+		}
+		return transcript, nil
+	}
+	return readTranscriptFromTree(sessionTree, agentType)
+}
+
+// readStoredNormalized reads the normalized transcript stored in a session
+// directory, decrypting and decompressing it as needed. Reports false if the
+// session has none. A delta-encoded normalized transcript is returned as
+// stored; see readSessionNormalized.
+func (s *GitStore) readStoredNormalized(sessionTree *object.Tree) ([]byte, bool, error) {
+	for _, name := range normalizedBaseNames {
+		if name == paths.NormalizedFileName {
+			// Plain normalized transcripts are chunked at JSONL line
+			// boundaries rather than split at byte boundaries.
+			normalized, err := readNormalizedTranscriptFromTree(sessionTree)
+			return normalized, normalized != nil || err != nil, err
+		}
+		data, ok, err := readConcatenatedChunks(sessionTree, name)
+		if err != nil {
+			return nil, true, err
+		}
+		if !ok {
+			continue
+		}
+		if strings.HasSuffix(name, EncryptedFileSuffix) {
+			if data, err = s.encryption.decrypt(data); err != nil {
+				return nil, true, err
+			}
+		}
+		if strings.HasPrefix(name, paths.NormalizedFileNameCompressed) {
+			data, err = decompressTranscript(data)
+		}
+		return data, true, err
+	}
+	return nil, false, nil
+}
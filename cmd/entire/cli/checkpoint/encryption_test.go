@@ -0,0 +1,160 @@
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"filippo.io/age"
+)
+
+// testEncryption returns an Encryption for a fresh key pair.
+func testEncryption(t *testing.T) *Encryption {
+	t.Helper()
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+	return &Encryption{
+		Recipients: []age.Recipient{identity.Recipient()},
+		Identities: func() ([]age.Identity, error) { return []age.Identity{identity}, nil },
+	}
+}
+
+func writeEncryptedCheckpoint(t *testing.T, store Store, cpID id.CheckpointID) {
+	t.Helper()
+	if err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID:     cpID,
+		SessionID:        "encrypted-session",
+		Strategy:         "manual-commit",
+		Transcript:       compressionTestTranscript,
+		Prompts:          []string{"refactor the parser"},
+		Context:          []byte("# Session context"),
+		FilesTouched:     []string{"parser.go"},
+		CheckpointsCount: 1,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+}
+
+func TestWriteCommitted_Encryption(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	store.SetEncryption(testEncryption(t))
+	cpID := id.MustCheckpointID("e0c000000001")
+
+	writeEncryptedCheckpoint(t, store, cpID)
+
+	names := sessionTreeFileNames(t, store, cpID, 0)
+	for _, want := range []string{
+		paths.TranscriptFileNameCompressed + EncryptedFileSuffix,
+		paths.PromptFileName + EncryptedFileSuffix,
+		paths.ContextFileName + EncryptedFileSuffix,
+		paths.MetadataFileName,
+		paths.ContentHashFileName,
+	} {
+		if !containsName(names, want) {
+			t.Errorf("session files = %v, want %s", names, want)
+		}
+	}
+	for _, plain := range []string{paths.TranscriptFileNameCompressed, paths.PromptFileName, paths.ContextFileName} {
+		if containsName(names, plain) {
+			t.Errorf("session files = %v, want no plain %s", names, plain)
+		}
+	}
+
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if string(content.Transcript) != string(compressionTestTranscript) {
+		t.Errorf("Transcript = %d bytes, want the decrypted transcript", len(content.Transcript))
+	}
+	if content.Prompts != "refactor the parser" || content.Context != "# Session context" {
+		t.Errorf("Prompts, Context = %q, %q; want the decrypted content", content.Prompts, content.Context)
+	}
+
+	prompt, err := store.ReadCommittedFile(context.Background(), cpID.Path()+"/0/"+paths.PromptFileName)
+	if err != nil || string(prompt) != "refactor the parser" {
+		t.Errorf("ReadCommittedFile(prompt.txt) = %q, %v; want the decrypted prompt", prompt, err)
+	}
+}
+
+// TestReadSessionContent_EncryptedWithoutKey verifies that metadata stays
+// readable without a key while content reads fail with ErrEncryptedContent.
+func TestReadSessionContent_EncryptedWithoutKey(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	writer := NewGitStore(repo)
+	writer.SetEncryption(testEncryption(t))
+	cpID := id.MustCheckpointID("e0c000000002")
+	writeEncryptedCheckpoint(t, writer, cpID)
+
+	tests := []struct {
+		name       string
+		encryption *Encryption
+	}{
+		{"no encryption configured", nil},
+		{"no identity", &Encryption{Identities: func() ([]age.Identity, error) {
+			return nil, errors.New("no identity file configured")
+		}}},
+		{"wrong identity", testEncryption(t)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reader := NewGitStore(repo)
+			reader.SetEncryption(tt.encryption)
+
+			summary, err := reader.ReadCommitted(context.Background(), cpID)
+			if err != nil || summary == nil || len(summary.FilesTouched) != 1 {
+				t.Errorf("ReadCommitted() = %+v, %v; want readable metadata", summary, err)
+			}
+			infos, err := reader.ListCommitted(context.Background())
+			if err != nil || len(infos) != 1 || infos[0].SessionID != "encrypted-session" {
+				t.Errorf("ListCommitted() = %+v, %v; want the checkpoint listed", infos, err)
+			}
+
+			_, err = reader.ReadSessionContent(context.Background(), cpID, 0)
+			if !errors.Is(err, ErrEncryptedContent) {
+				t.Errorf("ReadSessionContent() error = %v, want ErrEncryptedContent", err)
+			}
+			if tt.name == "no identity" && !strings.Contains(err.Error(), "no identity file configured") {
+				t.Errorf("ReadSessionContent() error = %v, want the identity error", err)
+			}
+		})
+	}
+}
+
+func TestFilesystemStore_Encryption(t *testing.T) {
+	t.Parallel()
+	store, _ := newTestFilesystemStore(t)
+	enc := testEncryption(t)
+	store.SetEncryption(enc)
+	cpID := id.MustCheckpointID("e0c000000003")
+
+	writeEncryptedCheckpoint(t, store, cpID)
+
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if string(content.Transcript) != string(compressionTestTranscript) || content.Prompts != "refactor the parser" {
+		t.Errorf("content = %d bytes / %q, want the decrypted transcript and prompt", len(content.Transcript), content.Prompts)
+	}
+	if _, err := store.ReadCommittedFile(context.Background(), cpID.Path()+"/0/"+paths.ContextFileName+EncryptedFileSuffix); err != nil {
+		t.Errorf("encrypted context file not stored: %v", err)
+	}
+
+	store.SetEncryption(nil)
+	if _, err := store.ReadSessionContent(context.Background(), cpID, 0); !errors.Is(err, ErrEncryptedContent) {
+		t.Errorf("ReadSessionContent() without a key error = %v, want ErrEncryptedContent", err)
+	}
+}
@@ -0,0 +1,643 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// Compile-time check that FilesystemStore implements the Store interface.
+var _ Store = (*FilesystemStore)(nil)
+
+// FilesystemStore keeps committed checkpoints as plain files in a directory,
+// using the same sharded layout as the entire/checkpoints/v1 branch. This is
+// for repositories where pushing an extra branch isn't possible; the directory
+// can be anywhere, e.g. on a shared network mount.
+//
+// Temporary checkpoints still live on shadow branches in the repository.
+//
+// Each committed operation mirrors the checkpoint directories it touches into
+// an in-memory git repository and runs the GitStore implementation against
+// it, then writes any changed files back. Both backends therefore share one
+// implementation of the checkpoint format.
+type FilesystemStore struct {
+	TemporaryStore
+
+	dir              string
+	compressionLevel int
+	deltaTranscripts bool
+	encryption       *Encryption
+}
+
+// NewFilesystemStore creates a checkpoint store that keeps temporary
+// checkpoints in repo and committed checkpoints under dir.
+func NewFilesystemStore(repo *git.Repository, dir string) *FilesystemStore {
+	return &FilesystemStore{TemporaryStore: NewGitStore(repo), dir: dir, compressionLevel: DefaultCompressionLevel}
+}
+
+// SetCompressionLevel sets the zstd level committed transcripts are written
+// with; see GitStore.SetCompressionLevel.
+func (s *FilesystemStore) SetCompressionLevel(level int) {
+	s.compressionLevel = level
+}
+
+// SetDeltaTranscripts enables delta-encoded transcripts; see GitStore.SetDeltaTranscripts.
+func (s *FilesystemStore) SetDeltaTranscripts(enabled bool) {
+	s.deltaTranscripts = enabled
+}
+
+// SetEncryption configures encryption of committed checkpoint content; see GitStore.SetEncryption.
+func (s *FilesystemStore) SetEncryption(enc *Encryption) {
+	s.encryption = enc
+}
+
+// Dir returns the directory committed checkpoints are stored in.
+func (s *FilesystemStore) Dir() string {
+	return s.dir
+}
+
+// Location returns the directory committed checkpoints are stored in.
+func (s *FilesystemStore) Location() string {
+	return s.dir
+}
+
+// WriteCommitted writes a committed checkpoint to <dir>/<id[:2]>/<id[2:]>/.
+func (s *FilesystemStore) WriteCommitted(ctx context.Context, opts WriteCommittedOptions) error {
+	return s.update(opts.CheckpointID, opts.TranscriptParent, func(m *GitStore) error {
+		return m.WriteCommitted(ctx, opts)
+	})
+}
+
+// ReadCommitted reads a committed checkpoint's summary by ID.
+// Returns nil, nil if the checkpoint does not exist.
+func (s *FilesystemStore) ReadCommitted(ctx context.Context, checkpointID id.CheckpointID) (*CheckpointSummary, error) {
+	var summary *CheckpointSummary
+	err := s.view(checkpointID, func(m *GitStore) error {
+		var err error
+		summary, err = m.ReadCommitted(ctx, checkpointID)
+		return err
+	})
+	return summary, err
+}
+
+// ReadSessionContent reads the content for a session within a checkpoint by index.
+func (s *FilesystemStore) ReadSessionContent(ctx context.Context, checkpointID id.CheckpointID, sessionIndex int) (*SessionContent, error) {
+	var content *SessionContent
+	err := s.view(checkpointID, func(m *GitStore) error {
+		var err error
+		content, err = m.ReadSessionContent(ctx, checkpointID, sessionIndex)
+		return err
+	})
+	return content, err
+}
+
+// ReadSessionContentByID reads the content for a session within a checkpoint by session ID.
+func (s *FilesystemStore) ReadSessionContentByID(ctx context.Context, checkpointID id.CheckpointID, sessionID string) (*SessionContent, error) {
+	var content *SessionContent
+	err := s.view(checkpointID, func(m *GitStore) error {
+		var err error
+		content, err = m.ReadSessionContentByID(ctx, checkpointID, sessionID)
+		return err
+	})
+	return content, err
+}
+
+// ReadLatestSessionContent reads the content of the checkpoint's most recent session.
+func (s *FilesystemStore) ReadLatestSessionContent(ctx context.Context, checkpointID id.CheckpointID) (*SessionContent, error) {
+	var content *SessionContent
+	err := s.view(checkpointID, func(m *GitStore) error {
+		var err error
+		content, err = m.ReadLatestSessionContent(ctx, checkpointID)
+		return err
+	})
+	return content, err
+}
+
+// ReadCommittedFile reads a file by its path relative to the store directory.
+func (s *FilesystemStore) ReadCommittedFile(_ context.Context, treePath string) ([]byte, error) {
+	rel := filepath.FromSlash(treePath)
+	if !filepath.IsLocal(rel) {
+		return nil, fmt.Errorf("invalid checkpoint file path: %s", treePath)
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, rel)) //nolint:gosec // path is validated to stay inside the store directory
+	if err == nil {
+		return data, nil
+	}
+	// Encrypted content is stored under treePath.age.
+	encrypted, encErr := os.ReadFile(filepath.Join(s.dir, rel+EncryptedFileSuffix)) //nolint:gosec // path is validated to stay inside the store directory
+	if encErr != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", treePath, err)
+	}
+	return s.encryption.decrypt(encrypted)
+}
+
+// GetSessionLog reads the latest session's transcript and session ID.
+func (s *FilesystemStore) GetSessionLog(cpID id.CheckpointID) ([]byte, string, error) {
+	var transcript []byte
+	var sessionID string
+	err := s.view(cpID, func(m *GitStore) error {
+		var err error
+		transcript, sessionID, err = m.GetSessionLog(cpID)
+		return err
+	})
+	return transcript, sessionID, err
+}
+
+// GetCheckpointAuthor returns an empty Author: plain directories keep no
+// record of who wrote a checkpoint.
+//
+//nolint:unparam // error return is required by interface, kept for consistency
+func (s *FilesystemStore) GetCheckpointAuthor(_ context.Context, _ id.CheckpointID) (Author, error) {
+	return Author{}, nil
+}
+
+// ListCommitted lists all committed checkpoints in the store directory.
+func (s *FilesystemStore) ListCommitted(ctx context.Context) ([]CommittedInfo, error) {
+	// Listing only needs the checkpoint and session metadata files.
+	m, _, err := s.mirror(func(treePath string) bool {
+		return path.Base(treePath) == paths.MetadataFileName
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+	return m.ListCommitted(ctx)
+}
+
+// UpdateCommitted replaces the transcript, prompts, and context for an existing checkpoint.
+func (s *FilesystemStore) UpdateCommitted(ctx context.Context, opts UpdateCommittedOptions) error {
+	return s.update(opts.CheckpointID, "", func(m *GitStore) error {
+		return m.UpdateCommitted(ctx, opts)
+	})
+}
+
+// UpdateSummary sets the AI summary on the checkpoint's latest session.
+func (s *FilesystemStore) UpdateSummary(ctx context.Context, checkpointID id.CheckpointID, summary *Summary) error {
+	return s.update(checkpointID, "", func(m *GitStore) error {
+		return m.UpdateSummary(ctx, checkpointID, summary)
+	})
+}
+
+// DeleteCommitted removes checkpoint directories. IDs that don't exist are ignored.
+func (s *FilesystemStore) DeleteCommitted(_ context.Context, checkpointIDs []id.CheckpointID) error {
+	deleted := checkpointIDSet(checkpointIDs)
+	if err := s.inlineDependents(deleted); err != nil {
+		return err
+	}
+	return s.removeCheckpointDirs(deleted)
+}
+
+// CommittedSizes returns the size of the files of each checkpoint in the
+// store directory.
+func (s *FilesystemStore) CommittedSizes(_ context.Context) (map[id.CheckpointID]CheckpointSize, error) {
+	sizes := make(map[id.CheckpointID]CheckpointSize)
+	err := filepath.WalkDir(s.dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, filePath)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path for %s: %w", filePath, err)
+		}
+		// Checkpoint files live at <id[:2]>/<id[2:]>/<path>.
+		parts := strings.SplitN(filepath.ToSlash(rel), "/", 3)
+		if len(parts) != 3 || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+		cpID, err := id.NewCheckpointID(parts[0] + parts[1])
+		if err != nil {
+			return nil //nolint:nilerr // Not a checkpoint directory
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", filePath, err)
+		}
+		size := sizes[cpID]
+		size.Total += info.Size()
+		if isTranscriptPath(parts[2]) {
+			size.Transcripts += info.Size()
+		}
+		sizes[cpID] = size
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to size checkpoints in %s: %w", s.dir, err)
+	}
+	return sizes, nil
+}
+
+// PruneCommitted deletes checkpoint directories or removes the transcripts
+// from them. A directory keeps no history, so nothing needs rewriting.
+func (s *FilesystemStore) PruneCommitted(ctx context.Context, opts PruneOptions) error {
+	deleted := checkpointIDSet(opts.Delete)
+	dropped := checkpointIDSet(opts.DropTranscripts)
+	pruned := make(map[id.CheckpointID]bool, len(deleted)+len(dropped))
+	for cpID := range dropped {
+		if deleted[cpID] {
+			delete(dropped, cpID)
+			continue
+		}
+		pruned[cpID] = true
+	}
+	for cpID := range deleted {
+		pruned[cpID] = true
+	}
+	if err := s.inlineDependents(pruned); err != nil {
+		return err
+	}
+
+	for cpID := range dropped {
+		if _, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(cpID.Path()))); err != nil {
+			continue
+		}
+		err := s.update(cpID, "", func(m *GitStore) error {
+			return m.PruneCommitted(ctx, PruneOptions{DropTranscripts: []id.CheckpointID{cpID}})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to drop transcripts of checkpoint %s: %w", cpID, err)
+		}
+	}
+	if err := s.recordPruned(deleted); err != nil {
+		return err
+	}
+	return s.removeCheckpointDirs(deleted)
+}
+
+// recordPruned adds the checkpoints of checkpointIDs that exist in the store
+// directory to its pruned directory; see GitStore.ListPruned.
+func (s *FilesystemStore) recordPruned(checkpointIDs map[id.CheckpointID]bool) error {
+	for cpID := range checkpointIDs {
+		if _, err := os.Stat(filepath.Join(s.dir, filepath.FromSlash(cpID.Path()))); err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(s.dir, prunedDir), 0o750); err != nil {
+			return fmt.Errorf("failed to create pruned directory: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(s.dir, prunedDir, cpID.String()), nil, 0o600); err != nil {
+			return fmt.Errorf("failed to record pruned checkpoint %s: %w", cpID, err)
+		}
+	}
+	return nil
+}
+
+// ListPruned returns the checkpoints PruneCommitted deleted, sorted.
+func (s *FilesystemStore) ListPruned(_ context.Context) ([]id.CheckpointID, error) {
+	dirEntries, err := os.ReadDir(filepath.Join(s.dir, prunedDir))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list pruned checkpoints: %w", err)
+	}
+	var pruned []id.CheckpointID
+	for _, entry := range dirEntries {
+		if cpID, err := id.NewCheckpointID(entry.Name()); err == nil {
+			pruned = append(pruned, cpID)
+		}
+	}
+	return pruned, nil
+}
+
+// VerifyCommitted checks the integrity of all checkpoints in the store
+// directory. It loads the whole directory into a mirror.
+func (s *FilesystemStore) VerifyCommitted(ctx context.Context) (*VerifyResult, error) {
+	m, _, err := s.mirror(nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return m.VerifyCommitted(ctx)
+}
+
+// inlineDependents rewrites the sessions delta-encoded against one of
+// parents with their full transcripts, so that the parents can be deleted.
+func (s *FilesystemStore) inlineDependents(parents map[id.CheckpointID]bool) error {
+	dependents, err := s.transcriptDependents(parents)
+	if err != nil {
+		return err
+	}
+	for _, dep := range dependents {
+		err := s.update(dep, "", func(m *GitStore) error {
+			return m.commitInlinedTranscripts(dep, parents)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to inline transcripts of checkpoint %s: %w", dep, err)
+		}
+	}
+	return nil
+}
+
+// removeCheckpointDirs removes the directories of the given checkpoints.
+func (s *FilesystemStore) removeCheckpointDirs(checkpointIDs map[id.CheckpointID]bool) error {
+	for cpID := range checkpointIDs {
+		cpDir := filepath.Join(s.dir, filepath.FromSlash(cpID.Path()))
+		if err := os.RemoveAll(cpDir); err != nil {
+			return fmt.Errorf("failed to remove checkpoint %s: %w", cpID, err)
+		}
+		// Drop the shard directory once its last checkpoint is gone.
+		_ = os.Remove(filepath.Dir(cpDir)) //nolint:errcheck // fails harmlessly while other checkpoints share the shard
+	}
+	return nil
+}
+
+// view runs fn against a mirror of one checkpoint's directory, plus the
+// checkpoints its delta-encoded transcripts are built on.
+func (s *FilesystemStore) view(checkpointID id.CheckpointID, fn func(m *GitStore) error) error {
+	m, _, err := s.mirror(nil, s.checkpointPrefixes(checkpointID, "")...)
+	if err != nil {
+		return err
+	}
+	return fn(m)
+}
+
+// update runs fn against a mirror of one checkpoint's directory and writes
+// the files fn changed back to the store directory. The transcripts of
+// parent and of the checkpoints delta-encoded transcripts are built on are
+// loaded too, but never written.
+func (s *FilesystemStore) update(checkpointID, parent id.CheckpointID, fn func(m *GitStore) error) error {
+	prefixes := s.checkpointPrefixes(checkpointID, parent)
+	m, before, err := s.mirror(nil, prefixes...)
+	if err != nil {
+		return err
+	}
+	// An empty ID loads nothing; fn reports the invalid options.
+	if err := fn(m); err != nil || len(prefixes) == 0 {
+		return err
+	}
+	return s.sync(m, before, prefixes[0])
+}
+
+// checkpointPrefixes returns the tree path prefix for a checkpoint, or none
+// for an empty ID, followed by the prefixes of parent and of the transcript
+// parents of both.
+func (s *FilesystemStore) checkpointPrefixes(checkpointID, parent id.CheckpointID) []string {
+	if checkpointID.IsEmpty() {
+		return nil
+	}
+	seen := map[id.CheckpointID]bool{checkpointID: true}
+	prefixes := []string{checkpointID.Path()}
+	queue := []id.CheckpointID{checkpointID}
+	if !parent.IsEmpty() && !seen[parent] {
+		seen[parent] = true
+		prefixes = append(prefixes, parent.Path())
+		queue = append(queue, parent)
+	}
+	for len(queue) > 0 && len(prefixes) <= maxTranscriptParentDepth {
+		cpID := queue[0]
+		queue = queue[1:]
+		for _, meta := range s.readSessionMetadata(cpID) {
+			if p := meta.TranscriptParent; !p.IsEmpty() && !seen[p] {
+				seen[p] = true
+				prefixes = append(prefixes, p.Path())
+				queue = append(queue, p)
+			}
+		}
+	}
+	return prefixes
+}
+
+// readSessionMetadata reads the session metadata files of a checkpoint
+// directly from the store directory, skipping unreadable ones.
+func (s *FilesystemStore) readSessionMetadata(checkpointID id.CheckpointID) []CommittedMetadata {
+	cpDir := filepath.Join(s.dir, filepath.FromSlash(checkpointID.Path()))
+	dirEntries, err := os.ReadDir(cpDir)
+	if err != nil {
+		return nil
+	}
+	var metas []CommittedMetadata
+	for _, entry := range dirEntries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(cpDir, entry.Name(), paths.MetadataFileName)) //nolint:gosec // path is built from the store directory and a checkpoint ID
+		if err != nil {
+			continue
+		}
+		var meta CommittedMetadata
+		if json.Unmarshal(data, &meta) == nil {
+			metas = append(metas, meta)
+		}
+	}
+	return metas
+}
+
+// transcriptDependents returns the checkpoints not in deleted that have a
+// session delta-encoded against a checkpoint in deleted.
+func (s *FilesystemStore) transcriptDependents(deleted map[id.CheckpointID]bool) ([]id.CheckpointID, error) {
+	shards, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint store: %w", err)
+	}
+	var dependents []id.CheckpointID
+	for _, shard := range shards {
+		if !shard.IsDir() || len(shard.Name()) != 2 {
+			continue
+		}
+		checkpoints, err := os.ReadDir(filepath.Join(s.dir, shard.Name()))
+		if err != nil {
+			continue
+		}
+		for _, cp := range checkpoints {
+			cpID, err := id.NewCheckpointID(shard.Name() + cp.Name())
+			if err != nil || deleted[cpID] {
+				continue
+			}
+			for _, meta := range s.readSessionMetadata(cpID) {
+				if deleted[meta.TranscriptParent] {
+					dependents = append(dependents, cpID)
+					break
+				}
+			}
+		}
+	}
+	return dependents, nil
+}
+
+// mirror loads the files under the given tree path prefixes ("" for all)
+// into an in-memory repository, committed as the tip of its
+// entire/checkpoints/v1 branch. keep, if set, filters the files loaded.
+// Returns the mirror and the tree entries it was created with.
+func (s *FilesystemStore) mirror(keep func(treePath string) bool, prefixes ...string) (*GitStore, map[string]object.TreeEntry, error) {
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create checkpoint mirror: %w", err)
+	}
+	m := NewGitStore(repo)
+	m.SetCompressionLevel(s.compressionLevel)
+	m.SetDeltaTranscripts(s.deltaTranscripts)
+	m.SetEncryption(s.encryption)
+
+	entries := make(map[string]object.TreeEntry)
+	for _, prefix := range prefixes {
+		root := filepath.Join(s.dir, filepath.FromSlash(prefix))
+		err := filepath.WalkDir(root, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			// Skip temporary files from interrupted writes.
+			if strings.HasPrefix(d.Name(), ".") && filePath != root {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(s.dir, filePath)
+			if err != nil {
+				return fmt.Errorf("failed to get relative path for %s: %w", filePath, err)
+			}
+			treePath := filepath.ToSlash(rel)
+			if keep != nil && !keep(treePath) {
+				return nil
+			}
+
+			content, err := os.ReadFile(filePath) //nolint:gosec // filePath comes from walking the store directory
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", filePath, err)
+			}
+			hash, err := CreateBlobFromContent(repo, content)
+			if err != nil {
+				return err
+			}
+			mode := filemode.Regular
+			if info, infoErr := d.Info(); infoErr == nil && info.Mode()&0o111 != 0 {
+				mode = filemode.Executable
+			}
+			entries[treePath] = object.TreeEntry{Name: treePath, Mode: mode, Hash: hash}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load checkpoints from %s: %w", s.dir, err)
+		}
+	}
+
+	// An empty mirror has no branch, which reads as "no checkpoints".
+	if len(entries) == 0 {
+		return m, entries, nil
+	}
+
+	treeHash, err := BuildTreeFromEntries(repo, entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	commitHash, err := m.createCommit(treeHash, plumbing.ZeroHash, "Mirror checkpoints", "Entire CLI", "cli@entire.io")
+	if err != nil {
+		return nil, nil, err
+	}
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(refName, commitHash)); err != nil {
+		return nil, nil, fmt.Errorf("failed to set mirror branch reference: %w", err)
+	}
+	return m, entries, nil
+}
+
+// sync writes the files under prefix that differ between the mirror's branch
+// tip and before to the store directory, and removes files that are gone.
+func (s *FilesystemStore) sync(m *GitStore, before map[string]object.TreeEntry, prefix string) error {
+	tree, err := m.getSessionsBranchTree()
+	if err != nil {
+		return nil //nolint:nilerr // The mirror was never committed to, so nothing changed
+	}
+	after := make(map[string]object.TreeEntry)
+	if err := FlattenTree(m.repo, tree, "", after); err != nil {
+		return err
+	}
+
+	inPrefix := func(treePath string) bool {
+		return strings.HasPrefix(treePath, prefix+"/")
+	}
+	for treePath, entry := range after {
+		if !inPrefix(treePath) {
+			continue
+		}
+		if old, ok := before[treePath]; ok && old.Hash == entry.Hash && old.Mode == entry.Mode {
+			continue
+		}
+		if err := s.writeBlob(m.repo, treePath, entry); err != nil {
+			return err
+		}
+	}
+	for treePath := range before {
+		if _, ok := after[treePath]; ok || !inPrefix(treePath) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(treePath))); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", treePath, err)
+		}
+	}
+	return nil
+}
+
+// writeBlob atomically writes a blob from the mirror to its path in the store directory.
+func (s *FilesystemStore) writeBlob(repo *git.Repository, treePath string, entry object.TreeEntry) error {
+	blob, err := repo.BlobObject(entry.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to read %s from mirror: %w", treePath, err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to read %s from mirror: %w", treePath, err)
+	}
+	defer reader.Close()
+
+	target := filepath.Join(s.dir, filepath.FromSlash(treePath))
+	//nolint:gosec // G301: the store directory may be shared between users
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", treePath, err)
+	}
+
+	// Write to a temporary file and rename, so readers never see a partial file.
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", treePath, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, reader); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", treePath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", treePath, err)
+	}
+
+	var perm os.FileMode = 0o644
+	if entry.Mode == filemode.Executable {
+		perm = 0o755
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", treePath, err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to write %s: %w", treePath, err)
+	}
+	return nil
+}
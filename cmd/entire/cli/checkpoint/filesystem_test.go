@@ -0,0 +1,192 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func newTestFilesystemStore(t *testing.T) (*FilesystemStore, *git.Repository) {
+	t.Helper()
+	repo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	return NewFilesystemStore(repo, filepath.Join(t.TempDir(), "checkpoints")), repo
+}
+
+func TestFilesystemStore_WriteAndReadCommitted(t *testing.T) {
+	t.Parallel()
+	store, repo := newTestFilesystemStore(t)
+	ctx := context.Background()
+	cpID := id.MustCheckpointID("a1b2c3d4e5f6")
+
+	for _, sessionID := range []string{"session-1", "session-2"} {
+		if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+			CheckpointID:     cpID,
+			SessionID:        sessionID,
+			Strategy:         "manual-commit",
+			Transcript:       []byte(`{"type":"user","message":"hello from ` + sessionID + `"}` + "\n"),
+			Prompts:          []string{"prompt for " + sessionID},
+			FilesTouched:     []string{sessionID + ".go"},
+			CheckpointsCount: 1,
+			AuthorName:       "Test",
+			AuthorEmail:      "test@test.com",
+		}); err != nil {
+			t.Fatalf("WriteCommitted(%s) error = %v", sessionID, err)
+		}
+	}
+
+	// Files are laid out as on the metadata branch.
+	for _, rel := range []string{"a1/b2c3d4e5f6/metadata.json", "a1/b2c3d4e5f6/0/full.jsonl.zst", "a1/b2c3d4e5f6/1/prompt.txt"} {
+		if _, err := os.Stat(filepath.Join(store.Dir(), filepath.FromSlash(rel))); err != nil {
+			t.Errorf("expected %s in store directory: %v", rel, err)
+		}
+	}
+	// Nothing is written to the repository's metadata branch.
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true); err == nil {
+		t.Errorf("%s should not be created", paths.MetadataBranchName)
+	}
+
+	summary, err := store.ReadCommitted(ctx, cpID)
+	if err != nil || summary == nil {
+		t.Fatalf("ReadCommitted() = %v, %v", summary, err)
+	}
+	if len(summary.Sessions) != 2 || summary.CheckpointsCount != 2 || len(summary.FilesTouched) != 2 {
+		t.Errorf("summary = %+v, want 2 aggregated sessions", summary)
+	}
+
+	content, err := store.ReadSessionContentByID(ctx, cpID, "session-1")
+	if err != nil {
+		t.Fatalf("ReadSessionContentByID() error = %v", err)
+	}
+	if content.Prompts != "prompt for session-1" || !strings.Contains(string(content.Transcript), "session-1") {
+		t.Errorf("session-1 content = %q / %q", content.Prompts, content.Transcript)
+	}
+
+	transcript, sessionID, err := store.GetSessionLog(cpID)
+	if err != nil || sessionID != "session-2" || !strings.Contains(string(transcript), "session-2") {
+		t.Errorf("GetSessionLog() = %q, %q, %v; want the latest session", transcript, sessionID, err)
+	}
+
+	data, err := store.ReadCommittedFile(ctx, "a1/b2c3d4e5f6/1/prompt.txt")
+	if err != nil || string(data) != "prompt for session-2" {
+		t.Errorf("ReadCommittedFile() = %q, %v", data, err)
+	}
+	if _, err := store.ReadCommittedFile(ctx, "../outside"); err == nil {
+		t.Error("ReadCommittedFile() should reject paths outside the store")
+	}
+}
+
+func TestFilesystemStore_UpdateAndDelete(t *testing.T) {
+	t.Parallel()
+	store, _ := newTestFilesystemStore(t)
+	ctx := context.Background()
+	cpID := id.MustCheckpointID("b1c2d3e4f5a6")
+
+	if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-1",
+		Strategy:     "manual-commit",
+		Transcript:   []byte("provisional\n"),
+		Prompts:      []string{"first"},
+		Context:      []byte("context"),
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	if err := store.UpdateCommitted(ctx, UpdateCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-1",
+		Transcript:   []byte("final line 1\nfinal line 2\n"),
+		Prompts:      []string{"first", "second"},
+	}); err != nil {
+		t.Fatalf("UpdateCommitted() error = %v", err)
+	}
+	if err := store.UpdateSummary(ctx, cpID, &Summary{Intent: "test intent"}); err != nil {
+		t.Fatalf("UpdateSummary() error = %v", err)
+	}
+
+	content, err := store.ReadLatestSessionContent(ctx, cpID)
+	if err != nil {
+		t.Fatalf("ReadLatestSessionContent() error = %v", err)
+	}
+	if string(content.Transcript) != "final line 1\nfinal line 2\n" {
+		t.Errorf("Transcript = %q, want the replaced transcript", content.Transcript)
+	}
+	if content.Metadata.Summary == nil || content.Metadata.Summary.Intent != "test intent" {
+		t.Errorf("Summary = %+v, want the updated summary", content.Metadata.Summary)
+	}
+	if content.Context != "context" {
+		t.Errorf("Context = %q, want it kept", content.Context)
+	}
+
+	infos, err := store.ListCommitted(ctx)
+	if err != nil || len(infos) != 1 || infos[0].CheckpointID != cpID || infos[0].SessionID != "session-1" {
+		t.Fatalf("ListCommitted() = %+v, %v", infos, err)
+	}
+
+	if err := store.DeleteCommitted(ctx, []id.CheckpointID{cpID}); err != nil {
+		t.Fatalf("DeleteCommitted() error = %v", err)
+	}
+	if summary, err := store.ReadCommitted(ctx, cpID); err != nil || summary != nil {
+		t.Errorf("ReadCommitted() after delete = %v, %v; want nil, nil", summary, err)
+	}
+	if _, err := os.Stat(filepath.Join(store.Dir(), "b1")); !os.IsNotExist(err) {
+		t.Error("empty shard directory should be removed")
+	}
+}
+
+func TestFilesystemStore_PruneRecordsDeleted(t *testing.T) {
+	t.Parallel()
+	store, _ := newTestFilesystemStore(t)
+	ctx := context.Background()
+	deleted := id.MustCheckpointID("c1c2d3e4f5a6")
+	dropped := id.MustCheckpointID("c2c2d3e4f5a6")
+	writePruneCheckpoint(t, store, deleted, "Alice")
+	writePruneCheckpoint(t, store, dropped, "Bob")
+
+	if err := store.PruneCommitted(ctx, PruneOptions{
+		Delete:          []id.CheckpointID{deleted, id.MustCheckpointID("c3c2d3e4f5a6")},
+		DropTranscripts: []id.CheckpointID{dropped},
+	}); err != nil {
+		t.Fatalf("PruneCommitted() error = %v", err)
+	}
+	pruned, err := store.ListPruned(ctx)
+	if err != nil || len(pruned) != 1 || pruned[0] != deleted {
+		t.Errorf("ListPruned() = %v, %v; want only [%s]", pruned, err, deleted)
+	}
+	infos, err := store.ListCommitted(ctx)
+	if err != nil || len(infos) != 1 || infos[0].CheckpointID != dropped {
+		t.Errorf("ListCommitted() = %+v, %v; want only %s", infos, err, dropped)
+	}
+}
+
+func TestFilesystemStore_NotFound(t *testing.T) {
+	t.Parallel()
+	store, _ := newTestFilesystemStore(t)
+	ctx := context.Background()
+	cpID := id.MustCheckpointID("c1d2e3f4a5b6")
+
+	if err := store.UpdateSummary(ctx, cpID, &Summary{}); err == nil {
+		t.Error("UpdateSummary() should fail for a missing checkpoint")
+	}
+	if _, err := store.ReadLatestSessionContent(ctx, cpID); err == nil {
+		t.Error("ReadLatestSessionContent() should fail for a missing checkpoint")
+	}
+	infos, err := store.ListCommitted(ctx)
+	if err != nil || len(infos) != 0 {
+		t.Errorf("ListCommitted() = %v, %v; want empty", infos, err)
+	}
+	if err := store.WriteCommitted(ctx, WriteCommittedOptions{SessionID: "s"}); err == nil {
+		t.Error("WriteCommitted() should require a checkpoint ID")
+	}
+}
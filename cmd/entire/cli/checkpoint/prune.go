@@ -0,0 +1,515 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// PruneOptions selects what PruneCommitted removes.
+type PruneOptions struct {
+	// Delete lists checkpoints to remove entirely.
+	Delete []id.CheckpointID
+
+	// DropTranscripts lists checkpoints to keep without their transcripts:
+	// metadata (including AI summaries), prompts and context stay.
+	DropTranscripts []id.CheckpointID
+}
+
+// CheckpointSize is the stored size of a committed checkpoint in bytes.
+type CheckpointSize struct {
+	// Total is the size of all of the checkpoint's files.
+	Total int64
+
+	// Transcripts is the part of Total taken by session and subagent
+	// transcripts, i.e. what dropping the transcripts frees.
+	Transcripts int64
+}
+
+// isTranscriptPath reports whether rel, a file path inside a checkpoint
+// directory, is a transcript: a session's full or normalized transcript
+// (including chunks and encrypted variants) or a subagent transcript.
+func isTranscriptPath(rel string) bool {
+	parts := strings.Split(rel, "/")
+	name := parts[len(parts)-1]
+	switch {
+	case len(parts) == 2:
+		if _, err := strconv.Atoi(parts[0]); err != nil {
+			return false
+		}
+		return strings.HasPrefix(name, paths.TranscriptFileName) ||
+			strings.HasPrefix(name, paths.TranscriptFileNameLegacy) ||
+			strings.HasPrefix(name, paths.NormalizedFileName)
+	case len(parts) == 3 && parts[0] == "tasks":
+		return strings.HasPrefix(name, "agent-")
+	}
+	return false
+}
+
+func checkpointIDSet(checkpointIDs []id.CheckpointID) map[id.CheckpointID]bool {
+	set := make(map[id.CheckpointID]bool, len(checkpointIDs))
+	for _, cpID := range checkpointIDs {
+		if !cpID.IsEmpty() {
+			set[cpID] = true
+		}
+	}
+	return set
+}
+
+// CommittedSizes returns the stored size of each checkpoint on the
+// entire/checkpoints/v1 branch, as uncompressed git object sizes.
+func (s *GitStore) CommittedSizes(ctx context.Context) (map[id.CheckpointID]CheckpointSize, error) {
+	_ = ctx // Reserved for future use
+
+	sizes := make(map[id.CheckpointID]CheckpointSize)
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return sizes, nil //nolint:nilerr // No sessions branch means no checkpoints
+	}
+	for _, bucketEntry := range tree.Entries {
+		if bucketEntry.Mode != filemode.Dir || len(bucketEntry.Name) != 2 {
+			continue
+		}
+		bucketTree, err := s.repo.TreeObject(bucketEntry.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint shard %s: %w", bucketEntry.Name, err)
+		}
+		for _, cpEntry := range bucketTree.Entries {
+			cpID, err := id.NewCheckpointID(bucketEntry.Name + cpEntry.Name)
+			if err != nil || cpEntry.Mode != filemode.Dir {
+				continue
+			}
+			cpTree, err := s.repo.TreeObject(cpEntry.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+			}
+			var size CheckpointSize
+			err = cpTree.Files().ForEach(func(f *object.File) error {
+				size.Total += f.Size
+				if isTranscriptPath(f.Name) {
+					size.Transcripts += f.Size
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to size checkpoint %s: %w", cpID, err)
+			}
+			sizes[cpID] = size
+		}
+	}
+	return sizes, nil
+}
+
+// PruneCommitted deletes checkpoints or drops their transcripts, then
+// rewrites the history of the entire/checkpoints/v1 branch so that no
+// commit references the pruned content any more. Commit messages and
+// authors are kept, so GetCheckpointAuthor keeps working. Sessions
+// delta-encoded against a pruned checkpoint are rewritten with their full
+// transcripts first.
+func (s *GitStore) PruneCommitted(ctx context.Context, opts PruneOptions) error {
+
+	deleted := checkpointIDSet(opts.Delete)
+	dropped := checkpointIDSet(opts.DropTranscripts)
+	for cpID := range deleted {
+		delete(dropped, cpID)
+	}
+	if len(deleted)+len(dropped) == 0 {
+		return nil
+	}
+
+	ref, entries, err := s.getSessionsBranchEntries()
+	if err != nil {
+		return err
+	}
+	tip, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get commit object: %w", err)
+	}
+	root, err := tip.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get commit tree: %w", err)
+	}
+
+	pruned := make(map[id.CheckpointID]bool, len(deleted)+len(dropped))
+	for cpID := range deleted {
+		pruned[cpID] = true
+	}
+	for cpID := range dropped {
+		pruned[cpID] = true
+	}
+	if _, err := s.inlineTranscripts(root, entries, pruned, func(cpID id.CheckpointID) bool {
+		return !pruned[cpID]
+	}); err != nil {
+		return err
+	}
+
+	if err := s.recordPruned(entries, deleted); err != nil {
+		return err
+	}
+	removed := removeCheckpointEntries(entries, deleted)
+	stripped, err := s.dropTranscriptEntries(entries, dropped)
+	if err != nil {
+		return err
+	}
+	if removed+stripped == 0 {
+		return nil
+	}
+
+	newTreeHash, err := BuildTreeFromEntries(s.repo, entries)
+	if err != nil {
+		return err
+	}
+	parent, err := newHistoryPruner(s, deleted, dropped).rewrite(ctx, ref.Hash())
+	if err != nil {
+		return err
+	}
+
+	commitMsg := fmt.Sprintf("Prune: removed %d checkpoints, dropped transcripts of %d", removed, stripped)
+	newCommitHash, err := s.createMetadataCommit(ctx, newTreeHash, parent, commitMsg, "Entire CLI", "cli@entire.io")
+	if err != nil {
+		return err
+	}
+	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	if err := s.repo.Storer.SetReference(plumbing.NewHashReference(refName, newCommitHash)); err != nil {
+		return fmt.Errorf("failed to set branch reference: %w", err)
+	}
+	return nil
+}
+
+// prunedDir is the directory of the checkpoints tree that records the
+// checkpoints PruneCommitted deleted, as one empty file per checkpoint ID.
+// One file per checkpoint, rather than a list, lets branches that pruned
+// independently merge without conflicts.
+const prunedDir = "pruned"
+
+// recordPruned adds the checkpoints of checkpointIDs that have files in
+// entries to the pruned directory.
+func (s *GitStore) recordPruned(entries map[string]object.TreeEntry, checkpointIDs map[id.CheckpointID]bool) error {
+	var emptyBlob plumbing.Hash
+	for cpID := range checkpointIDs {
+		prefix := cpID.Path() + "/"
+		for treePath := range entries {
+			if !strings.HasPrefix(treePath, prefix) {
+				continue
+			}
+			if emptyBlob.IsZero() {
+				hash, err := CreateBlobFromContent(s.repo, nil)
+				if err != nil {
+					return err
+				}
+				emptyBlob = hash
+			}
+			prunedPath := prunedDir + "/" + cpID.String()
+			entries[prunedPath] = object.TreeEntry{Name: prunedPath, Mode: filemode.Regular, Hash: emptyBlob}
+			break
+		}
+	}
+	return nil
+}
+
+// ListPruned returns the checkpoints PruneCommitted deleted, sorted.
+func (s *GitStore) ListPruned(ctx context.Context) ([]id.CheckpointID, error) {
+	_ = ctx // Reserved for future use
+
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return nil, nil //nolint:nilerr // No sessions branch means nothing was pruned
+	}
+	prunedTree, err := tree.Tree(prunedDir)
+	if err != nil {
+		return nil, nil //nolint:nilerr // Nothing was pruned
+	}
+	var pruned []id.CheckpointID
+	for _, entry := range prunedTree.Entries {
+		if cpID, err := id.NewCheckpointID(entry.Name); err == nil {
+			pruned = append(pruned, cpID)
+		}
+	}
+	slices.Sort(pruned)
+	return pruned, nil
+}
+
+// removeCheckpointEntries removes the files of the given checkpoints from
+// entries. Returns the number of checkpoints that had files.
+func removeCheckpointEntries(entries map[string]object.TreeEntry, checkpointIDs map[id.CheckpointID]bool) int {
+	removed := 0
+	for cpID := range checkpointIDs {
+		prefix := cpID.Path() + "/"
+		found := false
+		for treePath := range entries {
+			if strings.HasPrefix(treePath, prefix) {
+				delete(entries, treePath)
+				found = true
+			}
+		}
+		if found {
+			removed++
+		}
+	}
+	return removed
+}
+
+// dropTranscriptEntries removes the transcripts of the given checkpoints from
+// entries, clearing the transcript paths in their root summaries and any
+// transcript parents in their session metadata. Returns the number of
+// checkpoints that had transcripts.
+func (s *GitStore) dropTranscriptEntries(entries map[string]object.TreeEntry, checkpointIDs map[id.CheckpointID]bool) (int, error) {
+	stripped := 0
+	for cpID := range checkpointIDs {
+		prefix := cpID.Path() + "/"
+		found := false
+		for treePath, entry := range entries {
+			rel, ok := strings.CutPrefix(treePath, prefix)
+			if !ok {
+				continue
+			}
+			if isTranscriptPath(rel) {
+				delete(entries, treePath)
+				found = true
+				continue
+			}
+			// A session without a transcript has nothing to reassemble.
+			parts := strings.Split(rel, "/")
+			if len(parts) != 2 || parts[1] != paths.MetadataFileName {
+				continue
+			}
+			meta, err := s.readMetadataFromBlob(entry.Hash)
+			if err != nil || meta.TranscriptParent.IsEmpty() {
+				continue
+			}
+			meta.TranscriptParent, meta.TranscriptParentLines, meta.NormalizedParentLines = "", 0, 0
+			if err := s.writeJSONEntry(entries, treePath, meta); err != nil {
+				return 0, err
+			}
+		}
+		if !found {
+			continue
+		}
+		stripped++
+
+		rootMetadataPath := prefix + paths.MetadataFileName
+		rootEntry, ok := entries[rootMetadataPath]
+		if !ok {
+			continue
+		}
+		summary, err := s.readSummaryFromBlob(rootEntry.Hash)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read checkpoint summary: %w", err)
+		}
+		for i := range summary.Sessions {
+			summary.Sessions[i].Transcript = ""
+			summary.Sessions[i].Normalized = ""
+		}
+		if err := s.writeJSONEntry(entries, rootMetadataPath, summary); err != nil {
+			return 0, err
+		}
+	}
+	return stripped, nil
+}
+
+// historyPruner rewrites the commits of the entire/checkpoints/v1 branch
+// without the files of deleted checkpoints and the transcripts of dropped
+// ones. Only the shards holding pruned checkpoints are rewritten; rewritten
+// trees and commits are memoized, so each distinct tree is visited once.
+type historyPruner struct {
+	store   *GitStore
+	deleted map[id.CheckpointID]bool
+	dropped map[id.CheckpointID]bool
+	shards  map[string]bool
+
+	commits map[plumbing.Hash]plumbing.Hash
+	trees   map[string]plumbing.Hash // keyed by path + ":" + tree hash
+}
+
+func newHistoryPruner(s *GitStore, deleted, dropped map[id.CheckpointID]bool) *historyPruner {
+	shards := make(map[string]bool)
+	for cpID := range deleted {
+		shards[cpID.String()[:2]] = true
+	}
+	for cpID := range dropped {
+		shards[cpID.String()[:2]] = true
+	}
+	return &historyPruner{
+		store:   s,
+		deleted: deleted,
+		dropped: dropped,
+		shards:  shards,
+		commits: make(map[plumbing.Hash]plumbing.Hash),
+		trees:   make(map[string]plumbing.Hash),
+	}
+}
+
+// rewrite rewrites the history reachable from tip, parents first, and
+// returns the hash of the rewritten tip. Commits that reference no pruned
+// content, and have no rewritten ancestors, keep their hashes.
+func (p *historyPruner) rewrite(ctx context.Context, tip plumbing.Hash) (plumbing.Hash, error) {
+	// Iterative post-order walk: the branch history can be far deeper than
+	// is safe to recurse.
+	stack := []plumbing.Hash{tip}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		if _, done := p.commits[hash]; done {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		commit, err := p.store.repo.CommitObject(hash)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read commit %s: %w", hash, err)
+		}
+		pending := false
+		for _, parent := range commit.ParentHashes {
+			if _, done := p.commits[parent]; !done {
+				stack = append(stack, parent)
+				pending = true
+			}
+		}
+		if pending {
+			continue
+		}
+		stack = stack[:len(stack)-1]
+		newHash, err := p.rewriteCommit(ctx, commit)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		p.commits[hash] = newHash
+	}
+	return p.commits[tip], nil
+}
+
+func (p *historyPruner) rewriteCommit(ctx context.Context, commit *object.Commit) (plumbing.Hash, error) {
+	treeHash, err := p.rewriteTree(commit.TreeHash, "")
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	changed := treeHash != commit.TreeHash
+	parents := make([]plumbing.Hash, len(commit.ParentHashes))
+	for i, parent := range commit.ParentHashes {
+		parents[i] = p.commits[parent]
+		changed = changed || parents[i] != parent
+	}
+	if !changed {
+		return commit.Hash, nil
+	}
+
+	// Original signatures can't survive the rewrite, so rewritten commits are
+	// signed with the configured signer, if any; everything else is kept.
+	rewritten := &object.Commit{
+		Author:       commit.Author,
+		Committer:    commit.Committer,
+		Message:      commit.Message,
+		TreeHash:     treeHash,
+		ParentHashes: parents,
+		Encoding:     commit.Encoding,
+	}
+	if err := p.store.signMetadataCommit(ctx, rewritten); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return p.store.storeCommit(rewritten)
+}
+
+// rewriteTree rewrites the tree at dir ("" for the root, "a1" for a shard,
+// "a1/b2c3d4e5f6" for a checkpoint, deeper for its subdirectories). Returns
+// plumbing.ZeroHash if nothing is left of it.
+func (p *historyPruner) rewriteTree(hash plumbing.Hash, dir string) (plumbing.Hash, error) {
+	key := dir + ":" + hash.String()
+	if newHash, ok := p.trees[key]; ok {
+		return newHash, nil
+	}
+	tree, err := p.store.repo.TreeObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to read tree %s: %w", hash, err)
+	}
+
+	depth := 0
+	if dir != "" {
+		depth = strings.Count(dir, "/") + 1
+	}
+	changed := false
+	entries := make([]object.TreeEntry, 0, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		keep, newHash, err := p.rewriteEntry(entry, dir, depth)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if !keep || newHash.IsZero() {
+			changed = true
+			continue
+		}
+		if newHash != entry.Hash {
+			changed = true
+			entry.Hash = newHash
+		}
+		entries = append(entries, entry)
+	}
+
+	newHash := hash
+	switch {
+	case len(entries) == 0 && dir != "":
+		newHash = plumbing.ZeroHash
+	case changed:
+		newTree := &object.Tree{Entries: entries}
+		obj := p.store.repo.Storer.NewEncodedObject()
+		if err := newTree.Encode(obj); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+		}
+		newHash, err = p.store.repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to store tree: %w", err)
+		}
+	}
+	p.trees[key] = newHash
+	return newHash, nil
+}
+
+// rewriteEntry decides what happens to one entry of the tree at dir, which
+// is depth levels below the root. Reports whether to keep the entry and its
+// (possibly rewritten) hash.
+func (p *historyPruner) rewriteEntry(entry object.TreeEntry, dir string, depth int) (bool, plumbing.Hash, error) {
+	isDir := entry.Mode == filemode.Dir
+	switch depth {
+	case 0:
+		// Root: only shards holding pruned checkpoints change.
+		if !isDir || !p.shards[entry.Name] {
+			return true, entry.Hash, nil
+		}
+	case 1:
+		// Shard: checkpoint directories.
+		cpID, err := id.NewCheckpointID(dir + entry.Name)
+		if err != nil || !isDir {
+			return true, entry.Hash, nil
+		}
+		if p.deleted[cpID] {
+			return false, plumbing.ZeroHash, nil
+		}
+		if !p.dropped[cpID] {
+			return true, entry.Hash, nil
+		}
+	default:
+		// Inside a checkpoint whose transcripts are dropped.
+		rel := entry.Name
+		if _, cpRel, ok := strings.Cut(dir, "/"); ok {
+			if _, inner, ok := strings.Cut(cpRel, "/"); ok {
+				rel = inner + "/" + entry.Name
+			}
+		}
+		if !isDir {
+			return !isTranscriptPath(rel), entry.Hash, nil
+		}
+	}
+
+	childDir := entry.Name
+	if dir != "" {
+		childDir = dir + "/" + entry.Name
+	}
+	newHash, err := p.rewriteTree(entry.Hash, childDir)
+	return true, newHash, err
+}
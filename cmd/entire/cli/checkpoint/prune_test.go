@@ -0,0 +1,205 @@
+package checkpoint
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func writePruneCheckpoint(t *testing.T, store Store, cpID id.CheckpointID, author string) {
+	t.Helper()
+	if err := store.WriteCommitted(context.Background(), WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-" + cpID.String(),
+		Strategy:     "manual-commit",
+		Transcript:   transcriptLines(3),
+		Prompts:      []string{"prompt for " + cpID.String()},
+		Summary:      &Summary{Intent: "intent of " + cpID.String()},
+		AuthorName:   author,
+		AuthorEmail:  strings.ToLower(author) + "@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted(%s) error = %v", cpID, err)
+	}
+}
+
+// historyPaths returns every file path in any commit of the checkpoints branch.
+func historyPaths(t *testing.T, repo *git.Repository) map[string]bool {
+	t.Helper()
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err != nil {
+		t.Fatalf("checkpoints branch not found: %v", err)
+	}
+	iter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
+	if err != nil {
+		t.Fatalf("Log() error = %v", err)
+	}
+	found := make(map[string]bool)
+	err = iter.ForEach(func(c *object.Commit) error {
+		files, err := c.Files()
+		if err != nil {
+			return err
+		}
+		return files.ForEach(func(f *object.File) error {
+			found[f.Name] = true
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("failed to walk history: %v", err)
+	}
+	return found
+}
+
+func TestPruneCommitted_RewritesHistory(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	ctx := context.Background()
+	cpDelete := id.MustCheckpointID("9a0000000001")
+	cpDrop := id.MustCheckpointID("9a0000000002")
+	cpKeep := id.MustCheckpointID("9b0000000003")
+	writePruneCheckpoint(t, store, cpDelete, "Alice")
+	writePruneCheckpoint(t, store, cpDrop, "Bob")
+	writePruneCheckpoint(t, store, cpKeep, "Carol")
+
+	if err := store.PruneCommitted(ctx, PruneOptions{
+		Delete:          []id.CheckpointID{cpDelete},
+		DropTranscripts: []id.CheckpointID{cpDrop},
+	}); err != nil {
+		t.Fatalf("PruneCommitted() error = %v", err)
+	}
+
+	if summary, err := store.ReadCommitted(ctx, cpDelete); err != nil || summary != nil {
+		t.Errorf("ReadCommitted(deleted) = %v, %v; want nil", summary, err)
+	}
+	content, err := store.ReadSessionContent(ctx, cpDrop, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent(dropped) error = %v", err)
+	}
+	if len(content.Transcript) != 0 {
+		t.Errorf("dropped checkpoint transcript = %q, want none", content.Transcript)
+	}
+	if content.Prompts != "prompt for "+cpDrop.String() || content.Metadata.Summary == nil {
+		t.Errorf("dropped checkpoint prompts, summary = %q, %v; want them kept", content.Prompts, content.Metadata.Summary)
+	}
+	summary, err := store.ReadCommitted(ctx, cpDrop)
+	if err != nil || summary == nil || summary.Sessions[0].Transcript != "" {
+		t.Errorf("dropped checkpoint summary = %+v, %v; want no transcript path", summary, err)
+	}
+	assertTranscript(t, store, cpKeep, transcriptLines(3))
+
+	// Only deleted checkpoints are recorded as pruned; dropping transcripts
+	// keeps the checkpoint.
+	if pruned, err := store.ListPruned(ctx); err != nil || !slices.Equal(pruned, []id.CheckpointID{cpDelete}) {
+		t.Errorf("ListPruned() = %v, %v; want [%s]", pruned, err, cpDelete)
+	}
+
+	// No commit in the branch history references the pruned content.
+	for treePath := range historyPaths(t, repo) {
+		if strings.HasPrefix(treePath, cpDelete.Path()+"/") {
+			t.Errorf("history still contains deleted checkpoint file %s", treePath)
+		}
+		if rel, ok := strings.CutPrefix(treePath, cpDrop.Path()+"/"); ok && isTranscriptPath(rel) {
+			t.Errorf("history still contains dropped transcript %s", treePath)
+		}
+	}
+
+	// Authors survive the rewrite.
+	for cpID, want := range map[id.CheckpointID]string{cpDrop: "Bob", cpKeep: "Carol"} {
+		author, err := store.GetCheckpointAuthor(ctx, cpID)
+		if err != nil || author.Name != want {
+			t.Errorf("GetCheckpointAuthor(%s) = %+v, %v; want %s", cpID, author, err, want)
+		}
+	}
+}
+
+func TestPruneCommitted_InlinesDeltaDependents(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	store.SetDeltaTranscripts(true)
+	cpA := id.MustCheckpointID("9c0000000001")
+	cpB := id.MustCheckpointID("9c0000000002")
+	writeDeltaCheckpoint(t, store, cpA, "", transcriptLines(2))
+	writeDeltaCheckpoint(t, store, cpB, cpA, transcriptLines(4))
+
+	if err := store.PruneCommitted(context.Background(), PruneOptions{DropTranscripts: []id.CheckpointID{cpA}}); err != nil {
+		t.Fatalf("PruneCommitted() error = %v", err)
+	}
+
+	contentB := assertTranscript(t, store, cpB, transcriptLines(4))
+	if !contentB.Metadata.TranscriptParent.IsEmpty() {
+		t.Errorf("B TranscriptParent = %s, want none after its parent's transcript was dropped", contentB.Metadata.TranscriptParent)
+	}
+}
+
+func TestCommittedSizes(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo, _ := setupBranchTestRepo(t)
+	fsStore, _ := newTestFilesystemStore(t)
+	for name, store := range map[string]Store{"git": NewGitStore(repo), "filesystem": fsStore} {
+		t.Run(name, func(t *testing.T) {
+			cpA := id.MustCheckpointID("9d0000000001")
+			cpB := id.MustCheckpointID("9d0000000002")
+			writePruneCheckpoint(t, store, cpA, "Test")
+			writePruneCheckpoint(t, store, cpB, "Test")
+
+			sizes, err := store.CommittedSizes(ctx)
+			if err != nil {
+				t.Fatalf("CommittedSizes() error = %v", err)
+			}
+			if len(sizes) != 2 || sizes[cpA].Transcripts == 0 || sizes[cpA].Total <= sizes[cpA].Transcripts {
+				t.Fatalf("CommittedSizes() = %+v, want both checkpoints with transcripts and metadata", sizes)
+			}
+
+			if err := store.PruneCommitted(ctx, PruneOptions{
+				Delete:          []id.CheckpointID{cpB},
+				DropTranscripts: []id.CheckpointID{cpA},
+			}); err != nil {
+				t.Fatalf("PruneCommitted() error = %v", err)
+			}
+			sizes, err = store.CommittedSizes(ctx)
+			if err != nil {
+				t.Fatalf("CommittedSizes() error = %v", err)
+			}
+			if _, ok := sizes[cpB]; ok || len(sizes) != 1 || sizes[cpA].Transcripts != 0 || sizes[cpA].Total == 0 {
+				t.Errorf("CommittedSizes() after pruning = %+v, want only %s without transcripts", sizes, cpA)
+			}
+			content, err := store.ReadSessionContent(ctx, cpA, 0)
+			if err != nil || content.Prompts != "prompt for "+cpA.String() || len(content.Transcript) != 0 {
+				t.Errorf("ReadSessionContent() = %+v, %v; want the prompt without a transcript", content, err)
+			}
+		})
+	}
+}
+
+func TestIsTranscriptPath(t *testing.T) {
+	t.Parallel()
+	tests := map[string]bool{
+		"0/full.jsonl":              true,
+		"0/full.jsonl.zst.age":      true,
+		"1/full.jsonl.001":          true,
+		"0/full.log":                true,
+		"0/normalized.jsonl":        true,
+		"tasks/tu1/agent-a1.jsonl":  true,
+		"0/metadata.json":           false,
+		"0/prompt.txt":              false,
+		"metadata.json":             false,
+		"tasks/tu1/checkpoint.json": false,
+		"notes/full.jsonl":          false,
+	}
+	for rel, want := range tests {
+		if got := isTranscriptPath(rel); got != want {
+			t.Errorf("isTranscriptPath(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
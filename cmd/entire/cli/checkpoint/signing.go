@@ -0,0 +1,189 @@
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Signing formats, the values of git's gpg.format.
+const (
+	SigningFormatOpenPGP = "openpgp"
+	SigningFormatSSH     = "ssh"
+	SigningFormatX509    = "x509"
+)
+
+// CommitSigner signs commits on the entire/checkpoints/v1 branch the same way
+// git signs commits when commit.gpgSign is set, so the branch can be pushed to
+// remotes that require signed commits. Shadow branches stay local and are
+// never signed.
+type CommitSigner struct {
+	// Format is one of the SigningFormat constants.
+	Format string
+
+	// Program is the signing program, e.g. "gpg", "gpgsm" or "ssh-keygen".
+	Program string
+
+	// Key is git's user.signingKey. For ssh it is the path to a key file, or
+	// a public key ("ssh-ed25519 AAAA..." or "key::ssh-ed25519 AAAA...") whose
+	// private key is in ssh-agent. For openpgp and x509 it is a key ID, or
+	// the committer's "Name <email>" when user.signingKey isn't set.
+	Key string
+}
+
+// Sign signs commit, setting its signature. A nil signer leaves the commit
+// unsigned.
+func (c *CommitSigner) Sign(ctx context.Context, commit *object.Commit) error {
+	if c == nil {
+		return nil
+	}
+	obj := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(obj); err != nil {
+		return fmt.Errorf("failed to encode commit for signing: %w", err)
+	}
+	r, err := obj.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to encode commit for signing: %w", err)
+	}
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode commit for signing: %w", err)
+	}
+
+	var signature string
+	switch c.Format {
+	case SigningFormatSSH:
+		signature, err = c.signSSH(ctx, payload)
+	case SigningFormatOpenPGP, SigningFormatX509:
+		signature, err = c.signGPG(ctx, payload)
+	default:
+		err = fmt.Errorf("unsupported gpg.format %q", c.Format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to sign commit: %w", err)
+	}
+	commit.PGPSignature = signature
+	return nil
+}
+
+// signGPG creates a detached armored signature with gpg or gpgsm.
+func (c *CommitSigner) signGPG(ctx context.Context, payload []byte) (string, error) {
+	cmd := exec.CommandContext(ctx, c.Program, "--status-fd=2", "-bsau", c.Key) //nolint:gosec // Program and Key come from the user's git config
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil || !strings.Contains(stderr.String(), "[GNUPG:] SIG_CREATED ") {
+		return "", fmt.Errorf("%s failed to sign the data: %w\n%s", c.Program, errors.Join(err, errors.New("no signature created")), strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// signSSH creates an SSH signature in the "git" namespace with ssh-keygen.
+func (c *CommitSigner) signSSH(ctx context.Context, payload []byte) (string, error) {
+	if c.Key == "" {
+		return "", errors.New("user.signingKey must be set for ssh signing")
+	}
+	keyFile := c.Key
+	args := []string{"-Y", "sign", "-n", "git"}
+	if literal, ok := literalSSHKey(c.Key); ok {
+		// A public key: ssh-keygen finds the private key in ssh-agent.
+		tmp, err := os.CreateTemp("", ".entire-signing-key-*")
+		if err != nil {
+			return "", fmt.Errorf("failed to write signing key: %w", err)
+		}
+		defer func() { _ = os.Remove(tmp.Name()) }() //nolint:errcheck // Best effort cleanup of a public key
+		if _, err := tmp.WriteString(literal + "\n"); err != nil {
+			_ = tmp.Close()
+			return "", fmt.Errorf("failed to write signing key: %w", err)
+		}
+		if err := tmp.Close(); err != nil {
+			return "", fmt.Errorf("failed to write signing key: %w", err)
+		}
+		keyFile = tmp.Name()
+		args = append(args, "-U")
+	} else if rest, ok := strings.CutPrefix(keyFile, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve signing key: %w", err)
+		}
+		keyFile = filepath.Join(home, rest)
+	}
+	args = append(args, "-f", keyFile)
+
+	// With no file argument ssh-keygen signs stdin and writes the signature
+	// to stdout.
+	cmd := exec.CommandContext(ctx, c.Program, args...) //nolint:gosec // Program and key come from the user's git config
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed to sign the data: %w\n%s", c.Program, err, strings.TrimSpace(stderr.String()))
+	}
+	if !strings.Contains(stdout.String(), "-----BEGIN SSH SIGNATURE-----") {
+		return "", fmt.Errorf("%s produced no signature", c.Program)
+	}
+	return stdout.String(), nil
+}
+
+// literalSSHKey returns the public key if key is one rather than a path,
+// following git's rules for user.signingKey.
+func literalSSHKey(key string) (string, bool) {
+	if rest, ok := strings.CutPrefix(key, "key::"); ok {
+		return rest, true
+	}
+	if strings.HasPrefix(key, "ssh-") {
+		return key, true
+	}
+	return "", false
+}
+
+// SetCommitSigner configures signing of commits on the
+// entire/checkpoints/v1 branch. load is called on the first such commit, so
+// a store that only reads never consults the signing configuration. A nil
+// load, or a nil signer, writes unsigned commits.
+func (s *GitStore) SetCommitSigner(load func(context.Context) (*CommitSigner, error)) {
+	s.loadSigner = load
+	s.signer, s.signerErr = nil, nil
+}
+
+// commitSigner returns the signer for commits on the entire/checkpoints/v1
+// branch, loading it on first use.
+func (s *GitStore) commitSigner(ctx context.Context) (*CommitSigner, error) {
+	if s.loadSigner != nil {
+		s.signer, s.signerErr = s.loadSigner(ctx)
+		s.loadSigner = nil
+	}
+	return s.signer, s.signerErr
+}
+
+// signMetadataCommit signs commit for the entire/checkpoints/v1 branch when
+// a signer is configured.
+func (s *GitStore) signMetadataCommit(ctx context.Context, commit *object.Commit) error {
+	signer, err := s.commitSigner(ctx)
+	if err != nil {
+		return err
+	}
+	return signer.Sign(ctx, commit)
+}
+
+// createMetadataCommit creates a commit for the entire/checkpoints/v1
+// branch, signed when a signer is configured.
+func (s *GitStore) createMetadataCommit(ctx context.Context, treeHash, parentHash plumbing.Hash, message, authorName, authorEmail string) (plumbing.Hash, error) {
+	commit := newCommitObject(treeHash, parentHash, message, authorName, authorEmail)
+	if err := s.signMetadataCommit(ctx, commit); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.storeCommit(commit)
+}
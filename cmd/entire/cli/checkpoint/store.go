@@ -1,6 +1,10 @@
 package checkpoint
 
 import (
+	"context"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
 	"github.com/go-git/go-git/v5"
 )
 
@@ -11,15 +15,43 @@ var _ Store = (*GitStore)(nil)
 // It implements the Store interface by wrapping a git repository.
 type GitStore struct {
 	repo *git.Repository
+
+	// compressionLevel is the zstd level for committed transcripts (0 = uncompressed).
+	compressionLevel int
+
+	// deltaTranscripts stores only the lines a session appended since its
+	// previous checkpoint (see WriteCommittedOptions.TranscriptParent).
+	deltaTranscripts bool
+
+	// encryption, if set, encrypts committed transcripts, prompts and context.
+	encryption *Encryption
+
+	// loadSigner, if set, loads the signer for commits on the
+	// entire/checkpoints/v1 branch; see commitSigner.
+	loadSigner func(context.Context) (*CommitSigner, error)
+	signer     *CommitSigner
+	signerErr  error
 }
 
 // NewGitStore creates a new checkpoint store backed by the given git repository.
 func NewGitStore(repo *git.Repository) *GitStore {
-	return &GitStore{repo: repo}
+	return &GitStore{repo: repo, compressionLevel: DefaultCompressionLevel}
+}
+
+// SetCompressionLevel sets the zstd level (1-22) committed transcripts are
+// written with. Level 0 writes them uncompressed, as full.jsonl.
+// Existing checkpoints are readable whichever level they were written with.
+func (s *GitStore) SetCompressionLevel(level int) {
+	s.compressionLevel = level
+}
+
+// SetDeltaTranscripts enables delta-encoded transcripts. Existing delta-encoded
+// checkpoints are reassembled on read whether or not this is enabled.
+func (s *GitStore) SetDeltaTranscripts(enabled bool) {
+	s.deltaTranscripts = enabled
 }
 
-// Repository returns the underlying git repository.
-// This is useful for strategies that need direct repository access.
-func (s *GitStore) Repository() *git.Repository {
-	return s.repo
+// Location returns the name of the branch committed checkpoints are kept on.
+func (s *GitStore) Location() string {
+	return paths.MetadataBranchName
 }
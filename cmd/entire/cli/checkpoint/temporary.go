@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/ignore"
 	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -106,7 +107,7 @@ func (s *GitStore) WriteTemporary(ctx context.Context, opts WriteTemporaryOption
 	}
 
 	// Build tree with changes
-	treeHash, err := s.buildTreeWithChanges(baseTreeHash, allFiles, allDeletedFiles, opts.MetadataDir, opts.MetadataDirAbs)
+	treeHash, err := s.buildTreeWithChanges(baseTreeHash, allFiles, allDeletedFiles, opts.Ignored, opts.MetadataDir, opts.MetadataDirAbs)
 	if err != nil {
 		return WriteTemporaryResult{}, fmt.Errorf("failed to build tree: %w", err)
 	}
@@ -259,7 +260,7 @@ func (s *GitStore) WriteTemporaryTask(ctx context.Context, opts WriteTemporaryTa
 	allFiles = append(allFiles, opts.NewFiles...)
 
 	// Build new tree with code changes (no metadata dir yet)
-	newTreeHash, err := s.buildTreeWithChanges(baseTreeHash, allFiles, opts.DeletedFiles, "", "")
+	newTreeHash, err := s.buildTreeWithChanges(baseTreeHash, allFiles, opts.DeletedFiles, opts.Ignored, "", "")
 	if err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to build tree: %w", err)
 	}
@@ -687,9 +688,12 @@ func (s *GitStore) getOrCreateShadowBranch(branchName string) (plumbing.Hash, pl
 // buildTreeWithChanges builds a git tree with the given changes.
 // metadataDir is the relative path for git tree entries, metadataDirAbs is the absolute path
 // for filesystem operations (needed when CLI is run from a subdirectory).
+// Changes to paths excluded by ignored (.entire/ignore) are left out, so
+// excluded files keep their base tree version.
 func (s *GitStore) buildTreeWithChanges(
 	baseTreeHash plumbing.Hash,
 	modifiedFiles, deletedFiles []string,
+	ignored *ignore.Matcher,
 	metadataDir, metadataDirAbs string,
 ) (plumbing.Hash, error) {
 	// Get repo root for resolving file paths
@@ -714,12 +718,12 @@ func (s *GitStore) buildTreeWithChanges(
 	}
 
 	// Remove deleted files
-	for _, file := range deletedFiles {
+	for _, file := range ignored.Filter(deletedFiles) {
 		delete(entries, file)
 	}
 
 	// Add/update modified files
-	for _, file := range modifiedFiles {
+	for _, file := range ignored.Filter(modifiedFiles) {
 		// Resolve path relative to repo root for filesystem operations
 		absPath := filepath.Join(repoRoot, file)
 		if !fileExists(absPath) {
@@ -751,8 +755,13 @@ func (s *GitStore) buildTreeWithChanges(
 	return BuildTreeFromEntries(s.repo, entries)
 }
 
-// createCommit creates a commit object.
+// createCommit creates an unsigned commit object.
 func (s *GitStore) createCommit(treeHash, parentHash plumbing.Hash, message, authorName, authorEmail string) (plumbing.Hash, error) {
+	return s.storeCommit(newCommitObject(treeHash, parentHash, message, authorName, authorEmail))
+}
+
+// newCommitObject builds a commit authored and committed now.
+func newCommitObject(treeHash, parentHash plumbing.Hash, message, authorName, authorEmail string) *object.Commit {
 	now := time.Now()
 	sig := object.Signature{
 		Name:  authorName,
@@ -771,7 +780,11 @@ func (s *GitStore) createCommit(treeHash, parentHash plumbing.Hash, message, aut
 	if parentHash != plumbing.ZeroHash {
 		commit.ParentHashes = []plumbing.Hash{parentHash}
 	}
+	return commit
+}
 
+// storeCommit writes a commit object to the repository.
+func (s *GitStore) storeCommit(commit *object.Commit) (plumbing.Hash, error) {
 	obj := s.repo.Storer.NewEncodedObject()
 	if err := commit.Encode(obj); err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
@@ -0,0 +1,276 @@
+package checkpoint
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ProblemKind classifies an inconsistency found by VerifyCommitted.
+type ProblemKind string
+
+const (
+	// ProblemInvalidMetadata: a checkpoint's or session's metadata.json is
+	// missing, can't be parsed, or names another checkpoint.
+	ProblemInvalidMetadata ProblemKind = "invalid-metadata"
+
+	// ProblemMissingFile: a path listed in CheckpointSummary.Sessions doesn't exist.
+	ProblemMissingFile ProblemKind = "missing-file"
+
+	// ProblemIncompleteChunks: a chunked transcript is missing chunks.
+	ProblemIncompleteChunks ProblemKind = "incomplete-chunks"
+
+	// ProblemUnreadableTranscript: a transcript can't be decompressed or
+	// reassembled, e.g. because its delta parent is gone.
+	ProblemUnreadableTranscript ProblemKind = "unreadable-transcript"
+
+	// ProblemContentHashMismatch: content_hash.txt doesn't match the transcript.
+	ProblemContentHashMismatch ProblemKind = "content-hash-mismatch"
+
+	// ProblemDanglingTrailer: a commit's Entire-Checkpoint trailer references
+	// a checkpoint that doesn't exist.
+	ProblemDanglingTrailer ProblemKind = "dangling-trailer"
+
+	// ProblemUnsignedCommit: a commit on the entire/checkpoints/v1 branch
+	// has no signature.
+	ProblemUnsignedCommit ProblemKind = "unsigned-commit"
+
+	// ProblemBadSignature: a commit's signature is invalid or made with a
+	// revoked key.
+	ProblemBadSignature ProblemKind = "bad-signature"
+
+	// ProblemUnverifiableSignature: a commit's signature can't be checked,
+	// e.g. because the public key or gpg.ssh.allowedSignersFile is missing.
+	ProblemUnverifiableSignature ProblemKind = "unverifiable-signature"
+)
+
+// Problem is an inconsistency in checkpoint data.
+type Problem struct {
+	Kind         ProblemKind
+	CheckpointID id.CheckpointID
+	Path         string // File the problem is about, if any
+	Commit       string // Commit the problem is about, if any
+	Detail       string
+}
+
+// VerifyResult is the outcome of VerifyCommitted.
+type VerifyResult struct {
+	Checkpoints int
+	Sessions    int
+
+	// Encrypted counts sessions whose content hash couldn't be checked
+	// because their transcript is encrypted and no identity is configured.
+	Encrypted int
+
+	// Pruned counts checkpoints referenced by Entire-Checkpoint trailers
+	// that were deleted by PruneCommitted. They aren't problems.
+	Pruned int
+
+	Problems []Problem
+}
+
+// transcriptBaseNames are the base names transcripts are stored under,
+// each possibly split into .001, .002, ... chunks.
+var transcriptBaseNames = []string{
+	paths.TranscriptFileName,
+	paths.TranscriptFileName + EncryptedFileSuffix,
+	paths.TranscriptFileNameCompressed,
+	paths.TranscriptFileNameCompressed + EncryptedFileSuffix,
+	paths.NormalizedFileName,
+	paths.NormalizedFileName + EncryptedFileSuffix,
+	paths.NormalizedFileNameCompressed,
+	paths.NormalizedFileNameCompressed + EncryptedFileSuffix,
+}
+
+// VerifyCommitted checks every committed checkpoint on the
+// entire/checkpoints/v1 branch: that metadata parses, that the files listed
+// in the checkpoint summary exist, that chunked transcripts are complete and
+// that each session's content_hash.txt matches its transcript.
+func (s *GitStore) VerifyCommitted(ctx context.Context) (*VerifyResult, error) {
+	result := &VerifyResult{}
+	tree, err := s.getSessionsBranchTree()
+	if err != nil {
+		return result, nil //nolint:nilerr // No sessions branch means nothing to verify
+	}
+
+	for _, bucketEntry := range tree.Entries {
+		if bucketEntry.Mode != filemode.Dir || len(bucketEntry.Name) != 2 {
+			continue
+		}
+		bucketTree, err := s.repo.TreeObject(bucketEntry.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shard %s: %w", bucketEntry.Name, err)
+		}
+		for _, checkpointEntry := range bucketTree.Entries {
+			if checkpointEntry.Mode != filemode.Dir {
+				continue
+			}
+			checkpointID, err := id.NewCheckpointID(bucketEntry.Name + checkpointEntry.Name)
+			if err != nil {
+				continue
+			}
+			cpTree, err := s.repo.TreeObject(checkpointEntry.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read checkpoint %s: %w", checkpointID, err)
+			}
+			result.Checkpoints++
+			s.verifyCheckpoint(ctx, checkpointID, cpTree, result)
+		}
+	}
+	return result, nil
+}
+
+// verifyCheckpoint checks one checkpoint directory, adding what it finds to result.
+func (s *GitStore) verifyCheckpoint(ctx context.Context, checkpointID id.CheckpointID, cpTree *object.Tree, result *VerifyResult) {
+	report := func(kind ProblemKind, filePath, format string, args ...any) {
+		result.Problems = append(result.Problems, Problem{
+			Kind:         kind,
+			CheckpointID: checkpointID,
+			Path:         filePath,
+			Detail:       fmt.Sprintf(format, args...),
+		})
+	}
+
+	summaryPath := checkpointID.Path() + "/" + paths.MetadataFileName
+	var summary CheckpointSummary
+	if err := readTreeJSON(cpTree, paths.MetadataFileName, &summary); err != nil {
+		report(ProblemInvalidMetadata, summaryPath, "%v", err)
+		return
+	}
+	if summary.CheckpointID != checkpointID {
+		report(ProblemInvalidMetadata, summaryPath, "names checkpoint %q", summary.CheckpointID)
+	}
+
+	for i, session := range summary.Sessions {
+		result.Sessions++
+		sessionDir := strconv.Itoa(i)
+		sessionPrefix := checkpointID.Path() + "/" + sessionDir + "/"
+		sessionTree, err := cpTree.Tree(sessionDir)
+		if err != nil {
+			report(ProblemMissingFile, sessionPrefix, "session %d of the checkpoint summary has no directory", i)
+			continue
+		}
+
+		// Sessions committed without a transcript still list the default
+		// transcript and content hash paths.
+		_, hashErr := sessionTree.File(paths.ContentHashFileName)
+		hasTranscript := hashErr == nil || hasTranscriptFiles(sessionTree)
+
+		listed := []string{session.Metadata, session.Prompt, session.Context, session.Normalized}
+		if hasTranscript {
+			listed = append(listed, session.Transcript, session.ContentHash)
+		}
+		for _, listedPath := range listed {
+			if listedPath == "" {
+				continue
+			}
+			treePath := strings.TrimPrefix(listedPath, "/")
+			if _, err := cpTree.File(strings.TrimPrefix(treePath, checkpointID.Path()+"/")); err != nil {
+				report(ProblemMissingFile, treePath, "listed in session %d of the checkpoint summary", i)
+			}
+		}
+
+		var metadata CommittedMetadata
+		if err := readTreeJSON(sessionTree, paths.MetadataFileName, &metadata); err != nil {
+			report(ProblemInvalidMetadata, sessionPrefix+paths.MetadataFileName, "%v", err)
+		}
+		for _, baseName := range transcriptBaseNames {
+			if missing := missingChunks(sessionTree, baseName); len(missing) > 0 {
+				report(ProblemIncompleteChunks, sessionPrefix+baseName, "missing chunk(s) %s", strings.Join(missing, ", "))
+			}
+		}
+
+		// Transcripts dropped by pruning leave the content hash behind.
+		if !hasTranscript || hashErr != nil || session.Transcript == "" {
+			continue
+		}
+		s.verifyContentHash(ctx, checkpointID, i, sessionTree, session, result, report)
+	}
+}
+
+// verifyContentHash checks a session's content_hash.txt against its transcript.
+func (s *GitStore) verifyContentHash(ctx context.Context, checkpointID id.CheckpointID, sessionIndex int, sessionTree *object.Tree, session SessionFilePaths, result *VerifyResult, report func(ProblemKind, string, string, ...any)) {
+	hashPath := checkpointID.Path() + "/" + strconv.Itoa(sessionIndex) + "/" + paths.ContentHashFileName
+	hashFile, err := sessionTree.File(paths.ContentHashFileName)
+	if err != nil {
+		return
+	}
+	wantHash, err := hashFile.Contents()
+	if err != nil {
+		report(ProblemContentHashMismatch, hashPath, "unreadable: %v", err)
+		return
+	}
+	wantHash = strings.TrimSpace(wantHash)
+
+	content, err := s.ReadSessionContent(ctx, checkpointID, sessionIndex)
+	if errors.Is(err, ErrEncryptedContent) {
+		result.Encrypted++
+		return
+	}
+	if err != nil {
+		report(ProblemUnreadableTranscript, strings.TrimPrefix(session.Transcript, "/"), "%v", err)
+		return
+	}
+	if gotHash := fmt.Sprintf("sha256:%x", sha256.Sum256(content.Transcript)); gotHash != wantHash {
+		report(ProblemContentHashMismatch, hashPath, "expected %s, transcript hashes to %s", wantHash, gotHash)
+	}
+}
+
+// hasTranscriptFiles reports whether a session directory holds a native
+// transcript in any of its formats.
+func hasTranscriptFiles(sessionTree *object.Tree) bool {
+	for _, entry := range sessionTree.Entries {
+		if entry.Name == paths.TranscriptFileNameLegacy || strings.HasPrefix(entry.Name, paths.TranscriptFileName) {
+			return true
+		}
+	}
+	return false
+}
+
+// readTreeJSON reads and parses a JSON file from a tree.
+func readTreeJSON(tree *object.Tree, name string, v any) error {
+	file, err := tree.File(name)
+	if err != nil {
+		return fmt.Errorf("missing %s", name)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if err := json.Unmarshal([]byte(content), v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// missingChunks returns the names of the chunks missing from a file stored
+// as baseName plus .001, .002, ... chunks, i.e. the gaps below the highest
+// chunk present.
+func missingChunks(tree *object.Tree, baseName string) []string {
+	present := make(map[int]bool)
+	highest := -1
+	for _, entry := range tree.Entries {
+		if index := agent.ParseChunkIndex(entry.Name, baseName); index >= 0 {
+			present[index] = true
+			highest = max(highest, index)
+		}
+	}
+	var missing []string
+	for index := 0; index < highest; index++ {
+		if !present[index] {
+			missing = append(missing, agent.ChunkFileName(baseName, index))
+		}
+	}
+	return missing
+}
@@ -0,0 +1,124 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+)
+
+func problemKinds(result *VerifyResult) map[id.CheckpointID][]ProblemKind {
+	kinds := make(map[id.CheckpointID][]ProblemKind)
+	for _, p := range result.Problems {
+		kinds[p.CheckpointID] = append(kinds[p.CheckpointID], p.Kind)
+	}
+	return kinds
+}
+
+func TestVerifyCommitted_Consistent(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	pruned := id.MustCheckpointID("7e0000000002")
+	writePruneCheckpoint(t, store, id.MustCheckpointID("7e0000000001"), "Test")
+	writePruneCheckpoint(t, store, pruned, "Test")
+	if err := store.WriteCommitted(ctx, WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("7e0000000003"),
+		SessionID:    "no-transcript",
+		Strategy:     "manual-commit",
+		Prompts:      []string{"just a prompt"},
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+	if err := store.PruneCommitted(ctx, PruneOptions{DropTranscripts: []id.CheckpointID{pruned}}); err != nil {
+		t.Fatalf("PruneCommitted() error = %v", err)
+	}
+
+	result, err := store.VerifyCommitted(ctx)
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	if result.Checkpoints != 3 || result.Sessions != 3 || len(result.Problems) != 0 {
+		t.Errorf("VerifyCommitted() = %+v, want 3 checkpoints without problems", result)
+	}
+}
+
+func TestVerifyCommitted_EncryptedWithoutKey(t *testing.T) {
+	t.Parallel()
+	repo, _ := setupBranchTestRepo(t)
+	store := NewGitStore(repo)
+	enc := testEncryption(t)
+	store.SetEncryption(enc)
+	writeEncryptedCheckpoint(t, store, id.MustCheckpointID("7e0000000004"))
+
+	store.SetEncryption(&Encryption{Recipients: enc.Recipients})
+	result, err := store.VerifyCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	if result.Encrypted != 1 || len(result.Problems) != 0 {
+		t.Errorf("VerifyCommitted() = %+v, want the encrypted session skipped", result)
+	}
+}
+
+func TestVerifyCommitted_ReportsProblems(t *testing.T) {
+	t.Parallel()
+	store, _ := newTestFilesystemStore(t)
+	checkpoints := map[string]id.CheckpointID{
+		"ok":        id.MustCheckpointID("7f0000000001"),
+		"hash":      id.MustCheckpointID("7f0000000002"),
+		"prompt":    id.MustCheckpointID("7f0000000003"),
+		"metadata":  id.MustCheckpointID("7f0000000004"),
+		"chunks":    id.MustCheckpointID("7f0000000005"),
+		"summaryID": id.MustCheckpointID("7f0000000006"),
+	}
+	for _, cpID := range checkpoints {
+		writePruneCheckpoint(t, store, cpID, "Test")
+	}
+	sessionFile := func(name, file string) string {
+		return filepath.Join(store.Dir(), filepath.FromSlash(checkpoints[name].Path()), "0", file)
+	}
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	writeFile(sessionFile("hash", paths.ContentHashFileName), "sha256:0000")
+	if err := os.Remove(sessionFile("prompt", paths.PromptFileName)); err != nil {
+		t.Fatalf("failed to remove prompt: %v", err)
+	}
+	writeFile(sessionFile("metadata", paths.MetadataFileName), "{not json")
+	writeFile(sessionFile("chunks", paths.TranscriptFileNameCompressed+".002"), "orphan chunk")
+	summaryPath := filepath.Join(store.Dir(), filepath.FromSlash(checkpoints["summaryID"].Path()), paths.MetadataFileName)
+	writeFile(summaryPath, `{"checkpoint_id": "7f0000000001", "sessions": []}`)
+
+	result, err := store.VerifyCommitted(context.Background())
+	if err != nil {
+		t.Fatalf("VerifyCommitted() error = %v", err)
+	}
+	kinds := problemKinds(result)
+	want := map[string]ProblemKind{
+		"hash":      ProblemContentHashMismatch,
+		"prompt":    ProblemMissingFile,
+		"metadata":  ProblemInvalidMetadata,
+		"chunks":    ProblemIncompleteChunks,
+		"summaryID": ProblemInvalidMetadata,
+	}
+	if got := kinds[checkpoints["ok"]]; len(got) != 0 {
+		t.Errorf("consistent checkpoint has problems %v", got)
+	}
+	for name, kind := range want {
+		found := false
+		for _, got := range kinds[checkpoints[name]] {
+			found = found || got == kind
+		}
+		if !found {
+			t.Errorf("%s checkpoint problems = %v, want %s", name, kinds[checkpoints[name]], kind)
+		}
+	}
+}
@@ -25,6 +25,9 @@ func newDebugCmd() *cobra.Command {
 	}
 
 	cmd.AddCommand(newDebugAutoCommitCmd())
+	cmd.AddCommand(newDebugRecordCmd())
+	cmd.AddCommand(newDebugReplayCmd())
+	cmd.AddCommand(newDebugSimulateCmd())
 
 	return cmd
 }
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newDebugRecordCmd() *cobra.Command {
+	var stop bool
+
+	cmd := &cobra.Command{
+		Use:   "record",
+		Short: "Record raw hook payloads for replay",
+		Long: `Records every agent hook invocation in this repository until stopped.
+
+Each invocation is written to .entire/recordings/<session-id>/ as one JSON
+file holding the hook name, its raw stdin payload and arguments, agent
+environment variables, the checked out commit and a timestamp.
+Variables that look like credentials are not recorded.
+
+Hooks can also be recorded without this command by running the agent with
+ENTIRE_RECORD_HOOKS=1.
+
+Replay a recorded session with:
+
+  entire debug replay <session-id>`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runDebugRecord(cmd.OutOrStdout(), stop)
+		},
+	}
+
+	cmd.Flags().BoolVar(&stop, "stop", false, "Stop recording hooks")
+
+	return cmd
+}
+
+func runDebugRecord(w io.Writer, stop bool) error {
+	recordingsDir, err := paths.AbsPath(paths.EntireRecordingsDir)
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	markerPath := filepath.Join(recordingsDir, hookRecordingMarker)
+
+	if stop {
+		if err := os.Remove(markerPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to stop recording: %w", err)
+		}
+		fmt.Fprintf(w, "Stopped recording hooks. Recordings are in %s\n", paths.EntireRecordingsDir)
+		return nil
+	}
+
+	if err := strategy.EnsureEntireGitignore(); err != nil {
+		return fmt.Errorf("failed to update .entire/.gitignore: %w", err)
+	}
+	if err := os.MkdirAll(recordingsDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+	if err := os.WriteFile(markerPath, nil, 0o600); err != nil {
+		return fmt.Errorf("failed to start recording: %w", err)
+	}
+
+	fmt.Fprintf(w, "Recording hooks to %s/<session-id>/\n", paths.EntireRecordingsDir)
+	fmt.Fprintln(w, "Stop with: entire debug record --stop")
+	return nil
+}
+
+func newDebugReplayCmd() *cobra.Command {
+	var keep bool
+
+	cmd := &cobra.Command{
+		Use:   "replay <session-id | recording-dir>",
+		Short: "Replay recorded hook payloads against a scratch clone",
+		Long: `Feeds a recorded sequence of hooks back through Entire's hook handlers.
+
+The repository is cloned into a temporary directory at the commit the first
+hook was recorded on, with the current .entire settings copied in, and each
+recorded payload is passed to its handler in order. The repository itself is
+not touched.
+
+The argument is a session ID from .entire/recordings/ or the path to a
+recording directory, e.g. one attached to a bug report.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDebugReplay(cmd.Context(), cmd.OutOrStdout(), args[0], keep)
+		},
+	}
+
+	cmd.Flags().BoolVar(&keep, "keep", false, "Keep the scratch clone for inspection")
+
+	return cmd
+}
+
+func runDebugReplay(ctx context.Context, w io.Writer, target string, keep bool) error {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+
+	dir := target
+	if info, statErr := os.Stat(target); statErr != nil || !info.IsDir() {
+		dir = filepath.Join(repoRoot, paths.EntireRecordingsDir, target)
+	}
+	recordings, err := loadHookRecordings(dir)
+	if err != nil {
+		return err
+	}
+
+	scratch, err := os.MkdirTemp("", "entire-replay-*")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	if keep {
+		defer fmt.Fprintf(w, "\nScratch clone kept at %s\n", scratch)
+	} else {
+		defer os.RemoveAll(scratch)
+	}
+
+	if err := cloneForReplay(ctx, repoRoot, scratch, recordings[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Replaying %d hook(s) from %s\n\n", len(recordings), dir)
+
+	if err := os.Chdir(scratch); err != nil {
+		return fmt.Errorf("failed to enter scratch clone: %w", err)
+	}
+	paths.ClearRepoRootCache()
+	defer func() {
+		_ = os.Chdir(repoRoot) //nolint:errcheck // best effort restore, the command is exiting
+		paths.ClearRepoRootCache()
+	}()
+
+	var failed int
+	for i, rec := range recordings {
+		fmt.Fprintf(w, "[%d/%d] %s %s: ", i+1, len(recordings), rec.Agent, rec.Hook)
+		if err := replayHook(rec); err != nil {
+			failed++
+			fmt.Fprintf(w, "error: %v\n", err)
+			continue
+		}
+		fmt.Fprintln(w, "ok")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d hook(s) failed during replay", failed, len(recordings))
+	}
+	return nil
+}
+
+// cloneForReplay clones the repository into dir with all local branches, checks
+// out the commit the first hook was recorded on and copies the git identity and
+// .entire settings.
+func cloneForReplay(ctx context.Context, repoRoot, dir string, first HookRecording) error {
+	gitCmds := [][]string{
+		{"clone", "--quiet", "--no-checkout", repoRoot, dir},
+		{"-C", dir, "fetch", "--quiet", "--update-head-ok", "origin", "+refs/heads/*:refs/heads/*"},
+	}
+	switch {
+	case first.Branch != "" && first.Head != "":
+		gitCmds = append(gitCmds, []string{"-C", dir, "checkout", "--quiet", "-B", first.Branch, first.Head})
+	case first.Head != "":
+		gitCmds = append(gitCmds, []string{"-C", dir, "checkout", "--quiet", "--detach", first.Head})
+	default:
+		gitCmds = append(gitCmds, []string{"-C", dir, "checkout", "--quiet"})
+	}
+	for _, args := range gitCmds {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to prepare scratch clone: %s: %w", strings.TrimSpace(string(output)), err)
+		}
+	}
+
+	// Commit as the repository's user; a fresh clone only has global git config.
+	if author, err := GetGitAuthor(); err == nil {
+		for key, value := range map[string]string{"user.name": author.Name, "user.email": author.Email} {
+			cmd := exec.CommandContext(ctx, "git", "-C", dir, "config", key, value)
+			if output, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("failed to configure scratch clone: %s: %w", strings.TrimSpace(string(output)), err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, paths.EntireDir), 0o750); err != nil {
+		return fmt.Errorf("failed to create .entire directory: %w", err)
+	}
+	for _, name := range []string{EntireSettingsFile, EntireSettingsLocalFile} {
+		src := filepath.Join(repoRoot, name)
+		if !fileExists(src) {
+			continue
+		}
+		if err := copyFile(src, filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// replayHook runs a recorded hook through its registered handler, with the
+// recorded payload, arguments and environment.
+func replayHook(rec HookRecording) error {
+	handler := GetHookHandler(rec.Agent, rec.Hook)
+	if handler == nil {
+		// Maybe a plugin hook; plugins are only discovered on demand
+		registerExternalAgents()
+		handler = GetHookHandler(rec.Agent, rec.Hook)
+	}
+	if handler == nil {
+		return errors.New("no handler registered")
+	}
+
+	restoreEnv := setEnv(rec.Env)
+	defer restoreEnv()
+
+	currentHookAgentName = rec.Agent
+	currentHookArgs = rec.Args
+	currentHookInput = []byte(rec.Input)
+	defer func() {
+		currentHookAgentName = ""
+		currentHookArgs = nil
+		currentHookInput = nil
+	}()
+
+	return handler()
+}
+
+// setEnv sets the given environment variables and returns a function that
+// restores their previous values.
+func setEnv(env map[string]string) func() {
+	type previous struct {
+		value string
+		set   bool
+	}
+	saved := make(map[string]previous, len(env))
+	for key, value := range env {
+		old, ok := os.LookupEnv(key)
+		saved[key] = previous{value: old, set: ok}
+		_ = os.Setenv(key, value) //nolint:errcheck // keys come from a recorded environment and are valid
+	}
+	return func() {
+		for key, prev := range saved {
+			if prev.set {
+				_ = os.Setenv(key, prev.value) //nolint:errcheck // restoring a value that was set before
+			} else {
+				_ = os.Unsetenv(key) //nolint:errcheck // restoring an unset variable
+			}
+		}
+	}
+}
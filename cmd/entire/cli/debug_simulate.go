@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/simulator"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/spf13/cobra"
+)
+
+func newDebugSimulateCmd() *cobra.Command {
+	var here bool
+	var keep bool
+
+	cmd := &cobra.Command{
+		Use:   "simulate <scenario.yaml>",
+		Short: "Play a scripted agent session from a YAML scenario",
+		Long: `Plays a scripted session through Entire's hooks, as a real agent would.
+
+The scenario lists prompt turns (with tool calls, file writes and subagent
+tasks) and user commits. Each turn writes a Claude Code or Gemini CLI format
+transcript and sends the same hook payloads the agent would:
+
+  format: claude-code          # or gemini
+  steps:
+    - prompt: Add a greeting
+      files:
+        - path: hello.go
+          content: |
+            package main
+      response: Added hello.go.
+    - commit: Add greeting
+
+By default the scenario is played in a scratch clone of the repository with
+the current .entire settings and Entire's git hooks installed, so the
+repository itself is not touched. Commit steps run the hooks through the
+entire binary on PATH, as they would for a user.
+Use --here to play it in the current repository instead.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDebugSimulate(cmd.Context(), cmd.OutOrStdout(), args[0], here, keep)
+		},
+	}
+
+	cmd.Flags().BoolVar(&here, "here", false, "Play the scenario in the current repository")
+	cmd.Flags().BoolVar(&keep, "keep", false, "Keep the scratch clone for inspection")
+
+	return cmd
+}
+
+func runDebugSimulate(ctx context.Context, w io.Writer, scenarioPath string, here, keep bool) error {
+	scenario, err := simulator.LoadScenario(scenarioPath)
+	if err != nil {
+		return err //nolint:wrapcheck // LoadScenario errors already describe the scenario problem
+	}
+
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+
+	if !here {
+		originalRoot := repoRoot
+		scratch, err := os.MkdirTemp("", "entire-simulate-*")
+		if err != nil {
+			return fmt.Errorf("failed to create scratch directory: %w", err)
+		}
+		if keep {
+			defer fmt.Fprintf(w, "\nScratch clone kept at %s\n", scratch)
+		} else {
+			defer os.RemoveAll(scratch)
+		}
+		if err := cloneForReplay(ctx, repoRoot, scratch, HookRecording{}); err != nil {
+			return err
+		}
+
+		if err := os.Chdir(scratch); err != nil {
+			return fmt.Errorf("failed to enter scratch clone: %w", err)
+		}
+		paths.ClearRepoRootCache()
+		defer func() {
+			_ = os.Chdir(originalRoot) //nolint:errcheck // best effort restore, the command is exiting
+			paths.ClearRepoRootCache()
+		}()
+		repoRoot = scratch
+
+		// A clone doesn't carry .git/hooks over. Install Entire's hooks up
+		// front, rather than relying on the strategy setup done by the first
+		// agent hook, so every commit step adds trailers and condenses.
+		if _, err := strategy.InstallGitHook(true); err != nil {
+			return fmt.Errorf("failed to install git hooks in scratch clone: %w", err)
+		}
+	}
+
+	// Point the simulated agents at the simulator's transcripts, so lookups by
+	// session ID find them as they would find a real agent's.
+	sessionDir, err := (&simulator.SimulatorAgent{}).GetSessionDir(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to get simulator session directory: %w", err)
+	}
+	defer agent.OverrideSessionDir(sessionDir)()
+
+	ag, err := agent.Get(scenario.Format)
+	if err != nil {
+		return fmt.Errorf("unknown scenario format: %w", err)
+	}
+	fmt.Fprintf(w, "Simulating %s session %s\n", ag.Type(), scenario.SessionID)
+
+	result, err := simulator.Run(scenario, repoRoot, &simulateDriver{ctx: ctx, w: w, repoRoot: repoRoot})
+	if err != nil {
+		return fmt.Errorf("simulation failed: %w", err)
+	}
+
+	fmt.Fprintf(w, "\nPlayed %d turn(s) and %d commit(s)\n", result.Turns, result.Commits)
+	fmt.Fprintf(w, "Transcript: %s\n", result.TranscriptPath)
+	return nil
+}
+
+// simulateDriver runs a scenario's hooks in process and its commits with the
+// git CLI, so the repository's git hooks run as they would for a user.
+type simulateDriver struct {
+	ctx      context.Context
+	w        io.Writer
+	repoRoot string
+}
+
+func (d *simulateDriver) RunHook(agentName agent.AgentName, hookName string, payload []byte) error {
+	fmt.Fprintf(d.w, "  hook %s %s\n", agentName, hookName)
+	return replayHook(HookRecording{Agent: agentName, Hook: hookName, Input: string(payload)})
+}
+
+func (d *simulateDriver) Commit(message string, files []string) error {
+	fmt.Fprintf(d.w, "  commit %q\n", message)
+	if len(files) > 0 {
+		add := exec.CommandContext(d.ctx, "git", append([]string{"add", "--"}, files...)...)
+		add.Dir = d.repoRoot
+		if output, err := add.CombinedOutput(); err != nil {
+			return fmt.Errorf("git add failed: %s: %w", strings.TrimSpace(string(output)), err)
+		}
+	}
+	commit := exec.CommandContext(d.ctx, "git", "commit", "--quiet", "-m", message)
+	commit.Dir = d.repoRoot
+	if output, err := commit.CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit failed: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
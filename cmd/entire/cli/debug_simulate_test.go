@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+)
+
+func TestRunDebugSimulate(t *testing.T) {
+	repoDir := t.TempDir()
+	testutil.InitRepo(t, repoDir)
+	testutil.WriteFile(t, repoDir, "main.go", "package main\n")
+	testutil.GitAdd(t, repoDir, "main.go")
+	testutil.GitCommit(t, repoDir, "initial")
+	t.Chdir(repoDir)
+	paths.ClearRepoRootCache()
+	head := testutil.GetHeadHash(t, repoDir)
+
+	scenario := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := os.WriteFile(scenario, []byte(`
+steps:
+  - prompt: Add a greeting
+    files:
+      - path: hello.go
+        content: "package main\n"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	if err := runDebugSimulate(context.Background(), &stdout, scenario, false, false); err != nil {
+		t.Fatalf("runDebugSimulate() error = %v\n%s", err, stdout.String())
+	}
+
+	out := stdout.String()
+	for _, want := range []string{"hook claude-code user-prompt-submit", "hook claude-code stop", "Played 1 turn(s) and 0 commit(s)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// The scenario runs in a scratch clone; the repository is untouched.
+	if got := testutil.GetHeadHash(t, repoDir); got != head {
+		t.Errorf("HEAD moved from %s to %s", head, got)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "hello.go")); !os.IsNotExist(err) {
+		t.Error("hello.go should not be written to the repository")
+	}
+	if wd, err := os.Getwd(); err != nil || wd != repoDir {
+		t.Errorf("working directory = %q, want %q restored", wd, repoDir)
+	}
+}
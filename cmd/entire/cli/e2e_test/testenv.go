@@ -19,6 +19,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/format/config"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/klauspost/compress/zstd"
 )
 
 // TestEnv manages an isolated test environment for E2E tests with real agent calls.
@@ -688,6 +689,27 @@ func (env *TestEnv) ReadFileFromBranch(branchName, filePath string) (string, boo
 	return content, true
 }
 
+// readTranscriptFromBranch reads a session transcript from sessionDir on the
+// branch, decompressing full.jsonl.zst or falling back to an uncompressed full.jsonl.
+func (env *TestEnv) readTranscriptFromBranch(branchName, sessionDir string) (string, bool) {
+	env.T.Helper()
+
+	compressed, found := env.ReadFileFromBranch(branchName, sessionDir+"/full.jsonl.zst")
+	if !found {
+		return env.ReadFileFromBranch(branchName, sessionDir+"/full.jsonl")
+	}
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		env.T.Fatalf("failed to create zstd decoder: %v", err)
+	}
+	defer dec.Close()
+	content, err := dec.DecodeAll([]byte(compressed), nil)
+	if err != nil {
+		env.T.Fatalf("failed to decompress transcript: %v", err)
+	}
+	return string(content), true
+}
+
 // CheckpointValidation contains expected values for checkpoint validation.
 type CheckpointValidation struct {
 	// CheckpointID is the expected checkpoint ID
@@ -702,7 +724,7 @@ type CheckpointValidation struct {
 	// ExpectedPrompts are strings that should appear in prompt.txt (optional)
 	ExpectedPrompts []string
 
-	// ExpectedTranscriptContent are strings that should appear in full.jsonl (optional)
+	// ExpectedTranscriptContent are strings that should appear in the transcript (optional)
 	ExpectedTranscriptContent []string
 }
 
@@ -783,10 +805,9 @@ func (env *TestEnv) ValidateCheckpoint(v CheckpointValidation) {
 	}
 
 	// Validate transcript is valid JSONL
-	transcriptPath := shardedPath + "/0/full.jsonl"
-	transcriptContent, found := env.ReadFileFromBranch(metadataBranch, transcriptPath)
+	transcriptContent, found := env.readTranscriptFromBranch(metadataBranch, shardedPath+"/0")
 	if !found {
-		env.T.Errorf("Transcript not found at %s", transcriptPath)
+		env.T.Errorf("Transcript not found in %s/0", shardedPath)
 	} else {
 		// Check each line is valid JSON
 		lines := strings.Split(transcriptContent, "\n")
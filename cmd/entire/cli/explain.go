@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,7 +20,6 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 	"github.com/entireio/cli/cmd/entire/cli/summarize"
-	"github.com/entireio/cli/cmd/entire/cli/trailers"
 	"github.com/entireio/cli/cmd/entire/cli/transcript"
 
 	"github.com/go-git/go-git/v5"
@@ -201,10 +201,13 @@ func runExplainCheckpoint(w, errW io.Writer, checkpointIDPrefix string, noPager,
 		return fmt.Errorf("not a git repository: %w", err)
 	}
 
-	store := checkpoint.NewGitStore(repo)
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
 
 	// First, try to find in committed checkpoints by checkpoint ID prefix
-	committed, err := store.ListCommitted(context.Background())
+	committed, err := strategy.ListCommittedCheckpoints(context.Background(), repo, store)
 	if err != nil {
 		return fmt.Errorf("failed to list checkpoints: %w", err)
 	}
@@ -299,7 +302,7 @@ func runExplainCheckpoint(w, errW io.Writer, checkpointIDPrefix string, noPager,
 // generateCheckpointSummary generates an AI summary for a checkpoint and persists it.
 // The summary is generated from the scoped transcript (only this checkpoint's portion),
 // not the entire session transcript.
-func generateCheckpointSummary(w, _ io.Writer, store *checkpoint.GitStore, checkpointID id.CheckpointID, cpSummary *checkpoint.CheckpointSummary, content *checkpoint.SessionContent, force bool) error {
+func generateCheckpointSummary(w, _ io.Writer, store checkpoint.Store, checkpointID id.CheckpointID, cpSummary *checkpoint.CheckpointSummary, content *checkpoint.SessionContent, force bool) error {
 	// Check if summary already exists
 	if content.Metadata.Summary != nil && !force {
 		return fmt.Errorf("checkpoint %s already has a summary (use --force to regenerate)", checkpointID)
@@ -339,7 +342,7 @@ func generateCheckpointSummary(w, _ io.Writer, store *checkpoint.GitStore, check
 // Searches ALL shadow branches, not just the one for current HEAD, to find checkpoints
 // created from different base commits (e.g., if HEAD advanced since session start).
 // The writer w is used for raw transcript output to bypass the pager.
-func explainTemporaryCheckpoint(w io.Writer, repo *git.Repository, store *checkpoint.GitStore, shaPrefix string, verbose, full, rawTranscript bool) (string, bool) {
+func explainTemporaryCheckpoint(w io.Writer, repo *git.Repository, store checkpoint.Store, shaPrefix string, verbose, full, rawTranscript bool) (string, bool) {
 	// List temporary checkpoints from ALL shadow branches
 	// This ensures we find checkpoints even if HEAD has advanced since the session started
 	tempCheckpoints, err := store.ListAllTemporaryCheckpoints(context.Background(), "", branchCheckpointsLimit)
@@ -455,7 +458,7 @@ func explainTemporaryCheckpoint(w io.Writer, repo *git.Repository, store *checkp
 }
 
 // getAssociatedCommits finds git commits that reference the given checkpoint ID.
-// Searches commits on the current branch for Entire-Checkpoint trailer matches.
+// Searches commits on the current branch for Entire-Checkpoint trailer or note matches.
 // When searchAll is true, uses full DAG walk with no depth limit (may be slow).
 // This finds checkpoint commits on merged feature branches (second parents of merges).
 func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, searchAll bool) ([]associatedCommit, error) {
@@ -465,7 +468,7 @@ func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, se
 	}
 
 	commits := []associatedCommit{} // Initialize as empty slice, not nil (nil means "not searched")
-	targetID := checkpointID.String()
+	notes := strategy.LoadCheckpointNotes(repo)
 
 	collectCommit := func(c *object.Commit) {
 		fullSHA := c.Hash.String()
@@ -495,8 +498,7 @@ func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, se
 		defer iter.Close()
 
 		err = iter.ForEach(func(c *object.Commit) error {
-			cpID, found := trailers.ParseCheckpoint(c.Message)
-			if found && cpID.String() == targetID {
+			if slices.Contains(notes.ParseAllCheckpoints(c), checkpointID) {
 				collectCommit(c)
 			}
 			return nil
@@ -513,8 +515,7 @@ func getAssociatedCommits(repo *git.Repository, checkpointID id.CheckpointID, se
 				return errStopIteration
 			}
 
-			cpID, found := trailers.ParseCheckpoint(c.Message)
-			if found && cpID.String() == targetID {
+			if slices.Contains(notes.ParseAllCheckpoints(c), checkpointID) {
 				collectCommit(c)
 			}
 			return nil
@@ -873,10 +874,13 @@ func walkFirstParentCommits(repo *git.Repository, from plumbing.Hash, limit int,
 //   - On default branch (main/master): show all checkpoints in history (up to limit)
 //   - Includes both committed checkpoints (entire/checkpoints/v1) and temporary checkpoints (shadow branches)
 func getBranchCheckpoints(repo *git.Repository, limit int) ([]strategy.RewindPoint, error) {
-	store := checkpoint.NewGitStore(repo)
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
 
-	// Get all committed checkpoints for lookup
-	committedInfos, err := store.ListCommitted(context.Background())
+	// Get all committed checkpoints for lookup (from the checkpoint index when present)
+	committedInfos, err := strategy.ListCommittedCheckpoints(context.Background(), repo, store)
 	if err != nil {
 		committedInfos = nil // Continue without committed checkpoints
 	}
@@ -903,8 +907,9 @@ func getBranchCheckpoints(repo *git.Repository, limit int) ([]strategy.RewindPoi
 
 	var points []strategy.RewindPoint
 
+	notes := strategy.LoadCheckpointNotes(repo)
 	collectCheckpoint := func(c *object.Commit) {
-		cpID, found := trailers.ParseCheckpoint(c.Message)
+		cpID, found := notes.ParseCheckpoint(c)
 		if !found {
 			return
 		}
@@ -1000,7 +1005,7 @@ func getBranchCheckpoints(repo *git.Repository, limit int) ([]strategy.RewindPoi
 // whose base commit is reachable from the given HEAD hash and that belong to this worktree.
 // For default branches, all shadow branches for this worktree are included.
 // For feature branches, only shadow branches whose base commit is in HEAD's history are included.
-func getReachableTemporaryCheckpoints(repo *git.Repository, store *checkpoint.GitStore, headHash plumbing.Hash, isOnDefault bool, limit int) []strategy.RewindPoint {
+func getReachableTemporaryCheckpoints(repo *git.Repository, store checkpoint.Store, headHash plumbing.Hash, isOnDefault bool, limit int) []strategy.RewindPoint {
 	var points []strategy.RewindPoint
 
 	// Compute current worktree's hash for filtering shadow branches
@@ -1166,11 +1171,11 @@ func runExplainCommit(w io.Writer, commitRef string, noPager, verbose, full, sea
 		return fmt.Errorf("failed to get commit: %w", err)
 	}
 
-	// Extract Entire-Checkpoint trailer
-	checkpointID, hasCheckpoint := trailers.ParseCheckpoint(commit.Message)
+	// Extract Entire-Checkpoint trailer, or the checkpoint note
+	checkpointID, hasCheckpoint := strategy.LoadCheckpointNotes(repo).ParseCheckpoint(commit)
 	if !hasCheckpoint {
 		fmt.Fprintln(w, "No associated Entire checkpoint")
-		fmt.Fprintf(w, "\nCommit %s does not have an Entire-Checkpoint trailer.\n", hash.String()[:7])
+		fmt.Fprintf(w, "\nCommit %s does not have an Entire-Checkpoint trailer or note.\n", hash.String()[:7])
 		fmt.Fprintln(w, "This commit was not created during an Entire session, or the trailer was removed.")
 		return nil
 	}
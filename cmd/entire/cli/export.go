@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/spf13/cobra"
+)
+
+// defaultExportFile is where `entire export` writes the bundle by default.
+const defaultExportFile = "entire-checkpoints.tar.gz"
+
+func newExportCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export <checkpoint-id|commit|commit-range>...",
+		Short: "Export checkpoints as a portable bundle",
+		Long: `Export writes committed checkpoints to a self-contained bundle: metadata,
+transcripts, prompts, context, summaries and the SHAs of the commits linked to
+each checkpoint. Use 'entire import' to add the bundle to another repository,
+e.g. when moving a project to another host, attaching session history to an
+incident report, or carrying context from a prototype into the real project.
+
+Each argument is a checkpoint ID, a commit, or a commit range such as
+main..feature; commits select the checkpoints their Entire-Checkpoint
+trailers reference.
+
+Transcripts are exported decrypted, so an encrypted checkpoint can only be
+exported with encryption.identity_file configured. Treat the bundle like the
+transcripts it contains.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runExport(cmd.Context(), cmd.OutOrStdout(), args, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", defaultExportFile, "File to write the bundle to, or - for stdout")
+
+	return cmd
+}
+
+func runExport(ctx context.Context, w io.Writer, args []string, output string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+
+	checkpointIDs, err := strategy.ResolveExportCheckpoints(ctx, repo, args)
+	if err != nil {
+		return err //nolint:wrapcheck // Already names the argument
+	}
+
+	if output == "-" {
+		return strategy.ExportBundle(ctx, repo, store, checkpointIDs, w) //nolint:wrapcheck // Already descriptive
+	}
+
+	// Write to a temporary file first so a failed export doesn't leave a
+	// truncated bundle behind.
+	tmp, err := os.CreateTemp(filepath.Dir(output), ".entire-export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }() //nolint:errcheck // Already renamed on success
+	if err := strategy.ExportBundle(ctx, repo, store, checkpointIDs, tmp); err != nil {
+		_ = tmp.Close()
+		return err //nolint:wrapcheck // Already descriptive
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	fmt.Fprintf(w, "Exported %d checkpoint(s) to %s\n", len(checkpointIDs), output)
+	return nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+)
+
+func TestRunExportImport(t *testing.T) {
+	ctx := context.Background()
+	source := setupPruneTestRepo(t, `{"strategy": "manual-commit"}`)
+	cpID := id.MustCheckpointID("e10000000001")
+	writePruneTestCheckpoint(t, source, cpID)
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+
+	var stdout bytes.Buffer
+	if err := runExport(ctx, &stdout, []string{cpID.String()}, bundlePath); err != nil {
+		t.Fatalf("runExport() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Exported 1 checkpoint(s)") {
+		t.Errorf("export output = %q", stdout.String())
+	}
+
+	err := runExport(ctx, &stdout, []string{"e10000000002"}, filepath.Join(filepath.Dir(bundlePath), "missing.tar.gz"))
+	if err == nil || !strings.Contains(err.Error(), "checkpoint not found") {
+		t.Errorf("runExport() of a missing checkpoint error = %v, want checkpoint not found", err)
+	}
+
+	target := setupPruneTestRepo(t, `{"strategy": "manual-commit"}`)
+	for _, want := range []string{"Imported 1 checkpoint(s).", "Imported 0 checkpoint(s), skipped 1 that already exist."} {
+		stdout.Reset()
+		if err := runImport(ctx, &stdout, nil, bundlePath); err != nil {
+			t.Fatalf("runImport() error = %v", err)
+		}
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("import output = %q, want %q", stdout.String(), want)
+		}
+	}
+	content, err := target.ReadSessionContent(ctx, cpID, 0)
+	if err != nil {
+		t.Fatalf("ReadSessionContent() error = %v", err)
+	}
+	if content.Prompts != "do the thing" {
+		t.Errorf("imported prompts = %q, want %q", content.Prompts, "do the thing")
+	}
+
+	// The failed export leaves nothing behind.
+	if entries, err := os.ReadDir(filepath.Dir(bundlePath)); err != nil || len(entries) != 1 {
+		t.Errorf("bundle dir entries = %v, %v; want only the bundle", entries, err)
+	}
+}
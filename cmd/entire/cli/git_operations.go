@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 
@@ -25,6 +27,19 @@ func openRepository() (*git.Repository, error) {
 	return repo, nil
 }
 
+// openCheckpointStore opens the checkpoint store configured for the current repository.
+func openCheckpointStore() (checkpoint.Store, error) {
+	repo, err := openRepository()
+	if err != nil {
+		return nil, err
+	}
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+	return store, nil
+}
+
 // GitAuthor represents the git user configuration
 type GitAuthor struct {
 	Name  string
@@ -355,7 +370,8 @@ func FetchAndCheckoutRemoteBranch(branchName string) error {
 	return CheckoutBranch(branchName)
 }
 
-// FetchMetadataBranch fetches the entire/checkpoints/v1 branch from origin and creates/updates the local branch.
+// FetchMetadataBranch fetches the entire/checkpoints/v1 branch from origin and creates/updates the local branch,
+// along with the refs/notes/entire checkpoint notes.
 // This is used when the metadata branch exists on remote but not locally.
 // Uses git CLI instead of go-git for fetch because go-git doesn't use credential helpers,
 // which breaks HTTPS URLs that require authentication.
@@ -393,5 +409,12 @@ func FetchMetadataBranch() error {
 		return fmt.Errorf("failed to create local %s branch: %w", branchName, err)
 	}
 
+	// Commits may be linked with notes rather than trailers
+	if err := strategy.FetchCheckpointNotes(context.Background(), "origin"); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to fetch checkpoint notes: %v\n", err)
+	}
+
+	strategy.TryUpdateCheckpointIndex(context.Background())
+
 	return nil
 }
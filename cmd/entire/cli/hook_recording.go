@@ -0,0 +1,201 @@
+// hook_recording.go records raw hook invocations so a misbehaving hook can be
+// reproduced later with `entire debug replay`.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/validation"
+)
+
+const (
+	// recordHooksEnvVar enables recording for hooks run with it set to "1" or "true",
+	// without `entire debug record`.
+	recordHooksEnvVar = "ENTIRE_RECORD_HOOKS"
+
+	// hookRecordingMarker is created in the recordings directory by `entire debug record`
+	// and removed by `entire debug record --stop`.
+	hookRecordingMarker = "active"
+)
+
+// recordedEnvPrefixes selects the environment variables saved with a recording.
+// Variables that look like credentials are dropped even if they match.
+var recordedEnvPrefixes = []string{"ENTIRE_", "CLAUDE_", "GEMINI_", "CURSOR_", "CODEX_"}
+
+// HookRecording is one recorded hook invocation, stored as
+// .entire/recordings/<session-id>/<seq>-<agent>-<hook>.json.
+type HookRecording struct {
+	Agent     agent.AgentName   `json:"agent"`
+	Hook      string            `json:"hook"`
+	Args      []string          `json:"args,omitempty"`
+	Input     string            `json:"input"`
+	Env       map[string]string `json:"env,omitempty"`
+	Branch    string            `json:"branch,omitempty"`
+	Head      string            `json:"head,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// currentHookInput holds the payload of the current hook once it has been read
+// for recording or supplied by replay. hookInputReader serves it instead of stdin.
+var currentHookInput []byte
+
+// isHookRecordingEnabled reports whether hook invocations should be recorded.
+func isHookRecordingEnabled() bool {
+	switch strings.ToLower(os.Getenv(recordHooksEnvVar)) {
+	case "1", "true":
+		return true
+	}
+	markerPath, err := paths.AbsPath(filepath.Join(paths.EntireRecordingsDir, hookRecordingMarker))
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(markerPath)
+	return err == nil
+}
+
+// recordHookInvocation reads the current hook's payload, keeps it for the handler
+// in currentHookInput and writes it to the session's recordings directory.
+func recordHookInvocation(agentName agent.AgentName, hookName string, args []string) error {
+	input, err := io.ReadAll(hookInputReader())
+	currentHookInput = input
+	if err != nil {
+		return fmt.Errorf("failed to read hook input: %w", err)
+	}
+
+	rec := HookRecording{
+		Agent:     agentName,
+		Hook:      hookName,
+		Args:      args,
+		Input:     string(input),
+		Env:       recordedEnv(os.Environ()),
+		Timestamp: time.Now().UTC(),
+	}
+	if repo, err := openRepository(); err == nil {
+		if head, err := repo.Head(); err == nil {
+			rec.Head = head.Hash().String()
+			if head.Name().IsBranch() {
+				rec.Branch = head.Name().Short()
+			}
+		}
+	}
+
+	dir, err := paths.AbsPath(filepath.Join(paths.EntireRecordingsDir, recordingSessionID(input)))
+	if err != nil {
+		return fmt.Errorf("failed to resolve recordings directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create recordings directory: %w", err)
+	}
+	existing, err := recordingFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	data, err := jsonutil.MarshalIndentWithNewline(rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+	name := fmt.Sprintf("%04d-%s-%s.json", len(existing)+1, agentName, hookName)
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// recordedEnv returns the environment variables worth keeping with a recording.
+func recordedEnv(environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == recordHooksEnvVar || !hasRecordedEnvPrefix(key) {
+			continue
+		}
+		upper := strings.ToUpper(key)
+		if strings.Contains(upper, "KEY") || strings.Contains(upper, "TOKEN") ||
+			strings.Contains(upper, "SECRET") || strings.Contains(upper, "PASSWORD") {
+			continue
+		}
+		env[key] = value
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
+
+func hasRecordedEnvPrefix(key string) bool {
+	for _, prefix := range recordedEnvPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordingSessionID picks the session ID out of a raw hook payload, which
+// agents name differently. Payloads without a usable ID are grouped as "unknown".
+func recordingSessionID(input []byte) string {
+	var payload map[string]any
+	if err := json.Unmarshal(input, &payload); err != nil {
+		return unknownSessionID
+	}
+	for _, key := range []string{"session_id", "sessionId", "conversation_id"} {
+		id, ok := payload[key].(string)
+		if ok && id != "" && validation.ValidateSessionID(id) == nil && id != "." && id != ".." {
+			return id
+		}
+	}
+	return unknownSessionID
+}
+
+// recordingFiles returns the recording files in dir in the order they were recorded.
+func recordingFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recordings directory: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".json") {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// loadHookRecordings reads every recording in dir in the order they were recorded.
+func loadHookRecordings(dir string) ([]HookRecording, error) {
+	files, err := recordingFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no recordings found in " + dir)
+	}
+
+	recordings := make([]HookRecording, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file) //nolint:gosec // file is from the recordings directory listing
+		if err != nil {
+			return nil, fmt.Errorf("failed to read recording: %w", err)
+		}
+		var rec HookRecording
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse recording %s: %w", filepath.Base(file), err)
+		}
+		recordings = append(recordings, rec)
+	}
+	return recordings, nil
+}
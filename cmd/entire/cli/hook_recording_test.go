@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/testutil"
+)
+
+func TestRecordingSessionID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"session_id", `{"session_id":"abc-123"}`, "abc-123"},
+		{"camel case", `{"sessionId":"def"}`, "def"},
+		{"cursor conversation", `{"conversation_id":"conv-1"}`, "conv-1"},
+		{"path separator", `{"session_id":"../etc"}`, unknownSessionID},
+		{"dot dot", `{"session_id":".."}`, unknownSessionID},
+		{"missing", `{"prompt":"hi"}`, unknownSessionID},
+		{"not json", `plain text`, unknownSessionID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := recordingSessionID([]byte(tt.input)); got != tt.want {
+				t.Errorf("recordingSessionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordedEnv(t *testing.T) {
+	t.Parallel()
+
+	env := recordedEnv([]string{
+		"ENTIRE_LOG_LEVEL=debug",
+		"CLAUDE_PROJECT_DIR=/repo",
+		"CLAUDE_CODE_OAUTH_TOKEN=secret",
+		"GEMINI_API_KEY=secret",
+		"ENTIRE_RECORD_HOOKS=1",
+		"HOME=/home/user",
+	})
+
+	if len(env) != 2 || env["ENTIRE_LOG_LEVEL"] != "debug" || env["CLAUDE_PROJECT_DIR"] != "/repo" {
+		t.Errorf("recordedEnv() = %v, want only ENTIRE_LOG_LEVEL and CLAUDE_PROJECT_DIR", env)
+	}
+}
+
+func TestRecordHookInvocation(t *testing.T) {
+	setupTestRepo(t)
+	t.Cleanup(func() {
+		currentHookArgs = nil
+		currentHookInput = nil
+	})
+
+	payloads := []string{`{"session_id":"sess-1","prompt":"one"}`, `{"session_id":"sess-1","prompt":"two"}`}
+	for _, payload := range payloads {
+		// Payloads passed as arguments avoid reading the test's stdin.
+		currentHookArgs = []string{payload}
+		currentHookInput = nil
+		if err := recordHookInvocation(agent.AgentNameCodex, "turn-complete", currentHookArgs); err != nil {
+			t.Fatalf("recordHookInvocation() error = %v", err)
+		}
+
+		// The handler still sees the payload after it was read for recording.
+		data, err := io.ReadAll(hookInputReader())
+		if err != nil {
+			t.Fatalf("reading hook input: %v", err)
+		}
+		if string(data) != payload {
+			t.Errorf("hookInputReader() = %q, want %q", data, payload)
+		}
+	}
+
+	recordings, err := loadHookRecordings(filepath.Join(paths.EntireRecordingsDir, "sess-1"))
+	if err != nil {
+		t.Fatalf("loadHookRecordings() error = %v", err)
+	}
+	if len(recordings) != 2 {
+		t.Fatalf("got %d recordings, want 2", len(recordings))
+	}
+	for i, rec := range recordings {
+		if rec.Agent != agent.AgentNameCodex || rec.Hook != "turn-complete" {
+			t.Errorf("recording %d = %s/%s, want codex/turn-complete", i, rec.Agent, rec.Hook)
+		}
+		if rec.Input != payloads[i] {
+			t.Errorf("recording %d input = %q, want %q", i, rec.Input, payloads[i])
+		}
+	}
+}
+
+func TestIsHookRecordingEnabled(t *testing.T) {
+	setupTestRepo(t)
+	t.Setenv(recordHooksEnvVar, "")
+
+	if isHookRecordingEnabled() {
+		t.Fatal("recording should be off by default")
+	}
+
+	var stdout bytes.Buffer
+	if err := runDebugRecord(&stdout, false); err != nil {
+		t.Fatalf("runDebugRecord() error = %v", err)
+	}
+	if !isHookRecordingEnabled() {
+		t.Error("recording should be on after `debug record`")
+	}
+
+	if err := runDebugRecord(&stdout, true); err != nil {
+		t.Fatalf("runDebugRecord(stop) error = %v", err)
+	}
+	if isHookRecordingEnabled() {
+		t.Error("recording should be off after `debug record --stop`")
+	}
+
+	t.Setenv(recordHooksEnvVar, "1")
+	if !isHookRecordingEnabled() {
+		t.Errorf("recording should be on with %s=1", recordHooksEnvVar)
+	}
+}
+
+func TestRunDebugReplay(t *testing.T) {
+	repoDir := t.TempDir()
+	testutil.InitRepo(t, repoDir)
+	testutil.WriteFile(t, repoDir, "main.go", "package main\n")
+	testutil.GitAdd(t, repoDir, "main.go")
+	testutil.GitCommit(t, repoDir, "initial")
+	t.Chdir(repoDir)
+	paths.ClearRepoRootCache()
+
+	const testAgent agent.AgentName = "replay-test"
+	var replayed []string
+	var replayedDirs []string
+	RegisterHookHandler(testAgent, "stop", func() error {
+		data, err := io.ReadAll(hookInputReader())
+		if err != nil {
+			return err
+		}
+		replayed = append(replayed, string(data)+" "+os.Getenv("ENTIRE_REPLAY_TEST"))
+		root, err := paths.RepoRoot()
+		if err != nil {
+			return err
+		}
+		replayedDirs = append(replayedDirs, root)
+		return nil
+	})
+	t.Cleanup(func() { delete(hookRegistry, testAgent) })
+
+	recordingDir := filepath.Join(repoDir, paths.EntireRecordingsDir, "sess-1")
+	if err := os.MkdirAll(recordingDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i, input := range []string{"first", "second"} {
+		rec := `{"agent":"replay-test","hook":"stop","input":"` + input + `","env":{"ENTIRE_REPLAY_TEST":"set"},"head":"` +
+			testutil.GetHeadHash(t, repoDir) + `"}`
+		name := filepath.Join(recordingDir, []string{"0001-a.json", "0002-b.json"}[i])
+		if err := os.WriteFile(name, []byte(rec), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout bytes.Buffer
+	if err := runDebugReplay(context.Background(), &stdout, "sess-1", false); err != nil {
+		t.Fatalf("runDebugReplay() error = %v\n%s", err, stdout.String())
+	}
+
+	if strings.Join(replayed, ",") != "first set,second set" {
+		t.Errorf("replayed = %v, want payloads in order with recorded env", replayed)
+	}
+	for _, dir := range replayedDirs {
+		if dir == repoDir {
+			t.Error("replay should run against a scratch clone, not the repository")
+		}
+	}
+	if !strings.Contains(stdout.String(), "[2/2] replay-test stop: ok") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+	if os.Getenv("ENTIRE_REPLAY_TEST") != "" {
+		t.Error("recorded environment should be restored after replay")
+	}
+}
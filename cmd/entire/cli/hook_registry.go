@@ -1,14 +1,20 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	"github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	"github.com/entireio/cli/cmd/entire/cli/agent/cursor"
 	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
@@ -40,7 +46,7 @@ func GetHookHandler(agentName agent.AgentName, hookName string) HookHandlerFunc
 	return nil
 }
 
-// init registers Claude Code hook handlers.
+// init registers Claude Code, Gemini CLI, Cursor and Codex hook handlers.
 // Each handler checks if Entire is enabled before executing.
 //
 //nolint:gochecknoinits // Hook handler registration at startup is the intended pattern
@@ -190,6 +196,56 @@ func init() {
 		}
 		return handleGeminiNotification()
 	})
+
+	// Register Cursor handlers
+	RegisterHookHandler(agent.AgentNameCursor, cursor.HookNameSessionStart, func() error {
+		enabled, err := IsEnabled()
+		if err == nil && !enabled {
+			return nil
+		}
+		return handleCursorSessionStart()
+	})
+
+	RegisterHookHandler(agent.AgentNameCursor, cursor.HookNameSessionEnd, func() error {
+		enabled, err := IsEnabled()
+		if err == nil && !enabled {
+			return nil
+		}
+		return handleCursorSessionEnd()
+	})
+
+	RegisterHookHandler(agent.AgentNameCursor, cursor.HookNameBeforeSubmitPrompt, func() error {
+		enabled, err := IsEnabled()
+		if err == nil && !enabled {
+			return nil
+		}
+		return handleCursorBeforeSubmitPrompt()
+	})
+
+	RegisterHookHandler(agent.AgentNameCursor, cursor.HookNameStop, func() error {
+		enabled, err := IsEnabled()
+		if err == nil && !enabled {
+			return nil
+		}
+		return handleCursorStop()
+	})
+
+	RegisterHookHandler(agent.AgentNameCursor, cursor.HookNameAfterFileEdit, func() error {
+		enabled, err := IsEnabled()
+		if err == nil && !enabled {
+			return nil
+		}
+		return handleCursorAfterFileEdit()
+	})
+
+	// Register Codex handlers
+	RegisterHookHandler(agent.AgentNameCodex, codex.HookNameTurnComplete, func() error {
+		enabled, err := IsEnabled()
+		if err == nil && !enabled {
+			return nil
+		}
+		return handleCodexTurnComplete()
+	})
 }
 
 // agentHookLogCleanup stores the cleanup function for agent hook logging.
@@ -201,6 +257,23 @@ var agentHookLogCleanup func()
 // This allows handlers to know which agent invoked the hook without guessing.
 var currentHookAgentName agent.AgentName
 
+// currentHookArgs stores the positional arguments of the currently executing hook.
+// Most agents write hook payloads to stdin, but some (e.g., Codex) pass them as arguments.
+var currentHookArgs []string
+
+// hookInputReader returns the reader the current hook's payload should be parsed from.
+// A payload already read for recording (or supplied by replay) is used first,
+// then the last positional argument when present, otherwise stdin.
+func hookInputReader() io.Reader {
+	if currentHookInput != nil {
+		return bytes.NewReader(currentHookInput)
+	}
+	if n := len(currentHookArgs); n > 0 {
+		return strings.NewReader(currentHookArgs[n-1])
+	}
+	return os.Stdin
+}
+
 // GetCurrentHookAgent returns the agent for the currently executing hook.
 // Returns the agent based on the hook command structure (e.g., "entire hooks claude-code ...")
 // rather than guessing from directory presence.
@@ -247,13 +320,13 @@ func newAgentHooksCmd(agentName agent.AgentName, handler agent.HookHandler) *cob
 
 // getHookType returns the hook type based on the hook name.
 // Returns "subagent" for task-related hooks (pre-task, post-task, post-todo),
-// "tool" for tool-related hooks (before-tool, after-tool),
+// "tool" for tool-related hooks (before-tool, after-tool, after-file-edit),
 // "agent" for all other agent hooks.
 func getHookType(hookName string) string {
 	switch hookName {
 	case claudecode.HookNamePreTask, claudecode.HookNamePostTask, claudecode.HookNamePostTodo:
 		return "subagent"
-	case geminicli.HookNameBeforeTool, geminicli.HookNameAfterTool:
+	case geminicli.HookNameBeforeTool, geminicli.HookNameAfterTool, cursor.HookNameAfterFileEdit:
 		return "tool"
 	default:
 		return "agent"
@@ -267,7 +340,7 @@ func newAgentHookVerbCmdWithLogging(agentName agent.AgentName, hookName string)
 		Use:    hookName,
 		Hidden: true,
 		Short:  "Called on " + hookName,
-		RunE: func(_ *cobra.Command, _ []string) error {
+		RunE: func(_ *cobra.Command, args []string) error {
 			// Skip silently if not in a git repository - hooks shouldn't prevent the agent from working
 			if _, err := paths.RepoRoot(); err != nil {
 				return nil
@@ -302,7 +375,21 @@ func newAgentHookVerbCmdWithLogging(agentName agent.AgentName, hookName string)
 			// Set the current hook agent so handlers can retrieve it
 			// without guessing from directory presence
 			currentHookAgentName = agentName
-			defer func() { currentHookAgentName = "" }()
+			currentHookArgs = args
+			defer func() {
+				currentHookAgentName = ""
+				currentHookArgs = nil
+				currentHookInput = nil
+			}()
+
+			if isHookRecordingEnabled() {
+				if err := recordHookInvocation(agentName, hookName, args); err != nil {
+					logging.Warn(ctx, "failed to record hook",
+						slog.String("hook", hookName),
+						slog.String("error", err.Error()),
+					)
+				}
+			}
 
 			hookErr := handler()
 
@@ -245,7 +245,7 @@ func handleSessionStartCommon() error {
 		return fmt.Errorf("failed to get agent: %w", err)
 	}
 
-	input, err := ag.ParseHookInput(agent.HookSessionStart, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookSessionStart, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -278,21 +278,29 @@ func handleSessionStartCommon() error {
 		return err
 	}
 
-	// Fire EventSessionStart for the current session (if state exists).
-	// This handles ENDED → IDLE (re-entering a session).
-	// TODO(ENT-221): dispatch ActionWarnStaleSession for ACTIVE sessions.
-	if state, loadErr := strategy.LoadSessionState(input.SessionID); loadErr != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to load session state on start: %v\n", loadErr)
-	} else if state != nil {
-		strategy.TransitionAndLog(state, session.EventSessionStart, session.TransitionContext{})
-		if saveErr := strategy.SaveSessionState(state); saveErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to update session state on start: %v\n", saveErr)
-		}
-	}
+	transitionSessionStart(input.SessionID)
 
 	return nil
 }
 
+// transitionSessionStart fires EventSessionStart for the session (if state exists).
+// This handles ENDED → IDLE (re-entering a session).
+// TODO(ENT-221): dispatch ActionWarnStaleSession for ACTIVE sessions.
+func transitionSessionStart(sessionID string) {
+	state, loadErr := strategy.LoadSessionState(sessionID)
+	if loadErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load session state on start: %v\n", loadErr)
+		return
+	}
+	if state == nil {
+		return
+	}
+	strategy.TransitionAndLog(state, session.EventSessionStart, session.TransitionContext{})
+	if saveErr := strategy.SaveSessionState(state); saveErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update session state on start: %v\n", saveErr)
+	}
+}
+
 // hookResponse represents a JSON response.
 // Used to control whether Agent continues processing the prompt.
 type hookResponse struct {
@@ -0,0 +1,268 @@
+// hooks_agent_session.go contains the turn-end commit flow shared by agents whose
+// transcripts are parsed into prompts, summary and modified files up front
+// (Gemini CLI, Cursor, Codex). Claude Code uses its own flow in hooks_claudecode_handlers.go.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+)
+
+// agentSessionContext holds parsed session data for an agent turn commit.
+type agentSessionContext struct {
+	sessionID      string
+	agentType      agent.AgentType // Set when the agent may share one transcript file between sessions
+	transcriptPath string
+	sessionDir     string
+	sessionDirAbs  string
+	transcriptData []byte
+	allPrompts     []string
+	summary        string
+	modifiedFiles  []string
+	commitMessage  string
+}
+
+// agentCommitOptions holds the agent-specific parts of commitAgentSession.
+type agentCommitOptions struct {
+	agentType agent.AgentType
+
+	// transcriptStart returns the transcript offset the turn started at
+	// (line number for JSONL agents, message index for Gemini).
+	transcriptStart func(state *PrePromptState) int
+
+	// calculateTokenUsage computes token usage from the transcript file, starting at the given offset.
+	// Nil for agents whose transcripts carry no usage data.
+	calculateTokenUsage func(path string, startOffset int) (*agent.TokenUsage, error)
+
+	// newFilesFromTranscriptOnly restricts new files to those the transcript reports
+	// modifying when no pre-prompt baseline exists (agents without a prompt-submit hook).
+	newFilesFromTranscriptOnly bool
+}
+
+// setupAgentSessionDir creates session directory and copies transcript.
+func setupAgentSessionDir(ctx *agentSessionContext) error {
+	ctx.sessionDir = paths.SessionMetadataDirFromSessionID(ctx.sessionID)
+	sessionDirAbs, err := paths.AbsPath(ctx.sessionDir)
+	if err != nil {
+		sessionDirAbs = ctx.sessionDir
+	}
+	ctx.sessionDirAbs = sessionDirAbs
+
+	if err := os.MkdirAll(sessionDirAbs, 0o750); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	transcriptData, err := os.ReadFile(ctx.transcriptPath)
+	if err != nil {
+		return fmt.Errorf("failed to read transcript: %w", err)
+	}
+	// Only the session's part of a shared transcript file is kept
+	transcriptData, _ = strategy.SessionTranscript(ctx.agentType, ctx.sessionID, transcriptData)
+	ctx.transcriptData = transcriptData
+
+	logFile := filepath.Join(sessionDirAbs, paths.TranscriptFileName)
+	if err := os.WriteFile(logFile, transcriptData, 0o600); err != nil {
+		return fmt.Errorf("failed to copy transcript: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Copied transcript to: %s\n", ctx.sessionDir+"/"+paths.TranscriptFileName)
+
+	return nil
+}
+
+// writeAgentSessionMetadata writes the extracted prompts and summary to the session
+// directory and derives the commit message from the last prompt.
+// Callers populate ctx.allPrompts and ctx.summary first.
+func writeAgentSessionMetadata(ctx *agentSessionContext) error {
+	promptFile := filepath.Join(ctx.sessionDirAbs, paths.PromptFileName)
+	promptContent := strings.Join(ctx.allPrompts, "\n\n---\n\n")
+	if err := os.WriteFile(promptFile, []byte(promptContent), 0o600); err != nil {
+		return fmt.Errorf("failed to write prompt file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Extracted %d prompt(s) to: %s\n", len(ctx.allPrompts), ctx.sessionDir+"/"+paths.PromptFileName)
+
+	summaryFile := filepath.Join(ctx.sessionDirAbs, paths.SummaryFileName)
+	if err := os.WriteFile(summaryFile, []byte(ctx.summary), 0o600); err != nil {
+		return fmt.Errorf("failed to write summary file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Extracted summary to: %s\n", ctx.sessionDir+"/"+paths.SummaryFileName)
+
+	lastPrompt := ""
+	if len(ctx.allPrompts) > 0 {
+		lastPrompt = ctx.allPrompts[len(ctx.allPrompts)-1]
+	}
+	ctx.commitMessage = generateCommitMessage(lastPrompt)
+	fmt.Fprintf(os.Stderr, "Using commit message: %s\n", ctx.commitMessage)
+
+	return nil
+}
+
+// commitAgentSession commits the session changes using the strategy.
+func commitAgentSession(ctx *agentSessionContext, opts agentCommitOptions) error {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repo root: %w", err)
+	}
+
+	preState, err := LoadPrePromptState(ctx.sessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load pre-prompt state: %v\n", err)
+	}
+
+	// Get transcript position from pre-prompt state
+	var transcriptStart int
+	var transcriptIdentifierAtStart string
+	if preState != nil {
+		transcriptStart = opts.transcriptStart(preState)
+		transcriptIdentifierAtStart = preState.LastTranscriptIdentifier
+		fmt.Fprintf(os.Stderr, "Loaded pre-prompt state: %d pre-existing untracked files, transcript start: %d\n", len(preState.UntrackedFiles), transcriptStart)
+	}
+
+	// Calculate token usage for this prompt/response cycle
+	var tokenUsage *agent.TokenUsage
+	if ctx.transcriptPath != "" && opts.calculateTokenUsage != nil {
+		usage, tokenErr := opts.calculateTokenUsage(ctx.transcriptPath, transcriptStart)
+		if tokenErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to calculate token usage: %v\n", tokenErr)
+		} else if usage != nil && usage.APICallCount > 0 {
+			tokenUsage = usage
+			fmt.Fprintf(os.Stderr, "Token usage for this checkpoint: input=%d, output=%d, cache_read=%d, api_calls=%d\n",
+				tokenUsage.InputTokens, tokenUsage.OutputTokens, tokenUsage.CacheReadTokens, tokenUsage.APICallCount)
+		}
+	}
+
+	// Compute new and deleted files (single git status call)
+	changes, err := DetectFileChanges(preState.PreUntrackedFiles())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to compute file changes: %v\n", err)
+	}
+
+	relModifiedFiles := FilterAndNormalizePaths(ctx.modifiedFiles, repoRoot)
+	var relNewFiles, relDeletedFiles []string
+	if changes != nil {
+		relNewFiles = FilterAndNormalizePaths(changes.New, repoRoot)
+		relDeletedFiles = FilterAndNormalizePaths(changes.Deleted, repoRoot)
+	}
+	if preState == nil && opts.newFilesFromTranscriptOnly {
+		// Without a baseline we can't tell which untracked files predate the turn
+		relNewFiles = intersectPaths(relNewFiles, relModifiedFiles)
+	}
+
+	totalChanges := len(relModifiedFiles) + len(relNewFiles) + len(relDeletedFiles)
+	if totalChanges == 0 {
+		fmt.Fprintf(os.Stderr, "No files were modified during this session\n")
+		fmt.Fprintf(os.Stderr, "Skipping commit\n")
+		if cleanupErr := CleanupPrePromptState(ctx.sessionID); cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to cleanup pre-prompt state: %v\n", cleanupErr)
+		}
+		return nil
+	}
+
+	logFileChanges(relModifiedFiles, relNewFiles, relDeletedFiles)
+
+	contextFile := filepath.Join(ctx.sessionDirAbs, paths.ContextFileName)
+	if err := createContextFileFromPrompts(contextFile, ctx.commitMessage, ctx.sessionID, ctx.allPrompts, ctx.summary); err != nil {
+		return fmt.Errorf("failed to create context file: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Created context file: %s\n", ctx.sessionDir+"/"+paths.ContextFileName)
+
+	author, err := GetGitAuthor()
+	if err != nil {
+		return fmt.Errorf("failed to get git author: %w", err)
+	}
+
+	saveCtx := strategy.SaveContext{
+		SessionID:                ctx.sessionID,
+		ModifiedFiles:            relModifiedFiles,
+		NewFiles:                 relNewFiles,
+		DeletedFiles:             relDeletedFiles,
+		MetadataDir:              ctx.sessionDir,
+		MetadataDirAbs:           ctx.sessionDirAbs,
+		CommitMessage:            ctx.commitMessage,
+		TranscriptPath:           ctx.transcriptPath,
+		AuthorName:               author.Name,
+		AuthorEmail:              author.Email,
+		AgentType:                opts.agentType,
+		StepTranscriptStart:      transcriptStart,
+		StepTranscriptIdentifier: transcriptIdentifierAtStart,
+		TokenUsage:               tokenUsage,
+	}
+
+	if err := GetStrategy().SaveChanges(saveCtx); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	if cleanupErr := CleanupPrePromptState(ctx.sessionID); cleanupErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to cleanup pre-prompt state: %v\n", cleanupErr)
+	}
+
+	fmt.Fprintf(os.Stderr, "Session saved successfully\n")
+	return nil
+}
+
+// logFileChanges logs the modified, new, and deleted files to stderr.
+func logFileChanges(modified, newFiles, deleted []string) {
+	fmt.Fprintf(os.Stderr, "Files modified during session (%d):\n", len(modified))
+	for _, file := range modified {
+		fmt.Fprintf(os.Stderr, "  - %s\n", file)
+	}
+	if len(newFiles) > 0 {
+		fmt.Fprintf(os.Stderr, "New files created (%d):\n", len(newFiles))
+		for _, file := range newFiles {
+			fmt.Fprintf(os.Stderr, "  - %s\n", file)
+		}
+	}
+	if len(deleted) > 0 {
+		fmt.Fprintf(os.Stderr, "Files deleted (%d):\n", len(deleted))
+		for _, file := range deleted {
+			fmt.Fprintf(os.Stderr, "  - %s\n", file)
+		}
+	}
+}
+
+// createContextFileFromPrompts creates a context.md file from extracted prompts and summary.
+func createContextFileFromPrompts(contextFile, commitMessage, sessionID string, prompts []string, summary string) error {
+	var sb strings.Builder
+
+	sb.WriteString("# Session Context\n\n")
+	sb.WriteString(fmt.Sprintf("Session ID: %s\n", sessionID))
+	sb.WriteString(fmt.Sprintf("Commit Message: %s\n\n", commitMessage))
+
+	if len(prompts) > 0 {
+		sb.WriteString("## Prompts\n\n")
+		for i, p := range prompts {
+			sb.WriteString(fmt.Sprintf("### Prompt %d\n\n%s\n\n", i+1, p))
+		}
+	}
+
+	if summary != "" {
+		sb.WriteString("## Summary\n\n")
+		sb.WriteString(summary)
+		sb.WriteString("\n")
+	}
+
+	if err := os.WriteFile(contextFile, []byte(sb.String()), 0o600); err != nil {
+		return fmt.Errorf("failed to write context file: %w", err)
+	}
+	return nil
+}
+
+// intersectPaths returns the paths in a that also appear in b, preserving a's order.
+func intersectPaths(a, b []string) []string {
+	set := make(map[string]bool, len(b))
+	for _, p := range b {
+		set[p] = true
+	}
+	var result []string
+	for _, p := range a {
+		if set[p] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -36,7 +36,7 @@ func parseAndLogHookInput() (*hookInputData, error) {
 	}
 
 	// Parse hook input using agent interface
-	input, err := ag.ParseHookInput(agent.HookUserPromptSubmit, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookUserPromptSubmit, hookInputReader())
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -103,7 +103,7 @@ func commitWithMetadata() error { //nolint:maintidx // already present in codeba
 	}
 
 	// Parse hook input using agent interface
-	input, err := ag.ParseHookInput(agent.HookStop, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookStop, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -354,12 +354,12 @@ func commitWithMetadata() error { //nolint:maintidx // already present in codeba
 	}
 
 	// Update session state with new transcript position for strategies that create
-	// commits on the active branch (auto-commit strategy). This prevents parsing old transcript
-	// lines on subsequent checkpoints.
+	// commits on a branch (auto-commit and session-branch strategies). This prevents parsing
+	// old transcript lines on subsequent checkpoints.
 	// Note: Shadow strategy tracks transcript position per-step via StepTranscriptStart in
 	// pre-prompt state, but doesn't advance CheckpointTranscriptStart in session state because
 	// its checkpoints accumulate all files touched across the entire session.
-	if strat.Name() == strategy.StrategyNameAutoCommit {
+	if name := strat.Name(); name == strategy.StrategyNameAutoCommit || name == strategy.StrategyNameSessionBranch {
 		// Load session state for updating transcript position
 		sessionState, loadErr := strategy.LoadSessionState(sessionID)
 		if loadErr != nil {
@@ -398,7 +398,7 @@ func commitWithMetadata() error { //nolint:maintidx // already present in codeba
 // Creates a checkpoint if we're in a subagent context (active pre-task file exists).
 // Skips silently if not in subagent context (main agent).
 func handleClaudeCodePostTodo() error {
-	input, err := parseSubagentCheckpointHookInput(os.Stdin)
+	input, err := parseSubagentCheckpointHookInput(hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse PostToolUse[TodoWrite] input: %w", err)
 	}
@@ -516,7 +516,7 @@ func handleClaudeCodePostTodo() error {
 
 // handleClaudeCodePreTask handles the PreToolUse[Task] hook
 func handleClaudeCodePreTask() error {
-	input, err := parseTaskHookInput(os.Stdin)
+	input, err := parseTaskHookInput(hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse PreToolUse[Task] input: %w", err)
 	}
@@ -552,7 +552,7 @@ func handleClaudeCodePreTask() error {
 
 // handleClaudeCodePostTask handles the PostToolUse[Task] hook
 func handleClaudeCodePostTask() error {
-	input, err := parsePostTaskHookInput(os.Stdin)
+	input, err := parsePostTaskHookInput(hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse PostToolUse[Task] input: %w", err)
 	}
@@ -708,7 +708,7 @@ func handleClaudeCodeSessionEnd() error {
 		return fmt.Errorf("failed to get agent: %w", err)
 	}
 
-	input, err := ag.ParseHookInput(agent.HookSessionEnd, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookSessionEnd, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -774,6 +774,13 @@ func markSessionEnded(sessionID string) error {
 	now := time.Now()
 	state.EndedAt = &now
 
+	// Best-effort: failures here must not block session closure.
+	if handler, ok := GetStrategy().(strategy.SessionEndHandler); ok {
+		if err := handler.HandleSessionEnd(state); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: session-end action dispatch failed: %v\n", err)
+		}
+	}
+
 	if err := strategy.SaveSessionState(state); err != nil {
 		return fmt.Errorf("failed to save session state: %w", err)
 	}
@@ -3,8 +3,12 @@ package cli
 import (
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	// Import agents to ensure they are registered before we iterate
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/aider"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/cursor"
 	_ "github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
+	_ "github.com/entireio/cli/cmd/entire/cli/agent/simulator"
 
 	"github.com/spf13/cobra"
 )
@@ -15,6 +19,15 @@ func newHooksCmd() *cobra.Command {
 		Short:  "Hook handlers",
 		Long:   "Commands called by hooks. These are internal and not for direct user use.",
 		Hidden: true, // Internal command, not for direct user use
+		// Agent plugins aren't discovered up front, so `entire hooks <plugin> <verb>`
+		// matches no subcommand and lands here.
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return cmd.Help()
+			}
+			return runExternalAgentHook(agent.AgentName(args[0]), args[1], args[2:])
+		},
 	}
 
 	// Git hooks are strategy-level (not agent-specific)
@@ -0,0 +1,118 @@
+// hooks_codex_handlers.go contains Codex CLI specific hook handler implementations.
+// These are called by the hook registry in hook_registry.go.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+)
+
+// handleCodexTurnComplete handles Codex's agent-turn-complete notification.
+// Codex has no prompt-submit or session-start hook, so this single notification
+// drives the whole lifecycle: it starts (or resumes) the session and its turn,
+// saves the checkpoint, ends the turn, and captures the baseline for the next turn.
+func handleCodexTurnComplete() error {
+	ag, err := agent.Get(agent.AgentNameCodex)
+	if err != nil {
+		return fmt.Errorf("failed to get codex agent: %w", err)
+	}
+
+	// Codex passes the notify payload as the last argument rather than on stdin
+	input, err := ag.ParseHookInput(agent.HookStop, hookInputReader())
+	if err != nil {
+		return fmt.Errorf("failed to parse hook input: %w", err)
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), ag.Name())
+	logging.Info(logCtx, "codex-turn-complete",
+		slog.String("hook", "turn-complete"),
+		slog.String("hook_type", "agent"),
+		slog.String("model_session_id", input.SessionID),
+		slog.String("transcript_path", input.SessionRef),
+	)
+
+	if input.SessionID == "" {
+		return fmt.Errorf("no session found for notification (type %v)", input.RawData["type"])
+	}
+	sessionID := input.SessionID
+
+	transcriptPath := input.SessionRef
+	if transcriptPath == "" || !fileExists(transcriptPath) {
+		return fmt.Errorf("rollout file not found or empty: %s", transcriptPath)
+	}
+
+	// Early check: bail out quickly if the repo has no commits yet.
+	if repo, err := strategy.OpenRepository(); err == nil && strategy.IsEmptyRepository(repo) {
+		fmt.Fprintln(os.Stderr, "Entire: skipping checkpoint. Will activate after first commit.")
+		return NewSilentError(strategy.ErrEmptyRepository)
+	}
+
+	strat := GetStrategy()
+
+	// Ensure strategy setup is in place (git hooks, gitignore, metadata branch).
+	if err := strat.EnsureSetup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to ensure strategy setup: %v\n", err)
+	}
+
+	// Start the turn now - this is the earliest point Codex tells us about it
+	if initializer, ok := strat.(strategy.SessionInitializer); ok {
+		if err := initializer.InitializeSession(sessionID, ag.Type(), transcriptPath, input.UserPrompt); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to initialize session state: %v\n", err)
+		}
+	}
+
+	ctx := &agentSessionContext{
+		sessionID:      sessionID,
+		transcriptPath: transcriptPath,
+	}
+
+	if err := setupAgentSessionDir(ctx); err != nil {
+		return err
+	}
+
+	if err := extractCodexMetadata(ctx); err != nil {
+		return err
+	}
+
+	// Pre-prompt state comes from the end of the previous turn, so the first
+	// turn has no untracked-file baseline.
+	if err := commitAgentSession(ctx, agentCommitOptions{
+		agentType:                  ag.Type(),
+		transcriptStart:            func(state *PrePromptState) int { return state.StepTranscriptStart },
+		calculateTokenUsage:        codex.CalculateTokenUsageFromFile,
+		newFilesFromTranscriptOnly: true,
+	}); err != nil {
+		return err
+	}
+
+	transitionSessionTurnEnd(sessionID)
+
+	// Capture the baseline for the next turn (untracked files and rollout position),
+	// standing in for the prompt-submit capture other agents do.
+	if err := CapturePrePromptState(sessionID, transcriptPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to capture state for next turn: %v\n", err)
+	}
+
+	return nil
+}
+
+// extractCodexMetadata extracts prompts, summary, and modified files from the rollout.
+func extractCodexMetadata(ctx *agentSessionContext) error {
+	lines, err := codex.ParseRollout(ctx.transcriptData)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to parse rollout: %v\n", err)
+	}
+
+	ctx.allPrompts = codex.ExtractAllUserPromptsFromLines(lines)
+	ctx.summary = codex.ExtractLastAssistantMessageFromLines(lines)
+	ctx.modifiedFiles = codex.ExtractModifiedFiles(lines)
+
+	return writeAgentSessionMetadata(ctx)
+}
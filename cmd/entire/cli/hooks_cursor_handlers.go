@@ -0,0 +1,206 @@
+// hooks_cursor_handlers.go contains Cursor specific hook handler implementations.
+// These are called by the hook registry in hook_registry.go.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/cursor"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+)
+
+// handleCursorSessionStart handles the sessionStart hook for Cursor.
+func handleCursorSessionStart() error {
+	return handleSessionStartCommon()
+}
+
+// handleCursorSessionEnd handles the sessionEnd hook for Cursor.
+// Marks the session as ended so that subsequent git hooks (e.g., post-commit)
+// can trigger condensation for ended sessions.
+func handleCursorSessionEnd() error {
+	ag, err := GetCurrentHookAgent()
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	input, err := ag.ParseHookInput(agent.HookSessionEnd, hookInputReader())
+	if err != nil {
+		return fmt.Errorf("failed to parse hook input: %w", err)
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), ag.Name())
+	logging.Info(logCtx, "session-end",
+		slog.String("hook", "session-end"),
+		slog.String("hook_type", "agent"),
+		slog.String("model_session_id", input.SessionID),
+		slog.String("transcript_path", input.SessionRef),
+	)
+
+	if input.SessionID == "" {
+		return nil
+	}
+
+	if err := markSessionEnded(input.SessionID); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to mark session ended: %v\n", err)
+	}
+	return nil
+}
+
+// handleCursorBeforeSubmitPrompt handles the beforeSubmitPrompt hook for Cursor.
+// This is equivalent to Claude Code's UserPromptSubmit - it captures the initial
+// state so we can track what files were modified during the turn.
+func handleCursorBeforeSubmitPrompt() error {
+	// Always use the Cursor agent for Cursor hooks (don't rely on auto-detection,
+	// which may pick another agent when several are configured)
+	ag, err := agent.Get(agent.AgentNameCursor)
+	if err != nil {
+		return fmt.Errorf("failed to get cursor agent: %w", err)
+	}
+
+	input, err := ag.ParseHookInput(agent.HookUserPromptSubmit, hookInputReader())
+	if err != nil {
+		return fmt.Errorf("failed to parse hook input: %w", err)
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), ag.Name())
+	logging.Info(logCtx, "cursor-before-submit-prompt",
+		slog.String("hook", "before-submit-prompt"),
+		slog.String("hook_type", "agent"),
+		slog.String("model_session_id", input.SessionID),
+		slog.String("transcript_path", input.SessionRef),
+	)
+
+	if input.SessionID == "" {
+		return errors.New("no session_id in input")
+	}
+
+	// Cursor transcripts are JSONL, so the line-based pre-prompt capture applies
+	if err := CapturePrePromptState(input.SessionID, input.SessionRef); err != nil {
+		return fmt.Errorf("failed to capture pre-prompt state: %w", err)
+	}
+
+	strat := GetStrategy()
+
+	// Ensure strategy setup is in place (git hooks, gitignore, metadata branch).
+	// Done here at turn start so hooks are installed before any mid-turn commits.
+	if err := strat.EnsureSetup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to ensure strategy setup: %v\n", err)
+	}
+
+	if initializer, ok := strat.(strategy.SessionInitializer); ok {
+		if err := initializer.InitializeSession(input.SessionID, ag.Type(), input.SessionRef, input.UserPrompt); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to initialize session state: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// handleCursorStop handles the stop hook for Cursor.
+// This fires when the agent loop finishes a turn and commits the session
+// changes with metadata, mirroring Claude Code's Stop hook.
+func handleCursorStop() error {
+	ag, err := agent.Get(agent.AgentNameCursor)
+	if err != nil {
+		return fmt.Errorf("failed to get cursor agent: %w", err)
+	}
+
+	input, err := ag.ParseHookInput(agent.HookStop, hookInputReader())
+	if err != nil {
+		return fmt.Errorf("failed to parse hook input: %w", err)
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), ag.Name())
+	logging.Info(logCtx, "cursor-stop",
+		slog.String("hook", "stop"),
+		slog.String("hook_type", "agent"),
+		slog.String("model_session_id", input.SessionID),
+		slog.String("transcript_path", input.SessionRef),
+	)
+
+	sessionID := input.SessionID
+	if sessionID == "" {
+		sessionID = unknownSessionID
+	}
+
+	transcriptPath := input.SessionRef
+	if transcriptPath == "" || !fileExists(transcriptPath) {
+		return fmt.Errorf("transcript file not found or empty: %s", transcriptPath)
+	}
+
+	// Early check: bail out quickly if the repo has no commits yet.
+	if repo, err := strategy.OpenRepository(); err == nil && strategy.IsEmptyRepository(repo) {
+		fmt.Fprintln(os.Stderr, "Entire: skipping checkpoint. Will activate after first commit.")
+		return NewSilentError(strategy.ErrEmptyRepository)
+	}
+
+	ctx := &agentSessionContext{
+		sessionID:      sessionID,
+		transcriptPath: transcriptPath,
+	}
+
+	if err := setupAgentSessionDir(ctx); err != nil {
+		return err
+	}
+
+	if err := extractCursorMetadata(ctx); err != nil {
+		return err
+	}
+
+	if err := commitAgentSession(ctx, agentCommitOptions{
+		agentType:           ag.Type(),
+		transcriptStart:     func(state *PrePromptState) int { return state.StepTranscriptStart },
+		calculateTokenUsage: cursor.CalculateTokenUsageFromFile,
+	}); err != nil {
+		return err
+	}
+
+	transitionSessionTurnEnd(sessionID)
+
+	return nil
+}
+
+// handleCursorAfterFileEdit handles the afterFileEdit hook for Cursor.
+// Modified files are recovered from the transcript on stop, so this is logging only.
+func handleCursorAfterFileEdit() error {
+	ag, err := GetCurrentHookAgent()
+	if err != nil {
+		return fmt.Errorf("failed to get agent: %w", err)
+	}
+
+	input, err := ag.ParseHookInput(agent.HookPostToolUse, hookInputReader())
+	if err != nil {
+		return fmt.Errorf("failed to parse hook input: %w", err)
+	}
+
+	filePath, _ := input.RawData["file_path"].(string) //nolint:errcheck // type assertion on optional field
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), ag.Name())
+	logging.Debug(logCtx, "cursor-after-file-edit",
+		slog.String("hook", "after-file-edit"),
+		slog.String("hook_type", "tool"),
+		slog.String("model_session_id", input.SessionID),
+		slog.String("file_path", filePath),
+	)
+
+	return nil
+}
+
+// extractCursorMetadata extracts prompts, summary, and modified files from transcript.
+func extractCursorMetadata(ctx *agentSessionContext) error {
+	lines, err := cursor.ParseTranscript(ctx.transcriptData)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to parse transcript: %v\n", err)
+	}
+
+	ctx.allPrompts = cursor.ExtractAllUserPromptsFromLines(lines)
+	ctx.summary = cursor.ExtractLastAssistantMessageFromLines(lines)
+	ctx.modifiedFiles = cursor.ExtractModifiedFiles(lines)
+
+	return writeAgentSessionMetadata(ctx)
+}
@@ -0,0 +1,150 @@
+// hooks_external_handlers.go contains hook handling for agent plugins
+// (entire-agent-<name> executables, see the agent/external package).
+// Plugins declare which lifecycle event each of their hook verbs signals,
+// so one generic handler serves every plugin hook.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/external"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+)
+
+var registerExternalAgentsOnce sync.Once
+
+// registerExternalAgents discovers agent plugins and registers them with a
+// hook handler for each hook verb they declare. Discovery runs every plugin
+// executable, so it is done lazily, once per process, and only by the
+// commands that deal with plugins: enable, status, uninstall and plugin hooks.
+func registerExternalAgents() {
+	registerExternalAgentsOnce.Do(func() {
+		plugins, errs := external.Discover(localExternalAgentPaths())
+		errs = append(errs, external.Register(plugins)...)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %v\n", err)
+		}
+
+		for _, p := range plugins {
+			ag, err := agent.Get(agent.AgentName(p.Info.Name))
+			if err != nil {
+				continue
+			}
+			registerExternalAgentHooks(ag)
+		}
+	})
+}
+
+// localExternalAgentPaths returns the plugin paths configured in
+// .entire/settings.local.json, resolved against the repository root.
+// external_agents in the committed settings.json is ignored: honoring it
+// would let a cloned repository make entire run executables it ships.
+func localExternalAgentPaths() []string {
+	localFile, err := paths.AbsPath(settings.EntireSettingsLocalFile)
+	if err != nil {
+		return nil
+	}
+	s, err := settings.LoadFromFile(localFile)
+	if err != nil {
+		return nil
+	}
+	return resolveExternalAgentPaths(s.ExternalAgents)
+}
+
+// resolveExternalAgentPaths resolves configured plugin paths against the repository root.
+func resolveExternalAgentPaths(configured []string) []string {
+	resolved := make([]string, 0, len(configured))
+	for _, p := range configured {
+		if abs, err := paths.AbsPath(p); err == nil {
+			p = abs
+		}
+		resolved = append(resolved, p)
+	}
+	return resolved
+}
+
+// runExternalAgentHook runs `entire hooks <plugin> <verb>` for an agent plugin.
+// Plugins are discovered only here, so other hooks don't pay for running every
+// plugin executable.
+func runExternalAgentHook(agentName agent.AgentName, hookName string, args []string) error {
+	registerExternalAgents()
+	ag, err := agent.Get(agentName)
+	if err != nil || !external.IsPlugin(ag) {
+		return fmt.Errorf("unknown agent %q", agentName)
+	}
+	if _, ok := external.HookTypeFor(ag, hookName); !ok {
+		return fmt.Errorf("agent %s does not declare hook %q", agentName, hookName)
+	}
+
+	cleanup := initHookLogging()
+	defer cleanup()
+	verb := newAgentHookVerbCmdWithLogging(agentName, hookName)
+	return verb.RunE(verb, args)
+}
+
+// registerExternalAgentHooks registers a handler for each hook verb of a plugin agent.
+// Agents that aren't plugins (e.g., a built-in agent that won a name clash) are skipped.
+func registerExternalAgentHooks(ag agent.Agent) {
+	handler, ok := ag.(agent.HookHandler)
+	if !ok {
+		return
+	}
+	agentName := ag.Name()
+	for _, hookName := range handler.GetHookNames() {
+		if _, ok := external.HookTypeFor(ag, hookName); !ok {
+			continue
+		}
+		RegisterHookHandler(agentName, hookName, func() error {
+			enabled, err := IsEnabled()
+			if err == nil && !enabled {
+				return nil
+			}
+			return handleExternalAgentHook(agentName, hookName)
+		})
+	}
+}
+
+// handleExternalAgentHook parses a plugin hook's payload through the plugin and
+// applies the lifecycle event the hook verb signals.
+func handleExternalAgentHook(agentName agent.AgentName, hookName string) error {
+	ag, err := agent.Get(agentName)
+	if err != nil {
+		return fmt.Errorf("failed to get agent %s: %w", agentName, err)
+	}
+
+	hookType, ok := external.HookTypeFor(ag, hookName)
+	if !ok {
+		return fmt.Errorf("agent %s does not declare hook %q", agentName, hookName)
+	}
+
+	input, err := ag.ParseHookInput(hookType, hookInputReader())
+	if err != nil {
+		return fmt.Errorf("failed to parse hook input: %w", err)
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "hooks"), agentName)
+	logging.Info(logCtx, "external-agent-hook",
+		slog.String("hook", hookName),
+		slog.String("hook_type", string(hookType)),
+		slog.String("model_session_id", input.SessionID),
+		slog.String("transcript_path", input.SessionRef),
+	)
+
+	if input.SessionID == "" {
+		return fmt.Errorf("no session ID in %s hook input", hookName)
+	}
+
+	return applySessionChange(ag, &agent.SessionChange{
+		SessionID:  input.SessionID,
+		SessionRef: input.SessionRef,
+		EventType:  hookType,
+		Timestamp:  input.Timestamp,
+	})
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/external"
+)
+
+// Not parallel: registers handlers in the global hook registry.
+func TestRegisterExternalAgentHooks(t *testing.T) {
+	ag := external.New("/nonexistent/entire-agent-hooktest", external.Info{
+		Name:         "hooktest",
+		Type:         "Hook Test",
+		Capabilities: external.Capabilities{Hooks: true},
+		Hooks: []external.HookSpec{
+			{Name: "prompt", Type: agent.HookUserPromptSubmit},
+			{Name: "done", Type: agent.HookStop},
+		},
+	})
+
+	registerExternalAgentHooks(ag)
+
+	for _, hookName := range []string{"prompt", "done"} {
+		if GetHookHandler("hooktest", hookName) == nil {
+			t.Errorf("expected handler for hooktest/%s", hookName)
+		}
+	}
+	if GetHookHandler("hooktest", "unknown") != nil {
+		t.Error("expected no handler for undeclared hook")
+	}
+}
+
+func TestRegisterExternalAgentHooks_SkipsBuiltinAgents(t *testing.T) {
+	ag, err := agent.Get(agent.AgentNameCursor)
+	if err != nil {
+		t.Fatalf("failed to get cursor agent: %v", err)
+	}
+	handler, ok := ag.(agent.HookHandler)
+	if !ok {
+		t.Fatal("cursor agent should implement HookHandler")
+	}
+	before := GetHookHandler(agent.AgentNameCursor, handler.GetHookNames()[0])
+
+	registerExternalAgentHooks(ag)
+
+	after := GetHookHandler(agent.AgentNameCursor, handler.GetHookNames()[0])
+	if before == nil || after == nil {
+		t.Fatal("expected built-in cursor handler to remain registered")
+	}
+}
+
+func TestLocalExternalAgentPaths_IgnoresProjectSettings(t *testing.T) {
+	setupTestRepo(t)
+	writeSettings(t, `{"external_agents": ["bin/entire-agent-shipped"]}`)
+
+	if got := localExternalAgentPaths(); len(got) != 0 {
+		t.Errorf("localExternalAgentPaths() = %v, want none from settings.json", got)
+	}
+
+	if err := os.WriteFile(EntireSettingsLocalFile, []byte(`{"external_agents": ["bin/entire-agent-mine"]}`), 0o644); err != nil {
+		t.Fatalf("failed to write local settings: %v", err)
+	}
+	got := localExternalAgentPaths()
+	if len(got) != 1 || filepath.Base(got[0]) != "entire-agent-mine" || !filepath.IsAbs(got[0]) {
+		t.Errorf("localExternalAgentPaths() = %v, want the absolute path of bin/entire-agent-mine", got)
+	}
+}
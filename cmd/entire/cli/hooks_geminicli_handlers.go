@@ -8,13 +8,10 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
-	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
 )
 
@@ -47,27 +44,14 @@ func handleGeminiSessionEnd() error { // Parse stdin once upfront — all subseq
 	return nil
 }
 
-// geminiSessionContext holds parsed session data for Gemini commits.
-type geminiSessionContext struct {
-	sessionID      string
-	transcriptPath string
-	sessionDir     string
-	sessionDirAbs  string
-	transcriptData []byte
-	allPrompts     []string
-	summary        string
-	modifiedFiles  []string
-	commitMessage  string
-}
-
 // parseGeminiSessionEnd parses the session-end hook input and validates transcript.
-func parseGeminiSessionEnd() (*geminiSessionContext, error) {
+func parseGeminiSessionEnd() (*agentSessionContext, error) {
 	ag, err := GetCurrentHookAgent()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agent: %w", err)
 	}
 
-	input, err := ag.ParseHookInput(agent.HookSessionEnd, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookSessionEnd, hookInputReader())
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -90,243 +74,33 @@ func parseGeminiSessionEnd() (*geminiSessionContext, error) {
 		return nil, fmt.Errorf("transcript file not found or empty: %s", transcriptPath)
 	}
 
-	return &geminiSessionContext{
+	return &agentSessionContext{
 		sessionID:      sessionID,
 		transcriptPath: transcriptPath,
 	}, nil
 }
 
-// setupGeminiSessionDir creates session directory and copies transcript.
-func setupGeminiSessionDir(ctx *geminiSessionContext) error {
-	ctx.sessionDir = paths.SessionMetadataDirFromSessionID(ctx.sessionID)
-	sessionDirAbs, err := paths.AbsPath(ctx.sessionDir)
-	if err != nil {
-		sessionDirAbs = ctx.sessionDir
-	}
-	ctx.sessionDirAbs = sessionDirAbs
-
-	if err := os.MkdirAll(sessionDirAbs, 0o750); err != nil {
-		return fmt.Errorf("failed to create session directory: %w", err)
-	}
-
-	logFile := filepath.Join(sessionDirAbs, paths.TranscriptFileName)
-	if err := copyFile(ctx.transcriptPath, logFile); err != nil {
-		return fmt.Errorf("failed to copy transcript: %w", err)
-	}
-	fmt.Fprintf(os.Stderr, "Copied transcript to: %s\n", ctx.sessionDir+"/"+paths.TranscriptFileName)
-
-	transcriptData, err := os.ReadFile(ctx.transcriptPath)
-	if err != nil {
-		return fmt.Errorf("failed to read transcript: %w", err)
-	}
-	ctx.transcriptData = transcriptData
-
-	return nil
-}
-
 // extractGeminiMetadata extracts prompts, summary, and modified files from transcript.
-func extractGeminiMetadata(ctx *geminiSessionContext) error {
+func extractGeminiMetadata(ctx *agentSessionContext) error {
 	allPrompts, err := geminicli.ExtractAllUserPrompts(ctx.transcriptData)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to extract prompts: %v\n", err)
 	}
 	ctx.allPrompts = allPrompts
 
-	promptFile := filepath.Join(ctx.sessionDirAbs, paths.PromptFileName)
-	promptContent := strings.Join(allPrompts, "\n\n---\n\n")
-	if err := os.WriteFile(promptFile, []byte(promptContent), 0o600); err != nil {
-		return fmt.Errorf("failed to write prompt file: %w", err)
-	}
-	fmt.Fprintf(os.Stderr, "Extracted %d prompt(s) to: %s\n", len(allPrompts), ctx.sessionDir+"/"+paths.PromptFileName)
-
 	summary, err := geminicli.ExtractLastAssistantMessage(ctx.transcriptData)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to extract summary: %v\n", err)
 	}
 	ctx.summary = summary
 
-	summaryFile := filepath.Join(ctx.sessionDirAbs, paths.SummaryFileName)
-	if err := os.WriteFile(summaryFile, []byte(summary), 0o600); err != nil {
-		return fmt.Errorf("failed to write summary file: %w", err)
-	}
-	fmt.Fprintf(os.Stderr, "Extracted summary to: %s\n", ctx.sessionDir+"/"+paths.SummaryFileName)
-
 	modifiedFiles, err := geminicli.ExtractModifiedFiles(ctx.transcriptData)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to extract modified files: %v\n", err)
 	}
 	ctx.modifiedFiles = modifiedFiles
 
-	lastPrompt := ""
-	if len(allPrompts) > 0 {
-		lastPrompt = allPrompts[len(allPrompts)-1]
-	}
-	ctx.commitMessage = generateCommitMessage(lastPrompt)
-	fmt.Fprintf(os.Stderr, "Using commit message: %s\n", ctx.commitMessage)
-
-	return nil
-}
-
-// commitGeminiSession commits the session changes using the strategy.
-func commitGeminiSession(ctx *geminiSessionContext) error {
-	repoRoot, err := paths.RepoRoot()
-	if err != nil {
-		return fmt.Errorf("failed to get repo root: %w", err)
-	}
-
-	preState, err := LoadPrePromptState(ctx.sessionID)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to load pre-prompt state: %v\n", err)
-	}
-	if preState != nil {
-		fmt.Fprintf(os.Stderr, "Loaded pre-prompt state: %d pre-existing untracked files, start message index: %d\n", len(preState.UntrackedFiles), preState.StartMessageIndex)
-	}
-
-	// Get transcript position from pre-prompt state
-	var startMessageIndex int
-	if preState != nil {
-		startMessageIndex = preState.StartMessageIndex
-	}
-
-	// Calculate token usage for this prompt/response cycle (Gemini-specific)
-	var tokenUsage *agent.TokenUsage
-	if ctx.transcriptPath != "" {
-		usage, tokenErr := geminicli.CalculateTokenUsageFromFile(ctx.transcriptPath, startMessageIndex)
-		if tokenErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to calculate token usage: %v\n", tokenErr)
-		} else if usage != nil && usage.APICallCount > 0 {
-			tokenUsage = usage
-			fmt.Fprintf(os.Stderr, "Token usage for this checkpoint: input=%d, output=%d, cache_read=%d, api_calls=%d\n",
-				tokenUsage.InputTokens, tokenUsage.OutputTokens, tokenUsage.CacheReadTokens, tokenUsage.APICallCount)
-		}
-	}
-
-	// Compute new and deleted files (single git status call)
-	changes, err := DetectFileChanges(preState.PreUntrackedFiles())
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to compute file changes: %v\n", err)
-	}
-
-	relModifiedFiles := FilterAndNormalizePaths(ctx.modifiedFiles, repoRoot)
-	var relNewFiles, relDeletedFiles []string
-	if changes != nil {
-		relNewFiles = FilterAndNormalizePaths(changes.New, repoRoot)
-		relDeletedFiles = FilterAndNormalizePaths(changes.Deleted, repoRoot)
-	}
-
-	totalChanges := len(relModifiedFiles) + len(relNewFiles) + len(relDeletedFiles)
-	if totalChanges == 0 {
-		fmt.Fprintf(os.Stderr, "No files were modified during this session\n")
-		fmt.Fprintf(os.Stderr, "Skipping commit\n")
-		if cleanupErr := CleanupPrePromptState(ctx.sessionID); cleanupErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to cleanup pre-prompt state: %v\n", cleanupErr)
-		}
-		return nil
-	}
-
-	logFileChanges(relModifiedFiles, relNewFiles, relDeletedFiles)
-
-	contextFile := filepath.Join(ctx.sessionDirAbs, paths.ContextFileName)
-	if err := createContextFileForGemini(contextFile, ctx.commitMessage, ctx.sessionID, ctx.allPrompts, ctx.summary); err != nil {
-		return fmt.Errorf("failed to create context file: %w", err)
-	}
-	fmt.Fprintf(os.Stderr, "Created context file: %s\n", ctx.sessionDir+"/"+paths.ContextFileName)
-
-	author, err := GetGitAuthor()
-	if err != nil {
-		return fmt.Errorf("failed to get git author: %w", err)
-	}
-
-	strat := GetStrategy()
-
-	// Get agent type from the hook agent (determined by which hook command is running)
-	// This is authoritative - if we're in "entire hooks gemini session-end", it's Gemini CLI
-	hookAgent, agentErr := GetCurrentHookAgent()
-	if agentErr != nil {
-		return fmt.Errorf("failed to get agent: %w", agentErr)
-	}
-	agentType := hookAgent.Type()
-
-	// Get transcript identifier at start from pre-prompt state
-	var transcriptIdentifierAtStart string
-	if preState != nil {
-		transcriptIdentifierAtStart = preState.LastTranscriptIdentifier
-	}
-
-	saveCtx := strategy.SaveContext{
-		SessionID:                ctx.sessionID,
-		ModifiedFiles:            relModifiedFiles,
-		NewFiles:                 relNewFiles,
-		DeletedFiles:             relDeletedFiles,
-		MetadataDir:              ctx.sessionDir,
-		MetadataDirAbs:           ctx.sessionDirAbs,
-		CommitMessage:            ctx.commitMessage,
-		TranscriptPath:           ctx.transcriptPath,
-		AuthorName:               author.Name,
-		AuthorEmail:              author.Email,
-		AgentType:                agentType,
-		StepTranscriptStart:      startMessageIndex,
-		StepTranscriptIdentifier: transcriptIdentifierAtStart,
-		TokenUsage:               tokenUsage,
-	}
-
-	if err := strat.SaveChanges(saveCtx); err != nil {
-		return fmt.Errorf("failed to save session: %w", err)
-	}
-
-	if cleanupErr := CleanupPrePromptState(ctx.sessionID); cleanupErr != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to cleanup pre-prompt state: %v\n", cleanupErr)
-	}
-
-	fmt.Fprintf(os.Stderr, "Session saved successfully\n")
-	return nil
-}
-
-// logFileChanges logs the modified, new, and deleted files to stderr.
-func logFileChanges(modified, newFiles, deleted []string) {
-	fmt.Fprintf(os.Stderr, "Files modified during session (%d):\n", len(modified))
-	for _, file := range modified {
-		fmt.Fprintf(os.Stderr, "  - %s\n", file)
-	}
-	if len(newFiles) > 0 {
-		fmt.Fprintf(os.Stderr, "New files created (%d):\n", len(newFiles))
-		for _, file := range newFiles {
-			fmt.Fprintf(os.Stderr, "  - %s\n", file)
-		}
-	}
-	if len(deleted) > 0 {
-		fmt.Fprintf(os.Stderr, "Files deleted (%d):\n", len(deleted))
-		for _, file := range deleted {
-			fmt.Fprintf(os.Stderr, "  - %s\n", file)
-		}
-	}
-}
-
-// createContextFileForGemini creates a context.md file for Gemini sessions.
-func createContextFileForGemini(contextFile, commitMessage, sessionID string, prompts []string, summary string) error {
-	var sb strings.Builder
-
-	sb.WriteString("# Session Context\n\n")
-	sb.WriteString(fmt.Sprintf("Session ID: %s\n", sessionID))
-	sb.WriteString(fmt.Sprintf("Commit Message: %s\n\n", commitMessage))
-
-	if len(prompts) > 0 {
-		sb.WriteString("## Prompts\n\n")
-		for i, p := range prompts {
-			sb.WriteString(fmt.Sprintf("### Prompt %d\n\n%s\n\n", i+1, p))
-		}
-	}
-
-	if summary != "" {
-		sb.WriteString("## Summary\n\n")
-		sb.WriteString(summary)
-		sb.WriteString("\n")
-	}
-
-	if err := os.WriteFile(contextFile, []byte(sb.String()), 0o600); err != nil {
-		return fmt.Errorf("failed to write context file: %w", err)
-	}
-	return nil
+	return writeAgentSessionMetadata(ctx)
 }
 
 // handleGeminiBeforeTool handles the BeforeTool hook for Gemini CLI.
@@ -339,7 +113,7 @@ func handleGeminiBeforeTool() error {
 	}
 
 	// Parse hook input
-	input, err := ag.ParseHookInput(agent.HookPreToolUse, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookPreToolUse, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -367,7 +141,7 @@ func handleGeminiAfterTool() error {
 	}
 
 	// Parse hook input
-	input, err := ag.ParseHookInput(agent.HookPostToolUse, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookPostToolUse, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -398,7 +172,7 @@ func handleGeminiBeforeAgent() error {
 	}
 
 	// Parse hook input - BeforeAgent provides user prompt info similar to UserPromptSubmit
-	input, err := ag.ParseHookInput(agent.HookUserPromptSubmit, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookUserPromptSubmit, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -466,7 +240,7 @@ func handleGeminiAfterAgent() error {
 
 	// Parse hook input using HookStop - AfterAgent provides the same data as Stop
 	// (session_id, transcript_path) which is what we need for committing
-	input, err := ag.ParseHookInput(agent.HookStop, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookStop, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -496,12 +270,12 @@ func handleGeminiAfterAgent() error {
 	}
 
 	// Create session context and commit
-	ctx := &geminiSessionContext{
+	ctx := &agentSessionContext{
 		sessionID:      sessionID,
 		transcriptPath: transcriptPath,
 	}
 
-	if err := setupGeminiSessionDir(ctx); err != nil {
+	if err := setupAgentSessionDir(ctx); err != nil {
 		return err
 	}
 
@@ -509,7 +283,12 @@ func handleGeminiAfterAgent() error {
 		return err
 	}
 
-	if err := commitGeminiSession(ctx); err != nil {
+	// Gemini transcripts are a single JSON document, so offsets are message indices
+	if err := commitAgentSession(ctx, agentCommitOptions{
+		agentType:           ag.Type(),
+		transcriptStart:     func(state *PrePromptState) int { return state.StartMessageIndex },
+		calculateTokenUsage: geminicli.CalculateTokenUsageFromFile,
+	}); err != nil {
 		return err
 	}
 
@@ -530,7 +309,7 @@ func handleGeminiBeforeModel() error {
 	}
 
 	// Parse hook input - use HookPreToolUse as a generic hook type for now
-	input, err := ag.ParseHookInput(agent.HookPreToolUse, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookPreToolUse, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -558,7 +337,7 @@ func handleGeminiAfterModel() error {
 	}
 
 	// Parse hook input
-	input, err := ag.ParseHookInput(agent.HookPostToolUse, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookPostToolUse, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -585,7 +364,7 @@ func handleGeminiBeforeToolSelection() error {
 	}
 
 	// Parse hook input
-	input, err := ag.ParseHookInput(agent.HookPreToolUse, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookPreToolUse, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -612,7 +391,7 @@ func handleGeminiPreCompress() error {
 	}
 
 	// Parse hook input
-	input, err := ag.ParseHookInput(agent.HookSessionStart, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookSessionStart, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
@@ -641,7 +420,7 @@ func handleGeminiNotification() error {
 	}
 
 	// Parse hook input
-	input, err := ag.ParseHookInput(agent.HookSessionStart, os.Stdin)
+	input, err := ag.ParseHookInput(agent.HookSessionStart, hookInputReader())
 	if err != nil {
 		return fmt.Errorf("failed to parse hook input: %w", err)
 	}
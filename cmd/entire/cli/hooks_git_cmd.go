@@ -98,6 +98,7 @@ func newHooksGitCmd() *cobra.Command {
 	cmd.AddCommand(newHooksGitCommitMsgCmd())
 	cmd.AddCommand(newHooksGitPostCommitCmd())
 	cmd.AddCommand(newHooksGitPrePushCmd())
+	cmd.AddCommand(newHooksGitPostRewriteCmd())
 
 	return cmd
 }
@@ -162,6 +163,7 @@ func newHooksGitPostCommitCmd() *cobra.Command {
 				hookErr := handler.PostCommit()
 				g.logCompleted(hookErr)
 			}
+			strategy.TryUpdateCheckpointIndex(g.ctx)
 
 			return nil
 		},
@@ -188,3 +190,50 @@ func newHooksGitPrePushCmd() *cobra.Command {
 		},
 	}
 }
+
+func newHooksGitPostRewriteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "post-rewrite <amend|rebase>",
+		Short: "Handle post-rewrite git hook",
+		Long:  "Handle post-rewrite git hook. Reads the \"<old-sha> <new-sha>\" lines git supplies on stdin.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rewriteType := args[0]
+
+			g := newGitHookContext("post-rewrite")
+			g.logInvoked(slog.String("rewrite_type", rewriteType))
+
+			rewrites, err := strategy.ParseCommitRewrites(cmd.InOrStdin())
+			if err != nil || len(rewrites) == 0 {
+				g.logCompleted(err, slog.String("rewrite_type", rewriteType))
+				return nil
+			}
+
+			// Merge the checkpoint trailers of squashed commits and copy
+			// checkpoint notes first, so the strategy and the index see the
+			// final commits.
+			if repo, repoErr := strategy.OpenRepository(); repoErr == nil {
+				merged, mergeErr := strategy.MergeSquashedCheckpoints(g.ctx, repo, rewriteType, rewrites)
+				if mergeErr != nil {
+					logging.Warn(g.ctx, "failed to merge checkpoint trailers of squashed commits", slog.String("error", mergeErr.Error()))
+				} else {
+					rewrites = merged
+				}
+				if err := strategy.CopyCheckpointNotes(g.ctx, repo, rewrites); err != nil {
+					logging.Warn(g.ctx, "failed to copy checkpoint notes", slog.String("error", err.Error()))
+				}
+			}
+
+			var hookErr error
+			if handler, ok := g.strategy.(strategy.PostRewriteHandler); ok {
+				hookErr = handler.PostRewrite(rewriteType, rewrites)
+			}
+			if err := strategy.UpdateCheckpointIndexAfterRewrite(g.ctx, rewrites); err != nil {
+				logging.Warn(g.ctx, "failed to update checkpoint index", slog.String("error", err.Error()))
+			}
+			g.logCompleted(hookErr, slog.String("rewrite_type", rewriteType), slog.Int("rewrites", len(rewrites)))
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,101 @@
+// Package ignore matches paths against the exclusions in .entire/ignore and
+// the ignore setting. Excluded paths are left out of temporary checkpoint
+// snapshots, files_touched and attribution, and are left alone by rewind.
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// FileName is the repo-relative path of the exclusions file. It uses
+// gitignore syntax, with patterns relative to the repository root.
+const FileName = ".entire/ignore"
+
+// Matcher matches repo-relative paths against the exclusions. A nil *Matcher
+// matches nothing.
+type Matcher struct {
+	matcher gitignore.Matcher
+}
+
+// Load reads the exclusions of the repository at repoRoot: the patterns in
+// .entire/ignore, followed by those of the ignore setting. Later patterns
+// take precedence, so the setting can re-include paths with "!". Returns nil
+// when there are no patterns.
+func Load(repoRoot string) (*Matcher, error) {
+	var lines []string
+	data, err := os.ReadFile(filepath.Join(repoRoot, FileName)) //nolint:gosec // path is under the repo root
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	s, err := settings.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	lines = append(lines, s.Ignore...)
+
+	return New(lines), nil
+}
+
+// New builds a matcher from gitignore-syntax lines. Blank lines and comments
+// are skipped. Returns nil when there are no patterns.
+func New(lines []string) *Matcher {
+	var patterns []gitignore.Pattern
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	return &Matcher{matcher: gitignore.NewMatcher(patterns)}
+}
+
+// Match reports whether the file at the repo-relative path is excluded,
+// either by a pattern for the file or for one of its parent directories.
+func (m *Matcher) Match(path string) bool {
+	return m.match(path, false)
+}
+
+// MatchDir reports whether the directory at the repo-relative path is
+// excluded.
+func (m *Matcher) MatchDir(path string) bool {
+	return m.match(path, true)
+}
+
+func (m *Matcher) match(path string, isDir bool) bool {
+	if m == nil || path == "" || path == "." {
+		return false
+	}
+	return m.matcher.Match(strings.Split(filepath.ToSlash(path), "/"), isDir)
+}
+
+// Filter returns the files that aren't excluded, in their original order.
+func (m *Matcher) Filter(files []string) []string {
+	if m == nil {
+		return files
+	}
+	kept := make([]string, 0, len(files))
+	for _, file := range files {
+		if !m.Match(file) {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,99 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	t.Parallel()
+
+	m := New([]string{
+		"# Generated code",
+		"",
+		"*.lock",
+		"dist/",
+		"/gen",
+		"docs/**/*.pb.go",
+		"!keep.lock",
+	})
+	tests := map[string]bool{
+		"Cargo.lock":              true,
+		"sub/yarn.lock":           true,
+		"keep.lock":               false,
+		"dist/app.js":             true,
+		"web/dist/bundle/app.js":  true,
+		"dist":                    false, // dist/ only matches directories
+		"gen/api.go":              true,
+		"pkg/gen/api.go":          false, // anchored to the repo root
+		"docs/a/b/service.pb.go":  true,
+		"main.go":                 false,
+		"distribution/readme.txt": false,
+	}
+	for path, want := range tests {
+		if got := m.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if !m.MatchDir("dist") {
+		t.Error(`MatchDir("dist") = false, want true`)
+	}
+
+	got := m.Filter([]string{"main.go", "Cargo.lock", "dist/app.js", "keep.lock"})
+	if want := []string{"main.go", "keep.lock"}; !slices.Equal(got, want) {
+		t.Errorf("Filter() = %v, want %v", got, want)
+	}
+}
+
+func TestMatcher_Nil(t *testing.T) {
+	t.Parallel()
+
+	m := New([]string{"# only a comment", "  "})
+	if m != nil {
+		t.Fatalf("New() without patterns = %v, want nil", m)
+	}
+	if m.Match("anything.lock") {
+		t.Error("nil matcher should match nothing")
+	}
+	files := []string{"a.go", "b.lock"}
+	if got := m.Filter(files); !slices.Equal(got, files) {
+		t.Errorf("nil Filter() = %v, want %v", got, files)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0o755); err != nil {
+		t.Fatalf("failed to create .git: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".entire"), 0o755); err != nil {
+		t.Fatalf("failed to create .entire: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("*.lock\nvendor/\n"), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", FileName, err)
+	}
+	settings := `{"ignore": ["*.generated.go", "!go.lock"]}`
+	if err := os.WriteFile(filepath.Join(dir, ".entire", "settings.json"), []byte(settings), 0o644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	t.Chdir(dir)
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	tests := map[string]bool{
+		"Cargo.lock":       true,
+		"vendor/x/y.go":    true,
+		"api.generated.go": true,
+		"go.lock":          false, // re-included by the setting
+		"cmd/main.go":      false,
+	}
+	for path, want := range tests {
+		if got := m.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
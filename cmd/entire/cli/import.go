@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/spf13/cobra"
+)
+
+func newImportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Import checkpoints from a bundle written by 'entire export'",
+		Long: `Import adds the checkpoints in a bundle written by 'entire export' to this
+repository's checkpoint store, applying this repository's compression and
+encryption settings. Checkpoints that already exist are skipped, so importing
+the same bundle twice is harmless.
+
+Checkpoints are linked to commits through the commits' Entire-Checkpoint
+trailers, so they show up in 'entire explain' for commits that exist here
+too, e.g. after moving a repository to another host. Pass - to read the
+bundle from stdin.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runImport(cmd.Context(), cmd.OutOrStdout(), cmd.InOrStdin(), args[0])
+		},
+	}
+}
+
+func runImport(ctx context.Context, w io.Writer, stdin io.Reader, bundlePath string) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+
+	r := stdin
+	if bundlePath != "-" {
+		f, err := os.Open(bundlePath) //nolint:gosec // bundlePath is the user's own argument
+		if err != nil {
+			return fmt.Errorf("failed to open bundle: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	result, err := strategy.ImportBundle(ctx, repo, store, r)
+	if err != nil {
+		return err //nolint:wrapcheck // Already descriptive
+	}
+	if len(result.Imported) > 0 {
+		strategy.TryUpdateCheckpointIndex(ctx)
+	}
+
+	fmt.Fprintf(w, "Imported %d checkpoint(s)", len(result.Imported))
+	if len(result.Skipped) > 0 {
+		fmt.Fprintf(w, ", skipped %d that already exist", len(result.Skipped))
+	}
+	fmt.Fprintln(w, ".")
+	if result.LinkedCommits > 0 {
+		fmt.Fprintf(w, "%d of %d linked commit(s) exist in this repository.\n", result.PresentCommits, result.LinkedCommits)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/spf13/cobra"
+)
+
+func newIndexCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Manage the local checkpoint index",
+		Long: `Manage the local checkpoint index.
+
+The checkpoint index caches committed checkpoint metadata and the commits that
+reference each checkpoint, so that listing checkpoints in 'entire explain' and
+'entire rewind' stays fast on repositories with many checkpoints.
+
+The index is stored in the git common dir and is not shared. Once built with
+'entire index rebuild', it is updated automatically after commits and after
+fetching the metadata branch.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return cmd.Help()
+		},
+	}
+
+	cmd.AddCommand(newIndexRebuildCmd())
+
+	return cmd
+}
+
+func newIndexRebuildCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rebuild",
+		Short: "Build the checkpoint index from scratch",
+		Long: `Build the checkpoint index from scratch by reading every committed checkpoint
+and scanning the history of all local branches for Entire-Checkpoint trailers.
+
+Run this once to enable the index, or to repair it if it gets out of sync.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runIndexRebuild(cmd)
+		},
+	}
+}
+
+func runIndexRebuild(cmd *cobra.Command) error {
+	if _, err := paths.RepoRoot(); err != nil {
+		return errors.New("not a git repository")
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+
+	idx, err := strategy.RebuildCheckpointIndex(cmd.Context(), repo, store)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild checkpoint index: %w", err)
+	}
+
+	commits := make(map[string]struct{})
+	for _, hashes := range idx.Commits {
+		for _, hash := range hashes {
+			commits[hash] = struct{}{}
+		}
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Indexed %d checkpoint(s) linked to %d commit(s).\n", len(idx.Checkpoints), len(commits))
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build integration
+
+package integration
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+)
+
+// TestDebugSimulate_CommitCondensesSession verifies that a commit step in the
+// scratch clone runs Entire's git hooks: the commit gets a checkpoint trailer
+// and the session is condensed onto the metadata branch.
+func TestDebugSimulate_CommitCondensesSession(t *testing.T) {
+	t.Parallel()
+
+	env := NewTestEnv(t)
+	env.InitRepo()
+	env.WriteFile("README.md", "# Test\n")
+	env.GitAdd("README.md")
+	env.GitCommit("Initial commit")
+	// Not local_dev: the clone's git hooks must call the entire binary on PATH.
+	env.WriteFile(filepath.Join(paths.EntireDir, paths.SettingsFileName), `{"strategy": "manual-commit"}`+"\n")
+
+	scenario := filepath.Join(t.TempDir(), "scenario.yaml")
+	if err := os.WriteFile(scenario, []byte(`
+steps:
+  - prompt: Add a greeting
+    files:
+      - path: hello.go
+        content: "package main\n"
+  - commit: Add greeting
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(getTestBinary(), "debug", "simulate", "--keep", scenario)
+	cmd.Dir = env.RepoDir
+	cmd.Env = append(env.cliEnv(), "PATH="+filepath.Dir(getTestBinary())+string(os.PathListSeparator)+os.Getenv("PATH"))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("debug simulate failed: %v\n%s", err, output)
+	}
+
+	match := regexp.MustCompile(`Scratch clone kept at (\S+)`).FindSubmatch(output)
+	if match == nil {
+		t.Fatalf("output does not name the scratch clone:\n%s", output)
+	}
+	clone := &TestEnv{T: t, RepoDir: string(match[1])}
+	t.Cleanup(func() { os.RemoveAll(clone.RepoDir) })
+
+	message := clone.GetCommitMessage(clone.GetHeadHash())
+	cpID, ok := trailers.ParseCheckpoint(message)
+	if !ok {
+		t.Fatalf("simulated commit has no checkpoint trailer:\n%s", message)
+	}
+	if !clone.FileExistsInBranch(paths.MetadataBranchName, cpID.Path()+"/"+paths.MetadataFileName) {
+		t.Errorf("checkpoint %s not found on %s", cpID, paths.MetadataBranchName)
+	}
+	if strings.Contains(string(output), "git commit failed") {
+		t.Errorf("commit step failed:\n%s", output)
+	}
+}
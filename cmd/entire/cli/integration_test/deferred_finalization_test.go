@@ -132,15 +132,15 @@ func TestShadow_DeferredTranscriptFinalization(t *testing.T) {
 	}
 
 	// Read the provisional transcript
-	transcriptPath := SessionFilePath(checkpointID, paths.TranscriptFileName)
+	transcriptPath := SessionFilePath(checkpointID, paths.TranscriptFileNameCompressed)
 
-	// Verify the path structure matches expected sharded format: <id[:2]>/<id[2:]>/0/full.jsonl
+	// Verify the path structure matches expected sharded format: <id[:2]>/<id[2:]>/0/full.jsonl.zst
 	expectedPrefix := checkpointID[:2] + "/" + checkpointID[2:] + "/0/"
 	if !strings.HasPrefix(transcriptPath, expectedPrefix) {
 		t.Errorf("Unexpected path structure: got %s, expected prefix %s", transcriptPath, expectedPrefix)
 	}
 
-	provisionalContent, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	provisionalContent, found := env.ReadSessionTranscriptFromBranch(checkpointID)
 	if !found {
 		t.Fatalf("Provisional transcript should exist at %s", transcriptPath)
 	}
@@ -176,7 +176,7 @@ func TestShadow_DeferredTranscriptFinalization(t *testing.T) {
 	}
 
 	// Read the finalized transcript
-	finalContent, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	finalContent, found := env.ReadSessionTranscriptFromBranch(checkpointID)
 	if !found {
 		t.Fatalf("Finalized transcript should exist at %s", transcriptPath)
 	}
@@ -299,7 +299,7 @@ func TestShadow_AmendPreservesTrailer(t *testing.T) {
 		t.Errorf("Checkpoint metadata should still exist at %s after amend", summaryPath)
 	}
 
-	transcriptPath := SessionFilePath(originalCheckpointID, paths.TranscriptFileName)
+	transcriptPath := SessionFilePath(originalCheckpointID, paths.TranscriptFileNameCompressed)
 	if !env.FileExistsInBranch(paths.MetadataBranchName, transcriptPath) {
 		t.Errorf("Transcript should still exist at %s after amend", transcriptPath)
 	}
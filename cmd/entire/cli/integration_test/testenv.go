@@ -27,6 +27,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/format/config"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/klauspost/compress/zstd"
 )
 
 // testBinaryPath holds the path to the CLI binary built once in TestMain.
@@ -879,6 +880,29 @@ func (env *TestEnv) ReadFileFromBranch(branchName, filePath string) (string, boo
 	return content, true
 }
 
+// ReadSessionTranscriptFromBranch reads the first session's transcript of a
+// committed checkpoint from the metadata branch, decompressing full.jsonl.zst
+// when present and falling back to an uncompressed full.jsonl. Test
+// transcripts are small, so chunked transcripts aren't handled.
+func (env *TestEnv) ReadSessionTranscriptFromBranch(checkpointID string) (string, bool) {
+	env.T.Helper()
+
+	compressed, found := env.ReadFileFromBranch(paths.MetadataBranchName, SessionFilePath(checkpointID, paths.TranscriptFileNameCompressed))
+	if !found {
+		return env.ReadFileFromBranch(paths.MetadataBranchName, SessionFilePath(checkpointID, paths.TranscriptFileName))
+	}
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		env.T.Fatalf("failed to create zstd decoder: %v", err)
+	}
+	defer dec.Close()
+	content, err := dec.DecodeAll([]byte(compressed), nil)
+	if err != nil {
+		env.T.Fatalf("failed to decompress transcript: %v", err)
+	}
+	return string(content), true
+}
+
 // GetLatestCommitMessageOnBranch returns the commit message of the latest commit on the given branch.
 func (env *TestEnv) GetLatestCommitMessageOnBranch(branchName string) string {
 	env.T.Helper()
@@ -1299,7 +1323,7 @@ func ShardedCheckpointPath(checkpointID string) string {
 }
 
 // SessionFilePath returns the path to a session file within a checkpoint.
-// Session files are stored in numbered subdirectories using 0-based indexing (e.g., 0/full.jsonl).
+// Session files are stored in numbered subdirectories using 0-based indexing (e.g., 0/full.jsonl.zst).
 // This function constructs the path for the first (default) session.
 func SessionFilePath(checkpointID string, fileName string) string {
 	return id.CheckpointID(checkpointID).Path() + "/0/" + fileName
@@ -1332,7 +1356,7 @@ type CheckpointValidation struct {
 	// ExpectedPrompts are strings that should appear in prompt.txt
 	ExpectedPrompts []string
 
-	// ExpectedTranscriptContent are strings that should appear in full.jsonl
+	// ExpectedTranscriptContent are strings that should appear in the transcript
 	ExpectedTranscriptContent []string
 
 	// CheckpointsCount is the expected checkpoint count (0 means don't validate)
@@ -1343,7 +1367,7 @@ type CheckpointValidation struct {
 // It validates:
 // - Root metadata.json (CheckpointSummary) structure and expected fields
 // - Session metadata.json (CommittedMetadata) structure and expected fields
-// - Transcript file (full.jsonl) is valid JSONL and contains expected content
+// - Transcript file (full.jsonl.zst) is valid JSONL and contains expected content
 // - Content hash file (content_hash.txt) matches SHA256 of transcript
 // - Prompt file (prompt.txt) contains expected prompts
 func (env *TestEnv) ValidateCheckpoint(v CheckpointValidation) {
@@ -1470,14 +1494,13 @@ func (env *TestEnv) validateSessionMetadata(v CheckpointValidation) {
 	}
 }
 
-// validateTranscriptJSONL validates that full.jsonl exists and is valid JSONL.
+// validateTranscriptJSONL validates that the transcript exists and is valid JSONL.
 func (env *TestEnv) validateTranscriptJSONL(checkpointID string, expectedContent []string) {
 	env.T.Helper()
 
-	transcriptPath := SessionFilePath(checkpointID, paths.TranscriptFileName)
-	content, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	content, found := env.ReadSessionTranscriptFromBranch(checkpointID)
 	if !found {
-		env.T.Fatalf("Transcript not found at %s", transcriptPath)
+		env.T.Fatalf("Transcript not found for checkpoint %s", checkpointID)
 	}
 
 	// Validate it's valid JSONL (each non-empty line should be valid JSON)
@@ -1511,11 +1534,10 @@ func (env *TestEnv) validateTranscriptJSONL(checkpointID string, expectedContent
 func (env *TestEnv) validateContentHash(checkpointID string) {
 	env.T.Helper()
 
-	// Read transcript
-	transcriptPath := SessionFilePath(checkpointID, paths.TranscriptFileName)
-	transcript, found := env.ReadFileFromBranch(paths.MetadataBranchName, transcriptPath)
+	// Read transcript (the hash covers the uncompressed content)
+	transcript, found := env.ReadSessionTranscriptFromBranch(checkpointID)
 	if !found {
-		env.T.Fatalf("Transcript not found at %s", transcriptPath)
+		env.T.Fatalf("Transcript not found for checkpoint %s", checkpointID)
 	}
 
 	// Read content hash
@@ -15,27 +15,39 @@ import (
 
 // Directory constants
 const (
-	EntireDir         = ".entire"
-	EntireTmpDir      = ".entire/tmp"
-	EntireMetadataDir = ".entire/metadata"
+	EntireDir           = ".entire"
+	EntireTmpDir        = ".entire/tmp"
+	EntireMetadataDir   = ".entire/metadata"
+	EntireRecordingsDir = ".entire/recordings"
 )
 
 // Metadata file names
 const (
-	ContextFileName          = "context.md"
-	PromptFileName           = "prompt.txt"
-	SummaryFileName          = "summary.txt"
-	TranscriptFileName       = "full.jsonl"
-	TranscriptFileNameLegacy = "full.log"
-	MetadataFileName         = "metadata.json"
-	CheckpointFileName       = "checkpoint.json"
-	ContentHashFileName      = "content_hash.txt"
-	SettingsFileName         = "settings.json"
+	ContextFileName              = "context.md"
+	PromptFileName               = "prompt.txt"
+	SummaryFileName              = "summary.txt"
+	TranscriptFileName           = "full.jsonl"
+	TranscriptFileNameCompressed = "full.jsonl.zst"
+	TranscriptFileNameLegacy     = "full.log"
+	NormalizedFileName           = "normalized.jsonl"
+	NormalizedFileNameCompressed = "normalized.jsonl.zst"
+	MetadataFileName             = "metadata.json"
+	CheckpointFileName           = "checkpoint.json"
+	ContentHashFileName          = "content_hash.txt"
+	SettingsFileName             = "settings.json"
 )
 
 // MetadataBranchName is the orphan branch used by auto-commit and manual-commit strategies to store metadata
 const MetadataBranchName = "entire/checkpoints/v1"
 
+// CheckpointNotesRef is the git notes ref that links commits to checkpoints
+// when commit messages can't carry the Entire-Checkpoint trailer.
+const CheckpointNotesRef = "refs/notes/entire"
+
+// WorkBranchPrefix is the prefix of the per-session branches the session-branch
+// strategy commits agent turns to (entire/work/<session-id>).
+const WorkBranchPrefix = "entire/work/"
+
 // CheckpointPath returns the sharded storage path for a checkpoint ID.
 // Uses first 2 characters as shard (256 buckets), remaining as folder name.
 // Example: "a3b2c4d5e6f7" -> "a3/b2c4d5e6f7"
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/cobra"
+)
+
+// pruneOptions are the flags of `entire prune`.
+type pruneOptions struct {
+	DryRun bool
+	Force  bool
+	Remote string
+}
+
+func newPruneCmd() *cobra.Command {
+	var opts pruneOptions
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove committed checkpoints according to the retention policy",
+		Long: `Prune removes committed checkpoints that fall outside the retention policy
+configured in .entire/settings.json:
+
+  "retention": {
+    "max_age": "90d",
+    "max_total_size": "500MB",
+    "keep_linked_to_default_branch": true,
+    "keep_summaries": true
+  }
+
+Checkpoints older than max_age are pruned, then the oldest remaining ones
+until all checkpoints fit in max_total_size. With keep_linked_to_default_branch,
+checkpoints referenced by a commit on the default branch are always kept. With
+keep_summaries, only transcripts are removed and metadata, prompts and
+summaries are kept.
+
+Pruning rewrites the history of the entire/checkpoints/v1 branch so the
+removed content is really gone. The remote branch is merged first so no one
+else's checkpoints are lost, and the result is force-pushed with a lease: if
+someone pushes checkpoints in the meantime, the push is rejected and you can
+run 'entire prune' again. Other clones drop the pruned content the next time
+they push checkpoints.
+
+Use --dry-run to see what would be pruned without changing anything.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runPrune(cmd.Context(), cmd.OutOrStdout(), opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Show what would be pruned without changing anything")
+	cmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Skip the confirmation prompt")
+	cmd.Flags().StringVar(&opts.Remote, "remote", "origin", "Remote whose checkpoints branch is synced and force-pushed")
+
+	return cmd
+}
+
+func runPrune(ctx context.Context, w io.Writer, opts pruneOptions) error {
+	s, err := settings.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load settings: %w", err)
+	}
+	policy, err := strategy.ParseRetentionPolicy(s.Retention)
+	if err != nil {
+		return err //nolint:wrapcheck // Already names the setting
+	}
+	if policy.IsEmpty() {
+		fmt.Fprintln(w, "No retention policy configured. Set retention.max_age or retention.max_total_size in .entire/settings.json.")
+		return nil
+	}
+
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+
+	// Sync with the remote branch before pruning, so the force-push doesn't
+	// drop checkpoints others pushed.
+	syncRemote := false
+	if !opts.DryRun && s.CheckpointStoreType() == settings.CheckpointStoreGit && !s.IsPushSessionsDisabled() {
+		_, err := repo.Remote(opts.Remote)
+		syncRemote = err == nil
+	}
+	var lease plumbing.Hash
+	if syncRemote {
+		fmt.Fprintf(w, "Syncing checkpoints with %s...\n", opts.Remote)
+		if lease, err = strategy.SyncMetadataBranchRemote(ctx, repo, opts.Remote); err != nil {
+			return fmt.Errorf("failed to sync with %s: %w", opts.Remote, err)
+		}
+	}
+
+	plan, err := strategy.PlanRetention(ctx, repo, store, policy, time.Now())
+	if err != nil {
+		return err //nolint:wrapcheck // Already descriptive
+	}
+	printPrunePlan(w, policy, plan, strategy.GetDefaultBranchName(repo))
+	if plan.IsEmpty() {
+		return nil
+	}
+	if opts.DryRun {
+		fmt.Fprintln(w, "\nDry run: nothing was changed.")
+		return nil
+	}
+
+	if !opts.Force {
+		var confirmed bool
+		form := NewAccessibleForm(
+			huh.NewGroup(
+				huh.NewConfirm().
+					Title("Prune these checkpoints? This rewrites the checkpoints branch.").
+					Value(&confirmed),
+			),
+		)
+		if err := form.Run(); err != nil {
+			if errors.Is(err, huh.ErrUserAborted) {
+				return nil
+			}
+			return fmt.Errorf("failed to get confirmation: %w", err)
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	if err := strategy.ApplyRetention(ctx, store, plan); err != nil {
+		return err //nolint:wrapcheck // Already descriptive
+	}
+	fmt.Fprintf(w, "\nPruned %d checkpoint(s), freeing %s.\n", len(plan.Delete)+len(plan.DropTranscripts), formatPruneSize(plan.FreedSize))
+
+	if syncRemote {
+		fmt.Fprintf(w, "Force-pushing checkpoints to %s...\n", opts.Remote)
+		err := strategy.PushPrunedMetadataBranch(ctx, opts.Remote, lease)
+		if errors.Is(err, strategy.ErrPruneLeaseRejected) {
+			return fmt.Errorf("checkpoints were pushed to %s while pruning; run 'entire prune' again to include them", opts.Remote)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to push pruned checkpoints: %w", err)
+		}
+	}
+	return nil
+}
+
+// printPrunePlan prints the retention policy and what it prunes.
+func printPrunePlan(w io.Writer, policy strategy.RetentionPolicy, plan *strategy.RetentionPlan, defaultBranch string) {
+	var rules []string
+	if policy.MaxAge > 0 {
+		rules = append(rules, "max age "+strategy.FormatRetentionAge(policy.MaxAge))
+	}
+	if policy.MaxTotalSize > 0 {
+		rules = append(rules, "max total size "+formatPruneSize(policy.MaxTotalSize))
+	}
+	if policy.KeepLinkedToDefaultBranch {
+		if defaultBranch == "" {
+			defaultBranch = "the default branch"
+		}
+		rules = append(rules, "keep checkpoints linked to "+defaultBranch)
+	}
+	if policy.KeepSummaries {
+		rules = append(rules, "keep summaries")
+	}
+	fmt.Fprintf(w, "Retention policy: %s\n", strings.Join(rules, ", "))
+	fmt.Fprintf(w, "Checkpoints use %s.\n", formatPruneSize(plan.TotalSize))
+
+	if plan.IsEmpty() {
+		fmt.Fprintln(w, "\nNothing to prune.")
+		return
+	}
+	fmt.Fprintln(w)
+	for _, c := range plan.Delete {
+		fmt.Fprintf(w, "  delete            %s  %s  %9s  %s\n", c.CheckpointID, c.CreatedAt.Local().Format(time.DateOnly), formatPruneSize(c.Size.Total), c.Reason)
+	}
+	for _, c := range plan.DropTranscripts {
+		fmt.Fprintf(w, "  drop transcripts  %s  %s  %9s  %s\n", c.CheckpointID, c.CreatedAt.Local().Format(time.DateOnly), formatPruneSize(c.Size.Transcripts), c.Reason)
+	}
+	if plan.Protected > 0 {
+		fmt.Fprintf(w, "\nKept %d checkpoint(s) linked to the default branch.\n", plan.Protected)
+	}
+	fmt.Fprintf(w, "\nPruning frees %s of %s.\n", formatPruneSize(plan.FreedSize), formatPruneSize(plan.TotalSize))
+}
+
+// formatPruneSize formats a byte count with a decimal unit.
+func formatPruneSize(n int64) string {
+	switch {
+	case n >= 1000*1000*1000:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1000*1000*1000))
+	case n >= 1000*1000:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1000*1000))
+	case n >= 1000:
+		return fmt.Sprintf("%.1f KB", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
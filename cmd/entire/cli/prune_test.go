@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/go-git/go-git/v5"
+)
+
+func setupPruneTestRepo(t *testing.T, settingsJSON string) *checkpoint.GitStore {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	paths.ClearRepoRootCache()
+	repo, err := git.PlainInit(tmpDir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".entire"), 0o755); err != nil {
+		t.Fatalf("failed to create .entire: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".entire", "settings.json"), []byte(settingsJSON), 0o644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	return checkpoint.NewGitStore(repo)
+}
+
+func writePruneTestCheckpoint(t *testing.T, store *checkpoint.GitStore, cpID id.CheckpointID) {
+	t.Helper()
+	if err := store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    "session-" + cpID.String(),
+		Strategy:     "manual-commit",
+		Prompts:      []string{"do the thing"},
+		Transcript:   []byte(`{"type":"user","message":{"content":"do the thing"}}` + "\n"),
+	}); err != nil {
+		t.Fatalf("WriteCommitted(%s) error = %v", cpID, err)
+	}
+}
+
+func TestRunPrune_NoPolicy(t *testing.T) {
+	setupPruneTestRepo(t, `{"strategy": "manual-commit"}`)
+
+	var stdout bytes.Buffer
+	if err := runPrune(context.Background(), &stdout, pruneOptions{Force: true, Remote: "origin"}); err != nil {
+		t.Fatalf("runPrune() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "No retention policy configured") {
+		t.Errorf("output = %q, want a note about the missing policy", stdout.String())
+	}
+}
+
+func TestRunPrune(t *testing.T) {
+	store := setupPruneTestRepo(t, `{"strategy": "manual-commit", "retention": {"max_total_size": "1B"}}`)
+	cpID := id.MustCheckpointID("b1e000000001")
+	writePruneTestCheckpoint(t, store, cpID)
+
+	var stdout bytes.Buffer
+	if err := runPrune(context.Background(), &stdout, pruneOptions{DryRun: true, Remote: "origin"}); err != nil {
+		t.Fatalf("runPrune(dry run) error = %v", err)
+	}
+	for _, want := range []string{"Retention policy: max total size 1 B", "delete", cpID.String(), "over the size limit", "Dry run: nothing was changed."} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("dry run output missing %q:\n%s", want, stdout.String())
+		}
+	}
+	if summary, err := store.ReadCommitted(context.Background(), cpID); err != nil || summary == nil {
+		t.Fatalf("dry run removed the checkpoint: %v, %v", summary, err)
+	}
+
+	stdout.Reset()
+	if err := runPrune(context.Background(), &stdout, pruneOptions{Force: true, Remote: "origin"}); err != nil {
+		t.Fatalf("runPrune() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Pruned 1 checkpoint(s)") {
+		t.Errorf("output missing the pruned count:\n%s", stdout.String())
+	}
+	if summary, err := store.ReadCommitted(context.Background(), cpID); err != nil || summary != nil {
+		t.Errorf("ReadCommitted() = %v, %v; want the checkpoint pruned", summary, err)
+	}
+}
+
+func TestRunPrune_KeepSummaries(t *testing.T) {
+	store := setupPruneTestRepo(t, `{"strategy": "manual-commit", "retention": {"max_total_size": "1B", "keep_summaries": true}}`)
+	cpID := id.MustCheckpointID("b1e000000002")
+	writePruneTestCheckpoint(t, store, cpID)
+
+	var stdout bytes.Buffer
+	if err := runPrune(context.Background(), &stdout, pruneOptions{Force: true, Remote: "origin"}); err != nil {
+		t.Fatalf("runPrune() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "drop transcripts") {
+		t.Errorf("output missing the dropped transcripts:\n%s", stdout.String())
+	}
+	content, err := store.ReadSessionContent(context.Background(), cpID, 0)
+	if err != nil || content.Prompts != "do the thing" || len(content.Transcript) != 0 {
+		t.Errorf("ReadSessionContent() = %+v, %v; want the prompt kept and the transcript dropped", content, err)
+	}
+
+	// Running again finds nothing left to prune.
+	stdout.Reset()
+	if err := runPrune(context.Background(), &stdout, pruneOptions{Force: true, Remote: "origin"}); err != nil {
+		t.Fatalf("runPrune() error = %v", err)
+	}
+	if !strings.Contains(stdout.String(), "Nothing to prune.") {
+		t.Errorf("second run output = %q, want nothing to prune", stdout.String())
+	}
+}
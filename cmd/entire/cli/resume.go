@@ -14,17 +14,18 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
-	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/charmbracelet/huh"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
 
 func newResumeCmd() *cobra.Command {
 	var force bool
+	var asAgent string
 
 	cmd := &cobra.Command{
 		Use:   "resume <branch>",
@@ -42,27 +43,41 @@ to fetch it.
 
 If newer commits without checkpoints exist on the branch (e.g., after merging main
 or cherry-picking from elsewhere), this operation will reset your Git status to the
-most recent commit with a checkpoint.  You'll be prompted to confirm resuming in this case.`,
+most recent commit with a checkpoint.  You'll be prompted to confirm resuming in this case.
+
+With --as, the session is handed off to a different agent: the checkpoint
+transcript is translated into that agent's format and written as a new session
+of that agent (e.g. continue a Claude Code session in Gemini CLI). Only the
+conversation carries over; tool calls become plain text. For checkpoints with
+several sessions, only the branch's session is handed off.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if checkDisabledGuard(cmd.OutOrStdout()) {
 				return nil
 			}
-			return runResume(args[0], force)
+			var target agent.SessionImporter
+			if asAgent != "" {
+				var err error
+				if target, err = resolveHandoffAgent(asAgent); err != nil {
+					return err
+				}
+			}
+			return runResume(args[0], force, target)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Resume from older checkpoint without confirmation")
+	cmd.Flags().StringVar(&asAgent, "as", "", "Resume the session in a different agent (e.g. gemini, claude-code)")
 
 	return cmd
 }
 
-func runResume(branchName string, force bool) error {
+func runResume(branchName string, force bool, target agent.SessionImporter) error {
 	// Check if we're already on this branch
 	currentBranch, err := GetCurrentBranch()
 	if err == nil && currentBranch == branchName {
 		// Already on the branch, skip checkout
-		return resumeFromCurrentBranch(branchName, force)
+		return resumeFromCurrentBranch(branchName, force, target)
 	}
 
 	// Check if branch exists locally
@@ -116,10 +131,10 @@ func runResume(branchName string, force bool) error {
 		fmt.Fprintf(os.Stderr, "Switched to branch '%s'\n", branchName)
 	}
 
-	return resumeFromCurrentBranch(branchName, force)
+	return resumeFromCurrentBranch(branchName, force, target)
 }
 
-func resumeFromCurrentBranch(branchName string, force bool) error {
+func resumeFromCurrentBranch(branchName string, force bool, target agent.SessionImporter) error {
 	repo, err := openRepository()
 	if err != nil {
 		return fmt.Errorf("not a git repository: %w", err)
@@ -154,21 +169,32 @@ func resumeFromCurrentBranch(branchName string, force bool) error {
 
 	checkpointID := result.checkpointID
 
-	// Get metadata branch tree for lookups
-	metadataTree, err := strategy.GetMetadataBranchTree(repo)
+	store, err := strategy.NewCheckpointStore(repo)
 	if err != nil {
-		// No local metadata branch, check if remote has it
-		return checkRemoteMetadata(repo, checkpointID)
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+
+	// A branch-backed store without a local metadata branch reads from origin;
+	// fetch the branch first so the session can be restored from it.
+	_, isGitStore := store.(*checkpoint.GitStore)
+	if isGitStore {
+		if _, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true); err != nil {
+			return checkRemoteMetadata(repo, checkpointID, target)
+		}
 	}
 
 	// Look up metadata from sharded path
-	metadata, err := strategy.ReadCheckpointMetadata(metadataTree, checkpointID.Path())
+	metadata, err := strategy.ReadCommittedCheckpointMetadata(context.Background(), store, checkpointID.Path())
 	if err != nil {
+		if !isGitStore {
+			fmt.Fprintf(os.Stderr, "Checkpoint '%s' found in commit but session metadata not available\n", checkpointID)
+			return nil
+		}
 		// Checkpoint exists in commit but no local metadata - check remote
-		return checkRemoteMetadata(repo, checkpointID)
+		return checkRemoteMetadata(repo, checkpointID, target)
 	}
 
-	return resumeSession(metadata.SessionID, checkpointID, force)
+	return resumeSession(metadata.SessionID, checkpointID, force, target)
 }
 
 // branchCheckpointResult contains the result of searching for a checkpoint on a branch.
@@ -197,8 +223,11 @@ func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheck
 		return nil, fmt.Errorf("failed to get HEAD commit: %w", err)
 	}
 
+	// Commits are linked by trailers, or by notes when trailers aren't allowed
+	notes := strategy.LoadCheckpointNotes(repo)
+
 	// First, check if HEAD itself has a checkpoint (most common case)
-	if cpID, found := trailers.ParseCheckpoint(headCommit.Message); found {
+	if cpID, found := notes.ParseCheckpoint(headCommit); found {
 		result.checkpointID = cpID
 		result.commitHash = head.Hash().String()
 		result.commitMessage = headCommit.Message
@@ -221,31 +250,31 @@ func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheck
 
 	// If we can't find a default branch, or we're on it, just walk all commits
 	if defaultBranch == "" || defaultBranch == branchName {
-		return findCheckpointInHistory(headCommit, nil), nil
+		return findCheckpointInHistory(notes, headCommit, nil), nil
 	}
 
 	// Get the default branch reference
 	defaultRef, err := repo.Reference(plumbing.NewBranchReferenceName(defaultBranch), true)
 	if err != nil {
 		// Default branch doesn't exist locally, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(notes, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	defaultCommit, err := repo.CommitObject(defaultRef.Hash())
 	if err != nil {
 		// Can't get default commit, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(notes, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	// Find merge base
 	mergeBase, err := headCommit.MergeBase(defaultCommit)
 	if err != nil || len(mergeBase) == 0 {
 		// No common ancestor, fall back to walking all commits
-		return findCheckpointInHistory(headCommit, nil), nil //nolint:nilerr // Intentional fallback
+		return findCheckpointInHistory(notes, headCommit, nil), nil //nolint:nilerr // Intentional fallback
 	}
 
 	// Walk from HEAD to merge base, looking for checkpoint
-	return findCheckpointInHistory(headCommit, &mergeBase[0].Hash), nil
+	return findCheckpointInHistory(notes, headCommit, &mergeBase[0].Hash), nil
 }
 
 // findCheckpointInHistory walks commit history from start looking for a checkpoint trailer.
@@ -253,7 +282,7 @@ func findBranchCheckpoint(repo *git.Repository, branchName string) (*branchCheck
 // Returns the first checkpoint found and info about commits between HEAD and the checkpoint.
 // It distinguishes between merge commits (bringing in other branches) and regular commits
 // (actual branch work) to avoid false warnings after merging main.
-func findCheckpointInHistory(start *object.Commit, stopAt *plumbing.Hash) *branchCheckpointResult {
+func findCheckpointInHistory(notes *strategy.CheckpointNotes, start *object.Commit, stopAt *plumbing.Hash) *branchCheckpointResult {
 	result := &branchCheckpointResult{}
 	branchWorkCommits := 0 // Regular commits without checkpoints (actual work)
 	const maxCommits = 100 // Limit search depth
@@ -266,8 +295,8 @@ func findCheckpointInHistory(start *object.Commit, stopAt *plumbing.Hash) *branc
 			break
 		}
 
-		// Check for checkpoint trailer
-		if cpID, found := trailers.ParseCheckpoint(current.Message); found {
+		// Check for checkpoint trailer or note
+		if cpID, found := notes.ParseCheckpoint(current); found {
 			result.checkpointID = cpID
 			result.commitHash = current.Hash.String()
 			result.commitMessage = current.Message
@@ -324,7 +353,7 @@ func promptResumeFromOlderCheckpoint() (bool, error) {
 
 // checkRemoteMetadata checks if checkpoint metadata exists on origin/entire/checkpoints/v1
 // and automatically fetches it if available.
-func checkRemoteMetadata(repo *git.Repository, checkpointID id.CheckpointID) error {
+func checkRemoteMetadata(repo *git.Repository, checkpointID id.CheckpointID, target agent.SessionImporter) error {
 	// Try to get remote metadata branch tree
 	remoteTree, err := strategy.GetRemoteMetadataBranchTree(repo)
 	if err != nil {
@@ -349,25 +378,27 @@ func checkRemoteMetadata(repo *git.Repository, checkpointID id.CheckpointID) err
 	}
 
 	// Now resume the session with the fetched metadata
-	return resumeSession(metadata.SessionID, checkpointID, false)
+	return resumeSession(metadata.SessionID, checkpointID, false, target)
 }
 
 // resumeSession restores and displays the resume command for a specific session.
 // For multi-session checkpoints, restores ALL sessions and shows commands for each.
 // If force is false, prompts for confirmation when local logs have newer timestamps.
-func resumeSession(sessionID string, checkpointID id.CheckpointID, force bool) error {
+// If target is set and differs from the session's agent, the session is handed
+// off to target instead (see resumeSessionAs).
+func resumeSession(sessionID string, checkpointID id.CheckpointID, force bool, target agent.SessionImporter) error {
 	// Read checkpoint metadata first to get agent type (matching rewind pattern)
 	repo, err := openRepository()
 	if err != nil {
 		return fmt.Errorf("failed to open repository: %w", err)
 	}
 
-	metadataTree, err := strategy.GetMetadataBranchTree(repo)
+	store, err := strategy.NewCheckpointStore(repo)
 	if err != nil {
-		return fmt.Errorf("failed to get metadata branch: %w", err)
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
 	}
 
-	metadata, err := strategy.ReadCheckpointMetadata(metadataTree, checkpointID.Path())
+	metadata, err := strategy.ReadCommittedCheckpointMetadata(context.Background(), store, checkpointID.Path())
 	if err != nil {
 		return fmt.Errorf("failed to read checkpoint metadata: %w", err)
 	}
@@ -392,6 +423,10 @@ func resumeSession(sessionID string, checkpointID id.CheckpointID, force bool) e
 		return fmt.Errorf("failed to get repository root: %w", err)
 	}
 
+	if target != nil && target.Name() != ag.Name() {
+		return resumeSessionAs(ctx, store, sessionID, checkpointID, ag, target, repoRoot)
+	}
+
 	sessionDir, err := ag.GetSessionDir(repoRoot)
 	if err != nil {
 		return fmt.Errorf("failed to determine session directory: %w", err)
@@ -415,9 +450,12 @@ func resumeSession(sessionID string, checkpointID id.CheckpointID, force bool) e
 		}
 
 		sessions, restoreErr := restorer.RestoreLogsOnly(point, force)
+		if errors.Is(restoreErr, checkpoint.ErrEncryptedContent) {
+			return restoreErr
+		}
 		if restoreErr != nil || len(sessions) == 0 {
 			// Fall back to single-session restore (e.g., old checkpoints without agent metadata)
-			return resumeSingleSession(ctx, ag, sessionID, checkpointID, repoRoot, force)
+			return resumeSingleSession(ctx, store, ag, sessionID, checkpointID, repoRoot, force)
 		}
 
 		logging.Debug(ctx, "resume session completed",
@@ -466,13 +504,13 @@ func resumeSession(sessionID string, checkpointID id.CheckpointID, force bool) e
 	}
 
 	// Strategy doesn't support LogsOnlyRestorer, fall back to single session
-	return resumeSingleSession(ctx, ag, sessionID, checkpointID, repoRoot, force)
+	return resumeSingleSession(ctx, store, ag, sessionID, checkpointID, repoRoot, force)
 }
 
 // resumeSingleSession restores a single session (fallback when multi-session restore fails).
 // Always overwrites existing session logs to ensure consistency with checkpoint state.
 // If force is false, prompts for confirmation when local log has newer timestamps.
-func resumeSingleSession(ctx context.Context, ag agent.Agent, sessionID string, checkpointID id.CheckpointID, repoRoot string, force bool) error {
+func resumeSingleSession(ctx context.Context, store checkpoint.CommittedStore, ag agent.Agent, sessionID string, checkpointID id.CheckpointID, repoRoot string, force bool) error {
 	sessionLogPath, err := resolveTranscriptPath(sessionID, ag)
 	if err != nil {
 		return fmt.Errorf("failed to resolve transcript path: %w", err)
@@ -488,7 +526,7 @@ func resumeSingleSession(ctx context.Context, ag agent.Agent, sessionID string,
 		return nil
 	}
 
-	logContent, _, err := checkpoint.LookupSessionLog(checkpointID)
+	logContent, _, err := store.GetSessionLog(checkpointID)
 	if err != nil {
 		if errors.Is(err, checkpoint.ErrCheckpointNotFound) || errors.Is(err, checkpoint.ErrNoTranscript) {
 			logging.Debug(ctx, "resume session completed (no metadata)",
@@ -569,6 +607,75 @@ func resumeSingleSession(ctx context.Context, ag agent.Agent, sessionID string,
 	return nil
 }
 
+// resolveHandoffAgent returns the agent named by --as, which must be able to
+// import sessions from other agents.
+func resolveHandoffAgent(name string) (agent.SessionImporter, error) {
+	ag, err := agent.Get(agent.AgentName(name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown agent %q (available: %s)", name, JoinAgentNames(agent.List()))
+	}
+	importer, ok := ag.(agent.SessionImporter)
+	if !ok {
+		return nil, fmt.Errorf("agent %s can't resume sessions from other agents", ag.Type())
+	}
+	return importer, nil
+}
+
+// resumeSessionAs hands a checkpoint session off to a different agent: the
+// transcript is normalized, rebuilt in the target agent's native format, and
+// written as a new session of that agent. The new session gets a fresh ID,
+// since the original ID belongs to the source agent.
+func resumeSessionAs(ctx context.Context, store checkpoint.CommittedStore, sessionID string, checkpointID id.CheckpointID, source agent.Agent, target agent.SessionImporter, repoRoot string) error {
+	content, err := store.ReadSessionContentByID(ctx, checkpointID, sessionID)
+	if err != nil {
+		// Older checkpoints may not record the session ID; use the latest session
+		content, err = store.ReadLatestSessionContent(ctx, checkpointID)
+		if err != nil {
+			return fmt.Errorf("failed to read session from checkpoint: %w", err)
+		}
+	}
+	if len(content.Transcript) == 0 && len(content.NormalizedTranscript) == 0 {
+		return fmt.Errorf("checkpoint %s has no transcript for session %s", checkpointID, sessionID)
+	}
+
+	sourceType := content.Metadata.Agent
+	if sourceType == "" {
+		sourceType = source.Type()
+	}
+	entries, err := agent.ImportEntries(content.NormalizedTranscript, content.Transcript, sourceType)
+	if err != nil {
+		if errors.Is(err, agent.ErrNormalizeUnsupported) {
+			return fmt.Errorf("%s sessions can't be handed off to another agent", sourceType)
+		}
+		return fmt.Errorf("failed to translate session: %w", err)
+	}
+
+	newSessionID := uuid.NewString()
+	sess, err := target.ImportSession(repoRoot, newSessionID, entries)
+	if err != nil {
+		return fmt.Errorf("failed to translate session for %s: %w", target.Type(), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(sess.SessionRef), 0o750); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	if err := target.WriteSession(sess); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+
+	logging.Debug(ctx, "resume session handed off",
+		slog.String("checkpoint_id", checkpointID.String()),
+		slog.String("session_id", sessionID),
+		slog.String("target_agent", string(target.Name())),
+		slog.String("target_session_id", newSessionID),
+	)
+
+	fmt.Fprintf(os.Stderr, "Session %s (%s) handed off to %s\n", sessionID, sourceType, target.Type())
+	fmt.Fprintf(os.Stderr, "Session restored to: %s\n", sess.SessionRef)
+	fmt.Fprintf(os.Stderr, "\nTo continue this session, run:\n")
+	fmt.Fprintf(os.Stderr, "  %s\n", target.FormatResumeCommand(newSessionID))
+	return nil
+}
+
 func promptFetchFromRemote(branchName string) (bool, error) {
 	var confirmed bool
 
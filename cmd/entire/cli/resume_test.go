@@ -1,12 +1,16 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
@@ -173,7 +177,7 @@ func TestResumeFromCurrentBranch_NoCheckpoint(t *testing.T) {
 	setupResumeTestRepo(t, tmpDir, false)
 
 	// Run resumeFromCurrentBranch - should not error, just report no checkpoint found
-	err := resumeFromCurrentBranch("master", false)
+	err := resumeFromCurrentBranch("master", false, nil)
 	if err != nil {
 		t.Errorf("resumeFromCurrentBranch() returned error for commit without checkpoint: %v", err)
 	}
@@ -229,7 +233,7 @@ func TestResumeFromCurrentBranch_WithEntireCheckpointTrailer(t *testing.T) {
 	}
 
 	// Run resumeFromCurrentBranch
-	err := resumeFromCurrentBranch("master", false)
+	err := resumeFromCurrentBranch("master", false, nil)
 	if err != nil {
 		t.Errorf("resumeFromCurrentBranch() returned error: %v", err)
 	}
@@ -265,7 +269,7 @@ func TestRunResume_AlreadyOnBranch(t *testing.T) {
 	}
 
 	// Run resume on the branch we're already on - should skip checkout
-	err := runResume("feature", false)
+	err := runResume("feature", false, nil)
 	// Should not error (no session, but shouldn't error)
 	if err != nil {
 		t.Errorf("runResume() returned error when already on branch: %v", err)
@@ -279,7 +283,7 @@ func TestRunResume_BranchDoesNotExist(t *testing.T) {
 	setupResumeTestRepo(t, tmpDir, false)
 
 	// Run resume on a branch that doesn't exist
-	err := runResume("nonexistent", false)
+	err := runResume("nonexistent", false, nil)
 	if err == nil {
 		t.Error("runResume() expected error for nonexistent branch, got nil")
 	}
@@ -298,7 +302,7 @@ func TestRunResume_UncommittedChanges(t *testing.T) {
 	}
 
 	// Run resume - should fail due to uncommitted changes
-	err := runResume("feature", false)
+	err := runResume("feature", false, nil)
 	if err == nil {
 		t.Error("runResume() expected error for uncommitted changes, got nil")
 	}
@@ -501,7 +505,7 @@ func TestCheckRemoteMetadata_MetadataExistsOnRemote(t *testing.T) {
 	// Call checkRemoteMetadata - should find it on remote and attempt to fetch
 	// In this test environment without a real origin remote, the fetch will fail
 	// but it should return a SilentError (user-friendly error message already printed)
-	err = checkRemoteMetadata(repo, checkpointID)
+	err = checkRemoteMetadata(repo, checkpointID, nil)
 	if err == nil {
 		t.Error("checkRemoteMetadata() should return SilentError when fetch fails")
 	} else {
@@ -526,7 +530,7 @@ func TestCheckRemoteMetadata_NoRemoteMetadataBranch(t *testing.T) {
 	// Don't create any remote ref - simulating no remote entire/checkpoints/v1
 
 	// Call checkRemoteMetadata - should handle gracefully (no remote branch)
-	err := checkRemoteMetadata(repo, "nonexistent123")
+	err := checkRemoteMetadata(repo, "nonexistent123", nil)
 	if err != nil {
 		t.Errorf("checkRemoteMetadata() returned error when no remote branch: %v", err)
 	}
@@ -561,7 +565,7 @@ func TestCheckRemoteMetadata_CheckpointNotOnRemote(t *testing.T) {
 	}
 
 	// Call checkRemoteMetadata with a DIFFERENT checkpoint ID (not on remote)
-	err = checkRemoteMetadata(repo, "abcd12345678")
+	err = checkRemoteMetadata(repo, "abcd12345678", nil)
 	if err != nil {
 		t.Errorf("checkRemoteMetadata() returned error for missing checkpoint: %v", err)
 	}
@@ -622,7 +626,7 @@ func TestResumeFromCurrentBranch_FallsBackToRemote(t *testing.T) {
 	// Run resumeFromCurrentBranch - should fall back to remote and attempt fetch
 	// In this test environment without a real origin remote, the fetch will fail
 	// but it should return a SilentError (user-friendly error message already printed)
-	err = resumeFromCurrentBranch("master", false)
+	err = resumeFromCurrentBranch("master", false, nil)
 	if err == nil {
 		t.Error("resumeFromCurrentBranch() should return SilentError when fetch fails")
 	} else {
@@ -632,3 +636,80 @@ func TestResumeFromCurrentBranch_FallsBackToRemote(t *testing.T) {
 		}
 	}
 }
+
+func TestResolveHandoffAgent(t *testing.T) {
+	t.Parallel()
+
+	if _, err := resolveHandoffAgent("gemini"); err != nil {
+		t.Errorf("resolveHandoffAgent(gemini) error = %v", err)
+	}
+	if _, err := resolveHandoffAgent("nonexistent"); err == nil {
+		t.Error("resolveHandoffAgent(nonexistent) should fail")
+	}
+	if _, err := resolveHandoffAgent("cursor"); err == nil {
+		t.Error("resolveHandoffAgent(cursor) should fail: Cursor can't import sessions")
+	}
+}
+
+func TestResumeSessionAs_ClaudeToGemini(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	geminiDir := filepath.Join(tmpDir, "gemini-chats")
+	t.Setenv("ENTIRE_TEST_GEMINI_PROJECT_DIR", geminiDir)
+
+	repo, _, _ := setupResumeTestRepo(t, tmpDir, false)
+
+	sessionID := "4f8c1176-7025-4530-a860-c6fc4c63a150"
+	cpID := id.MustCheckpointID("ab12cd34ef56")
+	transcript := `{"type":"user","uuid":"u1","message":{"content":"Add a README"}}
+{"type":"assistant","uuid":"a1","message":{"content":[{"type":"text","text":"Adding it."},{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"README.md"}}]}}
+`
+	store := checkpoint.NewGitStore(repo)
+	if err := store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: cpID,
+		SessionID:    sessionID,
+		Strategy:     "manual-commit",
+		Agent:        agent.AgentTypeClaudeCode,
+		Transcript:   []byte(transcript),
+		AuthorName:   "Test User",
+		AuthorEmail:  "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	source, err := agent.Get(agent.AgentNameClaudeCode)
+	if err != nil {
+		t.Fatalf("Get(claude-code) error = %v", err)
+	}
+	target, err := resolveHandoffAgent("gemini")
+	if err != nil {
+		t.Fatalf("resolveHandoffAgent(gemini) error = %v", err)
+	}
+
+	if err := resumeSessionAs(context.Background(), checkpoint.NewGitStore(repo), sessionID, cpID, source, target, tmpDir); err != nil {
+		t.Fatalf("resumeSessionAs() error = %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(geminiDir, "session-*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one Gemini session file, got %v (err %v)", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("failed to read Gemini session: %v", err)
+	}
+	prompts, err := geminicli.ExtractAllUserPrompts(data)
+	if err != nil {
+		t.Fatalf("ExtractAllUserPrompts() error = %v", err)
+	}
+	if len(prompts) != 1 || prompts[0] != "Add a README" {
+		t.Errorf("prompts = %q, want [Add a README]", prompts)
+	}
+	last, err := geminicli.ExtractLastAssistantMessage(data)
+	if err != nil {
+		t.Fatalf("ExtractLastAssistantMessage() error = %v", err)
+	}
+	if last != "Adding it.\n\n[Tool: Write README.md]" {
+		t.Errorf("last assistant message = %q", last)
+	}
+}
@@ -668,7 +668,11 @@ func restoreSessionTranscript(transcriptFile, sessionID string, agent agentpkg.A
 // Returns the session ID that was actually used (may differ from input if checkpoint provides one).
 func restoreSessionTranscriptFromStrategy(cpID id.CheckpointID, sessionID string, agent agentpkg.Agent) (string, error) {
 	// Get transcript content from checkpoint storage
-	content, returnedSessionID, err := checkpoint.LookupSessionLog(cpID)
+	store, err := openCheckpointStore()
+	if err != nil {
+		return "", err
+	}
+	content, returnedSessionID, err := store.GetSessionLog(cpID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get session log: %w", err)
 	}
@@ -720,6 +724,7 @@ func writeTranscriptToAgentSession(content []byte, sessionID string, agent agent
 	}
 
 	fmt.Fprintf(os.Stderr, "Writing transcript to: %s\n", sessionFile)
+	content = strategy.TranscriptToRestore(agent.Type(), sessionFile, sessionID, content)
 	if err := os.WriteFile(sessionFile, content, 0o600); err != nil {
 		return "", fmt.Errorf("failed to write transcript: %w", err)
 	}
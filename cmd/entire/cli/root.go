@@ -81,8 +81,18 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(newHooksCmd())
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newExplainCmd())
+	cmd.AddCommand(newIndexCmd())
+	cmd.AddCommand(newSearchCmd())
+	cmd.AddCommand(newPruneCmd())
+	cmd.AddCommand(newVerifyCmd())
+	cmd.AddCommand(newExportCmd())
+	cmd.AddCommand(newImportCmd())
+	cmd.AddCommand(newAcceptCmd())
+	cmd.AddCommand(newDiscardCmd())
+	cmd.AddCommand(newSquashCmd())
 	cmd.AddCommand(newDebugCmd())
 	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newWatchCmd())
 	cmd.AddCommand(newSendAnalyticsCmd())
 	cmd.AddCommand(newCurlBashPostInstallCmd())
 
@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/search"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/entireio/cli/cmd/entire/cli/summarize"
+	"github.com/spf13/cobra"
+)
+
+// Field weights for ranking: what the user asked for and the summary of
+// what happened say more about a checkpoint than a passing mention in the
+// transcript.
+const (
+	searchWeightPrompt     = 3
+	searchWeightSummary    = 3
+	searchWeightContext    = 2
+	searchWeightTranscript = 1
+)
+
+// searchFilter restricts which checkpoints are searched.
+type searchFilter struct {
+	Agent  string
+	Branch string
+	Since  time.Time
+	Until  time.Time
+	Path   string
+}
+
+func newSearchCmd() *cobra.Command {
+	var filter searchFilter
+	var sinceFlag, untilFlag string
+	var limitFlag int
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search prompts, transcripts and summaries of committed checkpoints",
+		Long: `Search finds committed checkpoints whose prompts, context, AI summaries or
+transcripts match the query, ranked by relevance (BM25). Searching is local:
+it reads the checkpoint store directly and sends nothing anywhere.
+
+Each result shows the checkpoint ID, the commits that reference it and a
+snippet of the best matching text. Use 'entire explain --checkpoint <id>' to
+see the full checkpoint.
+
+Filters:
+  --agent    Only checkpoints created by this agent (e.g. claude-code, "Gemini CLI")
+  --branch   Only checkpoints created on this branch
+  --since    Only checkpoints created on or after this date (YYYY-MM-DD or RFC 3339)
+  --until    Only checkpoints created on or before this date (YYYY-MM-DD or RFC 3339)
+  --path     Only checkpoints that touched this file, directory or glob pattern
+
+Encrypted checkpoints are searched by their summaries only unless an identity
+file is configured.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+
+			var err error
+			if filter.Since, err = parseSearchDate(sinceFlag, false); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			if filter.Until, err = parseSearchDate(untilFlag, true); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+			if limitFlag < 1 {
+				return errors.New("--limit must be at least 1")
+			}
+
+			return runSearch(cmd.Context(), cmd.OutOrStdout(), cmd.ErrOrStderr(), strings.Join(args, " "), filter, limitFlag)
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.Agent, "agent", "", "Only search checkpoints created by this agent")
+	cmd.Flags().StringVar(&filter.Branch, "branch", "", "Only search checkpoints created on this branch")
+	cmd.Flags().StringVar(&sinceFlag, "since", "", "Only search checkpoints created on or after this date")
+	cmd.Flags().StringVar(&untilFlag, "until", "", "Only search checkpoints created on or before this date")
+	cmd.Flags().StringVar(&filter.Path, "path", "", "Only search checkpoints that touched this file, directory or glob")
+	cmd.Flags().IntVarP(&limitFlag, "limit", "n", 10, "Maximum number of results")
+
+	return cmd
+}
+
+func runSearch(ctx context.Context, w, errW io.Writer, query string, filter searchFilter, limit int) error {
+	if len(search.Tokenize(query)) == 0 {
+		return errors.New("query must contain at least one word")
+	}
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+
+	committed, err := strategy.ListCommittedCheckpoints(ctx, repo, store)
+	if err != nil {
+		return fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	infos := make(map[string]checkpoint.CommittedInfo)
+	var docs []search.Document
+	encrypted := 0
+	for _, info := range committed {
+		if !filter.matches(info) {
+			continue
+		}
+		doc, isEncrypted := searchDocument(ctx, store, info)
+		if isEncrypted {
+			encrypted++
+		}
+		infos[doc.ID] = info
+		docs = append(docs, doc)
+	}
+
+	if encrypted > 0 {
+		fmt.Fprintf(errW, "Note: %d encrypted checkpoint(s) were searched by summary only; configure encryption.identity_file to search their content.\n", encrypted)
+	}
+
+	results := search.Search(docs, query, limit)
+	if len(results) == 0 {
+		fmt.Fprintf(w, "No checkpoints match %q.\n", query)
+		return nil
+	}
+
+	linked, _ := strategy.LinkedCommits(repo) //nolint:errcheck // Results are still useful without their commits
+
+	for i, result := range results {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		info := infos[result.ID]
+		fmt.Fprintln(w, formatSearchHeader(info))
+		if commits := linked[info.CheckpointID]; len(commits) > 0 {
+			short := make([]string, 0, len(commits))
+			for _, hash := range commits {
+				short = append(short, hash[:min(7, len(hash))])
+			}
+			fmt.Fprintf(w, "  commits: %s\n", strings.Join(short, ", "))
+		}
+		fmt.Fprintf(w, "  %s: %s\n", result.Field, result.Snippet)
+	}
+	return nil
+}
+
+// formatSearchHeader formats the first line of a search result: checkpoint
+// ID, creation time, agent and branch.
+func formatSearchHeader(info checkpoint.CommittedInfo) string {
+	parts := []string{info.CheckpointID.String(), info.CreatedAt.Local().Format("2006-01-02 15:04")}
+	if info.Agent != "" {
+		parts = append(parts, string(info.Agent))
+	}
+	if info.Branch != "" {
+		parts = append(parts, info.Branch)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// searchDocument builds the search document for a checkpoint from all its
+// sessions. Reports whether content was skipped because it is encrypted.
+func searchDocument(ctx context.Context, store checkpoint.Store, info checkpoint.CommittedInfo) (search.Document, bool) {
+	var prompts, summaries, contexts, transcripts []string
+	isEncrypted := false
+	for i := range max(info.SessionCount, 1) {
+		content, err := store.ReadSessionContent(ctx, info.CheckpointID, i)
+		if errors.Is(err, checkpoint.ErrEncryptedContent) {
+			isEncrypted = true
+			if metadata := readSessionMetadata(ctx, store, info.CheckpointID, i); metadata != nil {
+				summaries = append(summaries, summaryText(metadata.Summary))
+			}
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		prompts = append(prompts, content.Prompts)
+		summaries = append(summaries, summaryText(content.Metadata.Summary))
+		contexts = append(contexts, content.Context)
+		transcripts = append(transcripts, transcriptText(content.Transcript, content.Metadata.Agent))
+	}
+
+	return search.Document{
+		ID: info.CheckpointID.String(),
+		Fields: []search.Field{
+			{Name: "prompt", Text: strings.Join(prompts, "\n"), Weight: searchWeightPrompt},
+			{Name: "summary", Text: strings.Join(summaries, "\n"), Weight: searchWeightSummary},
+			{Name: "context", Text: strings.Join(contexts, "\n"), Weight: searchWeightContext},
+			{Name: "transcript", Text: strings.Join(transcripts, "\n"), Weight: searchWeightTranscript},
+		},
+	}, isEncrypted
+}
+
+// readSessionMetadata reads a session's metadata.json, which stays readable
+// when the session's content is encrypted. Returns nil if it can't be read.
+func readSessionMetadata(ctx context.Context, store checkpoint.Store, checkpointID id.CheckpointID, sessionIndex int) *checkpoint.CommittedMetadata {
+	data, err := store.ReadCommittedFile(ctx, checkpointID.Path()+"/"+strconv.Itoa(sessionIndex)+"/"+paths.MetadataFileName)
+	if err != nil {
+		return nil
+	}
+	var metadata checkpoint.CommittedMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil
+	}
+	return &metadata
+}
+
+// summaryText flattens an AI summary into searchable text.
+func summaryText(summary *checkpoint.Summary) string {
+	if summary == nil {
+		return ""
+	}
+	lines := []string{summary.Intent, summary.Outcome}
+	lines = append(lines, summary.Learnings.Repo...)
+	for _, learning := range summary.Learnings.Code {
+		lines = append(lines, learning.Path+": "+learning.Finding)
+	}
+	lines = append(lines, summary.Learnings.Workflow...)
+	lines = append(lines, summary.Friction...)
+	lines = append(lines, summary.OpenItems...)
+	return strings.Join(lines, "\n")
+}
+
+// transcriptText extracts the prompts, responses and tool details from a
+// transcript, falling back to the raw transcript if it can't be parsed.
+func transcriptText(transcript []byte, agentType agent.AgentType) string {
+	if len(transcript) == 0 {
+		return ""
+	}
+	entries, err := summarize.BuildCondensedTranscriptFromBytes(transcript, agentType)
+	if err != nil || len(entries) == 0 {
+		return string(transcript)
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		switch entry.Type {
+		case summarize.EntryTypeTool:
+			lines = append(lines, entry.ToolName+" "+entry.ToolDetail)
+		case summarize.EntryTypeUser, summarize.EntryTypeAssistant:
+			lines = append(lines, entry.Content)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matches reports whether a checkpoint passes the filter.
+func (f searchFilter) matches(info checkpoint.CommittedInfo) bool {
+	if f.Agent != "" && !matchesAgent(info.Agent, f.Agent) {
+		return false
+	}
+	if f.Branch != "" && info.Branch != f.Branch {
+		return false
+	}
+	if !f.Since.IsZero() && info.CreatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && info.CreatedAt.After(f.Until) {
+		return false
+	}
+	if f.Path != "" && !touchesPath(info.FilesTouched, f.Path) {
+		return false
+	}
+	return true
+}
+
+// matchesAgent reports whether agentType is the agent named by filter,
+// given either as an agent type ("Claude Code") or a name ("claude-code").
+func matchesAgent(agentType agent.AgentType, filter string) bool {
+	if strings.EqualFold(string(agentType), filter) {
+		return true
+	}
+	ag, err := agent.Get(agent.AgentName(strings.ToLower(filter)))
+	return err == nil && ag.Type() == agentType
+}
+
+// touchesPath reports whether any of files is pattern, lies under the
+// directory pattern, or matches it as a glob.
+func touchesPath(files []string, pattern string) bool {
+	pattern = strings.TrimPrefix(path.Clean(pattern), "./")
+	for _, file := range files {
+		if file == pattern || strings.HasPrefix(file, pattern+"/") {
+			return true
+		}
+		if matched, err := path.Match(pattern, file); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSearchDate parses a YYYY-MM-DD date in local time or an RFC 3339
+// timestamp. With endOfDay, a plain date means the end of that day.
+func parseSearchDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a YYYY-MM-DD date or RFC 3339 timestamp", value)
+	}
+	return t, nil
+}
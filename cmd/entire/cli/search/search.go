@@ -0,0 +1,219 @@
+// Package search provides local full-text ranking of checkpoint content.
+//
+// Documents are ranked with BM25F: each document is made of named fields
+// (prompts, summary, transcript, ...) whose term frequencies are weighted
+// before BM25 scoring, so a match in a prompt counts for more than the same
+// match deep in a transcript.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/entireio/cli/cmd/entire/cli/stringutil"
+)
+
+// BM25 parameters. k1 controls term frequency saturation, b how strongly
+// scores are normalized by document length.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Snippet sizing, in bytes of source text around the first match.
+const (
+	snippetBefore = 60
+	snippetAfter  = 100
+)
+
+// Field is a named piece of a document's text.
+type Field struct {
+	Name string
+	Text string
+	// Weight multiplies the field's term frequencies. Zero means 1.
+	Weight float64
+}
+
+// Document is a unit of search results, e.g. a checkpoint.
+type Document struct {
+	ID     string
+	Fields []Field
+}
+
+// Result is a document matching a query.
+type Result struct {
+	ID    string
+	Score float64
+	// Field is the name of the field the snippet was taken from.
+	Field string
+	// Snippet is an excerpt of Field around the first match.
+	Snippet string
+}
+
+// Tokenize splits text into lowercase terms of letters and digits.
+// Single-character terms are dropped.
+func Tokenize(text string) []string {
+	var terms []string
+	for _, span := range tokenSpans(text) {
+		terms = append(terms, strings.ToLower(text[span.start:span.end]))
+	}
+	return terms
+}
+
+// Search ranks docs against query and returns up to limit matching
+// documents, best first. A limit of zero or less returns all matches.
+func Search(docs []Document, query string, limit int) []Result {
+	queryTerms := uniqueTerms(Tokenize(query))
+	if len(queryTerms) == 0 || len(docs) == 0 {
+		return nil
+	}
+
+	type docStats struct {
+		freqs  map[string]float64
+		length float64
+	}
+	stats := make([]docStats, len(docs))
+	docFreq := make(map[string]int, len(queryTerms))
+	var totalLength float64
+	for i, doc := range docs {
+		stats[i].freqs = make(map[string]float64, len(queryTerms))
+		for _, field := range doc.Fields {
+			weight := field.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			for _, term := range Tokenize(field.Text) {
+				stats[i].length += weight
+				if _, ok := queryTerms[term]; ok {
+					stats[i].freqs[term] += weight
+				}
+			}
+		}
+		totalLength += stats[i].length
+		for term := range stats[i].freqs {
+			docFreq[term]++
+		}
+	}
+	avgLength := totalLength / float64(len(docs))
+	if avgLength == 0 {
+		return nil
+	}
+
+	var results []Result
+	n := float64(len(docs))
+	for i, doc := range docs {
+		var score float64
+		for term, freq := range stats[i].freqs {
+			df := float64(docFreq[term])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := bm25K1 * (1 - bm25B + bm25B*stats[i].length/avgLength)
+			score += idf * freq * (bm25K1 + 1) / (freq + norm)
+		}
+		if score == 0 {
+			continue
+		}
+		field, snippet := bestSnippet(doc, queryTerms)
+		results = append(results, Result{ID: doc.ID, Score: score, Field: field, Snippet: snippet})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// span is the byte range of a term in a text.
+type span struct {
+	start, end int
+}
+
+// tokenSpans returns the byte ranges of the terms in text.
+func tokenSpans(text string) []span {
+	var spans []span
+	start := -1
+	runes := 0
+	flush := func(end int) {
+		if start >= 0 && runes > 1 {
+			spans = append(spans, span{start, end})
+		}
+		start, runes = -1, 0
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start < 0 {
+				start = i
+			}
+			runes++
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return spans
+}
+
+func uniqueTerms(terms []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		set[term] = struct{}{}
+	}
+	return set
+}
+
+// bestSnippet picks the field matching the most distinct query terms,
+// preferring earlier fields on ties, and returns an excerpt around its
+// first match.
+func bestSnippet(doc Document, queryTerms map[string]struct{}) (string, string) {
+	bestField, bestMatches := -1, 0
+	var bestSpan span
+	for i, field := range doc.Fields {
+		matched := make(map[string]bool)
+		var first span
+		for _, s := range tokenSpans(field.Text) {
+			term := strings.ToLower(field.Text[s.start:s.end])
+			if _, ok := queryTerms[term]; !ok {
+				continue
+			}
+			if len(matched) == 0 {
+				first = s
+			}
+			matched[term] = true
+		}
+		if len(matched) > bestMatches {
+			bestField, bestMatches, bestSpan = i, len(matched), first
+		}
+	}
+	if bestField < 0 {
+		return "", ""
+	}
+	field := doc.Fields[bestField]
+	return field.Name, excerpt(field.Text, bestSpan)
+}
+
+// excerpt returns the text around s on a single line, with ellipses where
+// it was cut.
+func excerpt(text string, s span) string {
+	start := max(0, s.start-snippetBefore)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	end := min(len(text), s.end+snippetAfter)
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	snippet := stringutil.CollapseWhitespace(text[start:end])
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(text) {
+		snippet += "..."
+	}
+	return snippet
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+	got := Tokenize("Fix the rate-limiter in api/v2_client.go, é2 a")
+	want := []string{"fix", "the", "rate", "limiter", "in", "api", "v2", "client", "go", "é2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestSearch_Ranking(t *testing.T) {
+	t.Parallel()
+	docs := []Document{
+		{ID: "transcript-only", Fields: []Field{
+			{Name: "transcript", Text: "we looked at the parser and later the rate limiter", Weight: 1},
+		}},
+		{ID: "prompt", Fields: []Field{
+			{Name: "prompt", Text: "add a rate limiter to the API", Weight: 3},
+			{Name: "transcript", Text: "added token bucket", Weight: 1},
+		}},
+		{ID: "unrelated", Fields: []Field{
+			{Name: "prompt", Text: "rename the config loader", Weight: 3},
+		}},
+	}
+
+	results := Search(docs, "Rate limiter", 0)
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	if want := []string{"prompt", "transcript-only"}; !slices.Equal(ids, want) {
+		t.Fatalf("Search() IDs = %v, want %v", ids, want)
+	}
+	if results[0].Field != "prompt" || results[0].Snippet != "add a rate limiter to the API" {
+		t.Errorf("top result snippet = %s: %q, want the prompt", results[0].Field, results[0].Snippet)
+	}
+
+	if got := Search(docs, "rate", 1); len(got) != 1 {
+		t.Errorf("Search() with limit 1 = %d results, want 1", len(got))
+	}
+	if got := Search(docs, "nothing matches", 0); len(got) != 0 {
+		t.Errorf("Search() = %v, want no results", got)
+	}
+	if got := Search(docs, "  ", 0); got != nil {
+		t.Errorf("Search() with empty query = %v, want nil", got)
+	}
+}
+
+func TestSearch_SnippetExcerpt(t *testing.T) {
+	t.Parallel()
+	long := strings.Repeat("lorem ipsum ", 20) + "the\n\nthrottle   setting " + strings.Repeat("dolor sit ", 20)
+	results := Search([]Document{{ID: "a", Fields: []Field{{Name: "context", Text: long}}}}, "throttle", 0)
+	if len(results) != 1 {
+		t.Fatalf("Search() = %d results, want 1", len(results))
+	}
+	snippet := results[0].Snippet
+	if !strings.HasPrefix(snippet, "...") || !strings.HasSuffix(snippet, "...") {
+		t.Errorf("snippet = %q, want ellipses on both ends", snippet)
+	}
+	if !strings.Contains(snippet, "the throttle setting") {
+		t.Errorf("snippet = %q, want collapsed whitespace around the match", snippet)
+	}
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestRunSearch(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	repo, err := git.PlainInit(tmpDir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+
+	store := checkpoint.NewGitStore(repo)
+	rateLimiter := id.MustCheckpointID("5ea000000001")
+	parser := id.MustCheckpointID("5ea000000002")
+	for _, opts := range []checkpoint.WriteCommittedOptions{
+		{
+			CheckpointID: rateLimiter,
+			SessionID:    "session-rate",
+			Strategy:     "manual-commit",
+			Branch:       "feature/limits",
+			Agent:        agent.AgentTypeClaudeCode,
+			FilesTouched: []string{"api/limiter.go"},
+			Prompts:      []string{"add a rate limiter to the API"},
+			Transcript:   []byte(`{"type":"user","message":{"content":"add a rate limiter to the API"}}` + "\n"),
+		},
+		{
+			CheckpointID: parser,
+			SessionID:    "session-parser",
+			Strategy:     "manual-commit",
+			Branch:       "master",
+			Agent:        agent.AgentTypeGemini,
+			FilesTouched: []string{"parser/parse.go"},
+			Prompts:      []string{"speed up the parser"},
+			Summary:      &checkpoint.Summary{Intent: "Faster parsing", Friction: []string{"the rate of allocations was high"}},
+		},
+	} {
+		if err := store.WriteCommitted(context.Background(), opts); err != nil {
+			t.Fatalf("WriteCommitted(%s) error = %v", opts.CheckpointID, err)
+		}
+	}
+
+	// Link the rate limiter checkpoint to a commit.
+	if err := os.WriteFile(filepath.Join(tmpDir, "limiter.go"), []byte("package api\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := wt.Add("limiter.go"); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	commitHash, err := wt.Commit(trailers.FormatCheckpoint("Add rate limiter", rateLimiter), &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		filter  searchFilter
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "ranks prompt matches first",
+			query: "rate limiter",
+			want:  []string{rateLimiter.String(), "commits: " + commitHash.String()[:7], "prompt: add a rate limiter to the API", parser.String()},
+		},
+		{
+			name:    "searches summaries",
+			query:   "allocations",
+			want:    []string{parser.String(), "summary: "},
+			notWant: []string{rateLimiter.String()},
+		},
+		{
+			name:    "filters by agent name",
+			query:   "rate",
+			filter:  searchFilter{Agent: "gemini"},
+			want:    []string{parser.String()},
+			notWant: []string{rateLimiter.String()},
+		},
+		{
+			name:    "filters by path",
+			query:   "rate",
+			filter:  searchFilter{Path: "api"},
+			want:    []string{rateLimiter.String()},
+			notWant: []string{parser.String()},
+		},
+		{
+			name:   "no matches",
+			query:  "rate",
+			filter: searchFilter{Branch: "release"},
+			want:   []string{`No checkpoints match "rate".`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if err := runSearch(context.Background(), &stdout, &stderr, tt.query, tt.filter, 10); err != nil {
+				t.Fatalf("runSearch() error = %v", err)
+			}
+			output := stdout.String()
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("output missing %q:\n%s", want, output)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(output, notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, output)
+				}
+			}
+			if len(tt.want) > 1 && strings.Index(output, tt.want[0]) > strings.Index(output, tt.want[len(tt.want)-1]) {
+				t.Errorf("results out of order:\n%s", output)
+			}
+		})
+	}
+}
+
+func TestSearchFilter_Matches(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	info := checkpoint.CommittedInfo{
+		CreatedAt:    created,
+		Agent:        agent.AgentTypeClaudeCode,
+		Branch:       "main",
+		FilesTouched: []string{"cmd/server/main.go"},
+	}
+	since, err := parseSearchDate("2026-03-10", false)
+	if err != nil {
+		t.Fatalf("parseSearchDate() error = %v", err)
+	}
+	until, err := parseSearchDate("2026-03-10", true)
+	if err != nil {
+		t.Fatalf("parseSearchDate() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter searchFilter
+		want   bool
+	}{
+		{"no filter", searchFilter{}, true},
+		{"agent type", searchFilter{Agent: "claude code"}, true},
+		{"agent name", searchFilter{Agent: "claude-code"}, true},
+		{"other agent", searchFilter{Agent: "codex"}, false},
+		{"branch", searchFilter{Branch: "main"}, true},
+		{"other branch", searchFilter{Branch: "dev"}, false},
+		{"same day", searchFilter{Since: since, Until: until}, true},
+		{"before since", searchFilter{Since: created.Add(time.Hour)}, false},
+		{"after until", searchFilter{Until: created.Add(-time.Hour)}, false},
+		{"exact path", searchFilter{Path: "cmd/server/main.go"}, true},
+		{"directory", searchFilter{Path: "./cmd/"}, true},
+		{"glob", searchFilter{Path: "cmd/*/*.go"}, true},
+		{"directory name prefix", searchFilter{Path: "cmd/serv"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.filter.matches(info); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSearchDate_Invalid(t *testing.T) {
+	t.Parallel()
+	if _, err := parseSearchDate("last week", false); err == nil {
+		t.Error("parseSearchDate() error = nil, want an error")
+	}
+}
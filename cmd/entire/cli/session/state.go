@@ -108,6 +108,11 @@ type State struct {
 	// sessions that have been condensed at least once. Cleared on new prompt.
 	LastCheckpointID id.CheckpointID `json:"last_checkpoint_id,omitempty"`
 
+	// LastCondensedCheckpointID is the checkpoint the session was most recently
+	// condensed into. Unlike LastCheckpointID it survives new prompts; it is the
+	// parent that delta-encoded transcripts are stored against.
+	LastCondensedCheckpointID id.CheckpointID `json:"last_condensed_checkpoint_id,omitempty"`
+
 	// AgentType identifies the agent that created this session (e.g., "Claude Code", "Gemini CLI", "Cursor")
 	AgentType agent.AgentType `json:"agent_type,omitempty"`
 
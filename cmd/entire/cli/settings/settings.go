@@ -49,6 +49,90 @@ type EntireSettings struct {
 	// Telemetry controls anonymous usage analytics.
 	// nil = not asked yet (show prompt), true = opted in, false = opted out
 	Telemetry *bool `json:"telemetry,omitempty"`
+
+	// ExternalAgents lists agent plugin executables to load in addition to the
+	// entire-agent-* executables found on PATH. Relative paths are resolved
+	// against the repository root. Only honored in settings.local.json.
+	ExternalAgents []string `json:"external_agents,omitempty"`
+
+	// Agents lists the agents Entire was enabled for (registry names, e.g.
+	// "claude-code"). `entire status` reports hook status for each of them.
+	Agents []string `json:"agents,omitempty"`
+
+	// CheckpointStore selects where committed checkpoints are kept.
+	// nil means the entire/checkpoints/v1 branch in the repository.
+	CheckpointStore *CheckpointStoreSettings `json:"checkpoint_store,omitempty"`
+
+	// Encryption configures age encryption of committed checkpoint content.
+	// nil means checkpoints are stored in plain text.
+	Encryption *EncryptionSettings `json:"encryption,omitempty"`
+
+	// Retention configures which committed checkpoints `entire prune`
+	// removes. nil means checkpoints are kept forever.
+	Retention *RetentionSettings `json:"retention,omitempty"`
+
+	// Ignore lists gitignore-syntax patterns for paths to leave out of
+	// checkpoint snapshots, files_touched, attribution and rewind, in
+	// addition to those in .entire/ignore.
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// Checkpoint store types accepted in CheckpointStoreSettings.Type.
+const (
+	CheckpointStoreGit        = "git"
+	CheckpointStoreFilesystem = "filesystem"
+)
+
+// CheckpointStoreSettings configures the committed checkpoint backend.
+type CheckpointStoreSettings struct {
+	// Type is "git" (default) or "filesystem".
+	Type string `json:"type,omitempty"`
+
+	// Path is the directory used by the filesystem store. Relative paths are
+	// resolved against the repository root.
+	Path string `json:"path,omitempty"`
+
+	// CompressionLevel is the zstd level (1-22) committed transcripts are
+	// written with; 0 stores them uncompressed. nil means the default (3).
+	CompressionLevel *int `json:"compression_level,omitempty"`
+
+	// DeltaTranscripts stores only the transcript lines a session appended
+	// since its previous checkpoint, instead of the whole transcript.
+	DeltaTranscripts bool `json:"delta_transcripts,omitempty"`
+}
+
+// EncryptionSettings configures age encryption of checkpoint transcripts,
+// prompts and context. Metadata, including AI summaries, stays readable.
+type EncryptionSettings struct {
+	// Recipients are the age public keys (age1...) checkpoint content is
+	// encrypted to. Usually committed in settings.json so the whole team
+	// encrypts to the same keys.
+	Recipients []string `json:"recipients,omitempty"`
+
+	// IdentityFile is the path to an age identity file used to decrypt
+	// checkpoint content. Usually set in settings.local.json. A leading "~/"
+	// is expanded to the home directory.
+	IdentityFile string `json:"identity_file,omitempty"`
+}
+
+// RetentionSettings configures the retention policy applied by `entire prune`.
+// A checkpoint is pruned when it is older than MaxAge, or when it is among the
+// oldest checkpoints and the checkpoints together exceed MaxTotalSize.
+type RetentionSettings struct {
+	// MaxAge is how long checkpoints are kept, e.g. "90d", "12w" or "720h".
+	MaxAge string `json:"max_age,omitempty"`
+
+	// MaxTotalSize caps the total size of committed checkpoints, e.g.
+	// "500MB" or "2GiB".
+	MaxTotalSize string `json:"max_total_size,omitempty"`
+
+	// KeepLinkedToDefaultBranch keeps checkpoints referenced by a commit on
+	// the default branch regardless of age and size.
+	KeepLinkedToDefaultBranch bool `json:"keep_linked_to_default_branch,omitempty"`
+
+	// KeepSummaries drops only the transcripts of pruned checkpoints and
+	// keeps their metadata, prompts and summaries.
+	KeepSummaries bool `json:"keep_summaries,omitempty"`
 }
 
 // Load loads the Entire settings from .entire/settings.json,
@@ -204,6 +288,89 @@ func mergeJSON(settings *EntireSettings, data []byte) error {
 		settings.Telemetry = &t
 	}
 
+	// Override external_agents if present
+	if externalAgentsRaw, ok := raw["external_agents"]; ok {
+		var agents []string
+		if err := json.Unmarshal(externalAgentsRaw, &agents); err != nil {
+			return fmt.Errorf("parsing external_agents field: %w", err)
+		}
+		settings.ExternalAgents = agents
+	}
+
+	// Override agents if present
+	if agentsRaw, ok := raw["agents"]; ok {
+		var agents []string
+		if err := json.Unmarshal(agentsRaw, &agents); err != nil {
+			return fmt.Errorf("parsing agents field: %w", err)
+		}
+		settings.Agents = agents
+	}
+
+	// Merge checkpoint_store field by field, so that settings.local.json can
+	// e.g. change the compression level without dropping the store type
+	if storeRaw, ok := raw["checkpoint_store"]; ok {
+		var store struct {
+			Type             string `json:"type"`
+			Path             string `json:"path"`
+			CompressionLevel *int   `json:"compression_level"`
+			DeltaTranscripts *bool  `json:"delta_transcripts"`
+		}
+		if err := json.Unmarshal(storeRaw, &store); err != nil {
+			return fmt.Errorf("parsing checkpoint_store field: %w", err)
+		}
+		if settings.CheckpointStore == nil {
+			settings.CheckpointStore = &CheckpointStoreSettings{}
+		}
+		if store.Type != "" {
+			settings.CheckpointStore.Type = store.Type
+		}
+		if store.Path != "" {
+			settings.CheckpointStore.Path = store.Path
+		}
+		if store.CompressionLevel != nil {
+			settings.CheckpointStore.CompressionLevel = store.CompressionLevel
+		}
+		if store.DeltaTranscripts != nil {
+			settings.CheckpointStore.DeltaTranscripts = *store.DeltaTranscripts
+		}
+	}
+
+	// Override retention if present
+	if retentionRaw, ok := raw["retention"]; ok {
+		var retention RetentionSettings
+		if err := json.Unmarshal(retentionRaw, &retention); err != nil {
+			return fmt.Errorf("parsing retention field: %w", err)
+		}
+		settings.Retention = &retention
+	}
+
+	// Override ignore if present
+	if ignoreRaw, ok := raw["ignore"]; ok {
+		var patterns []string
+		if err := json.Unmarshal(ignoreRaw, &patterns); err != nil {
+			return fmt.Errorf("parsing ignore field: %w", err)
+		}
+		settings.Ignore = patterns
+	}
+
+	// Merge encryption field by field, so that settings.local.json can add an
+	// identity file to the recipients configured in settings.json
+	if encryptionRaw, ok := raw["encryption"]; ok {
+		var enc EncryptionSettings
+		if err := json.Unmarshal(encryptionRaw, &enc); err != nil {
+			return fmt.Errorf("parsing encryption field: %w", err)
+		}
+		if settings.Encryption == nil {
+			settings.Encryption = &EncryptionSettings{}
+		}
+		if len(enc.Recipients) > 0 {
+			settings.Encryption.Recipients = enc.Recipients
+		}
+		if enc.IdentityFile != "" {
+			settings.Encryption.IdentityFile = enc.IdentityFile
+		}
+	}
+
 	return nil
 }
 
@@ -255,6 +422,50 @@ func (s *EntireSettings) IsPushSessionsDisabled() bool {
 	return false
 }
 
+// IsSquashOnSessionEnd checks if the auto-commit strategy should squash a
+// session's commits into one when the session ends, i.e. if
+// strategy_options.squash_on_session_end is true.
+func (s *EntireSettings) IsSquashOnSessionEnd() bool {
+	if s.StrategyOptions == nil {
+		return false
+	}
+	enabled, ok := s.StrategyOptions["squash_on_session_end"].(bool)
+	return ok && enabled
+}
+
+// Ways commits are linked to checkpoints, the values of
+// strategy_options.checkpoint_link.
+const (
+	// CheckpointLinkTrailer adds an Entire-Checkpoint trailer to the commit message.
+	CheckpointLinkTrailer = "trailer"
+
+	// CheckpointLinkNotes records the checkpoint ID in a refs/notes/entire
+	// note, leaving the commit message untouched.
+	CheckpointLinkNotes = "notes"
+)
+
+// CheckpointLink returns how the manual-commit strategy links commits to
+// checkpoints: CheckpointLinkNotes when strategy_options.checkpoint_link is
+// "notes", CheckpointLinkTrailer otherwise.
+func (s *EntireSettings) CheckpointLink() string {
+	if s.StrategyOptions == nil {
+		return CheckpointLinkTrailer
+	}
+	if val, ok := s.StrategyOptions["checkpoint_link"].(string); ok && val == CheckpointLinkNotes {
+		return CheckpointLinkNotes
+	}
+	return CheckpointLinkTrailer
+}
+
+// CheckpointStoreType returns the configured checkpoint store type,
+// defaulting to CheckpointStoreGit.
+func (s *EntireSettings) CheckpointStoreType() string {
+	if s.CheckpointStore == nil || s.CheckpointStore.Type == "" {
+		return CheckpointStoreGit
+	}
+	return s.CheckpointStore.Type
+}
+
 // Save saves the settings to .entire/settings.json.
 func Save(settings *EntireSettings) error {
 	return saveToFile(settings, EntireSettingsFile)
@@ -59,7 +59,12 @@ func TestLoad_AcceptsValidKeys(t *testing.T) {
 		"local_dev": false,
 		"log_level": "debug",
 		"strategy_options": {"key": "value"},
-		"telemetry": true
+		"telemetry": true,
+		"external_agents": ["bin/entire-agent-inhouse"],
+		"agents": ["claude-code", "gemini"],
+		"checkpoint_store": {"type": "filesystem", "path": "../checkpoints", "compression_level": 9, "delta_transcripts": true},
+		"retention": {"max_age": "90d", "max_total_size": "500MB", "keep_linked_to_default_branch": true, "keep_summaries": true},
+		"ignore": ["*.lock", "dist/"]
 	}`
 	if err := os.WriteFile(settingsFile, []byte(settingsContent), 0644); err != nil {
 		t.Fatalf("failed to write settings file: %v", err)
@@ -92,6 +97,27 @@ func TestLoad_AcceptsValidKeys(t *testing.T) {
 	if settings.Telemetry == nil || !*settings.Telemetry {
 		t.Error("expected telemetry to be true")
 	}
+	if len(settings.ExternalAgents) != 1 || settings.ExternalAgents[0] != "bin/entire-agent-inhouse" {
+		t.Errorf("expected external_agents [bin/entire-agent-inhouse], got %v", settings.ExternalAgents)
+	}
+	if len(settings.Agents) != 2 || settings.Agents[1] != "gemini" {
+		t.Errorf("expected agents [claude-code gemini], got %v", settings.Agents)
+	}
+	if settings.CheckpointStoreType() != CheckpointStoreFilesystem || settings.CheckpointStore.Path != "../checkpoints" {
+		t.Errorf("expected filesystem checkpoint store at ../checkpoints, got %+v", settings.CheckpointStore)
+	}
+	if level := settings.CheckpointStore.CompressionLevel; level == nil || *level != 9 {
+		t.Errorf("expected compression level 9, got %v", level)
+	}
+	if !settings.CheckpointStore.DeltaTranscripts {
+		t.Error("expected delta transcripts to be enabled")
+	}
+	if r := settings.Retention; r == nil || r.MaxAge != "90d" || r.MaxTotalSize != "500MB" || !r.KeepLinkedToDefaultBranch || !r.KeepSummaries {
+		t.Errorf("expected retention settings, got %+v", settings.Retention)
+	}
+	if len(settings.Ignore) != 2 || settings.Ignore[1] != "dist/" {
+		t.Errorf("expected ignore [*.lock dist/], got %v", settings.Ignore)
+	}
 }
 
 func TestLoad_LocalSettingsRejectsUnknownKeys(t *testing.T) {
@@ -135,6 +161,76 @@ func TestLoad_LocalSettingsRejectsUnknownKeys(t *testing.T) {
 	}
 }
 
+func TestLoad_MergesLocalEncryptionIdentity(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+
+	settingsContent := `{"strategy": "manual-commit", "encryption": {"recipients": ["age1alice", "age1bob"]}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(settingsContent), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	localContent := `{"encryption": {"identity_file": "~/.config/entire/key.txt"}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(localContent), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if settings.Encryption == nil {
+		t.Fatal("expected encryption settings")
+	}
+	if len(settings.Encryption.Recipients) != 2 || settings.Encryption.Recipients[1] != "age1bob" {
+		t.Errorf("expected recipients from settings.json, got %v", settings.Encryption.Recipients)
+	}
+	if settings.Encryption.IdentityFile != "~/.config/entire/key.txt" {
+		t.Errorf("expected identity file from settings.local.json, got %q", settings.Encryption.IdentityFile)
+	}
+}
+
+func TestLoad_MergesLocalCheckpointStore(t *testing.T) {
+	tmpDir := t.TempDir()
+	entireDir := filepath.Join(tmpDir, ".entire")
+	if err := os.MkdirAll(entireDir, 0755); err != nil {
+		t.Fatalf("failed to create .entire directory: %v", err)
+	}
+
+	settingsContent := `{"checkpoint_store": {"type": "filesystem", "path": "../checkpoints", "delta_transcripts": true}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.json"), []byte(settingsContent), 0644); err != nil {
+		t.Fatalf("failed to write settings file: %v", err)
+	}
+	localContent := `{"checkpoint_store": {"compression_level": 9, "delta_transcripts": false}}`
+	if err := os.WriteFile(filepath.Join(entireDir, "settings.local.json"), []byte(localContent), 0644); err != nil {
+		t.Fatalf("failed to write local settings file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create .git directory: %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	settings, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if settings.CheckpointStoreType() != CheckpointStoreFilesystem || settings.CheckpointStore.Path != "../checkpoints" {
+		t.Errorf("expected type and path from settings.json, got %+v", settings.CheckpointStore)
+	}
+	if settings.CheckpointStore.CompressionLevel == nil || *settings.CheckpointStore.CompressionLevel != 9 {
+		t.Errorf("expected compression level 9 from settings.local.json, got %v", settings.CheckpointStore.CompressionLevel)
+	}
+	if settings.CheckpointStore.DeltaTranscripts {
+		t.Error("expected settings.local.json to turn delta transcripts off")
+	}
+}
+
 // containsUnknownField checks if the error message indicates an unknown field
 func containsUnknownField(msg string) bool {
 	// Go's json package reports unknown fields with this message format
@@ -7,9 +7,11 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/external"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/strategy"
@@ -21,8 +23,9 @@ import (
 
 // Strategy display names for user-friendly selection
 const (
-	strategyDisplayManualCommit = "manual-commit"
-	strategyDisplayAutoCommit   = "auto-commit"
+	strategyDisplayManualCommit  = "manual-commit"
+	strategyDisplayAutoCommit    = "auto-commit"
+	strategyDisplaySessionBranch = "session-branch"
 )
 
 // Config path display strings
@@ -33,14 +36,16 @@ const (
 
 // strategyDisplayToInternal maps user-friendly names to internal strategy names
 var strategyDisplayToInternal = map[string]string{
-	strategyDisplayManualCommit: strategy.StrategyNameManualCommit,
-	strategyDisplayAutoCommit:   strategy.StrategyNameAutoCommit,
+	strategyDisplayManualCommit:  strategy.StrategyNameManualCommit,
+	strategyDisplayAutoCommit:    strategy.StrategyNameAutoCommit,
+	strategyDisplaySessionBranch: strategy.StrategyNameSessionBranch,
 }
 
 // strategyInternalToDisplay maps internal strategy names to user-friendly names
 var strategyInternalToDisplay = map[string]string{
-	strategy.StrategyNameManualCommit: strategyDisplayManualCommit,
-	strategy.StrategyNameAutoCommit:   strategyDisplayAutoCommit,
+	strategy.StrategyNameManualCommit:  strategyDisplayManualCommit,
+	strategy.StrategyNameAutoCommit:    strategyDisplayAutoCommit,
+	strategy.StrategyNameSessionBranch: strategyDisplaySessionBranch,
 }
 
 func newEnableCmd() *cobra.Command {
@@ -49,6 +54,7 @@ func newEnableCmd() *cobra.Command {
 	var useLocalSettings bool
 	var useProjectSettings bool
 	var agentName string
+	var allAgents bool
 	var strategyFlag string
 	var forceHooks bool
 	var skipPushSessions bool
@@ -63,6 +69,10 @@ Uses the manual-commit strategy by default. To use a different strategy:
 
   entire enable --strategy auto-commit
 
+To set up every agent detected in the repository at once:
+
+  entire enable --all-agents
+
 Strategies: manual-commit (default), auto-commit`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			// Check if we're in a git repository first - this is a prerequisite error,
@@ -77,6 +87,8 @@ Strategies: manual-commit (default), auto-commit`,
 				return err
 			}
 
+			registerExternalAgents()
+
 			// Warn if repo has no commits yet
 			if repo, err := strategy.OpenRepository(); err == nil && strategy.IsEmptyRepository(repo) {
 				fmt.Fprintln(cmd.OutOrStdout(), "Note: This repository has no commits yet. Entire will be configured, but")
@@ -90,6 +102,13 @@ Strategies: manual-commit (default), auto-commit`,
 				return NewSilentError(errors.New("missing agent name"))
 			}
 
+			if allAgents {
+				if agentName != "" {
+					return errors.New("--agent and --all-agents cannot be used together")
+				}
+				return setupAllAgentsNonInteractive(cmd.OutOrStdout(), strategyFlag, localDev, forceHooks, skipPushSessions, telemetry)
+			}
+
 			if agentName != "" {
 				ag, err := agent.Get(agent.AgentName(agentName))
 				if err != nil {
@@ -113,13 +132,14 @@ Strategies: manual-commit (default), auto-commit`,
 	cmd.Flags().BoolVar(&useLocalSettings, "local", false, "Write settings to .entire/settings.local.json instead of .entire/settings.json")
 	cmd.Flags().BoolVar(&useProjectSettings, "project", false, "Write settings to .entire/settings.json even if it already exists")
 	cmd.Flags().StringVar(&agentName, "agent", "", "Agent to setup hooks for (e.g., claude-code). Enables non-interactive mode.")
-	cmd.Flags().StringVar(&strategyFlag, "strategy", "", "Strategy to use (manual-commit or auto-commit)")
+	cmd.Flags().BoolVar(&allAgents, "all-agents", false, "Setup hooks for every agent detected in the repository. Enables non-interactive mode.")
+	cmd.Flags().StringVar(&strategyFlag, "strategy", "", "Strategy to use (manual-commit, auto-commit or session-branch)")
 	cmd.Flags().BoolVarP(&forceHooks, "force", "f", false, "Force reinstall hooks (removes existing Entire hooks first)")
 	cmd.Flags().BoolVar(&skipPushSessions, "skip-push-sessions", false, "Disable automatic pushing of session logs on git push")
 	cmd.Flags().BoolVar(&telemetry, "telemetry", true, "Enable anonymous usage analytics")
 	//nolint:errcheck,gosec // completion is optional, flag is defined above
 	cmd.RegisterFlagCompletionFunc("strategy", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
-		return []string{strategyDisplayManualCommit, strategyDisplayAutoCommit}, cobra.ShellCompDirectiveNoFileComp
+		return []string{strategyDisplayManualCommit, strategyDisplayAutoCommit, strategyDisplaySessionBranch}, cobra.ShellCompDirectiveNoFileComp
 	})
 
 	// Provide a helpful error when --agent is used without a value
@@ -154,10 +174,10 @@ show a disabled message.
 
 To completely remove Entire integrations from this repository, use --uninstall:
   - .entire/ directory (settings, logs, metadata)
-  - Git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push)
+  - Git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push, post-rewrite)
   - Session state files (.git/entire-sessions/)
   - Shadow branches (entire/<hash>)
-  - Agent hooks (Claude Code, Gemini CLI)`,
+  - Agent hooks (Claude Code, Gemini CLI, Cursor, Codex)`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if uninstall {
 				return runUninstall(cmd.OutOrStdout(), cmd.ErrOrStderr(), force)
@@ -231,7 +251,7 @@ func runEnableWithStrategy(w io.Writer, selectedStrategy string, localDev, _, us
 	// Validate the strategy exists
 	strat, err := strategy.Get(internalStrategy)
 	if err != nil {
-		return fmt.Errorf("unknown strategy: %s (use manual-commit or auto-commit)", selectedStrategy)
+		return fmt.Errorf("unknown strategy: %s (use manual-commit, auto-commit or session-branch)", selectedStrategy)
 	}
 
 	// Detect default agent
@@ -262,6 +282,7 @@ func runEnableWithStrategy(w io.Writer, selectedStrategy string, localDev, _, us
 	settings.Strategy = internalStrategy
 	settings.LocalDev = localDev
 	settings.Enabled = true
+	recordEnabledAgents(settings, agent.AgentNameClaudeCode)
 
 	// Set push_sessions option if --skip-push-sessions flag was provided
 	if skipPushSessions {
@@ -365,6 +386,7 @@ func runEnableInteractive(w io.Writer, localDev, _, useLocalSettings, useProject
 	settings.Strategy = internalStrategy
 	settings.LocalDev = localDev
 	settings.Enabled = true
+	recordEnabledAgents(settings, agent.AgentNameClaudeCode)
 
 	// Set push_sessions option if --skip-push-sessions flag was provided
 	if skipPushSessions {
@@ -546,19 +568,68 @@ func printWrongAgentError(w io.Writer, name string) {
 // setupAgentHooksNonInteractive sets up hooks for a specific agent non-interactively.
 // If strategyName is provided, it sets the strategy; otherwise uses default.
 func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName string, localDev, forceHooks, skipPushSessions, telemetry bool) error {
-	agentName := ag.Name()
-	// Check if agent supports hooks
-	hookAgent, ok := ag.(agent.HookSupport)
-	if !ok {
-		return fmt.Errorf("agent %s does not support hooks", agentName)
+	// Check if agent supports hooks, or can be captured by `entire watch` instead
+	if !canCaptureSessions(ag) {
+		return fmt.Errorf("agent %s does not support hooks", ag.Name())
 	}
 
 	fmt.Fprintf(w, "Agent: %s\n\n", ag.Type())
 
+	return setupAgentsNonInteractive(w, []agent.Agent{ag}, strategyName, localDev, forceHooks, skipPushSessions, telemetry)
+}
+
+// setupAllAgentsNonInteractive enables Entire for every agent detected in the
+// repository, installing hooks for each in one pass.
+func setupAllAgentsNonInteractive(w io.Writer, strategyName string, localDev, forceHooks, skipPushSessions, telemetry bool) error {
+	var agents []agent.Agent
+	for _, ag := range agent.DetectAll() {
+		if canCaptureSessions(ag) {
+			agents = append(agents, ag)
+		}
+	}
+	if len(agents) == 0 {
+		return errors.New("no supported agents detected in this repository (use --agent to choose one)")
+	}
+
+	types := make([]string, len(agents))
+	for i, ag := range agents {
+		types[i] = string(ag.Type())
+	}
+	fmt.Fprintf(w, "Agents: %s\n\n", strings.Join(types, ", "))
+
+	return setupAgentsNonInteractive(w, agents, strategyName, localDev, forceHooks, skipPushSessions, telemetry)
+}
+
+// canCaptureSessions reports whether Entire can capture an agent's sessions,
+// either through its hooks or by `entire watch`.
+func canCaptureSessions(ag agent.Agent) bool {
+	_, hooks := ag.(agent.HookSupport)
+	_, watchable := ag.(agent.FileWatcher)
+	return hooks || watchable
+}
+
+// recordEnabledAgents adds the given agents to the agents enabled in settings,
+// keeping the list sorted and free of duplicates.
+func recordEnabledAgents(settings *EntireSettings, names ...agent.AgentName) {
+	for _, name := range names {
+		if !slices.Contains(settings.Agents, string(name)) {
+			settings.Agents = append(settings.Agents, string(name))
+		}
+	}
+	slices.Sort(settings.Agents)
+}
+
+// setupAgentsNonInteractive installs hooks for the given agents, then configures
+// the project settings, git hooks and strategy once for all of them.
+func setupAgentsNonInteractive(w io.Writer, agents []agent.Agent, strategyName string, localDev, forceHooks, skipPushSessions, telemetry bool) error {
 	// Install agent hooks (agent hooks don't depend on settings)
-	installedHooks, err := hookAgent.InstallHooks(localDev, forceHooks)
-	if err != nil {
-		return fmt.Errorf("failed to install hooks for %s: %w", agentName, err)
+	msgs := make([]string, 0, len(agents))
+	for _, ag := range agents {
+		msg, err := installAgentHooks(ag, localDev, forceHooks)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
 	}
 
 	// Setup .entire directory
@@ -576,6 +647,11 @@ func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName str
 	if localDev {
 		settings.LocalDev = localDev
 	}
+	names := make([]agent.AgentName, 0, len(agents))
+	for _, ag := range agents {
+		names = append(names, ag.Name())
+	}
+	recordEnabledAgents(settings, names...)
 
 	// Set push_sessions option if --skip-push-sessions flag was provided
 	if skipPushSessions {
@@ -594,7 +670,7 @@ func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName str
 		}
 		// Validate the strategy exists
 		if _, err := strategy.Get(internalStrategy); err != nil {
-			return fmt.Errorf("unknown strategy: %s (use manual-commit or auto-commit)", strategyName)
+			return fmt.Errorf("unknown strategy: %s (use manual-commit, auto-commit or session-branch)", strategyName)
 		}
 		settings.Strategy = internalStrategy
 	}
@@ -615,17 +691,7 @@ func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName str
 		return fmt.Errorf("failed to install git hooks: %w", err)
 	}
 
-	if installedHooks == 0 {
-		msg := fmt.Sprintf("Hooks for %s already installed", ag.Description())
-		if agentName == agent.AgentNameGemini {
-			msg += " (Preview)"
-		}
-		fmt.Fprintf(w, "%s\n", msg)
-	} else {
-		msg := fmt.Sprintf("Installed %d hooks for %s", installedHooks, ag.Description())
-		if agentName == agent.AgentNameGemini {
-			msg += " (Preview)"
-		}
+	for _, msg := range msgs {
 		fmt.Fprintf(w, "%s\n", msg)
 	}
 
@@ -645,6 +711,39 @@ func setupAgentHooksNonInteractive(w io.Writer, ag agent.Agent, strategyName str
 	return nil
 }
 
+// installAgentHooks installs an agent's hooks and returns the line reporting what was done.
+// Agents without hooks are captured by `entire watch`, so nothing is installed for them.
+func installAgentHooks(ag agent.Agent, localDev, forceHooks bool) (string, error) {
+	var msg string
+	if hookAgent, ok := ag.(agent.HookSupport); ok {
+		installedHooks, err := hookAgent.InstallHooks(localDev, forceHooks)
+		if err != nil {
+			return "", fmt.Errorf("failed to install hooks for %s: %w", ag.Name(), err)
+		}
+		if installedHooks == 0 {
+			msg = fmt.Sprintf("Hooks for %s already installed", ag.Description())
+		} else {
+			msg = fmt.Sprintf("Installed %d hooks for %s", installedHooks, ag.Description())
+		}
+	} else {
+		msg = fmt.Sprintf("%s has no hooks; run 'entire watch' while using it to capture sessions", ag.Description())
+	}
+	if isPreviewAgent(ag.Name()) {
+		msg += " (Preview)"
+	}
+	return msg, nil
+}
+
+// isPreviewAgent reports whether an agent's integration is still in preview.
+func isPreviewAgent(name agent.AgentName) bool {
+	switch name {
+	case agent.AgentNameGemini, agent.AgentNameCursor, agent.AgentNameCodex, agent.AgentNameAider:
+		return true
+	default:
+		return false
+	}
+}
+
 // validateSetupFlags checks that --local and --project flags are not both specified.
 func validateSetupFlags(useLocal, useProject bool) error {
 	if useLocal && useProject {
@@ -917,17 +1016,23 @@ func runUninstall(w, errW io.Writer, force bool) error {
 		return NewSilentError(errors.New("not a git repository"))
 	}
 
+	registerExternalAgents()
+
 	// Gather counts for display
 	sessionStateCount := countSessionStates()
 	shadowBranchCount := countShadowBranches()
 	gitHooksInstalled := strategy.IsGitHookInstalled()
 	claudeHooksInstalled := checkClaudeCodeHooksInstalled()
 	geminiHooksInstalled := checkGeminiCLIHooksInstalled()
+	cursorHooksInstalled := checkCursorHooksInstalled()
+	codexHooksInstalled := checkCodexHooksInstalled()
+	pluginHooksInstalled := externalAgentsWithHooksInstalled()
 	entireDirExists := checkEntireDirExists()
 
 	// Check if there's anything to uninstall
 	if !entireDirExists && !gitHooksInstalled && sessionStateCount == 0 &&
-		shadowBranchCount == 0 && !claudeHooksInstalled && !geminiHooksInstalled {
+		shadowBranchCount == 0 && !claudeHooksInstalled && !geminiHooksInstalled && !cursorHooksInstalled && !codexHooksInstalled &&
+		len(pluginHooksInstalled) == 0 {
 		fmt.Fprintln(w, "Entire is not installed in this repository.")
 		return nil
 	}
@@ -939,7 +1044,7 @@ func runUninstall(w, errW io.Writer, force bool) error {
 			fmt.Fprintln(w, "  - .entire/ directory")
 		}
 		if gitHooksInstalled {
-			fmt.Fprintln(w, "  - Git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push)")
+			fmt.Fprintln(w, "  - Git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push, post-rewrite)")
 		}
 		if sessionStateCount > 0 {
 			fmt.Fprintf(w, "  - Session state files (%d)\n", sessionStateCount)
@@ -947,13 +1052,24 @@ func runUninstall(w, errW io.Writer, force bool) error {
 		if shadowBranchCount > 0 {
 			fmt.Fprintf(w, "  - Shadow branches (%d)\n", shadowBranchCount)
 		}
-		switch {
-		case claudeHooksInstalled && geminiHooksInstalled:
-			fmt.Fprintln(w, "  - Agent hooks (Claude Code, Gemini CLI)")
-		case claudeHooksInstalled:
-			fmt.Fprintln(w, "  - Agent hooks (Claude Code)")
-		case geminiHooksInstalled:
-			fmt.Fprintln(w, "  - Agent hooks (Gemini CLI)")
+		var agentHooks []string
+		if claudeHooksInstalled {
+			agentHooks = append(agentHooks, "Claude Code")
+		}
+		if geminiHooksInstalled {
+			agentHooks = append(agentHooks, "Gemini CLI")
+		}
+		if cursorHooksInstalled {
+			agentHooks = append(agentHooks, "Cursor")
+		}
+		if codexHooksInstalled {
+			agentHooks = append(agentHooks, "Codex")
+		}
+		for _, ag := range pluginHooksInstalled {
+			agentHooks = append(agentHooks, string(ag.Type()))
+		}
+		if len(agentHooks) > 0 {
+			fmt.Fprintf(w, "  - Agent hooks (%s)\n", strings.Join(agentHooks, ", "))
 		}
 		fmt.Fprintln(w)
 
@@ -1068,6 +1184,47 @@ func checkGeminiCLIHooksInstalled() bool {
 	return hookAgent.AreHooksInstalled()
 }
 
+// checkCursorHooksInstalled checks if Cursor hooks are installed.
+func checkCursorHooksInstalled() bool {
+	ag, err := agent.Get(agent.AgentNameCursor)
+	if err != nil {
+		return false
+	}
+	hookAgent, ok := ag.(agent.HookSupport)
+	if !ok {
+		return false
+	}
+	return hookAgent.AreHooksInstalled()
+}
+
+// checkCodexHooksInstalled checks if the Codex notify hook is installed.
+func checkCodexHooksInstalled() bool {
+	ag, err := agent.Get(agent.AgentNameCodex)
+	if err != nil {
+		return false
+	}
+	hookAgent, ok := ag.(agent.HookSupport)
+	if !ok {
+		return false
+	}
+	return hookAgent.AreHooksInstalled()
+}
+
+// externalAgentsWithHooksInstalled returns the agent plugins whose hooks are installed.
+func externalAgentsWithHooksInstalled() []agent.HookSupport {
+	var installed []agent.HookSupport
+	for _, name := range agent.List() {
+		ag, err := agent.Get(name)
+		if err != nil || !external.IsPlugin(ag) {
+			continue
+		}
+		if hookAgent, ok := ag.(agent.HookSupport); ok && hookAgent.AreHooksInstalled() {
+			installed = append(installed, hookAgent)
+		}
+	}
+	return installed
+}
+
 // checkEntireDirExists checks if the .entire directory exists.
 func checkEntireDirExists() bool {
 	entireDirAbs, err := paths.AbsPath(paths.EntireDir)
@@ -1108,6 +1265,41 @@ func removeAgentHooks(w io.Writer) error {
 		}
 	}
 
+	// Remove Cursor hooks
+	cursorAgent, err := agent.Get(agent.AgentNameCursor)
+	if err == nil {
+		if hookAgent, ok := cursorAgent.(agent.HookSupport); ok {
+			wasInstalled := hookAgent.AreHooksInstalled()
+			if err := hookAgent.UninstallHooks(); err != nil {
+				errs = append(errs, err)
+			} else if wasInstalled {
+				fmt.Fprintln(w, "  Removed Cursor hooks")
+			}
+		}
+	}
+
+	// Remove Codex hooks
+	codexAgent, err := agent.Get(agent.AgentNameCodex)
+	if err == nil {
+		if hookAgent, ok := codexAgent.(agent.HookSupport); ok {
+			wasInstalled := hookAgent.AreHooksInstalled()
+			if err := hookAgent.UninstallHooks(); err != nil {
+				errs = append(errs, err)
+			} else if wasInstalled {
+				fmt.Fprintln(w, "  Removed Codex hooks")
+			}
+		}
+	}
+
+	// Remove agent plugin hooks
+	for _, hookAgent := range externalAgentsWithHooksInstalled() {
+		if err := hookAgent.UninstallHooks(); err != nil {
+			errs = append(errs, err)
+		} else {
+			fmt.Fprintf(w, "  Removed %s hooks\n", hookAgent.Type())
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
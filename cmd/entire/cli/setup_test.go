@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -350,6 +351,7 @@ func TestRunEnableWithStrategy_PreservesExistingSettings(t *testing.T) {
 	initialSettings := `{
 		"strategy": "manual-commit",
 		"enabled": true,
+		"agents": ["gemini"],
 		"strategy_options": {
 			"push": true,
 			"some_other_option": "value"
@@ -385,6 +387,11 @@ func TestRunEnableWithStrategy_PreservesExistingSettings(t *testing.T) {
 	if settings.StrategyOptions["some_other_option"] != "value" {
 		t.Errorf("strategy_options.some_other_option should be 'value', got %v", settings.StrategyOptions["some_other_option"])
 	}
+
+	// The enabled agent is recorded alongside those already enabled
+	if want := []string{"claude-code", "gemini"}; !slices.Equal(settings.Agents, want) {
+		t.Errorf("Agents = %v, want %v", settings.Agents, want)
+	}
 }
 
 func TestRunEnableWithStrategy_PreservesLocalSettings(t *testing.T) {
@@ -801,6 +808,9 @@ func TestPrintMissingAgentError(t *testing.T) {
 			t.Errorf("expected agent %q listed in output", a)
 		}
 	}
+	if strings.Contains(output, string(agent.AgentNameSimulator)) {
+		t.Error("the simulator should not be listed as an available agent")
+	}
 	if !strings.Contains(output, "(default)") {
 		t.Error("expected default annotation in output")
 	}
@@ -878,3 +888,74 @@ func TestEnableCmd_AgentFlagEmptyValue(t *testing.T) {
 		t.Error("should not contain default cobra/pflag error message")
 	}
 }
+
+func TestEnableCmd_AllAgentsWithAgentFlag(t *testing.T) {
+	setupTestRepo(t)
+
+	cmd := newEnableCmd()
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--all-agents", "--agent", "claude-code"})
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error when --all-agents is combined with --agent")
+	}
+	if !strings.Contains(err.Error(), "cannot be used together") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSetupAllAgentsNonInteractive_NoneDetected(t *testing.T) {
+	setupTestRepo(t)
+
+	var stdout bytes.Buffer
+	err := setupAllAgentsNonInteractive(&stdout, "", false, false, false, false)
+	if err == nil {
+		t.Fatal("expected error when no agents are detected")
+	}
+	if !strings.Contains(err.Error(), "no supported agents detected") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSetupAllAgentsNonInteractive_InstallsDetected(t *testing.T) {
+	setupTestRepo(t)
+	for _, dir := range []string{".claude", ".gemini"} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	var stdout bytes.Buffer
+	if err := setupAllAgentsNonInteractive(&stdout, "", false, false, false, false); err != nil {
+		t.Fatalf("setupAllAgentsNonInteractive() error = %v", err)
+	}
+
+	output := stdout.String()
+	if !strings.Contains(output, "Agents: Claude Code, Gemini CLI") {
+		t.Errorf("expected detected agents listed, got: %s", output)
+	}
+
+	s, err := LoadEntireSettings()
+	if err != nil {
+		t.Fatalf("LoadEntireSettings() error = %v", err)
+	}
+	want := []string{string(agent.AgentNameClaudeCode), string(agent.AgentNameGemini)}
+	if strings.Join(s.Agents, ",") != strings.Join(want, ",") {
+		t.Errorf("settings.Agents = %v, want %v", s.Agents, want)
+	}
+
+	for _, name := range want {
+		ag, err := agent.Get(agent.AgentName(name))
+		if err != nil {
+			t.Fatalf("agent.Get(%s) error = %v", name, err)
+		}
+		hooks, ok := ag.(agent.HookSupport)
+		if !ok {
+			t.Fatalf("%s does not support hooks", name)
+		}
+		if !hooks.AreHooksInstalled() {
+			t.Errorf("expected hooks installed for %s", name)
+		}
+	}
+}
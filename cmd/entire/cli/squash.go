@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/spf13/cobra"
+)
+
+func newSquashCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "squash [session-id]",
+		Short: "Squash a session's auto-commits into one commit",
+		Long: `Squash collapses the commits the auto-commit strategy made for a session into
+a single commit on the current branch. Its message is generated from the
+session's summary or first prompt, and it keeps an Entire-Checkpoint trailer
+for every squashed checkpoint, so rewind and explain keep working.
+
+Only the session's commits at the tip of the current branch are squashed;
+squashing stops at the first commit that isn't one of them. The working tree
+and index are not touched.
+
+Squash refuses to rewrite commits that are already on a remote-tracking
+branch unless --force is given.
+
+Without a session ID, squashes the most recent session in this worktree.
+Set strategy_options.squash_on_session_end to squash automatically when a
+session ends.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runSquash(cmd.Context(), cmd.OutOrStdout(), sessionArg(args), force)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Squash even if the session's commits have been pushed")
+
+	return cmd
+}
+
+func runSquash(ctx context.Context, w io.Writer, sessionID string, force bool) error {
+	if name := GetStrategy().Name(); name != strategy.StrategyNameAutoCommit {
+		return fmt.Errorf("squash only applies to the %s strategy (current strategy: %s)", strategy.StrategyNameAutoCommit, name)
+	}
+	if sessionID == "" {
+		if sessionID = strategy.FindMostRecentSession(); sessionID == "" {
+			return errors.New("no session found; pass a session ID")
+		}
+	}
+
+	result, err := strategy.SquashSession(ctx, sessionID, force)
+	if errors.Is(err, strategy.ErrNothingToSquash) {
+		fmt.Fprintf(w, "Session %s has fewer than two commits at the tip of the current branch; nothing to squash.\n", sessionID)
+		return nil
+	}
+	if errors.Is(err, strategy.ErrSessionPushed) {
+		return fmt.Errorf("%w; use --force to rewrite them anyway", err)
+	}
+	if err != nil {
+		return err //nolint:wrapcheck // Already descriptive
+	}
+
+	fmt.Fprintf(w, "Squashed %d commits of session %s into %s (%d checkpoint(s)).\n",
+		result.Squashed, sessionID, result.Commit[:7], len(result.CheckpointIDs))
+	return nil
+}
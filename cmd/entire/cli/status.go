@@ -8,10 +8,12 @@ import (
 	"io/fs"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
 	"github.com/entireio/cli/cmd/entire/cli/settings"
@@ -44,6 +46,8 @@ func runStatus(w io.Writer, detailed bool) error {
 		return nil //nolint:nilerr // Not being in a git repo is a valid status, not an error
 	}
 
+	registerExternalAgents()
+
 	// Get absolute paths for settings files
 	settingsPath, err := paths.AbsPath(EntireSettingsFile)
 	if err != nil {
@@ -84,6 +88,7 @@ func runStatus(w io.Writer, detailed bool) error {
 	fmt.Fprintln(w, formatSettingsStatusShort(settings))
 
 	if settings.Enabled {
+		writeAgentHookStatus(w, settings)
 		writeActiveSessions(w)
 	}
 
@@ -119,6 +124,7 @@ func runStatusDetailed(w io.Writer, settingsPath, localSettingsPath string, proj
 	}
 
 	if effectiveSettings.Enabled {
+		writeAgentHookStatus(w, effectiveSettings)
 		writeActiveSessions(w)
 	}
 
@@ -153,6 +159,54 @@ func formatSettingsStatus(prefix string, settings *EntireSettings) string {
 	return fmt.Sprintf("%s, disabled (%s)", prefix, displayName)
 }
 
+// writeAgentHookStatus lists the agents Entire was enabled for, plus any other
+// agent with hooks installed, and whether each agent's hooks are in place.
+func writeAgentHookStatus(w io.Writer, settings *EntireSettings) {
+	names := make([]agent.AgentName, 0, len(settings.Agents))
+	for _, name := range settings.Agents {
+		names = append(names, agent.AgentName(name))
+	}
+	for _, name := range GetAgentsWithHooksInstalled() {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	slices.Sort(names)
+
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Agents:")
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, name, agentHookStatus(name))
+	}
+}
+
+// agentHookStatus describes whether an agent's hooks are installed.
+func agentHookStatus(name agent.AgentName) string {
+	ag, err := agent.Get(name)
+	if err != nil {
+		return "✕ agent not available"
+	}
+	hooks, ok := ag.(agent.HookSupport)
+	if !ok {
+		if _, watchable := ag.(agent.FileWatcher); watchable {
+			return "○ no hooks (captured by `entire watch`)"
+		}
+		return "✕ no hooks"
+	}
+	if hooks.AreHooksInstalled() {
+		return "✓ hooks installed"
+	}
+	return fmt.Sprintf("✕ hooks not installed (run `entire enable --agent %s`)", name)
+}
+
 // timeAgo formats a time as a human-readable relative duration.
 func timeAgo(t time.Time) string {
 	d := time.Since(t)
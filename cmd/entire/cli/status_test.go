@@ -387,3 +387,21 @@ func TestWriteActiveSessions_EndedSessionsExcluded(t *testing.T) {
 		t.Errorf("Expected empty output with only ended sessions, got: %s", buf.String())
 	}
 }
+
+func TestRunStatus_ShowsAgentHookStatus(t *testing.T) {
+	setupTestRepo(t)
+	writeSettings(t, `{"strategy": "manual-commit", "enabled": true, "agents": ["claude-code"]}`)
+
+	var stdout bytes.Buffer
+	if err := runStatus(&stdout, false); err != nil {
+		t.Fatalf("runStatus() error = %v", err)
+	}
+
+	output := stdout.String()
+	if !strings.Contains(output, "Agents:") {
+		t.Errorf("Expected output to list agents, got: %s", output)
+	}
+	if !strings.Contains(output, "claude-code  ✕ hooks not installed (run `entire enable --agent claude-code`)") {
+		t.Errorf("Expected claude-code hooks reported missing, got: %s", output)
+	}
+}
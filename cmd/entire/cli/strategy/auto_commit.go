@@ -59,8 +59,8 @@ func commitOrHead(repo *git.Repository, worktree *git.Worktree, msg string, auth
 // - Session logs are committed to a shadow branch (like manual-commit strategy)
 // - Code commits can reference the shadow branch via trailers
 type AutoCommitStrategy struct {
-	// checkpointStore manages checkpoint data on entire/checkpoints/v1 branch
-	checkpointStore *checkpoint.GitStore
+	// checkpointStore manages committed checkpoint data (entire/checkpoints/v1 by default)
+	checkpointStore checkpoint.Store
 	// checkpointStoreOnce ensures thread-safe lazy initialization
 	checkpointStoreOnce sync.Once
 	// checkpointStoreErr captures any error during initialization
@@ -69,14 +69,19 @@ type AutoCommitStrategy struct {
 
 // getCheckpointStore returns the checkpoint store, initializing it lazily if needed.
 // Thread-safe via sync.Once.
-func (s *AutoCommitStrategy) getCheckpointStore() (*checkpoint.GitStore, error) {
+func (s *AutoCommitStrategy) getCheckpointStore() (checkpoint.Store, error) {
 	s.checkpointStoreOnce.Do(func() {
 		repo, err := OpenRepository()
 		if err != nil {
 			s.checkpointStoreErr = fmt.Errorf("failed to open repository: %w", err)
 			return
 		}
-		s.checkpointStore = checkpoint.NewGitStore(repo)
+		store, err := NewCheckpointStore(repo)
+		if err != nil {
+			s.checkpointStoreErr = err
+			return
+		}
+		s.checkpointStore = store
 	})
 	return s.checkpointStore, s.checkpointStoreErr
 }
@@ -125,7 +130,13 @@ func (s *AutoCommitStrategy) SaveChanges(ctx SaveContext) error {
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
+	return s.saveChanges(repo, ctx, StrategyNameAutoCommit)
+}
 
+// saveChanges commits the session's code changes to the branch checked out in
+// repo's worktree and its metadata to entire/checkpoints/v1, recording
+// strategyName in the checkpoint metadata.
+func (s *AutoCommitStrategy) saveChanges(repo *git.Repository, ctx SaveContext, strategyName string) error {
 	// Generate checkpoint ID for this commit
 	cpID, err := id.Generate()
 	if err != nil {
@@ -145,7 +156,7 @@ func (s *AutoCommitStrategy) SaveChanges(ctx SaveContext) error {
 	if !codeResult.Created {
 		logCtx := logging.WithComponent(context.Background(), "checkpoint")
 		logging.Info(logCtx, "checkpoint skipped (no changes)",
-			slog.String("strategy", "auto-commit"),
+			slog.String("strategy", strategyName),
 			slog.String("checkpoint_type", "session"),
 		)
 		fmt.Fprintf(os.Stderr, "Skipped checkpoint (no changes since last commit)\n")
@@ -154,7 +165,7 @@ func (s *AutoCommitStrategy) SaveChanges(ctx SaveContext) error {
 
 	// Step 2: Commit metadata to entire/checkpoints/v1 branch using sharded path
 	// Path is <checkpointID[:2]>/<checkpointID[2:]>/ for direct lookup
-	_, err = s.commitMetadataToMetadataBranch(repo, ctx, cpID)
+	_, err = s.commitMetadataToMetadataBranch(repo, ctx, cpID, strategyName)
 	if err != nil {
 		return fmt.Errorf("failed to commit metadata to entire/checkpoints/v1 branch: %w", err)
 	}
@@ -162,7 +173,7 @@ func (s *AutoCommitStrategy) SaveChanges(ctx SaveContext) error {
 	// Log checkpoint creation
 	logCtx := logging.WithComponent(context.Background(), "checkpoint")
 	logging.Info(logCtx, "checkpoint saved",
-		slog.String("strategy", "auto-commit"),
+		slog.String("strategy", strategyName),
 		slog.String("checkpoint_type", "session"),
 		slog.String("checkpoint_id", cpID.String()),
 		slog.Int("modified_files", len(ctx.ModifiedFiles)),
@@ -170,6 +181,9 @@ func (s *AutoCommitStrategy) SaveChanges(ctx SaveContext) error {
 		slog.Int("deleted_files", len(ctx.DeletedFiles)),
 	)
 
+	// The code commit was made without running git hooks
+	TryUpdateCheckpointIndex(logCtx)
+
 	return nil
 }
 
@@ -234,7 +248,7 @@ func (s *AutoCommitStrategy) commitCodeToActive(repo *git.Repository, ctx SaveCo
 // Metadata is stored at sharded path: <checkpointID[:2]>/<checkpointID[2:]>/
 // This allows direct lookup from the checkpoint ID trailer on the code commit.
 // Uses checkpoint.WriteCommitted for git operations.
-func (s *AutoCommitStrategy) commitMetadataToMetadataBranch(repo *git.Repository, ctx SaveContext, checkpointID id.CheckpointID) (plumbing.Hash, error) {
+func (s *AutoCommitStrategy) commitMetadataToMetadataBranch(repo *git.Repository, ctx SaveContext, checkpointID id.CheckpointID, strategyName string) (plumbing.Hash, error) {
 	store, err := s.getCheckpointStore()
 	if err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to get checkpoint store: %w", err)
@@ -247,7 +261,7 @@ func (s *AutoCommitStrategy) commitMetadataToMetadataBranch(repo *git.Repository
 	branchName := GetCurrentBranchName(repo)
 
 	// Combine all file changes into FilesTouched (same as manual-commit)
-	filesTouched := mergeFilesTouched(nil, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles)
+	filesTouched := loadIgnoreMatcher().Filter(mergeFilesTouched(nil, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles))
 
 	// Load TurnID from session state (correlates checkpoints from the same turn)
 	var turnID string
@@ -259,7 +273,7 @@ func (s *AutoCommitStrategy) commitMetadataToMetadataBranch(repo *git.Repository
 	err = store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
 		CheckpointID:                checkpointID,
 		SessionID:                   sessionID,
-		Strategy:                    StrategyNameAutoCommit, // Use new strategy name
+		Strategy:                    strategyName,
 		Branch:                      branchName,
 		MetadataDir:                 ctx.MetadataDirAbs, // Copy all files from metadata dir
 		AuthorName:                  ctx.AuthorName,
@@ -276,7 +290,7 @@ func (s *AutoCommitStrategy) commitMetadataToMetadataBranch(repo *git.Repository
 		return plumbing.ZeroHash, fmt.Errorf("failed to write committed checkpoint: %w", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "Committed session metadata to %s (%s)\n", paths.MetadataBranchName, checkpointID)
+	fmt.Fprintf(os.Stderr, "Committed session metadata to %s (%s)\n", store.Location(), checkpointID)
 	return plumbing.ZeroHash, nil // Commit hash not needed by callers
 }
 
@@ -294,12 +308,11 @@ func (s *AutoCommitStrategy) GetRewindPoints(limit int) ([]RewindPoint, error) {
 		return nil, fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
-	// Get metadata branch tree for lookups
-	metadataTree, err := GetMetadataBranchTree(repo)
+	store, err := s.getCheckpointStore()
 	if err != nil {
-		// No metadata branch yet is fine
-		return []RewindPoint{}, nil //nolint:nilerr // Expected when no metadata exists
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
 	}
+	ctx := context.Background()
 
 	// Get the main branch commit hash to determine branch-only commits
 	mainBranchHash := GetMainBranchHash(repo)
@@ -330,7 +343,7 @@ func (s *AutoCommitStrategy) GetRewindPoints(limit int) ([]RewindPoint, error) {
 
 		// Look up metadata from sharded path
 		checkpointPath := cpID.Path()
-		metadata, err := ReadCheckpointMetadata(metadataTree, checkpointPath)
+		metadata, err := ReadCommittedCheckpointMetadata(ctx, store, checkpointPath)
 		if err != nil {
 			// Checkpoint exists in commit but no metadata found - skip this commit
 			return nil //nolint:nilerr // Intentional: skip commits without metadata
@@ -353,8 +366,8 @@ func (s *AutoCommitStrategy) GetRewindPoints(limit int) ([]RewindPoint, error) {
 			metadataDir = checkpointPath + "/tasks/" + metadata.ToolUseID
 		}
 
-		// Read session prompt from metadata tree
-		sessionPrompt := ReadSessionPromptFromTree(metadataTree, checkpointPath)
+		// Read session prompt from the checkpoint store
+		sessionPrompt := ReadCommittedSessionPrompt(ctx, store, checkpointPath)
 
 		points = append(points, RewindPoint{
 			ID:               c.Hash.String(),
@@ -381,48 +394,25 @@ func (s *AutoCommitStrategy) GetRewindPoints(limit int) ([]RewindPoint, error) {
 }
 
 // findTaskMetadataPathForCommit looks up the task metadata path for a task checkpoint commit
-// by searching the entire/checkpoints/v1 branch commit history for the checkpoint directory.
+// through the checkpoint store: of the checkpoints the commit is linked to, it returns the
+// task checkpoint recorded for the tool use ID.
 // Returns ("", nil) if metadata is not found - this is expected for commits without metadata.
-func (s *AutoCommitStrategy) findTaskMetadataPathForCommit(repo *git.Repository, commitSHA, toolUseID string) (string, error) {
-	// Get the entire/checkpoints/v1 branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := repo.Reference(refName, true)
+func (s *AutoCommitStrategy) findTaskMetadataPathForCommit(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore, commitSHA, toolUseID string) (string, error) {
+	commit, err := repo.CommitObject(plumbing.NewHash(commitSHA))
 	if err != nil {
-		if isNotFoundError(err) {
-			return "", nil // No metadata branch yet
-		}
-		return "", fmt.Errorf("failed to get metadata branch: %w", err)
-	}
-
-	// Search commit history for a commit referencing this code commit SHA and tool use ID
-	shortSHA := commitSHA
-	if len(shortSHA) > 7 {
-		shortSHA = shortSHA[:7]
+		return "", fmt.Errorf("failed to read commit %s: %w", commitSHA, err)
 	}
 
-	iter, err := repo.Log(&git.LogOptions{From: ref.Hash()})
-	if err != nil {
-		return "", fmt.Errorf("failed to get commit log: %w", err)
-	}
-
-	var foundTaskPath string
-	err = iter.ForEach(func(commit *object.Commit) error {
-		// Check if commit message contains "Commit: <sha>" and the tool use ID
-		if strings.Contains(commit.Message, "Commit: "+shortSHA) &&
-			strings.Contains(commit.Message, toolUseID) {
-			// Parse task metadata trailer
-			if taskPath, found := trailers.ParseTaskMetadata(commit.Message); found {
-				foundTaskPath = taskPath
-				return errStop // Found it
-			}
+	for _, cpID := range LoadCheckpointNotes(repo).ParseAllCheckpoints(commit) {
+		metadata, err := ReadCommittedCheckpointMetadata(ctx, store, cpID.Path())
+		if err != nil {
+			continue // No metadata for this checkpoint
+		}
+		if metadata.IsTask && metadata.ToolUseID == toolUseID {
+			return cpID.Path() + "/tasks/" + toolUseID, nil
 		}
-		return nil
-	})
-	if err != nil && !errors.Is(err, errStop) {
-		return "", fmt.Errorf("failed to iterate commits: %w", err)
 	}
-
-	return foundTaskPath, nil
+	return "", nil
 }
 
 func (s *AutoCommitStrategy) Rewind(point RewindPoint) error {
@@ -502,7 +492,13 @@ func (s *AutoCommitStrategy) SaveTaskCheckpoint(ctx TaskCheckpointContext) error
 	if err != nil {
 		return fmt.Errorf("failed to open git repository: %w", err)
 	}
+	return s.saveTaskCheckpoint(repo, ctx, StrategyNameAutoCommit)
+}
 
+// saveTaskCheckpoint commits the task's code changes to the branch checked out
+// in repo's worktree and its metadata to entire/checkpoints/v1, recording
+// strategyName in the checkpoint metadata.
+func (s *AutoCommitStrategy) saveTaskCheckpoint(repo *git.Repository, ctx TaskCheckpointContext, strategyName string) error {
 	// Ensure entire/checkpoints/v1 branch exists
 	if err := EnsureMetadataBranch(repo); err != nil {
 		return fmt.Errorf("failed to ensure metadata branch: %w", err)
@@ -522,7 +518,7 @@ func (s *AutoCommitStrategy) SaveTaskCheckpoint(ctx TaskCheckpointContext) error
 	}
 
 	// Step 2: Commit task metadata to entire/checkpoints/v1 branch at sharded path
-	_, err = s.commitTaskMetadataToMetadataBranch(repo, ctx, cpID)
+	_, err = s.commitTaskMetadataToMetadataBranch(repo, ctx, cpID, strategyName)
 	if err != nil {
 		return fmt.Errorf("failed to commit task metadata to entire/checkpoints/v1 branch: %w", err)
 	}
@@ -530,7 +526,7 @@ func (s *AutoCommitStrategy) SaveTaskCheckpoint(ctx TaskCheckpointContext) error
 	// Log task checkpoint creation
 	logCtx := logging.WithComponent(context.Background(), "checkpoint")
 	attrs := []any{
-		slog.String("strategy", "auto-commit"),
+		slog.String("strategy", strategyName),
 		slog.String("checkpoint_type", "task"),
 		slog.String("checkpoint_id", cpID.String()),
 		slog.String("checkpoint_uuid", ctx.CheckpointUUID),
@@ -549,6 +545,9 @@ func (s *AutoCommitStrategy) SaveTaskCheckpoint(ctx TaskCheckpointContext) error
 	}
 	logging.Info(logCtx, "task checkpoint saved", attrs...)
 
+	// The code commit was made without running git hooks
+	TryUpdateCheckpointIndex(logCtx)
+
 	return nil
 }
 
@@ -624,7 +623,7 @@ func (s *AutoCommitStrategy) commitTaskCodeToActive(repo *git.Repository, ctx Ta
 // Returns the metadata commit hash.
 // When IsIncremental is true, only writes the incremental checkpoint file, skipping transcripts.
 // Uses checkpoint.WriteCommitted for git operations.
-func (s *AutoCommitStrategy) commitTaskMetadataToMetadataBranch(repo *git.Repository, ctx TaskCheckpointContext, checkpointID id.CheckpointID) (plumbing.Hash, error) {
+func (s *AutoCommitStrategy) commitTaskMetadataToMetadataBranch(repo *git.Repository, ctx TaskCheckpointContext, checkpointID id.CheckpointID, strategyName string) (plumbing.Hash, error) {
 	store, err := s.getCheckpointStore()
 	if err != nil {
 		return plumbing.ZeroHash, fmt.Errorf("failed to get checkpoint store: %w", err)
@@ -657,7 +656,7 @@ func (s *AutoCommitStrategy) commitTaskMetadataToMetadataBranch(repo *git.Reposi
 	err = store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
 		CheckpointID:           checkpointID,
 		SessionID:              ctx.SessionID,
-		Strategy:               StrategyNameAutoCommit,
+		Strategy:               strategyName,
 		Branch:                 branchName,
 		IsTask:                 true,
 		ToolUseID:              ctx.ToolUseID,
@@ -679,9 +678,9 @@ func (s *AutoCommitStrategy) commitTaskMetadataToMetadataBranch(repo *git.Reposi
 	}
 
 	if ctx.IsIncremental {
-		fmt.Fprintf(os.Stderr, "Committed incremental checkpoint metadata to %s (%s)\n", paths.MetadataBranchName, checkpointID)
+		fmt.Fprintf(os.Stderr, "Committed incremental checkpoint metadata to %s (%s)\n", store.Location(), checkpointID)
 	} else {
-		fmt.Fprintf(os.Stderr, "Committed task metadata to %s (%s)\n", paths.MetadataBranchName, checkpointID)
+		fmt.Fprintf(os.Stderr, "Committed task metadata to %s (%s)\n", store.Location(), checkpointID)
 	}
 	return plumbing.ZeroHash, nil // Commit hash not needed by callers
 }
@@ -698,47 +697,30 @@ func (s *AutoCommitStrategy) GetTaskCheckpoint(point RewindPoint) (*TaskCheckpoi
 	if err != nil {
 		return nil, fmt.Errorf("failed to open repository: %w", err)
 	}
-
-	// Get the entire/checkpoints/v1 branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := repo.Reference(refName, true)
-	if err != nil {
-		return nil, fmt.Errorf("metadata branch %s not found: %w", paths.MetadataBranchName, err)
-	}
-
-	metadataCommit, err := repo.CommitObject(ref.Hash())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get metadata branch commit: %w", err)
-	}
-
-	tree, err := metadataCommit.Tree()
+	store, err := s.getCheckpointStore()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get metadata tree: %w", err)
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
 	}
+	ctx := context.Background()
 
 	// Find checkpoint using the metadata path from rewind point
 	// MetadataDir for auto-commit task checkpoints is: cond-YYYYMMDD-HHMMSS-XXXXXXXX/tasks/<tool-use-id>
 	checkpointPath := point.MetadataDir + "/checkpoint.json"
-	file, err := tree.File(checkpointPath)
+	content, err := store.ReadCommittedFile(ctx, checkpointPath)
 	if err != nil {
 		// Try finding via commit SHA lookup
-		taskCheckpointPath, findErr := s.findTaskCheckpointPath(repo, point.ID, point.ToolUseID)
+		taskCheckpointPath, findErr := s.findTaskCheckpointPath(ctx, repo, store, point.ID, point.ToolUseID)
 		if findErr != nil {
 			return nil, fmt.Errorf("failed to find checkpoint at %s: %w", checkpointPath, err)
 		}
-		file, err = tree.File(taskCheckpointPath)
+		content, err = store.ReadCommittedFile(ctx, taskCheckpointPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to find checkpoint at %s: %w", taskCheckpointPath, err)
 		}
 	}
 
-	content, err := file.Contents()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
-	}
-
 	var checkpoint TaskCheckpoint
-	if err := json.Unmarshal([]byte(content), &checkpoint); err != nil {
+	if err := json.Unmarshal(content, &checkpoint); err != nil {
 		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
 	}
 
@@ -753,27 +735,11 @@ func (s *AutoCommitStrategy) GetTaskCheckpointTranscript(point RewindPoint) ([]b
 		return nil, ErrNotTaskCheckpoint
 	}
 
-	repo, err := OpenRepository()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open repository: %w", err)
-	}
-
-	// Get the entire/checkpoints/v1 branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := repo.Reference(refName, true)
-	if err != nil {
-		return nil, fmt.Errorf("metadata branch %s not found: %w", paths.MetadataBranchName, err)
-	}
-
-	metadataCommit, err := repo.CommitObject(ref.Hash())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get metadata branch commit: %w", err)
-	}
-
-	tree, err := metadataCommit.Tree()
+	store, err := s.getCheckpointStore()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get metadata tree: %w", err)
+		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
 	}
+	ctx := context.Background()
 
 	// MetadataDir for auto-commit task checkpoints is: <id[:2]>/<id[2:]>/tasks/<tool-use-id>
 	// Extract the checkpoint path by removing "/tasks/<tool-use-id>"
@@ -781,48 +747,30 @@ func (s *AutoCommitStrategy) GetTaskCheckpointTranscript(point RewindPoint) ([]b
 	if idx := strings.Index(metadataDir, "/tasks/"); idx > 0 {
 		checkpointPath := metadataDir[:idx]
 
-		// Use the first session's transcript path from sessions array
-		transcriptPath := ""
-		summaryFile, summaryErr := tree.File(checkpointPath + "/" + paths.MetadataFileName)
-		if summaryErr == nil {
-			summaryContent, contentErr := summaryFile.Contents()
-			if contentErr == nil {
-				var summary checkpoint.CheckpointSummary
-				if json.Unmarshal([]byte(summaryContent), &summary) == nil && len(summary.Sessions) > 0 {
-					// Use first session's transcript path (task checkpoints have only one session)
-					// SessionFilePaths now contains absolute paths with leading "/"
-					// Strip the leading "/" for tree.File() which expects paths without leading slash
-					if summary.Sessions[0].Transcript != "" {
-						transcriptPath = strings.TrimPrefix(summary.Sessions[0].Transcript, "/")
-					}
-				}
+		// Task checkpoints have only one session. Reading it through the store
+		// reassembles chunks and decompresses compressed transcripts.
+		if cpID, idErr := id.NewCheckpointID(strings.ReplaceAll(checkpointPath, "/", "")); idErr == nil {
+			if content, readErr := store.ReadSessionContent(ctx, cpID, 0); readErr == nil && len(content.Transcript) > 0 {
+				return content.Transcript, nil
 			}
 		}
 
-		// Fall back to old format if sessions map not available
-		if transcriptPath == "" {
-			transcriptPath = checkpointPath + "/" + paths.TranscriptFileName
-		}
-
-		file, err := tree.File(transcriptPath)
+		// Fall back to old format with the transcript at the checkpoint root
+		transcriptPath := checkpointPath + "/" + paths.TranscriptFileName
+		content, err := store.ReadCommittedFile(ctx, transcriptPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to find transcript at %s: %w", transcriptPath, err)
 		}
-		content, err := file.Contents()
-		if err != nil {
-			return nil, fmt.Errorf("failed to read transcript: %w", err)
-		}
-		return []byte(content), nil
+		return content, nil
 	}
 
 	return nil, fmt.Errorf("invalid metadata path format: %s", metadataDir)
 }
 
-// findTaskCheckpointPath finds the full path to a task checkpoint on the entire/checkpoints/v1 branch.
-// Searches checkpoint directories for the task checkpoint matching the commit SHA and tool use ID.
-func (s *AutoCommitStrategy) findTaskCheckpointPath(repo *git.Repository, commitSHA, toolUseID string) (string, error) {
-	// Use findTaskMetadataPathForCommit which searches commit history
-	taskPath, err := s.findTaskMetadataPathForCommit(repo, commitSHA, toolUseID)
+// findTaskCheckpointPath finds the full path to a task checkpoint in the checkpoint store.
+// Searches the checkpoints linked to the commit for the task checkpoint matching the tool use ID.
+func (s *AutoCommitStrategy) findTaskCheckpointPath(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore, commitSHA, toolUseID string) (string, error) {
+	taskPath, err := s.findTaskMetadataPathForCommit(ctx, repo, store, commitSHA, toolUseID)
 	if err != nil {
 		return "", err
 	}
@@ -838,12 +786,16 @@ func (s *AutoCommitStrategy) findTaskCheckpointPath(repo *git.Repository, commit
 }
 
 // GetMetadataRef returns a reference to the metadata for the given checkpoint.
-// For auto-commit strategy, returns the checkpoint path on entire/checkpoints/v1 branch.
+// For auto-commit strategy, returns the checkpoint path in the checkpoint store.
 func (s *AutoCommitStrategy) GetMetadataRef(checkpoint Checkpoint) string {
 	if checkpoint.CheckpointID.IsEmpty() {
 		return ""
 	}
-	return paths.MetadataBranchName + ":" + checkpoint.CheckpointID.Path()
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		return ""
+	}
+	return store.Location() + ":" + checkpoint.CheckpointID.Path()
 }
 
 // GetSessionMetadataRef returns a reference to the most recent metadata for a session.
@@ -0,0 +1,204 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Squashing collapses the commits the auto-commit strategy made for a session
+// into a single commit carrying all their Entire-Checkpoint trailers. The
+// squashed commit has the same tree as the last session commit, so only the
+// branch moves; the index and working tree are left alone.
+
+// ErrNothingToSquash is returned by SquashSession when the session has fewer
+// than two commits at the tip of the current branch.
+var ErrNothingToSquash = errors.New("no session commits to squash")
+
+// ErrSessionPushed is returned by SquashSession when some of the session's
+// commits are on a remote-tracking branch and squashing isn't forced.
+var ErrSessionPushed = errors.New("session commits have already been pushed")
+
+// SquashResult describes a squashed session.
+type SquashResult struct {
+	Commit        string // The squashed commit
+	Squashed      int    // Number of commits it replaces
+	CheckpointIDs []id.CheckpointID
+}
+
+// SquashSession squashes the auto-commit strategy commits of a session into
+// one commit on the current branch.
+//
+// The session's commits are the run of commits at HEAD whose checkpoints all
+// belong to the session; squashing stops at the first commit that isn't one
+// of them, such as a commit made by the user. Unless force is set, it fails
+// with ErrSessionPushed if any of the commits is on a remote-tracking branch.
+func SquashSession(ctx context.Context, sessionID string, force bool) (*SquashResult, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	// Walk back from HEAD, newest first.
+	var commits []*object.Commit
+	var summary *checkpoint.Summary
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+	for len(commit.ParentHashes) == 1 {
+		cpIDs := trailers.ParseAllCheckpoints(commit.Message)
+		if len(cpIDs) == 0 {
+			break
+		}
+		belongs := true
+		for _, cpID := range cpIDs {
+			metadata, readErr := readLatestSessionMetadata(ctx, store, cpID)
+			if readErr != nil || metadata.SessionID != sessionID {
+				belongs = false
+				break
+			}
+			if summary == nil && metadata.Summary != nil {
+				summary = metadata.Summary
+			}
+		}
+		if !belongs {
+			break
+		}
+		commits = append(commits, commit)
+		if commit, err = repo.CommitObject(commit.ParentHashes[0]); err != nil {
+			return nil, fmt.Errorf("failed to read commit: %w", err)
+		}
+	}
+	if len(commits) < 2 {
+		return nil, ErrNothingToSquash
+	}
+	slices.Reverse(commits)
+	oldest := commits[0]
+	base := oldest.ParentHashes[0]
+
+	if !force {
+		remotes, err := gitIn(ctx, "", nil, "for-each-ref", "--format=%(refname:short)", "--contains", oldest.Hash.String(), "refs/remotes")
+		if err != nil {
+			return nil, fmt.Errorf("failed to check remote branches: %w", err)
+		}
+		if remotes != "" {
+			return nil, fmt.Errorf("%w to %s", ErrSessionPushed, strings.Join(strings.Fields(remotes), ", "))
+		}
+	}
+
+	messages := make([]string, len(commits))
+	var cpIDs []id.CheckpointID
+	for i, c := range commits {
+		messages[i] = strings.TrimSpace(c.Message)
+		for _, cpID := range trailers.ParseAllCheckpoints(c.Message) {
+			if !slices.Contains(cpIDs, cpID) {
+				cpIDs = append(cpIDs, cpID)
+			}
+		}
+	}
+	message := squashedAutoCommitMessage(sessionID, summary, messages, cpIDs)
+
+	// commit-tree honors commit.gpgSign, unlike commits made with go-git.
+	tip := head.Hash().String()
+	squashed, err := gitIn(ctx, "", strings.NewReader(message), "commit-tree", tip+"^{tree}", "-p", base.String(), "-F", "-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create squashed commit: %w", err)
+	}
+	if _, err := gitIn(ctx, "", nil, "update-ref", "-m", "entire: squash session "+sessionID, "HEAD", squashed, tip); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", head.Name().Short(), err)
+	}
+	TryUpdateCheckpointIndex(logging.WithComponent(ctx, "squash"))
+
+	return &SquashResult{
+		Commit:        squashed,
+		Squashed:      len(commits),
+		CheckpointIDs: cpIDs,
+	}, nil
+}
+
+// readLatestSessionMetadata reads the metadata of a checkpoint's most recent
+// session. Unlike ReadLatestSessionContent it leaves the transcript, prompts
+// and context alone, so it works on encrypted checkpoints without the key.
+func readLatestSessionMetadata(ctx context.Context, store checkpoint.CommittedStore, cpID id.CheckpointID) (*checkpoint.CommittedMetadata, error) {
+	summary, err := store.ReadCommitted(ctx, cpID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+	}
+	if summary == nil || len(summary.Sessions) == 0 {
+		return nil, checkpoint.ErrCheckpointNotFound
+	}
+	metadataPath := strings.TrimPrefix(summary.Sessions[len(summary.Sessions)-1].Metadata, "/")
+	data, err := store.ReadCommittedFile(ctx, metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session metadata of checkpoint %s: %w", cpID, err)
+	}
+	var metadata checkpoint.CommittedMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse session metadata of checkpoint %s: %w", cpID, err)
+	}
+	return &metadata, nil
+}
+
+// squashedAutoCommitMessage builds the message of a squashed session: the
+// intent of its summary (or else its first prompt) as subject, the outcome of
+// its summary, the subjects of the squashed commits, and the checkpoint
+// trailers.
+func squashedAutoCommitMessage(sessionID string, summary *checkpoint.Summary, messages []string, cpIDs []id.CheckpointID) string {
+	subject := sessionPromptSubject(sessionID)
+	if summary != nil && summary.Intent != "" {
+		subject = TruncateDescription(strings.TrimSpace(strings.SplitN(summary.Intent, "\n", 2)[0]), 72)
+	}
+	if subject == "" {
+		subject = strings.SplitN(messages[0], "\n", 2)[0]
+	}
+
+	intro := fmt.Sprintf("Squashed %d commits of session %s:", len(messages), sessionID)
+	if summary != nil && summary.Outcome != "" {
+		intro = strings.TrimSpace(summary.Outcome) + "\n\n" + intro
+	}
+	return formatSquashMessage(subject, intro, messages, cpIDs)
+}
+
+// HandleSessionEnd squashes the session's commits when
+// strategy_options.squash_on_session_end is enabled. Commits that were
+// already pushed are left alone.
+func (s *AutoCommitStrategy) HandleSessionEnd(state *session.State) error {
+	opts, err := settings.Load()
+	if err != nil || !opts.IsSquashOnSessionEnd() {
+		return nil //nolint:nilerr // Unreadable settings mean the option is off
+	}
+	result, err := SquashSession(context.Background(), state.SessionID, false)
+	if errors.Is(err, ErrNothingToSquash) {
+		return nil
+	}
+	if errors.Is(err, ErrSessionPushed) {
+		return fmt.Errorf("not squashing: %w; run 'entire squash --force %s' to squash anyway", err, state.SessionID)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Squashed %d session commits into %s\n", result.Squashed, result.Commit[:7])
+	return nil
+}
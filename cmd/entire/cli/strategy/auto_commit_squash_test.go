@@ -0,0 +1,151 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"filippo.io/age"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// initAutoCommitRepo creates a repository with one commit and the auto-commit
+// strategy set up.
+func initAutoCommitRepo(t *testing.T) (*git.Repository, string, Strategy) {
+	t.Helper()
+	repo, dir := initRepoWithCommit(t, "")
+	s := NewAutoCommitStrategy()
+	if err := s.EnsureSetup(); err != nil {
+		t.Fatalf("EnsureSetup() error = %v", err)
+	}
+	return repo, dir, s
+}
+
+func TestSquashSession(t *testing.T) {
+	repo, dir, s := initAutoCommitRepo(t)
+	ctx := context.Background()
+	sessionID := "2026-10-17-squash"
+	base := headHash(t, repo)
+
+	saveSessionTurn(t, s, dir, sessionID, "First turn", map[string]string{"a.txt": "a\n"})
+	saveSessionTurn(t, s, dir, sessionID, "Second turn", map[string]string{"b.txt": "b\n"})
+	saveSessionTurn(t, s, dir, sessionID, "Third turn", map[string]string{"a.txt": "a2\n"})
+	tip := headHash(t, repo)
+
+	result, err := SquashSession(ctx, sessionID, false)
+	if err != nil {
+		t.Fatalf("SquashSession() error = %v", err)
+	}
+	if result.Squashed != 3 || len(result.CheckpointIDs) != 3 {
+		t.Fatalf("SquashSession() = %+v, want 3 commits and 3 checkpoints", result)
+	}
+	if head := headHash(t, repo); head != result.Commit {
+		t.Errorf("HEAD = %s, want the squashed commit %s", head, result.Commit)
+	}
+	if parent := parentHash(t, repo, result.Commit); parent != base {
+		t.Errorf("squashed commit parent = %s, want %s", parent, base)
+	}
+
+	squashed, err := repo.CommitObject(plumbing.NewHash(result.Commit))
+	if err != nil {
+		t.Fatalf("failed to read squashed commit: %v", err)
+	}
+	old, err := repo.CommitObject(plumbing.NewHash(tip))
+	if err != nil {
+		t.Fatalf("failed to read old tip: %v", err)
+	}
+	if squashed.TreeHash != old.TreeHash {
+		t.Error("squashed commit should have the tree of the last session commit")
+	}
+	if got := trailers.ParseAllCheckpoints(squashed.Message); !slices.Equal(got, result.CheckpointIDs) {
+		t.Errorf("squashed commit checkpoints = %v, want %v", got, result.CheckpointIDs)
+	}
+
+	if _, err := SquashSession(ctx, sessionID, false); !errors.Is(err, ErrNothingToSquash) {
+		t.Errorf("second SquashSession() error = %v, want ErrNothingToSquash", err)
+	}
+}
+
+func TestSquashSession_StopsAtOtherCommits(t *testing.T) {
+	repo, dir, s := initAutoCommitRepo(t)
+	ctx := context.Background()
+	sessionID := "2026-10-17-squash-user"
+
+	saveSessionTurn(t, s, dir, sessionID, "Before", map[string]string{"a.txt": "a\n"})
+	writeRepoFile(t, dir, "user.txt", "user\n")
+	runGit(t, "add", "user.txt")
+	runGit(t, "commit", "-q", "--no-verify", "-m", "User commit")
+	userCommit := headHash(t, repo)
+	saveSessionTurn(t, s, dir, sessionID, "After 1", map[string]string{"b.txt": "b\n"})
+	saveSessionTurn(t, s, dir, sessionID, "After 2", map[string]string{"c.txt": "c\n"})
+
+	result, err := SquashSession(ctx, sessionID, false)
+	if err != nil {
+		t.Fatalf("SquashSession() error = %v", err)
+	}
+	if result.Squashed != 2 || parentHash(t, repo, result.Commit) != userCommit {
+		t.Errorf("SquashSession() = %+v, want the 2 commits after %s squashed", result, userCommit)
+	}
+
+	// Another session's commit at the tip stops the walk right away.
+	saveSessionTurn(t, s, dir, "2026-10-17-other", "Other session", map[string]string{"d.txt": "d\n"})
+	if _, err := SquashSession(ctx, sessionID, false); !errors.Is(err, ErrNothingToSquash) {
+		t.Errorf("SquashSession() under another session's commit error = %v, want ErrNothingToSquash", err)
+	}
+}
+
+func TestSquashSession_RefusesPushedCommits(t *testing.T) {
+	repo, dir, s := initAutoCommitRepo(t)
+	ctx := context.Background()
+	sessionID := "2026-10-17-squash-pushed"
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, "init", "-q", "--bare", remote)
+	runGit(t, "remote", "add", "origin", remote)
+
+	saveSessionTurn(t, s, dir, sessionID, "First turn", map[string]string{"a.txt": "a\n"})
+	saveSessionTurn(t, s, dir, sessionID, "Second turn", map[string]string{"b.txt": "b\n"})
+	tip := headHash(t, repo)
+	runGit(t, "push", "-q", "--no-verify", "origin", "HEAD:refs/heads/feature")
+
+	if _, err := SquashSession(ctx, sessionID, false); !errors.Is(err, ErrSessionPushed) {
+		t.Fatalf("SquashSession() error = %v, want ErrSessionPushed", err)
+	}
+	if headHash(t, repo) != tip {
+		t.Fatal("SquashSession() should not move HEAD when refusing")
+	}
+	if _, err := SquashSession(ctx, sessionID, true); err != nil {
+		t.Errorf("SquashSession(force) error = %v", err)
+	}
+}
+
+func TestSquashSession_EncryptedWithoutIdentity(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+	// Writers only need the recipients; the session is told apart by its
+	// metadata, which stays readable.
+	repo, dir := initRepoWithCommit(t, `{"encryption": {"recipients": ["`+identity.Recipient().String()+`"]}}`)
+	s := NewAutoCommitStrategy()
+	if err := s.EnsureSetup(); err != nil {
+		t.Fatalf("EnsureSetup() error = %v", err)
+	}
+	sessionID := "2026-10-17-squash-encrypted"
+	base := headHash(t, repo)
+
+	saveSessionTurn(t, s, dir, sessionID, "First turn", map[string]string{"a.txt": "a\n"})
+	saveSessionTurn(t, s, dir, sessionID, "Second turn", map[string]string{"b.txt": "b\n"})
+
+	result, err := SquashSession(context.Background(), sessionID, false)
+	if err != nil {
+		t.Fatalf("SquashSession() error = %v", err)
+	}
+	if result.Squashed != 2 || parentHash(t, repo, result.Commit) != base {
+		t.Errorf("SquashSession() = %+v, want both session commits squashed onto %s", result, base)
+	}
+}
@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -357,6 +358,47 @@ func TestAutoCommitStrategy_SaveTaskCheckpoint_CommitHasMetadataRef(t *testing.T
 	}
 }
 
+func TestAutoCommitStrategy_FindTaskCheckpointPath_FilesystemStore(t *testing.T) {
+	repo, dir := initRepoWithCommit(t, `{"checkpoint_store": {"type": "filesystem", "path": "../checkpoints"}}`)
+
+	s := &AutoCommitStrategy{}
+	if err := s.EnsureSetup(); err != nil {
+		t.Fatalf("EnsureSetup() error = %v", err)
+	}
+	writeRepoFile(t, dir, "task_output.txt", "task result\n")
+	transcriptPath := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(transcriptPath, []byte(`{"type":"test"}`), 0o644); err != nil {
+		t.Fatalf("failed to write transcript: %v", err)
+	}
+	if err := s.SaveTaskCheckpoint(TaskCheckpointContext{
+		SessionID:      "test-session-fs",
+		ToolUseID:      "toolu_fs123",
+		CheckpointUUID: "checkpoint-uuid-fs",
+		TranscriptPath: transcriptPath,
+		NewFiles:       []string{"task_output.txt"},
+		AuthorName:     "Test",
+		AuthorEmail:    "test@example.com",
+	}); err != nil {
+		t.Fatalf("SaveTaskCheckpoint() error = %v", err)
+	}
+
+	store, err := s.getCheckpointStore()
+	if err != nil {
+		t.Fatalf("getCheckpointStore() error = %v", err)
+	}
+	ctx := context.Background()
+	taskPath, err := s.findTaskCheckpointPath(ctx, repo, store, headHash(t, repo), "toolu_fs123")
+	if err != nil {
+		t.Fatalf("findTaskCheckpointPath() error = %v", err)
+	}
+	if _, err := store.ReadCommittedFile(ctx, taskPath); err != nil {
+		t.Errorf("ReadCommittedFile(%q) error = %v", taskPath, err)
+	}
+	if _, err := s.findTaskCheckpointPath(ctx, repo, store, headHash(t, repo), "toolu_other"); err == nil {
+		t.Error("findTaskCheckpointPath() should fail for an unknown tool use ID")
+	}
+}
+
 func TestAutoCommitStrategy_SaveTaskCheckpoint_NoChangesSkipsCommit(t *testing.T) {
 	// Setup temp git repo
 	dir := t.TempDir()
@@ -0,0 +1,408 @@
+package strategy
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/buildinfo"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Bundles
+//
+// A bundle is a gzipped tar archive holding committed checkpoints in a form
+// that doesn't depend on how the exporting repository stores them:
+//
+//	manifest.json
+//	checkpoints/<id>/checkpoint.json   summary and linked commit SHAs
+//	checkpoints/<id>/<n>/metadata.json session metadata
+//	checkpoints/<id>/<n>/full.jsonl    plain transcript
+//	checkpoints/<id>/<n>/prompts.json  user prompts, as a JSON array
+//	checkpoints/<id>/<n>/context.md
+//
+// Transcripts are exported reassembled, decompressed and decrypted, and are
+// written back through WriteCommitted on import, so the importing repository
+// applies its own compression, delta encoding and encryption settings.
+// Bundles are read into memory, so ImportBundle caps the size of each entry
+// and of the whole bundle.
+
+// BundleFormatVersion is the bundle layout version written by ExportBundle.
+const BundleFormatVersion = 1
+
+// bundleManifestName is the name of the manifest in a bundle.
+const bundleManifestName = "manifest.json"
+
+// bundleCheckpointName is the name of a checkpoint's summary in a bundle.
+const bundleCheckpointName = "checkpoint.json"
+
+// bundlePromptsName is the name of a session's prompts in a bundle.
+const bundlePromptsName = "prompts.json"
+
+// storedPromptSeparator separates prompts in a checkpoint's stored prompt.txt.
+const storedPromptSeparator = "\n\n---\n\n"
+
+// Size limits for the bundles ImportBundle reads. Variables so tests can lower them.
+var (
+	maxBundleEntrySize int64 = 512 << 20 // Largest file in a bundle
+	maxBundleSize      int64 = 2 << 30   // Largest bundle, uncompressed
+)
+
+// BundleManifest describes a bundle.
+type BundleManifest struct {
+	FormatVersion int               `json:"format_version"`
+	CLIVersion    string            `json:"cli_version,omitempty"`
+	ExportedAt    time.Time         `json:"exported_at"`
+	Checkpoints   []id.CheckpointID `json:"checkpoints"`
+}
+
+// BundleCheckpoint is a checkpoint's entry in a bundle.
+type BundleCheckpoint struct {
+	Summary checkpoint.CheckpointSummary `json:"summary"`
+
+	// LinkedCommits are the commits in the exporting repository whose
+	// Entire-Checkpoint trailer references the checkpoint.
+	LinkedCommits []string `json:"linked_commits,omitempty"`
+}
+
+// ImportResult is the outcome of ImportBundle.
+type ImportResult struct {
+	Imported []id.CheckpointID
+	Skipped  []id.CheckpointID // Already present in the store
+
+	// LinkedCommits counts the commits the bundle links to imported
+	// checkpoints; PresentCommits counts those that exist in this repository.
+	LinkedCommits  int
+	PresentCommits int
+}
+
+// ResolveExportCheckpoints resolves export arguments to checkpoint IDs, in
+// order and without duplicates. An argument is a checkpoint ID, a commit, or
+// a commit range ("main..feature"), which resolves to the checkpoints that
+// the Entire-Checkpoint trailers and notes of its commits reference, oldest
+// first.
+func ResolveExportCheckpoints(ctx context.Context, repo *git.Repository, args []string) ([]id.CheckpointID, error) {
+	var ids []id.CheckpointID
+	notes := LoadCheckpointNotes(repo)
+	add := func(cpID id.CheckpointID) {
+		if !slices.Contains(ids, cpID) {
+			ids = append(ids, cpID)
+		}
+	}
+	for _, arg := range args {
+		if cpID, err := id.NewCheckpointID(arg); err == nil {
+			add(cpID)
+			continue
+		}
+
+		var hashes []plumbing.Hash
+		if strings.Contains(arg, "..") {
+			rangeHashes, err := revList(ctx, arg)
+			if err != nil {
+				return nil, err
+			}
+			hashes = rangeHashes
+		} else {
+			hash, err := repo.ResolveRevision(plumbing.Revision(arg))
+			if err != nil {
+				return nil, fmt.Errorf("%q is neither a checkpoint ID nor a commit: %w", arg, err)
+			}
+			hashes = []plumbing.Hash{*hash}
+		}
+
+		found := false
+		for _, hash := range hashes {
+			commit, err := repo.CommitObject(hash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+			}
+			for _, cpID := range notes.ParseAllCheckpoints(commit) {
+				add(cpID)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no commits in %q are linked to a checkpoint", arg)
+		}
+	}
+	return ids, nil
+}
+
+// revList lists the commits in a range, oldest first.
+func revList(ctx context.Context, revRange string) ([]plumbing.Hash, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-list", "--reverse", revRange, "--") //nolint:gosec // revRange is passed as a single argument
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit range %q: %w", revRange, err)
+	}
+	var hashes []plumbing.Hash
+	for _, line := range strings.Fields(string(output)) {
+		hashes = append(hashes, plumbing.NewHash(line))
+	}
+	return hashes, nil
+}
+
+// ExportBundle writes the given checkpoints, with the commits linked to
+// them in repo, as a bundle to w.
+func ExportBundle(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore, checkpointIDs []id.CheckpointID, w io.Writer) error {
+	linked, err := LinkedCommits(repo)
+	if err != nil {
+		return fmt.Errorf("failed to find linked commits: %w", err)
+	}
+
+	now := time.Now().UTC()
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	writeFile := func(name string, content []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(content)),
+			ModTime: now,
+		}); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return nil
+	}
+	writeJSON := func(name string, v any) error {
+		data, err := jsonutil.MarshalIndentWithNewline(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		return writeFile(name, data)
+	}
+
+	if err := writeJSON(bundleManifestName, BundleManifest{
+		FormatVersion: BundleFormatVersion,
+		CLIVersion:    buildinfo.Version,
+		ExportedAt:    now,
+		Checkpoints:   checkpointIDs,
+	}); err != nil {
+		return err
+	}
+
+	for _, cpID := range checkpointIDs {
+		summary, err := store.ReadCommitted(ctx, cpID)
+		if err != nil {
+			return fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+		}
+		if summary == nil {
+			return fmt.Errorf("checkpoint %s: %w", cpID, checkpoint.ErrCheckpointNotFound)
+		}
+
+		dir := "checkpoints/" + cpID.String() + "/"
+		commits := slices.Clone(linked[cpID])
+		slices.Sort(commits)
+		if err := writeJSON(dir+bundleCheckpointName, BundleCheckpoint{Summary: *summary, LinkedCommits: commits}); err != nil {
+			return err
+		}
+
+		for i := range summary.Sessions {
+			content, err := store.ReadSessionContent(ctx, cpID, i)
+			if errors.Is(err, checkpoint.ErrEncryptedContent) {
+				return fmt.Errorf("checkpoint %s is encrypted; configure encryption.identity_file to export it: %w", cpID, err)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read session %d of checkpoint %s: %w", i, cpID, err)
+			}
+			sessionDir := dir + strconv.Itoa(i) + "/"
+			if err := writeJSON(sessionDir+paths.MetadataFileName, content.Metadata); err != nil {
+				return err
+			}
+			// The store keeps prompts joined in prompt.txt; WriteCommitted
+			// joins them the same way on import, so the file round-trips exactly.
+			if content.Prompts != "" {
+				if err := writeJSON(sessionDir+bundlePromptsName, strings.Split(content.Prompts, storedPromptSeparator)); err != nil {
+					return err
+				}
+			}
+			files := []struct {
+				name    string
+				content []byte
+			}{
+				{paths.TranscriptFileName, content.Transcript},
+				{paths.ContextFileName, []byte(content.Context)},
+			}
+			for _, f := range files {
+				if len(f.content) == 0 {
+					continue
+				}
+				if err := writeFile(sessionDir+f.name, f.content); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return nil
+}
+
+// ImportBundle writes the checkpoints in the bundle read from r to store.
+// Checkpoints that already exist in store are skipped.
+func ImportBundle(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore, r io.Reader) (*ImportResult, error) {
+	files, err := readBundleFiles(r)
+	if err != nil {
+		return nil, err
+	}
+	var manifest BundleManifest
+	if err := readBundleJSON(files, bundleManifestName, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.FormatVersion < 1 || manifest.FormatVersion > BundleFormatVersion {
+		return nil, fmt.Errorf("unsupported bundle format version %d; upgrade entire to import it", manifest.FormatVersion)
+	}
+
+	authorName, authorEmail := GetGitAuthorFromRepo(repo)
+	result := &ImportResult{}
+	for _, cpID := range manifest.Checkpoints {
+		existing, err := store.ReadCommitted(ctx, cpID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint %s: %w", cpID, err)
+		}
+		if existing != nil {
+			result.Skipped = append(result.Skipped, cpID)
+			continue
+		}
+
+		dir := "checkpoints/" + cpID.String() + "/"
+		var entry BundleCheckpoint
+		if err := readBundleJSON(files, dir+bundleCheckpointName, &entry); err != nil {
+			return nil, err
+		}
+		if entry.Summary.CheckpointID != cpID {
+			return nil, fmt.Errorf("invalid bundle: %s names checkpoint %q", dir+bundleCheckpointName, entry.Summary.CheckpointID)
+		}
+
+		for i := range entry.Summary.Sessions {
+			sessionDir := dir + strconv.Itoa(i) + "/"
+			var metadata checkpoint.CommittedMetadata
+			if err := readBundleJSON(files, sessionDir+paths.MetadataFileName, &metadata); err != nil {
+				return nil, err
+			}
+			var prompts []string
+			if _, ok := files[sessionDir+bundlePromptsName]; ok {
+				if err := readBundleJSON(files, sessionDir+bundlePromptsName, &prompts); err != nil {
+					return nil, err
+				}
+			}
+			if err := store.WriteCommitted(ctx, checkpoint.WriteCommittedOptions{
+				CheckpointID:                cpID,
+				SessionID:                   metadata.SessionID,
+				Strategy:                    metadata.Strategy,
+				Branch:                      metadata.Branch,
+				Transcript:                  files[sessionDir+paths.TranscriptFileName],
+				Prompts:                     prompts,
+				Context:                     files[sessionDir+paths.ContextFileName],
+				FilesTouched:                metadata.FilesTouched,
+				CheckpointsCount:            metadata.CheckpointsCount,
+				AuthorName:                  authorName,
+				AuthorEmail:                 authorEmail,
+				IsTask:                      metadata.IsTask,
+				ToolUseID:                   metadata.ToolUseID,
+				Agent:                       metadata.Agent,
+				TurnID:                      metadata.TurnID,
+				TranscriptIdentifierAtStart: metadata.TranscriptIdentifierAtStart,
+				CheckpointTranscriptStart:   metadata.GetTranscriptStart(),
+				TokenUsage:                  metadata.TokenUsage,
+				InitialAttribution:          metadata.InitialAttribution,
+				Summary:                     metadata.Summary,
+				SessionTranscriptPath:       metadata.TranscriptPath,
+				CreatedAt:                   metadata.CreatedAt,
+			}); err != nil {
+				return nil, fmt.Errorf("failed to import session %d of checkpoint %s: %w", i, cpID, err)
+			}
+		}
+		result.Imported = append(result.Imported, cpID)
+
+		for _, commit := range entry.LinkedCommits {
+			result.LinkedCommits++
+			if _, err := repo.CommitObject(plumbing.NewHash(commit)); err == nil {
+				result.PresentCommits++
+			}
+		}
+	}
+	return result, nil
+}
+
+// readBundleFiles reads the regular files of a bundle into memory. It fails
+// if a file is larger than maxBundleEntrySize or the uncompressed bundle is
+// larger than maxBundleSize.
+func readBundleFiles(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a checkpoint bundle: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	files := make(map[string][]byte)
+	bundle := &io.LimitedReader{R: gz, N: maxBundleSize + 1}
+	tooLarge := func() error {
+		return fmt.Errorf("bundle is larger than %d bytes", maxBundleSize)
+	}
+	tr := tar.NewReader(bundle)
+	for {
+		header, err := tr.Next()
+		if bundle.N <= 0 {
+			return nil, tooLarge()
+		}
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Size > maxBundleEntrySize {
+			return nil, fmt.Errorf("%s in bundle is larger than %d bytes", header.Name, maxBundleEntrySize)
+		}
+		content, err := io.ReadAll(io.LimitReader(tr, maxBundleEntrySize+1))
+		if bundle.N <= 0 {
+			return nil, tooLarge()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from bundle: %w", header.Name, err)
+		}
+		if int64(len(content)) > maxBundleEntrySize {
+			return nil, fmt.Errorf("%s in bundle is larger than %d bytes", header.Name, maxBundleEntrySize)
+		}
+		files[header.Name] = content
+	}
+}
+
+// readBundleJSON parses a JSON file from a bundle.
+func readBundleJSON(files map[string][]byte, name string, v any) error {
+	content, ok := files[name]
+	if !ok {
+		return fmt.Errorf("invalid bundle: missing %s", name)
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("invalid bundle: failed to parse %s: %w", name, err)
+	}
+	return nil
+}
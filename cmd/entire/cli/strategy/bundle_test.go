@@ -0,0 +1,205 @@
+package strategy
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestResolveExportCheckpoints(t *testing.T) {
+	repo, dir := initRepoWithSettings(t, "")
+	ctx := context.Background()
+	first := id.MustCheckpointID("b0000000000a")
+	second := id.MustCheckpointID("b0000000000b")
+	commitWithoutCheckpoint := func() {
+		t.Helper()
+		runGit(t, "-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "No checkpoint")
+	}
+	commitWithoutCheckpoint()
+	commitLinkedToCheckpoint(t, repo, dir, first)
+	commitLinkedToCheckpoint(t, repo, dir, second)
+	commitWithoutCheckpoint()
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []id.CheckpointID
+		wantErr string
+	}{
+		{name: "checkpoint ID", args: []string{"b0000000000b"}, want: []id.CheckpointID{second}},
+		{name: "commit", args: []string{"HEAD~1"}, want: []id.CheckpointID{second}},
+		{name: "range oldest first", args: []string{"HEAD~3..HEAD"}, want: []id.CheckpointID{first, second}},
+		{name: "no duplicates", args: []string{"b0000000000b", "HEAD~3..HEAD"}, want: []id.CheckpointID{second, first}},
+		{name: "commit without trailer", args: []string{"HEAD"}, wantErr: "no commits"},
+		{name: "unknown revision", args: []string{"no-such-branch"}, wantErr: "neither a checkpoint ID nor a commit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveExportCheckpoints(ctx, repo, tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveExportCheckpoints() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveExportCheckpoints() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ResolveExportCheckpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportImportBundle(t *testing.T) {
+	repo, dir := initRepoWithSettings(t, "")
+	ctx := context.Background()
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	cpID := id.MustCheckpointID("b0000000000c")
+	for _, sessionID := range []string{"session-a", "session-b"} {
+		if err := store.WriteCommitted(ctx, checkpoint.WriteCommittedOptions{
+			CheckpointID:     cpID,
+			SessionID:        sessionID,
+			Strategy:         "manual-commit",
+			Branch:           "master",
+			Transcript:       []byte(`{"type":"user","message":{"content":"` + sessionID + `"}}` + "\n"),
+			Prompts:          []string{"first prompt", "intro\n\n---\n\nsecond prompt after a rule"},
+			Context:          []byte("# Context\n"),
+			FilesTouched:     []string{"main.go"},
+			CheckpointsCount: 2,
+			Summary:          &checkpoint.Summary{Intent: "add a feature"},
+		}); err != nil {
+			t.Fatalf("WriteCommitted() error = %v", err)
+		}
+	}
+	commitLinkedToCheckpoint(t, repo, dir, cpID)
+
+	var bundle bytes.Buffer
+	if err := ExportBundle(ctx, repo, store, []id.CheckpointID{cpID}, &bundle); err != nil {
+		t.Fatalf("ExportBundle() error = %v", err)
+	}
+
+	files, err := readBundleFiles(bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("readBundleFiles() error = %v", err)
+	}
+	if _, ok := files["checkpoints/"+cpID.String()+"/0/prompts.json"]; !ok {
+		t.Errorf("bundle has no prompts.json: %v", slices.Collect(maps.Keys(files)))
+	}
+
+	targetRepo, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("failed to init target repo: %v", err)
+	}
+	target := checkpoint.NewGitStore(targetRepo)
+	result, err := ImportBundle(ctx, targetRepo, target, bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("ImportBundle() error = %v", err)
+	}
+	if !slices.Equal(result.Imported, []id.CheckpointID{cpID}) || result.LinkedCommits != 1 || result.PresentCommits != 0 {
+		t.Errorf("ImportBundle() = %+v, want the checkpoint imported with 1 linked commit absent", result)
+	}
+
+	summary, err := target.ReadCommitted(ctx, cpID)
+	if err != nil || summary == nil || len(summary.Sessions) != 2 {
+		t.Fatalf("ReadCommitted() = %+v, %v; want 2 sessions", summary, err)
+	}
+	for i := range summary.Sessions {
+		want, err := store.ReadSessionContent(ctx, cpID, i)
+		if err != nil {
+			t.Fatalf("ReadSessionContent(source, %d) error = %v", i, err)
+		}
+		got, err := target.ReadSessionContent(ctx, cpID, i)
+		if err != nil {
+			t.Fatalf("ReadSessionContent(target, %d) error = %v", i, err)
+		}
+		if !bytes.Equal(got.Transcript, want.Transcript) || got.Prompts != want.Prompts || got.Context != want.Context {
+			t.Errorf("session %d content = %+v, want %+v", i, got, want)
+		}
+		if got.Metadata.SessionID != want.Metadata.SessionID || !got.Metadata.CreatedAt.Equal(want.Metadata.CreatedAt) ||
+			got.Metadata.Summary == nil || got.Metadata.Summary.Intent != "add a feature" {
+			t.Errorf("session %d metadata = %+v, want %+v", i, got.Metadata, want.Metadata)
+		}
+	}
+
+	// Importing into a store that already has the checkpoint skips it.
+	result, err = ImportBundle(ctx, repo, store, bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("ImportBundle() again error = %v", err)
+	}
+	if len(result.Imported) != 0 || !slices.Equal(result.Skipped, []id.CheckpointID{cpID}) {
+		t.Errorf("ImportBundle() again = %+v, want the checkpoint skipped", result)
+	}
+}
+
+func TestImportBundle_Invalid(t *testing.T) {
+	repo, _ := initRepoWithSettings(t, "")
+	_, err := ImportBundle(context.Background(), repo, checkpoint.NewGitStore(repo), strings.NewReader("not a bundle"))
+	if err == nil || !strings.Contains(err.Error(), "not a checkpoint bundle") {
+		t.Errorf("ImportBundle() error = %v, want a bundle error", err)
+	}
+}
+
+func TestImportBundle_TooLarge(t *testing.T) {
+	repo, _ := initRepoWithSettings(t, "")
+	writeBundle := func(files map[string]string) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for name, content := range files {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+				t.Fatalf("failed to write header: %v", err)
+			}
+			if _, err := tw.Write([]byte(content)); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatalf("failed to close tar: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatalf("failed to close gzip: %v", err)
+		}
+		return buf.Bytes()
+	}
+	defer func(entry, total int64) { maxBundleEntrySize, maxBundleSize = entry, total }(maxBundleEntrySize, maxBundleSize)
+	maxBundleEntrySize, maxBundleSize = 1024, 8192
+
+	tests := map[string]struct {
+		files map[string]string
+		want  string
+	}{
+		"entry": {
+			files: map[string]string{"manifest.json": strings.Repeat("x", 2048)},
+			want:  "manifest.json in bundle is larger than 1024 bytes",
+		},
+		"bundle": {
+			files: map[string]string{"a": strings.Repeat("x", 1000), "b": strings.Repeat("x", 1000), "c": strings.Repeat("x", 1000),
+				"d": strings.Repeat("x", 1000), "e": strings.Repeat("x", 1000), "f": strings.Repeat("x", 1000)},
+			want: "bundle is larger than 8192 bytes",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ImportBundle(context.Background(), repo, checkpoint.NewGitStore(repo), bytes.NewReader(writeBundle(tt.files)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ImportBundle() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
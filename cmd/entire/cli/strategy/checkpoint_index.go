@@ -0,0 +1,697 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/jsonutil"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Checkpoint index
+//
+// Listing committed checkpoints walks every checkpoint directory in the
+// checkpoint store, and finding the commits that reference a checkpoint walks
+// commit history. Both get slow on repositories with tens of thousands of
+// checkpoints, so the checkpoint index caches them in the git common dir.
+//
+// The index is opt-in: `entire index rebuild` creates it, after which it is
+// updated incrementally after commits and metadata branch fetches. Listing
+// falls back to the checkpoint store when no index exists.
+
+// CheckpointIndexFileName is the name of the index file in the git common dir.
+const CheckpointIndexFileName = "entire-checkpoint-index.json"
+
+// checkpointIndexVersion is bumped when the index format changes; indexes
+// written with another version are ignored until rebuilt.
+const checkpointIndexVersion = 1
+
+// CheckpointIndexEntry is the indexed information about one committed checkpoint.
+type CheckpointIndexEntry struct {
+	CheckpointID     id.CheckpointID   `json:"checkpoint_id"`
+	SessionID        string            `json:"session_id,omitempty"` // Latest session
+	SessionIDs       []string          `json:"session_ids,omitempty"`
+	Branch           string            `json:"branch,omitempty"`
+	Agent            agent.AgentType   `json:"agent,omitempty"`
+	FilesTouched     []string          `json:"files_touched,omitempty"`
+	CreatedAt        time.Time         `json:"created_at"`
+	CheckpointsCount int               `json:"checkpoints_count,omitempty"`
+	TokenUsage       *agent.TokenUsage `json:"token_usage,omitempty"`
+	IsTask           bool              `json:"is_task,omitempty"`
+	ToolUseID        string            `json:"tool_use_id,omitempty"`
+}
+
+// CheckpointIndex is the local checkpoint index.
+type CheckpointIndex struct {
+	Version int `json:"version"`
+
+	// MetadataTip is the entire/checkpoints/v1 commit the checkpoint entries
+	// reflect. Empty for stores other than the metadata branch.
+	MetadataTip string `json:"metadata_tip,omitempty"`
+
+	// BranchTips records the local branch tips whose history has been
+	// scanned for Entire-Checkpoint trailers.
+	BranchTips map[string]string `json:"branch_tips,omitempty"`
+
+	// Checkpoints holds an entry per committed checkpoint.
+	Checkpoints map[id.CheckpointID]*CheckpointIndexEntry `json:"checkpoints"`
+
+	// Commits maps checkpoint IDs to the commits whose trailer or note
+	// references them. It includes checkpoints whose metadata isn't available
+	// locally, so the link survives until the metadata is fetched.
+	Commits map[id.CheckpointID][]string `json:"commits,omitempty"`
+
+	// NotesTip is the refs/notes/entire commit whose notes have been linked.
+	NotesTip string `json:"notes_tip,omitempty"`
+}
+
+func newCheckpointIndex() *CheckpointIndex {
+	return &CheckpointIndex{
+		Version:     checkpointIndexVersion,
+		BranchTips:  make(map[string]string),
+		Checkpoints: make(map[id.CheckpointID]*CheckpointIndexEntry),
+		Commits:     make(map[id.CheckpointID][]string),
+	}
+}
+
+// checkpointIndexFile returns the path to the index file.
+func checkpointIndexFile() (string, error) {
+	commonDir, err := GetGitCommonDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(commonDir, CheckpointIndexFileName), nil
+}
+
+// LoadCheckpointIndex loads the checkpoint index.
+// Returns (nil, nil) when there is no index, or it has an older format.
+func LoadCheckpointIndex() (*CheckpointIndex, error) {
+	indexFile, err := checkpointIndexFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(indexFile) //nolint:gosec // indexFile is a fixed name in the git common dir
+	if os.IsNotExist(err) {
+		return nil, nil //nolint:nilnil // nil,nil indicates no index (expected case)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint index: %w", err)
+	}
+
+	var idx CheckpointIndex
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint index: %w", err)
+	}
+	if idx.Version != checkpointIndexVersion {
+		return nil, nil //nolint:nilnil // An index in another format must be rebuilt
+	}
+	if idx.BranchTips == nil {
+		idx.BranchTips = make(map[string]string)
+	}
+	if idx.Checkpoints == nil {
+		idx.Checkpoints = make(map[id.CheckpointID]*CheckpointIndexEntry)
+	}
+	if idx.Commits == nil {
+		idx.Commits = make(map[id.CheckpointID][]string)
+	}
+	return &idx, nil
+}
+
+// saveCheckpointIndex writes the checkpoint index atomically.
+func saveCheckpointIndex(idx *CheckpointIndex) error {
+	indexFile, err := checkpointIndexFile()
+	if err != nil {
+		return err
+	}
+	data, err := jsonutil.MarshalIndentWithNewline(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint index: %w", err)
+	}
+
+	// Atomic write: write to temp file, then rename
+	tmpFile := indexFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write checkpoint index: %w", err)
+	}
+	if err := os.Rename(tmpFile, indexFile); err != nil {
+		return fmt.Errorf("failed to rename checkpoint index: %w", err)
+	}
+	return nil
+}
+
+// RebuildCheckpointIndex builds the checkpoint index from scratch, reading
+// every committed checkpoint and scanning the history of all local branches.
+func RebuildCheckpointIndex(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore) (*CheckpointIndex, error) {
+	idx := newCheckpointIndex()
+	if err := idx.update(ctx, repo, store); err != nil {
+		return nil, err
+	}
+	if err := saveCheckpointIndex(idx); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// UpdateCheckpointIndex brings an existing checkpoint index up to date with
+// the checkpoint store and local branches. It does nothing when there is no
+// index. Called after commits and after fetching the metadata branch.
+func UpdateCheckpointIndex(ctx context.Context) error {
+	idx, err := LoadCheckpointIndex()
+	if err != nil || idx == nil {
+		return err
+	}
+	repo, err := OpenRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		return err
+	}
+	if err := idx.update(ctx, repo, store); err != nil {
+		return err
+	}
+	return saveCheckpointIndex(idx)
+}
+
+// TryUpdateCheckpointIndex runs UpdateCheckpointIndex, logging failures.
+// The index is only a cache, so failing to update it never fails a command.
+func TryUpdateCheckpointIndex(ctx context.Context) {
+	if err := UpdateCheckpointIndex(ctx); err != nil {
+		logging.Warn(ctx, "failed to update checkpoint index", slog.String("error", err.Error()))
+	}
+}
+
+// UpdateCheckpointIndexAfterRewrite drops the commits a history rewrite
+// replaced from an existing checkpoint index, unless another local branch
+// still reaches them, then brings the index up to date so the replacements
+// are linked instead. It does nothing when there is no index.
+func UpdateCheckpointIndexAfterRewrite(ctx context.Context, rewrites []CommitRewrite) error {
+	idx, err := LoadCheckpointIndex()
+	if err != nil || idx == nil || len(rewrites) == 0 {
+		return err
+	}
+
+	rewritten := make([]string, 0, len(rewrites))
+	for _, rw := range rewrites {
+		rewritten = append(rewritten, rw.Old)
+	}
+	unreachable, err := unreachableCommits(ctx, rewritten)
+	if err != nil {
+		return err
+	}
+	for cpID, hashes := range idx.Commits {
+		hashes = slices.DeleteFunc(hashes, func(hash string) bool { return unreachable[hash] })
+		if len(hashes) == 0 {
+			delete(idx.Commits, cpID)
+		} else {
+			idx.Commits[cpID] = hashes
+		}
+	}
+	if err := saveCheckpointIndex(idx); err != nil {
+		return err
+	}
+	return UpdateCheckpointIndex(ctx)
+}
+
+// ListCommittedCheckpoints lists committed checkpoints, most recent first,
+// from the checkpoint index when one exists and from the store otherwise.
+// With the metadata branch store, an index that is behind the branch (e.g.
+// after a plain `git fetch`) is brought up to date first.
+func ListCommittedCheckpoints(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore) ([]checkpoint.CommittedInfo, error) {
+	idx, err := LoadCheckpointIndex()
+	if err != nil || idx == nil {
+		return store.ListCommitted(ctx) //nolint:wrapcheck // Thin fallback to the store
+	}
+	if _, ok := store.(*checkpoint.GitStore); ok {
+		refresh, changed, err := idx.syncMetadataBranch(repo)
+		if err != nil {
+			return store.ListCommitted(ctx) //nolint:wrapcheck // Thin fallback to the store
+		}
+		if changed {
+			idx.refresh(ctx, store, refresh)
+			_ = saveCheckpointIndex(idx) //nolint:errcheck // Best effort: the index is only a cache
+		}
+	}
+	return idx.CommittedInfos(), nil
+}
+
+// LinkedCommits maps checkpoints to the hashes of the commits on local
+// branches whose Entire-Checkpoint trailer references them. Uses the
+// checkpoint index when one exists and scans branch history otherwise.
+func LinkedCommits(repo *git.Repository) (map[id.CheckpointID][]string, error) {
+	idx, err := LoadCheckpointIndex()
+	if err != nil || idx == nil {
+		idx = newCheckpointIndex()
+	}
+	if _, err := idx.scanBranches(repo); err != nil {
+		return nil, err
+	}
+	return idx.Commits, nil
+}
+
+// CommittedInfos returns the indexed checkpoints, most recent first.
+func (idx *CheckpointIndex) CommittedInfos() []checkpoint.CommittedInfo {
+	infos := make([]checkpoint.CommittedInfo, 0, len(idx.Checkpoints))
+	for _, e := range idx.Checkpoints {
+		infos = append(infos, checkpoint.CommittedInfo{
+			CheckpointID:     e.CheckpointID,
+			SessionID:        e.SessionID,
+			CreatedAt:        e.CreatedAt,
+			CheckpointsCount: e.CheckpointsCount,
+			FilesTouched:     e.FilesTouched,
+			Agent:            e.Agent,
+			IsTask:           e.IsTask,
+			ToolUseID:        e.ToolUseID,
+			SessionCount:     len(e.SessionIDs),
+			SessionIDs:       e.SessionIDs,
+			Branch:           e.Branch,
+			TokenUsage:       e.TokenUsage,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.After(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// CommitsFor returns the commits whose Entire-Checkpoint trailer or note
+// references checkpointID.
+func (idx *CheckpointIndex) CommitsFor(checkpointID id.CheckpointID) []string {
+	return idx.Commits[checkpointID]
+}
+
+// update syncs the checkpoint entries with the store and scans new commits
+// on local branches.
+func (idx *CheckpointIndex) update(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore) error {
+	var refresh []id.CheckpointID
+	if _, ok := store.(*checkpoint.GitStore); ok {
+		changed, _, err := idx.syncMetadataBranch(repo)
+		if err != nil {
+			return err
+		}
+		refresh = changed
+	} else {
+		listed, err := idx.syncListed(ctx, store)
+		if err != nil {
+			return err
+		}
+		refresh = listed
+	}
+
+	linked, err := idx.scanBranches(repo)
+	if err != nil {
+		return err
+	}
+	// A checkpoint that just got a commit may have gained sessions since it
+	// was indexed (stores other than the metadata branch can't tell).
+	refresh = append(refresh, linked...)
+	idx.refresh(ctx, store, refresh)
+	return nil
+}
+
+// refresh re-reads the entries for checkpointIDs from store, dropping
+// checkpoints that no longer exist.
+func (idx *CheckpointIndex) refresh(ctx context.Context, store checkpoint.CommittedStore, checkpointIDs []id.CheckpointID) {
+	readFile := storeFileReader(ctx, store)
+	done := make(map[id.CheckpointID]bool, len(checkpointIDs))
+	for _, cpID := range checkpointIDs {
+		if done[cpID] {
+			continue
+		}
+		done[cpID] = true
+		entry, err := readCheckpointIndexEntry(readFile, cpID)
+		if err != nil {
+			delete(idx.Checkpoints, cpID)
+			continue
+		}
+		idx.Checkpoints[cpID] = entry
+	}
+}
+
+// syncMetadataBranch compares the metadata branch with the tip the index
+// was built from, removes deleted checkpoints and returns the checkpoints
+// that were added or changed. Reports whether the tip moved.
+func (idx *CheckpointIndex) syncMetadataBranch(repo *git.Repository) ([]id.CheckpointID, bool, error) {
+	tip, ok := metadataBranchTip(repo)
+	if !ok {
+		// No metadata branch (yet): nothing is committed.
+		changed := len(idx.Checkpoints) > 0
+		clear(idx.Checkpoints)
+		idx.MetadataTip = ""
+		return nil, changed, nil
+	}
+	if tip.String() == idx.MetadataTip {
+		return nil, false, nil
+	}
+
+	newTree, err := commitTree(repo, tip)
+	if err != nil {
+		return nil, false, err
+	}
+	var oldTree *object.Tree
+	if idx.MetadataTip != "" {
+		// The old tip may have been garbage collected; list everything then.
+		oldTree, _ = commitTree(repo, plumbing.NewHash(idx.MetadataTip)) //nolint:errcheck // nil tree means full resync
+	}
+
+	changed, removed, err := diffCheckpointTrees(repo, oldTree, newTree)
+	if err != nil {
+		return nil, false, err
+	}
+	if oldTree == nil {
+		// Without a base to diff against, everything not listed is gone.
+		removed = removed[:0]
+		listed := make(map[id.CheckpointID]bool, len(changed))
+		for _, cpID := range changed {
+			listed[cpID] = true
+		}
+		for cpID := range idx.Checkpoints {
+			if !listed[cpID] {
+				removed = append(removed, cpID)
+			}
+		}
+	}
+	for _, cpID := range removed {
+		delete(idx.Checkpoints, cpID)
+	}
+	idx.MetadataTip = tip.String()
+	return changed, true, nil
+}
+
+// syncListed lists the checkpoints in store, removes deleted checkpoints and
+// returns the checkpoints not yet indexed. Used for stores without a
+// metadata branch to diff.
+func (idx *CheckpointIndex) syncListed(ctx context.Context, store checkpoint.CommittedStore) ([]id.CheckpointID, error) {
+	infos, err := store.ListCommitted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list committed checkpoints: %w", err)
+	}
+	listed := make(map[id.CheckpointID]bool, len(infos))
+	var added []id.CheckpointID
+	for _, info := range infos {
+		listed[info.CheckpointID] = true
+		if _, ok := idx.Checkpoints[info.CheckpointID]; !ok {
+			added = append(added, info.CheckpointID)
+		}
+	}
+	for cpID := range idx.Checkpoints {
+		if !listed[cpID] {
+			delete(idx.Checkpoints, cpID)
+		}
+	}
+	return added, nil
+}
+
+// scanBranches scans the history of local branches for Entire-Checkpoint
+// trailers and notes, stopping at commits scanned before. Returns the
+// checkpoints that were linked to new commits.
+func (idx *CheckpointIndex) scanBranches(repo *git.Repository) ([]id.CheckpointID, error) {
+	notes := LoadCheckpointNotes(repo)
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+	tips := make(map[string]plumbing.Hash)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		// Shadow branches and the metadata branch carry no trailers.
+		if name.IsBranch() && !strings.HasPrefix(name.Short(), shadowBranchPrefix) {
+			tips[name.Short()] = ref.Hash()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	// Everything reachable from a previously scanned tip has been seen.
+	seen := make(map[plumbing.Hash]bool)
+	for _, hash := range idx.BranchTips {
+		seen[plumbing.NewHash(hash)] = true
+	}
+
+	var linked []id.CheckpointID
+	for _, name := range sortedKeys(tips) {
+		queue := []plumbing.Hash{tips[name]}
+		for len(queue) > 0 {
+			hash := queue[0]
+			queue = queue[1:]
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+			commit, err := repo.CommitObject(hash)
+			if err != nil {
+				continue // Shallow clones lack older commits
+			}
+			linked = append(linked, idx.link(hash.String(), notes.ParseAllCheckpoints(commit))...)
+			queue = append(queue, commit.ParentHashes...)
+		}
+	}
+
+	clear(idx.BranchTips)
+	for name, hash := range tips {
+		idx.BranchTips[name] = hash.String()
+	}
+
+	noteLinked, err := idx.scanNotes(notes)
+	if err != nil {
+		return nil, err
+	}
+	return append(linked, noteLinked...), nil
+}
+
+// scanNotes links commits scanned before that have since gained a checkpoint
+// note, e.g. from fetching refs/notes/entire, if a local branch reaches them.
+// Returns the checkpoints that were linked to commits.
+func (idx *CheckpointIndex) scanNotes(notes *CheckpointNotes) ([]id.CheckpointID, error) {
+	tip := ""
+	if !notes.Tip().IsZero() {
+		tip = notes.Tip().String()
+	}
+	if tip == idx.NotesTip {
+		return nil, nil
+	}
+
+	var unlinked []string
+	for hash, cpIDs := range notes.Annotated() {
+		for _, cpID := range cpIDs {
+			if !slices.Contains(idx.Commits[cpID], hash.String()) {
+				unlinked = append(unlinked, hash.String())
+				break
+			}
+		}
+	}
+	unreachable, err := unreachableCommits(context.Background(), unlinked)
+	if err != nil {
+		return nil, err
+	}
+
+	var linked []id.CheckpointID
+	for _, hash := range unlinked {
+		if !unreachable[hash] {
+			linked = append(linked, idx.link(hash, notes.Annotated()[plumbing.NewHash(hash)])...)
+		}
+	}
+	idx.NotesTip = tip
+	return linked, nil
+}
+
+// link records that commit hash references the given checkpoints. Returns
+// the checkpoints it wasn't linked to before.
+func (idx *CheckpointIndex) link(hash string, cpIDs []id.CheckpointID) []id.CheckpointID {
+	var linked []id.CheckpointID
+	for _, cpID := range cpIDs {
+		if !slices.Contains(idx.Commits[cpID], hash) {
+			idx.Commits[cpID] = append(idx.Commits[cpID], hash)
+			linked = append(linked, cpID)
+		}
+	}
+	return linked
+}
+
+// unreachableCommits returns which of the given commits no local branch
+// reaches, along with their unreachable ancestors.
+func unreachableCommits(ctx context.Context, hashes []string) (map[string]bool, error) {
+	unreachable := make(map[string]bool)
+	for batch := range slices.Chunk(hashes, 500) {
+		args := append([]string{"rev-list"}, batch...)
+		args = append(args, "--not", "--exclude="+shadowBranchPrefix+"*", "--branches", "--")
+		output, err := exec.CommandContext(ctx, "git", args...).Output() //nolint:gosec // Arguments are commit hashes
+		if err != nil {
+			return nil, fmt.Errorf("failed to check commit reachability: %w", err)
+		}
+		for _, hash := range strings.Fields(string(output)) {
+			unreachable[hash] = true
+		}
+	}
+	return unreachable, nil
+}
+
+// readCheckpointIndexEntry reads the index entry for a checkpoint: the root
+// summary plus every session's metadata. Like ListCommitted, the session
+// fields describe the latest session.
+func readCheckpointIndexEntry(readFile checkpointFileReader, checkpointID id.CheckpointID) (*CheckpointIndexEntry, error) {
+	content, err := readFile(checkpointID.Path() + "/" + paths.MetadataFileName)
+	if err != nil {
+		return nil, err
+	}
+	var summary checkpoint.CheckpointSummary
+	if err := json.Unmarshal(content, &summary); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint summary: %w", err)
+	}
+
+	entry := &CheckpointIndexEntry{
+		CheckpointID:     checkpointID,
+		Branch:           summary.Branch,
+		FilesTouched:     summary.FilesTouched,
+		CheckpointsCount: summary.CheckpointsCount,
+		TokenUsage:       summary.TokenUsage,
+	}
+	for _, sessionPaths := range summary.Sessions {
+		if sessionPaths.Metadata == "" {
+			continue
+		}
+		sessionContent, err := readFile(strings.TrimPrefix(sessionPaths.Metadata, "/"))
+		if err != nil {
+			continue
+		}
+		var meta checkpoint.CommittedMetadata
+		if json.Unmarshal(sessionContent, &meta) != nil {
+			continue
+		}
+		entry.SessionIDs = append(entry.SessionIDs, meta.SessionID)
+		entry.SessionID = meta.SessionID
+		entry.Agent = meta.Agent
+		entry.CreatedAt = meta.CreatedAt
+		entry.IsTask = meta.IsTask
+		entry.ToolUseID = meta.ToolUseID
+	}
+	return entry, nil
+}
+
+// metadataBranchTip returns the entire/checkpoints/v1 commit, falling back to
+// origin's when there is no local branch.
+func metadataBranchTip(repo *git.Repository) (plumbing.Hash, bool) {
+	for _, refName := range []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(paths.MetadataBranchName),
+		plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName),
+	} {
+		if ref, err := repo.Reference(refName, true); err == nil {
+			return ref.Hash(), true
+		}
+	}
+	return plumbing.ZeroHash, false
+}
+
+func commitTree(repo *git.Repository, hash plumbing.Hash) (*object.Tree, error) {
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", hash, err)
+	}
+	return tree, nil
+}
+
+// diffCheckpointTrees compares two metadata branch trees and returns the
+// checkpoints that were added or changed, and those that were removed.
+// Unchanged shards are skipped by comparing tree hashes. A nil oldTree
+// returns every checkpoint in newTree as changed.
+func diffCheckpointTrees(repo *git.Repository, oldTree, newTree *object.Tree) (changed, removed []id.CheckpointID, err error) {
+	oldCheckpoints := make(map[id.CheckpointID]plumbing.Hash)
+	oldShards := make(map[string]plumbing.Hash)
+	if oldTree != nil {
+		oldShards = shardHashes(oldTree)
+	}
+	newShards := shardHashes(newTree)
+
+	for shard, hash := range oldShards {
+		if newShards[shard] == hash {
+			continue
+		}
+		if err := collectShardCheckpoints(repo, shard, hash, oldCheckpoints); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, shard := range sortedKeys(newShards) {
+		hash := newShards[shard]
+		if oldShards[shard] == hash {
+			continue
+		}
+		newCheckpoints := make(map[id.CheckpointID]plumbing.Hash)
+		if err := collectShardCheckpoints(repo, shard, hash, newCheckpoints); err != nil {
+			return nil, nil, err
+		}
+		for cpID, cpHash := range newCheckpoints {
+			if oldHash, ok := oldCheckpoints[cpID]; !ok || oldHash != cpHash {
+				changed = append(changed, cpID)
+			}
+			delete(oldCheckpoints, cpID)
+		}
+	}
+	for cpID := range oldCheckpoints {
+		removed = append(removed, cpID)
+	}
+	return changed, removed, nil
+}
+
+// shardHashes returns the tree hashes of the two-character shard directories.
+func shardHashes(tree *object.Tree) map[string]plumbing.Hash {
+	shards := make(map[string]plumbing.Hash)
+	for _, entry := range tree.Entries {
+		if entry.Mode == filemode.Dir && len(entry.Name) == 2 {
+			shards[entry.Name] = entry.Hash
+		}
+	}
+	return shards
+}
+
+// collectShardCheckpoints adds the checkpoint directories of a shard to out.
+func collectShardCheckpoints(repo *git.Repository, shard string, hash plumbing.Hash, out map[id.CheckpointID]plumbing.Hash) error {
+	tree, err := repo.TreeObject(hash)
+	if err != nil {
+		return fmt.Errorf("failed to read shard %s: %w", shard, err)
+	}
+	for _, entry := range tree.Entries {
+		if entry.Mode != filemode.Dir {
+			continue
+		}
+		cpID, err := id.NewCheckpointID(shard + entry.Name)
+		if err != nil {
+			continue
+		}
+		out[cpID] = entry.Hash
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,166 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func writeIndexTestCheckpoint(t *testing.T, store checkpoint.CommittedStore, cpID id.CheckpointID, sessionID string) {
+	t.Helper()
+	if err := store.WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID:     cpID,
+		SessionID:        sessionID,
+		Strategy:         "manual-commit",
+		Branch:           "master",
+		Transcript:       []byte(`{"type":"user","message":{"content":"hello"}}` + "\n"),
+		FilesTouched:     []string{"main.go"},
+		CheckpointsCount: 1,
+		AuthorName:       "Test",
+		AuthorEmail:      "test@example.com",
+	}); err != nil {
+		t.Fatalf("WriteCommitted(%s) error = %v", cpID, err)
+	}
+}
+
+// commitLinkedToCheckpoint commits a file change whose message links cpID.
+func commitLinkedToCheckpoint(t *testing.T, repo *git.Repository, dir string, cpID id.CheckpointID) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main // "+cpID.String()+"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := wt.Add("main.go"); err != nil {
+		t.Fatalf("failed to add: %v", err)
+	}
+	hash, err := wt.Commit(trailers.FormatCheckpoint("Update main.go", cpID), &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	return hash.String()
+}
+
+func TestUpdateCheckpointIndex_NoIndex(t *testing.T) {
+	repo, _ := initRepoWithSettings(t, "")
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	writeIndexTestCheckpoint(t, store, id.MustCheckpointID("1d0000000001"), "session-a")
+
+	if err := UpdateCheckpointIndex(context.Background()); err != nil {
+		t.Fatalf("UpdateCheckpointIndex() error = %v", err)
+	}
+	idx, err := LoadCheckpointIndex()
+	if err != nil || idx != nil {
+		t.Errorf("LoadCheckpointIndex() = %v, %v; want no index to be created", idx, err)
+	}
+
+	infos, err := ListCommittedCheckpoints(context.Background(), repo, store)
+	if err != nil || len(infos) != 1 {
+		t.Errorf("ListCommittedCheckpoints() = %+v, %v; want the checkpoint listed from the store", infos, err)
+	}
+}
+
+func TestCheckpointIndex_RebuildAndUpdate(t *testing.T) {
+	repo, dir := initRepoWithSettings(t, "")
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	cpA := id.MustCheckpointID("1d1000000001")
+	cpB := id.MustCheckpointID("1d1000000002")
+
+	writeIndexTestCheckpoint(t, store, cpA, "session-a")
+	commitA := commitLinkedToCheckpoint(t, repo, dir, cpA)
+
+	idx, err := RebuildCheckpointIndex(context.Background(), repo, store)
+	if err != nil {
+		t.Fatalf("RebuildCheckpointIndex() error = %v", err)
+	}
+	entry := idx.Checkpoints[cpA]
+	if entry == nil || entry.SessionID != "session-a" || entry.Branch != "master" {
+		t.Fatalf("index entry for %s = %+v, want session-a on master", cpA, entry)
+	}
+	if got := idx.CommitsFor(cpA); !slices.Equal(got, []string{commitA}) {
+		t.Errorf("CommitsFor(%s) = %v, want [%s]", cpA, got, commitA)
+	}
+
+	// A checkpoint written to the metadata branch after the rebuild is picked
+	// up when listing, even without an explicit update.
+	writeIndexTestCheckpoint(t, store, cpB, "session-b")
+	infos, err := ListCommittedCheckpoints(context.Background(), repo, store)
+	if err != nil {
+		t.Fatalf("ListCommittedCheckpoints() error = %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("ListCommittedCheckpoints() = %d checkpoints, want 2", len(infos))
+	}
+
+	commitB := commitLinkedToCheckpoint(t, repo, dir, cpB)
+	if err := store.DeleteCommitted(context.Background(), []id.CheckpointID{cpA}); err != nil {
+		t.Fatalf("DeleteCommitted() error = %v", err)
+	}
+	if err := UpdateCheckpointIndex(context.Background()); err != nil {
+		t.Fatalf("UpdateCheckpointIndex() error = %v", err)
+	}
+
+	idx, err = LoadCheckpointIndex()
+	if err != nil || idx == nil {
+		t.Fatalf("LoadCheckpointIndex() = %v, %v; want the index", idx, err)
+	}
+	if _, ok := idx.Checkpoints[cpA]; ok {
+		t.Errorf("index still has deleted checkpoint %s", cpA)
+	}
+	if _, ok := idx.Checkpoints[cpB]; !ok {
+		t.Errorf("index is missing checkpoint %s", cpB)
+	}
+	if got := idx.CommitsFor(cpB); !slices.Equal(got, []string{commitB}) {
+		t.Errorf("CommitsFor(%s) = %v, want [%s]", cpB, got, commitB)
+	}
+}
+
+func TestCheckpointIndex_FilesystemStore(t *testing.T) {
+	repo, _ := initRepoWithSettings(t, `{"checkpoint_store": {"type": "filesystem", "path": "../checkpoints"}}`)
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	cpA := id.MustCheckpointID("1d2000000001")
+	cpB := id.MustCheckpointID("1d2000000002")
+	writeIndexTestCheckpoint(t, store, cpA, "session-a")
+
+	if _, err := RebuildCheckpointIndex(context.Background(), repo, store); err != nil {
+		t.Fatalf("RebuildCheckpointIndex() error = %v", err)
+	}
+
+	writeIndexTestCheckpoint(t, store, cpB, "session-b")
+	if err := store.DeleteCommitted(context.Background(), []id.CheckpointID{cpA}); err != nil {
+		t.Fatalf("DeleteCommitted() error = %v", err)
+	}
+	if err := UpdateCheckpointIndex(context.Background()); err != nil {
+		t.Fatalf("UpdateCheckpointIndex() error = %v", err)
+	}
+
+	infos, err := ListCommittedCheckpoints(context.Background(), repo, store)
+	if err != nil {
+		t.Fatalf("ListCommittedCheckpoints() error = %v", err)
+	}
+	if len(infos) != 1 || infos[0].CheckpointID != cpB {
+		t.Errorf("ListCommittedCheckpoints() = %+v, want only %s", infos, cpB)
+	}
+}
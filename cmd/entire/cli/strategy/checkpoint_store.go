@@ -0,0 +1,155 @@
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"filippo.io/age"
+	"github.com/go-git/go-git/v5"
+)
+
+// NewCheckpointStore returns the checkpoint store selected by the
+// checkpoint_store setting. Committed checkpoints go to the
+// entire/checkpoints/v1 branch by default, or to a plain directory when the
+// type is "filesystem". Temporary checkpoints always live on shadow branches.
+func NewCheckpointStore(repo *git.Repository) (checkpoint.Store, error) {
+	s, err := settings.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	level, err := checkpointCompressionLevel(s.CheckpointStore)
+	if err != nil {
+		return nil, err
+	}
+	delta := s.CheckpointStore != nil && s.CheckpointStore.DeltaTranscripts
+	enc, err := checkpointEncryption(s.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s.CheckpointStoreType() {
+	case settings.CheckpointStoreGit:
+		store := checkpoint.NewGitStore(repo)
+		store.SetCompressionLevel(level)
+		store.SetDeltaTranscripts(delta)
+		store.SetEncryption(enc)
+		// Loaded on the first metadata commit: running git config for it,
+		// and failing on a broken signing setup, would otherwise slow down
+		// and break read-only commands.
+		store.SetCommitSigner(LoadCommitSigner)
+		return store, nil
+	case settings.CheckpointStoreFilesystem:
+		dir, err := checkpointStoreDir(s.CheckpointStore.Path)
+		if err != nil {
+			return nil, err
+		}
+		store := checkpoint.NewFilesystemStore(repo, dir)
+		store.SetCompressionLevel(level)
+		store.SetDeltaTranscripts(delta)
+		store.SetEncryption(enc)
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown checkpoint store type %q (expected %q or %q)",
+			s.CheckpointStoreType(), settings.CheckpointStoreGit, settings.CheckpointStoreFilesystem)
+	}
+}
+
+// checkpointCompressionLevel returns the configured transcript compression
+// level, defaulting to checkpoint.DefaultCompressionLevel.
+func checkpointCompressionLevel(cfg *settings.CheckpointStoreSettings) (int, error) {
+	if cfg == nil || cfg.CompressionLevel == nil {
+		return checkpoint.DefaultCompressionLevel, nil
+	}
+	level := *cfg.CompressionLevel
+	if level < 0 || level > checkpoint.MaxCompressionLevel {
+		return 0, fmt.Errorf("checkpoint_store.compression_level must be between 0 and %d, got %d", checkpoint.MaxCompressionLevel, level)
+	}
+	return level, nil
+}
+
+// checkpointEncryption returns the configured checkpoint encryption, or nil if
+// none is configured. Recipients are parsed eagerly so typos are reported on
+// every command; the identity file is only read when encrypted content is.
+func checkpointEncryption(cfg *settings.EncryptionSettings) (*checkpoint.Encryption, error) {
+	if cfg == nil {
+		return nil, nil //nolint:nilnil // No encryption configured is not an error
+	}
+	enc := &checkpoint.Encryption{}
+	for _, r := range cfg.Recipients {
+		recipient, err := age.ParseX25519Recipient(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption recipient %q: %w", r, err)
+		}
+		enc.Recipients = append(enc.Recipients, recipient)
+	}
+	identityFile := cfg.IdentityFile
+	enc.Identities = func() ([]age.Identity, error) {
+		return loadIdentities(identityFile)
+	}
+	return enc, nil
+}
+
+// loadIdentities reads age identities from path, expanding a leading "~/"
+// and resolving relative paths against the repository root.
+func loadIdentities(path string) ([]age.Identity, error) {
+	if path == "" {
+		return nil, errors.New("no identity file configured: set encryption.identity_file in " + settings.EntireSettingsLocalFile)
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve identity file: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	} else if !filepath.IsAbs(path) {
+		repoRoot, err := GetMainRepoRoot()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve identity file: %w", err)
+		}
+		path = filepath.Join(repoRoot, path)
+	}
+	f, err := os.Open(path) //nolint:gosec // path comes from the user's own settings
+	if err != nil {
+		return nil, fmt.Errorf("failed to open identity file: %w", err)
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity file %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// checkpointStoreDir resolves the filesystem store path. Relative paths are
+// resolved against the main repository root so that worktrees share one store.
+func checkpointStoreDir(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("checkpoint_store.path is required for the %q store", settings.CheckpointStoreFilesystem)
+	}
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	repoRoot, err := GetMainRepoRoot()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve checkpoint store path: %w", err)
+	}
+	return filepath.Join(repoRoot, path), nil
+}
+
+// usesMetadataBranch reports whether committed checkpoints are kept on the
+// entire/checkpoints/v1 branch. When they are not, the branch is neither
+// created nor pushed.
+func usesMetadataBranch() bool {
+	s, err := settings.Load()
+	if err != nil {
+		return true
+	}
+	return s.CheckpointStoreType() == settings.CheckpointStoreGit
+}
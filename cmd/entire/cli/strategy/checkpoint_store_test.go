@@ -0,0 +1,142 @@
+package strategy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"filippo.io/age"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// initRepoWithSettings creates a repository with the given .entire/settings.json
+// content and changes into it.
+func initRepoWithSettings(t *testing.T, settingsJSON string) (*git.Repository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	// Resolve symlinks so paths compare equal to git rev-parse output on macOS.
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatalf("failed to resolve temp dir: %v", err)
+	}
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+	if settingsJSON != "" {
+		if err := os.MkdirAll(filepath.Join(dir, ".entire"), 0o755); err != nil {
+			t.Fatalf("failed to create .entire: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ".entire", "settings.json"), []byte(settingsJSON), 0o644); err != nil {
+			t.Fatalf("failed to write settings: %v", err)
+		}
+	}
+	t.Chdir(dir)
+	paths.ClearRepoRootCache()
+	return repo, dir
+}
+
+// initRepoWithCommit creates a repository like initRepoWithSettings, with a
+// git identity configured and one commit.
+func initRepoWithCommit(t *testing.T, settingsJSON string) (*git.Repository, string) {
+	t.Helper()
+	repo, dir := initRepoWithSettings(t, settingsJSON)
+	runGit(t, "config", "user.name", "Test")
+	runGit(t, "config", "user.email", "test@example.com")
+	writeRepoFile(t, dir, "README.md", "# Test\n")
+	runGit(t, "add", "README.md")
+	runGit(t, "commit", "-q", "-m", "Initial commit")
+	return repo, dir
+}
+
+func TestNewCheckpointStore_DefaultsToGit(t *testing.T) {
+	repo, _ := initRepoWithSettings(t, "")
+
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	if _, ok := store.(*checkpoint.GitStore); !ok {
+		t.Errorf("NewCheckpointStore() = %T, want *checkpoint.GitStore", store)
+	}
+	if !usesMetadataBranch() {
+		t.Error("usesMetadataBranch() = false, want true for the default store")
+	}
+}
+
+func TestNewCheckpointStore_Filesystem(t *testing.T) {
+	repo, dir := initRepoWithSettings(t, `{"checkpoint_store": {"type": "filesystem", "path": "../shared-checkpoints"}}`)
+
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	fsStore, ok := store.(*checkpoint.FilesystemStore)
+	if !ok {
+		t.Fatalf("NewCheckpointStore() = %T, want *checkpoint.FilesystemStore", store)
+	}
+	if want := filepath.Join(filepath.Dir(dir), "shared-checkpoints"); fsStore.Dir() != want {
+		t.Errorf("Dir() = %q, want %q", fsStore.Dir(), want)
+	}
+
+	// The metadata branch is neither created nor pushed.
+	if usesMetadataBranch() {
+		t.Error("usesMetadataBranch() = true, want false for the filesystem store")
+	}
+	if err := EnsureMetadataBranch(repo); err != nil {
+		t.Fatalf("EnsureMetadataBranch() error = %v", err)
+	}
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true); err == nil {
+		t.Errorf("%s should not be created for the filesystem store", paths.MetadataBranchName)
+	}
+}
+
+func TestNewCheckpointStore_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+	}{
+		{"unknown type", `{"checkpoint_store": {"type": "s3"}}`},
+		{"filesystem without path", `{"checkpoint_store": {"type": "filesystem"}}`},
+		{"compression level too high", `{"checkpoint_store": {"compression_level": 23}}`},
+		{"negative compression level", `{"checkpoint_store": {"compression_level": -1}}`},
+		{"invalid encryption recipient", `{"encryption": {"recipients": ["not-a-key"]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, _ := initRepoWithSettings(t, tt.settings)
+			if _, err := NewCheckpointStore(repo); err == nil {
+				t.Error("NewCheckpointStore() should fail")
+			}
+		})
+	}
+}
+
+func TestLoadIdentities(t *testing.T) {
+	_, dir := initRepoWithSettings(t, "")
+
+	if _, err := loadIdentities(""); err == nil || !strings.Contains(err.Error(), "encryption.identity_file") {
+		t.Errorf("loadIdentities(\"\") error = %v, want a hint to set encryption.identity_file", err)
+	}
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "key.txt"), []byte(identity.String()+"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write identity file: %v", err)
+	}
+	// Relative paths resolve against the repository root.
+	identities, err := loadIdentities("key.txt")
+	if err != nil {
+		t.Fatalf("loadIdentities() error = %v", err)
+	}
+	if len(identities) != 1 {
+		t.Errorf("loadIdentities() returned %d identities, want 1", len(identities))
+	}
+}
@@ -15,7 +15,6 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/session"
 
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
 const (
@@ -164,7 +163,10 @@ func ListOrphanedSessionStates() ([]CleanupItem, error) {
 	}
 
 	// Get all checkpoints to find which sessions have checkpoints
-	cpStore := checkpoint.NewGitStore(repo)
+	cpStore, err := NewCheckpointStore(repo)
+	if err != nil {
+		return nil, err
+	}
 
 	sessionsWithCheckpoints := make(map[string]bool)
 	checkpoints, listErr := cpStore.ListCommitted(context.Background())
@@ -235,7 +237,7 @@ func DeleteOrphanedSessionStates(sessionIDs []string) (deleted []string, failed
 	return deleted, failed, nil
 }
 
-// DeleteOrphanedCheckpoints removes checkpoint directories from the entire/checkpoints/v1 branch.
+// DeleteOrphanedCheckpoints removes checkpoint directories from the checkpoint store.
 func DeleteOrphanedCheckpoints(checkpointIDs []string) (deleted []string, failed []string, err error) {
 	if len(checkpointIDs) == 0 {
 		return []string{}, []string{}, nil
@@ -245,88 +247,24 @@ func DeleteOrphanedCheckpoints(checkpointIDs []string) (deleted []string, failed
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to open git repository: %w", err)
 	}
-
-	// Get sessions branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := repo.Reference(refName, true)
-	if err != nil {
-		return nil, nil, fmt.Errorf("sessions branch not found: %w", err)
-	}
-
-	parentCommit, err := repo.CommitObject(ref.Hash())
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get commit: %w", err)
-	}
-
-	baseTree, err := parentCommit.Tree()
+	store, err := NewCheckpointStore(repo)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get tree: %w", err)
-	}
-
-	// Flatten tree to entries
-	entries := make(map[string]object.TreeEntry)
-	if err := checkpoint.FlattenTree(repo, baseTree, "", entries); err != nil {
-		return nil, nil, fmt.Errorf("failed to flatten tree: %w", err)
+		return nil, nil, err
 	}
 
-	// Remove entries for each checkpoint
-	checkpointSet := make(map[string]bool)
-	for _, id := range checkpointIDs {
-		checkpointSet[id] = true
-	}
-
-	// Find and remove entries matching checkpoint paths
-	for path := range entries {
-		for checkpointIDStr := range checkpointSet {
-			cpID, err := id.NewCheckpointID(checkpointIDStr)
-			if err != nil {
-				continue // Skip invalid checkpoint IDs
-			}
-			cpPath := cpID.Path()
-			if strings.HasPrefix(path, cpPath+"/") {
-				delete(entries, path)
-			}
+	ids := make([]id.CheckpointID, 0, len(checkpointIDs))
+	for _, checkpointIDStr := range checkpointIDs {
+		cpID, err := id.NewCheckpointID(checkpointIDStr)
+		if err != nil {
+			continue // Skip invalid checkpoint IDs
 		}
+		ids = append(ids, cpID)
 	}
 
-	// Build new tree
-	newTreeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to build tree: %w", err)
-	}
-
-	// Create commit
-	commit := &object.Commit{
-		Author: object.Signature{
-			Name:  "Entire CLI",
-			Email: "cli@entire.io",
-			When:  parentCommit.Author.When,
-		},
-		Committer: object.Signature{
-			Name:  "Entire CLI",
-			Email: "cli@entire.io",
-			When:  parentCommit.Committer.When,
-		},
-		Message:      fmt.Sprintf("Cleanup: removed %d orphaned checkpoints", len(checkpointIDs)),
-		TreeHash:     newTreeHash,
-		ParentHashes: []plumbing.Hash{ref.Hash()},
-	}
-
-	obj := repo.Storer.NewEncodedObject()
-	if err := commit.Encode(obj); err != nil {
-		return nil, nil, fmt.Errorf("failed to encode commit: %w", err)
-	}
-
-	commitHash, err := repo.Storer.SetEncodedObject(obj)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to store commit: %w", err)
-	}
-
-	// Update branch reference
-	newRef := plumbing.NewHashReference(refName, commitHash)
-	if err := repo.Storer.SetReference(newRef); err != nil {
-		return nil, nil, fmt.Errorf("failed to update branch: %w", err)
+	if err := store.DeleteCommitted(context.Background(), ids); err != nil {
+		return nil, nil, fmt.Errorf("failed to delete checkpoints: %w", err)
 	}
+	TryUpdateCheckpointIndex(context.Background())
 
 	// All checkpoints deleted successfully
 	return checkpointIDs, []string{}, nil
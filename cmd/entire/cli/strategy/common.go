@@ -15,13 +15,12 @@ import (
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
-	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/ignore"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -77,112 +76,33 @@ func IsAncestorOf(repo *git.Repository, commit, target plumbing.Hash) bool {
 	return found
 }
 
-// ListCheckpoints returns all checkpoints from the entire/checkpoints/v1 branch.
-// Scans sharded paths: <id[:2]>/<id[2:]>/ directories containing metadata.json.
+// ListCheckpoints returns all committed checkpoints from the configured checkpoint store.
 // Used by both manual-commit and auto-commit strategies.
 func ListCheckpoints() ([]CheckpointInfo, error) {
 	repo, err := OpenRepository()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open git repository: %w", err)
 	}
-
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := repo.Reference(refName, true)
-	if err != nil {
-		//nolint:nilerr // No sessions branch yet is expected, return empty list
-		return []CheckpointInfo{}, nil
-	}
-
-	commit, err := repo.CommitObject(ref.Hash())
+	store, err := NewCheckpointStore(repo)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit object: %w", err)
+		return nil, err
 	}
 
-	tree, err := commit.Tree()
+	ctx := context.Background()
+	committed, err := store.ListCommitted(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+		return nil, fmt.Errorf("failed to list committed checkpoints: %w", err)
 	}
 
-	var checkpoints []CheckpointInfo
-
-	// Scan sharded structure: <2-char-prefix>/<remaining-id>/metadata.json
-	// The tree has 2-character directories (hex buckets)
-	for _, bucketEntry := range tree.Entries {
-		if bucketEntry.Mode != filemode.Dir {
-			continue
-		}
-		// Bucket should be 2 hex chars
-		if len(bucketEntry.Name) != 2 {
-			continue
-		}
-
-		bucketTree, treeErr := repo.TreeObject(bucketEntry.Hash)
-		if treeErr != nil {
+	checkpoints := make([]CheckpointInfo, 0, len(committed))
+	for _, c := range committed {
+		info, err := ReadCommittedCheckpointMetadata(ctx, store, c.CheckpointID.Path())
+		if err != nil {
+			checkpoints = append(checkpoints, CheckpointInfo{CheckpointID: c.CheckpointID})
 			continue
 		}
-
-		// Each entry in the bucket is the remaining part of the checkpoint ID
-		for _, checkpointEntry := range bucketTree.Entries {
-			if checkpointEntry.Mode != filemode.Dir {
-				continue
-			}
-
-			checkpointTree, cpTreeErr := repo.TreeObject(checkpointEntry.Hash)
-			if cpTreeErr != nil {
-				continue
-			}
-
-			// Reconstruct checkpoint ID: <bucket><remaining>
-			checkpointIDStr := bucketEntry.Name + checkpointEntry.Name
-			checkpointID, cpErr := id.NewCheckpointID(checkpointIDStr)
-			if cpErr != nil {
-				// Skip invalid checkpoint IDs
-				continue
-			}
-
-			info := CheckpointInfo{
-				CheckpointID: checkpointID,
-			}
-
-			// Get details from metadata file (CheckpointSummary format)
-			if metadataFile, fileErr := checkpointTree.File(paths.MetadataFileName); fileErr == nil {
-				if content, contentErr := metadataFile.Contents(); contentErr == nil {
-					var summary checkpoint.CheckpointSummary
-					if json.Unmarshal([]byte(content), &summary) == nil && len(summary.Sessions) > 0 {
-						info.CheckpointsCount = summary.CheckpointsCount
-						info.FilesTouched = summary.FilesTouched
-						info.SessionCount = len(summary.Sessions)
-
-						// Read session-level metadata for Agent, SessionID, CreatedAt, SessionIDs
-						for i, sessionPaths := range summary.Sessions {
-							if sessionPaths.Metadata != "" {
-								// SessionFilePaths now contains absolute paths with leading "/"
-								// Strip the leading "/" for tree.File() which expects paths without leading slash
-								sessionMetadataPath := strings.TrimPrefix(sessionPaths.Metadata, "/")
-								if sessionFile, sErr := tree.File(sessionMetadataPath); sErr == nil {
-									if sessionContent, scErr := sessionFile.Contents(); scErr == nil {
-										var sessionMetadata checkpoint.CommittedMetadata
-										if json.Unmarshal([]byte(sessionContent), &sessionMetadata) == nil {
-											info.SessionIDs = append(info.SessionIDs, sessionMetadata.SessionID)
-											// Use first session's metadata for Agent, SessionID, CreatedAt
-											if i == 0 {
-												info.Agent = sessionMetadata.Agent
-												info.SessionID = sessionMetadata.SessionID
-												info.CreatedAt = sessionMetadata.CreatedAt
-												info.IsTask = sessionMetadata.IsTask
-												info.ToolUseID = sessionMetadata.ToolUseID
-											}
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}
-
-			checkpoints = append(checkpoints, info)
-		}
+		info.CheckpointID = c.CheckpointID
+		checkpoints = append(checkpoints, *info)
 	}
 
 	// Sort by time (most recent first)
@@ -275,8 +195,13 @@ func resolveAgentType(ctxAgentType agent.AgentType, state *SessionState) agent.A
 }
 
 // ensureMetadataBranch creates the orphan entire/checkpoints/v1 branch if it doesn't exist.
-// This branch has no parent and starts with an empty tree.
+// This branch has no parent and starts with an empty tree. It does nothing when
+// committed checkpoints are kept outside the repository (see NewCheckpointStore).
 func EnsureMetadataBranch(repo *git.Repository) error {
+	if !usesMetadataBranch() {
+		return nil
+	}
+
 	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
 
 	// Check if branch already exists
@@ -313,6 +238,9 @@ func EnsureMetadataBranch(repo *git.Repository) error {
 		Message:   "Initialize metadata branch\n\nThis branch stores session metadata for the auto-commit strategy.\n",
 	}
 	// Note: No ParentHashes - this is an orphan commit
+	if err := signMetadataCommit(context.Background(), commit); err != nil {
+		return err
+	}
 
 	commitObj := repo.Storer.NewEncodedObject()
 	if err := commit.Encode(commitObj); err != nil {
@@ -333,25 +261,61 @@ func EnsureMetadataBranch(repo *git.Repository) error {
 	return nil
 }
 
-// readCheckpointMetadata reads metadata.json from a checkpoint path on entire/checkpoints/v1.
+// checkpointFileReader reads a file by its slash-separated path relative to
+// the root of committed checkpoint storage.
+type checkpointFileReader func(path string) ([]byte, error)
+
+// treeFileReader reads checkpoint files from a metadata branch tree.
+func treeFileReader(tree *object.Tree) checkpointFileReader {
+	return func(path string) ([]byte, error) {
+		file, err := tree.File(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s: %w", path, err)
+		}
+		content, err := file.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return []byte(content), nil
+	}
+}
+
+// storeFileReader reads checkpoint files from a committed checkpoint store.
+func storeFileReader(ctx context.Context, store checkpoint.CommittedStore) checkpointFileReader {
+	return func(path string) ([]byte, error) {
+		data, err := store.ReadCommittedFile(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		return data, nil
+	}
+}
+
+// ReadCheckpointMetadata reads metadata.json from a checkpoint path in a metadata branch tree.
+// Use it for trees that are not behind a store, such as origin/entire/checkpoints/v1.
+func ReadCheckpointMetadata(tree *object.Tree, checkpointPath string) (*CheckpointInfo, error) {
+	return readCheckpointMetadata(treeFileReader(tree), checkpointPath)
+}
+
+// ReadCommittedCheckpointMetadata reads metadata.json from a checkpoint path in a committed checkpoint store.
+func ReadCommittedCheckpointMetadata(ctx context.Context, store checkpoint.CommittedStore, checkpointPath string) (*CheckpointInfo, error) {
+	return readCheckpointMetadata(storeFileReader(ctx, store), checkpointPath)
+}
+
+// readCheckpointMetadata reads metadata.json from a checkpoint path.
 // With the new format, root metadata.json is a CheckpointSummary with Agents array.
 // This function reads the summary and extracts relevant fields into CheckpointInfo,
 // also reading session-level metadata for IsTask/ToolUseID fields.
-func ReadCheckpointMetadata(tree *object.Tree, checkpointPath string) (*CheckpointInfo, error) {
+func readCheckpointMetadata(readFile checkpointFileReader, checkpointPath string) (*CheckpointInfo, error) {
 	metadataPath := checkpointPath + "/metadata.json"
-	file, err := tree.File(metadataPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find metadata at %s: %w", metadataPath, err)
-	}
-
-	content, err := file.Contents()
+	content, err := readFile(metadataPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read metadata: %w", err)
+		return nil, fmt.Errorf("failed to read metadata at %s: %w", metadataPath, err)
 	}
 
 	// Try to parse as CheckpointSummary first (new format)
 	var summary checkpoint.CheckpointSummary
-	if err := json.Unmarshal([]byte(content), &summary); err == nil {
+	if err := json.Unmarshal(content, &summary); err == nil {
 		// If we have sessions array, this is the new format
 		if len(summary.Sessions) > 0 {
 			info := &CheckpointInfo{
@@ -366,21 +330,19 @@ func ReadCheckpointMetadata(tree *object.Tree, checkpointPath string) (*Checkpoi
 			for i, sessionPaths := range summary.Sessions {
 				if sessionPaths.Metadata != "" {
 					// SessionFilePaths now contains absolute paths with leading "/"
-					// Strip the leading "/" for tree.File() which expects paths without leading slash
+					// Strip the leading "/" since file paths are relative to the storage root
 					sessionMetadataPath := strings.TrimPrefix(sessionPaths.Metadata, "/")
-					if sessionFile, err := tree.File(sessionMetadataPath); err == nil {
-						if sessionContent, err := sessionFile.Contents(); err == nil {
-							var sessionMetadata checkpoint.CommittedMetadata
-							if json.Unmarshal([]byte(sessionContent), &sessionMetadata) == nil {
-								sessionIDs = append(sessionIDs, sessionMetadata.SessionID)
-								// Use first session for Agent, SessionID, CreatedAt, IsTask, ToolUseID
-								if i == 0 {
-									info.Agent = sessionMetadata.Agent
-									info.SessionID = sessionMetadata.SessionID
-									info.CreatedAt = sessionMetadata.CreatedAt
-									info.IsTask = sessionMetadata.IsTask
-									info.ToolUseID = sessionMetadata.ToolUseID
-								}
+					if sessionContent, err := readFile(sessionMetadataPath); err == nil {
+						var sessionMetadata checkpoint.CommittedMetadata
+						if json.Unmarshal(sessionContent, &sessionMetadata) == nil {
+							sessionIDs = append(sessionIDs, sessionMetadata.SessionID)
+							// Use first session for Agent, SessionID, CreatedAt, IsTask, ToolUseID
+							if i == 0 {
+								info.Agent = sessionMetadata.Agent
+								info.SessionID = sessionMetadata.SessionID
+								info.CreatedAt = sessionMetadata.CreatedAt
+								info.IsTask = sessionMetadata.IsTask
+								info.ToolUseID = sessionMetadata.ToolUseID
 							}
 						}
 					}
@@ -394,33 +356,13 @@ func ReadCheckpointMetadata(tree *object.Tree, checkpointPath string) (*Checkpoi
 
 	// Fall back to parsing as CheckpointInfo (old format or direct info)
 	var metadata CheckpointInfo
-	if err := json.Unmarshal([]byte(content), &metadata); err != nil {
+	if err := json.Unmarshal(content, &metadata); err != nil {
 		return nil, fmt.Errorf("failed to parse metadata: %w", err)
 	}
 
 	return &metadata, nil
 }
 
-// GetMetadataBranchTree returns the tree object for the entire/checkpoints/v1 branch.
-func GetMetadataBranchTree(repo *git.Repository) (*object.Tree, error) {
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := repo.Reference(refName, true)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get metadata branch reference: %w", err)
-	}
-
-	commit, err := repo.CommitObject(ref.Hash())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get metadata branch commit: %w", err)
-	}
-
-	tree, err := commit.Tree()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get metadata branch tree: %w", err)
-	}
-	return tree, nil
-}
-
 // ExtractFirstPrompt extracts and truncates the first meaningful prompt from prompt content.
 // Prompts are separated by "\n\n---\n\n". Skips empty prompts and separator-only content.
 // Returns empty string if no valid prompt is found.
@@ -453,23 +395,26 @@ func ExtractFirstPrompt(content string) string {
 // ReadSessionPromptFromTree reads the first meaningful prompt from a checkpoint's prompt.txt file in a git tree.
 // Returns an empty string if the prompt cannot be read.
 func ReadSessionPromptFromTree(tree *object.Tree, checkpointPath string) string {
-	promptPath := checkpointPath + "/" + paths.PromptFileName
-	file, err := tree.File(promptPath)
-	if err != nil {
-		return ""
-	}
+	return readCheckpointPrompt(treeFileReader(tree), checkpointPath)
+}
 
-	content, err := file.Contents()
+// ReadCommittedSessionPrompt reads the first meaningful prompt from a checkpoint's prompt.txt file
+// in a committed checkpoint store. Returns an empty string if the prompt cannot be read.
+func ReadCommittedSessionPrompt(ctx context.Context, store checkpoint.CommittedStore, checkpointPath string) string {
+	return readCheckpointPrompt(storeFileReader(ctx, store), checkpointPath)
+}
+
+func readCheckpointPrompt(readFile checkpointFileReader, checkpointPath string) string {
+	content, err := readFile(checkpointPath + "/" + paths.PromptFileName)
 	if err != nil {
 		return ""
 	}
-
-	return ExtractFirstPrompt(content)
+	return ExtractFirstPrompt(string(content))
 }
 
 // ReadAgentTypeFromTree reads the agent type from a checkpoint's metadata.json file in a git tree.
 // If metadata.json doesn't exist (shadow branches), it falls back to detecting the agent
-// from the presence of agent-specific config files (.gemini/settings.json or .claude/).
+// from the presence of agent-specific config files (.gemini/settings.json, .cursor/hooks.json, .codex/config.toml, .aider.conf.yml or .claude/).
 // Returns agent.AgentTypeUnknown if the agent type cannot be determined.
 func ReadAgentTypeFromTree(tree *object.Tree, checkpointPath string) agent.AgentType {
 	// First, try to read from metadata.json (present in condensed/committed checkpoints)
@@ -488,6 +433,18 @@ func ReadAgentTypeFromTree(tree *object.Tree, checkpointPath string) agent.Agent
 	if _, err := tree.File(".gemini/settings.json"); err == nil {
 		return agent.AgentTypeGemini
 	}
+	// Check for Cursor hooks config
+	if _, err := tree.File(".cursor/hooks.json"); err == nil {
+		return agent.AgentTypeCursor
+	}
+	// Check for Codex config
+	if _, err := tree.File(".codex/config.toml"); err == nil {
+		return agent.AgentTypeCodex
+	}
+	// Check for Aider config (its history files are usually gitignored)
+	if _, err := tree.File(".aider.conf.yml"); err == nil {
+		return agent.AgentTypeAider
+	}
 	// Check for Claude config (either settings.local.json or settings.json in .claude/)
 	if _, err := tree.Tree(".claude"); err == nil {
 		return agent.AgentTypeClaudeCode
@@ -506,13 +463,13 @@ func isOnlySeparators(s string) bool {
 	return true
 }
 
-// ReadAllSessionPromptsFromTree reads the first prompt for all sessions in a multi-session checkpoint.
+// ReadAllCommittedSessionPrompts reads the first prompt for all sessions in a multi-session checkpoint.
 // Returns a slice of prompts parallel to sessionIDs (oldest to newest).
 // For single-session checkpoints, returns a slice with just the root prompt.
-func ReadAllSessionPromptsFromTree(tree *object.Tree, checkpointPath string, sessionCount int, sessionIDs []string) []string {
+func ReadAllCommittedSessionPrompts(ctx context.Context, store checkpoint.CommittedStore, checkpointPath string, sessionCount int, sessionIDs []string) []string {
 	if sessionCount <= 1 || len(sessionIDs) <= 1 {
 		// Single session - just return the root prompt
-		prompt := ReadSessionPromptFromTree(tree, checkpointPath)
+		prompt := ReadCommittedSessionPrompt(ctx, store, checkpointPath)
 		if prompt != "" {
 			return []string{prompt}
 		}
@@ -525,11 +482,11 @@ func ReadAllSessionPromptsFromTree(tree *object.Tree, checkpointPath string, ses
 	// Read archived session prompts (folders 0, 1, ... N-2)
 	for i := range sessionCount - 1 {
 		archivedPath := fmt.Sprintf("%s/%d", checkpointPath, i)
-		prompts[i] = ReadSessionPromptFromTree(tree, archivedPath)
+		prompts[i] = ReadCommittedSessionPrompt(ctx, store, archivedPath)
 	}
 
 	// Read the most recent session prompt (at root level)
-	prompts[len(prompts)-1] = ReadSessionPromptFromTree(tree, checkpointPath)
+	prompts[len(prompts)-1] = ReadCommittedSessionPrompt(ctx, store, checkpointPath)
 
 	return prompts
 }
@@ -705,6 +662,22 @@ func GetWorktreePath() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// loadIgnoreMatcher loads the .entire/ignore exclusions of the current
+// worktree. Best-effort: if they can't be loaded, it warns and returns nil,
+// which excludes nothing.
+func loadIgnoreMatcher() *ignore.Matcher {
+	repoRoot, err := paths.RepoRoot()
+	if err != nil {
+		return nil
+	}
+	ignored, err := ignore.Load(repoRoot)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load ignore patterns: %v\n", err)
+		return nil
+	}
+	return ignored
+}
+
 // EnsureEntireGitignore ensures all required entries are in .entire/.gitignore
 // Works correctly from any subdirectory within the repository.
 func EnsureEntireGitignore() error {
@@ -726,6 +699,7 @@ func EnsureEntireGitignore() error {
 		"settings.local.json",
 		"metadata/",
 		"logs/",
+		"recordings/",
 	}
 
 	// Track what needs to be added
@@ -1378,42 +1352,19 @@ func createCommit(repo *git.Repository, treeHash, parentHash plumbing.Hash, mess
 // the session directory, e.g., auto-commit strategy's sharded metadata), then falls back to
 // searching for .entire/metadata/*/prompt.txt or context.md (for full worktree trees).
 func getSessionDescriptionFromTree(tree *object.Tree, metadataDir string) string {
-	// Helper to read first line from a file in tree
-	readFirstLine := func(path string) string {
-		file, err := tree.File(path)
-		if err != nil {
-			return ""
-		}
-		content, err := file.Contents()
-		if err != nil {
-			return ""
-		}
-		lines := strings.SplitN(content, "\n", 2)
-		if len(lines) > 0 && lines[0] != "" {
-			desc := strings.TrimSpace(lines[0])
-			// Remove markdown header prefix if present
-			return strings.TrimPrefix(desc, "# ")
-		}
-		return ""
-	}
+	readFile := treeFileReader(tree)
 
 	// If metadataDir is provided, look there directly
 	if metadataDir != "" {
-		if desc := readFirstLine(metadataDir + "/" + paths.PromptFileName); desc != "" {
-			return desc
-		}
-		if desc := readFirstLine(metadataDir + "/" + paths.ContextFileName); desc != "" {
-			return desc
-		}
-		return NoDescription
+		return readSessionDescription(readFile, metadataDir)
 	}
 
 	// No metadataDir provided - first try looking at the root of the tree
 	// (used when the tree is already the session directory)
-	if desc := readFirstLine(paths.PromptFileName); desc != "" {
+	if desc := readDescriptionLine(readFile, paths.PromptFileName); desc != "" {
 		return desc
 	}
-	if desc := readFirstLine(paths.ContextFileName); desc != "" {
+	if desc := readDescriptionLine(readFile, paths.ContextFileName); desc != "" {
 		return desc
 	}
 
@@ -1448,6 +1399,32 @@ func getSessionDescriptionFromTree(tree *object.Tree, metadataDir string) string
 	return NoDescription
 }
 
+// readSessionDescription returns the first line of prompt.txt or context.md in metadataDir.
+func readSessionDescription(readFile checkpointFileReader, metadataDir string) string {
+	if desc := readDescriptionLine(readFile, metadataDir+"/"+paths.PromptFileName); desc != "" {
+		return desc
+	}
+	if desc := readDescriptionLine(readFile, metadataDir+"/"+paths.ContextFileName); desc != "" {
+		return desc
+	}
+	return NoDescription
+}
+
+// readDescriptionLine reads the first line of a file, without any markdown header prefix.
+func readDescriptionLine(readFile checkpointFileReader, path string) string {
+	content, err := readFile(path)
+	if err != nil {
+		return ""
+	}
+	lines := strings.SplitN(string(content), "\n", 2)
+	if len(lines) > 0 && lines[0] != "" {
+		desc := strings.TrimSpace(lines[0])
+		// Remove markdown header prefix if present
+		return strings.TrimPrefix(desc, "# ")
+	}
+	return ""
+}
+
 // GetGitAuthorFromRepo retrieves the git user.name and user.email,
 // checking both the repository-local config and the global ~/.gitconfig.
 // Delegates to checkpoint.GetGitAuthorFromRepo — this wrapper exists so
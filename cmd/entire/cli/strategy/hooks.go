@@ -18,8 +18,12 @@ const entireHookMarker = "Entire CLI hooks"
 const backupSuffix = ".pre-entire"
 const chainComment = "# Chain: run pre-existing hook"
 
+// stdinVar is the shell variable hooks that read stdin save it in, so it can
+// be passed on to the pre-existing hook too.
+const stdinVar = "_entire_stdin"
+
 // gitHookNames are the git hooks managed by Entire CLI
-var gitHookNames = []string{"prepare-commit-msg", "commit-msg", "post-commit", "pre-push"}
+var gitHookNames = []string{"prepare-commit-msg", "commit-msg", "post-commit", "pre-push", "post-rewrite"}
 
 // ManagedGitHookNames returns the list of git hooks managed by Entire CLI.
 // This is useful for tests that need to manipulate hooks.
@@ -131,6 +135,16 @@ func buildHookSpecs(cmdPrefix string) []hookSpec {
 %s hooks git pre-push "$1" || true
 `, entireHookMarker, cmdPrefix),
 		},
+		{
+			name: "post-rewrite",
+			content: fmt.Sprintf(`#!/bin/sh
+# %s
+# Post-rewrite hook: keep checkpoint links across amend and rebase
+# $1 is "amend" or "rebase"; stdin has "<old-sha> <new-sha>" lines
+%s="$(cat)"
+printf '%%s\n' "$%s" | %s hooks git post-rewrite "$1" 2>/dev/null || true
+`, entireHookMarker, stdinVar, stdinVar, cmdPrefix),
+		},
 	}
 }
 
@@ -195,7 +209,7 @@ func InstallGitHook(silent bool) (int, error) {
 	}
 
 	if !silent {
-		fmt.Println("✓ Installed git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push)")
+		fmt.Println("✓ Installed git hooks (prepare-commit-msg, commit-msg, post-commit, pre-push, post-rewrite)")
 		fmt.Println("  Hooks delegate to the current strategy at runtime")
 	}
 
@@ -269,13 +283,18 @@ func RemoveGitHook() (int, error) {
 
 // generateChainedContent appends a chain call to the base hook content,
 // so the pre-existing hook (backed up to .pre-entire) is called after our hook.
+// Hooks that saved their stdin pass it on to the pre-existing hook.
 func generateChainedContent(baseContent, hookName string) string {
+	input := ""
+	if strings.Contains(baseContent, stdinVar+"=") {
+		input = fmt.Sprintf(`printf '%%s\n' "$%s" | `, stdinVar)
+	}
 	return baseContent + fmt.Sprintf(`%s
 _entire_hook_dir="$(dirname "$0")"
 if [ -x "$_entire_hook_dir/%s%s" ]; then
-    "$_entire_hook_dir/%s%s" "$@"
+    %s"$_entire_hook_dir/%s%s" "$@"
 fi
-`, chainComment, hookName, backupSuffix, hookName, backupSuffix)
+`, chainComment, hookName, backupSuffix, input, hookName, backupSuffix)
 }
 
 // isLocalDev reads the local_dev setting from .entire/settings.json
@@ -764,6 +764,47 @@ func TestGenerateChainedContent(t *testing.T) {
 	}
 }
 
+func TestGenerateChainedContent_ForwardsStdin(t *testing.T) {
+	t.Parallel()
+
+	var base string
+	for _, spec := range buildHookSpecs("true") {
+		if spec.name == "post-rewrite" {
+			base = spec.content
+		}
+	}
+	if base == "" {
+		t.Fatal("no post-rewrite hook spec")
+	}
+
+	// The pre-existing hook must get the rewrite list git supplied on stdin,
+	// even though our hook read it first.
+	hooksDir := t.TempDir()
+	received := filepath.Join(hooksDir, "received")
+	backup := "#!/bin/sh\necho \"$1\" > " + received + "\ncat >> " + received + "\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, "post-rewrite"+backupSuffix), []byte(backup), 0o755); err != nil {
+		t.Fatalf("failed to write backup hook: %v", err)
+	}
+	hookPath := filepath.Join(hooksDir, "post-rewrite")
+	if err := os.WriteFile(hookPath, []byte(generateChainedContent(base, "post-rewrite")), 0o755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+
+	input := "1111111111111111111111111111111111111111 2222222222222222222222222222222222222222\n"
+	cmd := exec.CommandContext(context.Background(), hookPath, "rebase")
+	cmd.Stdin = strings.NewReader(input)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("hook failed: %v\n%s", err, output)
+	}
+	got, err := os.ReadFile(received)
+	if err != nil {
+		t.Fatalf("pre-existing hook didn't run: %v", err)
+	}
+	if want := "rebase\n" + input; string(got) != want {
+		t.Errorf("pre-existing hook received %q, want %q", got, want)
+	}
+}
+
 func TestInstallGitHook_InstallRemoveReinstall(t *testing.T) {
 	_, hooksDir := initHooksTestRepo(t)
 
@@ -19,8 +19,8 @@ type ManualCommitStrategy struct {
 	// stateStoreErr captures any error during initialization
 	stateStoreErr error
 
-	// checkpointStore manages checkpoint data in git
-	checkpointStore *checkpoint.GitStore
+	// checkpointStore manages temporary and committed checkpoint data
+	checkpointStore checkpoint.Store
 	// checkpointStoreOnce ensures thread-safe lazy initialization
 	checkpointStoreOnce sync.Once
 	// checkpointStoreErr captures any error during initialization
@@ -43,14 +43,19 @@ func (s *ManualCommitStrategy) getStateStore() (*session.StateStore, error) {
 
 // getCheckpointStore returns the checkpoint store, initializing it lazily if needed.
 // Thread-safe via sync.Once.
-func (s *ManualCommitStrategy) getCheckpointStore() (*checkpoint.GitStore, error) {
+func (s *ManualCommitStrategy) getCheckpointStore() (checkpoint.Store, error) {
 	s.checkpointStoreOnce.Do(func() {
 		repo, err := OpenRepository()
 		if err != nil {
 			s.checkpointStoreErr = fmt.Errorf("failed to open repository: %w", err)
 			return
 		}
-		s.checkpointStore = checkpoint.NewGitStore(repo)
+		store, err := NewCheckpointStore(repo)
+		if err != nil {
+			s.checkpointStoreErr = err
+			return
+		}
+		s.checkpointStore = store
 	})
 	return s.checkpointStore, s.checkpointStoreErr
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/ignore"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
@@ -159,7 +160,9 @@ func countLinesStr(content string) int {
 // 5. Compute percentages
 //
 // Note: Binary files (detected by null bytes) are silently excluded from attribution
-// calculations since line-based diffing only applies to text files.
+// calculations since line-based diffing only applies to text files. So are files
+// matched by ignored (.entire/ignore), such as generated code and lockfiles; a nil
+// ignored excludes nothing.
 //
 // See docs/architecture/attribution.md for details on the per-file tracking approach.
 func CalculateAttributionWithAccumulated(
@@ -168,7 +171,9 @@ func CalculateAttributionWithAccumulated(
 	headTree *object.Tree,
 	filesTouched []string,
 	promptAttributions []PromptAttribution,
+	ignored *ignore.Matcher,
 ) *checkpoint.InitialAttribution {
+	filesTouched = ignored.Filter(filesTouched)
 	if len(filesTouched) == 0 {
 		return nil
 	}
@@ -216,7 +221,7 @@ func CalculateAttributionWithAccumulated(
 
 	// Calculate total user edits to non-agent files (files not in filesTouched)
 	// These files are not in the shadow tree, so base→head captures ALL their user edits
-	nonAgentFiles := getAllChangedFilesBetweenTrees(baseTree, headTree)
+	nonAgentFiles := ignored.Filter(getAllChangedFilesBetweenTrees(baseTree, headTree))
 	var allUserEditsToNonAgentFiles int
 	for _, filePath := range nonAgentFiles {
 		if slices.Contains(filesTouched, filePath) {
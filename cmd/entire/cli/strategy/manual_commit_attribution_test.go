@@ -4,6 +4,8 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/entireio/cli/cmd/entire/cli/ignore"
+
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/storage/memory"
@@ -278,7 +280,7 @@ func TestCalculateAttributionWithAccumulated_BasicCase(t *testing.T) {
 	promptAttributions := []PromptAttribution{} // No intermediate checkpoints
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -312,6 +314,45 @@ func TestCalculateAttributionWithAccumulated_BasicCase(t *testing.T) {
 	}
 }
 
+// TestCalculateAttributionWithAccumulated_IgnoredFiles tests that files excluded
+// by .entire/ignore count neither as agent nor as user lines.
+func TestCalculateAttributionWithAccumulated_IgnoredFiles(t *testing.T) {
+	baseTree := buildTestTree(t, map[string]string{
+		"main.go": "",
+	})
+
+	// Agent adds 4 lines of code and regenerates a 6-line lockfile
+	shadowTree := buildTestTree(t, map[string]string{
+		"main.go":   "line1\nline2\nline3\nline4\n",
+		"deps.lock": "a\nb\nc\nd\ne\nf\n",
+	})
+
+	// User adds 1 line of code and a generated file
+	headTree := buildTestTree(t, map[string]string{
+		"main.go":        "line1\nline2\nline3\nline4\nuser1\n",
+		"deps.lock":      "a\nb\nc\nd\ne\nf\n",
+		"dist/bundle.js": "x\ny\nz\n",
+	})
+
+	result := CalculateAttributionWithAccumulated(
+		baseTree, shadowTree, headTree, []string{"main.go", "deps.lock"}, []PromptAttribution{},
+		ignore.New([]string{"*.lock", "dist/"}),
+	)
+
+	if result == nil {
+		t.Fatal("expected non-nil result")
+	}
+	if result.AgentLines != 4 {
+		t.Errorf("AgentLines = %d, want 4", result.AgentLines)
+	}
+	if result.HumanAdded != 1 {
+		t.Errorf("HumanAdded = %d, want 1", result.HumanAdded)
+	}
+	if result.TotalCommitted != 5 {
+		t.Errorf("TotalCommitted = %d, want 5", result.TotalCommitted)
+	}
+}
+
 // TestCalculateAttributionWithAccumulated_BugScenario tests the specific bug case:
 // agent adds 10 lines, user removes 5 and adds 2.
 //
@@ -336,7 +377,7 @@ func TestCalculateAttributionWithAccumulated_BugScenario(t *testing.T) {
 	promptAttributions := []PromptAttribution{} // No intermediate checkpoints
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -394,7 +435,7 @@ func TestCalculateAttributionWithAccumulated_DeletionOnly(t *testing.T) {
 	promptAttributions := []PromptAttribution{} // No intermediate checkpoints
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -444,7 +485,7 @@ func TestCalculateAttributionWithAccumulated_NoUserEdits(t *testing.T) {
 	promptAttributions := []PromptAttribution{} // No intermediate checkpoints
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -497,7 +538,7 @@ func TestCalculateAttributionWithAccumulated_NoAgentWork(t *testing.T) {
 	promptAttributions := []PromptAttribution{} // No intermediate checkpoints
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -552,7 +593,7 @@ func TestCalculateAttributionWithAccumulated_UserRemovesAllAgentLines(t *testing
 	promptAttributions := []PromptAttribution{} // No intermediate checkpoints
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -622,7 +663,7 @@ func TestCalculateAttributionWithAccumulated_WithPromptAttributions(t *testing.T
 	}
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -666,7 +707,7 @@ func TestCalculateAttributionWithAccumulated_EmptyFilesTouched(t *testing.T) {
 	headTree := buildTestTree(t, map[string]string{})
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, []string{}, []PromptAttribution{},
+		baseTree, shadowTree, headTree, []string{}, []PromptAttribution{}, nil,
 	)
 
 	if result != nil {
@@ -719,7 +760,7 @@ func TestCalculateAttributionWithAccumulated_UserEditsNonAgentFile(t *testing.T)
 	}
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -1000,7 +1041,7 @@ func TestCalculateAttributionWithAccumulated_UserSelfModification(t *testing.T)
 	}
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -1072,7 +1113,7 @@ func TestCalculateAttributionWithAccumulated_MixedModifications(t *testing.T) {
 	}
 
 	result := CalculateAttributionWithAccumulated(
-		baseTree, shadowTree, headTree, filesTouched, promptAttributions,
+		baseTree, shadowTree, headTree, filesTouched, promptAttributions, nil,
 	)
 
 	if result == nil {
@@ -10,10 +10,14 @@ import (
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/aider"
 	"github.com/entireio/cli/cmd/entire/cli/agent/claudecode"
+	"github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	"github.com/entireio/cli/cmd/entire/cli/agent/cursor"
 	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	cpkg "github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/ignore"
 	"github.com/entireio/cli/cmd/entire/cli/logging"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
 	"github.com/entireio/cli/cmd/entire/cli/session"
@@ -27,15 +31,19 @@ import (
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
-// listCheckpoints returns all checkpoints from the metadata branch.
-// Uses checkpoint.GitStore.ListCommitted() for reading from entire/checkpoints/v1.
+// listCheckpoints returns all committed checkpoints, read from the checkpoint
+// index when one exists and from the checkpoint store otherwise.
 func (s *ManualCommitStrategy) listCheckpoints() ([]CheckpointInfo, error) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %w", err)
+	}
 	store, err := s.getCheckpointStore()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get checkpoint store: %w", err)
 	}
 
-	committed, err := store.ListCommitted(context.Background())
+	committed, err := ListCommittedCheckpoints(context.Background(), repo, store)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list committed checkpoints: %w", err)
 	}
@@ -141,6 +149,9 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 			return nil, fmt.Errorf("failed to extract session data from live transcript: %w", err)
 		}
 	}
+	// Files excluded by .entire/ignore count neither as touched nor in attribution
+	ignored := loadIgnoreMatcher()
+	sessionData.FilesTouched = ignored.Filter(sessionData.FilesTouched)
 
 	// Get checkpoint store
 	store, err := s.getCheckpointStore()
@@ -153,11 +164,15 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 	// Attribution calculation requires shadow branch reference; skip if mid-session commit
 	var attribution *cpkg.InitialAttribution
 	if hasShadowBranch {
-		attribution = calculateSessionAttributions(repo, ref, sessionData, state)
+		attribution = calculateSessionAttributions(repo, ref, sessionData, state, ignored)
 	}
 	// Get current branch name
 	branchName := GetCurrentBranchName(repo)
 
+	// Offsets in the session state count lines of the whole transcript file;
+	// the stored transcript may start further in (see SessionTranscript).
+	checkpointTranscriptStart := max(state.CheckpointTranscriptStart-sessionData.TranscriptStart, 0)
+
 	// Generate summary if enabled
 	var summary *cpkg.Summary
 	if settings.IsSummarizeEnabled() && len(sessionData.Transcript) > 0 {
@@ -169,9 +184,9 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		// For Gemini (JSON), CheckpointTranscriptStart is a message index.
 		var scopedTranscript []byte
 		if state.AgentType == agent.AgentTypeGemini {
-			scopedTranscript = geminicli.SliceFromMessage(sessionData.Transcript, state.CheckpointTranscriptStart)
+			scopedTranscript = geminicli.SliceFromMessage(sessionData.Transcript, checkpointTranscriptStart)
 		} else {
-			scopedTranscript = transcript.SliceFromLine(sessionData.Transcript, state.CheckpointTranscriptStart)
+			scopedTranscript = transcript.SliceFromLine(sessionData.Transcript, checkpointTranscriptStart)
 		}
 		if len(scopedTranscript) > 0 {
 			var err error
@@ -205,7 +220,8 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 		Agent:                       state.AgentType,
 		TurnID:                      state.TurnID,
 		TranscriptIdentifierAtStart: state.TranscriptIdentifierAtStart,
-		CheckpointTranscriptStart:   state.CheckpointTranscriptStart,
+		CheckpointTranscriptStart:   checkpointTranscriptStart,
+		TranscriptParent:            state.LastCondensedCheckpointID,
 		TokenUsage:                  sessionData.TokenUsage,
 		InitialAttribution:          attribution,
 		Summary:                     summary,
@@ -223,7 +239,7 @@ func (s *ManualCommitStrategy) CondenseSession(repo *git.Repository, checkpointI
 	}, nil
 }
 
-func calculateSessionAttributions(repo *git.Repository, shadowRef *plumbing.Reference, sessionData *ExtractedSessionData, state *SessionState) *cpkg.InitialAttribution {
+func calculateSessionAttributions(repo *git.Repository, shadowRef *plumbing.Reference, sessionData *ExtractedSessionData, state *SessionState, ignored *ignore.Matcher) *cpkg.InitialAttribution {
 	// Calculate initial attribution using accumulated prompt attribution data.
 	// This uses user edits captured at each prompt start (before agent works),
 	// plus any user edits after the final checkpoint (shadow → head).
@@ -295,6 +311,7 @@ func calculateSessionAttributions(repo *git.Repository, shadowRef *plumbing.Refe
 							headTree,
 							sessionData.FilesTouched,
 							state.PromptAttributions,
+							ignored,
 						)
 
 						if attribution != nil {
@@ -361,22 +378,19 @@ func (s *ManualCommitStrategy) extractSessionData(repo *git.Repository, shadowRe
 		}
 	}
 
-	// Process transcript based on agent type
+	// Process transcript based on agent type. Line counts and token usage
+	// cover the whole file, as the offsets in the session state do.
 	if fullTranscript != "" {
-		data.Transcript = []byte(fullTranscript)
 		data.FullTranscriptLines = countTranscriptItems(agentType, fullTranscript)
-		data.Prompts = extractUserPrompts(agentType, fullTranscript)
+		data.TokenUsage = calculateTokenUsage(agentType, []byte(fullTranscript), checkpointTranscriptStart)
+		data.Transcript, data.TranscriptStart = SessionTranscript(agentType, sessionID, []byte(fullTranscript))
+		data.Prompts = extractUserPrompts(agentType, string(data.Transcript))
 		data.Context = generateContextFromPrompts(data.Prompts)
 	}
 
 	// Use tracked files from session state (not all files in tree)
 	data.FilesTouched = filesTouched
 
-	// Calculate token usage from the extracted transcript portion
-	if len(data.Transcript) > 0 {
-		data.TokenUsage = calculateTokenUsage(agentType, data.Transcript, checkpointTranscriptStart)
-	}
-
 	return data, nil
 }
 
@@ -400,9 +414,11 @@ func (s *ManualCommitStrategy) extractSessionDataFromLiveTranscript(state *Sessi
 	}
 
 	fullTranscript := string(liveData)
-	data.Transcript = liveData
 	data.FullTranscriptLines = countTranscriptItems(state.AgentType, fullTranscript)
-	data.Prompts = extractUserPrompts(state.AgentType, fullTranscript)
+	// Calculate token usage from the whole file, which the offset counts lines of
+	data.TokenUsage = calculateTokenUsage(state.AgentType, liveData, state.CheckpointTranscriptStart)
+	data.Transcript, data.TranscriptStart = SessionTranscript(state.AgentType, state.SessionID, liveData)
+	data.Prompts = extractUserPrompts(state.AgentType, string(data.Transcript))
 	data.Context = generateContextFromPrompts(data.Prompts)
 
 	// Extract files from transcript since state.FilesTouched may be empty for mid-session commits
@@ -440,12 +456,33 @@ func (s *ManualCommitStrategy) extractSessionDataFromLiveTranscript(state *Sessi
 		}
 	}
 
-	// Calculate token usage from the extracted transcript portion
-	if len(data.Transcript) > 0 {
-		data.TokenUsage = calculateTokenUsage(state.AgentType, data.Transcript, state.CheckpointTranscriptStart)
+	return data, nil
+}
+
+// SessionTranscript returns the part of a transcript that belongs to
+// sessionID, and the line it starts at. Aider keeps every session in one chat
+// history file, so its checkpoints store only the captured session; other
+// agents' transcripts are returned whole.
+func SessionTranscript(agentType agent.AgentType, sessionID string, transcript []byte) ([]byte, int) {
+	if agentType == agent.AgentTypeAider {
+		return aider.SessionTranscript(transcript, sessionID)
 	}
+	return transcript, 0
+}
 
-	return data, nil
+// TranscriptToRestore returns the content to write to sessionFile to restore
+// a session's transcript. For Aider the session's part replaces its part of
+// the existing chat history, so other sessions are kept; other agents'
+// transcripts are written as is.
+func TranscriptToRestore(agentType agent.AgentType, sessionFile, sessionID string, transcript []byte) []byte {
+	if agentType != agent.AgentTypeAider {
+		return transcript
+	}
+	existing, err := os.ReadFile(sessionFile) //nolint:gosec // sessionFile is the agent's transcript path
+	if err != nil {
+		return transcript
+	}
+	return aider.MergeSessionTranscript(existing, transcript, sessionID)
 }
 
 // countTranscriptItems counts lines (JSONL) or messages (JSON) in a transcript.
@@ -506,6 +543,29 @@ func extractUserPrompts(agentType agent.AgentType, content string) []string {
 		// Otherwise fall through to JSONL parsing for Unknown type
 	}
 
+	// Cursor and Codex use their own JSONL line shapes rather than Claude's typed entries,
+	// and Aider writes a markdown chat history
+	switch agentType { //nolint:exhaustive // Claude Code and unknown agents fall through below
+	case agent.AgentTypeCursor:
+		prompts, err := cursor.ExtractAllUserPrompts([]byte(content))
+		if err != nil {
+			return nil
+		}
+		return prompts
+	case agent.AgentTypeCodex:
+		prompts, err := codex.ExtractAllUserPrompts([]byte(content))
+		if err != nil {
+			return nil
+		}
+		return prompts
+	case agent.AgentTypeAider:
+		prompts, err := aider.ExtractAllUserPrompts([]byte(content))
+		if err != nil {
+			return nil
+		}
+		return prompts
+	}
+
 	// Claude Code and other JSONL-based agents
 	return extractUserPromptsFromLines(strings.Split(content, "\n"))
 }
@@ -533,6 +593,15 @@ func calculateTokenUsage(agentType agent.AgentType, data []byte, startOffset int
 		// Otherwise fall through to JSONL parsing for Unknown type
 	}
 
+	switch agentType { //nolint:exhaustive // Claude Code and unknown agents fall through below
+	case agent.AgentTypeCursor:
+		return cursor.CalculateTokenUsage(data, startOffset)
+	case agent.AgentTypeCodex:
+		return codex.CalculateTokenUsage(data, startOffset)
+	case agent.AgentTypeAider:
+		return aider.CalculateTokenUsage(data, startOffset)
+	}
+
 	// Claude Code and other JSONL-based agents
 	lines, err := claudecode.ParseTranscript(data)
 	if err != nil || len(lines) == 0 {
@@ -690,6 +759,7 @@ func (s *ManualCommitStrategy) CondenseSessionByID(sessionID string) error {
 	state.CheckpointTranscriptStart = result.TotalTranscriptLines
 	state.Phase = session.PhaseIdle
 	state.LastCheckpointID = checkpointID
+	state.LastCondensedCheckpointID = checkpointID
 	state.AttributionBaseCommit = state.BaseCommit
 	state.PromptAttributions = nil
 	state.PendingPromptAttribution = nil
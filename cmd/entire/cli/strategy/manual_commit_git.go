@@ -81,6 +81,7 @@ func (s *ManualCommitStrategy) SaveChanges(ctx SaveContext) error {
 
 	// Use WriteTemporary to create the checkpoint
 	isFirstCheckpointOfSession := state.StepCount == 0
+	ignored := loadIgnoreMatcher()
 	result, err := store.WriteTemporary(context.Background(), checkpoint.WriteTemporaryOptions{
 		SessionID:         sessionID,
 		BaseCommit:        state.BaseCommit,
@@ -94,6 +95,7 @@ func (s *ManualCommitStrategy) SaveChanges(ctx SaveContext) error {
 		AuthorName:        ctx.AuthorName,
 		AuthorEmail:       ctx.AuthorEmail,
 		IsFirstCheckpoint: isFirstCheckpointOfSession,
+		Ignored:           ignored,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to write temporary checkpoint: %w", err)
@@ -123,7 +125,7 @@ func (s *ManualCommitStrategy) SaveChanges(ctx SaveContext) error {
 	state.PromptAttributions = append(state.PromptAttributions, promptAttr)
 
 	// Track touched files (modified, new, and deleted)
-	state.FilesTouched = mergeFilesTouched(state.FilesTouched, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles)
+	state.FilesTouched = ignored.Filter(mergeFilesTouched(state.FilesTouched, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles))
 
 	// On first checkpoint, record the transcript identifier for this session
 	if state.StepCount == 1 {
@@ -225,6 +227,7 @@ func (s *ManualCommitStrategy) SaveTaskCheckpoint(ctx TaskCheckpointContext) err
 	)
 
 	// Use WriteTemporaryTask to create the checkpoint
+	ignored := loadIgnoreMatcher()
 	_, err = store.WriteTemporaryTask(context.Background(), checkpoint.WriteTemporaryTaskOptions{
 		SessionID:              ctx.SessionID,
 		BaseCommit:             state.BaseCommit,
@@ -234,6 +237,7 @@ func (s *ManualCommitStrategy) SaveTaskCheckpoint(ctx TaskCheckpointContext) err
 		ModifiedFiles:          ctx.ModifiedFiles,
 		NewFiles:               ctx.NewFiles,
 		DeletedFiles:           ctx.DeletedFiles,
+		Ignored:                ignored,
 		TranscriptPath:         ctx.TranscriptPath,
 		SubagentTranscriptPath: ctx.SubagentTranscriptPath,
 		CheckpointUUID:         ctx.CheckpointUUID,
@@ -250,7 +254,7 @@ func (s *ManualCommitStrategy) SaveTaskCheckpoint(ctx TaskCheckpointContext) err
 	}
 
 	// Track touched files (modified, new, and deleted)
-	state.FilesTouched = mergeFilesTouched(state.FilesTouched, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles)
+	state.FilesTouched = ignored.Filter(mergeFilesTouched(state.FilesTouched, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles))
 
 	// Save updated state
 	if err := s.saveSessionState(state); err != nil {
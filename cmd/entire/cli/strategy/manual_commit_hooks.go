@@ -104,6 +104,9 @@ func askConfirmTTY(prompt string, context string, defaultYes bool) bool {
 // If the message contains only our trailer (no actual user content), strip it
 // so git will abort the commit due to empty message.
 //
+// When commits are linked with notes, the trailer is always stripped and its
+// checkpoint ID is kept for PostCommit to record in a note.
+//
 //nolint:unparam // error return required by interface but hooks must return nil
 func (s *ManualCommitStrategy) CommitMsg(commitMsgFile string) error {
 	content, err := os.ReadFile(commitMsgFile) //nolint:gosec // Path comes from git hook
@@ -113,21 +116,39 @@ func (s *ManualCommitStrategy) CommitMsg(commitMsgFile string) error {
 
 	message := string(content)
 
+	notes := usesCheckpointNotes()
+	if notes {
+		clearPendingCheckpoint()
+	}
+
 	// Check if our trailer is present (ParseCheckpoint validates format, so found==true means valid)
-	if _, found := trailers.ParseCheckpoint(message); !found {
+	checkpointID, found := trailers.ParseCheckpoint(message)
+	if !found {
 		// No trailer, nothing to do
 		return nil
 	}
 
 	// Check if there's any user content (non-comment, non-trailer lines)
-	if !hasUserContent(message) {
+	switch {
+	case !hasUserContent(message):
 		// No user content - strip the trailer so git aborts
-		message = stripCheckpointTrailer(message)
-		if err := os.WriteFile(commitMsgFile, []byte(message), 0o600); err != nil {
+	case notes:
+		// Linked with a note instead - keep the ID for PostCommit
+		repo, err := OpenRepository()
+		if err != nil {
 			return nil //nolint:nilerr // Hook must be silent on failure
 		}
+		if err := savePendingCheckpoint(repo, checkpointID); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to link commit to checkpoint: %v\n", err)
+		}
+	default:
+		return nil
 	}
 
+	message = stripCheckpointTrailer(message)
+	if err := os.WriteFile(commitMsgFile, []byte(message), 0o600); err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
 	return nil
 }
 
@@ -485,6 +506,16 @@ func (s *ManualCommitStrategy) PostCommit() error {
 
 	// Check if commit has checkpoint trailer (ParseCheckpoint validates format)
 	checkpointID, found := trailers.ParseCheckpoint(commit.Message)
+	if !found && usesCheckpointNotes() {
+		// Linked with a note: CommitMsg kept the ID of the trailer it stripped
+		if cpID, pending := takePendingCheckpoint(repo, commit); pending {
+			if err := AddCheckpointNote(logCtx, repo, commit.Hash, []id.CheckpointID{cpID}); err != nil {
+				fmt.Fprintf(os.Stderr, "[entire] Warning: failed to link commit to checkpoint: %v\n", err)
+			} else {
+				checkpointID, found = cpID, true
+			}
+		}
+	}
 	if !found {
 		// No trailer — user removed it or it was never added (mid-turn commit).
 		// Still update BaseCommit for active sessions so future commits can match.
@@ -740,6 +771,7 @@ func (s *ManualCommitStrategy) condenseAndUpdateState(
 
 	// Save checkpoint ID so subsequent commits can reuse it (e.g., amend restores trailer)
 	state.LastCheckpointID = checkpointID
+	state.LastCondensedCheckpointID = checkpointID
 
 	shortID := state.SessionID
 	if len(shortID) > 8 {
@@ -1488,6 +1520,7 @@ func (s *ManualCommitStrategy) calculatePromptAttributionAtStart(
 	// IMPORTANT: We read from worktree (not staging area) to match what WriteTemporary
 	// captures in checkpoints. This ensures attribution is consistent.
 	changedFiles := make(map[string]string)
+	ignored := loadIgnoreMatcher()
 	for filePath, fileStatus := range status {
 		// Skip unmodified files
 		if fileStatus.Worktree == git.Unmodified && fileStatus.Staging == git.Unmodified {
@@ -1497,6 +1530,10 @@ func (s *ManualCommitStrategy) calculatePromptAttributionAtStart(
 		if strings.HasPrefix(filePath, paths.EntireMetadataDir+"/") || strings.HasPrefix(filePath, ".entire/") {
 			continue
 		}
+		// Skip files excluded by .entire/ignore (not snapshotted in checkpoints either)
+		if ignored.Match(filePath) {
+			continue
+		}
 
 		// Always read from worktree to match checkpoint behavior
 		fullPath := filepath.Join(worktreeRoot, filePath)
@@ -1636,14 +1673,16 @@ func (s *ManualCommitStrategy) finalizeAllTurnCheckpoints(state *SessionState) i
 		return 1 // Count as error - all checkpoints will be skipped
 	}
 
-	// Extract prompts and context from the full transcript
-	prompts := extractUserPrompts(state.AgentType, string(fullTranscript))
+	// Extract prompts and context from the session's part of the transcript
+	fullTranscriptLines := countTranscriptItems(state.AgentType, string(fullTranscript))
+	sessionTranscript, _ := SessionTranscript(state.AgentType, state.SessionID, fullTranscript)
+	prompts := extractUserPrompts(state.AgentType, string(sessionTranscript))
 	contextBytes := generateContextFromPrompts(prompts)
 
 	// Redact secrets before writing — matches WriteCommitted behavior.
 	// The live transcript on disk contains raw content; redaction must happen
 	// before anything is persisted to the metadata branch.
-	fullTranscript, err = redact.JSONLBytes(fullTranscript)
+	sessionTranscript, err = redact.JSONLBytes(sessionTranscript)
 	if err != nil {
 		logging.Warn(logCtx, "finalize: transcript redaction failed, skipping",
 			slog.String("session_id", state.SessionID),
@@ -1657,16 +1696,14 @@ func (s *ManualCommitStrategy) finalizeAllTurnCheckpoints(state *SessionState) i
 	}
 	contextBytes = redact.Bytes(contextBytes)
 
-	// Open repository and create checkpoint store
-	repo, err := OpenRepository()
+	store, err := s.getCheckpointStore()
 	if err != nil {
-		logging.Warn(logCtx, "finalize: failed to open repository",
+		logging.Warn(logCtx, "finalize: failed to open checkpoint store",
 			slog.String("error", err.Error()),
 		)
 		state.TurnCheckpointIDs = nil
 		return 1 // Count as error - all checkpoints will be skipped
 	}
-	store := checkpoint.NewGitStore(repo)
 
 	// Update each checkpoint with the full transcript
 	for _, cpIDStr := range state.TurnCheckpointIDs {
@@ -1683,7 +1720,7 @@ func (s *ManualCommitStrategy) finalizeAllTurnCheckpoints(state *SessionState) i
 		updateErr := store.UpdateCommitted(context.Background(), checkpoint.UpdateCommittedOptions{
 			CheckpointID: cpID,
 			SessionID:    state.SessionID,
-			Transcript:   fullTranscript,
+			Transcript:   sessionTranscript,
 			Prompts:      prompts,
 			Context:      contextBytes,
 			Agent:        state.AgentType,
@@ -1704,7 +1741,6 @@ func (s *ManualCommitStrategy) finalizeAllTurnCheckpoints(state *SessionState) i
 	}
 
 	// Update transcript start and clear turn checkpoint IDs
-	fullTranscriptLines := countTranscriptItems(state.AgentType, string(fullTranscript))
 	state.CheckpointTranscriptStart = fullTranscriptLines
 	state.TurnCheckpointIDs = nil
 
@@ -1792,6 +1828,7 @@ func (s *ManualCommitStrategy) carryForwardToNewShadowBranch(
 		MetadataDirAbs:    "",
 		CommitMessage:     "carry forward: uncommitted session files",
 		IsFirstCheckpoint: false,
+		Ignored:           loadIgnoreMatcher(),
 	})
 	if err != nil {
 		logging.Warn(logCtx, "post-commit: carry-forward failed",
@@ -1814,8 +1851,8 @@ func (s *ManualCommitStrategy) carryForwardToNewShadowBranch(
 	//   multiple checkpoints to understand the session history
 	// - Con: For long sessions with multiple partial commits, each checkpoint includes
 	//   the full transcript, which could be large
-	// An alternative would be incremental checkpoints (only new content since last condensation),
-	// but this would complicate checkpoint retrieval and require careful tracking of dependencies.
+	// With checkpoint_store.delta_transcripts enabled, the checkpoint store keeps only the
+	// lines appended since the previous checkpoint and reassembles the transcript on read.
 	state.FilesTouched = remainingFiles
 	state.StepCount = 1
 	state.CheckpointTranscriptStart = 0
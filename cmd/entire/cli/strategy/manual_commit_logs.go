@@ -1,14 +1,15 @@
 package strategy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
-	"github.com/entireio/cli/cmd/entire/cli/trailers"
 
 	"github.com/go-git/go-git/v5/plumbing"
 )
@@ -67,32 +68,29 @@ func (s *ManualCommitStrategy) GetSessionInfo() (*SessionInfo, error) {
 }
 
 // GetMetadataRef returns a reference to the metadata for the given checkpoint.
-// For manual-commit strategy, returns the sharded path on entire/checkpoints/v1 branch.
+// For manual-commit strategy, returns the sharded path in the checkpoint store.
 func (s *ManualCommitStrategy) GetMetadataRef(checkpoint Checkpoint) string {
 	if checkpoint.CheckpointID.IsEmpty() {
 		return ""
 	}
-	return paths.MetadataBranchName + ":" + checkpoint.CheckpointID.Path()
-}
-
-// GetSessionMetadataRef returns a reference to the most recent metadata commit for a session.
-// For manual-commit strategy, metadata lives on the entire/checkpoints/v1 branch.
-func (s *ManualCommitStrategy) GetSessionMetadataRef(_ string) string {
-	repo, err := OpenRepository()
+	store, err := s.getCheckpointStore()
 	if err != nil {
 		return ""
 	}
+	return store.Location() + ":" + checkpoint.CheckpointID.Path()
+}
 
-	// Get the sessions branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := repo.Reference(refName, true)
-	if err != nil {
+// GetSessionMetadataRef returns a reference to the most recent metadata for a session.
+// For manual-commit strategy, this is the session's most recent committed checkpoint.
+func (s *ManualCommitStrategy) GetSessionMetadataRef(sessionID string) string {
+	checkpoints, err := s.getCheckpointsForSession(sessionID)
+	if err != nil || len(checkpoints) == 0 {
 		return ""
 	}
-
-	// The tip of entire/checkpoints/v1 contains all condensed sessions
-	// Return a reference to it (sessionID is not used as all sessions are on the same branch)
-	return trailers.FormatSourceRef(paths.MetadataBranchName, ref.Hash().String())
+	latest := slices.MaxFunc(checkpoints, func(a, b CheckpointInfo) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return s.GetMetadataRef(Checkpoint{CheckpointID: latest.CheckpointID})
 }
 
 // GetSessionContext returns the context.md content for a session.
@@ -110,47 +108,20 @@ func (s *ManualCommitStrategy) GetSessionContext(sessionID string) string {
 	})
 	checkpointID := checkpoints[0].CheckpointID
 
-	repo, err := OpenRepository()
-	if err != nil {
-		return ""
-	}
-
-	// Get the sessions branch
-	refName := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
-	ref, err := repo.Reference(refName, true)
-	if err != nil {
-		return ""
-	}
-
-	commit, err := repo.CommitObject(ref.Hash())
-	if err != nil {
-		return ""
-	}
-
-	tree, err := commit.Tree()
-	if err != nil {
-		return ""
-	}
-
-	// Get checkpoint tree to read the sessions summary
-	checkpointTree, err := tree.Tree(checkpointID.Path())
+	store, err := s.getCheckpointStore()
 	if err != nil {
 		return ""
 	}
+	ctx := context.Background()
 
 	// Read root metadata to find session's context path from sessions map
-	metadataFile, err := checkpointTree.File(paths.MetadataFileName)
-	if err != nil {
-		return ""
-	}
-
-	metadataContent, err := metadataFile.Contents()
+	metadataContent, err := store.ReadCommittedFile(ctx, checkpointID.Path()+"/"+paths.MetadataFileName)
 	if err != nil {
 		return ""
 	}
 
 	var summary checkpoint.CheckpointSummary
-	if err := json.Unmarshal([]byte(metadataContent), &summary); err != nil {
+	if err := json.Unmarshal(metadataContent, &summary); err != nil {
 		return ""
 	}
 
@@ -165,22 +136,18 @@ func (s *ManualCommitStrategy) GetSessionContext(sessionID string) string {
 		return ""
 	}
 
-	// Read context using absolute path from root tree
+	// Read context using absolute path from the storage root
 	// SessionFilePaths now contains absolute paths like "/a1/b2c3d4e5f6/1/context.md"
 	if sessionPaths.Context == "" {
 		return ""
 	}
-	// Strip leading "/" for tree.File() which expects paths without leading slash
+	// Strip leading "/" since file paths are relative to the storage root
 	contextPath := strings.TrimPrefix(sessionPaths.Context, "/")
-	file, err := tree.File(contextPath)
-	if err != nil {
-		return ""
-	}
-	content, err := file.Contents()
+	content, err := store.ReadCommittedFile(ctx, contextPath)
 	if err != nil {
 		return ""
 	}
-	return content
+	return string(content)
 }
 
 // GetCheckpointLog returns the session transcript for a specific checkpoint.
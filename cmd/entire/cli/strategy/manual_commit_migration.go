@@ -1,10 +1,14 @@
 package strategy
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -44,29 +48,16 @@ func (s *ManualCommitStrategy) migrateShadowBranchIfNeeded(repo *git.Repository,
 		return true, nil
 	}
 
-	oldRefName := plumbing.NewBranchReferenceName(oldShadowBranch)
-	oldRef, err := repo.Reference(oldRefName, true)
+	moved, err := moveShadowBranch(repo, oldShadowBranch, newShadowBranch)
 	if err != nil {
+		return false, err
+	}
+	if !moved {
 		// Old shadow branch doesn't exist - just update state.BaseCommit
 		// This can happen if this is the first checkpoint after HEAD changed
 		state.BaseCommit = currentHead
 		fmt.Fprintf(os.Stderr, "Updated session base commit to %s (HEAD changed during session)\n", currentHead[:7])
-		return true, nil //nolint:nilerr // err is "reference not found" which is fine - just need to update state
-	}
-
-	// Old shadow branch exists - move it to new base commit
-	newRefName := plumbing.NewBranchReferenceName(newShadowBranch)
-
-	// Create new reference pointing to same commit as old shadow branch
-	newRef := plumbing.NewHashReference(newRefName, oldRef.Hash())
-	if err := repo.Storer.SetReference(newRef); err != nil {
-		return false, fmt.Errorf("failed to create new shadow branch %s: %w", newShadowBranch, err)
-	}
-
-	// Delete old reference via CLI (go-git v5's RemoveReference doesn't persist with packed refs/worktrees)
-	if err := DeleteBranchCLI(oldShadowBranch); err != nil {
-		// Non-fatal: log but continue - the important thing is the new branch exists
-		fmt.Fprintf(os.Stderr, "Warning: failed to remove old shadow branch %s: %v\n", oldShadowBranch, err)
+		return true, nil
 	}
 
 	fmt.Fprintf(os.Stderr, "Moved shadow branch from %s to %s (HEAD changed during session)\n",
@@ -91,3 +82,129 @@ func (s *ManualCommitStrategy) migrateAndPersistIfNeeded(repo *git.Repository, s
 	}
 	return nil
 }
+
+// moveShadowBranch renames a shadow branch. Returns false if the old shadow
+// branch doesn't exist.
+func moveShadowBranch(repo *git.Repository, oldShadowBranch, newShadowBranch string) (bool, error) {
+	oldRef, err := repo.Reference(plumbing.NewBranchReferenceName(oldShadowBranch), true)
+	if err != nil {
+		return false, nil //nolint:nilerr // err is "reference not found" - nothing to move
+	}
+
+	// Create new reference pointing to same commit as old shadow branch
+	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(newShadowBranch), oldRef.Hash())
+	if err := repo.Storer.SetReference(newRef); err != nil {
+		return false, fmt.Errorf("failed to create new shadow branch %s: %w", newShadowBranch, err)
+	}
+
+	// Delete old reference via CLI (go-git v5's RemoveReference doesn't persist with packed refs/worktrees)
+	if err := DeleteBranchCLI(oldShadowBranch); err != nil {
+		// Non-fatal: log but continue - the important thing is the new branch exists
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove old shadow branch %s: %v\n", oldShadowBranch, err)
+	}
+	return true, nil
+}
+
+// PostRewrite moves the sessions of this worktree off commits that
+// `git commit --amend` or `git rebase` rewrote: BaseCommit (with its shadow
+// branch) and AttributionBaseCommit follow the rewritten commits, and
+// LastCheckpointID is cleared if the rewrite dropped that checkpoint's
+// trailer, so a later amend doesn't restore a trailer the user removed.
+//
+//nolint:unparam // error return required by interface but hooks must return nil
+func (s *ManualCommitStrategy) PostRewrite(rewriteType string, rewrites []CommitRewrite) error {
+	logCtx := logging.WithComponent(context.Background(), "checkpoint")
+	if len(rewrites) == 0 {
+		return nil
+	}
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	worktreePath, err := GetWorktreePath()
+	if err != nil {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+	sessions, err := s.findSessionsForWorktree(worktreePath)
+	if err != nil || len(sessions) == 0 {
+		return nil //nolint:nilerr // Hook must be silent on failure
+	}
+
+	replacements := make(map[string]string, len(rewrites))
+	for _, rw := range rewrites {
+		replacements[rw.Old] = rw.New
+	}
+	// A rewrite may list a commit and, separately, the commit that later
+	// replaced its replacement; follow the chain to the final commit.
+	resolve := func(hash string) (string, bool) {
+		final, ok := replacements[hash]
+		for i := 0; ok && i < len(rewrites); i++ {
+			next, found := replacements[final]
+			if !found {
+				break
+			}
+			final = next
+		}
+		return final, ok
+	}
+
+	// Checkpoints whose trailer or note was on a rewritten commit but isn't
+	// on any replacement.
+	notes := LoadCheckpointNotes(repo)
+	kept := make(map[id.CheckpointID]bool)
+	var rewritten []id.CheckpointID
+	for _, rw := range rewrites {
+		if commit, err := repo.CommitObject(plumbing.NewHash(rw.Old)); err == nil {
+			rewritten = append(rewritten, notes.ParseAllCheckpoints(commit)...)
+		}
+		final, _ := resolve(rw.Old)
+		if commit, err := repo.CommitObject(plumbing.NewHash(final)); err == nil {
+			for _, cpID := range notes.ParseAllCheckpoints(commit) {
+				kept[cpID] = true
+			}
+		}
+	}
+	dropped := make(map[id.CheckpointID]bool)
+	for _, cpID := range rewritten {
+		if !kept[cpID] {
+			dropped[cpID] = true
+		}
+	}
+
+	for _, state := range sessions {
+		changed := false
+		if newBase, ok := resolve(state.BaseCommit); ok {
+			oldShadowBranch := checkpoint.ShadowBranchNameForCommit(state.BaseCommit, state.WorktreeID)
+			newShadowBranch := checkpoint.ShadowBranchNameForCommit(newBase, state.WorktreeID)
+			if oldShadowBranch != newShadowBranch {
+				if _, err := moveShadowBranch(repo, oldShadowBranch, newShadowBranch); err != nil {
+					fmt.Fprintf(os.Stderr, "[entire] Warning: failed to move shadow branch after %s: %v\n", rewriteType, err)
+					continue
+				}
+			}
+			logging.Debug(logCtx, "post-rewrite: updating BaseCommit",
+				slog.String("session_id", state.SessionID),
+				slog.String("rewrite_type", rewriteType),
+				slog.String("old_base", truncateHash(state.BaseCommit)),
+				slog.String("new_base", truncateHash(newBase)),
+			)
+			state.BaseCommit = newBase
+			changed = true
+		}
+		if newBase, ok := resolve(state.AttributionBaseCommit); ok {
+			state.AttributionBaseCommit = newBase
+			changed = true
+		}
+		if !state.LastCheckpointID.IsEmpty() && dropped[state.LastCheckpointID] {
+			state.LastCheckpointID = ""
+			changed = true
+		}
+		if changed {
+			if err := s.saveSessionState(state); err != nil {
+				fmt.Fprintf(os.Stderr, "[entire] Warning: failed to update session state: %v\n", err)
+			}
+		}
+	}
+	return nil
+}
@@ -158,8 +158,9 @@ func (s *ManualCommitStrategy) GetLogsOnlyRewindPoints(limit int) ([]RewindPoint
 		}
 	}
 
-	// Get metadata branch tree for reading session prompts (best-effort, ignore errors)
-	metadataTree, _ := GetMetadataBranchTree(repo) //nolint:errcheck // Best-effort for session prompts
+	// Checkpoint store for reading session prompts (best-effort, ignore errors)
+	store, _ := s.getCheckpointStore() //nolint:errcheck // Best-effort for session prompts
+	ctx := context.Background()
 
 	head, err := repo.Head()
 	if err != nil {
@@ -177,6 +178,7 @@ func (s *ManualCommitStrategy) GetLogsOnlyRewindPoints(limit int) ([]RewindPoint
 
 	var points []RewindPoint
 	count := 0
+	notes := LoadCheckpointNotes(repo)
 
 	err = iter.ForEach(func(c *object.Commit) error {
 		if count >= logsOnlyScanLimit {
@@ -184,8 +186,8 @@ func (s *ManualCommitStrategy) GetLogsOnlyRewindPoints(limit int) ([]RewindPoint
 		}
 		count++
 
-		// Extract checkpoint ID from Entire-Checkpoint trailer (ParseCheckpoint validates format)
-		cpID, found := trailers.ParseCheckpoint(c.Message)
+		// Extract checkpoint ID from Entire-Checkpoint trailer or note (ParseCheckpoint validates format)
+		cpID, found := notes.ParseCheckpoint(c)
 		if !found {
 			return nil
 		}
@@ -198,20 +200,20 @@ func (s *ManualCommitStrategy) GetLogsOnlyRewindPoints(limit int) ([]RewindPoint
 		// Create logs-only rewind point
 		message := strings.Split(c.Message, "\n")[0]
 
-		// Read session prompts from metadata tree
+		// Read session prompts from the checkpoint store
 		var sessionPrompt string
 		var sessionPrompts []string
-		if metadataTree != nil {
+		if store != nil {
 			checkpointPath := paths.CheckpointPath(cpInfo.CheckpointID) //nolint:staticcheck // already present in codebase
 			// For multi-session checkpoints, read all prompts
 			if cpInfo.SessionCount > 1 && len(cpInfo.SessionIDs) > 1 {
-				sessionPrompts = ReadAllSessionPromptsFromTree(metadataTree, checkpointPath, cpInfo.SessionCount, cpInfo.SessionIDs)
+				sessionPrompts = ReadAllCommittedSessionPrompts(ctx, store, checkpointPath, cpInfo.SessionCount, cpInfo.SessionIDs)
 				// Use the last (most recent) prompt as the main session prompt
 				if len(sessionPrompts) > 0 {
 					sessionPrompt = sessionPrompts[len(sessionPrompts)-1]
 				}
 			} else {
-				sessionPrompt = ReadSessionPromptFromTree(metadataTree, checkpointPath)
+				sessionPrompt = ReadCommittedSessionPrompt(ctx, store, checkpointPath)
 				if sessionPrompt != "" {
 					sessionPrompts = []string{sessionPrompt}
 				}
@@ -287,10 +289,13 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 		}
 	}
 
+	// Files excluded by .entire/ignore are neither restored nor deleted
+	ignored := loadIgnoreMatcher()
+
 	// Build set of files in the checkpoint tree (excluding metadata)
 	checkpointFiles := make(map[string]bool)
 	err = tree.Files().ForEach(func(f *object.File) error {
-		if !strings.HasPrefix(f.Name, entireDir) {
+		if !strings.HasPrefix(f.Name, entireDir) && !ignored.Match(f.Name) {
 			checkpointFiles[f.Name] = true
 		}
 		return nil
@@ -340,16 +345,16 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 			return nil //nolint:nilerr // Skip paths we can't make relative
 		}
 
-		// Skip directories and protected paths
+		// Skip directories and protected or ignored paths
 		if info.IsDir() {
-			if isProtectedPath(relPath) {
+			if isProtectedPath(relPath) || ignored.MatchDir(relPath) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Skip files in protected directories
-		if isProtectedPath(relPath) {
+		// Skip files in protected directories and ignored files
+		if isProtectedPath(relPath) || ignored.Match(relPath) {
 			return nil
 		}
 
@@ -382,8 +387,9 @@ func (s *ManualCommitStrategy) Rewind(point RewindPoint) error {
 
 	// Restore files from checkpoint
 	err = tree.Files().ForEach(func(f *object.File) error {
-		// Skip metadata directories - these are for checkpoint storage, not working dir
-		if strings.HasPrefix(f.Name, entireDir) {
+		// Skip metadata directories - these are for checkpoint storage, not working dir -
+		// and ignored files
+		if strings.HasPrefix(f.Name, entireDir) || ignored.Match(f.Name) {
 			return nil
 		}
 
@@ -507,11 +513,14 @@ func (s *ManualCommitStrategy) PreviewRewind(point RewindPoint) (*RewindPreview,
 		}
 	}
 
+	// Files excluded by .entire/ignore are neither restored nor deleted
+	ignored := loadIgnoreMatcher()
+
 	// Build set of files in the checkpoint tree (excluding metadata)
 	checkpointFiles := make(map[string]bool)
 	var filesToRestore []string
 	err = tree.Files().ForEach(func(f *object.File) error {
-		if !strings.HasPrefix(f.Name, entireDir) {
+		if !strings.HasPrefix(f.Name, entireDir) && !ignored.Match(f.Name) {
 			checkpointFiles[f.Name] = true
 			filesToRestore = append(filesToRestore, f.Name)
 		}
@@ -561,16 +570,16 @@ func (s *ManualCommitStrategy) PreviewRewind(point RewindPoint) (*RewindPreview,
 			return nil //nolint:nilerr // Skip paths we can't make relative
 		}
 
-		// Skip directories and protected paths
+		// Skip directories and protected or ignored paths
 		if info.IsDir() {
-			if isProtectedPath(relPath) {
+			if isProtectedPath(relPath) || ignored.MatchDir(relPath) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		// Skip files in protected directories
-		if isProtectedPath(relPath) {
+		// Skip files in protected directories and ignored files
+		if isProtectedPath(relPath) || ignored.Match(relPath) {
 			return nil
 		}
 
@@ -679,6 +688,9 @@ func (s *ManualCommitStrategy) RestoreLogsOnly(point RewindPoint, force bool) ([
 	var restored []RestoredSession
 	for i := range totalSessions {
 		content, readErr := store.ReadSessionContent(context.Background(), point.CheckpointID, i)
+		if errors.Is(readErr, cpkg.ErrEncryptedContent) {
+			return nil, fmt.Errorf("failed to read session %d: %w", i, readErr)
+		}
 		if readErr != nil {
 			fmt.Fprintf(os.Stderr, "  Warning: failed to read session %d: %v\n", i, readErr)
 			continue
@@ -741,7 +753,8 @@ func (s *ManualCommitStrategy) RestoreLogsOnly(point RewindPoint, force bool) ([
 			continue
 		}
 
-		if writeErr := os.WriteFile(sessionFile, content.Transcript, 0o600); writeErr != nil {
+		transcript := TranscriptToRestore(sessionAgent.Type(), sessionFile, sessionID, content.Transcript)
+		if writeErr := os.WriteFile(sessionFile, transcript, 0o600); writeErr != nil {
 			if totalSessions > 1 {
 				fmt.Fprintf(os.Stderr, "    Warning: failed to write transcript: %v\n", writeErr)
 				continue
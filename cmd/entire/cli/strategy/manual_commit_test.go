@@ -1911,6 +1911,42 @@ func TestExtractUserPrompts(t *testing.T) {
 {"type":"user","message":{"content":"Goodbye"}}`,
 			expected: []string{"Hello", "Goodbye"},
 		},
+		{
+			name:      "Cursor JSONL with user_query wrapper",
+			agentType: agent.AgentTypeCursor,
+			content: `{"role":"user","message":{"content":[{"type":"text","text":"<user_query>\nAdd tests\n</user_query>"}]}}
+{"role":"assistant","message":{"content":[{"type":"text","text":"Done"}]}}
+{"role":"user","message":{"content":[{"type":"text","text":"Thanks"}]}}`,
+			expected: []string{"Add tests", "Thanks"},
+		},
+		{
+			name:      "Codex rollout with user_message events",
+			agentType: agent.AgentTypeCodex,
+			content: `{"type":"session_meta","payload":{"id":"abc","cwd":"/repo"}}
+{"type":"event_msg","payload":{"type":"user_message","message":"Add tests"}}
+{"type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Done"}]}}
+{"type":"event_msg","payload":{"type":"user_message","message":"Thanks"}}`,
+			expected: []string{"Add tests", "Thanks"},
+		},
+		{
+			name:      "Aider chat history uses most recent session",
+			agentType: agent.AgentTypeAider,
+			content: `# aider chat started at 2025-01-01 09:00:00
+
+#### Old prompt
+
+Old answer.
+
+# aider chat started at 2025-01-02 10:00:00
+
+#### Add tests
+
+Done.
+
+#### Thanks
+`,
+			expected: []string{"Add tests", "Thanks"},
+		},
 		{
 			name:      "empty string",
 			agentType: agent.AgentTypeClaudeCode,
@@ -1935,6 +1971,53 @@ func TestExtractUserPrompts(t *testing.T) {
 	}
 }
 
+// TestExtractSessionDataFromLiveTranscript_AiderSession verifies that condensing
+// an Aider session keeps only its part of the shared chat history, even when a
+// later session has been appended to it.
+func TestExtractSessionDataFromLiveTranscript_AiderSession(t *testing.T) {
+	t.Parallel()
+
+	first := `# aider chat started at 2025-01-01 09:00:00
+
+#### Add tests
+
+Done.
+
+`
+	second := `# aider chat started at 2025-01-02 10:00:00
+
+#### Other task
+
+On it.
+`
+	historyPath := filepath.Join(t.TempDir(), ".aider.chat.history.md")
+	if err := os.WriteFile(historyPath, []byte(first+second), 0o644); err != nil {
+		t.Fatalf("failed to write chat history: %v", err)
+	}
+
+	s := &ManualCommitStrategy{}
+	data, err := s.extractSessionDataFromLiveTranscript(&SessionState{
+		SessionID:      "aider-2025-01-01T09-00-00",
+		AgentType:      agent.AgentTypeAider,
+		TranscriptPath: historyPath,
+	})
+	if err != nil {
+		t.Fatalf("extractSessionDataFromLiveTranscript() error = %v", err)
+	}
+	if string(data.Transcript) != first {
+		t.Errorf("Transcript = %q, want only the session's part %q", data.Transcript, first)
+	}
+	if data.TranscriptStart != 0 {
+		t.Errorf("TranscriptStart = %d, want 0", data.TranscriptStart)
+	}
+	if len(data.Prompts) != 1 || data.Prompts[0] != "Add tests" {
+		t.Errorf("Prompts = %v, want [Add tests]", data.Prompts)
+	}
+	if want := strings.Count(first+second, "\n"); data.FullTranscriptLines != want {
+		t.Errorf("FullTranscriptLines = %d, want %d (the whole file)", data.FullTranscriptLines, want)
+	}
+}
+
 // TestCondenseSession_IncludesInitialAttribution verifies that when manual-commit
 // condenses a session, it calculates InitialAttribution by comparing the shadow branch
 // (agent work) to HEAD (what was committed).
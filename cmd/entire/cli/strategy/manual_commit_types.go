@@ -58,6 +58,7 @@ type CondenseResult struct {
 type ExtractedSessionData struct {
 	Transcript          []byte   // Full transcript content for the session
 	FullTranscriptLines int      // Total line count in full transcript
+	TranscriptStart     int      // Line of the full transcript that Transcript starts at (see SessionTranscript)
 	Prompts             []string // All user prompts from this portion
 	Context             []byte   // Generated context.md content
 	FilesTouched        []string
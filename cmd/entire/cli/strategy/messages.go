@@ -3,6 +3,10 @@ package strategy
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
 )
 
 // MaxDescriptionLength is the maximum length for descriptions in commit messages
@@ -21,6 +25,28 @@ func TruncateDescription(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
+// formatSquashMessage formats the message of a commit squashing a session's
+// commits: the subject, the intro paragraph(s), the subjects of the squashed
+// commits as a list, and an Entire-Checkpoint trailer per checkpoint.
+func formatSquashMessage(subject, intro string, messages []string, cpIDs []id.CheckpointID) string {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\n\n%s\n\n", subject, intro)
+	for _, message := range messages {
+		fmt.Fprintf(&body, "- %s\n", strings.SplitN(message, "\n", 2)[0])
+	}
+	return trailers.SetCheckpoints(body.String(), cpIDs)
+}
+
+// sessionPromptSubject returns the first line of a session's first prompt,
+// truncated to fit a commit subject, or "" if the session state is gone.
+func sessionPromptSubject(sessionID string) string {
+	state, err := LoadSessionState(sessionID)
+	if err != nil || state == nil || state.FirstPrompt == "" {
+		return ""
+	}
+	return TruncateDescription(strings.TrimSpace(strings.SplitN(state.FirstPrompt, "\n", 2)[0]), 72)
+}
+
 // FormatSubagentEndMessage formats a commit message for when a subagent completes.
 // Format: "Completed '<agent-type>' agent: <description> (<tool-use-id>)"
 //
@@ -0,0 +1,398 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Checkpoint notes
+//
+// Some repositories reject commit messages with extra trailers. With
+// strategy_options.checkpoint_link set to "notes", the manual-commit strategy
+// still decides whether to link a commit by adding the Entire-Checkpoint
+// trailer in prepare-commit-msg, but the commit-msg hook takes it out again
+// and post-commit records the checkpoint ID in a refs/notes/entire note on
+// the new commit instead. Notes hold the same "Entire-Checkpoint: <id>" lines
+// as the trailer, are copied on amend and rebase by the post-rewrite hook, and
+// are pushed and fetched alongside entire/checkpoints/v1.
+
+// pendingCheckpointFileName is the file in the git dir holding the checkpoint
+// a commit in progress will be linked to with a note.
+const pendingCheckpointFileName = "entire-pending-checkpoint.json"
+
+// usesCheckpointNotes reports whether commits are linked to checkpoints with
+// notes rather than trailers.
+func usesCheckpointNotes() bool {
+	s, err := settings.Load()
+	if err != nil {
+		return false
+	}
+	return s.CheckpointLink() == settings.CheckpointLinkNotes
+}
+
+// CheckpointNotes holds the checkpoint IDs recorded in refs/notes/entire.
+// A nil *CheckpointNotes has no notes, so its methods only read trailers.
+type CheckpointNotes struct {
+	tip plumbing.Hash
+	ids map[plumbing.Hash][]id.CheckpointID
+}
+
+// LoadCheckpointNotes reads refs/notes/entire. Notes only add links to the
+// ones trailers provide, so a missing or unreadable notes ref yields no notes
+// rather than an error.
+func LoadCheckpointNotes(repo *git.Repository) *CheckpointNotes {
+	notes := &CheckpointNotes{ids: make(map[plumbing.Hash][]id.CheckpointID)}
+	ref, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true)
+	if err != nil {
+		return notes
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return notes
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return notes
+	}
+	notes.tip = ref.Hash()
+
+	// Notes trees fan out into directories named after leading hex digits
+	// of the annotated commit; the path without slashes is the hash.
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err != nil {
+			break // io.EOF, or a corrupt tree: keep what was read
+		}
+		if entry.Mode == filemode.Dir {
+			continue
+		}
+		hash := strings.ReplaceAll(name, "/", "")
+		if !plumbing.IsHash(hash) {
+			continue
+		}
+		blob, err := repo.BlobObject(entry.Hash)
+		if err != nil {
+			continue
+		}
+		content, err := readBlob(blob)
+		if err != nil {
+			continue
+		}
+		if cpIDs := trailers.ParseAllCheckpoints(content); len(cpIDs) > 0 {
+			notes.ids[plumbing.NewHash(hash)] = cpIDs
+		}
+	}
+	return notes
+}
+
+// ParseCheckpoint returns the checkpoint a commit is linked to: the first
+// Entire-Checkpoint trailer, or else the first checkpoint in its note.
+func (n *CheckpointNotes) ParseCheckpoint(commit *object.Commit) (id.CheckpointID, bool) {
+	if cpID, found := trailers.ParseCheckpoint(commit.Message); found {
+		return cpID, true
+	}
+	if cpIDs := n.noteCheckpoints(commit.Hash); len(cpIDs) > 0 {
+		return cpIDs[0], true
+	}
+	return id.EmptyCheckpointID, false
+}
+
+// ParseAllCheckpoints returns every checkpoint a commit is linked to by its
+// trailers and its note, deduplicated in that order.
+func (n *CheckpointNotes) ParseAllCheckpoints(commit *object.Commit) []id.CheckpointID {
+	cpIDs := trailers.ParseAllCheckpoints(commit.Message)
+	for _, cpID := range n.noteCheckpoints(commit.Hash) {
+		if !slices.Contains(cpIDs, cpID) {
+			cpIDs = append(cpIDs, cpID)
+		}
+	}
+	return cpIDs
+}
+
+// Tip returns the refs/notes/entire commit the notes were read from, or the
+// zero hash when there are no notes.
+func (n *CheckpointNotes) Tip() plumbing.Hash {
+	if n == nil {
+		return plumbing.ZeroHash
+	}
+	return n.tip
+}
+
+// Annotated returns the commits that have a checkpoint note.
+func (n *CheckpointNotes) Annotated() map[plumbing.Hash][]id.CheckpointID {
+	if n == nil {
+		return nil
+	}
+	return n.ids
+}
+
+// noteCheckpoints returns the checkpoints in a commit's note.
+func (n *CheckpointNotes) noteCheckpoints(hash plumbing.Hash) []id.CheckpointID {
+	if n == nil {
+		return nil
+	}
+	return n.ids[hash]
+}
+
+// AddCheckpointNote links a commit to checkpoints by adding them to its
+// refs/notes/entire note, keeping the checkpoints already in it.
+func AddCheckpointNote(ctx context.Context, repo *git.Repository, commitHash plumbing.Hash, cpIDs []id.CheckpointID) error {
+	existing := LoadCheckpointNotes(repo).noteCheckpoints(commitHash)
+	merged := slices.Clone(existing)
+	for _, cpID := range cpIDs {
+		if !slices.Contains(merged, cpID) {
+			merged = append(merged, cpID)
+		}
+	}
+	if len(merged) == len(existing) {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "notes", "--ref="+paths.CheckpointNotesRef, "add", "-f", "-F", "-", commitHash.String()) //nolint:gosec // commitHash is a parsed hash
+	cmd.Stdin = strings.NewReader(trailers.SetCheckpoints("", merged))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to add checkpoint note to %s: %s: %w", commitHash.String()[:7], strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// CopyCheckpointNotes copies the checkpoint notes of rewritten commits to the
+// commits that replaced them. git only copies notes refs listed in
+// notes.rewriteRef, so the post-rewrite hook does it for refs/notes/entire.
+func CopyCheckpointNotes(ctx context.Context, repo *git.Repository, rewrites []CommitRewrite) error {
+	notes := LoadCheckpointNotes(repo)
+	if len(notes.ids) == 0 {
+		return nil
+	}
+	copied := make(map[string][]id.CheckpointID)
+	var order []string
+	for _, rw := range rewrites {
+		cpIDs := notes.noteCheckpoints(plumbing.NewHash(rw.Old))
+		if len(cpIDs) == 0 {
+			continue
+		}
+		if _, ok := copied[rw.New]; !ok {
+			order = append(order, rw.New)
+		}
+		copied[rw.New] = append(copied[rw.New], cpIDs...)
+	}
+	for _, newHash := range order {
+		if err := AddCheckpointNote(ctx, repo, plumbing.NewHash(newHash), copied[newHash]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingCheckpoint is the checkpoint the commit in progress will be linked
+// to. Head is HEAD when the commit message was written, so post-commit can
+// tell the commit it belongs to from a later one after an aborted commit.
+type pendingCheckpoint struct {
+	CheckpointID id.CheckpointID `json:"checkpoint_id"`
+	Head         string          `json:"head,omitempty"`
+}
+
+// pendingCheckpointFile returns the path of the pending checkpoint file.
+func pendingCheckpointFile() (string, error) {
+	gitDir, err := GetGitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, pendingCheckpointFileName), nil
+}
+
+// savePendingCheckpoint records the checkpoint the commit in progress will be
+// linked to with a note.
+func savePendingCheckpoint(repo *git.Repository, cpID id.CheckpointID) error {
+	file, err := pendingCheckpointFile()
+	if err != nil {
+		return err
+	}
+	pending := pendingCheckpoint{CheckpointID: cpID}
+	if head, err := repo.Head(); err == nil {
+		pending.Head = head.Hash().String()
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return fmt.Errorf("failed to encode pending checkpoint: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write pending checkpoint: %w", err)
+	}
+	return nil
+}
+
+// clearPendingCheckpoint removes the pending checkpoint, if any.
+func clearPendingCheckpoint() {
+	if file, err := pendingCheckpointFile(); err == nil {
+		_ = os.Remove(file) //nolint:errcheck // Usually doesn't exist
+	}
+}
+
+// takePendingCheckpoint returns and clears the checkpoint pending for commit.
+// A checkpoint recorded for another commit, e.g. one that was aborted after
+// the commit-msg hook ran, is discarded.
+func takePendingCheckpoint(repo *git.Repository, commit *object.Commit) (id.CheckpointID, bool) {
+	file, err := pendingCheckpointFile()
+	if err != nil {
+		return id.EmptyCheckpointID, false
+	}
+	data, err := os.ReadFile(file) //nolint:gosec // Path is in the git dir
+	if err != nil {
+		return id.EmptyCheckpointID, false
+	}
+	clearPendingCheckpoint()
+
+	var pending pendingCheckpoint
+	if err := json.Unmarshal(data, &pending); err != nil || pending.CheckpointID.IsEmpty() {
+		return id.EmptyCheckpointID, false
+	}
+
+	// The commit was made on top of the recorded HEAD, or amended it.
+	switch {
+	case pending.Head == "":
+		if len(commit.ParentHashes) == 0 {
+			return pending.CheckpointID, true
+		}
+	case slices.Contains(commit.ParentHashes, plumbing.NewHash(pending.Head)):
+		return pending.CheckpointID, true
+	default:
+		if amended, err := repo.CommitObject(plumbing.NewHash(pending.Head)); err == nil && slices.Equal(amended.ParentHashes, commit.ParentHashes) {
+			return pending.CheckpointID, true
+		}
+	}
+	return id.EmptyCheckpointID, false
+}
+
+// readBlob returns a blob's content.
+func readBlob(blob *object.Blob) (string, error) {
+	r, err := blob.Reader()
+	if err != nil {
+		return "", fmt.Errorf("failed to read blob: %w", err)
+	}
+	defer func() { _ = r.Close() }()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read blob: %w", err)
+	}
+	return string(content), nil
+}
+
+// remoteCheckpointNotesRef returns the ref holding the last known
+// refs/notes/entire of a remote. It lives under refs/notes/ so git notes can
+// merge it.
+func remoteCheckpointNotesRef(remote string) string {
+	return "refs/notes/remotes/" + remote + "/entire"
+}
+
+// pushCheckpointNotes pushes refs/notes/entire alongside the user's push.
+// When the remote's notes have diverged they are fetched and merged first;
+// notes only ever list checkpoint IDs, so merging concatenates them.
+func pushCheckpointNotes(remote string) {
+	repo, err := OpenRepository()
+	if err != nil {
+		return
+	}
+	localRef, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true)
+	if err != nil {
+		return // No notes
+	}
+	if remoteRef, err := repo.Reference(plumbing.ReferenceName(remoteCheckpointNotesRef(remote)), true); err == nil && remoteRef.Hash() == localRef.Hash() {
+		return // Nothing new to push
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	fmt.Fprintf(os.Stderr, "[entire] Pushing checkpoint notes to %s...\n", remote)
+	if err := tryPushCheckpointNotes(ctx, remote); err != nil {
+		if err := fetchCheckpointNotes(ctx, remote); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: couldn't sync checkpoint notes: %v\n", err)
+			return
+		}
+		if err := tryPushCheckpointNotes(ctx, remote); err != nil {
+			fmt.Fprintf(os.Stderr, "[entire] Warning: failed to push checkpoint notes: %v\n", err)
+		}
+	}
+}
+
+// tryPushCheckpointNotes pushes refs/notes/entire and records what the remote
+// now has.
+func tryPushCheckpointNotes(ctx context.Context, remote string) error {
+	// Use --no-verify to prevent recursive hook calls
+	cmd := exec.CommandContext(ctx, "git", "push", "--no-verify", remote, paths.CheckpointNotesRef+":"+paths.CheckpointNotesRef)
+	cmd.Stdin = nil // Disconnect stdin to prevent hanging in hook context
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("push failed: %s", output)
+	}
+	cmd = exec.CommandContext(ctx, "git", "update-ref", remoteCheckpointNotesRef(remote), paths.CheckpointNotesRef) //nolint:gosec // remote is the pushed remote's name
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to record pushed notes: %s", output)
+	}
+	return nil
+}
+
+// fetchCheckpointNotes fetches the remote's refs/notes/entire and merges it
+// into the local notes.
+func fetchCheckpointNotes(ctx context.Context, remote string) error {
+	remoteRef := remoteCheckpointNotesRef(remote)
+	cmd := exec.CommandContext(ctx, "git", "fetch", remote, "+"+paths.CheckpointNotesRef+":"+remoteRef) //nolint:gosec // remote is the user's remote name
+	cmd.Stdin = nil
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("fetch failed: %s", output)
+	}
+
+	repo, err := OpenRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+	if _, err := repo.Reference(plumbing.ReferenceName(paths.CheckpointNotesRef), true); err != nil {
+		// No local notes yet: take the remote's
+		cmd = exec.CommandContext(ctx, "git", "update-ref", paths.CheckpointNotesRef, remoteRef) //nolint:gosec // remoteRef is built from the remote name
+	} else {
+		cmd = exec.CommandContext(ctx, "git", "notes", "--ref="+paths.CheckpointNotesRef, "merge", "--quiet", "--strategy=cat_sort_uniq", remoteRef) //nolint:gosec // remoteRef is built from the remote name
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to merge checkpoint notes: %s", output)
+	}
+	return nil
+}
+
+// FetchCheckpointNotes fetches refs/notes/entire from a remote and merges it
+// into the local notes. A remote without checkpoint notes is not an error.
+func FetchCheckpointNotes(ctx context.Context, remote string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--exit-code", remote, paths.CheckpointNotesRef) //nolint:gosec // remote is the user's remote name
+	cmd.Stdin = nil
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+			return nil // The remote has no checkpoint notes
+		}
+		return fmt.Errorf("failed to list remote checkpoint notes: %w", err)
+	}
+	return fetchCheckpointNotes(ctx, remote)
+}
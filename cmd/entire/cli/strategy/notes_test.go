@@ -0,0 +1,251 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitWithoutTrailer commits a change to main.go with a plain message.
+func commitWithoutTrailer(t *testing.T, repo *git.Repository, dir, content string) *object.Commit {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main // "+content+"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	runGit(t, "add", "main.go")
+	runGit(t, "commit", "-q", "-m", "Update main.go: "+content)
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to get HEAD: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatalf("failed to read HEAD: %v", err)
+	}
+	return commit
+}
+
+func initNotesTestRepo(t *testing.T) (*git.Repository, string) {
+	t.Helper()
+	repo, dir := initRepoWithSettings(t, `{"strategy": "manual-commit", "strategy_options": {"checkpoint_link": "notes"}}`)
+	runGit(t, "config", "user.name", "Test")
+	runGit(t, "config", "user.email", "test@example.com")
+	return repo, dir
+}
+
+func TestCheckpointNotes(t *testing.T) {
+	repo, dir := initNotesTestRepo(t)
+	ctx := context.Background()
+	if !usesCheckpointNotes() {
+		t.Fatal("usesCheckpointNotes() = false, want true")
+	}
+
+	noted := commitWithoutTrailer(t, repo, dir, "noted")
+	trailerID := id.MustCheckpointID("b0000000000a")
+	trailered := commitLinkedToCheckpoint(t, repo, dir, trailerID)
+	first := id.MustCheckpointID("b00000000001")
+	second := id.MustCheckpointID("b00000000002")
+	if err := AddCheckpointNote(ctx, repo, noted.Hash, []id.CheckpointID{first}); err != nil {
+		t.Fatalf("AddCheckpointNote() error = %v", err)
+	}
+	if err := AddCheckpointNote(ctx, repo, noted.Hash, []id.CheckpointID{second, first}); err != nil {
+		t.Fatalf("AddCheckpointNote() error = %v", err)
+	}
+	trailerCommit, err := repo.CommitObject(plumbing.NewHash(trailered))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	if err := AddCheckpointNote(ctx, repo, trailerCommit.Hash, []id.CheckpointID{second}); err != nil {
+		t.Fatalf("AddCheckpointNote() error = %v", err)
+	}
+
+	notes := LoadCheckpointNotes(repo)
+	if got, found := notes.ParseCheckpoint(noted); !found || got != first {
+		t.Errorf("ParseCheckpoint(noted) = %s, %v; want %s", got, found, first)
+	}
+	if got := notes.ParseAllCheckpoints(noted); !slices.Equal(got, []id.CheckpointID{first, second}) {
+		t.Errorf("ParseAllCheckpoints(noted) = %v, want [%s %s]", got, first, second)
+	}
+	// The trailer comes first.
+	if got, found := notes.ParseCheckpoint(trailerCommit); !found || got != trailerID {
+		t.Errorf("ParseCheckpoint(trailered) = %s, %v; want %s", got, found, trailerID)
+	}
+	if got := notes.ParseAllCheckpoints(trailerCommit); !slices.Equal(got, []id.CheckpointID{trailerID, second}) {
+		t.Errorf("ParseAllCheckpoints(trailered) = %v, want [%s %s]", got, trailerID, second)
+	}
+
+	// Without notes only trailers are read.
+	var none *CheckpointNotes
+	if _, found := none.ParseCheckpoint(noted); found {
+		t.Error("nil CheckpointNotes should not find a checkpoint in a commit without a trailer")
+	}
+
+	linked, err := LinkedCommits(repo)
+	if err != nil {
+		t.Fatalf("LinkedCommits() error = %v", err)
+	}
+	if !slices.Contains(linked[first], noted.Hash.String()) || !slices.Contains(linked[second], trailered) {
+		t.Errorf("LinkedCommits() = %v, want note-linked commits", linked)
+	}
+}
+
+func TestCheckpointIndex_LinksNotesAddedLater(t *testing.T) {
+	repo, dir := initNotesTestRepo(t)
+	ctx := context.Background()
+	noted := commitWithoutTrailer(t, repo, dir, "noted")
+
+	idx := newCheckpointIndex()
+	if _, err := idx.scanBranches(repo); err != nil {
+		t.Fatalf("scanBranches() error = %v", err)
+	}
+	cpID := id.MustCheckpointID("b10000000001")
+	if err := AddCheckpointNote(ctx, repo, noted.Hash, []id.CheckpointID{cpID}); err != nil {
+		t.Fatalf("AddCheckpointNote() error = %v", err)
+	}
+
+	// The branch tip didn't move, but the notes did.
+	linked, err := idx.scanBranches(repo)
+	if err != nil {
+		t.Fatalf("scanBranches() error = %v", err)
+	}
+	if !slices.Equal(linked, []id.CheckpointID{cpID}) || !slices.Equal(idx.CommitsFor(cpID), []string{noted.Hash.String()}) {
+		t.Errorf("scanBranches() linked %v, commits %v; want %s linked to %s", linked, idx.CommitsFor(cpID), cpID, noted.Hash)
+	}
+}
+
+func TestCopyCheckpointNotes(t *testing.T) {
+	repo, dir := initNotesTestRepo(t)
+	ctx := context.Background()
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+	first := commitWithoutTrailer(t, repo, dir, "first")
+	second := commitWithoutTrailer(t, repo, dir, "second")
+	a := id.MustCheckpointID("b20000000001")
+	b := id.MustCheckpointID("b20000000002")
+	if err := AddCheckpointNote(ctx, repo, first.Hash, []id.CheckpointID{a}); err != nil {
+		t.Fatalf("AddCheckpointNote() error = %v", err)
+	}
+	if err := AddCheckpointNote(ctx, repo, second.Hash, []id.CheckpointID{b}); err != nil {
+		t.Fatalf("AddCheckpointNote() error = %v", err)
+	}
+
+	// Squash both into one commit.
+	runGit(t, "reset", "-q", "--soft", "HEAD~2")
+	squashed := commitWithoutTrailer(t, repo, dir, "squashed")
+	rewrites := []CommitRewrite{
+		{Old: first.Hash.String(), New: squashed.Hash.String()},
+		{Old: second.Hash.String(), New: squashed.Hash.String()},
+	}
+	if err := CopyCheckpointNotes(ctx, repo, rewrites); err != nil {
+		t.Fatalf("CopyCheckpointNotes() error = %v", err)
+	}
+	if got := LoadCheckpointNotes(repo).ParseAllCheckpoints(squashed); !slices.Equal(got, []id.CheckpointID{a, b}) {
+		t.Errorf("squashed commit checkpoints = %v, want [%s %s]", got, a, b)
+	}
+}
+
+func TestCommitMsg_CheckpointNotes(t *testing.T) {
+	repo, dir := initNotesTestRepo(t)
+	base := commitWithoutTrailer(t, repo, dir, "base")
+	cpID := id.MustCheckpointID("b30000000001")
+
+	msgFile := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	if err := os.WriteFile(msgFile, []byte(trailers.FormatCheckpoint("Add feature", cpID)), 0o600); err != nil {
+		t.Fatalf("failed to write message: %v", err)
+	}
+	s := &ManualCommitStrategy{}
+	if err := s.CommitMsg(msgFile); err != nil {
+		t.Fatalf("CommitMsg() error = %v", err)
+	}
+	content, err := os.ReadFile(msgFile)
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	if _, found := trailers.ParseCheckpoint(string(content)); found || !strings.Contains(string(content), "Add feature") {
+		t.Errorf("CommitMsg() left message %q, want the trailer stripped", content)
+	}
+
+	// The pending checkpoint belongs to the next commit on top of base ...
+	child := commitWithoutTrailer(t, repo, dir, "child")
+	if got, ok := takePendingCheckpoint(repo, child); !ok || got != cpID {
+		t.Errorf("takePendingCheckpoint() = %s, %v; want %s", got, ok, cpID)
+	}
+	if _, ok := takePendingCheckpoint(repo, child); ok {
+		t.Error("takePendingCheckpoint() should clear the pending checkpoint")
+	}
+
+	// ... and not to a later commit after the one it was meant for was aborted.
+	runGit(t, "reset", "-q", "--hard", base.Hash.String())
+	if err := savePendingCheckpoint(repo, cpID); err != nil {
+		t.Fatalf("savePendingCheckpoint() error = %v", err)
+	}
+	commitWithoutTrailer(t, repo, dir, "intermediate")
+	later := commitWithoutTrailer(t, repo, dir, "later")
+	if _, ok := takePendingCheckpoint(repo, later); ok {
+		t.Error("takePendingCheckpoint() should discard a checkpoint pending for another commit")
+	}
+}
+
+func TestPushCheckpointNotes_MergesDivergedNotes(t *testing.T) {
+	repo, dir := initNotesTestRepo(t)
+	ctx := context.Background()
+	remoteDir := t.TempDir()
+	runGit(t, "init", "--bare", "-q", remoteDir)
+	runGit(t, "remote", "add", "origin", remoteDir)
+
+	local := commitWithoutTrailer(t, repo, dir, "local")
+	runGit(t, "push", "-q", "origin", "HEAD:refs/heads/main")
+	a := id.MustCheckpointID("b40000000001")
+	if err := AddCheckpointNote(ctx, repo, local.Hash, []id.CheckpointID{a}); err != nil {
+		t.Fatalf("AddCheckpointNote() error = %v", err)
+	}
+	pushCheckpointNotes("origin")
+
+	// Someone else adds a note to the same commit and pushes first.
+	otherDir := t.TempDir()
+	runGit(t, "clone", "-q", remoteDir, otherDir)
+	b := id.MustCheckpointID("b40000000002")
+	other := exec.CommandContext(ctx, "sh", "-c", `git fetch -q origin refs/notes/entire:refs/notes/entire &&
+		git -c user.name=Other -c user.email=other@example.com notes --ref=entire append -m "Entire-Checkpoint: `+b.String()+`" `+local.Hash.String()+` &&
+		git push -q origin refs/notes/entire`)
+	other.Dir = otherDir
+	if output, err := other.CombinedOutput(); err != nil {
+		t.Fatalf("failed to push other notes: %v\n%s", err, output)
+	}
+
+	c := id.MustCheckpointID("b40000000003")
+	if err := AddCheckpointNote(ctx, repo, local.Hash, []id.CheckpointID{c}); err != nil {
+		t.Fatalf("AddCheckpointNote() error = %v", err)
+	}
+	pushCheckpointNotes("origin")
+
+	remote, err := git.PlainOpen(remoteDir)
+	if err != nil {
+		t.Fatalf("failed to open remote: %v", err)
+	}
+	got := LoadCheckpointNotes(remote).ParseAllCheckpoints(local)
+	slices.Sort(got)
+	if want := []id.CheckpointID{a, b, c}; !slices.Equal(got, want) {
+		t.Errorf("remote notes = %v, want %v", got, want)
+	}
+
+	// Fetching brings the merged notes back to a clone without notes.
+	runGit(t, "update-ref", "-d", paths.CheckpointNotesRef)
+	if err := FetchCheckpointNotes(ctx, "origin"); err != nil {
+		t.Fatalf("FetchCheckpointNotes() error = %v", err)
+	}
+	if got := LoadCheckpointNotes(repo).ParseAllCheckpoints(local); len(got) != 3 {
+		t.Errorf("fetched notes = %v, want 3 checkpoints", got)
+	}
+}
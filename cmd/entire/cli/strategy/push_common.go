@@ -19,16 +19,23 @@ import (
 
 // pushSessionsBranchCommon is the shared implementation for pushing session branches.
 // Used by both manual-commit and auto-commit strategies.
-// By default, session logs are pushed automatically alongside user pushes.
+// By default, session logs and checkpoint notes are pushed automatically alongside user pushes.
 // Configuration (stored in .entire/settings.json under strategy_options.push_sessions):
 //   - false: disable automatic pushing
 //   - true or not set: push automatically (default)
 func pushSessionsBranchCommon(remote, branchName string) error {
-	// Check if pushing is disabled
 	if isPushSessionsDisabled() {
 		return nil
 	}
 
+	// Checkpoint notes link commits whichever store keeps the checkpoints
+	pushCheckpointNotes(remote)
+
+	// Check if checkpoints are not kept on a branch
+	if !usesMetadataBranch() {
+		return nil
+	}
+
 	repo, err := OpenRepository()
 	if err != nil {
 		return nil //nolint:nilerr // Hook must be silent on failure
@@ -125,10 +132,26 @@ func tryPushSessionsCommon(remote, branchName string) error {
 
 // fetchAndMergeSessionsCommon fetches remote sessions and merges into local using go-git.
 // Since session logs are append-only (unique cond-* directories), we just combine trees.
+//
+// Files the remote removed since we last fetched it (e.g. by `entire prune`)
+// are removed locally too unless they changed locally, so they don't come
+// back with the merge. When the remote history was rewritten, the merge only
+// has the remote parent, so the pruned history doesn't become reachable again.
 func fetchAndMergeSessionsCommon(remote, branchName string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
+	repo, err := OpenRepository()
+	if err != nil {
+		return fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	// Remember what the remote looked like before fetching
+	var lastFetched *object.Commit
+	if ref, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, branchName), true); err == nil {
+		lastFetched, _ = repo.CommitObject(ref.Hash()) //nolint:errcheck // Treated as never fetched
+	}
+
 	// Use git CLI for fetch (go-git's fetch can be tricky with auth)
 	fetchCmd := exec.CommandContext(ctx, "git", "fetch", remote, branchName)
 	fetchCmd.Stdin = nil
@@ -136,11 +159,6 @@ func fetchAndMergeSessionsCommon(remote, branchName string) error {
 		return fmt.Errorf("fetch failed: %s", output)
 	}
 
-	repo, err := OpenRepository()
-	if err != nil {
-		return fmt.Errorf("failed to open git repository: %w", err)
-	}
-
 	// Get local branch
 	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(branchName), true)
 	if err != nil {
@@ -175,35 +193,80 @@ func fetchAndMergeSessionsCommon(remote, branchName string) error {
 	if err := checkpoint.FlattenTree(repo, localTree, "", entries); err != nil {
 		return fmt.Errorf("failed to flatten local tree: %w", err)
 	}
-	if err := checkpoint.FlattenTree(repo, remoteTree, "", entries); err != nil {
+	remoteEntries := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, remoteTree, "", remoteEntries); err != nil {
 		return fmt.Errorf("failed to flatten remote tree: %w", err)
 	}
 
+	rewritten := false
+	if lastFetched != nil && lastFetched.Hash != remoteCommit.Hash {
+		if err := removeRemoteDeletions(repo, lastFetched, remoteEntries, entries); err != nil {
+			return err
+		}
+		isAncestor, err := lastFetched.IsAncestor(remoteCommit)
+		rewritten = err == nil && !isAncestor
+	}
+	for path, entry := range remoteEntries {
+		entries[path] = entry
+	}
+
 	// Build merged tree
 	mergedTreeHash, err := checkpoint.BuildTreeFromEntries(repo, entries)
 	if err != nil {
 		return fmt.Errorf("failed to build merged tree: %w", err)
 	}
 
-	// Create merge commit with both parents
-	mergeCommitHash, err := createMergeCommitCommon(repo, mergedTreeHash,
-		[]plumbing.Hash{localRef.Hash(), fetchHeadRef.Hash()},
-		"Merge remote session logs")
-	if err != nil {
-		return fmt.Errorf("failed to create merge commit: %w", err)
+	parents := []plumbing.Hash{localRef.Hash(), fetchHeadRef.Hash()}
+	if rewritten {
+		parents = []plumbing.Hash{fetchHeadRef.Hash()}
+	}
+
+	newHead := fetchHeadRef.Hash()
+	if !rewritten || mergedTreeHash != remoteCommit.TreeHash {
+		// Create merge commit with both parents
+		newHead, err = createMergeCommitCommon(ctx, repo, mergedTreeHash, parents, "Merge remote session logs")
+		if err != nil {
+			return fmt.Errorf("failed to create merge commit: %w", err)
+		}
 	}
 
 	// Update branch ref
-	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), mergeCommitHash)
+	newRef := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branchName), newHead)
 	if err := repo.Storer.SetReference(newRef); err != nil {
 		return fmt.Errorf("failed to update branch ref: %w", err)
 	}
 
+	// The merge brought in checkpoints from other machines
+	TryUpdateCheckpointIndex(ctx)
+
+	return nil
+}
+
+// removeRemoteDeletions removes from local the files that were in the
+// lastFetched remote commit but are gone from the remote now, unless they
+// changed locally since.
+func removeRemoteDeletions(repo *git.Repository, lastFetched *object.Commit, remote, local map[string]object.TreeEntry) error {
+	lastTree, err := lastFetched.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get previously fetched tree: %w", err)
+	}
+	lastEntries := make(map[string]object.TreeEntry)
+	if err := checkpoint.FlattenTree(repo, lastTree, "", lastEntries); err != nil {
+		return fmt.Errorf("failed to flatten previously fetched tree: %w", err)
+	}
+	for path, entry := range lastEntries {
+		if _, ok := remote[path]; ok {
+			continue
+		}
+		if localEntry, ok := local[path]; ok && localEntry.Hash == entry.Hash {
+			delete(local, path)
+		}
+	}
 	return nil
 }
 
 // createMergeCommitCommon creates a merge commit with multiple parents.
-func createMergeCommitCommon(repo *git.Repository, treeHash plumbing.Hash, parents []plumbing.Hash, message string) (plumbing.Hash, error) {
+func createMergeCommitCommon(ctx context.Context, repo *git.Repository, treeHash plumbing.Hash, parents []plumbing.Hash, message string) (plumbing.Hash, error) {
 	authorName, authorEmail := GetGitAuthorFromRepo(repo)
 	now := time.Now()
 	sig := object.Signature{
@@ -219,6 +282,9 @@ func createMergeCommitCommon(repo *git.Repository, treeHash plumbing.Hash, paren
 		Committer:    sig,
 		Message:      message,
 	}
+	if err := signMetadataCommit(ctx, commit); err != nil {
+		return plumbing.ZeroHash, err
+	}
 
 	obj := repo.Storer.NewEncodedObject()
 	if err := commit.Encode(obj); err != nil {
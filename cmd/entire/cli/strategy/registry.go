@@ -51,8 +51,9 @@ func List() []string {
 
 // Strategy name constants
 const (
-	StrategyNameManualCommit = "manual-commit"
-	StrategyNameAutoCommit   = "auto-commit"
+	StrategyNameManualCommit  = "manual-commit"
+	StrategyNameAutoCommit    = "auto-commit"
+	StrategyNameSessionBranch = "session-branch"
 )
 
 // DefaultStrategyName is the name of the default strategy.
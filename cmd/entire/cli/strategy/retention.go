@@ -0,0 +1,401 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Retention
+//
+// Committed checkpoints are kept forever by default. A retention policy in
+// settings lets `entire prune` remove checkpoints that are older than a
+// maximum age, or the oldest checkpoints once all of them exceed a maximum
+// size. Checkpoints referenced from the default branch can be kept
+// regardless, and pruning can drop only transcripts, which are most of a
+// checkpoint's size, keeping metadata, prompts and summaries.
+//
+// Pruning rewrites the history of entire/checkpoints/v1 so the removed
+// content is actually gone, which means the remote branch has to be
+// force-pushed. SyncMetadataBranchRemote merges the remote branch before
+// pruning so nothing pushed by others is lost, and PushPrunedMetadataBranch
+// pushes with --force-with-lease so a push that races with the prune is
+// rejected instead of overwritten.
+
+// RetentionPolicy is a parsed retention configuration.
+type RetentionPolicy struct {
+	MaxAge                    time.Duration // 0 means no age limit
+	MaxTotalSize              int64         // Bytes; 0 means no size limit
+	KeepLinkedToDefaultBranch bool
+	KeepSummaries             bool
+}
+
+// ParseRetentionPolicy parses retention settings. A nil s gives an empty policy.
+func ParseRetentionPolicy(s *settings.RetentionSettings) (RetentionPolicy, error) {
+	if s == nil {
+		return RetentionPolicy{}, nil
+	}
+	maxAge, err := parseRetentionAge(s.MaxAge)
+	if err != nil {
+		return RetentionPolicy{}, fmt.Errorf("invalid retention.max_age: %w", err)
+	}
+	maxSize, err := parseRetentionSize(s.MaxTotalSize)
+	if err != nil {
+		return RetentionPolicy{}, fmt.Errorf("invalid retention.max_total_size: %w", err)
+	}
+	return RetentionPolicy{
+		MaxAge:                    maxAge,
+		MaxTotalSize:              maxSize,
+		KeepLinkedToDefaultBranch: s.KeepLinkedToDefaultBranch,
+		KeepSummaries:             s.KeepSummaries,
+	}, nil
+}
+
+// IsEmpty reports whether the policy prunes nothing.
+func (p RetentionPolicy) IsEmpty() bool {
+	return p.MaxAge == 0 && p.MaxTotalSize == 0
+}
+
+// parseRetentionAge parses a number of days ("90d") or weeks ("12w"), or a Go
+// duration ("720h").
+func parseRetentionAge(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	unit, unitName := time.Duration(0), ""
+	switch {
+	case strings.HasSuffix(value, "d"):
+		unit, unitName = 24*time.Hour, "days"
+	case strings.HasSuffix(value, "w"):
+		unit, unitName = 7*24*time.Hour, "weeks"
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%q is not a positive number of %s", value, unitName)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%q is not an age like 90d, 12w or 720h", value)
+	}
+	return d, nil
+}
+
+// retentionSizeUnits are the size suffixes accepted by parseRetentionSize,
+// longest first so "MiB" isn't read as "B".
+var retentionSizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30},
+	{"KB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// parseRetentionSize parses a size like "500MB" or "2GiB". A plain number is
+// a number of bytes.
+func parseRetentionSize(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	number, unit := strings.TrimSpace(value), int64(1)
+	for _, u := range retentionSizeUnits {
+		if n, ok := strings.CutSuffix(strings.ToUpper(number), strings.ToUpper(u.suffix)); ok {
+			number, unit = strings.TrimSpace(n), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%q is not a size like 500MB or 2GiB", value)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// RetentionCandidate is a checkpoint selected for pruning.
+type RetentionCandidate struct {
+	CheckpointID id.CheckpointID
+	CreatedAt    time.Time
+	Size         checkpoint.CheckpointSize
+	Reason       string
+}
+
+// RetentionPlan is what applying a retention policy would do.
+type RetentionPlan struct {
+	// Delete are checkpoints to remove entirely.
+	Delete []RetentionCandidate
+
+	// DropTranscripts are checkpoints whose transcripts are removed.
+	DropTranscripts []RetentionCandidate
+
+	// Protected counts checkpoints kept because the default branch references them.
+	Protected int
+
+	// TotalSize is the size of all committed checkpoints before pruning.
+	TotalSize int64
+
+	// FreedSize is how much pruning removes.
+	FreedSize int64
+}
+
+// IsEmpty reports whether the plan prunes nothing.
+func (p *RetentionPlan) IsEmpty() bool {
+	return len(p.Delete) == 0 && len(p.DropTranscripts) == 0
+}
+
+// PruneOptions returns the checkpoint store options that carry out the plan.
+func (p *RetentionPlan) PruneOptions() checkpoint.PruneOptions {
+	var opts checkpoint.PruneOptions
+	for _, c := range p.Delete {
+		opts.Delete = append(opts.Delete, c.CheckpointID)
+	}
+	for _, c := range p.DropTranscripts {
+		opts.DropTranscripts = append(opts.DropTranscripts, c.CheckpointID)
+	}
+	return opts
+}
+
+// PlanRetention works out which committed checkpoints policy prunes.
+func PlanRetention(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore, policy RetentionPolicy, now time.Time) (*RetentionPlan, error) {
+	infos, err := store.ListCommitted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	sizes, err := store.CommittedSizes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure checkpoints: %w", err)
+	}
+	var protected map[id.CheckpointID]bool
+	if policy.KeepLinkedToDefaultBranch && !policy.IsEmpty() {
+		if protected, err = defaultBranchCheckpoints(repo); err != nil {
+			return nil, err
+		}
+	}
+	return planRetention(infos, sizes, protected, policy, now), nil
+}
+
+// planRetention selects checkpoints to prune, oldest first: those older than
+// the maximum age, then more until the rest fit the maximum total size.
+func planRetention(infos []checkpoint.CommittedInfo, sizes map[id.CheckpointID]checkpoint.CheckpointSize, protected map[id.CheckpointID]bool, policy RetentionPolicy, now time.Time) *RetentionPlan {
+	plan := &RetentionPlan{}
+	for _, size := range sizes {
+		plan.TotalSize += size.Total
+	}
+	if policy.IsEmpty() {
+		return plan
+	}
+
+	oldestFirst := make([]checkpoint.CommittedInfo, len(infos))
+	copy(oldestFirst, infos)
+	sort.SliceStable(oldestFirst, func(i, j int) bool {
+		return oldestFirst[i].CreatedAt.Before(oldestFirst[j].CreatedAt)
+	})
+
+	remaining := plan.TotalSize
+	for _, info := range oldestFirst {
+		var reason string
+		switch {
+		case policy.MaxAge > 0 && now.Sub(info.CreatedAt) > policy.MaxAge:
+			reason = "older than " + FormatRetentionAge(policy.MaxAge)
+		case policy.MaxTotalSize > 0 && remaining > policy.MaxTotalSize:
+			reason = "over the size limit"
+		default:
+			continue
+		}
+		if protected[info.CheckpointID] {
+			plan.Protected++
+			continue
+		}
+
+		size := sizes[info.CheckpointID]
+		candidate := RetentionCandidate{CheckpointID: info.CheckpointID, CreatedAt: info.CreatedAt, Size: size, Reason: reason}
+		if policy.KeepSummaries {
+			if size.Transcripts == 0 {
+				continue // Already pruned
+			}
+			plan.DropTranscripts = append(plan.DropTranscripts, candidate)
+			remaining -= size.Transcripts
+			plan.FreedSize += size.Transcripts
+		} else {
+			plan.Delete = append(plan.Delete, candidate)
+			remaining -= size.Total
+			plan.FreedSize += size.Total
+		}
+	}
+	return plan
+}
+
+// FormatRetentionAge formats a retention age in days when it is a whole number of days.
+func FormatRetentionAge(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	}
+	return d.String()
+}
+
+// defaultBranchCheckpoints returns the checkpoints referenced by the
+// Entire-Checkpoint trailers and notes of commits on the default branch.
+func defaultBranchCheckpoints(repo *git.Repository) (map[id.CheckpointID]bool, error) {
+	linked := make(map[id.CheckpointID]bool)
+	name := GetDefaultBranchName(repo)
+	if name == "" {
+		return linked, nil
+	}
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(name), true)
+	if err != nil {
+		ref, err = repo.Reference(plumbing.NewRemoteReferenceName("origin", name), true)
+		if err != nil {
+			return linked, nil //nolint:nilerr // No default branch yet
+		}
+	}
+
+	notes := LoadCheckpointNotes(repo)
+	seen := make(map[plumbing.Hash]bool)
+	queue := []plumbing.Hash{ref.Hash()}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			continue // Shallow clones lack older commits
+		}
+		for _, cpID := range notes.ParseAllCheckpoints(commit) {
+			linked[cpID] = true
+		}
+		queue = append(queue, commit.ParentHashes...)
+	}
+	return linked, nil
+}
+
+// ApplyRetention prunes the checkpoints in plan and updates the checkpoint index.
+func ApplyRetention(ctx context.Context, store checkpoint.CommittedStore, plan *RetentionPlan) error {
+	if plan.IsEmpty() {
+		return nil
+	}
+	if err := store.PruneCommitted(ctx, plan.PruneOptions()); err != nil {
+		return fmt.Errorf("failed to prune checkpoints: %w", err)
+	}
+	TryUpdateCheckpointIndex(ctx)
+	return nil
+}
+
+// ErrPruneLeaseRejected is returned when the remote checkpoints branch moved
+// between syncing with it and force-pushing the pruned branch.
+var ErrPruneLeaseRejected = errors.New("the remote checkpoints branch changed during pruning")
+
+// SyncMetadataBranchRemote makes the local checkpoints branch include the
+// remote one, so pruning doesn't drop checkpoints that were only pushed by
+// others. Returns the remote tip it synced with, for PushPrunedMetadataBranch,
+// or the zero hash when the remote has no checkpoints branch.
+func SyncMetadataBranchRemote(ctx context.Context, repo *git.Repository, remote string) (plumbing.Hash, error) {
+	remoteTip, err := lsRemoteMetadataBranch(ctx, remote)
+	if err != nil || remoteTip.IsZero() {
+		return plumbing.ZeroHash, err
+	}
+
+	localRef, err := repo.Reference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), true)
+	if err == nil && IsAncestorOf(repo, remoteTip, localRef.Hash()) {
+		return remoteTip, nil
+	}
+	if err != nil {
+		// No local branch yet: start from the remote one.
+		if err := fetchMetadataBranch(ctx, remote); err != nil {
+			return plumbing.ZeroHash, err
+		}
+		fetched, err := repo.Reference(plumbing.NewRemoteReferenceName(remote, paths.MetadataBranchName), true)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to read fetched checkpoints branch: %w", err)
+		}
+		ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(paths.MetadataBranchName), fetched.Hash())
+		if err := repo.Storer.SetReference(ref); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to create checkpoints branch: %w", err)
+		}
+		return fetched.Hash(), nil
+	}
+
+	if err := fetchAndMergeSessionsCommon(remote, paths.MetadataBranchName); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	fetchHead, err := repo.Reference(plumbing.ReferenceName("FETCH_HEAD"), true)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get FETCH_HEAD: %w", err)
+	}
+	return fetchHead.Hash(), nil
+}
+
+// PushPrunedMetadataBranch force-pushes the pruned checkpoints branch,
+// provided the remote branch is still at expected (absent if zero).
+func PushPrunedMetadataBranch(ctx context.Context, remote string, expected plumbing.Hash) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	lease := "--force-with-lease=refs/heads/" + paths.MetadataBranchName + ":"
+	if !expected.IsZero() {
+		lease += expected.String()
+	}
+	// Use --no-verify to prevent recursive hook calls
+	cmd := exec.CommandContext(ctx, "git", "push", "--no-verify", lease, remote,
+		"refs/heads/"+paths.MetadataBranchName+":refs/heads/"+paths.MetadataBranchName)
+	cmd.Stdin = nil
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "stale info") || strings.Contains(string(output), "rejected") {
+			return ErrPruneLeaseRejected
+		}
+		return fmt.Errorf("push failed: %s", output)
+	}
+	return nil
+}
+
+// lsRemoteMetadataBranch returns the remote's checkpoints branch tip, or the
+// zero hash if it has none.
+func lsRemoteMetadataBranch(ctx context.Context, remote string) (plumbing.Hash, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", remote, "refs/heads/"+paths.MetadataBranchName)
+	cmd.Stdin = nil
+	output, err := cmd.Output()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to query %s: %w", remote, err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return plumbing.ZeroHash, nil
+	}
+	return plumbing.NewHash(fields[0]), nil
+}
+
+// fetchMetadataBranch fetches the remote checkpoints branch into its
+// remote-tracking ref.
+func fetchMetadataBranch(ctx context.Context, remote string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	refSpec := "+refs/heads/" + paths.MetadataBranchName + ":refs/remotes/" + remote + "/" + paths.MetadataBranchName
+	cmd := exec.CommandContext(ctx, "git", "fetch", remote, refSpec)
+	cmd.Stdin = nil
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("fetch failed: %s", output)
+	}
+	return nil
+}
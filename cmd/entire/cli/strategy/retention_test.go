@@ -0,0 +1,239 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/settings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestParseRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	policy, err := ParseRetentionPolicy(&settings.RetentionSettings{MaxAge: "90d", MaxTotalSize: "1.5GiB", KeepSummaries: true})
+	if err != nil {
+		t.Fatalf("ParseRetentionPolicy() error = %v", err)
+	}
+	if policy.MaxAge != 90*24*time.Hour || policy.MaxTotalSize != 3<<29 || !policy.KeepSummaries {
+		t.Errorf("ParseRetentionPolicy() = %+v", policy)
+	}
+
+	tests := []struct {
+		age, size string
+		wantErr   bool
+	}{
+		{age: "2w"},
+		{age: "36h"},
+		{size: "500MB"},
+		{size: "500 kb"},
+		{size: "1048576"},
+		{age: "-3d", wantErr: true},
+		{age: "forever", wantErr: true},
+		{size: "lots", wantErr: true},
+		{size: "0MB", wantErr: true},
+	}
+	for _, tt := range tests {
+		_, err := ParseRetentionPolicy(&settings.RetentionSettings{MaxAge: tt.age, MaxTotalSize: tt.size})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRetentionPolicy(%q, %q) error = %v, wantErr %v", tt.age, tt.size, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPlanRetention(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	cp := func(n int) id.CheckpointID {
+		return id.MustCheckpointID("ae000000000" + string(rune('0'+n)))
+	}
+	// Checkpoint n was created n*30 days ago and is 100 bytes, 80 of them transcript.
+	var infos []checkpoint.CommittedInfo
+	sizes := make(map[id.CheckpointID]checkpoint.CheckpointSize)
+	for n := 1; n <= 4; n++ {
+		infos = append(infos, checkpoint.CommittedInfo{CheckpointID: cp(n), CreatedAt: now.AddDate(0, 0, -30*n)})
+		sizes[cp(n)] = checkpoint.CheckpointSize{Total: 100, Transcripts: 80}
+	}
+
+	ids := func(candidates []RetentionCandidate) []id.CheckpointID {
+		var out []id.CheckpointID
+		for _, c := range candidates {
+			out = append(out, c.CheckpointID)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		policy    RetentionPolicy
+		protected map[id.CheckpointID]bool
+		sizes     map[id.CheckpointID]checkpoint.CheckpointSize
+		delete    []id.CheckpointID
+		drop      []id.CheckpointID
+	}{
+		{name: "no policy"},
+		{
+			name:   "max age",
+			policy: RetentionPolicy{MaxAge: 75 * 24 * time.Hour},
+			delete: []id.CheckpointID{cp(4), cp(3)},
+		},
+		{
+			name:   "max size removes oldest first",
+			policy: RetentionPolicy{MaxTotalSize: 250},
+			delete: []id.CheckpointID{cp(4), cp(3)},
+		},
+		{
+			name:      "linked checkpoints are kept",
+			policy:    RetentionPolicy{MaxTotalSize: 250},
+			protected: map[id.CheckpointID]bool{cp(4): true},
+			delete:    []id.CheckpointID{cp(3), cp(2)},
+		},
+		{
+			name:   "keep summaries drops transcripts",
+			policy: RetentionPolicy{MaxTotalSize: 250, KeepSummaries: true},
+			drop:   []id.CheckpointID{cp(4), cp(3)},
+		},
+		{
+			name:   "already dropped transcripts are skipped",
+			policy: RetentionPolicy{MaxAge: 75 * 24 * time.Hour, KeepSummaries: true},
+			sizes:  map[id.CheckpointID]checkpoint.CheckpointSize{cp(4): {Total: 20}, cp(3): {Total: 100, Transcripts: 80}},
+			drop:   []id.CheckpointID{cp(3)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			planSizes := sizes
+			if tt.sizes != nil {
+				planSizes = tt.sizes
+			}
+			plan := planRetention(infos, planSizes, tt.protected, tt.policy, now)
+			if got := ids(plan.Delete); !slices.Equal(got, tt.delete) {
+				t.Errorf("Delete = %v, want %v", got, tt.delete)
+			}
+			if got := ids(plan.DropTranscripts); !slices.Equal(got, tt.drop) {
+				t.Errorf("DropTranscripts = %v, want %v", got, tt.drop)
+			}
+			if plan.Protected != len(tt.protected) {
+				t.Errorf("Protected = %d, want %d", plan.Protected, len(tt.protected))
+			}
+		})
+	}
+}
+
+func TestPlanRetention_KeepsCheckpointsLinkedToDefaultBranch(t *testing.T) {
+	repo, dir := initRepoWithSettings(t, "")
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	linked := id.MustCheckpointID("ae0000000011")
+	unlinked := id.MustCheckpointID("ae0000000012")
+	writeIndexTestCheckpoint(t, store, linked, "session-a")
+	writeIndexTestCheckpoint(t, store, unlinked, "session-b")
+	commitLinkedToCheckpoint(t, repo, dir, linked)
+
+	policy := RetentionPolicy{MaxAge: 24 * time.Hour, KeepLinkedToDefaultBranch: true}
+	plan, err := PlanRetention(context.Background(), repo, store, policy, time.Now().AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("PlanRetention() error = %v", err)
+	}
+	if len(plan.Delete) != 1 || plan.Delete[0].CheckpointID != unlinked || plan.Protected != 1 {
+		t.Fatalf("PlanRetention() = %+v, want only the unlinked checkpoint deleted", plan)
+	}
+
+	if err := ApplyRetention(context.Background(), store, plan); err != nil {
+		t.Fatalf("ApplyRetention() error = %v", err)
+	}
+	infos, err := store.ListCommitted(context.Background())
+	if err != nil || len(infos) != 1 || infos[0].CheckpointID != linked {
+		t.Errorf("ListCommitted() = %+v, %v; want only the linked checkpoint", infos, err)
+	}
+}
+
+func runGit(t *testing.T, args ...string) {
+	t.Helper()
+	cmd := exec.CommandContext(context.Background(), "git", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+}
+
+func TestPruneWithRemote(t *testing.T) {
+	repo, _ := initRepoWithSettings(t, "")
+	ctx := context.Background()
+	remoteDir := t.TempDir()
+	runGit(t, "init", "--bare", "-q", remoteDir)
+	runGit(t, "remote", "add", "origin", remoteDir)
+
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	kept := id.MustCheckpointID("af0000000001")
+	pruned := id.MustCheckpointID("af0000000002")
+	writeIndexTestCheckpoint(t, store, kept, "session-a")
+	writeIndexTestCheckpoint(t, store, pruned, "session-b")
+	runGit(t, "push", "-q", "origin", paths.MetadataBranchName)
+	branchRef := plumbing.NewBranchReferenceName(paths.MetadataBranchName)
+	oldTip, err := repo.Reference(branchRef, true)
+	if err != nil {
+		t.Fatalf("failed to read checkpoints branch: %v", err)
+	}
+
+	lease, err := SyncMetadataBranchRemote(ctx, repo, "origin")
+	if err != nil || lease != oldTip.Hash() {
+		t.Fatalf("SyncMetadataBranchRemote() = %s, %v; want %s", lease, err, oldTip.Hash())
+	}
+	if err := store.PruneCommitted(ctx, checkpoint.PruneOptions{Delete: []id.CheckpointID{pruned}}); err != nil {
+		t.Fatalf("PruneCommitted() error = %v", err)
+	}
+	if err := PushPrunedMetadataBranch(ctx, "origin", plumbing.ZeroHash); !errors.Is(err, ErrPruneLeaseRejected) {
+		t.Errorf("PushPrunedMetadataBranch() with a stale lease error = %v, want ErrPruneLeaseRejected", err)
+	}
+	if err := PushPrunedMetadataBranch(ctx, "origin", lease); err != nil {
+		t.Fatalf("PushPrunedMetadataBranch() error = %v", err)
+	}
+	prunedTip, err := repo.Reference(branchRef, true)
+	if err != nil {
+		t.Fatalf("failed to read checkpoints branch: %v", err)
+	}
+
+	// Another clone that still has the old history adds a checkpoint and
+	// merges the pruned remote branch before pushing.
+	for _, name := range []plumbing.ReferenceName{branchRef, plumbing.NewRemoteReferenceName("origin", paths.MetadataBranchName)} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(name, oldTip.Hash())); err != nil {
+			t.Fatalf("failed to reset %s: %v", name, err)
+		}
+	}
+	added := id.MustCheckpointID("af0000000003")
+	writeIndexTestCheckpoint(t, store, added, "session-c")
+	if err := fetchAndMergeSessionsCommon("origin", paths.MetadataBranchName); err != nil {
+		t.Fatalf("fetchAndMergeSessionsCommon() error = %v", err)
+	}
+
+	for cpID, want := range map[id.CheckpointID]bool{kept: true, pruned: false, added: true} {
+		summary, err := store.ReadCommitted(ctx, cpID)
+		if err != nil || (summary != nil) != want {
+			t.Errorf("ReadCommitted(%s) = %v, %v; want present = %v", cpID, summary, err, want)
+		}
+	}
+	merged, err := repo.Reference(branchRef, true)
+	if err != nil {
+		t.Fatalf("failed to read checkpoints branch: %v", err)
+	}
+	mergeCommit, err := repo.CommitObject(merged.Hash())
+	if err != nil {
+		t.Fatalf("failed to read merge commit: %v", err)
+	}
+	if len(mergeCommit.ParentHashes) != 1 || mergeCommit.ParentHashes[0] != prunedTip.Hash() {
+		t.Errorf("merge parents = %v, want only the pruned remote tip %s", mergeCommit.ParentHashes, prunedTip.Hash())
+	}
+}
@@ -0,0 +1,248 @@
+package strategy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// History rewrites
+//
+// git runs the post-rewrite hook after `git commit --amend` and `git rebase`,
+// passing the rewrite type as its argument and "<old-sha> <new-sha>" lines on
+// stdin. When a rebase squashes commits (squash, fixup, or `git commit
+// --fixup` with --autosquash) several old commits map to one new commit, and
+// the Entire-Checkpoint trailers of all but the first are usually lost
+// (fixup drops the message) or scattered through the combined message
+// (squash). MergeSquashedCheckpoints puts them back on the surviving commit.
+
+// Rewrite types passed to the post-rewrite hook.
+const (
+	RewriteAmend  = "amend"
+	RewriteRebase = "rebase"
+)
+
+// CommitRewrite records that commit Old was rewritten as commit New.
+type CommitRewrite struct {
+	Old string
+	New string
+}
+
+// ParseCommitRewrites parses the mapping git passes to the post-rewrite hook
+// on stdin: one "<old-sha> <new-sha> [extra-info]" line per rewritten commit.
+func ParseCommitRewrites(r io.Reader) ([]CommitRewrite, error) {
+	var rewrites []CommitRewrite
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 || !plumbing.IsHash(fields[0]) || !plumbing.IsHash(fields[1]) {
+			return nil, fmt.Errorf("invalid rewrite line %q", scanner.Text())
+		}
+		rewrites = append(rewrites, CommitRewrite{Old: fields[0], New: fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rewrites: %w", err)
+	}
+	return rewrites, nil
+}
+
+// MergeSquashedCheckpoints gives every commit that several commits were
+// squashed into one Entire-Checkpoint trailer per checkpoint of the squashed
+// commits, its own first. The fixed commits and their descendants on HEAD's
+// first-parent chain are re-created with the same tree, author and committer
+// (signed if git is configured to sign commits) and HEAD is moved to the new
+// tip.
+//
+// Returns the rewrites with New replaced by the final commit, followed by a
+// rewrite for each commit this re-created, so callers see every stale hash.
+// Rewrites of other types are returned unchanged: after an amend, a missing
+// trailer was removed by the user. When commits are linked with notes, the
+// checkpoints are added to the surviving commit's note instead and no commit
+// is re-created.
+func MergeSquashedCheckpoints(ctx context.Context, repo *git.Repository, rewriteType string, rewrites []CommitRewrite) ([]CommitRewrite, error) {
+	if rewriteType != RewriteRebase {
+		return rewrites, nil
+	}
+
+	squashed := make(map[string][]string)
+	var order []string
+	for _, rw := range rewrites {
+		if _, ok := squashed[rw.New]; !ok {
+			order = append(order, rw.New)
+		}
+		squashed[rw.New] = append(squashed[rw.New], rw.Old)
+	}
+
+	notes := usesCheckpointNotes()
+	messages := make(map[plumbing.Hash]string)
+	rebased := make(map[plumbing.Hash]bool, len(order))
+	for _, newHash := range order {
+		rebased[plumbing.NewHash(newHash)] = true
+	}
+	for _, newHash := range order {
+		olds := squashed[newHash]
+		if len(olds) < 2 {
+			continue
+		}
+		commit, err := repo.CommitObject(plumbing.NewHash(newHash))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", newHash, err)
+		}
+		cpIDs := trailers.ParseAllCheckpoints(commit.Message)
+		for _, oldHash := range olds {
+			old, err := repo.CommitObject(plumbing.NewHash(oldHash))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read commit %s: %w", oldHash, err)
+			}
+			for _, cpID := range trailers.ParseAllCheckpoints(old.Message) {
+				if !slices.Contains(cpIDs, cpID) {
+					cpIDs = append(cpIDs, cpID)
+				}
+			}
+		}
+		if notes {
+			// Commit messages must not change: link the surviving commit
+			// with a note instead.
+			if err := AddCheckpointNote(ctx, repo, commit.Hash, cpIDs); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if message := trailers.SetCheckpoints(commit.Message, cpIDs); message != commit.Message {
+			messages[commit.Hash] = message
+		}
+	}
+	if len(messages) == 0 {
+		return rewrites, nil
+	}
+
+	recreated, err := rewriteFirstParentChain(ctx, repo, messages, rebased)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CommitRewrite, 0, len(rewrites)+len(recreated))
+	for _, rw := range rewrites {
+		if final, ok := recreated[rw.New]; ok {
+			rw.New = final
+		}
+		result = append(result, rw)
+	}
+	for _, newHash := range sortedKeys(recreated) {
+		result = append(result, CommitRewrite{Old: newHash, New: recreated[newHash]})
+	}
+	return result, nil
+}
+
+// rewriteFirstParentChain re-creates the commits on HEAD's first-parent chain
+// from the oldest commit in messages up to HEAD, replacing the messages of the
+// commits in messages, and moves HEAD to the new tip. Commits in messages that
+// aren't on the chain are left alone. rebased holds every commit the rebase
+// created, messages' keys among them: the walk stops below the oldest one on
+// the chain rather than at the root commit. Returns a map from the replaced
+// commits to their replacements.
+func rewriteFirstParentChain(ctx context.Context, repo *git.Repository, messages map[plumbing.Hash]string, rebased map[plumbing.Hash]bool) (map[string]string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	// Walk back until every commit to fix has been seen, or past the rebased
+	// commits (a commit to fix may be off the first-parent chain, e.g. after
+	// --rebase-merges), keeping the chain up to the oldest one found.
+	var chain []*object.Commit
+	keep, found := 0, 0
+	inRebased := false
+	hash := head.Hash()
+	for found < len(messages) {
+		if rebased[hash] {
+			inRebased = true
+		} else if inRebased {
+			break // Below the oldest rebased commit
+		}
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			break // Shallow clones lack older commits
+		}
+		chain = append(chain, commit)
+		if _, ok := messages[hash]; ok {
+			found++
+			keep = len(chain)
+		}
+		if len(commit.ParentHashes) == 0 {
+			break
+		}
+		hash = commit.ParentHashes[0]
+	}
+	chain = chain[:keep]
+	if len(chain) == 0 {
+		return nil, nil //nolint:nilnil // Nothing on the chain to rewrite
+	}
+
+	signer, err := LoadCommitSigner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replaced := make(map[plumbing.Hash]plumbing.Hash)
+	for i := len(chain) - 1; i >= 0; i-- {
+		commit := chain[i]
+		rewritten := &object.Commit{
+			Author:       commit.Author,
+			Committer:    commit.Committer,
+			MergeTag:     commit.MergeTag,
+			Message:      commit.Message,
+			TreeHash:     commit.TreeHash,
+			ParentHashes: make([]plumbing.Hash, len(commit.ParentHashes)),
+			Encoding:     commit.Encoding,
+		}
+		if message, ok := messages[commit.Hash]; ok {
+			rewritten.Message = message
+		}
+		for j, parent := range commit.ParentHashes {
+			if newParent, ok := replaced[parent]; ok {
+				parent = newParent
+			}
+			rewritten.ParentHashes[j] = parent
+		}
+		if err := signer.Sign(ctx, rewritten); err != nil {
+			return nil, fmt.Errorf("failed to sign commit: %w", err)
+		}
+		obj := repo.Storer.NewEncodedObject()
+		if err := rewritten.Encode(obj); err != nil {
+			return nil, fmt.Errorf("failed to encode commit: %w", err)
+		}
+		newHash, err := repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store commit: %w", err)
+		}
+		replaced[commit.Hash] = newHash
+	}
+
+	// Let git move HEAD, so the branch it points at and the reflogs are
+	// updated, and a concurrent change to HEAD is detected.
+	newTip := replaced[head.Hash()]
+	cmd := exec.CommandContext(ctx, "git", "update-ref", "-m", "entire: merge checkpoint trailers of squashed commits", "HEAD", newTip.String(), head.Hash().String()) //nolint:gosec // Hashes are generated above
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to update HEAD: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+
+	result := make(map[string]string, len(replaced))
+	for oldHash, newHash := range replaced {
+		result[oldHash.String()] = newHash.String()
+	}
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestParseCommitRewrites(t *testing.T) {
+	t.Parallel()
+
+	oldHash := strings.Repeat("a", 40)
+	newHash := strings.Repeat("b", 40)
+	rewrites, err := ParseCommitRewrites(strings.NewReader(oldHash + " " + newHash + "\n\n" + newHash + " " + oldHash + " extra\n"))
+	if err != nil {
+		t.Fatalf("ParseCommitRewrites() error = %v", err)
+	}
+	want := []CommitRewrite{{Old: oldHash, New: newHash}, {Old: newHash, New: oldHash}}
+	if !slices.Equal(rewrites, want) {
+		t.Errorf("ParseCommitRewrites() = %v, want %v", rewrites, want)
+	}
+
+	if _, err := ParseCommitRewrites(strings.NewReader("not-a-hash " + newHash + "\n")); err == nil {
+		t.Error("ParseCommitRewrites() should reject invalid lines")
+	}
+}
+
+func TestMergeSquashedCheckpoints(t *testing.T) {
+	repo, dir := initRepoWithSettings(t, "")
+	ctx := context.Background()
+	runGit(t, "config", "user.name", "Test")
+	runGit(t, "config", "user.email", "test@example.com")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+
+	first := id.MustCheckpointID("7e0000000001")
+	fixup := id.MustCheckpointID("7e0000000002")
+	last := id.MustCheckpointID("7e0000000003")
+	oldFirst := commitLinkedToCheckpoint(t, repo, dir, first)
+	oldFixup := commitLinkedToCheckpoint(t, repo, dir, fixup)
+	oldLast := commitLinkedToCheckpoint(t, repo, dir, last)
+
+	// Turn the second commit into a fixup of the first: git drops its
+	// message, and with it the checkpoint trailer.
+	cmd := exec.CommandContext(ctx, "git", "rebase", "-q", "-i", "HEAD~3")
+	cmd.Env = append(os.Environ(), "GIT_SEQUENCE_EDITOR=sed -i -e 2s/^pick/fixup/")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git rebase failed: %v\n%s", err, output)
+	}
+	rebasedLast := headHash(t, repo)
+	rebasedFirst := parentHash(t, repo, rebasedLast)
+	rewrites := []CommitRewrite{
+		{Old: oldFirst, New: rebasedFirst},
+		{Old: oldFixup, New: rebasedFirst},
+		{Old: oldLast, New: rebasedLast},
+	}
+
+	// An amend is left alone.
+	got, err := MergeSquashedCheckpoints(ctx, repo, RewriteAmend, rewrites)
+	if err != nil || !slices.Equal(got, rewrites) || headHash(t, repo) != rebasedLast {
+		t.Fatalf("MergeSquashedCheckpoints(amend) = %v, %v; want rewrites unchanged", got, err)
+	}
+
+	got, err = MergeSquashedCheckpoints(ctx, repo, RewriteRebase, rewrites)
+	if err != nil {
+		t.Fatalf("MergeSquashedCheckpoints() error = %v", err)
+	}
+	finalLast := headHash(t, repo)
+	finalFirst := parentHash(t, repo, finalLast)
+	if finalLast == rebasedLast || finalFirst == rebasedFirst {
+		t.Fatal("MergeSquashedCheckpoints() should re-create the squashed commit and its descendants")
+	}
+	want := []CommitRewrite{
+		{Old: oldFirst, New: finalFirst},
+		{Old: oldFixup, New: finalFirst},
+		{Old: oldLast, New: finalLast},
+		{Old: rebasedFirst, New: finalFirst},
+		{Old: rebasedLast, New: finalLast},
+	}
+	slices.SortFunc(got[3:], func(a, b CommitRewrite) int { return strings.Compare(a.Old, b.Old) })
+	slices.SortFunc(want[3:], func(a, b CommitRewrite) int { return strings.Compare(a.Old, b.Old) })
+	if !slices.Equal(got, want) {
+		t.Errorf("MergeSquashedCheckpoints() = %v, want %v", got, want)
+	}
+
+	firstCommit, err := repo.CommitObject(plumbing.NewHash(finalFirst))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	if ids := trailers.ParseAllCheckpoints(firstCommit.Message); !slices.Equal(ids, []id.CheckpointID{first, fixup}) {
+		t.Errorf("squashed commit checkpoints = %v, want [%s %s]", ids, first, fixup)
+	}
+	rebased, err := repo.CommitObject(plumbing.NewHash(rebasedLast))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	lastCommit, err := repo.CommitObject(plumbing.NewHash(finalLast))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	if lastCommit.Message != rebased.Message || lastCommit.TreeHash != rebased.TreeHash || lastCommit.Committer != rebased.Committer {
+		t.Error("descendants should keep their message, tree and committer")
+	}
+
+	// The index links both checkpoints to the surviving commit.
+	linked, err := LinkedCommits(repo)
+	if err != nil {
+		t.Fatalf("LinkedCommits() error = %v", err)
+	}
+	if !slices.Contains(linked[fixup], finalFirst) {
+		t.Errorf("LinkedCommits()[%s] = %v, want it to include %s", fixup, linked[fixup], finalFirst)
+	}
+}
+
+func headHash(t *testing.T, repo *git.Repository) string {
+	t.Helper()
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatalf("failed to get HEAD: %v", err)
+	}
+	return head.Hash().String()
+}
+
+func parentHash(t *testing.T, repo *git.Repository, hash string) string {
+	t.Helper()
+	commit, err := repo.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		t.Fatalf("failed to read commit: %v", err)
+	}
+	return commit.ParentHashes[0].String()
+}
+
+func TestRewriteFirstParentChain_TargetOffChain(t *testing.T) {
+	repo, dir := initRepoWithSettings(t, "")
+	ctx := context.Background()
+	runGit(t, "config", "user.name", "Test")
+	runGit(t, "config", "user.email", "test@example.com")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "Initial commit")
+
+	// A side branch merged into HEAD: its commit is off the first-parent
+	// chain, as a squash target can be after --rebase-merges.
+	runGit(t, "checkout", "-q", "-b", "side")
+	side := commitLinkedToCheckpoint(t, repo, dir, id.MustCheckpointID("7e0000000011"))
+	runGit(t, "checkout", "-q", "-")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "Main commit")
+	mainCommit := headHash(t, repo)
+	runGit(t, "merge", "-q", "--no-ff", "--no-edit", "side")
+	merge := headHash(t, repo)
+
+	rebased := map[plumbing.Hash]bool{
+		plumbing.NewHash(side):       true,
+		plumbing.NewHash(mainCommit): true,
+		plumbing.NewHash(merge):      true,
+	}
+	messages := map[plumbing.Hash]string{plumbing.NewHash(side): "Rewritten\n"}
+	recreated, err := rewriteFirstParentChain(ctx, repo, messages, rebased)
+	if err != nil {
+		t.Fatalf("rewriteFirstParentChain() error = %v", err)
+	}
+	if len(recreated) != 0 || headHash(t, repo) != merge {
+		t.Errorf("rewriteFirstParentChain() = %v, want nothing re-created and HEAD left at %s", recreated, merge)
+	}
+}
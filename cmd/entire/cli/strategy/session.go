@@ -1,6 +1,7 @@
 package strategy
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -11,8 +12,6 @@ import (
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 	"github.com/entireio/cli/cmd/entire/cli/paths"
-
-	"github.com/go-git/go-git/v5"
 )
 
 // NoDescription is the default description for sessions without one.
@@ -92,8 +91,12 @@ func ListSessions() ([]Session, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to open git repository: %w", err)
 	}
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get checkpoints from the entire/checkpoints/v1 branch
+	// Get committed checkpoints from the checkpoint store
 	checkpoints, err := ListCheckpoints()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
@@ -125,7 +128,7 @@ func ListSessions() ([]Session, error) {
 				})
 			} else {
 				// Get description from the checkpoint tree
-				description := getDescriptionForCheckpoint(repo, cp.CheckpointID)
+				description := getDescriptionForCheckpoint(store, cp.CheckpointID)
 
 				sessionMap[sessionID] = &Session{
 					ID:          sessionID,
@@ -216,33 +219,19 @@ func GetSession(sessionID string) (*Session, error) {
 	return findSessionByID(sessions, sessionID)
 }
 
-// getDescriptionForCheckpoint reads the description for a checkpoint from the entire/checkpoints/v1 branch.
+// getDescriptionForCheckpoint reads the description for a checkpoint from the checkpoint store.
 // It reads from the latest session subdirectory in the new storage format.
-func getDescriptionForCheckpoint(repo *git.Repository, checkpointID id.CheckpointID) string {
-	tree, err := GetMetadataBranchTree(repo)
-	if err != nil {
-		return NoDescription
-	}
-
-	// Get the checkpoint tree
-	checkpointTree, err := tree.Tree(checkpointID.Path())
-	if err != nil {
-		return NoDescription
-	}
+func getDescriptionForCheckpoint(store checkpoint.CommittedStore, checkpointID id.CheckpointID) string {
+	readFile := storeFileReader(context.Background(), store)
 
 	// Read root metadata.json to get session count and sessions map
-	metadataFile, err := checkpointTree.File(paths.MetadataFileName)
-	if err != nil {
-		return NoDescription
-	}
-
-	content, err := metadataFile.Contents()
+	content, err := readFile(checkpointID.Path() + "/" + paths.MetadataFileName)
 	if err != nil {
 		return NoDescription
 	}
 
 	var summary checkpoint.CheckpointSummary
-	if err := json.Unmarshal([]byte(content), &summary); err != nil {
+	if err := json.Unmarshal(content, &summary); err != nil {
 		return NoDescription
 	}
 
@@ -253,12 +242,7 @@ func getDescriptionForCheckpoint(repo *git.Repository, checkpointID id.Checkpoin
 		sessionDir = strconv.Itoa(len(summary.Sessions) - 1) // Use latest session
 	}
 
-	sessionTree, err := checkpointTree.Tree(sessionDir)
-	if err != nil {
-		return NoDescription
-	}
-
-	return getSessionDescriptionFromTree(sessionTree, "")
+	return readSessionDescription(readFile, checkpointID.Path()+"/"+sessionDir)
 }
 
 // findSessionByID finds a session by exact ID or prefix match.
@@ -0,0 +1,823 @@
+package strategy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/session"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// sessionWorktreesDir is the directory in the git common dir that holds the
+// worktrees of session work branches.
+const sessionWorktreesDir = "entire-worktrees"
+
+// ErrNoSessionBranch is returned when no session work branch matches.
+var ErrNoSessionBranch = errors.New("no session branch found")
+
+// ErrNothingToAccept is returned when a session's work branch has no changes
+// that aren't already on the current branch.
+var ErrNothingToAccept = errors.New("session has no changes to accept")
+
+// SessionBranchStrategy implements the session-branch strategy:
+// - Each session gets its own entire/work/<session-id> branch, checked out in a
+// dedicated worktree under .git/entire-worktrees/
+// - At the end of every turn, the files the agent touched are copied into that
+// worktree and committed there with an Entire-Checkpoint trailer (like auto-commit
+// does on the active branch), with metadata on entire/checkpoints/v1
+// - Sessions sharing a checkout aren't isolated from each other: a file another
+// live session already copied from that checkout is left out, and agents that
+// need full isolation run in their session's worktree, where nothing is copied
+// - `entire accept` squashes (or rebases) the session onto the user's branch, and
+// `entire discard` throws the whole session away
+//
+// This keeps auto-commit's per-turn history on the work branch while the user's
+// branch only gets the commits they accept, like with manual-commit.
+type SessionBranchStrategy struct {
+	AutoCommitStrategy
+}
+
+// NewSessionBranchStrategy creates a new SessionBranchStrategy instance
+func NewSessionBranchStrategy() Strategy { //nolint:ireturn // already present in codebase
+	return &SessionBranchStrategy{}
+}
+
+func (s *SessionBranchStrategy) Name() string {
+	return StrategyNameSessionBranch
+}
+
+func (s *SessionBranchStrategy) Description() string {
+	return "Commits each session to its own entire/work/<session> branch, applied with 'entire accept'"
+}
+
+// SaveChanges copies the files changed during the turn into the session's
+// worktree and commits them to its work branch.
+func (s *SessionBranchStrategy) SaveChanges(ctx SaveContext) error {
+	sessionID := ctx.SessionID
+	if sessionID == "" {
+		sessionID = filepath.Base(ctx.MetadataDir)
+	}
+	repo, err := syncSessionWorktree(sessionID, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles)
+	if err != nil {
+		return err
+	}
+	return s.saveChanges(repo, ctx, StrategyNameSessionBranch)
+}
+
+// SaveTaskCheckpoint copies the files changed by the subagent into the
+// session's worktree and commits them to its work branch.
+func (s *SessionBranchStrategy) SaveTaskCheckpoint(ctx TaskCheckpointContext) error {
+	repo, err := syncSessionWorktree(ctx.SessionID, ctx.ModifiedFiles, ctx.NewFiles, ctx.DeletedFiles)
+	if err != nil {
+		return err
+	}
+	return s.saveTaskCheckpoint(repo, ctx, StrategyNameSessionBranch)
+}
+
+// InitializeSession creates session state like auto-commit does and makes
+// sure the session's work branch and worktree exist.
+func (s *SessionBranchStrategy) InitializeSession(sessionID string, agentType agent.AgentType, transcriptPath string, userPrompt string) error {
+	if err := s.AutoCommitStrategy.InitializeSession(sessionID, agentType, transcriptPath, userPrompt); err != nil {
+		return err
+	}
+	branch := WorkBranchName(sessionID)
+	worktreePath, created, err := ensureWorktreeForBranch(context.Background(), branch)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Fprintf(os.Stderr, "Session changes will be committed to %s (worktree: %s)\n", branch, worktreePath)
+		fmt.Fprintf(os.Stderr, "To keep them apart from other sessions, run the agent in the worktree: cd %s\n", worktreePath)
+	}
+	return nil
+}
+
+// ListOrphanedItems returns no items: work branches and their worktrees are
+// removed by `entire accept` and `entire discard`, and the checkpoints they
+// reference are covered by auto-commit's orphan detection.
+func (s *SessionBranchStrategy) ListOrphanedItems() ([]CleanupItem, error) {
+	return []CleanupItem{}, nil
+}
+
+// HandleSessionEnd does nothing: a session branch is squashed when it is
+// accepted, not when the session ends.
+func (s *SessionBranchStrategy) HandleSessionEnd(_ *session.State) error {
+	return nil
+}
+
+// WorkBranchName returns the branch the session-branch strategy commits the
+// given session to. Characters other than ASCII letters, digits, '-' and '_'
+// are replaced with '-' so any session ID makes a valid branch name.
+func WorkBranchName(sessionID string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, sessionID)
+	return paths.WorkBranchPrefix + name
+}
+
+// syncSessionWorktree copies the given files from the current worktree into
+// the session's worktree, creating it if needed, and opens it. Nothing is
+// copied when the agent works in the session's worktree itself.
+//
+// Files another live session copied from the same checkout are skipped, as
+// their content there can't be told apart; the copied files are recorded in
+// the session's state so other sessions skip them in turn.
+func syncSessionWorktree(sessionID string, modified, newFiles, deleted []string) (*git.Repository, error) {
+	worktreePath, _, err := ensureWorktreeForBranch(context.Background(), WorkBranchName(sessionID))
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := GetWorktreePath()
+	if err != nil {
+		return nil, err
+	}
+	if !sameDir(repoRoot, worktreePath) {
+		claimed, err := filesOfOtherSessions(context.Background(), sessionID, repoRoot)
+		if err != nil {
+			return nil, err
+		}
+		var skipped []string
+		unclaimed := func(files []string) []string {
+			var kept []string
+			for _, file := range files {
+				if slices.Contains(claimed, file) {
+					skipped = append(skipped, file)
+				} else {
+					kept = append(kept, file)
+				}
+			}
+			return kept
+		}
+		files := unclaimed(slices.Concat(modified, newFiles))
+		deleted = unclaimed(deleted)
+		if len(skipped) > 0 {
+			fmt.Fprintf(os.Stderr, "Not committing %s to %s: another session in this checkout changed them. Run the agent in %s to keep sessions apart.\n",
+				strings.Join(skipped, ", "), WorkBranchName(sessionID), worktreePath)
+		}
+		if err := copyWorktreeFiles(repoRoot, worktreePath, files, deleted); err != nil {
+			return nil, err
+		}
+		if err := recordCopiedFiles(sessionID, repoRoot, files, deleted); err != nil {
+			return nil, err
+		}
+	}
+
+	repo, err := git.PlainOpenWithOptions(worktreePath, &git.PlainOpenOptions{
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session worktree: %w", err)
+	}
+	return repo, nil
+}
+
+// filesOfOtherSessions returns the files that live sessions other than
+// sessionID copied from the checkout at repoRoot. A session is live until it
+// ends or its work branch is accepted or discarded.
+func filesOfOtherSessions(ctx context.Context, sessionID, repoRoot string) ([]string, error) {
+	states, err := ListSessionStates()
+	if err != nil {
+		return nil, err
+	}
+	worktrees, err := listWorktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, state := range states {
+		if state.SessionID == sessionID || state.Phase == session.PhaseEnded || state.EndedAt != nil {
+			continue
+		}
+		if _, ok := worktrees[WorkBranchName(state.SessionID)]; !ok {
+			continue
+		}
+		if state.WorktreePath != "" && sameDir(state.WorktreePath, repoRoot) {
+			files = mergeFilesTouched(files, state.FilesTouched)
+		}
+	}
+	return files, nil
+}
+
+// recordCopiedFiles adds the files copied from the checkout at repoRoot to the
+// session's FilesTouched.
+func recordCopiedFiles(sessionID, repoRoot string, files, deleted []string) error {
+	if len(files) == 0 && len(deleted) == 0 {
+		return nil
+	}
+	state, err := LoadSessionState(sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to load session state: %w", err)
+	}
+	if state == nil {
+		return nil
+	}
+	state.WorktreePath = repoRoot
+	state.FilesTouched = mergeFilesTouched(state.FilesTouched, files, deleted)
+	if err := SaveSessionState(state); err != nil {
+		return fmt.Errorf("failed to save session state: %w", err)
+	}
+	return nil
+}
+
+// copyWorktreeFiles copies files (relative to the worktree roots) from src to
+// dst, removing those that no longer exist in src, and removes deleted from dst.
+func copyWorktreeFiles(src, dst string, files, deleted []string) error {
+	for _, file := range files {
+		srcPath := filepath.Join(src, filepath.FromSlash(file))
+		dstPath := filepath.Join(dst, filepath.FromSlash(file))
+		info, err := os.Lstat(srcPath)
+		if os.IsNotExist(err) {
+			deleted = append(deleted, file)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dstPath), 0o750); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", file, err)
+		}
+		if err := os.RemoveAll(dstPath); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", file, err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(srcPath)
+			if err != nil {
+				return fmt.Errorf("failed to read link %s: %w", file, err)
+			}
+			if err := os.Symlink(target, dstPath); err != nil {
+				return fmt.Errorf("failed to copy link %s: %w", file, err)
+			}
+			continue
+		}
+		content, err := os.ReadFile(srcPath) //nolint:gosec // Path is inside the repository
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := os.WriteFile(dstPath, content, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", file, err)
+		}
+	}
+	for _, file := range deleted {
+		if err := os.Remove(filepath.Join(dst, filepath.FromSlash(file))); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// ensureWorktreeForBranch returns the worktree branch is checked out in. If
+// there is none, it checks branch out in a new worktree under
+// .git/entire-worktrees/, creating branch at HEAD if it doesn't exist.
+func ensureWorktreeForBranch(ctx context.Context, branch string) (string, bool, error) {
+	worktrees, err := listWorktrees(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if path, ok := worktrees[branch]; ok {
+		return path, false, nil
+	}
+
+	commonDir, err := GetGitCommonDir()
+	if err != nil {
+		return "", false, err
+	}
+	commonDir, err = filepath.Abs(commonDir)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to resolve git common dir: %w", err)
+	}
+	path := filepath.Join(commonDir, sessionWorktreesDir, strings.TrimPrefix(branch, paths.WorkBranchPrefix))
+
+	args := []string{"worktree", "add", "--quiet", path, branch}
+	if branchExistsCLI(branch) != nil {
+		args = []string{"worktree", "add", "--quiet", "-b", branch, path, "HEAD"}
+	}
+	if _, err := gitIn(ctx, "", nil, args...); err != nil {
+		return "", false, fmt.Errorf("failed to create worktree for %s: %w", branch, err)
+	}
+	return path, true, nil
+}
+
+// listWorktrees returns the path of each worktree that has a branch checked
+// out, keyed by the branch name.
+func listWorktrees(ctx context.Context) (map[string]string, error) {
+	output, err := gitIn(ctx, "", nil, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	worktrees := make(map[string]string)
+	var path string
+	for _, line := range strings.Split(output, "\n") {
+		if rest, ok := strings.CutPrefix(line, "worktree "); ok {
+			path = rest
+		} else if rest, ok := strings.CutPrefix(line, "branch refs/heads/"); ok {
+			worktrees[rest] = path
+		}
+	}
+	return worktrees, nil
+}
+
+// sameDir reports whether a and b are the same directory.
+func sameDir(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// gitIn runs git in dir (the current directory if empty) with stdin as its
+// input and returns its trimmed output.
+func gitIn(ctx context.Context, dir string, stdin io.Reader, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SessionBranch is the work branch of a session-branch strategy session.
+type SessionBranch struct {
+	SessionID string // Empty if the session's state is gone
+	Branch    string
+	Worktree  string // Empty if the branch isn't checked out
+}
+
+// Name returns the session ID, or the branch name if the session is unknown.
+func (b SessionBranch) Name() string {
+	if b.SessionID != "" {
+		return b.SessionID
+	}
+	return b.Branch
+}
+
+// ListSessionBranches returns the work branches of all session-branch sessions.
+func ListSessionBranches(ctx context.Context) ([]SessionBranch, error) {
+	output, err := gitIn(ctx, "", nil, "for-each-ref", "--format=%(refname)", "refs/heads/"+paths.WorkBranchPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session branches: %w", err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	worktrees, err := listWorktrees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionIDs := make(map[string]string)
+	if store, err := session.NewStateStore(); err == nil {
+		states, _ := store.List(ctx) //nolint:errcheck // Sessions without state are still listed
+		for _, state := range states {
+			sessionIDs[WorkBranchName(state.SessionID)] = state.SessionID
+		}
+	}
+
+	var branches []SessionBranch
+	for _, ref := range strings.Split(output, "\n") {
+		branch := strings.TrimPrefix(ref, "refs/heads/")
+		branches = append(branches, SessionBranch{
+			SessionID: sessionIDs[branch],
+			Branch:    branch,
+			Worktree:  worktrees[branch],
+		})
+	}
+	return branches, nil
+}
+
+// FindSessionBranch returns the work branch of the session whose ID (or work
+// branch name) starts with query. An empty query matches the only session
+// branch there is.
+func FindSessionBranch(ctx context.Context, query string) (SessionBranch, error) {
+	branches, err := ListSessionBranches(ctx)
+	if err != nil {
+		return SessionBranch{}, err
+	}
+	var matches []SessionBranch
+	for _, b := range branches {
+		if query == "" || b.SessionID == query || b.Branch == query || b.Branch == WorkBranchName(query) {
+			matches = append(matches, b)
+		}
+	}
+	if len(matches) == 0 && query != "" {
+		for _, b := range branches {
+			if strings.HasPrefix(b.SessionID, query) || strings.HasPrefix(b.Branch, WorkBranchName(query)) {
+				matches = append(matches, b)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if query == "" {
+			return SessionBranch{}, ErrNoSessionBranch
+		}
+		return SessionBranch{}, fmt.Errorf("%w for %q", ErrNoSessionBranch, query)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, b := range matches {
+			names[i] = b.Name()
+		}
+		return SessionBranch{}, fmt.Errorf("several sessions match, specify one of: %s", strings.Join(names, ", "))
+	}
+}
+
+// AcceptMode selects how a session is applied to the user's branch.
+type AcceptMode string
+
+const (
+	// AcceptSquash applies the session as one commit with an Entire-Checkpoint
+	// trailer per checkpoint.
+	AcceptSquash AcceptMode = "squash"
+	// AcceptRebase applies the session's commits one by one.
+	AcceptRebase AcceptMode = "rebase"
+)
+
+// AcceptResult describes an accepted session.
+type AcceptResult struct {
+	Branch        string   // Branch the session was applied to
+	Commits       []string // Commits added to Branch, oldest first
+	CheckpointIDs []id.CheckpointID
+}
+
+// AcceptSession applies a session's work branch to the branch checked out in
+// the current worktree, then removes the work branch and its worktree.
+//
+// The work branch is first rebased onto the current branch in the session's
+// worktree, so a conflicting session can be fixed up there and accepted again.
+// With AcceptSquash its commits are then squashed into one. The current branch
+// is fast-forwarded to the result, and the files that changed are updated in
+// the working tree. Files with local changes matching neither the old nor the
+// new version make it fail before the branch is touched.
+func AcceptSession(ctx context.Context, sb SessionBranch, mode AcceptMode) (*AcceptResult, error) {
+	repo, head, err := openCheckoutForSession(sb)
+	if err != nil {
+		return nil, err
+	}
+	branch := head.Name().Short()
+	base := head.Hash().String()
+
+	worktree, _, err := ensureWorktreeForBranch(ctx, sb.Branch)
+	if err != nil {
+		return nil, err
+	}
+	status, err := gitIn(ctx, worktree, nil, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check session worktree: %w", err)
+	}
+	if status != "" {
+		return nil, fmt.Errorf("session worktree %s has uncommitted changes; commit or reset them first", worktree)
+	}
+
+	// The session's commits were made without hooks, so replay them without too.
+	if _, err := gitIn(ctx, worktree, nil, "-c", "core.hooksPath=/dev/null", "rebase", "--quiet", base); err != nil {
+		_, _ = gitIn(ctx, worktree, nil, "rebase", "--abort") //nolint:errcheck // Best effort, the conflict is reported below
+		return nil, fmt.Errorf("session conflicts with %s; rebase %s onto %s in %s, then accept again: %w", branch, sb.Branch, branch, worktree, err)
+	}
+	messages, err := commitMessages(ctx, worktree, base+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, ErrNothingToAccept
+	}
+	var cpIDs []id.CheckpointID
+	for _, message := range messages {
+		for _, cpID := range trailers.ParseAllCheckpoints(message) {
+			if !slices.Contains(cpIDs, cpID) {
+				cpIDs = append(cpIDs, cpID)
+			}
+		}
+	}
+
+	if mode == AcceptSquash {
+		if _, err := gitIn(ctx, worktree, nil, "reset", "--quiet", "--soft", base); err != nil {
+			return nil, fmt.Errorf("failed to squash session: %w", err)
+		}
+		message := squashedSessionMessage(sb, messages, cpIDs)
+		if _, err := gitIn(ctx, worktree, strings.NewReader(message), "-c", "core.hooksPath=/dev/null", "commit", "--quiet", "--no-verify", "--allow-empty", "--cleanup=verbatim", "-F", "-"); err != nil {
+			return nil, fmt.Errorf("failed to squash session: %w", err)
+		}
+	}
+	newTip, err := gitIn(ctx, worktree, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve session branch: %w", err)
+	}
+
+	changes, err := planCheckoutUpdate(ctx, repo, base, newTip)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes.conflicts) > 0 {
+		return nil, fmt.Errorf("local changes to %s would be overwritten; commit or stash them first", strings.Join(changes.conflicts, ", "))
+	}
+	if _, err := gitIn(ctx, "", nil, "update-ref", "-m", "entire: accept "+sb.Branch, "HEAD", newTip, base); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", branch, err)
+	}
+	if err := changes.apply(ctx); err != nil {
+		return nil, err
+	}
+
+	commits, err := gitIn(ctx, "", nil, "rev-list", "--reverse", base+".."+newTip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accepted commits: %w", err)
+	}
+	if err := removeSessionBranch(ctx, sb.Branch, worktree); err != nil {
+		return nil, err
+	}
+	TryUpdateCheckpointIndex(logging.WithComponent(ctx, "session-branch"))
+
+	return &AcceptResult{
+		Branch:        branch,
+		Commits:       strings.Fields(commits),
+		CheckpointIDs: cpIDs,
+	}, nil
+}
+
+// DiscardResult describes a discarded session.
+type DiscardResult struct {
+	Restored []string // Files restored to their HEAD version in the working tree
+	Kept     []string // Files the session changed that have other local changes
+}
+
+// DiscardSession removes a session's work branch, its worktree and its state.
+// Files in the current worktree that still have the session's latest content
+// are restored to their HEAD version, so the session's changes are undone
+// when the agent worked there; files changed since are left alone.
+func DiscardSession(ctx context.Context, sb SessionBranch) (*DiscardResult, error) {
+	repo, head, err := openCheckoutForSession(sb)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := gitIn(ctx, "", nil, "rev-parse", "--verify", "refs/heads/"+sb.Branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", sb.Branch, err)
+	}
+	mergeBase, err := gitIn(ctx, "", nil, "merge-base", head.Hash().String(), tip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find where %s forked: %w", sb.Branch, err)
+	}
+
+	// Restore files whose content is the session's to the HEAD version.
+	changes, err := planCheckoutUpdate(ctx, repo, tip, head.Hash().String())
+	if err != nil {
+		return nil, err
+	}
+	sessionFiles, err := changedFiles(ctx, mergeBase, tip)
+	if err != nil {
+		return nil, err
+	}
+	changes.keep(func(path string) bool { return slices.Contains(sessionFiles, path) })
+	result := &DiscardResult{Restored: changes.modified, Kept: changes.conflicts}
+	if err := changes.apply(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := removeSessionBranch(ctx, sb.Branch, sb.Worktree); err != nil {
+		return nil, err
+	}
+	if sb.SessionID != "" {
+		if err := ClearSessionState(sb.SessionID); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// openCheckoutForSession opens the current worktree and returns its HEAD,
+// failing unless a branch other than an Entire branch is checked out there.
+func openCheckoutForSession(sb SessionBranch) (*git.Repository, *plumbing.Reference, error) {
+	repoRoot, err := GetWorktreePath()
+	if err != nil {
+		return nil, nil, err
+	}
+	if sb.Worktree != "" && sameDir(repoRoot, sb.Worktree) {
+		return nil, nil, fmt.Errorf("this is the worktree of %s; run this from your own checkout", sb.Branch)
+	}
+	repo, err := OpenRepository()
+	if err != nil {
+		return nil, nil, err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return nil, nil, errors.New("HEAD is detached; check out a branch first")
+	}
+	if strings.HasPrefix(head.Name().Short(), "entire/") {
+		return nil, nil, fmt.Errorf("cannot apply a session to %s", head.Name().Short())
+	}
+	return repo, head, nil
+}
+
+// commitMessages returns the full messages of the commits in revRange, oldest first.
+func commitMessages(ctx context.Context, dir, revRange string) ([]string, error) {
+	output, err := gitIn(ctx, dir, nil, "log", "--reverse", "--format=%B%x00", revRange)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session commits: %w", err)
+	}
+	var messages []string
+	for _, message := range strings.Split(output, "\x00") {
+		if message = strings.TrimSpace(message); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+// squashedSessionMessage builds the message of an accepted, squashed session:
+// the session's first prompt as subject, the subjects of the squashed commits
+// as body, and the checkpoint trailers.
+func squashedSessionMessage(sb SessionBranch, messages []string, cpIDs []id.CheckpointID) string {
+	subject := "Apply session " + sb.Name()
+	if sb.SessionID != "" {
+		if prompt := sessionPromptSubject(sb.SessionID); prompt != "" {
+			subject = prompt
+		}
+	}
+	return formatSquashMessage(subject, "Squashed from "+sb.Branch+":", messages, cpIDs)
+}
+
+// changedFiles returns the paths that differ between two commits.
+func changedFiles(ctx context.Context, from, to string) ([]string, error) {
+	output, err := gitIn(ctx, "", nil, "diff", "--name-only", "--no-renames", "-z", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s..%s: %w", from, to, err)
+	}
+	var files []string
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// checkoutUpdate lists how the working tree must change when HEAD moves from
+// one commit to another.
+type checkoutUpdate struct {
+	checkout  []string // Paths to check out from HEAD
+	remove    []string // Paths to remove
+	modified  []string // Paths of checkout and remove whose content changes
+	conflicts []string // Paths with local changes matching neither commit
+}
+
+// planCheckoutUpdate compares each path that differs between from and to with
+// the working tree. Paths already matching to are updated in the index only.
+func planCheckoutUpdate(ctx context.Context, repo *git.Repository, from, to string) (*checkoutUpdate, error) {
+	files, err := changedFiles(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	fromTree, err := commitTree(repo, plumbing.NewHash(from))
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := commitTree(repo, plumbing.NewHash(to))
+	if err != nil {
+		return nil, err
+	}
+	repoRoot, err := GetWorktreePath()
+	if err != nil {
+		return nil, err
+	}
+
+	update := &checkoutUpdate{}
+	for _, file := range files {
+		oldHash, oldOK := treeBlobHash(fromTree, file)
+		newHash, newOK := treeBlobHash(toTree, file)
+		if !oldOK || !newOK {
+			continue // Submodules are left alone
+		}
+		current := worktreeBlobHash(filepath.Join(repoRoot, filepath.FromSlash(file)))
+		if current != oldHash && current != newHash {
+			update.conflicts = append(update.conflicts, file)
+			continue
+		}
+		if current != newHash {
+			update.modified = append(update.modified, file)
+		}
+		if newHash.IsZero() {
+			update.remove = append(update.remove, file)
+		} else {
+			update.checkout = append(update.checkout, file)
+		}
+	}
+	return update, nil
+}
+
+// keep drops the paths for which include returns false.
+func (u *checkoutUpdate) keep(include func(path string) bool) {
+	drop := func(path string) bool { return !include(path) }
+	u.checkout = slices.DeleteFunc(u.checkout, drop)
+	u.remove = slices.DeleteFunc(u.remove, drop)
+	u.modified = slices.DeleteFunc(u.modified, drop)
+	u.conflicts = slices.DeleteFunc(u.conflicts, drop)
+}
+
+// apply updates the index and working tree to HEAD for the planned paths.
+func (u *checkoutUpdate) apply(ctx context.Context) error {
+	if len(u.checkout) > 0 {
+		pathspec := strings.NewReader(strings.Join(u.checkout, "\x00"))
+		if _, err := gitIn(ctx, "", pathspec, "--literal-pathspecs", "checkout", "--quiet", "HEAD", "--pathspec-from-file=-", "--pathspec-file-nul"); err != nil {
+			return fmt.Errorf("failed to update files: %w", err)
+		}
+	}
+	if len(u.remove) > 0 {
+		pathspec := strings.NewReader(strings.Join(u.remove, "\x00"))
+		if _, err := gitIn(ctx, "", pathspec, "--literal-pathspecs", "rm", "--quiet", "--cached", "--ignore-unmatch", "--pathspec-from-file=-", "--pathspec-file-nul"); err != nil {
+			return fmt.Errorf("failed to remove files: %w", err)
+		}
+		repoRoot, err := GetWorktreePath()
+		if err != nil {
+			return err
+		}
+		for _, file := range u.remove {
+			if err := os.Remove(filepath.Join(repoRoot, filepath.FromSlash(file))); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove %s: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// treeBlobHash returns the blob hash of path in tree, or the zero hash if it
+// doesn't exist. Reports false if path is a submodule.
+func treeBlobHash(tree *object.Tree, path string) (plumbing.Hash, bool) {
+	entry, err := tree.FindEntry(path)
+	if err != nil {
+		return plumbing.ZeroHash, true
+	}
+	if entry.Mode == filemode.Submodule {
+		return plumbing.ZeroHash, false
+	}
+	return entry.Hash, true
+}
+
+// worktreeBlobHash returns the blob hash of the file at path, or the zero hash
+// if it doesn't exist. Directories hash to a value no blob has.
+func worktreeBlobHash(path string) plumbing.Hash {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return plumbing.ZeroHash
+	}
+	var content []byte
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return plumbing.ZeroHash
+		}
+		content = []byte(target)
+	case info.IsDir():
+		return plumbing.ComputeHash(plumbing.TreeObject, nil)
+	default:
+		if content, err = os.ReadFile(path); err != nil { //nolint:gosec // Path is inside the repository
+			return plumbing.ZeroHash
+		}
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, content)
+}
+
+// removeSessionBranch removes a work branch and the worktree it is checked out in.
+func removeSessionBranch(ctx context.Context, branch, worktree string) error {
+	if worktree != "" {
+		if _, err := gitIn(ctx, "", nil, "worktree", "remove", "--force", worktree); err != nil {
+			return fmt.Errorf("failed to remove worktree %s: %w", worktree, err)
+		}
+	}
+	if err := DeleteBranchCLI(branch); err != nil && !errors.Is(err, ErrBranchNotFound) {
+		return err
+	}
+	return nil
+}
+
+//nolint:gochecknoinits // Standard pattern for strategy registration
+func init() {
+	Register(StrategyNameSessionBranch, NewSessionBranchStrategy)
+}
@@ -0,0 +1,305 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestWorkBranchName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"2026-02-02-abc123":                    "entire/work/2026-02-02-abc123",
+		"f47ac10b-58cc-4372-a567-0e02b2c3d479": "entire/work/f47ac10b-58cc-4372-a567-0e02b2c3d479",
+		"../odd id.lock":                       "entire/work/---odd-id-lock",
+	}
+	for sessionID, want := range tests {
+		if got := WorkBranchName(sessionID); got != want {
+			t.Errorf("WorkBranchName(%q) = %q, want %q", sessionID, got, want)
+		}
+	}
+}
+
+// initSessionBranchRepo creates a repository with one commit and the
+// session-branch strategy set up.
+func initSessionBranchRepo(t *testing.T) (*git.Repository, string, *SessionBranchStrategy) {
+	t.Helper()
+	repo, dir := initRepoWithCommit(t, "")
+	s, ok := NewSessionBranchStrategy().(*SessionBranchStrategy)
+	if !ok {
+		t.Fatal("NewSessionBranchStrategy() is not a *SessionBranchStrategy")
+	}
+	if err := s.EnsureSetup(); err != nil {
+		t.Fatalf("EnsureSetup() error = %v", err)
+	}
+	return repo, dir, s
+}
+
+// saveSessionTurn writes files in the main checkout and saves them as a turn.
+func saveSessionTurn(t *testing.T, s Strategy, dir, sessionID, message string, files map[string]string) {
+	t.Helper()
+	var modified []string
+	for name, content := range files {
+		writeRepoFile(t, dir, name, content)
+		modified = append(modified, name)
+	}
+	metadataDir := filepath.Join(paths.EntireMetadataDir, sessionID)
+	if err := os.MkdirAll(filepath.Join(dir, metadataDir), 0o750); err != nil {
+		t.Fatalf("failed to create metadata dir: %v", err)
+	}
+	writeRepoFile(t, dir, filepath.Join(metadataDir, paths.TranscriptFileName), "{}\n")
+	err := s.SaveChanges(SaveContext{
+		SessionID:      sessionID,
+		CommitMessage:  message,
+		MetadataDir:    metadataDir,
+		MetadataDirAbs: filepath.Join(dir, metadataDir),
+		ModifiedFiles:  modified,
+		AuthorName:     "Test",
+		AuthorEmail:    "test@example.com",
+	})
+	if err != nil {
+		t.Fatalf("SaveChanges() error = %v", err)
+	}
+}
+
+func writeRepoFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func TestSessionBranchStrategy_SaveChangesCommitsToWorkBranch(t *testing.T) {
+	repo, dir, s := initSessionBranchRepo(t)
+	sessionID := "2026-10-17-session-branch"
+	mainHead := headHash(t, repo)
+
+	if err := s.InitializeSession(sessionID, "Claude Code", "", "Add a greeting"); err != nil {
+		t.Fatalf("InitializeSession() error = %v", err)
+	}
+	saveSessionTurn(t, s, dir, sessionID, "Add greeting", map[string]string{"hello.txt": "hello\n"})
+
+	if headHash(t, repo) != mainHead {
+		t.Error("SaveChanges() should not commit to the user's branch")
+	}
+	sb, err := FindSessionBranch(context.Background(), "")
+	if err != nil {
+		t.Fatalf("FindSessionBranch() error = %v", err)
+	}
+	if sb.SessionID != sessionID || sb.Branch != WorkBranchName(sessionID) || sb.Worktree == "" {
+		t.Fatalf("FindSessionBranch() = %+v", sb)
+	}
+	if content, err := os.ReadFile(filepath.Join(sb.Worktree, "hello.txt")); err != nil || string(content) != "hello\n" {
+		t.Errorf("worktree hello.txt = %q, %v", content, err)
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(sb.Branch), true)
+	if err != nil {
+		t.Fatalf("work branch not found: %v", err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("failed to read work branch commit: %v", err)
+	}
+	if len(commit.ParentHashes) != 1 || commit.ParentHashes[0].String() != mainHead {
+		t.Errorf("work branch commit parents = %v, want [%s]", commit.ParentHashes, mainHead)
+	}
+	cpID, found := trailers.ParseCheckpoint(commit.Message)
+	if !found {
+		t.Fatalf("work branch commit has no checkpoint trailer: %q", commit.Message)
+	}
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	summary, err := store.ReadCommitted(context.Background(), cpID)
+	if err != nil || summary == nil {
+		t.Fatalf("ReadCommitted(%s) = %v, %v", cpID, summary, err)
+	}
+	if summary.Strategy != StrategyNameSessionBranch {
+		t.Errorf("checkpoint strategy = %q, want %q", summary.Strategy, StrategyNameSessionBranch)
+	}
+}
+
+func TestSessionBranchStrategy_ConcurrentSessionsInOneCheckout(t *testing.T) {
+	_, dir, s := initSessionBranchRepo(t)
+	first, second := "2026-10-17-first", "2026-10-17-second"
+	for _, sessionID := range []string{first, second} {
+		if err := s.InitializeSession(sessionID, "Claude Code", "", "Edit shared.txt"); err != nil {
+			t.Fatalf("InitializeSession(%s) error = %v", sessionID, err)
+		}
+	}
+
+	saveSessionTurn(t, s, dir, first, "First edit", map[string]string{"shared.txt": "first\n"})
+	saveSessionTurn(t, s, dir, second, "Second edit", map[string]string{"shared.txt": "second\n", "own.txt": "own\n"})
+
+	worktree := func(sessionID string) string {
+		t.Helper()
+		sb, err := FindSessionBranch(context.Background(), sessionID)
+		if err != nil {
+			t.Fatalf("FindSessionBranch(%s) error = %v", sessionID, err)
+		}
+		return sb.Worktree
+	}
+	// The second session doesn't take over the file the first one changed.
+	if content, err := os.ReadFile(filepath.Join(worktree(first), "shared.txt")); err != nil || string(content) != "first\n" {
+		t.Errorf("first worktree shared.txt = %q, %v; want %q", content, err, "first\n")
+	}
+	if _, err := os.Stat(filepath.Join(worktree(second), "shared.txt")); !os.IsNotExist(err) {
+		t.Errorf("second worktree has shared.txt (err = %v), want it left to the first session", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(worktree(second), "own.txt")); err != nil || string(content) != "own\n" {
+		t.Errorf("second worktree own.txt = %q, %v; want %q", content, err, "own\n")
+	}
+
+	// Once the first session is discarded, the file is the second session's.
+	sb, err := FindSessionBranch(context.Background(), first)
+	if err != nil {
+		t.Fatalf("FindSessionBranch() error = %v", err)
+	}
+	if _, err := DiscardSession(context.Background(), sb); err != nil {
+		t.Fatalf("DiscardSession() error = %v", err)
+	}
+	saveSessionTurn(t, s, dir, second, "Second edit again", map[string]string{"shared.txt": "second again\n"})
+	if content, err := os.ReadFile(filepath.Join(worktree(second), "shared.txt")); err != nil || string(content) != "second again\n" {
+		t.Errorf("second worktree shared.txt = %q, %v; want %q", content, err, "second again\n")
+	}
+}
+
+func TestAcceptSession(t *testing.T) {
+	for _, mode := range []AcceptMode{AcceptSquash, AcceptRebase} {
+		t.Run(string(mode), func(t *testing.T) {
+			repo, dir, s := initSessionBranchRepo(t)
+			ctx := context.Background()
+			sessionID := "2026-10-17-accept-" + string(mode)
+
+			saveSessionTurn(t, s, dir, sessionID, "First turn", map[string]string{"a.txt": "a\n"})
+			saveSessionTurn(t, s, dir, sessionID, "Second turn", map[string]string{"b.txt": "b\n"})
+			// The user commits something else meanwhile.
+			writeRepoFile(t, dir, "user.txt", "user\n")
+			runGit(t, "add", "user.txt")
+			runGit(t, "commit", "-q", "--no-verify", "-m", "User commit")
+			userHead := headHash(t, repo)
+
+			sb, err := FindSessionBranch(ctx, sessionID[:len(sessionID)-2])
+			if err != nil {
+				t.Fatalf("FindSessionBranch() error = %v", err)
+			}
+			result, err := AcceptSession(ctx, sb, mode)
+			if err != nil {
+				t.Fatalf("AcceptSession() error = %v", err)
+			}
+
+			wantCommits := 1
+			if mode == AcceptRebase {
+				wantCommits = 2
+			}
+			if result.Branch != "master" || len(result.Commits) != wantCommits || len(result.CheckpointIDs) != 2 {
+				t.Fatalf("AcceptSession() = %+v, want %d commits and 2 checkpoints on master", result, wantCommits)
+			}
+			head := headHash(t, repo)
+			if head != result.Commits[len(result.Commits)-1] {
+				t.Errorf("HEAD = %s, want %s", head, result.Commits[len(result.Commits)-1])
+			}
+			first := result.Commits[0]
+			if parentHash(t, repo, first) != userHead {
+				t.Errorf("first accepted commit should be on top of %s", userHead)
+			}
+			commit, err := repo.CommitObject(plumbing.NewHash(head))
+			if err != nil {
+				t.Fatalf("failed to read HEAD: %v", err)
+			}
+			if mode == AcceptSquash && !slices.Equal(trailers.ParseAllCheckpoints(commit.Message), result.CheckpointIDs) {
+				t.Errorf("squashed commit checkpoints = %v, want %v", trailers.ParseAllCheckpoints(commit.Message), result.CheckpointIDs)
+			}
+
+			// The working tree is clean and the work branch is gone.
+			if status, err := gitIn(ctx, "", nil, "status", "--porcelain", "--untracked-files=no"); err != nil || status != "" {
+				t.Errorf("working tree not clean after accept: %v\n%s", err, status)
+			}
+			if _, err := FindSessionBranch(ctx, sessionID); !errors.Is(err, ErrNoSessionBranch) {
+				t.Errorf("FindSessionBranch() after accept error = %v, want ErrNoSessionBranch", err)
+			}
+			if _, err := os.Stat(sb.Worktree); !os.IsNotExist(err) {
+				t.Errorf("session worktree should be removed, stat error = %v", err)
+			}
+		})
+	}
+}
+
+func TestAcceptSession_RefusesToOverwriteLocalChanges(t *testing.T) {
+	_, dir, s := initSessionBranchRepo(t)
+	ctx := context.Background()
+	sessionID := "2026-10-17-conflict"
+	saveSessionTurn(t, s, dir, sessionID, "Edit README", map[string]string{"README.md": "# Session\n"})
+	writeRepoFile(t, dir, "README.md", "# Mine\n")
+
+	sb, err := FindSessionBranch(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("FindSessionBranch() error = %v", err)
+	}
+	if _, err := AcceptSession(ctx, sb, AcceptSquash); err == nil {
+		t.Fatal("AcceptSession() should fail when local changes would be overwritten")
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "README.md")); err != nil || string(content) != "# Mine\n" {
+		t.Errorf("README.md = %q, %v; local changes should be kept", content, err)
+	}
+	if _, err := FindSessionBranch(ctx, sessionID); err != nil {
+		t.Errorf("work branch should be kept after a failed accept: %v", err)
+	}
+}
+
+func TestDiscardSession(t *testing.T) {
+	repo, dir, s := initSessionBranchRepo(t)
+	ctx := context.Background()
+	sessionID := "2026-10-17-discard"
+	mainHead := headHash(t, repo)
+	saveSessionTurn(t, s, dir, sessionID, "Session edits", map[string]string{
+		"README.md": "# Session\n",
+		"new.txt":   "new\n",
+		"kept.txt":  "session\n",
+	})
+	// The user edits one of the files after the turn.
+	writeRepoFile(t, dir, "kept.txt", "user\n")
+
+	sb, err := FindSessionBranch(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("FindSessionBranch() error = %v", err)
+	}
+	result, err := DiscardSession(ctx, sb)
+	if err != nil {
+		t.Fatalf("DiscardSession() error = %v", err)
+	}
+	if !slices.Equal(result.Restored, []string{"README.md", "new.txt"}) || !slices.Equal(result.Kept, []string{"kept.txt"}) {
+		t.Errorf("DiscardSession() = %+v, want README.md and new.txt restored, kept.txt kept", result)
+	}
+	if content, err := os.ReadFile(filepath.Join(dir, "README.md")); err != nil || string(content) != "# Test\n" {
+		t.Errorf("README.md = %q, %v; want the HEAD version", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("new.txt should be removed, stat error = %v", err)
+	}
+	if headHash(t, repo) != mainHead {
+		t.Error("DiscardSession() should not move HEAD")
+	}
+	if _, err := FindSessionBranch(ctx, sessionID); !errors.Is(err, ErrNoSessionBranch) {
+		t.Errorf("FindSessionBranch() after discard error = %v, want ErrNoSessionBranch", err)
+	}
+	if state, err := LoadSessionState(sessionID); err != nil || state != nil {
+		t.Errorf("LoadSessionState() = %v, %v; want the state cleared", state, err)
+	}
+}
@@ -0,0 +1,113 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// LoadCommitSigner returns the signer for commits on the
+// entire/checkpoints/v1 branch, following git's own signing configuration:
+// commit.gpgSign, gpg.format, gpg.<format>.program (or gpg.program) and
+// user.signingKey. Returns nil when commit.gpgSign is off.
+func LoadCommitSigner(ctx context.Context) (*checkpoint.CommitSigner, error) {
+	if gitConfig(ctx, "--type=bool", "commit.gpgSign") != "true" {
+		return nil, nil //nolint:nilnil // Signing disabled is not an error
+	}
+
+	format := gitConfig(ctx, "gpg.format")
+	if format == "" {
+		format = checkpoint.SigningFormatOpenPGP
+	}
+	signer := &checkpoint.CommitSigner{
+		Format:  format,
+		Program: gitConfig(ctx, "gpg."+format+".program"),
+		Key:     gitConfig(ctx, "user.signingKey"),
+	}
+	switch format {
+	case checkpoint.SigningFormatOpenPGP:
+		if signer.Program == "" {
+			signer.Program = gitConfig(ctx, "gpg.program")
+		}
+		if signer.Program == "" {
+			signer.Program = "gpg"
+		}
+	case checkpoint.SigningFormatX509:
+		if signer.Program == "" {
+			signer.Program = "gpgsm"
+		}
+	case checkpoint.SigningFormatSSH:
+		if signer.Program == "" {
+			signer.Program = "ssh-keygen"
+		}
+		if signer.Key == "" {
+			key, err := sshDefaultKey(ctx)
+			if err != nil {
+				return nil, err
+			}
+			signer.Key = key
+		}
+	default:
+		return nil, fmt.Errorf("unsupported gpg.format %q", format)
+	}
+
+	if signer.Key == "" && format != checkpoint.SigningFormatSSH {
+		// Like git, sign with the committer identity.
+		ident := gitVar(ctx, "GIT_COMMITTER_IDENT")
+		if end := strings.Index(ident, ">"); end >= 0 {
+			signer.Key = ident[:end+1]
+		}
+	}
+	return signer, nil
+}
+
+// signMetadataCommit signs a commit for the entire/checkpoints/v1 branch
+// when git is configured to sign commits.
+func signMetadataCommit(ctx context.Context, commit *object.Commit) error {
+	signer, err := LoadCommitSigner(ctx)
+	if err != nil {
+		return err
+	}
+	return signer.Sign(ctx, commit) //nolint:wrapcheck // Already descriptive
+}
+
+// sshDefaultKey runs gpg.ssh.defaultKeyCommand, which git uses for ssh
+// signing when user.signingKey isn't set. Returns "" if it isn't configured.
+func sshDefaultKey(ctx context.Context) (string, error) {
+	command := gitConfig(ctx, "gpg.ssh.defaultKeyCommand")
+	if command == "" {
+		return "", nil
+	}
+	output, err := exec.CommandContext(ctx, "sh", "-c", command).Output() //nolint:gosec // Runs the user's own configured command, as git does
+	if err != nil {
+		return "", fmt.Errorf("gpg.ssh.defaultKeyCommand failed: %w", err)
+	}
+	key, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return key, nil
+}
+
+// gitConfig returns the value of a git config key, or "" if it isn't set.
+// Extra leading args such as --type=bool are passed to git config.
+func gitConfig(ctx context.Context, args ...string) string {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"config", "--get"}, args...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// gitVar returns the value of a git logical variable, or "" if unavailable.
+func gitVar(ctx context.Context, name string) string {
+	cmd := exec.CommandContext(ctx, "git", "var", name)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
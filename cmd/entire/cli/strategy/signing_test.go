@@ -0,0 +1,177 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+)
+
+func TestLoadCommitSigner(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  [][2]string
+		want    *checkpoint.CommitSigner
+		wantErr bool
+	}{
+		{name: "signing disabled"},
+		{
+			name:   "openpgp with committer identity",
+			config: [][2]string{{"commit.gpgSign", "true"}, {"user.name", "Test"}, {"user.email", "test@example.com"}},
+			want:   &checkpoint.CommitSigner{Format: "openpgp", Program: "gpg", Key: "Test <test@example.com>"},
+		},
+		{
+			name:   "openpgp with gpg.program",
+			config: [][2]string{{"commit.gpgSign", "yes"}, {"gpg.program", "gpg2"}, {"user.signingKey", "ABCD1234"}},
+			want:   &checkpoint.CommitSigner{Format: "openpgp", Program: "gpg2", Key: "ABCD1234"},
+		},
+		{
+			name:   "ssh",
+			config: [][2]string{{"commit.gpgSign", "true"}, {"gpg.format", "ssh"}, {"user.signingKey", "~/.ssh/id_ed25519.pub"}},
+			want:   &checkpoint.CommitSigner{Format: "ssh", Program: "ssh-keygen", Key: "~/.ssh/id_ed25519.pub"},
+		},
+		{
+			name:   "x509 with program",
+			config: [][2]string{{"commit.gpgSign", "true"}, {"gpg.format", "x509"}, {"gpg.x509.program", "smimesign"}, {"user.signingKey", "KEY"}},
+			want:   &checkpoint.CommitSigner{Format: "x509", Program: "smimesign", Key: "KEY"},
+		},
+		{
+			name:    "unsupported format",
+			config:  [][2]string{{"commit.gpgSign", "true"}, {"gpg.format", "pgp"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initRepoWithSettings(t, "")
+			for _, kv := range tt.config {
+				runGit(t, "config", kv[0], kv[1])
+			}
+			got, err := LoadCommitSigner(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadCommitSigner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("LoadCommitSigner() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || *got != *tt.want {
+				t.Errorf("LoadCommitSigner() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCheckpointStore_LoadsSignerOnFirstCommit(t *testing.T) {
+	repo, _ := initRepoWithSettings(t, "")
+	ctx := context.Background()
+	cpID := id.MustCheckpointID("5a0000000101")
+	writeIndexTestCheckpoint(t, checkpoint.NewGitStore(repo), cpID, "session-unsigned")
+
+	// A broken signing setup must not get in the way of reads.
+	runGit(t, "config", "commit.gpgSign", "true")
+	runGit(t, "config", "gpg.format", "pgp")
+	store, err := NewCheckpointStore(repo)
+	if err != nil {
+		t.Fatalf("NewCheckpointStore() error = %v", err)
+	}
+	if _, err := store.ReadCommitted(ctx, cpID); err != nil {
+		t.Errorf("ReadCommitted() error = %v", err)
+	}
+
+	err = store.WriteCommitted(ctx, checkpoint.WriteCommittedOptions{
+		CheckpointID: id.MustCheckpointID("5a0000000102"),
+		SessionID:    "session-signed",
+		Strategy:     "manual-commit",
+		Transcript:   []byte(`{"type":"user","message":{"content":"hello"}}` + "\n"),
+		AuthorName:   "Test",
+		AuthorEmail:  "test@example.com",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported gpg.format") {
+		t.Errorf("WriteCommitted() error = %v, want the signing configuration error", err)
+	}
+}
+
+func TestSignedMetadataCommits(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	repo, _ := initRepoWithSettings(t, "")
+	ctx := context.Background()
+	keyDir := t.TempDir()
+	keyFile := filepath.Join(keyDir, "id_ed25519")
+	if output, err := exec.CommandContext(ctx, "ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "test@example.com", "-f", keyFile).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v\n%s", err, output)
+	}
+	publicKey, err := os.ReadFile(keyFile + ".pub")
+	if err != nil {
+		t.Fatalf("failed to read public key: %v", err)
+	}
+	allowedSigners := filepath.Join(keyDir, "allowed_signers")
+	if err := os.WriteFile(allowedSigners, []byte("test@example.com "+string(publicKey)), 0o644); err != nil {
+		t.Fatalf("failed to write allowed signers: %v", err)
+	}
+	runGit(t, "config", "user.email", "test@example.com")
+	runGit(t, "config", "gpg.format", "ssh")
+	runGit(t, "config", "user.signingKey", keyFile)
+	runGit(t, "config", "gpg.ssh.allowedSignersFile", allowedSigners)
+
+	writeCheckpoint := func(cpID id.CheckpointID) checkpoint.CommittedStore {
+		t.Helper()
+		store, err := NewCheckpointStore(repo)
+		if err != nil {
+			t.Fatalf("NewCheckpointStore() error = %v", err)
+		}
+		writeIndexTestCheckpoint(t, store, cpID, "session-"+cpID.String())
+		return store
+	}
+	verify := func() []checkpoint.Problem {
+		t.Helper()
+		problems, err := VerifyMetadataSignatures(ctx, repo)
+		if err != nil {
+			t.Fatalf("VerifyMetadataSignatures() error = %v", err)
+		}
+		return problems
+	}
+
+	runGit(t, "config", "commit.gpgSign", "true")
+	signed := id.MustCheckpointID("5a0000000001")
+	writeCheckpoint(signed)
+	if problems := verify(); len(problems) != 0 {
+		t.Fatalf("VerifyMetadataSignatures() = %+v, want all commits signed", problems)
+	}
+	runGit(t, "verify-commit", "refs/heads/entire/checkpoints/v1")
+
+	runGit(t, "config", "commit.gpgSign", "false")
+	unsigned := id.MustCheckpointID("5a0000000002")
+	writeCheckpoint(unsigned)
+	problems := verify()
+	if len(problems) != 1 || problems[0].Kind != checkpoint.ProblemUnsignedCommit || problems[0].CheckpointID != unsigned {
+		t.Fatalf("VerifyMetadataSignatures() = %+v, want the unsigned commit of %s", problems, unsigned)
+	}
+
+	// Pruning rewrites history and signs the rewritten commits.
+	runGit(t, "config", "commit.gpgSign", "true")
+	store := writeCheckpoint(id.MustCheckpointID("5a0000000003"))
+	if err := store.PruneCommitted(ctx, checkpoint.PruneOptions{Delete: []id.CheckpointID{signed}}); err != nil {
+		t.Fatalf("PruneCommitted() error = %v", err)
+	}
+	if problems := verify(); len(problems) != 0 {
+		t.Errorf("VerifyMetadataSignatures() after prune = %+v, want all commits signed", problems)
+	}
+}
+
+func TestVerifyMetadataSignatures_NoBranch(t *testing.T) {
+	repo, _ := initRepoWithSettings(t, "")
+	problems, err := VerifyMetadataSignatures(context.Background(), repo)
+	if err != nil || len(problems) != 0 {
+		t.Errorf("VerifyMetadataSignatures() = %+v, %v; want nothing to check", problems, err)
+	}
+}
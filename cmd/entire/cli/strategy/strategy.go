@@ -379,13 +379,13 @@ type Strategy interface {
 	// NOTE: ListSessions and GetSession are standalone functions in session.go.
 	// They read from entire/checkpoints/v1 and merge with SessionSource if implemented.
 
-	// GetMetadataRef returns a reference to the metadata commit for the given checkpoint.
-	// Format: "<branch>@<commit-sha>" (e.g., "entire/checkpoints/v1@abc123").
+	// GetMetadataRef returns a reference to the metadata for the given checkpoint.
+	// Format: "<store-location>:<checkpoint-path>" (e.g., "entire/checkpoints/v1:a1/b2c3d4e5f6").
 	// Returns empty string if not applicable (e.g., commit strategy with filesystem metadata).
 	GetMetadataRef(checkpoint Checkpoint) string
 
-	// GetSessionMetadataRef returns a reference to the most recent metadata commit for a session.
-	// Format: "<branch>@<commit-sha>" (e.g., "entire/checkpoints/v1@abc123").
+	// GetSessionMetadataRef returns a reference to the most recent metadata for a session,
+	// in the same format as GetMetadataRef.
 	// Returns empty string if not applicable or session not found.
 	GetSessionMetadataRef(sessionID string) string
 
@@ -457,6 +457,17 @@ type PrePushHandler interface {
 	PrePush(remote string) error
 }
 
+// PostRewriteHandler is an optional interface for strategies that need to
+// handle the git post-rewrite hook.
+type PostRewriteHandler interface {
+	// PostRewrite is called by the git post-rewrite hook after `git commit --amend`
+	// or `git rebase` rewrote commits. rewriteType is "amend" or "rebase", and
+	// rewrites maps each rewritten commit to its final replacement.
+	// Used to move state that references the rewritten commits to their replacements.
+	// Should return nil on errors to not block the rewrite (log warnings to stderr).
+	PostRewrite(rewriteType string, rewrites []CommitRewrite) error
+}
+
 // TurnEndHandler is an optional interface for strategies that need to
 // perform work when an agent turn ends (ACTIVE → IDLE).
 // For example, manual-commit strategy uses this to finalize checkpoints
@@ -469,6 +480,16 @@ type TurnEndHandler interface {
 	HandleTurnEnd(state *session.State) error
 }
 
+// SessionEndHandler is an optional interface for strategies that need to
+// perform work when a session ends (→ ENDED).
+// For example, auto-commit strategy uses this to squash the session's commits
+// when strategy_options.squash_on_session_end is enabled.
+type SessionEndHandler interface {
+	// HandleSessionEnd performs strategy-specific work at the end of a session.
+	// The caller saves the state after this method returns.
+	HandleSessionEnd(state *session.State) error
+}
+
 // RestoredSession describes a single session that was restored by RestoreLogsOnly.
 // Each session may come from a different agent, so callers use this to print
 // per-session resume commands without re-reading the metadata tree.
@@ -0,0 +1,114 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// VerifyCheckpoints checks the integrity of the committed checkpoints in
+// store and that every Entire-Checkpoint trailer in the history of local
+// branches references a checkpoint that exists. Trailers referencing
+// checkpoints deleted by PruneCommitted are counted in Pruned instead.
+func VerifyCheckpoints(ctx context.Context, repo *git.Repository, store checkpoint.CommittedStore) (*checkpoint.VerifyResult, error) {
+	result, err := store.VerifyCommitted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify checkpoints: %w", err)
+	}
+
+	infos, err := store.ListCommitted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	exists := make(map[id.CheckpointID]bool, len(infos))
+	for _, info := range infos {
+		exists[info.CheckpointID] = true
+	}
+	// Checkpoints deleted by `entire prune` are gone on purpose.
+	prunedIDs, err := store.ListPruned(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pruned checkpoints: %w", err)
+	}
+	pruned := make(map[id.CheckpointID]bool, len(prunedIDs))
+	for _, cpID := range prunedIDs {
+		pruned[cpID] = true
+	}
+
+	// Scan from scratch: an existing index may still link commits that are
+	// no longer reachable.
+	idx := newCheckpointIndex()
+	if _, err := idx.scanBranches(repo); err != nil {
+		return nil, err
+	}
+	var dangling []id.CheckpointID
+	for cpID := range idx.Commits {
+		switch {
+		case pruned[cpID]:
+			result.Pruned++
+		case !exists[cpID]:
+			dangling = append(dangling, cpID)
+		}
+	}
+	slices.Sort(dangling)
+	for _, cpID := range dangling {
+		commits := idx.Commits[cpID]
+		slices.Sort(commits)
+		for _, commit := range commits {
+			result.Problems = append(result.Problems, checkpoint.Problem{
+				Kind:         checkpoint.ProblemDanglingTrailer,
+				CheckpointID: cpID,
+				Commit:       commit,
+				Detail:       "Entire-Checkpoint trailer references a checkpoint that doesn't exist",
+			})
+		}
+	}
+	return result, nil
+}
+
+// VerifyMetadataSignatures checks the signatures of the commits on the
+// local entire/checkpoints/v1 branch with git's own signature verification,
+// so gpg.ssh.allowedSignersFile and the gpg keyring apply.
+func VerifyMetadataSignatures(ctx context.Context, repo *git.Repository) ([]checkpoint.Problem, error) {
+	if _, ok := metadataBranchTip(repo); !ok {
+		return nil, nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "log", "--format=%H %G? %s", "refs/heads/"+paths.MetadataBranchName, "--")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check signatures of %s: %w", paths.MetadataBranchName, err)
+	}
+
+	var problems []checkpoint.Problem
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		hash, rest, _ := strings.Cut(line, " ")
+		status, subject, _ := strings.Cut(rest, " ")
+		problem := checkpoint.Problem{Commit: hash}
+		switch status {
+		case "G", "U", "X", "Y":
+			continue // Good, possibly with unknown trust or since expired
+		case "N":
+			problem.Kind, problem.Detail = checkpoint.ProblemUnsignedCommit, "commit is not signed"
+		case "B":
+			problem.Kind, problem.Detail = checkpoint.ProblemBadSignature, "signature is invalid"
+		case "R":
+			problem.Kind, problem.Detail = checkpoint.ProblemBadSignature, "signed with a revoked key"
+		default:
+			problem.Kind, problem.Detail = checkpoint.ProblemUnverifiableSignature, "signature can't be checked; is the signer's key known to git?"
+		}
+		if idStr, ok := strings.CutPrefix(subject, "Checkpoint: "); ok {
+			if cpID, err := id.NewCheckpointID(idStr); err == nil {
+				problem.CheckpointID = cpID
+			}
+		}
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
@@ -9,6 +9,9 @@ import (
 	"strings"
 
 	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/agent/aider"
+	"github.com/entireio/cli/cmd/entire/cli/agent/codex"
+	"github.com/entireio/cli/cmd/entire/cli/agent/cursor"
 	"github.com/entireio/cli/cmd/entire/cli/agent/geminicli"
 	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
 	"github.com/entireio/cli/cmd/entire/cli/transcript"
@@ -116,6 +119,12 @@ func BuildCondensedTranscriptFromBytes(content []byte, agentType agent.AgentType
 	switch agentType {
 	case agent.AgentTypeGemini:
 		return buildCondensedTranscriptFromGemini(content)
+	case agent.AgentTypeCursor:
+		return buildCondensedTranscriptFromCursor(content)
+	case agent.AgentTypeCodex:
+		return buildCondensedTranscriptFromCodex(content)
+	case agent.AgentTypeAider:
+		return buildCondensedTranscriptFromAider(content), nil
 	case agent.AgentTypeClaudeCode, agent.AgentTypeUnknown:
 		// Claude format - fall through to shared logic below
 	}
@@ -177,6 +186,159 @@ func extractGeminiToolDetail(args map[string]interface{}) string {
 	return ""
 }
 
+// buildCondensedTranscriptFromCursor parses Cursor JSONL transcript and extracts a condensed view.
+func buildCondensedTranscriptFromCursor(content []byte) ([]Entry, error) {
+	lines, err := cursor.ParseTranscript(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Cursor transcript: %w", err)
+	}
+
+	var entries []Entry
+	for _, line := range lines {
+		blocks := line.ContentBlocks()
+		switch line.Role {
+		case cursor.RoleUser:
+			var texts []string
+			for _, block := range blocks {
+				if block.Text != "" {
+					texts = append(texts, block.Text)
+				}
+			}
+			if text := cursor.CleanUserQuery(strings.Join(texts, "\n\n")); text != "" {
+				entries = append(entries, Entry{
+					Type:    EntryTypeUser,
+					Content: text,
+				})
+			}
+		case cursor.RoleAssistant:
+			for _, block := range blocks {
+				switch {
+				case block.Name != "":
+					var args map[string]interface{}
+					//nolint:errcheck,gosec // Unparseable input just means no tool detail
+					json.Unmarshal(block.Input, &args)
+					entries = append(entries, Entry{
+						Type:       EntryTypeTool,
+						ToolName:   block.Name,
+						ToolDetail: extractCursorToolDetail(args),
+					})
+				case block.Text != "":
+					entries = append(entries, Entry{
+						Type:    EntryTypeAssistant,
+						Content: block.Text,
+					})
+				}
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// extractCursorToolDetail extracts an appropriate detail string from Cursor tool input.
+// Cursor's file tools use target_file; the remaining fields match Gemini's.
+func extractCursorToolDetail(args map[string]interface{}) string {
+	if v, ok := args["target_file"].(string); ok && v != "" {
+		return v
+	}
+	return extractGeminiToolDetail(args)
+}
+
+// buildCondensedTranscriptFromCodex parses a Codex JSONL rollout and extracts a condensed view.
+func buildCondensedTranscriptFromCodex(content []byte) ([]Entry, error) {
+	lines, err := codex.ParseRollout(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Codex rollout: %w", err)
+	}
+
+	var entries []Entry
+	for _, line := range lines {
+		if text := codex.UserPromptText(line); text != "" {
+			entries = append(entries, Entry{
+				Type:    EntryTypeUser,
+				Content: text,
+			})
+			continue
+		}
+		if text := codex.AssistantText(line); text != "" {
+			entries = append(entries, Entry{
+				Type:    EntryTypeAssistant,
+				Content: text,
+			})
+			continue
+		}
+		if name, input, ok := codex.ToolCall(line); ok {
+			entries = append(entries, Entry{
+				Type:       EntryTypeTool,
+				ToolName:   name,
+				ToolDetail: extractCodexToolDetail(name, input),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// extractCodexToolDetail extracts an appropriate detail string from a Codex tool call.
+// apply_patch shows the patched files; shell shows the command line.
+func extractCodexToolDetail(name, input string) string {
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(input), &args); err != nil {
+		// custom_tool_call input is the raw patch rather than JSON
+		if name == codex.ToolApplyPatch {
+			return strings.Join(codex.ParsePatchFiles(input), ", ")
+		}
+		return ""
+	}
+	if patch, ok := args["input"].(string); ok && name == codex.ToolApplyPatch {
+		return strings.Join(codex.ParsePatchFiles(patch), ", ")
+	}
+	if command, ok := args["command"].([]interface{}); ok {
+		parts := make([]string, 0, len(command))
+		for _, part := range command {
+			if s, ok := part.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+	return extractGeminiToolDetail(args)
+}
+
+// buildCondensedTranscriptFromAider extracts a condensed view of the most recent
+// session in Aider's markdown chat history. Edited files are shown as tool entries.
+func buildCondensedTranscriptFromAider(content []byte) []Entry {
+	session := aider.LastSession(aider.ParseChatHistory(content))
+	if session == nil {
+		return nil
+	}
+
+	var entries []Entry
+	for _, exchange := range session.Exchanges {
+		if exchange.Prompt != "" {
+			entries = append(entries, Entry{
+				Type:    EntryTypeUser,
+				Content: exchange.Prompt,
+			})
+		}
+		if exchange.Response != "" {
+			entries = append(entries, Entry{
+				Type:    EntryTypeAssistant,
+				Content: exchange.Response,
+			})
+		}
+		for _, file := range exchange.ModifiedFiles {
+			entries = append(entries, Entry{
+				Type:       EntryTypeTool,
+				ToolName:   "edit",
+				ToolDetail: file,
+			})
+		}
+	}
+
+	return entries
+}
+
 // BuildCondensedTranscript extracts a condensed view of the transcript.
 // It processes user prompts, assistant responses, and tool calls into
 // a simplified format suitable for LLM summarization.
@@ -704,3 +704,97 @@ func mustMarshal(t *testing.T, v interface{}) json.RawMessage {
 	}
 	return data
 }
+
+func TestBuildCondensedTranscriptFromBytes_Cursor(t *testing.T) {
+	cursorJSONL := `{"role":"user","message":{"content":[{"type":"text","text":"<user_query>\nRename the handler\n</user_query>"}]}}
+{"role":"assistant","message":{"content":[{"type":"text","text":"Renaming it."},{"type":"tool_use","name":"edit_file","input":{"target_file":"handler.go"}}]}}
+`
+
+	entries, err := BuildCondensedTranscriptFromBytes([]byte(cursorJSONL), agent.AgentTypeCursor)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	if entries[0].Type != EntryTypeUser || entries[0].Content != "Rename the handler" {
+		t.Errorf("entry 0: unexpected entry: %+v", entries[0])
+	}
+	if entries[1].Type != EntryTypeAssistant || entries[1].Content != "Renaming it." {
+		t.Errorf("entry 1: unexpected entry: %+v", entries[1])
+	}
+	if entries[2].Type != EntryTypeTool || entries[2].ToolName != "edit_file" || entries[2].ToolDetail != "handler.go" {
+		t.Errorf("entry 2: unexpected entry: %+v", entries[2])
+	}
+}
+
+func TestBuildCondensedTranscriptFromBytes_Codex(t *testing.T) {
+	codexJSONL := `{"timestamp":"2025-01-01T00:00:00Z","type":"session_meta","payload":{"id":"abc","cwd":"/repo"}}
+{"timestamp":"2025-01-01T00:00:01Z","type":"event_msg","payload":{"type":"user_message","message":"Add a greeting"}}
+{"timestamp":"2025-01-01T00:00:02Z","type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"Adding it."}]}}
+{"timestamp":"2025-01-01T00:00:03Z","type":"response_item","payload":{"type":"custom_tool_call","name":"apply_patch","input":"*** Begin Patch\n*** Add File: hello.txt\n+hi\n*** End Patch"}}
+{"timestamp":"2025-01-01T00:00:04Z","type":"response_item","payload":{"type":"function_call","name":"shell","arguments":"{\"command\":[\"go\",\"test\",\"./...\"]}"}}
+`
+
+	entries, err := BuildCondensedTranscriptFromBytes([]byte(codexJSONL), agent.AgentTypeCodex)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d: %+v", len(entries), entries)
+	}
+
+	if entries[0].Type != EntryTypeUser || entries[0].Content != "Add a greeting" {
+		t.Errorf("entry 0: unexpected entry: %+v", entries[0])
+	}
+	if entries[1].Type != EntryTypeAssistant || entries[1].Content != "Adding it." {
+		t.Errorf("entry 1: unexpected entry: %+v", entries[1])
+	}
+	if entries[2].Type != EntryTypeTool || entries[2].ToolName != "apply_patch" || entries[2].ToolDetail != "hello.txt" {
+		t.Errorf("entry 2: unexpected entry: %+v", entries[2])
+	}
+	if entries[3].Type != EntryTypeTool || entries[3].ToolName != "shell" || entries[3].ToolDetail != "go test ./..." {
+		t.Errorf("entry 3: unexpected entry: %+v", entries[3])
+	}
+}
+
+func TestBuildCondensedTranscriptFromBytes_Aider(t *testing.T) {
+	history := `
+# aider chat started at 2025-01-01 09:00:00
+
+#### Old prompt
+
+Old answer.
+
+# aider chat started at 2025-01-02 10:00:00
+
+#### Add a greeting
+
+Adding it.
+
+> Applied edit to hello.txt
+`
+
+	entries, err := BuildCondensedTranscriptFromBytes([]byte(history), agent.AgentTypeAider)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only the most recent session is included
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d: %+v", len(entries), entries)
+	}
+
+	if entries[0].Type != EntryTypeUser || entries[0].Content != "Add a greeting" {
+		t.Errorf("entry 0: unexpected entry: %+v", entries[0])
+	}
+	if entries[1].Type != EntryTypeAssistant || entries[1].Content != "Adding it." {
+		t.Errorf("entry 1: unexpected entry: %+v", entries[1])
+	}
+	if entries[2].Type != EntryTypeTool || entries[2].ToolName != "edit" || entries[2].ToolDetail != "hello.txt" {
+		t.Errorf("entry 2: unexpected entry: %+v", entries[2])
+	}
+}
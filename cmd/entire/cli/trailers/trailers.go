@@ -60,6 +60,12 @@ var (
 	condensationTrailerRegex = regexp.MustCompile(CondensationTrailerKey + `:\s*(.+)`)
 	sessionTrailerRegex      = regexp.MustCompile(SessionTrailerKey + `:\s*(.+)`)
 	checkpointTrailerRegex   = regexp.MustCompile(CheckpointTrailerKey + `:\s*(` + checkpointID.Pattern + `)(?:\s|$)`)
+
+	// checkpointTrailerLineRegex matches a line holding only a checkpoint trailer.
+	checkpointTrailerLineRegex = regexp.MustCompile(`^` + CheckpointTrailerKey + `:\s*` + checkpointID.Pattern + `\s*$`)
+
+	// trailerLineRegex matches any "Key: value" trailer line.
+	trailerLineRegex = regexp.MustCompile(`^[A-Za-z0-9-]+:\s`)
 )
 
 // ParseStrategy extracts strategy from commit message.
@@ -138,6 +144,29 @@ func ParseCheckpoint(commitMessage string) (checkpointID.CheckpointID, bool) {
 	return checkpointID.EmptyCheckpointID, false
 }
 
+// ParseAllCheckpoints extracts all checkpoint IDs from a commit message.
+// Returns a slice of checkpoint IDs (may be empty if none found).
+// Duplicate checkpoint IDs are deduplicated while preserving order.
+// Commits squashed together during a rebase carry one trailer per checkpoint.
+func ParseAllCheckpoints(commitMessage string) []checkpointID.CheckpointID {
+	matches := checkpointTrailerRegex.FindAllStringSubmatch(commitMessage, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	seen := make(map[checkpointID.CheckpointID]bool)
+	cpIDs := make([]checkpointID.CheckpointID, 0, len(matches))
+	for _, match := range matches {
+		cpID, err := checkpointID.NewCheckpointID(strings.TrimSpace(match[1]))
+		if err != nil || seen[cpID] {
+			continue
+		}
+		seen[cpID] = true
+		cpIDs = append(cpIDs, cpID)
+	}
+	return cpIDs
+}
+
 // ParseAllSessions extracts all session IDs from a commit message.
 // Returns a slice of session IDs (may be empty if none found).
 // Duplicate session IDs are deduplicated while preserving order.
@@ -226,3 +255,59 @@ func FormatShadowTaskCommit(message, taskMetadataDir, sessionID string) string {
 func FormatCheckpoint(message string, cpID checkpointID.CheckpointID) string {
 	return fmt.Sprintf("%s\n\n%s: %s\n", message, CheckpointTrailerKey, cpID.String())
 }
+
+// SetCheckpoints replaces the checkpoint trailers of a commit message with one
+// Entire-Checkpoint trailer per ID, appended to the message's trailer block
+// (or a new one). Blank lines left behind by removed trailers are collapsed.
+// With no IDs it removes the checkpoint trailers.
+func SetCheckpoints(message string, cpIDs []checkpointID.CheckpointID) string {
+	var lines []string
+	removed := false
+	for _, line := range strings.Split(message, "\n") {
+		if checkpointTrailerLineRegex.MatchString(line) {
+			removed = true
+			continue
+		}
+		blank := strings.TrimSpace(line) == ""
+		if blank && removed && (len(lines) == 0 || strings.TrimSpace(lines[len(lines)-1]) == "") {
+			continue
+		}
+		if !blank {
+			removed = false
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(cpIDs) == 0 {
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	// Join an existing trailer block: the last paragraph, if every line in
+	// it is a trailer and it isn't the subject.
+	inTrailerBlock := false
+	for i := len(lines) - 1; i > 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			inTrailerBlock = true
+			break
+		}
+		if !trailerLineRegex.MatchString(lines[i]) {
+			break
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.Join(lines, "\n"))
+	switch {
+	case len(lines) == 0:
+	case inTrailerBlock:
+		sb.WriteString("\n")
+	default:
+		sb.WriteString("\n\n")
+	}
+	for _, cpID := range cpIDs {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", CheckpointTrailerKey, cpID.String()))
+	}
+	return sb.String()
+}
@@ -1,7 +1,10 @@
 package trailers
 
 import (
+	"slices"
 	"testing"
+
+	checkpointID "github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
 )
 
 func TestFormatMetadata(t *testing.T) {
@@ -318,3 +321,78 @@ func TestParseCheckpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAllCheckpoints(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{name: "no trailer", message: "Simple commit message"},
+		{
+			name:    "single trailer",
+			message: "Add feature\n\nEntire-Checkpoint: a1b2c3d4e5f6\n",
+			want:    []string{"a1b2c3d4e5f6"},
+		},
+		{
+			name:    "squashed messages, deduplicated in order",
+			message: "Add feature\n\nEntire-Checkpoint: a1b2c3d4e5f6\n\nFix typo\n\nEntire-Checkpoint: 0123456789ab\nEntire-Checkpoint: a1b2c3d4e5f6\n",
+			want:    []string{"a1b2c3d4e5f6", "0123456789ab"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, cpID := range ParseAllCheckpoints(tt.message) {
+				got = append(got, cpID.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseAllCheckpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetCheckpoints(t *testing.T) {
+	a := checkpointID.MustCheckpointID("a1b2c3d4e5f6")
+	b := checkpointID.MustCheckpointID("0123456789ab")
+	tests := []struct {
+		name    string
+		message string
+		ids     []checkpointID.CheckpointID
+		want    string
+	}{
+		{
+			name:    "adds a trailer block",
+			message: "Add feature\n",
+			ids:     []checkpointID.CheckpointID{a},
+			want:    "Add feature\n\nEntire-Checkpoint: a1b2c3d4e5f6\n",
+		},
+		{
+			name:    "merges squashed messages",
+			message: "Add feature\n\nEntire-Checkpoint: a1b2c3d4e5f6\n\nFix typo\n\nEntire-Checkpoint: 0123456789ab\n",
+			ids:     []checkpointID.CheckpointID{a, b},
+			want:    "Add feature\n\nFix typo\n\nEntire-Checkpoint: a1b2c3d4e5f6\nEntire-Checkpoint: 0123456789ab\n",
+		},
+		{
+			name:    "joins an existing trailer block",
+			message: "Add feature\n\nSigned-off-by: Test <test@example.com>\nEntire-Checkpoint: a1b2c3d4e5f6\n",
+			ids:     []checkpointID.CheckpointID{a, b},
+			want:    "Add feature\n\nSigned-off-by: Test <test@example.com>\nEntire-Checkpoint: a1b2c3d4e5f6\nEntire-Checkpoint: 0123456789ab\n",
+		},
+		{
+			name:    "removes trailers",
+			message: "Add feature\n\nEntire-Checkpoint: a1b2c3d4e5f6\n",
+			want:    "Add feature\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SetCheckpoints(tt.message, tt.ids); got != tt.want {
+				t.Errorf("SetCheckpoints() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
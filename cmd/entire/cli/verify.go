@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+	"github.com/spf13/cobra"
+)
+
+// verifyProblemOrder is the order problem classes are reported in.
+var verifyProblemOrder = []checkpoint.ProblemKind{
+	checkpoint.ProblemInvalidMetadata,
+	checkpoint.ProblemMissingFile,
+	checkpoint.ProblemIncompleteChunks,
+	checkpoint.ProblemUnreadableTranscript,
+	checkpoint.ProblemContentHashMismatch,
+	checkpoint.ProblemDanglingTrailer,
+	checkpoint.ProblemUnsignedCommit,
+	checkpoint.ProblemBadSignature,
+	checkpoint.ProblemUnverifiableSignature,
+}
+
+func newVerifyCmd() *cobra.Command {
+	var signatures bool
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check the integrity of committed checkpoints",
+		Long: `Verify walks all committed checkpoints and the history of local branches
+and reports inconsistencies:
+
+  invalid-metadata       metadata.json is missing, unparsable, or names another checkpoint
+  missing-file           a file listed in a checkpoint's metadata.json doesn't exist
+  incomplete-chunks      a chunked transcript is missing chunks
+  unreadable-transcript  a transcript can't be decompressed or reassembled
+  content-hash-mismatch  content_hash.txt doesn't match the session's transcript
+  dangling-trailer       a commit's Entire-Checkpoint trailer references a missing checkpoint
+                         (checkpoints removed by entire prune are skipped)
+
+With --signatures, the commits on the entire/checkpoints/v1 branch are also
+checked with git's signature verification (gpg keyring, or
+gpg.ssh.allowedSignersFile for SSH signatures):
+
+  unsigned-commit         a commit has no signature
+  bad-signature           a signature is invalid or made with a revoked key
+  unverifiable-signature  a signature can't be checked, e.g. the key is unknown
+
+Content hashes of encrypted transcripts are only checked when an identity
+file is configured. Fetch the entire/checkpoints/v1 branch first, or trailers
+of commits made on other machines may be reported as dangling.
+
+Exits with a non-zero status when problems are found, so it can run in CI.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if checkDisabledGuard(cmd.OutOrStdout()) {
+				return nil
+			}
+			return runVerify(cmd.Context(), cmd.OutOrStdout(), signatures)
+		},
+	}
+
+	cmd.Flags().BoolVar(&signatures, "signatures", false, "Also check the signatures of commits on the checkpoints branch")
+
+	return cmd
+}
+
+func runVerify(ctx context.Context, w io.Writer, signatures bool) error {
+	repo, err := openRepository()
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	store, err := strategy.NewCheckpointStore(repo)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint store: %w", err)
+	}
+
+	result, err := strategy.VerifyCheckpoints(ctx, repo, store)
+	if err != nil {
+		return err //nolint:wrapcheck // Already descriptive
+	}
+	if signatures {
+		problems, err := strategy.VerifyMetadataSignatures(ctx, repo)
+		if err != nil {
+			return err //nolint:wrapcheck // Already descriptive
+		}
+		result.Problems = append(result.Problems, problems...)
+	}
+
+	fmt.Fprintf(w, "Verified %d checkpoint(s) with %d session(s).\n", result.Checkpoints, result.Sessions)
+	if result.Encrypted > 0 {
+		fmt.Fprintf(w, "Skipped content hashes of %d encrypted session(s); configure encryption.identity_file to check them.\n", result.Encrypted)
+	}
+	if result.Pruned > 0 {
+		fmt.Fprintf(w, "Skipped trailers of %d checkpoint(s) removed by entire prune.\n", result.Pruned)
+	}
+	if len(result.Problems) == 0 {
+		fmt.Fprintln(w, "No problems found.")
+		return nil
+	}
+
+	byKind := make(map[checkpoint.ProblemKind][]checkpoint.Problem)
+	for _, p := range result.Problems {
+		byKind[p.Kind] = append(byKind[p.Kind], p)
+	}
+	for _, kind := range verifyProblemOrder {
+		problems := byKind[kind]
+		if len(problems) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s (%d)\n", kind, len(problems))
+		for _, p := range problems {
+			if p.CheckpointID.IsEmpty() {
+				fmt.Fprintf(w, "  %s\n", formatVerifyProblem(p))
+			} else {
+				fmt.Fprintf(w, "  %s  %s\n", p.CheckpointID, formatVerifyProblem(p))
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\nFound %d problem(s).\n", len(result.Problems))
+	return NewSilentError(fmt.Errorf("found %d problem(s)", len(result.Problems)))
+}
+
+// formatVerifyProblem formats where a problem is and what it is.
+func formatVerifyProblem(p checkpoint.Problem) string {
+	switch {
+	case p.Commit != "":
+		return fmt.Sprintf("commit %s: %s", p.Commit[:min(7, len(p.Commit))], p.Detail)
+	case p.Path != "":
+		return p.Path + ": " + p.Detail
+	default:
+		return p.Detail
+	}
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint"
+	"github.com/entireio/cli/cmd/entire/cli/checkpoint/id"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/trailers"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestRunVerify(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	paths.ClearRepoRootCache()
+	repo, err := git.PlainInit(tmpDir, false)
+	if err != nil {
+		t.Fatalf("failed to init git repo: %v", err)
+	}
+	existing := id.MustCheckpointID("fe0000000001")
+	if err := checkpoint.NewGitStore(repo).WriteCommitted(context.Background(), checkpoint.WriteCommittedOptions{
+		CheckpointID: existing,
+		SessionID:    "session-1",
+		Strategy:     "manual-commit",
+		Prompts:      []string{"add a feature"},
+		Transcript:   []byte(`{"type":"user","message":{"content":"add a feature"}}` + "\n"),
+	}); err != nil {
+		t.Fatalf("WriteCommitted() error = %v", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	commit := func(cpID id.CheckpointID) string {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tmpDir, "file.txt"), []byte(cpID.String()), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := wt.Add("file.txt"); err != nil {
+			t.Fatalf("failed to add file: %v", err)
+		}
+		hash, err := wt.Commit(trailers.FormatCheckpoint("Change file", cpID), &git.CommitOptions{
+			Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatalf("failed to commit: %v", err)
+		}
+		return hash.String()
+	}
+	commit(existing)
+
+	var stdout bytes.Buffer
+	if err := runVerify(context.Background(), &stdout, false); err != nil {
+		t.Fatalf("runVerify() error = %v\n%s", err, stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "Verified 1 checkpoint(s) with 1 session(s).") || !strings.Contains(stdout.String(), "No problems found.") {
+		t.Errorf("output = %q, want a clean report", stdout.String())
+	}
+
+	missing := id.MustCheckpointID("fe0000000002")
+	danglingCommit := commit(missing)
+	stdout.Reset()
+	err = runVerify(context.Background(), &stdout, false)
+	var silent *SilentError
+	if !errors.As(err, &silent) {
+		t.Fatalf("runVerify() error = %v, want a SilentError", err)
+	}
+	for _, want := range []string{"dangling-trailer (1)", missing.String(), "commit " + danglingCommit[:7], "Found 1 problem(s)."} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
+
+func TestRunVerify_AfterPrune(t *testing.T) {
+	store := setupPruneTestRepo(t, `{"strategy": "manual-commit", "retention": {"max_total_size": "1B"}}`)
+	cpID := id.MustCheckpointID("fe0000000011")
+	writePruneTestCheckpoint(t, store, cpID)
+
+	repo, err := git.PlainOpen(".")
+	if err != nil {
+		t.Fatalf("failed to open repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if err := os.WriteFile("file.txt", []byte("change\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := wt.Add("file.txt"); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	if _, err := wt.Commit(trailers.FormatCheckpoint("Change file", cpID), &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	if err := runPrune(context.Background(), &stdout, pruneOptions{Force: true, Remote: "origin"}); err != nil {
+		t.Fatalf("runPrune() error = %v", err)
+	}
+	if summary, err := store.ReadCommitted(context.Background(), cpID); err != nil || summary != nil {
+		t.Fatalf("ReadCommitted() = %v, %v; want the checkpoint pruned", summary, err)
+	}
+
+	// The trailer of the pruned checkpoint isn't dangling.
+	stdout.Reset()
+	if err := runVerify(context.Background(), &stdout, false); err != nil {
+		t.Fatalf("runVerify() error = %v\n%s", err, stdout.String())
+	}
+	for _, want := range []string{"Skipped trailers of 1 checkpoint(s) removed by entire prune.", "No problems found."} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, stdout.String())
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+	"github.com/entireio/cli/cmd/entire/cli/logging"
+	"github.com/entireio/cli/cmd/entire/cli/paths"
+	"github.com/entireio/cli/cmd/entire/cli/strategy"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// defaultWatchDebounce is how long a watched file must be quiet before its
+// change is handed to the agent. Agents often write their history in bursts.
+const defaultWatchDebounce = 2 * time.Second
+
+func newWatchCmd() *cobra.Command {
+	var agentFlag string
+	var debounce time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Capture sessions for agents without hooks",
+		Long: `Run a foreground process that captures sessions for agents that have no
+hook system, such as Aider.
+
+Each supported agent names the files it writes session history to. Entire
+watches those files and, when they change, starts and ends turns and creates
+checkpoints the same way hook-based agents do.
+
+Use --agent to watch a single agent. Press Ctrl+C to stop watching.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := paths.RepoRoot(); err != nil {
+				return fmt.Errorf("not a git repository: %w", err)
+			}
+
+			watchers, err := fileWatcherAgents(agent.AgentName(agentFlag))
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return runWatch(ctx, cmd.OutOrStdout(), watchers, debounce)
+		},
+	}
+
+	cmd.Flags().StringVar(&agentFlag, "agent", "", "Only watch the named agent")
+	cmd.Flags().DurationVar(&debounce, "debounce", defaultWatchDebounce, "How long a file must be unchanged before it is processed")
+
+	return cmd
+}
+
+// fileWatcherAgents returns the registered agents that support file watching.
+// If name is set, only that agent is returned.
+func fileWatcherAgents(name agent.AgentName) ([]agent.FileWatcher, error) {
+	if name != "" {
+		ag, err := agent.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown agent %q: %w", name, err)
+		}
+		watcher, ok := ag.(agent.FileWatcher)
+		if !ok {
+			return nil, fmt.Errorf("agent %q does not support file watching; it is captured through hooks instead", name)
+		}
+		return []agent.FileWatcher{watcher}, nil
+	}
+
+	var watchers []agent.FileWatcher
+	for _, agentName := range agent.List() {
+		ag, err := agent.Get(agentName)
+		if err != nil {
+			continue
+		}
+		if watcher, ok := ag.(agent.FileWatcher); ok {
+			watchers = append(watchers, watcher)
+		}
+	}
+	if len(watchers) == 0 {
+		return nil, errors.New("no registered agents support file watching")
+	}
+	return watchers, nil
+}
+
+// watchTarget is a path an agent asked to be watched.
+// Directories match any file directly inside them; files match only themselves.
+type watchTarget struct {
+	agent agent.FileWatcher
+	path  string
+	isDir bool
+}
+
+// watchKey identifies a changed file and the agent that owns it.
+type watchKey struct {
+	agentName agent.AgentName
+	path      string
+}
+
+func runWatch(ctx context.Context, w io.Writer, watchers []agent.FileWatcher, debounce time.Duration) error {
+	// Initialize logging so structured logs go to .entire/logs/ instead of stderr.
+	// Error is non-fatal: if logging init fails, logs go to stderr (acceptable fallback).
+	logging.SetLogLevelGetter(GetLogLevel)
+	if err := logging.Init(""); err == nil {
+		defer logging.Close()
+	}
+	logCtx := logging.WithComponent(context.Background(), "watch")
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer fsWatcher.Close()
+
+	targets, err := resolveWatchTargets(watchers)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return errors.New("no paths to watch")
+	}
+
+	watchedDirs := make(map[string]bool)
+	for _, target := range targets {
+		dir := target.path
+		if !target.isDir {
+			// Watch the parent so files that are replaced or created later are seen
+			dir = filepath.Dir(target.path)
+		}
+		if watchedDirs[dir] {
+			continue
+		}
+		if err := fsWatcher.Add(dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: cannot watch %s: %v\n", dir, err)
+			continue
+		}
+		watchedDirs[dir] = true
+	}
+	if len(watchedDirs) == 0 {
+		return errors.New("none of the agent paths could be watched")
+	}
+
+	for _, target := range targets {
+		fmt.Fprintf(w, "Watching %s for %s\n", target.path, target.agent.Name())
+	}
+	fmt.Fprintln(w, "Press Ctrl+C to stop.")
+
+	agentsByName := make(map[agent.AgentName]agent.FileWatcher, len(watchers))
+	for _, watcher := range watchers {
+		agentsByName[watcher.Name()] = watcher
+	}
+
+	pending := make(map[watchKey]time.Time)
+	ticker := time.NewTicker(watchTickInterval(debounce))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(w, "Stopped watching.")
+			return nil
+
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			for _, key := range matchWatchTargets(targets, event.Name) {
+				pending[key] = time.Now()
+			}
+
+		case watchErr, ok := <-fsWatcher.Errors:
+			if !ok {
+				return nil
+			}
+			logging.Warn(logCtx, "file watcher error", slog.String("error", watchErr.Error()))
+
+		case now := <-ticker.C:
+			for _, key := range dueWatchChanges(pending, now, debounce) {
+				delete(pending, key)
+				if err := handleWatchedFileChange(agentsByName[key.agentName], key.path); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", key.agentName, err)
+				}
+			}
+		}
+	}
+}
+
+// resolveWatchTargets collects the watch paths of each agent, resolving
+// relative paths against the repository root.
+func resolveWatchTargets(watchers []agent.FileWatcher) ([]watchTarget, error) {
+	var targets []watchTarget
+	for _, watcher := range watchers {
+		watchPaths, err := watcher.GetWatchPaths()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get watch paths for %s: %w", watcher.Name(), err)
+		}
+		for _, watchPath := range watchPaths {
+			absPath, err := paths.AbsPath(watchPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %w", watchPath, err)
+			}
+			info, statErr := os.Stat(absPath)
+			targets = append(targets, watchTarget{
+				agent: watcher,
+				path:  absPath,
+				isDir: statErr == nil && info.IsDir(),
+			})
+		}
+	}
+	return targets, nil
+}
+
+// matchWatchTargets returns the agent/file pairs a filesystem event applies to.
+func matchWatchTargets(targets []watchTarget, eventPath string) []watchKey {
+	var keys []watchKey
+	for _, target := range targets {
+		if (target.isDir && filepath.Dir(eventPath) == target.path) || (!target.isDir && eventPath == target.path) {
+			keys = append(keys, watchKey{agentName: target.agent.Name(), path: eventPath})
+		}
+	}
+	return keys
+}
+
+// dueWatchChanges returns the pending changes that have been quiet for at least
+// debounce, oldest first so turns are processed in the order they happened.
+func dueWatchChanges(pending map[watchKey]time.Time, now time.Time, debounce time.Duration) []watchKey {
+	var due []watchKey
+	for key, lastEvent := range pending {
+		if now.Sub(lastEvent) >= debounce {
+			due = append(due, key)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !pending[due[i]].Equal(pending[due[j]]) {
+			return pending[due[i]].Before(pending[due[j]])
+		}
+		return due[i].path < due[j].path
+	})
+	return due
+}
+
+// watchTickInterval returns how often pending changes are checked.
+func watchTickInterval(debounce time.Duration) time.Duration {
+	if interval := debounce / 4; interval > 50*time.Millisecond {
+		return interval
+	}
+	return 50 * time.Millisecond
+}
+
+// handleWatchedFileChange asks the agent what a file change means and applies
+// the resulting session event.
+func handleWatchedFileChange(ag agent.FileWatcher, path string) error {
+	enabled, err := IsEnabled()
+	if err == nil && !enabled {
+		return nil
+	}
+
+	change, err := ag.OnFileChange(path)
+	if err != nil {
+		return fmt.Errorf("failed to process change to %s: %w", path, err)
+	}
+	if change == nil || change.SessionID == "" {
+		return nil
+	}
+
+	logCtx := logging.WithAgent(logging.WithComponent(context.Background(), "watch"), ag.Name())
+	logging.Info(logCtx, "watch-session-change",
+		slog.String("event", string(change.EventType)),
+		slog.String("model_session_id", change.SessionID),
+		slog.String("transcript_path", change.SessionRef),
+	)
+
+	return applySessionChange(ag, change)
+}
+
+// applySessionChange converts a SessionChange into the same session transitions
+// the hook handlers perform for the equivalent hook. Agent plugin hooks are
+// dispatched through here too, since they report the same generic events.
+func applySessionChange(ag agent.Agent, change *agent.SessionChange) error {
+	switch change.EventType {
+	case agent.HookSessionStart:
+		transitionSessionStart(change.SessionID)
+		return nil
+	case agent.HookUserPromptSubmit:
+		return startWatchedTurn(ag, change)
+	case agent.HookStop:
+		return endWatchedTurn(ag, change)
+	case agent.HookSessionEnd:
+		return markSessionEnded(change.SessionID)
+	case agent.HookPreToolUse, agent.HookPostToolUse:
+		// Tool activity is recovered from the transcript at turn end
+		return nil
+	}
+	return nil
+}
+
+// startWatchedTurn captures pre-prompt state and starts a turn,
+// mirroring the prompt-submit hook of hook-based agents.
+func startWatchedTurn(ag agent.Agent, change *agent.SessionChange) error {
+	if err := CapturePrePromptState(change.SessionID, change.SessionRef); err != nil {
+		return fmt.Errorf("failed to capture pre-prompt state: %w", err)
+	}
+
+	strat := GetStrategy()
+	if err := strat.EnsureSetup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to ensure strategy setup: %v\n", err)
+	}
+
+	if initializer, ok := strat.(strategy.SessionInitializer); ok {
+		prompt := lastWatchedPrompt(ag, change)
+		if err := initializer.InitializeSession(change.SessionID, ag.Type(), change.SessionRef, prompt); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to initialize session state: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// endWatchedTurn saves a checkpoint for the turn and ends it.
+// Agents that only report turn ends get their turn started here, the way Codex does.
+func endWatchedTurn(ag agent.Agent, change *agent.SessionChange) error {
+	if change.SessionRef == "" || !fileExists(change.SessionRef) {
+		return fmt.Errorf("transcript file not found or empty: %s", change.SessionRef)
+	}
+
+	if repo, err := strategy.OpenRepository(); err == nil && strategy.IsEmptyRepository(repo) {
+		fmt.Fprintln(os.Stderr, "Entire: skipping checkpoint. Will activate after first commit.")
+		return nil
+	}
+
+	state, err := strategy.LoadSessionState(change.SessionID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load session state: %v\n", err)
+	}
+	if state == nil || !state.Phase.IsActive() {
+		strat := GetStrategy()
+		if err := strat.EnsureSetup(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to ensure strategy setup: %v\n", err)
+		}
+		if initializer, ok := strat.(strategy.SessionInitializer); ok {
+			prompt := lastWatchedPrompt(ag, change)
+			if err := initializer.InitializeSession(change.SessionID, ag.Type(), change.SessionRef, prompt); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to initialize session state: %v\n", err)
+			}
+		}
+	}
+
+	ctx := &agentSessionContext{
+		sessionID:      change.SessionID,
+		agentType:      ag.Type(),
+		transcriptPath: change.SessionRef,
+	}
+	if err := setupAgentSessionDir(ctx); err != nil {
+		return err
+	}
+	if err := extractWatchedMetadata(ag, change, ctx); err != nil {
+		return err
+	}
+	opts := agentCommitOptions{
+		agentType:                  ag.Type(),
+		transcriptStart:            func(state *PrePromptState) int { return state.StepTranscriptStart },
+		newFilesFromTranscriptOnly: true,
+	}
+	if calculator, ok := ag.(agent.TokenUsageCalculator); ok {
+		opts.calculateTokenUsage = calculator.CalculateTokenUsage
+	}
+	if err := commitAgentSession(ctx, opts); err != nil {
+		return err
+	}
+
+	transitionSessionTurnEnd(change.SessionID)
+
+	// Capture the baseline for the next turn in case the agent never reports turn starts
+	if err := CapturePrePromptState(change.SessionID, change.SessionRef); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to capture state for next turn: %v\n", err)
+	}
+	return nil
+}
+
+// extractWatchedMetadata fills prompts, summary, and modified files from the
+// agent's own session reader, since watched agents have no hook payloads.
+func extractWatchedMetadata(ag agent.Agent, change *agent.SessionChange, ctx *agentSessionContext) error {
+	sess, err := ag.ReadSession(&agent.HookInput{
+		HookType:   change.EventType,
+		SessionID:  change.SessionID,
+		SessionRef: change.SessionRef,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read session: %v\n", err)
+	}
+	if sess != nil {
+		for _, entry := range sess.Entries {
+			if entry.Type == agent.EntryUser && entry.Content != "" {
+				ctx.allPrompts = append(ctx.allPrompts, entry.Content)
+			}
+		}
+		ctx.summary = sess.GetLastAssistantResponse()
+		ctx.modifiedFiles = sess.ModifiedFiles
+	}
+	return writeAgentSessionMetadata(ctx)
+}
+
+// lastWatchedPrompt returns the latest user prompt in the session, or empty string.
+func lastWatchedPrompt(ag agent.Agent, change *agent.SessionChange) string {
+	sess, err := ag.ReadSession(&agent.HookInput{
+		HookType:   change.EventType,
+		SessionID:  change.SessionID,
+		SessionRef: change.SessionRef,
+	})
+	if err != nil || sess == nil {
+		return ""
+	}
+	return sess.GetLastUserPrompt()
+}
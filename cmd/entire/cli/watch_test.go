@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/entireio/cli/cmd/entire/cli/agent"
+)
+
+// fakeWatcherAgent is a minimal FileWatcher for exercising the watch loop helpers.
+// Methods not overridden here panic via the nil embedded Agent.
+type fakeWatcherAgent struct {
+	agent.Agent
+
+	name agent.AgentName
+}
+
+func (f *fakeWatcherAgent) Name() agent.AgentName { return f.name }
+
+func (f *fakeWatcherAgent) GetWatchPaths() ([]string, error) { return nil, nil }
+
+func (f *fakeWatcherAgent) OnFileChange(string) (*agent.SessionChange, error) {
+	return nil, nil //nolint:nilnil // No change to report
+}
+
+func TestFileWatcherAgents_RejectsHookAgent(t *testing.T) {
+	t.Parallel()
+
+	_, err := fileWatcherAgents(agent.AgentNameClaudeCode)
+	if err == nil {
+		t.Fatal("expected error for agent without file watching support")
+	}
+	if !strings.Contains(err.Error(), "does not support file watching") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFileWatcherAgents_UnknownAgent(t *testing.T) {
+	t.Parallel()
+
+	if _, err := fileWatcherAgents("no-such-agent"); err == nil {
+		t.Fatal("expected error for unknown agent")
+	}
+}
+
+func TestMatchWatchTargets(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	dirAgent := &fakeWatcherAgent{name: "dir-agent"}
+	fileAgent := &fakeWatcherAgent{name: "file-agent"}
+	historyFile := filepath.Join(root, ".history.md")
+	sessionsDir := filepath.Join(root, "sessions")
+
+	targets := []watchTarget{
+		{agent: fileAgent, path: historyFile},
+		{agent: dirAgent, path: sessionsDir, isDir: true},
+	}
+
+	tests := []struct {
+		name      string
+		eventPath string
+		want      []watchKey
+	}{
+		{
+			name:      "watched file",
+			eventPath: historyFile,
+			want:      []watchKey{{agentName: "file-agent", path: historyFile}},
+		},
+		{
+			name:      "sibling of watched file",
+			eventPath: filepath.Join(root, "other.md"),
+			want:      nil,
+		},
+		{
+			name:      "file inside watched directory",
+			eventPath: filepath.Join(sessionsDir, "a.jsonl"),
+			want:      []watchKey{{agentName: "dir-agent", path: filepath.Join(sessionsDir, "a.jsonl")}},
+		},
+		{
+			name:      "nested file is not matched",
+			eventPath: filepath.Join(sessionsDir, "nested", "a.jsonl"),
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := matchWatchTargets(targets, tt.eventPath)
+			if len(got) != len(tt.want) {
+				t.Fatalf("matchWatchTargets() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("matchWatchTargets()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDueWatchChanges(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	older := watchKey{agentName: "a", path: "/older"}
+	newer := watchKey{agentName: "a", path: "/newer"}
+	recent := watchKey{agentName: "a", path: "/recent"}
+	pending := map[watchKey]time.Time{
+		recent: now.Add(-500 * time.Millisecond),
+		newer:  now.Add(-3 * time.Second),
+		older:  now.Add(-5 * time.Second),
+	}
+
+	due := dueWatchChanges(pending, now, 2*time.Second)
+	if len(due) != 2 {
+		t.Fatalf("dueWatchChanges() = %v, want 2 keys", due)
+	}
+	if due[0] != older || due[1] != newer {
+		t.Errorf("dueWatchChanges() = %v, want oldest first [%v %v]", due, older, newer)
+	}
+}
+
+func TestWatchTickInterval(t *testing.T) {
+	t.Parallel()
+
+	if got := watchTickInterval(2 * time.Second); got != 500*time.Millisecond {
+		t.Errorf("watchTickInterval(2s) = %v, want 500ms", got)
+	}
+	if got := watchTickInterval(10 * time.Millisecond); got != 50*time.Millisecond {
+		t.Errorf("watchTickInterval(10ms) = %v, want 50ms floor", got)
+	}
+}